@@ -0,0 +1,13 @@
+package client
+
+import "github.com/bodrovis/lokex/v2/internal/apierr"
+
+// APIError is returned (possibly wrapped) for non-2xx API responses. Use
+// errors.As to inspect Status, Code, Details and the captured body in Raw;
+// Truncated reports that the body exceeded the limit set by
+// WithErrorBodyLimit.
+type APIError = apierr.APIError
+
+// ItemIssue is one per-item failure extracted by APIError.Items: the item's
+// index in the request, the key or file it refers to, and the message.
+type ItemIssue = apierr.ItemIssue
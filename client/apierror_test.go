@@ -0,0 +1,38 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestAPIError_ItemsFromResponse(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"Validation failed","code":400,"details":{"keys[1].key_name":"is already taken"}}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("test-token", "proj", client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.DoJSONWithRetry(context.Background(), http.MethodPost, "projects/proj/keys", nil, nil)
+
+	var ae *client.APIError
+	if !errors.As(err, &ae) {
+		t.Fatalf("error = %v, want *client.APIError", err)
+	}
+	items := ae.Items()
+	want := client.ItemIssue{Index: 1, Field: "key_name", Message: "is already taken", Source: "keys[1].key_name"}
+	if len(items) != 1 || items[0] != want {
+		t.Fatalf("Items() = %+v, want [%+v]", items, want)
+	}
+}
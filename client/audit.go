@@ -0,0 +1,220 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+// AuditEntry describes one mutating API call (after retries). It never
+// contains the token or request payloads; errors are redacted.
+type AuditEntry struct {
+	Time       time.Time     `json:"time"`
+	Actor      string        `json:"actor"` // TokenFingerprint of the API token
+	ProjectID  string        `json:"project_id"`
+	Operation  string        `json:"operation"` // e.g. "files.upload", "keys.create", "project.update"
+	Method     string        `json:"method"`
+	Path       string        `json:"path"`                  // without the query string
+	ParamsHash string        `json:"params_hash,omitempty"` // SHA-256 of the request body
+	Attempts   int           `json:"attempts"`
+	Duration   time.Duration `json:"duration"`
+	OK         bool          `json:"ok"`
+	Status     int           `json:"status,omitempty"`     // HTTP status of a failed call, if any
+	RequestID  string        `json:"request_id,omitempty"` // X-Request-Id of the last response, if any
+	Err        string        `json:"error,omitempty"`
+}
+
+// AuditSink receives audit entries. Record is called synchronously after
+// each mutating call; its error is ignored so auditing never changes the
+// outcome of a request. Implementations must be safe for concurrent use.
+type AuditSink interface {
+	Record(AuditEntry) error
+}
+
+// AuditSinkFunc adapts a function to AuditSink.
+type AuditSinkFunc func(AuditEntry) error
+
+// Record calls f.
+func (f AuditSinkFunc) Record(e AuditEntry) error { return f(e) }
+
+// JSONLinesSink writes each entry as one JSON line.
+type JSONLinesSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	c   io.Closer
+	err error
+}
+
+// NewJSONLinesSink writes entries to w.
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: w}
+}
+
+// OpenAuditLog appends entries to the file at path, creating it with 0600
+// permissions if needed. Close the sink when done.
+func OpenAuditLog(path string) (*JSONLinesSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &JSONLinesSink{w: f, c: f}, nil
+}
+
+// Record implements AuditSink.
+func (s *JSONLinesSink) Record(e AuditEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		return err
+	}
+	return nil
+}
+
+// Err returns the first write error, so callers can detect a broken audit
+// trail.
+func (s *JSONLinesSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close closes the file opened by OpenAuditLog; it is a no-op otherwise.
+func (s *JSONLinesSink) Close() error {
+	if s.c == nil {
+		return nil
+	}
+	return s.c.Close()
+}
+
+// WithAudit records every mutating API call (POST, PUT, PATCH, DELETE) to
+// sink: uploads, key changes, project and language changes, and so on.
+// Export requests (files/download, files/async-download) only read data and
+// are not recorded. Request bodies are hashed, never stored.
+func WithAudit(sink AuditSink) Option {
+	return func(c *Client) error {
+		if sink == nil {
+			return errors.New("audit sink cannot be nil")
+		}
+		c.audit = sink
+		return nil
+	}
+}
+
+// TokenFingerprint identifies an API token without revealing it: "sha256:"
+// followed by the first 16 hex digits of its SHA-256.
+func TokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// readOnlyPosts are POST endpoints (relative to the project) that don't
+// change anything.
+var readOnlyPosts = []string{"files/download", "files/async-download"}
+
+var auditVerbs = map[string]string{
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "update",
+	http.MethodDelete: "delete",
+}
+
+// auditOperation names a mutating call, or returns "" if it is not audited.
+func auditOperation(method, path string) string {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return ""
+	}
+
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	resource := segs[0]
+	var rest []string
+	if resource == "projects" && len(segs) >= 2 {
+		rest = segs[2:]
+		resource = "project"
+		if len(rest) > 0 {
+			resource = rest[0]
+		}
+	}
+	sub := strings.Join(rest, "/")
+	if method == http.MethodPost && slices.Contains(readOnlyPosts, sub) {
+		return ""
+	}
+	if resource == "files" && len(rest) > 1 {
+		return "files." + rest[1]
+	}
+
+	return resource + "." + auditVerbs[method]
+}
+
+// hashBody returns the SHA-256 of an in-memory or replayable body without
+// consuming it, or "" for other readers.
+func hashBody(body io.Reader) string {
+	h := sha256.New()
+	switch b := body.(type) {
+	case nil:
+		return ""
+	case interface {
+		io.ReaderAt
+		Size() int64
+	}:
+		if _, err := io.Copy(h, io.NewSectionReader(b, 0, b.Size())); err != nil {
+			return ""
+		}
+	case interface{ NewBody() (io.ReadCloser, error) }:
+		rc, err := b.NewBody()
+		if err != nil {
+			return ""
+		}
+		defer func() { _ = rc.Close() }()
+		if _, err := io.Copy(h, rc); err != nil {
+			return ""
+		}
+	default:
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordAudit sends an entry for a finished call to the audit sink.
+func (c *Client) recordAudit(op, method, path, paramsHash, requestID string, start time.Time, attempts int, err error) {
+	e := AuditEntry{
+		Time:       start.UTC(),
+		Actor:      TokenFingerprint(c.Token),
+		ProjectID:  c.ProjectID,
+		Operation:  op,
+		Method:     method,
+		Path:       path,
+		ParamsHash: paramsHash,
+		RequestID:  requestID,
+		Attempts:   attempts,
+		Duration:   time.Since(start),
+		OK:         err == nil,
+	}
+	if err != nil {
+		e.Err = c.Redactor().String(err.Error())
+		var ae *apierr.APIError
+		if errors.As(err, &ae) {
+			e.Status = ae.Status
+		}
+	}
+	_ = c.audit.Record(e)
+}
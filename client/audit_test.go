@@ -0,0 +1,260 @@
+package client_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+type auditRecorder struct {
+	mu      sync.Mutex
+	entries []client.AuditEntry
+}
+
+func (r *auditRecorder) Record(e client.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *auditRecorder) all() []client.AuditEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]client.AuditEntry(nil), r.entries...)
+}
+
+func auditServer(t *testing.T, failFirst int32) *httptest.Server {
+	t.Helper()
+
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/forbidden") {
+			w.Header().Set("X-Request-Id", "req-forbidden")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"message":"Forbidden","code":403}}`))
+			return
+		}
+		if n.Add(1) <= failFirst {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWithAudit_NilSink(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("tok", "proj", client.WithAudit(nil)); err == nil {
+		t.Fatal("WithAudit(nil) error = nil")
+	}
+}
+
+func TestWithAudit_RecordsMutatingCalls(t *testing.T) {
+	t.Parallel()
+
+	rec := &auditRecorder{}
+	srv := auditServer(t, 1)
+	c, err := client.NewClient("secret-token", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+		client.WithAudit(rec),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	body := []byte(`{"keys":[{"key_name":"a"}]}`)
+	calls := []struct {
+		method, path string
+		body         []byte
+	}{
+		{http.MethodPost, "projects/proj/keys", body},                   // retried once (503)
+		{http.MethodGet, "projects/proj/keys?limit=10", nil},            // read: not audited
+		{http.MethodPost, "projects/proj/files/download", []byte(`{}`)}, // export: not audited
+		{http.MethodPut, "projects/proj/keys/42?x=1", []byte(`{}`)},
+		{http.MethodDelete, "projects/proj/keys", []byte(`{}`)},
+		{http.MethodPost, "projects/proj/files/upload", []byte(`{}`)},
+		{http.MethodPut, "projects/proj", []byte(`{}`)},
+	}
+	for _, call := range calls {
+		var rdr *bytes.Reader
+		if call.body != nil {
+			rdr = bytes.NewReader(call.body)
+		}
+		var err error
+		if rdr != nil {
+			err = c.DoJSONWithRetry(ctx, call.method, call.path, rdr, nil)
+		} else {
+			err = c.DoJSONWithRetry(ctx, call.method, call.path, nil, nil)
+		}
+		if err != nil {
+			t.Fatalf("%s %s: %v", call.method, call.path, err)
+		}
+	}
+
+	got := rec.all()
+	wantOps := []string{"keys.create", "keys.update", "keys.delete", "files.upload", "project.update"}
+	if len(got) != len(wantOps) {
+		t.Fatalf("entries = %+v, want ops %v", got, wantOps)
+	}
+	for i, op := range wantOps {
+		if got[i].Operation != op {
+			t.Fatalf("entry %d operation = %q, want %q", i, got[i].Operation, op)
+		}
+	}
+
+	first := got[0]
+	sum := sha256.Sum256(body)
+	if first.ParamsHash != hex.EncodeToString(sum[:]) {
+		t.Fatalf("ParamsHash = %q", first.ParamsHash)
+	}
+	if first.Attempts != 2 || !first.OK || first.Err != "" || first.ProjectID != "proj" || first.Method != http.MethodPost {
+		t.Fatalf("first entry = %+v", first)
+	}
+	if first.Actor != client.TokenFingerprint("secret-token") || strings.Contains(first.Actor, "secret") {
+		t.Fatalf("Actor = %q", first.Actor)
+	}
+	if got[1].Path != "projects/proj/keys/42" {
+		t.Fatalf("Path = %q, want query stripped", got[1].Path)
+	}
+}
+
+func TestWithAudit_RecordsFailures(t *testing.T) {
+	t.Parallel()
+
+	rec := &auditRecorder{}
+	srv := auditServer(t, 0)
+	c, err := client.NewClient("tok", "proj", client.WithBaseURL(srv.URL), client.WithMaxRetries(0), client.WithAudit(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.DoJSONWithRetry(context.Background(), http.MethodPost, "projects/proj/forbidden", bytes.NewReader([]byte(`{}`)), nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	got := rec.all()
+	if len(got) != 1 || got[0].OK || got[0].Status != http.StatusForbidden || got[0].Err == "" || got[0].Attempts != 1 {
+		t.Fatalf("entries = %+v", got)
+	}
+	if got[0].RequestID != "req-forbidden" {
+		t.Fatalf("RequestID = %q, want req-forbidden", got[0].RequestID)
+	}
+}
+
+func TestWithAudit_SinkErrorsIgnored(t *testing.T) {
+	t.Parallel()
+
+	srv := auditServer(t, 0)
+	sink := client.AuditSinkFunc(func(client.AuditEntry) error { return errors.New("disk full") })
+	c, err := client.NewClient("tok", "proj", client.WithBaseURL(srv.URL), client.WithAudit(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodDelete, "projects/proj/keys/1", nil, nil); err != nil {
+		t.Fatalf("sink error leaked into the call: %v", err)
+	}
+}
+
+func TestOpenAuditLog(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := client.OpenAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := auditServer(t, 0)
+	c, err := client.NewClient("tok", "proj", client.WithBaseURL(srv.URL), client.WithAudit(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := c.DoJSONWithRetry(context.Background(), http.MethodPost, "projects/proj/languages", bytes.NewReader([]byte(`{}`)), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil || sink.Err() != nil {
+		t.Fatalf("Close() = %v, Err() = %v", err, sink.Err())
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("audit log mode = %v, want 0600", perm)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	var lines int
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e client.AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %d: %v", lines+1, err)
+		}
+		if e.Operation != "languages.create" || !e.OK {
+			t.Fatalf("entry = %+v", e)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Fatalf("lines = %d, want 2", lines)
+	}
+	if bytes.Contains(mustRead(t, path), []byte(`"tok"`)) {
+		t.Fatal("audit log contains the raw token")
+	}
+}
+
+func TestJSONLinesSink_WriteError(t *testing.T) {
+	t.Parallel()
+
+	sink := client.NewJSONLinesSink(failingWriter{})
+	if err := sink.Record(client.AuditEntry{Operation: "keys.create"}); err == nil {
+		t.Fatal("Record() error = nil")
+	}
+	if sink.Err() == nil {
+		t.Fatal("Err() = nil after failed write")
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken") }
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,314 @@
+// Package backup writes a full backup of a project into a dated directory:
+// the project's configuration (languages, custom translation statuses,
+// webhooks and settings, as a snapshot), its keys' metadata, and its files
+// exported in one or more formats, described by a manifest.
+//
+// A backup is assembled in a temporary directory next to its final one and
+// renamed into place when complete, so a failed run leaves nothing behind
+// that looks like a backup. Restore loads a backup into another project,
+// for disaster recovery or to clone a project.
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+	"github.com/bodrovis/lokex/v2/client/keys"
+	"github.com/bodrovis/lokex/v2/client/snapshot"
+)
+
+// Version is the manifest format version written by Backup.
+const Version = 1
+
+// Names of the files in a backup directory.
+const (
+	ManifestFile = "manifest.json"
+	ProjectFile  = "project.json" // a snapshot.Snapshot
+	KeysFile     = "keys.json"    // []keys.Key, without translations
+	FilesDir     = "files"        // files/<format>/<lang_iso>/<filename>
+)
+
+// DefaultFormats are exported when Options.Formats is empty.
+var DefaultFormats = []string{"json"}
+
+var now = time.Now
+
+// Manager wraps a *Client to back up its project.
+// Construct with NewManager; the embedded client must be non-nil.
+type Manager struct {
+	client *client.Client
+}
+
+// NewManager creates a new Manager bound to c.
+func NewManager(c *client.Client) *Manager {
+	if c == nil {
+		panic("lokex/backup: nil client passed to NewManager")
+	}
+	return &Manager{
+		client: c,
+	}
+}
+
+const managerIsNilMsg = "backup: manager/client is nil"
+
+// Options controls Backup.
+type Options struct {
+	// Formats are the file formats to export (e.g. "json", "xliff",
+	// "strings"), one export each. Empty means DefaultFormats.
+	Formats []string
+	// Params are added to every export, e.g. {"include_comments": true}.
+	// Backup sets format, original_filenames and directory_prefix itself.
+	Params download.DownloadParams
+	// Async uses async exports, which large projects need.
+	Async bool
+}
+
+// Manifest describes a backup. It is written last, so a directory without
+// one is not a complete backup.
+type Manifest struct {
+	Version     int       `json:"version"`
+	ProjectID   string    `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	Formats     []string  `json:"formats"`
+	Languages   int       `json:"languages"`
+	Statuses    int       `json:"custom_translation_statuses"`
+	Webhooks    int       `json:"webhooks"`
+	Keys        int       `json:"keys"`
+	Files       []File    `json:"files"`
+}
+
+// File is an exported file in a backup.
+type File struct {
+	Path    string `json:"path"` // relative to the backup directory, "/"-separated
+	Format  string `json:"format"`
+	LangISO string `json:"lang_iso"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+}
+
+// Result is a completed backup.
+type Result struct {
+	Dir      string // the backup directory
+	Manifest Manifest
+}
+
+// Backup writes a backup of the project into a new directory in dir, named
+// after the project ID and the UTC time, e.g.
+// "123.abc-20261018T150405Z". dir is created if needed.
+//
+// Files are exported with original filenames under a directory per
+// language, so files/<format>/<lang_iso>/ holds that language's files
+// as they are named in Lokalise. Webhook secrets are not included.
+func (m *Manager) Backup(ctx context.Context, dir string, opts Options) (Result, error) {
+	if m == nil || m.client == nil {
+		return Result{}, errors.New(managerIsNilMsg)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	formats, err := normalizeFormats(opts.Formats)
+	if err != nil {
+		return Result{}, err
+	}
+
+	created := now().UTC().Truncate(time.Second)
+	final := filepath.Join(dir, fmt.Sprintf("%s-%s", safeName(m.client.ProjectID), created.Format("20060102T150405Z")))
+	if _, err := os.Stat(final); err == nil {
+		return Result{}, fmt.Errorf("backup: %s already exists", final)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Result{}, fmt.Errorf("backup: %w", err)
+	}
+	tmp, err := os.MkdirTemp(dir, ".backup-*")
+	if err != nil {
+		return Result{}, fmt.Errorf("backup: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }() // a no-op once renamed
+
+	man := Manifest{
+		Version:   Version,
+		ProjectID: m.client.ProjectID,
+		CreatedAt: created,
+		Formats:   formats,
+	}
+
+	snap, err := snapshot.NewManager(m.client).Export(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("backup: %w", err)
+	}
+	man.ProjectName = snap.Name
+	man.Languages, man.Statuses, man.Webhooks = len(snap.Languages), len(snap.CustomStatuses), len(snap.Webhooks)
+	if err := writeFile(filepath.Join(tmp, ProjectFile), func(w io.Writer) error { return snapshot.Write(w, snap) }); err != nil {
+		return Result{}, fmt.Errorf("backup: %w", err)
+	}
+
+	ks, err := keys.NewManager(m.client).List(ctx, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("backup: %w", err)
+	}
+	man.Keys = len(ks)
+	if err := writeFile(filepath.Join(tmp, KeysFile), func(w io.Writer) error { return writeJSON(w, ks) }); err != nil {
+		return Result{}, fmt.Errorf("backup: keys: %w", err)
+	}
+
+	d := download.NewDownloader(m.client)
+	for _, f := range formats {
+		params := maps.Clone(opts.Params)
+		if params == nil {
+			params = download.DownloadParams{}
+		}
+		params["format"] = f
+		params["original_filenames"] = true
+		params["directory_prefix"] = download.PlaceholderLangISO
+
+		dest := filepath.Join(tmp, FilesDir, f)
+		if opts.Async {
+			_, err = d.DownloadAsync(ctx, dest, params)
+		} else {
+			_, err = d.Download(ctx, dest, params)
+		}
+		if err != nil {
+			return Result{}, fmt.Errorf("backup: export %s: %w", f, err)
+		}
+
+		files, err := listFiles(tmp, f)
+		if err != nil {
+			return Result{}, fmt.Errorf("backup: export %s: %w", f, err)
+		}
+		man.Files = append(man.Files, files...)
+	}
+
+	if err := writeFile(filepath.Join(tmp, ManifestFile), func(w io.Writer) error { return writeJSON(w, man) }); err != nil {
+		return Result{}, fmt.Errorf("backup: manifest: %w", err)
+	}
+	if err := os.Rename(tmp, final); err != nil {
+		return Result{}, fmt.Errorf("backup: %w", err)
+	}
+	return Result{Dir: final, Manifest: man}, nil
+}
+
+// ReadManifest reads the manifest of the backup in dir.
+func ReadManifest(dir string) (Manifest, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("backup: read manifest: %w", err)
+	}
+	var man Manifest
+	if err := json.Unmarshal(raw, &man); err != nil {
+		return Manifest{}, fmt.Errorf("backup: read manifest: %w", err)
+	}
+	if man.Version != Version {
+		return Manifest{}, fmt.Errorf("backup: read manifest: unsupported version %d", man.Version)
+	}
+	return man, nil
+}
+
+func normalizeFormats(formats []string) ([]string, error) {
+	if len(formats) == 0 {
+		return slices.Clone(DefaultFormats), nil
+	}
+	out := make([]string, 0, len(formats))
+	for _, f := range formats {
+		f = strings.TrimSpace(f)
+		if f == "" || f != filepath.Base(f) || f == "." || f == ".." {
+			return nil, fmt.Errorf("backup: invalid format %q", f)
+		}
+		if !slices.Contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// safeName makes a project ID usable as a directory name.
+func safeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// listFiles describes the files exported in format, sorted by path.
+func listFiles(root, format string) ([]File, error) {
+	base := filepath.Join(root, FilesDir, format)
+	var out []File
+	err := filepath.WalkDir(base, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == base {
+				return fs.SkipDir // an empty export
+			}
+			return err
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		size, sum, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		lang, _, ok := strings.Cut(strings.TrimPrefix(rel, path.Join(FilesDir, format)+"/"), "/")
+		if !ok {
+			lang = "" // not under a language directory
+		}
+		out = append(out, File{Path: rel, Format: format, LangISO: lang, Size: size, SHA256: sum})
+		return nil
+	})
+	return out, err
+}
+
+func hashFile(p string) (int64, string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeFile(p string, write func(io.Writer) error) (err error) {
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return write(f)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
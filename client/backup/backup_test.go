@@ -0,0 +1,212 @@
+package backup_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/backup"
+	"github.com/bodrovis/lokex/v2/client/snapshot"
+	"github.com/bodrovis/lokex/v2/testutils"
+
+	"github.com/jarcoal/httpmock"
+)
+
+const apiBase = "https://api.lokalise.com/api2/projects/proj/"
+
+// mockProject registers a project with two languages, one custom status,
+// one webhook, two keys and a bundle per format.
+func mockProject(t *testing.T, mt *httpmock.MockTransport, bundles map[string][]byte) *[]map[string]any {
+	t.Helper()
+
+	mt.RegisterResponder(http.MethodGet, "https://api.lokalise.com/api2/projects/proj",
+		httpmock.NewStringResponder(200, `{"project_id":"proj","name":"Shop","base_language_iso":"en","settings":{"branching":false}}`))
+	mt.RegisterResponder(http.MethodGet, `=~^`+apiBase+`languages`,
+		httpmock.NewStringResponder(200, `{"languages":[{"lang_iso":"en","lang_name":"English"},{"lang_iso":"de","lang_name":"German"}]}`))
+	mt.RegisterResponder(http.MethodGet, `=~^`+apiBase+`custom_translation_statuses`,
+		httpmock.NewStringResponder(200, `{"custom_translation_statuses":[{"title":"Legal","color":"#ff0000"}]}`))
+	mt.RegisterResponder(http.MethodGet, `=~^`+apiBase+`webhooks`,
+		httpmock.NewStringResponder(200, `{"webhooks":[{"url":"https://hooks.example.com/l","events":["project.imported"],"secret":"s3cr3t"}]}`))
+	mt.RegisterResponder(http.MethodGet, `=~^`+apiBase+`keys`,
+		httpmock.NewStringResponder(200, `{"keys":[
+			{"key_id":1,"key_name":{"web":"home.title"},"platforms":["web"],"tags":["home"]},
+			{"key_id":2,"key_name":{"web":"home.subtitle"},"platforms":["web"]}
+		]}`))
+
+	var exports []map[string]any
+	mt.RegisterResponder(http.MethodPost, apiBase+"files/download", func(r *http.Request) (*http.Response, error) {
+		var params map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			return httpmock.NewStringResponse(400, err.Error()), nil
+		}
+		exports = append(exports, params)
+		format, _ := params["format"].(string)
+		return httpmock.NewStringResponse(200, `{"project_id":"proj","bundle_url":"https://cdn.example.com/`+format+`.zip"}`), nil
+	})
+	for format, zip := range bundles {
+		mt.RegisterResponder(http.MethodGet, "https://cdn.example.com/"+format+".zip", func(*http.Request) (*http.Response, error) {
+			resp := httpmock.NewBytesResponse(200, zip)
+			resp.Header.Set("Content-Type", "application/zip")
+			return resp, nil
+		})
+	}
+	return &exports
+}
+
+func newTestClient(t *testing.T, mt *httpmock.MockTransport) *client.Client {
+	t.Helper()
+	c, err := client.NewClient("tok", "proj",
+		client.WithHTTPClient(&http.Client{Transport: mt}),
+		client.WithMaxRetries(0),
+		client.WithPollWait(time.Millisecond, 5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+func TestManager_Backup(t *testing.T) {
+	restore := backup.ExportSetNowForTest(func() time.Time {
+		return time.Date(2026, 10, 18, 15, 4, 5, 0, time.FixedZone("CEST", 2*3600))
+	})
+	defer restore()
+
+	mt := httpmock.NewMockTransport()
+	exports := mockProject(t, mt, map[string][]byte{
+		"json": testutils.BuildZip(t,
+			testutils.ZipFile("en/app.json", `{"home.title":"Home"}`),
+			testutils.ZipFile("de/app.json", `{"home.title":"Start"}`),
+		),
+		"xliff": testutils.BuildZip(t, testutils.ZipFile("en/app.xliff", `<xliff/>`)),
+	})
+
+	root := filepath.Join(t.TempDir(), "backups")
+	res, err := backup.NewManager(newTestClient(t, mt)).Backup(context.Background(), root, backup.Options{
+		Formats: []string{"json", " xliff", "json"},
+		Params:  map[string]any{"include_comments": true},
+	})
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	if want := filepath.Join(root, "proj-20261018T130405Z"); res.Dir != want {
+		t.Fatalf("Dir = %s, want %s", res.Dir, want)
+	}
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 1 {
+		t.Fatalf("backup root holds %d entries, want only the backup", len(entries))
+	}
+
+	man, err := backup.ReadManifest(res.Dir)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if !reflect.DeepEqual(man, res.Manifest) {
+		t.Fatalf("manifest on disk = %+v, want %+v", man, res.Manifest)
+	}
+	if man.ProjectName != "Shop" || man.Languages != 2 || man.Statuses != 1 || man.Webhooks != 1 || man.Keys != 2 {
+		t.Fatalf("manifest counts = %+v", man)
+	}
+	if !reflect.DeepEqual(man.Formats, []string{"json", "xliff"}) {
+		t.Fatalf("Formats = %v", man.Formats)
+	}
+
+	var paths []string
+	for _, f := range man.Files {
+		paths = append(paths, f.Format+":"+f.LangISO+":"+f.Path)
+		data, err := os.ReadFile(filepath.Join(res.Dir, filepath.FromSlash(f.Path)))
+		if err != nil || int64(len(data)) != f.Size || len(f.SHA256) != 64 {
+			t.Fatalf("file %+v: read %d bytes, err %v", f, len(data), err)
+		}
+	}
+	want := []string{
+		"json:de:files/json/de/app.json",
+		"json:en:files/json/en/app.json",
+		"xliff:en:files/xliff/en/app.xliff",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("files = %v, want %v", paths, want)
+	}
+
+	for _, p := range *exports {
+		if p["original_filenames"] != true || p["directory_prefix"] != "%LANG_ISO%" || p["include_comments"] != true {
+			t.Fatalf("export params = %v", p)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(res.Dir, backup.ProjectFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	snap, err := snapshot.Read(f)
+	if err != nil {
+		t.Fatalf("snapshot.Read() error = %v", err)
+	}
+	if len(snap.Languages) != 2 || snap.Webhooks[0].URL != "https://hooks.example.com/l" {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+	raw, _ := os.ReadFile(filepath.Join(res.Dir, backup.ProjectFile))
+	if strings.Contains(string(raw), "s3cr3t") {
+		t.Fatal("project.json contains the webhook secret")
+	}
+
+	var ks []struct {
+		KeyID int64 `json:"key_id"`
+	}
+	raw, _ = os.ReadFile(filepath.Join(res.Dir, backup.KeysFile))
+	if err := json.Unmarshal(raw, &ks); err != nil || len(ks) != 2 {
+		t.Fatalf("keys.json = %s (err %v)", raw, err)
+	}
+
+	// Same second, same name: refuse rather than mix two backups.
+	if _, err := backup.NewManager(newTestClient(t, mt)).Backup(context.Background(), root, backup.Options{}); err == nil {
+		t.Fatal("second Backup() in the same second error = nil, want error")
+	}
+}
+
+func TestManager_Backup_FailureLeavesNothing(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mockProject(t, mt, nil) // no bundle: the download fails
+
+	root := t.TempDir()
+	_, err := backup.NewManager(newTestClient(t, mt)).Backup(context.Background(), root, backup.Options{})
+	if err == nil || !strings.Contains(err.Error(), "backup: export json") {
+		t.Fatalf("Backup() error = %v, want an export error", err)
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 0 {
+		t.Fatalf("backup root holds %v after a failure, want nothing", entries)
+	}
+}
+
+func TestManager_Backup_InvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	m := backup.NewManager(newTestClient(t, httpmock.NewMockTransport()))
+	for _, f := range []string{"", "../json", "a/b", ".."} {
+		if _, err := m.Backup(context.Background(), t.TempDir(), backup.Options{Formats: []string{f}}); err == nil {
+			t.Fatalf("Backup(format %q) error = nil, want error", f)
+		}
+	}
+}
+
+func TestReadManifest_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if _, err := backup.ReadManifest(dir); err == nil {
+		t.Fatal("ReadManifest(missing) error = nil")
+	}
+	_ = os.WriteFile(filepath.Join(dir, backup.ManifestFile), []byte(`{"version":99}`), 0o644)
+	if _, err := backup.ReadManifest(dir); err == nil || !strings.Contains(err.Error(), "unsupported version 99") {
+		t.Fatalf("ReadManifest(v99) error = %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package backup
+
+import "time"
+
+func ExportSetNowForTest(fn func() time.Time) func() {
+	prev := now
+	now = fn
+	return func() {
+		now = prev
+	}
+}
@@ -0,0 +1,309 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/keys"
+	"github.com/bodrovis/lokex/v2/client/snapshot"
+	"github.com/bodrovis/lokex/v2/client/upload"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// ErrProjectNotEmpty is returned by Restore when the target project already
+// has keys and RestoreOptions.AllowNonEmpty is not set.
+var ErrProjectNotEmpty = errors.New("backup: restore: target project is not empty")
+
+// RestoreOptions controls Restore.
+type RestoreOptions struct {
+	// Format picks which of the backup's formats is uploaded; the others
+	// hold the same translations. Empty means the manifest's first format.
+	Format string
+	// Params are added to every upload, e.g. {"replace_modified": true}.
+	// Restore sets filename and lang_iso itself.
+	Params upload.UploadParams
+	// DryRun returns the plan without changing the project.
+	DryRun bool
+	// AllowNonEmpty restores into a project that already has keys, merging
+	// the backup into it.
+	AllowNonEmpty bool
+	// Progress, if set, is called after each file upload, successful or
+	// not.
+	Progress func(RestoreProgress)
+}
+
+// RestoreUpload is a file Restore uploads.
+type RestoreUpload struct {
+	Path     string // relative to the backup directory, "/"-separated
+	Filename string // the filename in Lokalise
+	LangISO  string
+}
+
+// RestorePlan lists what Restore does, or would do in a dry run.
+type RestorePlan struct {
+	Format    string
+	Languages []string // languages to create
+	Statuses  []string // custom translation statuses to create
+	Uploads   []RestoreUpload
+	// Skipped are backup files outside a language directory, which can't
+	// be uploaded without a lang_iso.
+	Skipped []string
+}
+
+// String renders the plan for review, one change per line.
+func (p RestorePlan) String() string {
+	var b strings.Builder
+	for _, l := range p.Languages {
+		fmt.Fprintf(&b, "+ language %s\n", l)
+	}
+	for _, s := range p.Statuses {
+		fmt.Fprintf(&b, "+ status %s\n", s)
+	}
+	for _, u := range p.Uploads {
+		fmt.Fprintf(&b, "^ %s (%s)\n", u.Filename, u.LangISO)
+	}
+	for _, s := range p.Skipped {
+		fmt.Fprintf(&b, "? %s (no language)\n", s)
+	}
+	fmt.Fprintf(&b, "%d languages, %d statuses, %d files (%s), %d skipped",
+		len(p.Languages), len(p.Statuses), len(p.Uploads), p.Format, len(p.Skipped))
+	return b.String()
+}
+
+// RestoreProgress reports one finished upload: the Done-th of Total.
+type RestoreProgress struct {
+	Done   int
+	Total  int
+	Upload RestoreUpload
+	Err    error
+}
+
+// RestoredFile is the outcome of one upload.
+type RestoredFile struct {
+	RestoreUpload
+	ProcessID string
+	Summary   client.ImportSummary
+	Err       error
+}
+
+// RestoreResult is the outcome of Restore.
+type RestoreResult struct {
+	Plan RestorePlan
+	// Files follow Plan.Uploads; empty in a dry run.
+	Files []RestoredFile
+	// KeysUpdated counts keys whose tags, description or platforms were
+	// set from keys.json after the uploads.
+	KeysUpdated int
+	// KeysMissing are backed-up keys that weren't in the project after
+	// the uploads, e.g. keys without translations in the uploaded format.
+	KeysMissing []string
+}
+
+// HasErrors reports whether any upload failed.
+func (r RestoreResult) HasErrors() bool {
+	return slices.ContainsFunc(r.Files, func(f RestoredFile) bool { return f.Err != nil })
+}
+
+// Restore loads the backup in dir into the manager's project: it creates
+// the backup's missing languages and custom translation statuses, then
+// uploads the files of one format, one at a time, with the lang_iso of
+// their language directory and their original filenames. Finally it sets
+// the tags, descriptions and platforms from keys.json on the keys the
+// uploads created (or that already existed, see AllowNonEmpty); keys the
+// uploads didn't create are reported in KeysMissing, not created. Key
+// comments and screenshots are not in the backup and are not restored.
+// Webhooks are not restored either: the backup has no secrets, and a copy
+// of a project rarely should notify the same endpoints.
+//
+// Everything is checked before the project is touched: the manifest, the
+// checksums of the files, and that the project has no keys yet (see
+// RestoreOptions.AllowNonEmpty). A failed upload doesn't stop the others;
+// it is reported in the result. The error is for everything else.
+func (m *Manager) Restore(ctx context.Context, dir string, opts RestoreOptions) (RestoreResult, error) {
+	if m == nil || m.client == nil {
+		return RestoreResult{}, errors.New(managerIsNilMsg)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	man, err := ReadManifest(dir)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	f, err := os.Open(filepath.Join(dir, ProjectFile))
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("backup: restore: %w", err)
+	}
+	snap, err := snapshot.Read(f)
+	_ = f.Close()
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("backup: restore: %w", err)
+	}
+	snap.Webhooks = nil
+
+	plan, err := planUploads(dir, man, opts.Format)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	sm := snapshot.NewManager(m.client)
+	cfg, err := sm.Plan(ctx, snap)
+	if err != nil {
+		return RestoreResult{Plan: plan}, fmt.Errorf("backup: restore: %w", err)
+	}
+	plan.Languages, plan.Statuses = cfg.LanguagesAdded, cfg.StatusesAdded
+	res := RestoreResult{Plan: plan}
+
+	if !opts.AllowNonEmpty {
+		empty, err := m.projectEmpty(ctx)
+		if err != nil {
+			return res, fmt.Errorf("backup: restore: %w", err)
+		}
+		if !empty {
+			return res, fmt.Errorf("%w: %s", ErrProjectNotEmpty, m.client.ProjectID)
+		}
+	}
+	if opts.DryRun {
+		return res, nil
+	}
+
+	if _, err := sm.Apply(ctx, snap); err != nil {
+		return res, fmt.Errorf("backup: restore: %w", err)
+	}
+
+	up := upload.NewUploader(m.client)
+	res.Files = make([]RestoredFile, 0, len(plan.Uploads))
+	for i, u := range plan.Uploads {
+		if err := ctx.Err(); err != nil {
+			return res, fmt.Errorf("backup: restore: %w", err)
+		}
+
+		params := maps.Clone(opts.Params)
+		if params == nil {
+			params = upload.UploadParams{}
+		}
+		params["filename"] = u.Filename
+		params["lang_iso"] = u.LangISO
+
+		out, err := up.UploadWithResult(ctx, params, filepath.Join(dir, filepath.FromSlash(u.Path)))
+		rf := RestoredFile{RestoreUpload: u, ProcessID: out.ProcessID, Summary: out.Summary, Err: err}
+		res.Files = append(res.Files, rf)
+		if opts.Progress != nil {
+			opts.Progress(RestoreProgress{Done: i + 1, Total: len(plan.Uploads), Upload: u, Err: err})
+		}
+	}
+
+	if err := m.restoreKeys(ctx, dir, &res); err != nil {
+		return res, fmt.Errorf("backup: restore: keys: %w", err)
+	}
+	return res, nil
+}
+
+// restoreKeys sets the tags, descriptions and platforms recorded in
+// keys.json on the project's keys of the same name.
+func (m *Manager) restoreKeys(ctx context.Context, dir string, res *RestoreResult) error {
+	raw, err := os.ReadFile(filepath.Join(dir, KeysFile))
+	if err != nil {
+		return err
+	}
+	var saved []keys.Key
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		return fmt.Errorf("decode %s: %w", KeysFile, err)
+	}
+
+	desired := make([]keys.DesiredKey, 0, len(saved))
+	seen := make(map[string]bool, len(saved))
+	for _, k := range saved {
+		names := k.Names()
+		if len(names) == 0 || len(k.Platforms) == 0 || seen[names[0]] {
+			continue
+		}
+		seen[names[0]] = true
+		desired = append(desired, keys.DesiredKey{
+			Name: names[0], Platforms: k.Platforms, Tags: k.Tags, Description: k.Description,
+		})
+	}
+	if len(desired) == 0 {
+		return nil
+	}
+
+	km := keys.NewManager(m.client)
+	kp, err := km.Plan(ctx, desired, keys.PlanOptions{})
+	if err != nil {
+		return err
+	}
+	for _, d := range kp.Create {
+		res.KeysMissing = append(res.KeysMissing, d.Name)
+	}
+	if len(kp.Update) == 0 {
+		return nil
+	}
+	out, err := km.UpdateKeys(ctx, kp.Update)
+	res.KeysUpdated = len(out.Keys)
+	if err != nil {
+		return err
+	}
+	return out.Err()
+}
+
+// planUploads picks the files of format (or the manifest's first) and
+// checks them against their checksums.
+func planUploads(dir string, man Manifest, format string) (RestorePlan, error) {
+	format = strings.TrimSpace(format)
+	if format == "" {
+		if len(man.Formats) == 0 {
+			return RestorePlan{}, errors.New("backup: restore: manifest lists no formats")
+		}
+		format = man.Formats[0]
+	}
+	if !slices.Contains(man.Formats, format) {
+		return RestorePlan{}, fmt.Errorf("backup: restore: format %q not in backup (have %v)", format, man.Formats)
+	}
+
+	plan := RestorePlan{Format: format}
+	prefix := path.Join(FilesDir, format) + "/"
+	for _, f := range man.Files {
+		if f.Format != format {
+			continue
+		}
+		if f.LangISO == "" {
+			plan.Skipped = append(plan.Skipped, f.Path)
+			continue
+		}
+		name, ok := strings.CutPrefix(f.Path, prefix+f.LangISO+"/")
+		if !ok || !filepath.IsLocal(filepath.FromSlash(f.Path)) {
+			return RestorePlan{}, fmt.Errorf("backup: restore: unexpected file path %q", f.Path)
+		}
+		size, sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return RestorePlan{}, fmt.Errorf("backup: restore: %w", err)
+		}
+		if size != f.Size || sum != f.SHA256 {
+			return RestorePlan{}, fmt.Errorf("backup: restore: %s does not match its checksum", f.Path)
+		}
+		plan.Uploads = append(plan.Uploads, RestoreUpload{Path: f.Path, Filename: name, LangISO: f.LangISO})
+	}
+	return plan, nil
+}
+
+// projectEmpty reports whether the project has no keys, reading at most
+// one.
+func (m *Manager) projectEmpty(ctx context.Context) (bool, error) {
+	p := client.NewPaginator[json.RawMessage](m.client, utils.ProjectPath(m.client.ProjectID, "keys"), "keys",
+		client.PaginatorOptions{Limit: 1})
+	ks, err := p.Next(ctx)
+	if err != nil {
+		return false, fmt.Errorf("check keys: %w", err)
+	}
+	return len(ks) == 0, nil
+}
@@ -0,0 +1,282 @@
+package backup_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/backup"
+	"github.com/bodrovis/lokex/v2/testutils"
+
+	"github.com/jarcoal/httpmock"
+)
+
+// makeBackup writes a backup of the mockProject project, with English and
+// German JSON files and an English XLIFF file, and returns its directory.
+func makeBackup(t *testing.T) string {
+	t.Helper()
+
+	mt := httpmock.NewMockTransport()
+	mockProject(t, mt, map[string][]byte{
+		"json": testutils.BuildZip(t,
+			testutils.ZipFile("en/app.json", `{"home.title":"Home"}`),
+			testutils.ZipFile("de/nested/app.json", `{"home.title":"Start"}`),
+		),
+		"xliff": testutils.BuildZip(t, testutils.ZipFile("en/app.xliff", `<xliff/>`)),
+	})
+	res, err := backup.NewManager(newTestClient(t, mt)).Backup(context.Background(), t.TempDir(), backup.Options{
+		Formats: []string{"json", "xliff"},
+	})
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	return res.Dir
+}
+
+// targetProject records what Restore sends to the project mockTarget
+// serves: English only, with the given keys, failing the upload of
+// failUpload.
+type targetProject struct {
+	mu      sync.Mutex
+	posts   []string
+	uploads []string // filename:lang_iso:content
+}
+
+func mockTarget(t *testing.T, mt *httpmock.MockTransport, keys string, failUpload string) *targetProject {
+	t.Helper()
+
+	tp := &targetProject{}
+	mt.RegisterResponder(http.MethodGet, "https://api.lokalise.com/api2/projects/proj",
+		httpmock.NewStringResponder(200, `{"project_id":"proj","name":"Copy","base_language_iso":"en","settings":{"branching":false}}`))
+	mt.RegisterResponder(http.MethodGet, `=~^`+apiBase+`languages`,
+		httpmock.NewStringResponder(200, `{"languages":[{"lang_iso":"en"}]}`))
+	mt.RegisterResponder(http.MethodGet, `=~^`+apiBase+`custom_translation_statuses`,
+		httpmock.NewStringResponder(200, `{"custom_translation_statuses":[]}`))
+	mt.RegisterResponder(http.MethodGet, `=~^`+apiBase+`webhooks`,
+		httpmock.NewStringResponder(200, `{"webhooks":[]}`))
+	mt.RegisterResponder(http.MethodGet, `=~^`+apiBase+`keys`,
+		httpmock.NewStringResponder(200, `{"keys":[`+keys+`]}`))
+
+	for _, res := range []string{"languages", "custom_translation_statuses", "webhooks"} {
+		mt.RegisterResponder(http.MethodPost, apiBase+res, func(r *http.Request) (*http.Response, error) {
+			tp.mu.Lock()
+			defer tp.mu.Unlock()
+			tp.posts = append(tp.posts, res)
+			return httpmock.NewStringResponse(200, `{}`), nil
+		})
+	}
+
+	mt.RegisterResponder(http.MethodPost, apiBase+"files/upload", func(r *http.Request) (*http.Response, error) {
+		var body struct {
+			Data     string `json:"data"`
+			Filename string `json:"filename"`
+			LangISO  string `json:"lang_iso"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return httpmock.NewStringResponse(400, err.Error()), nil
+		}
+		data, _ := base64.StdEncoding.DecodeString(body.Data)
+
+		tp.mu.Lock()
+		defer tp.mu.Unlock()
+		tp.uploads = append(tp.uploads, body.Filename+":"+body.LangISO+":"+string(data))
+		id := fmt.Sprintf("p%d", len(tp.uploads))
+		if body.Filename == failUpload {
+			id = "bad"
+		}
+		return httpmock.NewStringResponse(202, `{"process":{"process_id":"`+id+`","status":"queued"}}`), nil
+	})
+	mt.RegisterResponder(http.MethodGet, `=~^`+apiBase+`processes/`, func(r *http.Request) (*http.Response, error) {
+		id := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+		if id == "bad" {
+			return httpmock.NewStringResponse(200, `{"process":{"process_id":"bad","status":"failed","message":"invalid file"}}`), nil
+		}
+		return httpmock.NewStringResponse(200, `{"process":{"process_id":"`+id+`","status":"finished","details":{"files":[
+			{"name_original":"app.json","status":"finished","key_count_total":1,"key_count_inserted":1}]}}}`), nil
+	})
+	return tp
+}
+
+func TestManager_Restore(t *testing.T) {
+	t.Parallel()
+
+	dir := makeBackup(t)
+	mt := httpmock.NewMockTransport()
+	tp := mockTarget(t, mt, "", "")
+	m := backup.NewManager(newTestClient(t, mt))
+
+	dry, err := m.Restore(context.Background(), dir, backup.RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Restore(dry run) error = %v", err)
+	}
+	want := "+ language de\n" +
+		"+ status Legal\n" +
+		"^ nested/app.json (de)\n" +
+		"^ app.json (en)\n" +
+		"1 languages, 1 statuses, 2 files (json), 0 skipped"
+	if got := dry.Plan.String(); got != want {
+		t.Fatalf("plan =\n%s\nwant\n%s", got, want)
+	}
+	if len(dry.Files) != 0 || len(tp.posts) != 0 || len(tp.uploads) != 0 {
+		t.Fatalf("dry run changed the project: posts %v, uploads %v", tp.posts, tp.uploads)
+	}
+
+	var progress []string
+	res, err := m.Restore(context.Background(), dir, backup.RestoreOptions{
+		Params: map[string]any{"replace_modified": true},
+		Progress: func(p backup.RestoreProgress) {
+			progress = append(progress, fmt.Sprintf("%d/%d %s %v", p.Done, p.Total, p.Upload.Filename, p.Err))
+		},
+	})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if res.HasErrors() || len(res.Files) != 2 {
+		t.Fatalf("files = %+v, want 2 restored", res.Files)
+	}
+	if res.Files[0].ProcessID != "p1" || res.Files[0].Summary.Inserted != 1 {
+		t.Fatalf("first file = %+v", res.Files[0])
+	}
+	if want := []string{"languages", "custom_translation_statuses"}; !reflect.DeepEqual(tp.posts, want) {
+		t.Fatalf("posts = %v, want %v (no webhooks)", tp.posts, want)
+	}
+	wantUploads := []string{
+		`nested/app.json:de:{"home.title":"Start"}`,
+		`app.json:en:{"home.title":"Home"}`,
+	}
+	if !reflect.DeepEqual(tp.uploads, wantUploads) {
+		t.Fatalf("uploads = %q, want %q", tp.uploads, wantUploads)
+	}
+	if want := []string{"1/2 nested/app.json <nil>", "2/2 app.json <nil>"}; !reflect.DeepEqual(progress, want) {
+		t.Fatalf("progress = %q, want %q", progress, want)
+	}
+}
+
+func TestManager_Restore_FailedUploadContinues(t *testing.T) {
+	t.Parallel()
+
+	dir := makeBackup(t)
+	mt := httpmock.NewMockTransport()
+	tp := mockTarget(t, mt, "", "nested/app.json")
+
+	res, err := backup.NewManager(newTestClient(t, mt)).Restore(context.Background(), dir, backup.RestoreOptions{})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if !res.HasErrors() || res.Files[0].Err == nil || res.Files[1].Err != nil {
+		t.Fatalf("files = %+v, want only the first failed", res.Files)
+	}
+	if len(tp.uploads) != 2 {
+		t.Fatalf("uploads = %v, want both attempted", tp.uploads)
+	}
+}
+
+func TestManager_Restore_Format(t *testing.T) {
+	t.Parallel()
+
+	dir := makeBackup(t)
+	mt := httpmock.NewMockTransport()
+	mockTarget(t, mt, "", "")
+	m := backup.NewManager(newTestClient(t, mt))
+
+	res, err := m.Restore(context.Background(), dir, backup.RestoreOptions{Format: "xliff", DryRun: true})
+	if err != nil {
+		t.Fatalf("Restore(xliff) error = %v", err)
+	}
+	if want := []backup.RestoreUpload{{Path: "files/xliff/en/app.xliff", Filename: "app.xliff", LangISO: "en"}}; !reflect.DeepEqual(res.Plan.Uploads, want) {
+		t.Fatalf("uploads = %+v, want %+v", res.Plan.Uploads, want)
+	}
+
+	if _, err := m.Restore(context.Background(), dir, backup.RestoreOptions{Format: "yaml", DryRun: true}); err == nil {
+		t.Fatal("Restore(yaml) error = nil, want error for a format not in the backup")
+	}
+}
+
+func TestManager_Restore_NotEmpty(t *testing.T) {
+	t.Parallel()
+
+	dir := makeBackup(t)
+	mt := httpmock.NewMockTransport()
+	tp := mockTarget(t, mt, `{"key_id":1,"key_name":{"web":"x"}}`, "")
+	m := backup.NewManager(newTestClient(t, mt))
+
+	res, err := m.Restore(context.Background(), dir, backup.RestoreOptions{})
+	if !errors.Is(err, backup.ErrProjectNotEmpty) {
+		t.Fatalf("Restore() error = %v, want ErrProjectNotEmpty", err)
+	}
+	if len(res.Plan.Uploads) != 2 || len(tp.posts) != 0 || len(tp.uploads) != 0 {
+		t.Fatalf("result = %+v, posts %v, uploads %v; want the plan and no changes", res, tp.posts, tp.uploads)
+	}
+
+	if _, err := m.Restore(context.Background(), dir, backup.RestoreOptions{AllowNonEmpty: true, DryRun: true}); err != nil {
+		t.Fatalf("Restore(AllowNonEmpty) error = %v", err)
+	}
+}
+
+func TestManager_Restore_ChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	dir := makeBackup(t)
+	if err := os.WriteFile(filepath.Join(dir, "files", "json", "en", "app.json"), []byte(`{"home.title":"Tampered"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mt := httpmock.NewMockTransport()
+	tp := mockTarget(t, mt, "", "")
+
+	_, err := backup.NewManager(newTestClient(t, mt)).Restore(context.Background(), dir, backup.RestoreOptions{})
+	if err == nil || !strings.Contains(err.Error(), "files/json/en/app.json does not match its checksum") {
+		t.Fatalf("Restore() error = %v, want a checksum error", err)
+	}
+	if len(tp.posts) != 0 || len(tp.uploads) != 0 {
+		t.Fatalf("posts %v, uploads %v; want no changes", tp.posts, tp.uploads)
+	}
+}
+
+func TestManager_Restore_NoManifest(t *testing.T) {
+	t.Parallel()
+
+	m := backup.NewManager(newTestClient(t, httpmock.NewMockTransport()))
+	if _, err := m.Restore(context.Background(), t.TempDir(), backup.RestoreOptions{}); err == nil {
+		t.Fatal("Restore() error = nil, want error for a directory without a manifest")
+	}
+
+	var nilManager *backup.Manager
+	if _, err := nilManager.Restore(context.Background(), t.TempDir(), backup.RestoreOptions{}); err == nil {
+		t.Fatal("nil Manager Restore() error = nil, want error")
+	}
+}
+
+func TestManager_Restore_KeyMetadata(t *testing.T) {
+	t.Parallel()
+
+	dir := makeBackup(t)
+	mt := httpmock.NewMockTransport()
+	mockTarget(t, mt, `{"key_id":7,"key_name":{"web":"home.title"},"platforms":["web"]}`, "")
+	var update string
+	mt.RegisterResponder(http.MethodPut, apiBase+"keys", func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		update = strings.TrimSpace(string(b))
+		return httpmock.NewStringResponse(200, `{"keys":[{"key_id":7,"key_name":{"web":"home.title"}}]}`), nil
+	})
+
+	res, err := backup.NewManager(newTestClient(t, mt)).Restore(context.Background(), dir, backup.RestoreOptions{AllowNonEmpty: true})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if want := `{"keys":[{"key_id":7,"tags":["home"]}]}`; update != want {
+		t.Fatalf("key update = %s, want %s", update, want)
+	}
+	if res.KeysUpdated != 1 || !reflect.DeepEqual(res.KeysMissing, []string{"home.subtitle"}) {
+		t.Fatalf("keys updated %d, missing %v; want 1 and [home.subtitle]", res.KeysUpdated, res.KeysMissing)
+	}
+}
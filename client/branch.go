@@ -0,0 +1,38 @@
+package client
+
+import (
+	"errors"
+	"strings"
+)
+
+// branchSep separates the project ID from the branch name in Lokalise's
+// branch-scoped project IDs ("123.abc:feature-x").
+const branchSep = ":"
+
+// WithBranch points project-scoped requests at a branch of the project by
+// setting ProjectID to "<project ID>:<name>", the form Lokalise expects.
+// A branch already present in the project ID is replaced. The branch must
+// exist; see upload.WithBranchSync for creating it on demand.
+func WithBranch(name string) Option {
+	return func(c *Client) error {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.New("branch name cannot be empty")
+		}
+		c.ProjectID = c.BaseProjectID() + branchSep + name
+		return nil
+	}
+}
+
+// BaseProjectID returns ProjectID without its branch, for endpoints that
+// manage the branches themselves.
+func (c *Client) BaseProjectID() string {
+	id, _, _ := strings.Cut(c.ProjectID, branchSep)
+	return id
+}
+
+// Branch returns the branch ProjectID points at, or "" for the main branch.
+func (c *Client) Branch() string {
+	_, branch, _ := strings.Cut(c.ProjectID, branchSep)
+	return branch
+}
@@ -0,0 +1,54 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestWithBranch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		projectID  string
+		branch     string
+		wantID     string
+		wantBase   string
+		wantBranch string
+	}{
+		{"adds branch", "123.abc", "feature-x", "123.abc:feature-x", "123.abc", "feature-x"},
+		{"replaces branch", "123.abc:old", " new ", "123.abc:new", "123.abc", "new"},
+		{"slash in name", "123.abc", "feature/x", "123.abc:feature/x", "123.abc", "feature/x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := client.NewClient("tok", tt.projectID, client.WithBranch(tt.branch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.ProjectID != tt.wantID || c.BaseProjectID() != tt.wantBase || c.Branch() != tt.wantBranch {
+				t.Fatalf("ProjectID=%q base=%q branch=%q", c.ProjectID, c.BaseProjectID(), c.Branch())
+			}
+		})
+	}
+}
+
+func TestWithBranch_Empty(t *testing.T) {
+	t.Parallel()
+	if _, err := client.NewClient("tok", "123.abc", client.WithBranch("  ")); err == nil {
+		t.Fatal("expected error for empty branch")
+	}
+}
+
+func TestBranch_NoBranch(t *testing.T) {
+	t.Parallel()
+	c, err := client.NewClient("tok", "123.abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Branch() != "" || c.BaseProjectID() != "123.abc" {
+		t.Fatalf("branch=%q base=%q", c.Branch(), c.BaseProjectID())
+	}
+}
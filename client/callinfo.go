@@ -0,0 +1,110 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrProjectMismatch is matched (via errors.Is) by CallInfo.Verify errors.
+var ErrProjectMismatch = errors.New("lokex: response from another project or branch")
+
+// CallInfo collects the project context Lokalise echoes in its responses
+// (top-level project_id and branch), for tooling that works on many
+// projects and wants to double-check it touched the intended one. Fill it
+// with WithCallInfo. It is safe for concurrent use; don't copy it once used.
+type CallInfo struct {
+	mu        sync.Mutex
+	responses int
+	projects  []string // distinct project_id values, in order seen
+	branches  []string // distinct branch values, in order seen
+}
+
+// WithCallInfo records the project_id and branch of every successful API
+// response of the call into info. info must be non-nil. Recording keeps a
+// copy of each response body until it is decoded.
+func WithCallInfo(info *CallInfo) RequestOption {
+	return func(c *Client) {
+		if info != nil {
+			c.callInfo = info
+		}
+	}
+}
+
+// envelopeHook returns the transport's envelope hook, or nil without
+// WithCallInfo.
+func (c *Client) envelopeHook() func(projectID, branch string) {
+	if c.callInfo == nil {
+		return nil
+	}
+	return c.callInfo.observe
+}
+
+// observe records one response.
+func (ci *CallInfo) observe(projectID, branch string) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.responses++
+	if projectID != "" && !slices.Contains(ci.projects, projectID) {
+		ci.projects = append(ci.projects, projectID)
+	}
+	if branch != "" && !slices.Contains(ci.branches, branch) {
+		ci.branches = append(ci.branches, branch)
+	}
+}
+
+// Responses returns the number of successful API responses seen.
+func (ci *CallInfo) Responses() int {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	return ci.responses
+}
+
+// ProjectID returns the project_id the responses carried, or "" if none
+// did. If they named several projects, it returns the first.
+func (ci *CallInfo) ProjectID() string {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if len(ci.projects) == 0 {
+		return ""
+	}
+	return ci.projects[0]
+}
+
+// Branch returns the branch the responses carried, or "" if none did.
+func (ci *CallInfo) Branch() string {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if len(ci.branches) == 0 {
+		return ""
+	}
+	return ci.branches[0]
+}
+
+// Verify checks the responses against projectID, which may carry a branch
+// as in "<project ID>:<branch>" (the form of Client.ProjectID). Responses
+// without a project_id or branch can't contradict it; a response naming
+// another project, or another branch when projectID names one, is an
+// ErrProjectMismatch.
+func (ci *CallInfo) Verify(projectID string) error {
+	wantID, wantBranch, _ := strings.Cut(strings.TrimSpace(projectID), branchSep)
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	for _, p := range ci.projects {
+		// Responses may name a branch in project_id too.
+		if id, _, _ := strings.Cut(p, branchSep); id != wantID {
+			return fmt.Errorf("%w: got project %s, want %s", ErrProjectMismatch, p, wantID)
+		}
+	}
+	if wantBranch != "" {
+		for _, b := range ci.branches {
+			if b != wantBranch {
+				return fmt.Errorf("%w: got branch %s, want %s", ErrProjectMismatch, b, wantBranch)
+			}
+		}
+	}
+	return nil
+}
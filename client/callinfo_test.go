@@ -0,0 +1,108 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestWithCallInfo(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/projects/p1/keys":
+			_, _ = w.Write([]byte(`{"project_id":"p1","branch":"feature","keys":[]}`))
+		case "/projects":
+			_, _ = w.Write([]byte(`{"projects":[]}`))
+		default:
+			_, _ = w.Write([]byte(`[1,2]`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("token", "p1:feature", client.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var info client.CallInfo
+	var out struct {
+		Keys []any `json:"keys"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "projects/p1/keys", nil, &out, client.WithCallInfo(&info)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Do(ctx, http.MethodGet, "projects", nil, nil, client.WithCallInfo(&info)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Do(ctx, http.MethodGet, "other", nil, nil, client.WithCallInfo(&info)); err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Responses() != 3 || info.ProjectID() != "p1" || info.Branch() != "feature" {
+		t.Fatalf("info = %d responses, project %q, branch %q", info.Responses(), info.ProjectID(), info.Branch())
+	}
+	if err := info.Verify(c.ProjectID); err != nil {
+		t.Fatalf("Verify(%q) = %v", c.ProjectID, err)
+	}
+	if err := info.Verify("p1"); err != nil {
+		t.Fatalf("Verify(p1) = %v, want nil (no branch to check)", err)
+	}
+	if err := info.Verify("p2"); !errors.Is(err, client.ErrProjectMismatch) {
+		t.Fatalf("Verify(p2) = %v, want ErrProjectMismatch", err)
+	}
+	if err := info.Verify("p1:main"); !errors.Is(err, client.ErrProjectMismatch) {
+		t.Fatalf("Verify(p1:main) = %v, want ErrProjectMismatch", err)
+	}
+
+	// Calls without the option record nothing.
+	if err := c.Do(ctx, http.MethodGet, "projects/p1/keys", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if info.Responses() != 3 {
+		t.Fatalf("Responses() = %d after a call without WithCallInfo", info.Responses())
+	}
+}
+
+func TestCallInfo_SeveralProjects(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"project_id":"` + r.URL.Query().Get("id") + `"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("token", "a", client.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info client.CallInfo
+	c = c.WithRequestOptions(client.WithCallInfo(&info))
+	for _, id := range []string{"a", "b:dev", "a"} {
+		if err := c.Do(context.Background(), http.MethodGet, "x?id="+id, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if info.ProjectID() != "a" {
+		t.Fatalf("ProjectID() = %q, want the first one", info.ProjectID())
+	}
+	if err := info.Verify("a"); !errors.Is(err, client.ErrProjectMismatch) {
+		t.Fatalf("Verify(a) = %v, want ErrProjectMismatch for b", err)
+	}
+}
+
+func TestCallInfo_Empty(t *testing.T) {
+	t.Parallel()
+
+	var info client.CallInfo
+	if info.Responses() != 0 || info.ProjectID() != "" || info.Branch() != "" || info.Verify("x") != nil {
+		t.Fatal("zero CallInfo not empty")
+	}
+}
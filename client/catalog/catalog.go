@@ -0,0 +1,187 @@
+// Package catalog loads downloaded JSON bundles into an in-memory message
+// catalog for runtime lookups. Adapters in the xtext and goi18n subpackages
+// register a Catalog with golang.org/x/text/message or nicksnyder/go-i18n.
+//
+// Nested JSON objects are flattened with "." (e.g. {"home":{"title":"Hi"}}
+// becomes "home.title"). An object whose keys are all CLDR plural categories
+// (zero, one, two, few, many, other) and include "other" is a plural message.
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Message is one translated message.
+type Message struct {
+	ID      string
+	Text    string            // the message, or the "other" form for plurals
+	Plurals map[string]string // CLDR category -> text; nil for plain messages
+}
+
+// Catalog holds messages per language. It is safe for concurrent use;
+// Reload swaps contents atomically so lookups never see a partial load.
+type Catalog struct {
+	mu    sync.RWMutex
+	langs map[string]map[string]Message
+}
+
+// New returns an empty catalog.
+func New() *Catalog {
+	return &Catalog{langs: make(map[string]map[string]Message)}
+}
+
+// LoadDir loads every .json file under dir. The language of a file is its
+// base name for files directly in dir (dir/en.json) and the first directory
+// below dir otherwise (dir/en/app.json, dir/fr_CA/nested/x.json). Messages
+// from several files of one language are merged.
+func LoadDir(dir string) (*Catalog, error) {
+	c := New()
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".json") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		lang := strings.TrimSuffix(parts[0], filepath.Ext(parts[0]))
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if err := c.AddJSON(lang, data); err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("catalog: load %s: %w", dir, err)
+	}
+	return c, nil
+}
+
+// AddJSON merges a JSON bundle for lang into the catalog.
+func (c *Catalog) AddJSON(lang string, data []byte) error {
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		return errors.New("catalog: empty language")
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("catalog: %s: %w", lang, err)
+	}
+
+	msgs := make(map[string]Message)
+	if err := flatten("", root, msgs); err != nil {
+		return fmt.Errorf("catalog: %s: %w", lang, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.langs[lang] == nil {
+		c.langs[lang] = make(map[string]Message, len(msgs))
+	}
+	maps.Copy(c.langs[lang], msgs)
+	return nil
+}
+
+var pluralCategories = map[string]bool{
+	"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true,
+}
+
+func flatten(prefix string, node map[string]any, out map[string]Message) error {
+	for k, v := range node {
+		id := k
+		if prefix != "" {
+			id = prefix + "." + k
+		}
+
+		switch val := v.(type) {
+		case string:
+			out[id] = Message{ID: id, Text: val}
+		case map[string]any:
+			if forms, ok := pluralForms(val); ok {
+				out[id] = Message{ID: id, Text: forms["other"], Plurals: forms}
+				continue
+			}
+			if err := flatten(id, val, out); err != nil {
+				return err
+			}
+		case nil:
+			// Untranslated keys may be exported as null; skip them.
+		default:
+			return fmt.Errorf("key %q: unsupported value %T", id, v)
+		}
+	}
+	return nil
+}
+
+func pluralForms(m map[string]any) (map[string]string, bool) {
+	if _, ok := m["other"]; !ok {
+		return nil, false
+	}
+	forms := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok || !pluralCategories[k] {
+			return nil, false
+		}
+		forms[k] = s
+	}
+	return forms, true
+}
+
+// Reload loads dir and, on success, replaces the catalog's contents with it.
+// On error the current contents are kept.
+func (c *Catalog) Reload(dir string) error {
+	next, err := LoadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.langs = next.langs
+	return nil
+}
+
+// Lookup returns the message id in lang.
+func (c *Catalog) Lookup(lang, id string) (Message, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m, ok := c.langs[lang][id]
+	return m, ok
+}
+
+// Languages returns the loaded languages, sorted.
+func (c *Catalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.langs))
+}
+
+// Messages returns the messages of lang sorted by ID.
+func (c *Catalog) Messages(lang string) []Message {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := slices.Collect(maps.Values(c.langs[lang]))
+	slices.SortFunc(out, func(a, b Message) int { return strings.Compare(a.ID, b.ID) })
+	return out
+}
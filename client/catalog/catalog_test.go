@@ -0,0 +1,120 @@
+package catalog_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/catalog"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.json"), `{"home":{"title":"Home","items":{"one":"%d item","other":"%d items"}},"missing":null}`)
+	writeFile(t, filepath.Join(dir, "fr_CA", "app.json"), `{"home.title":"Accueil"}`)
+	writeFile(t, filepath.Join(dir, "fr_CA", "nested", "more.json"), `{"bye":"Salut"}`)
+	writeFile(t, filepath.Join(dir, "README.txt"), `ignored`)
+
+	c, err := catalog.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+
+	if got, want := c.Languages(), []string{"en", "fr_CA"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Languages() = %v, want %v", got, want)
+	}
+
+	wantEN := []catalog.Message{
+		{ID: "home.items", Text: "%d items", Plurals: map[string]string{"one": "%d item", "other": "%d items"}},
+		{ID: "home.title", Text: "Home"},
+	}
+	if got := c.Messages("en"); !reflect.DeepEqual(got, wantEN) {
+		t.Fatalf("Messages(en) = %+v, want %+v", got, wantEN)
+	}
+
+	if m, ok := c.Lookup("fr_CA", "bye"); !ok || m.Text != "Salut" {
+		t.Fatalf("Lookup(fr_CA, bye) = %+v, %v", m, ok)
+	}
+	if _, ok := c.Lookup("de", "home.title"); ok {
+		t.Fatal("Lookup(de) found a message")
+	}
+}
+
+func TestAddJSON_NonPluralObjectsAreNested(t *testing.T) {
+	t.Parallel()
+
+	c := catalog.New()
+	// "other" alongside a non-category key is an ordinary nested object.
+	if err := c.AddJSON("en", []byte(`{"menu":{"other":"More","settings":"Settings"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := c.Lookup("en", "menu.other"); !ok || m.Text != "More" || m.Plurals != nil {
+		t.Fatalf("Lookup(menu.other) = %+v, %v", m, ok)
+	}
+}
+
+func TestAddJSON_Errors(t *testing.T) {
+	t.Parallel()
+
+	c := catalog.New()
+	tests := []struct {
+		lang, data, want string
+	}{
+		{lang: " ", data: `{}`, want: "empty language"},
+		{lang: "en", data: `[`, want: "catalog: en:"},
+		{lang: "en", data: `{"n":1}`, want: `key "n": unsupported value float64`},
+	}
+	for _, tt := range tests {
+		err := c.AddJSON(tt.lang, []byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("AddJSON(%q, %s) error = %v, want %q", tt.lang, tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestCatalog_Reload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.json"), `{"a":"one"}`)
+
+	c, err := catalog.LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(dir, "en.json"), `{"a":"two"}`)
+	if err := c.Reload(dir); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if m, _ := c.Lookup("en", "a"); m.Text != "two" {
+		t.Fatalf("after Reload: %q, want two", m.Text)
+	}
+
+	writeFile(t, filepath.Join(dir, "en.json"), `{broken`)
+	if err := c.Reload(dir); err == nil {
+		t.Fatal("Reload(broken) error = nil")
+	}
+	if m, _ := c.Lookup("en", "a"); m.Text != "two" {
+		t.Fatalf("failed Reload changed contents: %q", m.Text)
+	}
+
+	if _, err := catalog.LoadDir(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("LoadDir(missing) error = nil")
+	}
+}
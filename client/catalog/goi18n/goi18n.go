@@ -0,0 +1,44 @@
+// Package goi18n registers a lokex catalog with nicksnyder/go-i18n.
+//
+// Messages are registered verbatim, so export bundles with go-i18n style
+// template placeholders (e.g. {{.Count}}) for Localizer to fill them.
+package goi18n
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+
+	"github.com/bodrovis/lokex/v2/client/catalog"
+)
+
+// Register adds every message in c to b. Lokalise ISO codes such as "pt_BR"
+// are mapped to BCP 47 tags ("pt-BR").
+func Register(b *i18n.Bundle, c *catalog.Catalog) error {
+	for _, lang := range c.Languages() {
+		tag, err := language.Parse(strings.ReplaceAll(lang, "_", "-"))
+		if err != nil {
+			return fmt.Errorf("goi18n: language %q: %w", lang, err)
+		}
+
+		msgs := c.Messages(lang)
+		out := make([]*i18n.Message, 0, len(msgs))
+		for _, m := range msgs {
+			im := &i18n.Message{ID: m.ID, Other: m.Text}
+			if m.Plurals != nil {
+				im.Zero = m.Plurals["zero"]
+				im.One = m.Plurals["one"]
+				im.Two = m.Plurals["two"]
+				im.Few = m.Plurals["few"]
+				im.Many = m.Plurals["many"]
+			}
+			out = append(out, im)
+		}
+		if err := b.AddMessages(tag, out...); err != nil {
+			return fmt.Errorf("goi18n: %s: %w", lang, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package goi18n_test
+
+import (
+	"testing"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+
+	"github.com/bodrovis/lokex/v2/client/catalog"
+	"github.com/bodrovis/lokex/v2/client/catalog/goi18n"
+)
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	c := catalog.New()
+	err := c.AddJSON("en", []byte(`{"hello":"Hello, {{.Name}}!","files":{"one":"{{.Count}} file","other":"{{.Count}} files"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := i18n.NewBundle(language.English)
+	if err := goi18n.Register(b, c); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	loc := i18n.NewLocalizer(b, "en")
+	got, err := loc.Localize(&i18n.LocalizeConfig{MessageID: "hello", TemplateData: map[string]string{"Name": "Ann"}})
+	if err != nil || got != "Hello, Ann!" {
+		t.Errorf("hello = %q, %v", got, err)
+	}
+
+	for n, want := range map[int]string{1: "1 file", 4: "4 files"} {
+		got, err := loc.Localize(&i18n.LocalizeConfig{MessageID: "files", PluralCount: n, TemplateData: map[string]int{"Count": n}})
+		if err != nil || got != want {
+			t.Errorf("files(%d) = %q, %v, want %q", n, got, err, want)
+		}
+	}
+}
+
+func TestRegister_BadLanguage(t *testing.T) {
+	t.Parallel()
+
+	c := catalog.New()
+	if err := c.AddJSON("not a tag!", []byte(`{"a":"b"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := goi18n.Register(i18n.NewBundle(language.English), c); err == nil {
+		t.Fatal("Register() error = nil for invalid language")
+	}
+}
@@ -0,0 +1,248 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ChangeEvent describes files (relative to the watched directory, with "/"
+// separators) that changed since the previous event.
+type ChangeEvent struct {
+	Added    []string
+	Modified []string
+	Removed  []string
+}
+
+func (e ChangeEvent) empty() bool {
+	return len(e.Added) == 0 && len(e.Modified) == 0 && len(e.Removed) == 0
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher polls a directory (typically a download destination) and reports
+// when its files change, so an application can reload catalogs after a pull
+// without restarting. A burst of writes, such as a bundle being extracted,
+// is reported as one event once the directory stops changing.
+type Watcher struct {
+	dir      string
+	interval time.Duration
+	ch       chan ChangeEvent
+
+	// scanMu serializes scans, so that concurrent Check, Flush and Run
+	// calls never replace a newer state with an older one.
+	scanMu  sync.Mutex
+	mu      sync.Mutex
+	state   map[string]fileState
+	pending ChangeEvent
+	onEvent []func(ChangeEvent)
+}
+
+// NewWatcher returns a watcher for dir that scans every interval. The
+// current contents of dir are the baseline; only later changes are reported.
+func NewWatcher(dir string, interval time.Duration) (*Watcher, error) {
+	if interval <= 0 {
+		return nil, errors.New("catalog: watch: interval must be positive")
+	}
+	state, err := scanDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: watch: %w", err)
+	}
+	return &Watcher{dir: dir, interval: interval, ch: make(chan ChangeEvent, 1), state: state}, nil
+}
+
+// Changes returns a channel receiving change events. If an event has not
+// been received when the next one is ready, the two are merged.
+func (w *Watcher) Changes() <-chan ChangeEvent {
+	return w.ch
+}
+
+// OnChange registers fn to be called for every change event, from the
+// goroutine whose scan emitted it (Run's, or a Check or Flush caller's).
+func (w *Watcher) OnChange(fn func(ChangeEvent)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onEvent = append(w.onEvent, fn)
+}
+
+// Run scans the directory until ctx is done and returns ctx.Err(). Scan
+// errors (e.g. the directory being replaced mid-pull) are retried on the
+// next tick.
+func (w *Watcher) Run(ctx context.Context) error {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			_, _, _ = w.Check()
+		}
+	}
+}
+
+// Check scans the directory once, as Run does on every tick. Changes are
+// accumulated until a scan finds nothing new; that scan emits the
+// accumulated event and returns it with true. The first Check after a pull
+// therefore only records the changes, and a later one reports them; use
+// Flush when the pull is known to be complete.
+func (w *Watcher) Check() (ChangeEvent, bool, error) {
+	return w.scan(false)
+}
+
+// Flush scans the directory once and emits everything changed since the
+// last event, without waiting for the directory to settle. Call it right
+// after a pull to report its changes without waiting for Run's next ticks.
+func (w *Watcher) Flush() (ChangeEvent, bool, error) {
+	return w.scan(true)
+}
+
+func (w *Watcher) scan(flush bool) (ChangeEvent, bool, error) {
+	w.scanMu.Lock()
+	next, err := scanDir(w.dir)
+	if err != nil {
+		w.scanMu.Unlock()
+		return ChangeEvent{}, false, fmt.Errorf("catalog: watch: %w", err)
+	}
+
+	w.mu.Lock()
+	diff := diffStates(w.state, next)
+	w.state = next
+	w.pending = mergeEvents(w.pending, diff)
+	if !diff.empty() && !flush {
+		w.mu.Unlock()
+		w.scanMu.Unlock()
+		return ChangeEvent{}, false, nil
+	}
+	ev := w.pending
+	w.pending = ChangeEvent{}
+	handlers := slices.Clone(w.onEvent)
+	w.mu.Unlock()
+	// Handlers run without the locks, so they may call Check themselves.
+	w.scanMu.Unlock()
+
+	if ev.empty() {
+		return ChangeEvent{}, false, nil
+	}
+
+	w.send(ev)
+	for _, fn := range handlers {
+		fn(ev)
+	}
+	return ev, true, nil
+}
+
+func (w *Watcher) send(ev ChangeEvent) {
+	select {
+	case w.ch <- ev:
+		return
+	default:
+	}
+	select {
+	case old := <-w.ch:
+		ev = mergeEvents(old, ev)
+	default:
+	}
+	select {
+	case w.ch <- ev:
+	default:
+	}
+}
+
+func scanDir(dir string) (map[string]fileState, error) {
+	out := make(map[string]fileState)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		out[filepath.ToSlash(rel)] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return out, err
+}
+
+func diffStates(prev, next map[string]fileState) ChangeEvent {
+	var ev ChangeEvent
+	for p, st := range next {
+		old, ok := prev[p]
+		switch {
+		case !ok:
+			ev.Added = append(ev.Added, p)
+		case old != st:
+			ev.Modified = append(ev.Modified, p)
+		}
+	}
+	for p := range prev {
+		if _, ok := next[p]; !ok {
+			ev.Removed = append(ev.Removed, p)
+		}
+	}
+	slices.Sort(ev.Added)
+	slices.Sort(ev.Modified)
+	slices.Sort(ev.Removed)
+	return ev
+}
+
+// mergeEvents combines two consecutive events into the net change.
+func mergeEvents(a, b ChangeEvent) ChangeEvent {
+	if a.empty() {
+		return b
+	}
+
+	state := make(map[string]string) // path -> "added" | "modified" | "removed"
+	for _, e := range []ChangeEvent{a, b} {
+		for _, p := range e.Added {
+			if state[p] == "removed" {
+				state[p] = "modified"
+			} else {
+				state[p] = "added"
+			}
+		}
+		for _, p := range e.Modified {
+			if state[p] != "added" {
+				state[p] = "modified"
+			}
+		}
+		for _, p := range e.Removed {
+			if state[p] == "added" {
+				delete(state, p)
+			} else {
+				state[p] = "removed"
+			}
+		}
+	}
+
+	var out ChangeEvent
+	for _, p := range slices.Sorted(maps.Keys(state)) {
+		switch state[p] {
+		case "added":
+			out.Added = append(out.Added, p)
+		case "modified":
+			out.Modified = append(out.Modified, p)
+		case "removed":
+			out.Removed = append(out.Removed, p)
+		}
+	}
+	return out
+}
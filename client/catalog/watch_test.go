@@ -0,0 +1,218 @@
+package catalog_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/catalog"
+)
+
+func TestWatcher_Check_SettlesBeforeEmitting(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.json"), `{"a":"1"}`)
+	writeFile(t, filepath.Join(dir, "old.json"), `{}`)
+
+	w, err := catalog.NewWatcher(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	var got []catalog.ChangeEvent
+	w.OnChange(func(ev catalog.ChangeEvent) { got = append(got, ev) })
+
+	if _, emitted, err := w.Check(); emitted || err != nil {
+		t.Fatalf("Check() with no changes = %v, %v", emitted, err)
+	}
+
+	// A pull in progress: two scans see changes, the third sees none.
+	writeFile(t, filepath.Join(dir, "en.json"), `{"a":"changed"}`)
+	writeFile(t, filepath.Join(dir, "fr", "app.json"), `{}`)
+	if _, emitted, _ := w.Check(); emitted {
+		t.Fatal("emitted while still changing")
+	}
+	if err := os.Remove(filepath.Join(dir, "old.json")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "tmp.json"), `{}`)
+	if err := os.Remove(filepath.Join(dir, "tmp.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, emitted, _ := w.Check(); emitted {
+		t.Fatal("emitted while still changing")
+	}
+
+	ev, emitted, err := w.Check()
+	if err != nil || !emitted {
+		t.Fatalf("Check() after settling = %v, %v; want event", emitted, err)
+	}
+	want := catalog.ChangeEvent{
+		Added:    []string{"fr/app.json"},
+		Modified: []string{"en.json"},
+		Removed:  []string{"old.json"},
+	}
+	if !reflect.DeepEqual(ev, want) {
+		t.Fatalf("event = %+v, want %+v", ev, want)
+	}
+	if !reflect.DeepEqual(got, []catalog.ChangeEvent{want}) {
+		t.Fatalf("callbacks = %+v, want one event", got)
+	}
+	select {
+	case chEv := <-w.Changes():
+		if !reflect.DeepEqual(chEv, want) {
+			t.Fatalf("channel event = %+v, want %+v", chEv, want)
+		}
+	default:
+		t.Fatal("no event on Changes()")
+	}
+
+	if _, emitted, _ := w.Check(); emitted {
+		t.Fatal("emitted again without changes")
+	}
+}
+
+func TestWatcher_UnreadEventsAreMerged(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.json"), `{}`)
+	w, err := catalog.NewWatcher(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(dir, "de.json"), `{}`)
+	_, _, _ = w.Check()
+	_, _, _ = w.Check()
+
+	writeFile(t, filepath.Join(dir, "en.json"), `{"x":"y"}`)
+	if err := os.Remove(filepath.Join(dir, "de.json")); err != nil {
+		t.Fatal(err)
+	}
+	_, _, _ = w.Check()
+	_, _, _ = w.Check()
+
+	want := catalog.ChangeEvent{Modified: []string{"en.json"}}
+	if got := <-w.Changes(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("merged event = %+v, want %+v", got, want)
+	}
+}
+
+func TestWatcher_Flush(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w, err := catalog.NewWatcher(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(dir, "en.json"), `{}`)
+	if _, emitted, _ := w.Check(); emitted {
+		t.Fatal("Check() emitted before the directory settled")
+	}
+	writeFile(t, filepath.Join(dir, "de.json"), `{}`)
+
+	ev, emitted, err := w.Flush()
+	if err != nil || !emitted {
+		t.Fatalf("Flush() = %v, %v; want event", emitted, err)
+	}
+	if want := []string{"de.json", "en.json"}; !reflect.DeepEqual(ev.Added, want) {
+		t.Fatalf("event = %+v, want added %v", ev, want)
+	}
+	if _, emitted, _ := w.Flush(); emitted {
+		t.Fatal("Flush() emitted again without changes")
+	}
+}
+
+func TestWatcher_ConcurrentChecks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w, err := catalog.NewWatcher(dir, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	w.OnChange(func(ev catalog.ChangeEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range ev.Added {
+			seen[p] = true
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Go(func() {
+			for j := range 10 {
+				writeFile(t, filepath.Join(dir, fmt.Sprintf("%d-%d.json", i, j)), `{}`)
+				_, _, _ = w.Check()
+			}
+		})
+	}
+	wg.Wait()
+	cancel()
+	<-done
+	_, _, _ = w.Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 40 {
+		t.Fatalf("reported %d added files, want 40", len(seen))
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w, err := catalog.NewWatcher(dir, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	writeFile(t, filepath.Join(dir, "en.json"), `{}`)
+
+	select {
+	case ev := <-w.Changes():
+		if !reflect.DeepEqual(ev.Added, []string{"en.json"}) {
+			t.Fatalf("event = %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event from Run")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() = %v, want context.Canceled", err)
+	}
+}
+
+func TestNewWatcher_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := catalog.NewWatcher(t.TempDir(), 0); err == nil {
+		t.Fatal("zero interval: error = nil")
+	}
+	if _, err := catalog.NewWatcher(filepath.Join(t.TempDir(), "missing"), time.Second); err == nil {
+		t.Fatal("missing dir: error = nil")
+	}
+}
@@ -0,0 +1,60 @@
+// Package xtext registers a lokex catalog with golang.org/x/text/message.
+//
+// Messages are registered verbatim, so export bundles with printf-style
+// placeholders (placeholder_format=printf) for message.Printer to fill them.
+package xtext
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	xcatalog "golang.org/x/text/message/catalog"
+
+	"github.com/bodrovis/lokex/v2/client/catalog"
+)
+
+// pluralOrder lists CLDR categories in the order passed to plural.Selectf.
+var pluralOrder = []string{"zero", "one", "two", "few", "many", "other"}
+
+// Register adds every message in c to b. Plural messages select on the
+// first argument. Lokalise ISO codes such as "pt_BR" are mapped to BCP 47
+// tags ("pt-BR").
+func Register(b *xcatalog.Builder, c *catalog.Catalog) error {
+	for _, lang := range c.Languages() {
+		tag, err := Tag(lang)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range c.Messages(lang) {
+			if m.Plurals == nil {
+				if err := b.SetString(tag, m.ID, m.Text); err != nil {
+					return fmt.Errorf("xtext: %s %q: %w", lang, m.ID, err)
+				}
+				continue
+			}
+
+			var cases []any
+			for _, cat := range pluralOrder {
+				if text, ok := m.Plurals[cat]; ok {
+					cases = append(cases, cat, text)
+				}
+			}
+			if err := b.Set(tag, m.ID, plural.Selectf(1, "", cases...)); err != nil {
+				return fmt.Errorf("xtext: %s %q: %w", lang, m.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Tag converts a Lokalise language ISO code to a language.Tag.
+func Tag(lang string) (language.Tag, error) {
+	tag, err := language.Parse(strings.ReplaceAll(lang, "_", "-"))
+	if err != nil {
+		return language.Und, fmt.Errorf("xtext: language %q: %w", lang, err)
+	}
+	return tag, nil
+}
@@ -0,0 +1,56 @@
+package xtext_test
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	xcatalog "golang.org/x/text/message/catalog"
+
+	"github.com/bodrovis/lokex/v2/client/catalog"
+	"github.com/bodrovis/lokex/v2/client/catalog/xtext"
+)
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	c := catalog.New()
+	if err := c.AddJSON("en", []byte(`{"hello":"Hello, %s!","files":{"one":"%d file","other":"%d files"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddJSON("pt_BR", []byte(`{"hello":"Olá, %s!"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	b := xcatalog.NewBuilder()
+	if err := xtext.Register(b, c); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	en := message.NewPrinter(language.English, message.Catalog(b))
+	if got := en.Sprintf("hello", "Ann"); got != "Hello, Ann!" {
+		t.Errorf("en hello = %q", got)
+	}
+	if got := en.Sprintf("files", 1); got != "1 file" {
+		t.Errorf("en files(1) = %q", got)
+	}
+	if got := en.Sprintf("files", 3); got != "3 files" {
+		t.Errorf("en files(3) = %q", got)
+	}
+
+	pt := message.NewPrinter(language.MustParse("pt-BR"), message.Catalog(b))
+	if got := pt.Sprintf("hello", "Ana"); got != "Olá, Ana!" {
+		t.Errorf("pt-BR hello = %q", got)
+	}
+}
+
+func TestTag(t *testing.T) {
+	t.Parallel()
+
+	if tag, err := xtext.Tag("zh_Hans_CN"); err != nil || tag.String() != "zh-Hans-CN" {
+		t.Fatalf("Tag(zh_Hans_CN) = %v, %v", tag, err)
+	}
+	if _, err := xtext.Tag("not a tag!"); err == nil {
+		t.Fatal("Tag(invalid) error = nil")
+	}
+}
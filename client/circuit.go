@@ -0,0 +1,36 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/circuit"
+)
+
+// ErrCircuitOpen is matched (via errors.Is) by the error of an API request
+// rejected, without being sent, by an open circuit breaker; see
+// WithCircuitBreaker. It is never retried.
+var ErrCircuitOpen = circuit.ErrOpen
+
+// WithCircuitBreaker stops the client from hammering an API that is down.
+// After threshold consecutive failed API requests (5xx responses, or no
+// response at all: timeouts, refused connections) the breaker opens, and
+// every API request fails with ErrCircuitOpen until cooldown has passed.
+// Then one probe request is let through: if it succeeds the breaker
+// closes, otherwise it stays open for another cooldown. Retries count as
+// requests, so an open breaker also ends retry loops early.
+//
+// The breaker is shared by every goroutine using the client and its
+// ForProject copies; its state is in Health.Circuit. Bundle downloads,
+// which don't go to the API, are not affected. threshold must be at least
+// 1 and cooldown positive.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) error {
+		b, err := circuit.New(threshold, cooldown)
+		if err != nil {
+			return fmt.Errorf("circuit breaker: %w", err)
+		}
+		c.breaker = b
+		return nil
+	}
+}
@@ -0,0 +1,146 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestWithCircuitBreaker_Validates(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("tok", "p", client.WithCircuitBreaker(0, time.Second)); err == nil {
+		t.Fatal("WithCircuitBreaker(0, 1s) error = nil, want error")
+	}
+	if _, err := client.NewClient("tok", "p", client.WithCircuitBreaker(1, 0)); err == nil {
+		t.Fatal("WithCircuitBreaker(1, 0) error = nil, want error")
+	}
+
+	c, err := client.NewClient("tok", "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Health().Circuit; got != "" {
+		t.Fatalf("Health().Circuit without breaker = %q, want empty", got)
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var down atomic.Bool
+	down.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"message":"down","code":503}}`))
+			return
+		}
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"not found","code":404}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	const cooldown = 50 * time.Millisecond
+	c, err := client.NewClient("tok", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(5),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+		client.WithCircuitBreaker(3, cooldown),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// The third failed attempt opens the breaker, which ends the retries.
+	err = c.DoJSONWithRetry(ctx, http.MethodGet, "projects", nil, nil)
+	if !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("DoJSONWithRetry() error = %v, want ErrCircuitOpen", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("requests sent = %d, want 3", n)
+	}
+	if got := c.Health().Circuit; got != "open" {
+		t.Fatalf("Health().Circuit = %q, want open", got)
+	}
+
+	// ForProject copies share the breaker; nothing is sent while it's open.
+	if err := c.ForProject("other").DoJSONWithRetry(ctx, http.MethodGet, "projects", nil, nil); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("ForProject() call error = %v, want ErrCircuitOpen", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("requests sent while open = %d, want 3", n)
+	}
+
+	// After the cooldown a failed probe reopens it.
+	time.Sleep(cooldown + 10*time.Millisecond)
+	if got := c.Health().Circuit; got != "half-open" {
+		t.Fatalf("Health().Circuit after cooldown = %q, want half-open", got)
+	}
+	if err := c.DoJSONWithRetry(ctx, http.MethodGet, "projects", nil, nil); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("DoJSONWithRetry() after failed probe error = %v, want ErrCircuitOpen", err)
+	}
+	if n := calls.Load(); n != 4 {
+		t.Fatalf("requests sent = %d, want 4 (one probe)", n)
+	}
+
+	// Once the API is back, a successful probe closes it; a 4xx response
+	// shows the API is up too.
+	down.Store(false)
+	time.Sleep(cooldown + 10*time.Millisecond)
+	var apiErr *client.APIError
+	if err := c.DoJSONWithRetry(ctx, http.MethodGet, "missing", nil, nil); !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		t.Fatalf("DoJSONWithRetry() probe error = %v, want 404 APIError", err)
+	}
+	if got := c.Health().Circuit; got != "closed" {
+		t.Fatalf("Health().Circuit after probe = %q, want closed", got)
+	}
+	if err := c.DoJSONWithRetry(ctx, http.MethodGet, "projects", nil, nil); err != nil {
+		t.Fatalf("DoJSONWithRetry() error = %v", err)
+	}
+}
+
+func TestWithCircuitBreaker_CanceledCallsDontCount(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	c, err := client.NewClient("tok", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(0),
+		client.WithCircuitBreaker(1, time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if err := c.DoJSONWithRetry(ctx, http.MethodGet, "projects", nil, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("DoJSONWithRetry() error = %v, want Canceled", err)
+	}
+	if got := c.Health().Circuit; got != "closed" {
+		t.Fatalf("Health().Circuit = %q, want closed", got)
+	}
+}
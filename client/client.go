@@ -3,15 +3,24 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+	"github.com/bodrovis/lokex/v2/client/internal/circuit"
+	"github.com/bodrovis/lokex/v2/client/internal/ratelimit"
 	"github.com/bodrovis/lokex/v2/client/internal/retry"
 	"github.com/bodrovis/lokex/v2/client/internal/transport"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // It is intended to be safe for concurrent use after construction, assuming
@@ -23,11 +32,59 @@ type Client struct {
 	ProjectID       string        // default project ID for project-scoped endpoints
 	UserAgent       string        // User-Agent header value
 	HTTPClient      *http.Client  // underlying HTTP client
+	BundleClient    *http.Client  // optional client for bundle (CDN) downloads; see WithBundleHTTPClient
 	MaxRetries      int           // number of retries after first attempt
 	InitialBackoff  time.Duration // initial backoff duration for retries
 	MaxBackoff      time.Duration // cap for backoff (and jittered sleep)
+	MaxRetryAfter   time.Duration // cap for delays requested via Retry-After; see WithMaxRetryAfter
+	RetryBudget     time.Duration // cap for a whole retried operation, backoff included; see WithRetryBudget
+	Jitter          Jitter        // randomization of backoff delays; see WithJitter
 	PollInitialWait time.Duration // initial wait between PollProcesses rounds
 	PollMaxWait     time.Duration // overall cap for PollProcesses duration
+	PollConcurrency int           // status requests in flight per PollProcesses round; see WithPollConcurrency
+
+	// Streaming bundle downloads; see WithBundleStreaming.
+	BundleStreaming     bool          // ignore the bundle client's Timeout; rely on ctx and idle timeouts
+	BundleHeaderTimeout time.Duration // max wait for bundle response headers in streaming mode
+	BundleStallTimeout  time.Duration // abort a bundle GET after this long without data; see WithStallTimeout
+	BundleMaxRedirects  int           // redirects a bundle GET may follow; 0 means 5, negative none; see WithBundleMaxRedirects
+
+	// JSON decoding of successful API responses.
+	UseNumber             bool // decode numbers in interface targets as json.Number
+	DisallowUnknownFields bool // fail on response fields unknown to the target struct
+	StrictDecoding        bool // fail when a typed response lacks a field lokex relies on; see WithStrictDecoding
+
+	ErrorBodyLimit int64 // bytes of a non-2xx body kept in APIError.Raw; see WithErrorBodyLimit
+
+	RequestCompression int64 // gzip request bodies of at least this many bytes; see WithRequestCompression
+	UnsafeRetryPosts   bool  // retry failed POST requests; see WithUnsafeRetryPosts
+
+	Codec  Codec  // JSON codec for request bodies and responses (encoding/json by default)
+	Signer Signer // optional per-attempt request signer; see WithSigner
+
+	audit       AuditSink          // mutating calls; see WithAudit
+	diagnostics *diagnosticsLog    // failed exchanges; see WithDiagnostics
+	limiter     *ratelimit.Limiter // shared request pacing; see WithRateLimit
+	tls         *tlsSettings       // see WithMinTLSVersion, WithPinnedCertificates
+	tracer      trace.Tracer       // see WithTracerProvider
+	metrics     MetricsRecorder    // see WithMetrics
+	retryPolicy RetryPolicy        // see WithRetryPolicy
+	health      *healthState       // shared with ForProject copies; see Health
+	waker       *background.Waker  // shared with ForProject copies; see NotifyProcessEvent
+
+	tuneTransport []func(*http.Transport) // see WithTransport
+	dryRun        DryRunRecorder          // see WithDryRun
+	debug         io.Writer               // see WithDebugWriter
+	concurrency   *ratelimit.Semaphore    // shared in-flight cap; see WithMaxConcurrency
+	responseHook  func(ResponseMeta)      // see WithResponseHook
+	breaker       *circuit.Breaker        // shared; see WithCircuitBreaker
+
+	// Per-call overrides; see WithRequestOptions.
+	headers        http.Header
+	attemptTimeout time.Duration
+	callInfo       *CallInfo
+	responseMeta   *ResponseMeta
+	idempotencyKey string // see WithIdempotencyKey
 }
 
 // NewClient builds a Client with sensible defaults and applies the provided
@@ -51,8 +108,16 @@ func NewClient(token, projectID string, opts ...Option) (*Client, error) {
 		MaxRetries:      defaultMaxRetries,
 		InitialBackoff:  defaultInitialBackoff,
 		MaxBackoff:      defaultMaxBackoff,
+		MaxRetryAfter:   defaultMaxRetryAfter,
 		PollInitialWait: defaultPollInitialWait,
 		PollMaxWait:     defaultPollMaxWait,
+		PollConcurrency: defaultPollConcurrency,
+		ErrorBodyLimit:  apierr.DefaultErrCap,
+		Codec:           utils.StdCodec{},
+		health:          new(healthState),
+		waker:           new(background.Waker),
+
+		UnsafeRetryPosts: true,
 	}
 
 	for _, opt := range opts {
@@ -64,16 +129,80 @@ func NewClient(token, projectID string, opts ...Option) (*Client, error) {
 		}
 	}
 
+	if err := c.applyTransportTuning(); err != nil {
+		return nil, err
+	}
+
+	hc, err := c.tls.withTLS(c.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	c.HTTPClient = hc
+
+	bc, err := c.tls.withTLS(c.BundleClient)
+	if err != nil {
+		return nil, fmt.Errorf("bundle client: %w", err)
+	}
+	c.BundleClient = bc
+
 	return c, nil
 }
 
+// decodeOptions returns how the client decodes JSON responses.
+func (c *Client) decodeOptions() transport.DecodeOptions {
+	return transport.DecodeOptions{
+		UseNumber:             c.UseNumber,
+		DisallowUnknownFields: c.DisallowUnknownFields,
+		Codec:                 c.Codec,
+		Strict:                c.StrictDecoding,
+		Envelope:              c.envelopeHook(),
+	}
+}
+
 // Requester builds a transport requester using the client's current HTTP settings.
 func (c *Client) Requester() transport.Requester {
 	return transport.Requester{
-		BaseURL:    c.BaseURL,
-		Token:      c.Token,
-		UserAgent:  c.UserAgent,
-		HTTPClient: c.HTTPClient,
+		BaseURL:      c.BaseURL,
+		Token:        c.Token,
+		UserAgent:    c.UserAgent,
+		HTTPClient:   c.HTTPClient,
+		Header:       c.headers,
+		Decode:       c.decodeOptions(),
+		ErrBodyLimit: c.ErrorBodyLimit,
+		OnFailure:    c.failureRecorder(),
+		Signer:       c.Signer,
+		Limiter:      c.limiter,
+		Priority:     ratelimit.High,
+		Concurrency:  c.concurrency,
+		Breaker:      c.breaker,
+		Tracer:       c.tracer,
+		Metrics:      c.metrics,
+		CompressMin:  c.RequestCompression,
+		OnResult:     c.onResult(),
+		Debug:        c.debug,
+	}
+}
+
+// BundleHTTPClient returns the client used to GET bundles: BundleClient if
+// set, otherwise HTTPClient.
+func (c *Client) BundleHTTPClient() *http.Client {
+	if c.BundleClient != nil {
+		return c.BundleClient
+	}
+	return c.HTTPClient
+}
+
+// PollConfig returns the settings used to poll async processes.
+func (c *Client) PollConfig() background.Config {
+	return background.Config{
+		Requester:   c.Requester(),
+		ProjectID:   c.ProjectID,
+		InitialWait: c.PollInitialWait,
+		MaxWait:     c.PollMaxWait,
+		Concurrency: c.PollConcurrency,
+		Tracer:      c.tracer,
+		Metrics:     c.metrics,
+		Wake:        c.wakeChannel(),
 	}
 }
 
@@ -86,37 +215,104 @@ func (c *Client) DoJSONWithRetry(
 	body io.Reader,
 	v any,
 ) error {
+	_, err := c.doJSONWithRetry(ctx, method, path, body, v)
+	return err
+}
+
+// doJSONWithRetry is DoJSONWithRetry that also returns the header of the
+// last response.
+func (c *Client) doJSONWithRetry(
+	ctx context.Context,
+	method, path string,
+	body io.Reader,
+	v any,
+) (http.Header, error) {
 	reqr := c.Requester()
-	return retry.DoWithRetry(
+	reqr.Header = c.withIdempotencyKey(method, path, body, reqr.Header)
+	var op, paramsHash string
+	auditPath, _, _ := strings.Cut(path, "?")
+	if c.audit != nil {
+		if op = auditOperation(method, auditPath); op != "" {
+			paramsHash = hashBody(body)
+		}
+	}
+	start := time.Now()
+	attempts := 0
+
+	cfg := c.RetryConfig("request")
+	cfg.Method, cfg.Path = method, auditPath
+	if method == http.MethodPost && !c.UnsafeRetryPosts {
+		cfg.MaxRetries = 0
+	}
+	if reqr.DryRun = c.dryRunHook(method); reqr.DryRun != nil {
+		cfg.MaxRetries = 0
+	}
+
+	var header http.Header
+	err := retry.DoWithRetry(
 		ctx,
-		retry.Config{
-			Label:          "request",
-			MaxRetries:     c.MaxRetries,
-			InitialBackoff: c.InitialBackoff,
-			MaxBackoff:     c.MaxBackoff,
-		},
+		cfg,
 		body,
-		func(_ int, b io.Reader) error {
-			return reqr.DoJSON(ctx, method, path, b, v)
+		func(actx context.Context, _ int, b io.Reader) error {
+			attempts++
+			var err error
+			header, err = reqr.DoJSONHeader(actx, method, path, b, v)
+			return err
 		},
 		nil,
 	)
+	if op != "" {
+		c.recordAudit(op, method, auditPath, paramsHash, header.Get(HeaderRequestID), start, attempts, err)
+	}
+	return header, err
 }
 
-// WithExpBackoff runs op using the client's retry/backoff settings.
+// WithExpBackoff runs op using the client's retry/backoff settings. A retry
+// policy set with WithRetryPolicy decides which errors are retried;
+// otherwise isRetryable does, or the default policy if it is nil.
 func (c *Client) WithExpBackoff(
 	ctx context.Context,
 	label string,
 	op func(attempt int) error,
 	isRetryable func(error) bool,
 ) error {
-	return retry.WithExpBackoff(
-		ctx,
-		label,
-		c.MaxRetries,
-		c.InitialBackoff,
-		c.MaxBackoff,
-		op,
-		isRetryable,
-	)
+	return c.WithExpBackoffContext(ctx, label, func(_ context.Context, attempt int) error {
+		return op(attempt)
+	}, isRetryable)
+}
+
+// WithExpBackoffContext is WithExpBackoff passing each attempt its own
+// context, which carries the attempt timeout (see WithAttemptTimeout).
+func (c *Client) WithExpBackoffContext(
+	ctx context.Context,
+	label string,
+	op func(ctx context.Context, attempt int) error,
+	isRetryable func(error) bool,
+) error {
+	return retry.WithExpBackoff(ctx, c.RetryConfig(label), op, isRetryable)
+}
+
+// RetryConfig returns the client's retry settings under label, for
+// packages that run their own retry loop on top of the client's backoff,
+// tracer and metrics.
+func (c *Client) RetryConfig(label string) retry.Config {
+	return retry.Config{
+		Label:          label,
+		MaxRetries:     c.MaxRetries,
+		InitialBackoff: c.InitialBackoff,
+		MaxBackoff:     c.MaxBackoff,
+		Jitter:         c.Jitter,
+		MaxRetryAfter:  c.MaxRetryAfter,
+		Tracer:         c.tracer,
+		Metrics:        c.metrics,
+		Policy:         c.retryPolicy,
+		AttemptTimeout: c.attemptTimeout,
+		Budget:         c.RetryBudget,
+	}
+}
+
+// EncodeJSON encodes body with the client's codec into a replayable reader
+// suitable for DoJSONWithRetry.
+func (c *Client) EncodeJSON(body any) (*bytes.Reader, error) {
+	return utils.EncodeJSONBodyWith(c.Codec, body)
 }
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// Codec creates streaming JSON encoders and decoders. It lets high-throughput
+// services swap encoding/json for a faster implementation (go-json, sonic, ...)
+// usually via a thin adapter, since their APIs mirror encoding/json.
+type Codec = utils.Codec
+
+// JSONEncoder is the encoder subset used by lokex (satisfied by *json.Encoder).
+type JSONEncoder = utils.JSONEncoder
+
+// JSONDecoder is the decoder subset used by lokex (satisfied by *json.Decoder).
+type JSONDecoder = utils.JSONDecoder
+
+// StdCodec is the default encoding/json Codec.
+type StdCodec = utils.StdCodec
+
+// WithCodec sets the JSON codec used to encode request bodies and decode
+// successful responses. Upload bodies encode their params with it too; the
+// file data is streamed as base64 without it. The codec must be non-nil.
+func WithCodec(codec Codec) Option {
+	return func(c *Client) error {
+		if codec == nil {
+			return errors.New("codec cannot be nil")
+		}
+		c.Codec = codec
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+type countingCodec struct {
+	encoders atomic.Int32
+	decoders atomic.Int32
+}
+
+func (c *countingCodec) NewEncoder(w io.Writer) client.JSONEncoder {
+	c.encoders.Add(1)
+	return json.NewEncoder(w)
+}
+
+func (c *countingCodec) NewDecoder(r io.Reader) client.JSONDecoder {
+	c.decoders.Add(1)
+	return json.NewDecoder(r)
+}
+
+func TestWithCodec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil codec is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := client.NewClient("t", "p", client.WithCodec(nil))
+		if err == nil || err.Error() != "codec cannot be nil" {
+			t.Fatalf("error = %v, want codec cannot be nil", err)
+		}
+	})
+
+	t.Run("default is std codec", func(t *testing.T) {
+		t.Parallel()
+
+		c, err := client.NewClient("t", "p")
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if _, ok := c.Codec.(client.StdCodec); !ok {
+			t.Fatalf("Codec = %T, want client.StdCodec", c.Codec)
+		}
+	})
+
+	t.Run("custom codec encodes and decodes", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var in map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&in)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"echo": in["format"]})
+		}))
+		defer srv.Close()
+
+		codec := &countingCodec{}
+		c, err := client.NewClient("t", "p",
+			client.WithBaseURL(srv.URL),
+			client.WithHTTPClient(srv.Client()),
+			client.WithCodec(codec),
+		)
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+
+		body, err := c.EncodeJSON(map[string]any{"format": "<json>"})
+		if err != nil {
+			t.Fatalf("EncodeJSON() error = %v", err)
+		}
+
+		var out struct {
+			Echo string `json:"echo"`
+		}
+		if err := c.DoJSONWithRetry(context.Background(), http.MethodPost, "echo", body, &out); err != nil {
+			t.Fatalf("DoJSONWithRetry() error = %v", err)
+		}
+		if out.Echo != "<json>" {
+			t.Fatalf("echo = %q, want %q", out.Echo, "<json>")
+		}
+		if codec.encoders.Load() != 1 || codec.decoders.Load() != 1 {
+			t.Fatalf("encoders = %d, decoders = %d, want 1 and 1", codec.encoders.Load(), codec.decoders.Load())
+		}
+	})
+}
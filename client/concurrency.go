@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bodrovis/lokex/v2/client/internal/ratelimit"
+)
+
+// WithMaxConcurrency caps how many requests the client has in flight at
+// once, across every goroutine using it (and its ForProject copies): API
+// calls, polls and bundle downloads. Further requests wait for a free slot,
+// or until their context is done. A slot is held from sending the request
+// until its response body has been read, so a large bundle download holds
+// one for the whole transfer. Unlike WithRateLimit, which paces requests
+// over time, this bounds open connections. n must be at least 1.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) error {
+		s, err := ratelimit.NewSemaphore(n)
+		if err != nil {
+			return fmt.Errorf("max concurrency: %w", err)
+		}
+		c.concurrency = s
+		return nil
+	}
+}
+
+// AcquireSlot takes one of the WithMaxConcurrency slots for a request sent
+// outside the Requester, such as a bundle GET, blocking until one is free
+// or ctx is done. Call release once the response body is closed. Without
+// WithMaxConcurrency it returns immediately.
+func (c *Client) AcquireSlot(ctx context.Context) (release func(), err error) {
+	release, err = c.concurrency.Acquire(ctx)
+	if err != nil {
+		return release, fmt.Errorf("max concurrency: %w", err)
+	}
+	return release, nil
+}
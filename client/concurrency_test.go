@@ -0,0 +1,103 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestWithMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var cur, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := cur.Add(1)
+		defer cur.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("tok", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxConcurrency(2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects", nil, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p != 2 {
+		t.Fatalf("peak concurrency = %d, want 2", p)
+	}
+	if n := c.Health().InFlight; n != 0 {
+		t.Fatalf("Health().InFlight = %d after all requests, want 0", n)
+	}
+}
+
+func TestWithMaxConcurrency_WaitHonorsContext(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok", "p",
+		client.WithBaseURL("http://127.0.0.1:1"),
+		client.WithMaxConcurrency(1),
+		client.WithMaxRetries(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := c.AcquireSlot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	if n := c.ForProject("other").Health().InFlight; n != 1 {
+		t.Fatalf("Health().InFlight = %d, want 1 (shared with ForProject copies)", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = c.DoJSONWithRetry(ctx, http.MethodGet, "projects", nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded while waiting for a slot", err)
+	}
+}
+
+func TestWithMaxConcurrency_Validates(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("tok", "p", client.WithMaxConcurrency(0)); err == nil {
+		t.Fatal("WithMaxConcurrency(0): expected error")
+	}
+	c, err := client.NewClient("tok", "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := c.AcquireSlot(context.Background())
+	if err != nil {
+		t.Fatalf("AcquireSlot() without a cap: %v", err)
+	}
+	release()
+}
@@ -2,10 +2,15 @@ package client
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+	"github.com/bodrovis/lokex/v2/client/internal/ratelimit"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
 )
 
 const (
@@ -19,11 +24,13 @@ const (
 	defaultMaxRetries     = 3
 	defaultInitialBackoff = 400 * time.Millisecond
 	defaultMaxBackoff     = 5 * time.Second
+	defaultMaxRetryAfter  = 60 * time.Second
 	defaultHTTPTimeout    = 30 * time.Second
 
 	// defaults for the polling helper.
 	defaultPollInitialWait = 1 * time.Second
 	defaultPollMaxWait     = 120 * time.Second
+	defaultPollConcurrency = background.DefaultConcurrency
 )
 
 // Option customizes a Client during construction.
@@ -64,7 +71,8 @@ func WithUserAgent(ua string) Option {
 }
 
 // WithHTTPClient replaces the underlying http.Client.
-// The client must be non-nil.
+// The client must be non-nil. With TLS options (WithMinTLSVersion,
+// WithPinnedCertificates) its transport must be an *http.Transport.
 func WithHTTPClient(hc *http.Client) Option {
 	return func(c *Client) error {
 		if hc == nil {
@@ -91,6 +99,89 @@ func WithHTTPTimeout(d time.Duration) Option {
 	}
 }
 
+// WithBundleHTTPClient sets a separate http.Client for bundle downloads
+// (the CDN GET after an export), so they can have their own timeouts, proxy
+// and transport while API calls keep HTTPClient. The client must be non-nil,
+// and its transport an *http.Transport if TLS options are used.
+func WithBundleHTTPClient(hc *http.Client) Option {
+	return func(c *Client) error {
+		if hc == nil {
+			return errors.New("bundle http client cannot be nil")
+		}
+		c.BundleClient = hc
+		return nil
+	}
+}
+
+// WithBundleTimeout sets the timeout for bundle downloads only. If no bundle
+// client was set, one is created sharing HTTPClient's transport (with its
+// connection pool and TLS settings). A zero value disables the timeout.
+func WithBundleTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d < 0 {
+			return errors.New("bundle timeout cannot be negative")
+		}
+		if c.BundleClient == nil {
+			c.BundleClient = &http.Client{}
+			if c.HTTPClient != nil {
+				c.BundleClient.Transport = c.HTTPClient.Transport
+			}
+		}
+		c.BundleClient.Timeout = d
+		return nil
+	}
+}
+
+// WithBundleStreaming makes bundle downloads ignore the http.Client Timeout,
+// which caps the whole transfer, so multi-GB exports don't need a global
+// timeout bump. Instead the response headers must arrive within
+// headerTimeout and the body is aborted (and retried) only if it stalls
+// (see WithStallTimeout; 60s unless set); the overall duration is bounded
+// by the caller's context. A zero/negative headerTimeout falls back to the default HTTP
+// timeout.
+func WithBundleStreaming(headerTimeout time.Duration) Option {
+	return func(c *Client) error {
+		if headerTimeout <= 0 {
+			headerTimeout = defaultHTTPTimeout
+		}
+		c.BundleStreaming = true
+		c.BundleHeaderTimeout = headerTimeout
+		return nil
+	}
+}
+
+// WithStallTimeout aborts and retries a bundle download when no bytes arrive
+// for d, telling a stalled transfer apart from a merely slow one: the timer
+// restarts on every read that returns data. It applies with or without
+// WithBundleStreaming. Zero disables it (streaming mode then uses 60s).
+func WithStallTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d < 0 {
+			return errors.New("stall timeout cannot be negative")
+		}
+		c.BundleStallTimeout = d
+		return nil
+	}
+}
+
+// WithBundleMaxRedirects caps how many redirects a bundle download follows
+// (5 by default); zero refuses redirects altogether. Every hop must stay on
+// https and pass the same host checks as the bundle URL itself, credentials
+// are dropped once the host changes, and loops fail fast with
+// download.ErrRedirectLoop.
+func WithBundleMaxRedirects(n int) Option {
+	return func(c *Client) error {
+		if n < 0 {
+			return errors.New("bundle max redirects cannot be negative")
+		}
+		if n == 0 {
+			n = -1
+		}
+		c.BundleMaxRedirects = n
+		return nil
+	}
+}
+
 // WithMaxRetries sets how many *retries* to attempt after the initial try.
 // Zero disables retries; negative values are normalized to zero.
 func WithMaxRetries(n int) Option {
@@ -103,6 +194,20 @@ func WithMaxRetries(n int) Option {
 	}
 }
 
+// WithErrorBodyLimit sets how many bytes of a non-2xx response body are kept
+// for the returned API error (default 8 KiB). Raise it when validation errors
+// list many keys and get cut mid-JSON; APIError.Truncated reports when that
+// happened. Zero/negative values fall back to the default.
+func WithErrorBodyLimit(n int64) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			n = apierr.DefaultErrCap
+		}
+		c.ErrorBodyLimit = n
+		return nil
+	}
+}
+
 // WithBackoff sets the exponential backoff window for retries.
 // Zero/negative inputs fall back to library defaults.
 // If max < initial, max is promoted to initial.
@@ -123,6 +228,31 @@ func WithBackoff(initial, max time.Duration) Option {
 	}
 }
 
+// WithMaxRetryAfter caps how long a retry waits when a 429 or 503 response
+// carries a Retry-After header (seconds or HTTP date). The server's delay is
+// used as the minimum wait before the next attempt, even above the backoff
+// cap, up to d (default 60s). Zero or negative ignores Retry-After and uses
+// plain exponential backoff.
+func WithMaxRetryAfter(d time.Duration) Option {
+	return func(c *Client) error {
+		c.MaxRetryAfter = max(d, 0)
+		return nil
+	}
+}
+
+// WithRetryBudget caps how long one retried operation may take, attempts
+// and backoff included, e.g. 30s so a long 429 storm fails fast instead of
+// running through every retry. Once the next wait would overrun d, the call
+// fails with an error matching ErrRetryBudgetExhausted that also wraps the
+// last attempt's error; an attempt still running at d is canceled. It
+// applies on top of the caller's context. Zero or negative disables it.
+func WithRetryBudget(d time.Duration) Option {
+	return func(c *Client) error {
+		c.RetryBudget = max(d, 0)
+		return nil
+	}
+}
+
 // WithPollWait sets the initial wait and the overall max wait for PollProcesses.
 // Zero/negative inputs fall back to library defaults. If max < initial,
 // max is promoted to initial.
@@ -143,3 +273,82 @@ func WithPollWait(initial, max time.Duration) Option {
 		return nil
 	}
 }
+
+// WithPollConcurrency sets how many process statuses PollProcesses fetches
+// at once in each round (6 by default), so waiting on many uploads at once
+// takes fewer round trips. Results keep the input order regardless. The
+// requests still count against WithMaxConcurrency and WithRateLimit.
+// Zero/negative inputs fall back to the default.
+func WithPollConcurrency(n int) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			n = defaultPollConcurrency
+		}
+		c.PollConcurrency = n
+		return nil
+	}
+}
+
+// WithUseNumber makes response decoding keep numbers as json.Number when the
+// target is an interface value (map[string]any, []any, any). Lokalise IDs can
+// exceed float64 precision, so enable this when decoding into untyped values.
+func WithUseNumber(on bool) Option {
+	return func(c *Client) error {
+		c.UseNumber = on
+		return nil
+	}
+}
+
+// WithDisallowUnknownFields makes response decoding fail when the payload
+// contains fields that the target struct does not declare.
+func WithDisallowUnknownFields(on bool) Option {
+	return func(c *Client) error {
+		c.DisallowUnknownFields = on
+		return nil
+	}
+}
+
+// WithStrictDecoding makes typed endpoints (keys, screenshots, uploads,
+// exports, processes) fail when a response lacks a field lokex relies on,
+// such as key_id or process_id, instead of silently leaving it zero. The
+// error matches ErrSchemaMismatch. Unknown fields are still allowed, since
+// typed structs only declare what lokex uses; combine with
+// WithDisallowUnknownFields for your own exhaustive structs. Meant for
+// staging and CI, to catch upstream API changes early.
+func WithStrictDecoding(on bool) Option {
+	return func(c *Client) error {
+		c.StrictDecoding = on
+		return nil
+	}
+}
+
+// WithRequestCompression gzips API request bodies of at least minSize bytes
+// and sends them with Content-Encoding: gzip. Uploads carry base64 file data,
+// which shrinks well, so this saves time on slow links; smaller bodies aren't
+// worth the CPU. Large bodies are compressed as they are sent, without being
+// buffered. minSize must be positive.
+func WithRequestCompression(minSize int64) Option {
+	return func(c *Client) error {
+		if minSize <= 0 {
+			return errors.New("request compression threshold must be positive")
+		}
+		c.RequestCompression = minSize
+		return nil
+	}
+}
+
+// WithRateLimit paces every request sent by the client, including retries and
+// process polling, to perSecond on average with bursts of up to burst.
+// User-initiated calls are served before polling when both are waiting, so
+// long polls cannot starve uploads and downloads. Lokalise allows 6 requests
+// per second per token. Disabled by default.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *Client) error {
+		l, err := ratelimit.New(perSecond, burst)
+		if err != nil {
+			return fmt.Errorf("rate limit: %w", err)
+		}
+		c.limiter = l
+		return nil
+	}
+}
@@ -1,8 +1,15 @@
 package client_test
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -235,6 +242,31 @@ func TestWithPollWait_DefaultsWhenInitialAndMaxAreZero(t *testing.T) {
 	}
 }
 
+func TestWithPollConcurrency(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok", "proj")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if c.PollConcurrency != 6 {
+		t.Fatalf("default PollConcurrency = %d, want 6", c.PollConcurrency)
+	}
+
+	for in, want := range map[int]int{20: 20, 1: 1, 0: 6, -3: 6} {
+		c := &client.Client{}
+		if err := client.WithPollConcurrency(in)(c); err != nil {
+			t.Fatalf("WithPollConcurrency(%d) error = %v", in, err)
+		}
+		if c.PollConcurrency != want {
+			t.Fatalf("WithPollConcurrency(%d): PollConcurrency = %d, want %d", in, c.PollConcurrency, want)
+		}
+		if got := c.PollConfig().Concurrency; got != want {
+			t.Fatalf("WithPollConcurrency(%d): PollConfig().Concurrency = %d, want %d", in, got, want)
+		}
+	}
+}
+
 func TestWithPollWait_DefaultsWhenValuesAreNegative(t *testing.T) {
 	t.Parallel()
 
@@ -273,3 +305,410 @@ func TestWithPollWait_PromotesMaxToInitialWhenMaxLessThanInitial(t *testing.T) {
 		t.Fatalf("PollMaxWait = %v, want %v", c.PollMaxWait, initial)
 	}
 }
+
+func TestWithDecodingOptions(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("t", "p",
+		client.WithUseNumber(true),
+		client.WithDisallowUnknownFields(true),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if !c.UseNumber || !c.DisallowUnknownFields {
+		t.Fatalf("UseNumber = %v, DisallowUnknownFields = %v, want both true", c.UseNumber, c.DisallowUnknownFields)
+	}
+
+	reqr := c.Requester()
+	if !reqr.Decode.UseNumber || !reqr.Decode.DisallowUnknownFields {
+		t.Fatalf("Requester().Decode = %+v, want both enabled", reqr.Decode)
+	}
+}
+
+func TestDoJSONWithRetry_UseNumber(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"key_id":9007199254740993}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("t", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithHTTPClient(srv.Client()),
+		client.WithUseNumber(true),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var out map[string]any
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "keys", nil, &out); err != nil {
+		t.Fatalf("DoJSONWithRetry() error = %v", err)
+	}
+	if got := fmt.Sprint(out["key_id"]); got != "9007199254740993" {
+		t.Fatalf("key_id = %s, want 9007199254740993", got)
+	}
+}
+
+func TestWithErrorBodyLimit(t *testing.T) {
+	t.Parallel()
+
+	body := `{"error":{"message":"Validation failed","code":400,"details":{"keys":["` + strings.Repeat("k", 100) + `"]}}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name          string
+		limit         int64
+		wantLimit     int64
+		wantTruncated bool
+	}{
+		{"small limit truncates", 32, 32, true},
+		{"large limit keeps body", 1 << 20, 1 << 20, false},
+		{"zero falls back to default", 0, 8192, false},
+		{"negative falls back to default", -1, 8192, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := client.NewClient("test-token", "p",
+				client.WithBaseURL(srv.URL),
+				client.WithHTTPClient(srv.Client()),
+				client.WithErrorBodyLimit(tt.limit),
+			)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if c.ErrorBodyLimit != tt.wantLimit || c.Requester().ErrBodyLimit != tt.wantLimit {
+				t.Fatalf("ErrorBodyLimit = %d, Requester().ErrBodyLimit = %d; want %d", c.ErrorBodyLimit, c.Requester().ErrBodyLimit, tt.wantLimit)
+			}
+
+			err = c.DoJSONWithRetry(context.Background(), http.MethodPost, "projects/p/keys", nil, nil)
+			var ae *client.APIError
+			if !errors.As(err, &ae) {
+				t.Fatalf("error = %v, want *client.APIError", err)
+			}
+			if ae.Truncated != tt.wantTruncated {
+				t.Fatalf("Truncated = %v, want %v", ae.Truncated, tt.wantTruncated)
+			}
+			if !tt.wantTruncated && ae.Raw != body {
+				t.Fatalf("Raw = %q, want full body", ae.Raw)
+			}
+			if tt.wantTruncated && ae.Raw != strings.TrimSpace(body[:tt.limit]) {
+				t.Fatalf("Raw = %q, want the first %d bytes", ae.Raw, tt.limit)
+			}
+		})
+	}
+}
+
+func TestWithMaxRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cap     time.Duration
+		minWait time.Duration
+		maxWait time.Duration
+	}{
+		{"honored up to the cap", 100 * time.Millisecond, 100 * time.Millisecond, 5 * time.Second},
+		{"disabled", 0, 0, 900 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := client.NewClient("test-token", "p",
+				client.WithBaseURL(srv.URL),
+				client.WithBackoff(time.Millisecond, time.Millisecond),
+				client.WithMaxRetryAfter(tt.cap),
+			)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if c.MaxRetryAfter != tt.cap {
+				t.Fatalf("MaxRetryAfter = %v, want %v", c.MaxRetryAfter, tt.cap)
+			}
+
+			start := time.Now()
+			if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects", nil, nil); err != nil {
+				t.Fatalf("DoJSONWithRetry() error = %v", err)
+			}
+			if waited := time.Since(start); waited < tt.minWait || waited > tt.maxWait {
+				t.Fatalf("waited %v, want between %v and %v", waited, tt.minWait, tt.maxWait)
+			}
+		})
+	}
+}
+
+func TestWithRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("test-token", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(5),
+		client.WithRetryBudget(2*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if c.RetryBudget != 2*time.Second {
+		t.Fatalf("RetryBudget = %v, want 2s", c.RetryBudget)
+	}
+
+	start := time.Now()
+	err = c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects", nil, nil)
+	if !errors.Is(err, client.ErrRetryBudgetExhausted) {
+		t.Fatalf("err = %v, want ErrRetryBudgetExhausted", err)
+	}
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want the 429 APIError wrapped", err)
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Fatalf("took %v, want a fast failure instead of a 30s wait", took)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+
+	if err := client.WithRetryBudget(-time.Second)(c); err != nil || c.RetryBudget != 0 {
+		t.Fatalf("WithRetryBudget(-1s): err = %v, RetryBudget = %v; want 0", err, c.RetryBudget)
+	}
+}
+
+func TestWithMaxRetryAfter_Default(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("t", "p", client.WithMaxRetryAfter(-time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.MaxRetryAfter != 0 {
+		t.Fatalf("MaxRetryAfter = %v, want 0 for negative input", c.MaxRetryAfter)
+	}
+	c, err = client.NewClient("t", "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.MaxRetryAfter != time.Minute {
+		t.Fatalf("default MaxRetryAfter = %v, want 1m", c.MaxRetryAfter)
+	}
+}
+
+func TestWithBundleHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	bundle := &http.Client{Timeout: 10 * time.Minute}
+	c, err := client.NewClient("tok", "proj",
+		client.WithHTTPTimeout(5*time.Second),
+		client.WithBundleHTTPClient(bundle),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if c.HTTPClient.Timeout != 5*time.Second {
+		t.Fatalf("HTTPClient.Timeout = %v, want 5s", c.HTTPClient.Timeout)
+	}
+	if c.BundleHTTPClient() != bundle {
+		t.Fatal("BundleHTTPClient() is not the configured bundle client")
+	}
+
+	if _, err := client.NewClient("tok", "proj", client.WithBundleHTTPClient(nil)); err == nil {
+		t.Fatal("WithBundleHTTPClient(nil) error = nil, want error")
+	}
+}
+
+func TestBundleHTTPClient_FallsBackToHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok", "proj")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if c.BundleClient != nil {
+		t.Fatal("BundleClient != nil by default")
+	}
+	if c.BundleHTTPClient() != c.HTTPClient {
+		t.Fatal("BundleHTTPClient() should fall back to HTTPClient")
+	}
+}
+
+func TestWithBundleTimeout(t *testing.T) {
+	t.Parallel()
+
+	tr := &http.Transport{}
+	c, err := client.NewClient("tok", "proj",
+		client.WithHTTPClient(&http.Client{Transport: tr, Timeout: 5 * time.Second}),
+		client.WithBundleTimeout(10*time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if c.HTTPClient.Timeout != 5*time.Second {
+		t.Fatalf("HTTPClient.Timeout = %v, want 5s", c.HTTPClient.Timeout)
+	}
+	bc := c.BundleHTTPClient()
+	if bc == c.HTTPClient {
+		t.Fatal("BundleHTTPClient() is the API client, want a separate one")
+	}
+	if bc.Timeout != 10*time.Minute {
+		t.Fatalf("bundle Timeout = %v, want 10m", bc.Timeout)
+	}
+	if bc.Transport != tr {
+		t.Fatal("bundle client should share the API transport")
+	}
+
+	if _, err := client.NewClient("tok", "proj", client.WithBundleTimeout(-time.Second)); err == nil {
+		t.Fatal("WithBundleTimeout(-1s) error = nil, want error")
+	}
+}
+
+func TestWithBundleTimeout_UsesExistingBundleClient(t *testing.T) {
+	t.Parallel()
+
+	bundle := &http.Client{}
+	c, err := client.NewClient("tok", "proj",
+		client.WithBundleHTTPClient(bundle),
+		client.WithBundleTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if c.BundleHTTPClient() != bundle || bundle.Timeout != time.Minute {
+		t.Fatal("WithBundleTimeout should update the configured bundle client")
+	}
+}
+
+func TestWithBundleStreaming(t *testing.T) {
+	t.Parallel()
+
+	c := &client.Client{}
+	if err := client.WithBundleStreaming(2 * time.Minute)(c); err != nil {
+		t.Fatalf("WithBundleStreaming() error = %v", err)
+	}
+	if !c.BundleStreaming || c.BundleHeaderTimeout != 2*time.Minute {
+		t.Fatalf("streaming = %v, header timeout = %v", c.BundleStreaming, c.BundleHeaderTimeout)
+	}
+
+	c = &client.Client{}
+	if err := client.WithBundleStreaming(0)(c); err != nil {
+		t.Fatalf("WithBundleStreaming(0) error = %v", err)
+	}
+	if c.BundleHeaderTimeout != 30*time.Second {
+		t.Fatalf("BundleHeaderTimeout = %v, want default 30s", c.BundleHeaderTimeout)
+	}
+}
+
+func TestWithStallTimeout(t *testing.T) {
+	t.Parallel()
+
+	c := &client.Client{}
+	if err := client.WithStallTimeout(20 * time.Second)(c); err != nil {
+		t.Fatalf("WithStallTimeout() error = %v", err)
+	}
+	if c.BundleStallTimeout != 20*time.Second {
+		t.Fatalf("BundleStallTimeout = %v, want 20s", c.BundleStallTimeout)
+	}
+	if err := client.WithStallTimeout(-time.Second)(c); err == nil {
+		t.Fatal("WithStallTimeout(-1s) error = nil, want error")
+	}
+}
+
+func TestWithBundleMaxRedirects(t *testing.T) {
+	t.Parallel()
+
+	c := &client.Client{}
+	if err := client.WithBundleMaxRedirects(3)(c); err != nil {
+		t.Fatalf("WithBundleMaxRedirects() error = %v", err)
+	}
+	if c.BundleMaxRedirects != 3 {
+		t.Fatalf("BundleMaxRedirects = %d, want 3", c.BundleMaxRedirects)
+	}
+	if err := client.WithBundleMaxRedirects(0)(c); err != nil {
+		t.Fatalf("WithBundleMaxRedirects(0) error = %v", err)
+	}
+	if c.BundleMaxRedirects >= 0 {
+		t.Fatalf("BundleMaxRedirects = %d, want negative (no redirects)", c.BundleMaxRedirects)
+	}
+	if err := client.WithBundleMaxRedirects(-1)(c); err == nil {
+		t.Fatal("WithBundleMaxRedirects(-1) error = nil, want error")
+	}
+}
+
+func TestWithRequestCompression(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int64{0, -1} {
+		if _, err := client.NewClient("tok", "p", client.WithRequestCompression(n)); err == nil {
+			t.Fatalf("WithRequestCompression(%d): expected error", n)
+		}
+	}
+
+	var attempts atomic.Int32
+	data := strings.Repeat("QUJD", 4096)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := attempts.Add(1)
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("attempt %d: Content-Encoding = %q, want gzip", n, r.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("attempt %d: %v", n, err)
+			return
+		}
+		var body struct{ Data string }
+		if err := json.NewDecoder(zr).Decode(&body); err != nil || body.Data != data {
+			t.Errorf("attempt %d: body not decoded intact (err %v)", n, err)
+		}
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("tok", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithRequestCompression(1024),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Do(context.Background(), http.MethodPost, "projects/{project_id}/files/upload", map[string]string{"data": data}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Fatalf("attempts = %d, want 2 (the retry must resend the compressed body)", n)
+	}
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// WithDebugWriter dumps every attempt of every API request to w: method,
+// URL, headers and body, then the status, headers and body of the response
+// (or the send error) and how long it took. The API token, credentials and
+// file contents are redacted as in diagnostics, and bodies are cut at 8 KiB;
+// streamed upload bodies are not shown. Dumps of concurrent requests don't
+// interleave. Bundle downloads from the CDN are not dumped. w must be
+// non-nil.
+func WithDebugWriter(w io.Writer) Option {
+	return func(c *Client) error {
+		if w == nil {
+			return errors.New("debug writer cannot be nil")
+		}
+		c.debug = &lockedWriter{w: w}
+		return nil
+	}
+}
+
+// lockedWriter serializes writes, so each dump lands in one piece.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
@@ -0,0 +1,115 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestWithDebugWriter(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"code":503,"message":"busy"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"process":{"process_id":"p1"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var buf bytes.Buffer
+	c, err := client.NewClient("secret-token", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+		client.WithDebugWriter(&buf),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := map[string]any{"filename": "en.json", "data": "QUFBQUFB"}
+	if err := c.Do(context.Background(), http.MethodPost, "projects/p/files/upload", body, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, "--> POST "+srv.URL+"/projects/p/files/upload"); n != 2 {
+		t.Fatalf("dumped %d attempts, want 2:\n%s", n, out)
+	}
+	for _, want := range []string{
+		"<-- 503 Service Unavailable",
+		`"message":"busy"`,
+		"<-- 200 OK",
+		`{"process":{"process_id":"p1"}}`,
+		"X-Api-Token: [REDACTED]",
+		`"filename":"en.json"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dump lacks %q:\n%s", want, out)
+		}
+	}
+	for _, secret := range []string{"secret-token", "QUFBQUFB"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("dump leaks %q:\n%s", secret, out)
+		}
+	}
+}
+
+func TestWithDebugWriter_SendError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	var buf bytes.Buffer
+	c, err := client.NewClient("token", "p", client.WithBaseURL(url), client.WithMaxRetries(0), client.WithDebugWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Do(context.Background(), http.MethodGet, "projects", nil, nil); err == nil {
+		t.Fatal("Do() error = nil")
+	}
+	if out := buf.String(); !strings.Contains(out, "--> GET ") || !strings.Contains(out, "<-- error (") {
+		t.Fatalf("dump = %q", out)
+	}
+}
+
+func TestWithDebugWriter_Polls(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := processServer(t, "", "running", "finished")
+	var buf bytes.Buffer
+	c, err := client.NewClient("token", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithPollWait(time.Millisecond, time.Second),
+		client.WithDebugWriter(&buf),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.WaitAll(context.Background(), []string{"pid"}); err != nil {
+		t.Fatalf("WaitAll() error = %v", err)
+	}
+	if got := strings.Count(buf.String(), "--> GET "+srv.URL+"/projects/proj/processes/pid"); got != 2 {
+		t.Fatalf("debug output shows %d polls, want 2:\n%s", got, buf.String())
+	}
+}
+
+func TestWithDebugWriter_Nil(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("token", "p", client.WithDebugWriter(nil)); err == nil {
+		t.Fatal("WithDebugWriter(nil) error = nil")
+	}
+}
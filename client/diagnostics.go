@@ -0,0 +1,131 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+)
+
+// FailedExchange is a sanitized snapshot of one failed API request/response
+// pair. The API token and cookies are redacted; bodies are truncated.
+type FailedExchange = transport.FailedExchange
+
+// diagnosticsLog keeps the last N failed exchanges in memory and optionally
+// mirrors them to files in dir, pruning older files beyond N.
+type diagnosticsLog struct {
+	mu    sync.Mutex
+	ring  []FailedExchange
+	next  int
+	full  bool
+	dir   string
+	files []string
+	seq   uint64
+}
+
+func newDiagnosticsLog(n int, dir string) *diagnosticsLog {
+	return &diagnosticsLog{ring: make([]FailedExchange, n), dir: dir}
+}
+
+func (d *diagnosticsLog) record(fe FailedExchange) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.ring[d.next] = fe
+	d.next = (d.next + 1) % len(d.ring)
+	if d.next == 0 {
+		d.full = true
+	}
+
+	if d.dir != "" {
+		d.persist(fe)
+	}
+}
+
+// persist writes fe to the diagnostics directory. Failures are ignored:
+// diagnostics must never change the outcome of a request.
+func (d *diagnosticsLog) persist(fe FailedExchange) {
+	data, err := json.MarshalIndent(fe, "", "  ")
+	if err != nil {
+		return
+	}
+
+	d.seq++
+	name := filepath.Join(d.dir, fmt.Sprintf("failed-%s-%06d.json", fe.Time.UTC().Format("20060102T150405.000000000Z"), d.seq))
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		return
+	}
+
+	d.files = append(d.files, name)
+	for len(d.files) > len(d.ring) {
+		_ = os.Remove(d.files[0])
+		d.files = d.files[1:]
+	}
+}
+
+// snapshot returns the recorded exchanges, oldest first.
+func (d *diagnosticsLog) snapshot() []FailedExchange {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.full {
+		return append([]FailedExchange(nil), d.ring[:d.next]...)
+	}
+	out := make([]FailedExchange, 0, len(d.ring))
+	out = append(out, d.ring[d.next:]...)
+	return append(out, d.ring[:d.next]...)
+}
+
+// WithDiagnostics keeps the last n failed requests (non-2xx responses and
+// send errors) in memory; retrieve them with Client.Diagnostics.
+// n must be positive.
+func WithDiagnostics(n int) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			return errors.New("diagnostics size must be positive")
+		}
+		c.diagnostics = newDiagnosticsLog(n, "")
+		return nil
+	}
+}
+
+// WithDiagnosticsDir behaves like WithDiagnostics and additionally writes each
+// failed exchange as a JSON file to dir, keeping at most n files written by
+// this client. The directory is created if needed.
+func WithDiagnosticsDir(dir string, n int) Option {
+	return func(c *Client) error {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			return errors.New("diagnostics dir cannot be empty")
+		}
+		if n <= 0 {
+			return errors.New("diagnostics size must be positive")
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("create diagnostics dir: %w", err)
+		}
+		c.diagnostics = newDiagnosticsLog(n, dir)
+		return nil
+	}
+}
+
+// Diagnostics returns the recorded failed exchanges, oldest first.
+// It returns nil unless WithDiagnostics or WithDiagnosticsDir was used.
+func (c *Client) Diagnostics() []FailedExchange {
+	if c == nil || c.diagnostics == nil {
+		return nil
+	}
+	return c.diagnostics.snapshot()
+}
+
+func (c *Client) failureRecorder() transport.FailureRecorder {
+	if c.diagnostics == nil {
+		return nil
+	}
+	return c.diagnostics.record
+}
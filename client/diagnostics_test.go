@@ -0,0 +1,167 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func failingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"message":"boom","code":500}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDiagnostics_DisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok", "proj")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if got := c.Diagnostics(); got != nil {
+		t.Fatalf("Diagnostics() = %v, want nil", got)
+	}
+}
+
+func TestDiagnostics_InvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("tok", "proj", client.WithDiagnostics(0)); err == nil {
+		t.Fatal("WithDiagnostics(0) error = nil, want error")
+	}
+	if _, err := client.NewClient("tok", "proj", client.WithDiagnosticsDir(" ", 3)); err == nil {
+		t.Fatal("WithDiagnosticsDir(empty) error = nil, want error")
+	}
+	if _, err := client.NewClient("tok", "proj", client.WithDiagnosticsDir(t.TempDir(), -1)); err == nil {
+		t.Fatal("WithDiagnosticsDir(n<0) error = nil, want error")
+	}
+}
+
+func TestDiagnostics_RingKeepsLastN(t *testing.T) {
+	t.Parallel()
+
+	srv := failingServer(t)
+	c, err := client.NewClient("secret-token", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(0),
+		client.WithDiagnostics(2),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	for _, p := range []string{"a", "b", "c"} {
+		body := strings.NewReader(`{"path":"` + p + `"}`)
+		if err := c.DoJSONWithRetry(context.Background(), http.MethodPost, p, body, nil); err == nil {
+			t.Fatalf("DoJSONWithRetry(%s) error = nil, want error", p)
+		}
+	}
+
+	got := c.Diagnostics()
+	if len(got) != 2 {
+		t.Fatalf("len(Diagnostics()) = %d, want 2", len(got))
+	}
+	if !strings.HasSuffix(got[0].URL, "/b") || !strings.HasSuffix(got[1].URL, "/c") {
+		t.Fatalf("URLs = %q, %q; want .../b, .../c", got[0].URL, got[1].URL)
+	}
+
+	fe := got[1]
+	if fe.Method != http.MethodPost || fe.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("method/status = %s/%d", fe.Method, fe.StatusCode)
+	}
+	if fe.RequestBody != `{"path":"c"}` {
+		t.Fatalf("RequestBody = %q", fe.RequestBody)
+	}
+	if fe.ResponseBody != `{"message":"boom","code":500}` {
+		t.Fatalf("ResponseBody = %q", fe.ResponseBody)
+	}
+	if fe.Err != "boom" {
+		t.Fatalf("Err = %q, want boom", fe.Err)
+	}
+	if v := fe.RequestHeader.Get("X-Api-Token"); v != "[REDACTED]" {
+		t.Fatalf("X-Api-Token = %q, want redacted", v)
+	}
+	if v := fe.ResponseHeader.Get("Set-Cookie"); v != "[REDACTED]" {
+		t.Fatalf("Set-Cookie = %q, want redacted", v)
+	}
+}
+
+func TestDiagnostics_RecordsEveryRetryAttempt(t *testing.T) {
+	t.Parallel()
+
+	srv := failingServer(t)
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(2),
+		client.WithBackoff(1, 1),
+		client.WithDiagnostics(10),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_ = c.DoJSONWithRetry(context.Background(), http.MethodGet, "x", nil, nil)
+
+	if got := len(c.Diagnostics()); got != 3 {
+		t.Fatalf("len(Diagnostics()) = %d, want 3", got)
+	}
+}
+
+func TestDiagnostics_Dir(t *testing.T) {
+	t.Parallel()
+
+	srv := failingServer(t)
+	dir := filepath.Join(t.TempDir(), "diag")
+	c, err := client.NewClient("secret-token", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(0),
+		client.WithDiagnosticsDir(dir, 2),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	for range 3 {
+		_ = c.DoJSONWithRetry(context.Background(), http.MethodGet, "x", nil, nil)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("files = %d, want 2", len(entries))
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(raw), "secret-token") {
+		t.Fatalf("persisted exchange leaks token: %s", raw)
+	}
+
+	var fe client.FailedExchange
+	if err := json.Unmarshal(raw, &fe); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if fe.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("StatusCode = %d, want 500", fe.StatusCode)
+	}
+	if len(c.Diagnostics()) != 2 {
+		t.Fatalf("len(Diagnostics()) = %d, want 2", len(c.Diagnostics()))
+	}
+}
@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// Resolver looks up the addresses of a host; *net.Resolver implements it.
+// network is "ip", "ip4" or "ip6".
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// DialOptions configures how API calls and bundle downloads open
+// connections; see WithDialer. Zero values keep Go's defaults.
+type DialOptions struct {
+	Timeout   time.Duration // connect timeout (default 30s)
+	KeepAlive time.Duration // TCP keep-alive period (default 15s); negative disables
+
+	// FallbackDelay is how long a dial to the preferred address family
+	// (the family of the first address DNS returns) runs before one to the
+	// other family is raced against it ("Happy Eyeballs"). Lower it where
+	// IPv6 routes look fine but black-hole traffic. Default 300ms;
+	// negative disables the race and tries addresses one by one.
+	FallbackDelay time.Duration
+
+	// Resolver replaces the system resolver, e.g. a *net.Resolver that
+	// talks to a specific DNS server.
+	Resolver Resolver
+
+	// DNSCacheTTL caches successful lookups for this long, so retries
+	// don't re-resolve the host every time. When a lookup fails, the last
+	// known addresses are used even if they expired. Zero disables the
+	// cache.
+	DNSCacheTTL time.Duration
+}
+
+// WithDialer sets how connections are dialed for API calls and bundle
+// downloads, for networks with flaky DNS or broken IPv6:
+//
+//	client.WithDialer(client.DialOptions{
+//		FallbackDelay: 50 * time.Millisecond,
+//		DNSCacheTTL:   time.Minute,
+//	})
+//
+// It replaces the transport's DialContext through WithTransport, so the
+// same rules apply: transports are cloned, never modified, and must be
+// *http.Transport.
+func WithDialer(o DialOptions) Option {
+	return func(c *Client) error {
+		if o.Timeout < 0 {
+			return errors.New("dial timeout cannot be negative")
+		}
+		if o.DNSCacheTTL < 0 {
+			return errors.New("DNS cache TTL cannot be negative")
+		}
+		dial := newDialFunc(o)
+		return WithTransport(func(t *http.Transport) {
+			t.DialContext = dial
+		})(c)
+	}
+}
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// newDialFunc builds the DialContext for o. Without a custom resolver or
+// a cache it is a plain net.Dialer; otherwise lokex resolves hosts itself
+// and dials the addresses.
+func newDialFunc(o DialOptions) dialFunc {
+	if o.Timeout == 0 {
+		o.Timeout = 30 * time.Second
+	}
+	nd := &net.Dialer{
+		Timeout:       o.Timeout,
+		KeepAlive:     o.KeepAlive,
+		FallbackDelay: o.FallbackDelay,
+	}
+	if o.Resolver == nil && o.DNSCacheTTL == 0 {
+		return nd.DialContext
+	}
+
+	r := o.Resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	d := &resolvingDialer{
+		dialer:        nd,
+		fallbackDelay: o.FallbackDelay,
+		lookup:        r.LookupNetIP,
+	}
+	if o.DNSCacheTTL > 0 {
+		cache := &dnsCache{resolver: r, ttl: o.DNSCacheTTL}
+		d.lookup = cache.LookupNetIP
+	}
+	if d.fallbackDelay == 0 {
+		d.fallbackDelay = 300 * time.Millisecond
+	}
+	return d.DialContext
+}
+
+// resolvingDialer dials host:port by resolving host itself, so lookups can
+// go through a custom Resolver or the DNS cache.
+type resolvingDialer struct {
+	dialer        *net.Dialer
+	fallbackDelay time.Duration
+	lookup        func(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+func (d *resolvingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return d.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+	}
+
+	ipNet := "ip"
+	switch network {
+	case "tcp4":
+		ipNet = "ip4"
+	case "tcp6":
+		ipNet = "ip6"
+	}
+	addrs, err := d.lookup(ctx, ipNet, host)
+	if err != nil {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+	}
+	if len(addrs) == 0 {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}}
+	}
+
+	primaries, fallbacks := partitionAddrs(addrs)
+	if len(fallbacks) == 0 || d.fallbackDelay < 0 {
+		return d.dialSerial(ctx, network, append(primaries, fallbacks...), port)
+	}
+	return d.dialParallel(ctx, network, primaries, fallbacks, port)
+}
+
+// partitionAddrs splits addrs into those of the first address's family and
+// the rest, keeping their order.
+func partitionAddrs(addrs []netip.Addr) (primaries, fallbacks []netip.Addr) {
+	first := addrs[0].Unmap().Is4()
+	for _, a := range addrs {
+		a = a.Unmap()
+		if a.Is4() == first {
+			primaries = append(primaries, a)
+		} else {
+			fallbacks = append(fallbacks, a)
+		}
+	}
+	return primaries, fallbacks
+}
+
+// dialSerial tries addrs in order and returns the first connection.
+func (d *resolvingDialer) dialSerial(ctx context.Context, network string, addrs []netip.Addr, port string) (net.Conn, error) {
+	var firstErr error
+	for _, a := range addrs {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(a.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// dialParallel dials the primaries and, after fallbackDelay or once they
+// fail, the fallbacks, returning whichever connects first.
+func (d *resolvingDialer) dialParallel(ctx context.Context, network string, primaries, fallbacks []netip.Addr, port string) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	results := make(chan result, 2)
+	start := func(addrs []netip.Addr, primary bool) {
+		go func() {
+			conn, err := d.dialSerial(ctx, network, addrs, port)
+			results <- result{conn, err, primary}
+		}()
+	}
+
+	start(primaries, true)
+	timer := time.NewTimer(d.fallbackDelay)
+	defer timer.Stop()
+
+	pending, fallbackStarted := 1, false
+	startFallback := func() {
+		if !fallbackStarted {
+			fallbackStarted = true
+			pending++
+			start(fallbacks, false)
+		}
+	}
+
+	var primaryErr, fallbackErr error
+	for {
+		select {
+		case <-timer.C:
+			startFallback()
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if pending > 0 {
+					go func() {
+						if late := <-results; late.conn != nil {
+							_ = late.conn.Close()
+						}
+					}()
+				}
+				return r.conn, nil
+			}
+			if r.primary {
+				primaryErr = r.err
+			} else {
+				fallbackErr = r.err
+			}
+			startFallback()
+			if pending == 0 {
+				if primaryErr != nil {
+					return nil, primaryErr
+				}
+				return nil, fallbackErr
+			}
+		}
+	}
+}
+
+// dnsCache caches lookups of a Resolver for ttl and falls back to expired
+// entries when a fresh lookup fails.
+type dnsCache struct {
+	resolver Resolver
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[dnsKey]dnsEntry
+}
+
+type dnsKey struct{ network, host string }
+
+type dnsEntry struct {
+	addrs   []netip.Addr
+	expires time.Time
+}
+
+func (c *dnsCache) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	key := dnsKey{network, host}
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.addrs, nil
+	}
+
+	addrs, err := c.resolver.LookupNetIP(ctx, network, host)
+	if err != nil || len(addrs) == 0 {
+		if ok {
+			return e.addrs, nil
+		}
+		if err == nil {
+			err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return nil, fmt.Errorf("lookup %s: %w", host, err)
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[dnsKey]dnsEntry)
+	}
+	c.entries[key] = dnsEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return addrs, nil
+}
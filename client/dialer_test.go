@@ -0,0 +1,178 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+type fakeResolver struct {
+	mu      sync.Mutex
+	addrs   []netip.Addr
+	fail    bool
+	lookups int
+}
+
+func (r *fakeResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups++
+	if r.fail {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: host, IsTemporary: true}
+	}
+	return r.addrs, nil
+}
+
+func (r *fakeResolver) setFail(fail bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fail = fail
+}
+
+func (r *fakeResolver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lookups
+}
+
+// dialerTarget starts a server and returns a URL for it on a made-up host,
+// which only the fake resolver knows.
+func dialerTarget(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "http://api.lokex.test:" + u.Port() + "/"
+}
+
+func newDialerClient(t *testing.T, o client.DialOptions) *client.Client {
+	t.Helper()
+	c, err := client.NewClient("token", "project",
+		client.WithDialer(o),
+		client.WithTransport(func(tr *http.Transport) { tr.DisableKeepAlives = true }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func getN(t *testing.T, c *client.Client, target string, n int) {
+	t.Helper()
+	for i := range n {
+		resp, err := c.HTTPClient.Get(target)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		_ = resp.Body.Close()
+	}
+}
+
+func TestWithDialer_CustomResolver(t *testing.T) {
+	t.Parallel()
+
+	r := &fakeResolver{addrs: []netip.Addr{netip.MustParseAddr("127.0.0.1")}}
+	c := newDialerClient(t, client.DialOptions{Resolver: r})
+	getN(t, c, dialerTarget(t), 3)
+
+	if got := r.count(); got != 3 {
+		t.Fatalf("lookups = %d, want 3 without a cache", got)
+	}
+}
+
+func TestWithDialer_DNSCache(t *testing.T) {
+	t.Parallel()
+
+	r := &fakeResolver{addrs: []netip.Addr{netip.MustParseAddr("127.0.0.1")}}
+	c := newDialerClient(t, client.DialOptions{Resolver: r, DNSCacheTTL: time.Hour})
+	getN(t, c, dialerTarget(t), 3)
+
+	if got := r.count(); got != 1 {
+		t.Fatalf("lookups = %d, want 1 with a cache", got)
+	}
+}
+
+func TestWithDialer_DNSCacheServesStaleOnError(t *testing.T) {
+	t.Parallel()
+
+	r := &fakeResolver{addrs: []netip.Addr{netip.MustParseAddr("127.0.0.1")}}
+	c := newDialerClient(t, client.DialOptions{Resolver: r, DNSCacheTTL: time.Millisecond})
+	target := dialerTarget(t)
+	getN(t, c, target, 1)
+
+	time.Sleep(5 * time.Millisecond)
+	r.setFail(true)
+	getN(t, c, target, 1)
+	if got := r.count(); got != 2 {
+		t.Fatalf("lookups = %d, want 2 (expired entry re-resolved)", got)
+	}
+}
+
+func TestWithDialer_LookupError(t *testing.T) {
+	t.Parallel()
+
+	r := &fakeResolver{fail: true}
+	c := newDialerClient(t, client.DialOptions{Resolver: r, DNSCacheTTL: time.Minute})
+	_, err := c.HTTPClient.Get(dialerTarget(t))
+
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsTemporary {
+		t.Fatalf("err = %v, want a temporary *net.DNSError", err)
+	}
+}
+
+func TestWithDialer_FallsBackToOtherFamily(t *testing.T) {
+	t.Parallel()
+
+	// 100::/64 is the IPv6 discard prefix: dials there never succeed.
+	r := &fakeResolver{addrs: []netip.Addr{
+		netip.MustParseAddr("100::1"),
+		netip.MustParseAddr("127.0.0.1"),
+	}}
+	c := newDialerClient(t, client.DialOptions{
+		Resolver:      r,
+		FallbackDelay: 10 * time.Millisecond,
+		Timeout:       5 * time.Second,
+	})
+
+	start := time.Now()
+	getN(t, c, dialerTarget(t), 1)
+	if took := time.Since(start); took > 3*time.Second {
+		t.Fatalf("request took %v, want the IPv4 fallback to win quickly", took)
+	}
+}
+
+func TestWithDialer_Validation(t *testing.T) {
+	t.Parallel()
+
+	for _, o := range []client.DialOptions{
+		{Timeout: -time.Second},
+		{DNSCacheTTL: -time.Second},
+	} {
+		if _, err := client.NewClient("token", "project", client.WithDialer(o)); err == nil {
+			t.Fatalf("WithDialer(%+v): expected error", o)
+		}
+	}
+
+	c, err := client.NewClient("token", "project", client.WithDialer(client.DialOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr, ok := c.HTTPClient.Transport.(*http.Transport); !ok || tr.DialContext == nil {
+		t.Fatalf("transport = %T, want *http.Transport with DialContext", c.HTTPClient.Transport)
+	}
+}
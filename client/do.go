@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// projectIDPlaceholder in a Do path is replaced with the client's ProjectID.
+const projectIDPlaceholder = "{project_id}"
+
+// Do calls an arbitrary Lokalise endpoint with the same plumbing as the
+// wrapped ones: retries and backoff, rate limiting, signing, hooks, and
+// *APIError for non-2xx responses. Use it for endpoints lokex doesn't wrap.
+//
+// path is relative to BaseURL and may carry a query string;
+// "{project_id}" is replaced with the escaped ProjectID:
+//
+//	var resp struct{ Contributors []Contributor }
+//	err := c.Do(ctx, http.MethodGet, "projects/{project_id}/contributors?limit=100", nil, &resp)
+//
+// body may be nil, an io.Reader (sent as-is; buffered for retries unless
+// it is seekable), []byte or json.RawMessage (sent verbatim), or any other
+// value, which is encoded as JSON with the client's codec. If out is
+// non-nil, the JSON response is decoded into it. opts override client
+// settings for this call only (see WithRequestOptions).
+func (c *Client) Do(ctx context.Context, method, path string, body, out any, opts ...RequestOption) error {
+	if c == nil {
+		return errors.New("client is nil")
+	}
+	c = c.WithRequestOptions(opts...)
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		return errors.New("do: empty method")
+	}
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return errors.New("do: empty path")
+	}
+	path = strings.ReplaceAll(path, projectIDPlaceholder, url.PathEscape(c.ProjectID))
+
+	rdr, err := c.doBody(body)
+	if err != nil {
+		return fmt.Errorf("do: %w", err)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return c.DoJSONWithRetry(ctx, method, path, rdr, out)
+}
+
+func (c *Client) doBody(body any) (io.Reader, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+	case io.Reader:
+		return b, nil
+	case json.RawMessage:
+		return bytes.NewReader(b), nil
+	case []byte:
+		return bytes.NewReader(b), nil
+	default:
+		return c.EncodeJSON(b)
+	}
+}
@@ -0,0 +1,126 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+type doRequest struct {
+	method, path, query, body string
+}
+
+// doServer records the last request and answers with status and resp.
+func doServer(t *testing.T, status int, resp string) (*client.Client, *doRequest, *atomic.Int32) {
+	t.Helper()
+
+	var (
+		got   doRequest
+		calls atomic.Int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		b, _ := io.ReadAll(r.Body)
+		got = doRequest{r.Method, r.URL.EscapedPath(), r.URL.RawQuery, string(b)}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("test-token", "123.abc:feat/x",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(2),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, &got, &calls
+}
+
+func TestClient_Do(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     any
+		wantBody string
+	}{
+		{"nil body", nil, ""},
+		{"struct encoded as JSON", struct {
+			Email string `json:"email"`
+		}{"a@b.c"}, `{"email":"a@b.c"}`},
+		{"raw JSON", json.RawMessage(`{"raw":true}`), `{"raw":true}`},
+		{"bytes", []byte(`[1,2]`), `[1,2]`},
+		{"reader", strings.NewReader(`{"r":1}`), `{"r":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, got, _ := doServer(t, http.StatusOK, `{"contributors":[{"email":"a@b.c"}]}`)
+
+			var out struct {
+				Contributors []struct {
+					Email string `json:"email"`
+				} `json:"contributors"`
+			}
+			err := c.Do(context.Background(), "post", "projects/{project_id}/contributors?limit=100", tt.body, &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.method != http.MethodPost || got.path != "/projects/123.abc:feat%2Fx/contributors" || got.query != "limit=100" {
+				t.Fatalf("request = %+v", *got)
+			}
+			if strings.TrimSpace(got.body) != tt.wantBody {
+				t.Fatalf("body = %q, want %q", got.body, tt.wantBody)
+			}
+			if len(out.Contributors) != 1 || out.Contributors[0].Email != "a@b.c" {
+				t.Fatalf("out = %+v", out)
+			}
+		})
+	}
+}
+
+func TestClient_Do_ErrorsAndRetries(t *testing.T) {
+	t.Parallel()
+
+	c, _, calls := doServer(t, http.StatusServiceUnavailable, `{"error":{"message":"down","code":503}}`)
+	err := c.Do(context.Background(), http.MethodGet, "/system/languages", nil, nil)
+
+	var ae *client.APIError
+	if !errors.As(err, &ae) || ae.Status != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want *APIError 503", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("calls = %d, want 3 (1 + 2 retries)", calls.Load())
+	}
+}
+
+func TestClient_Do_Validation(t *testing.T) {
+	t.Parallel()
+
+	c, _, calls := doServer(t, http.StatusOK, `{}`)
+	ctx := context.Background()
+	if err := c.Do(ctx, " ", "projects", nil, nil); err == nil {
+		t.Fatal("empty method: expected error")
+	}
+	if err := c.Do(ctx, http.MethodGet, "", nil, nil); err == nil {
+		t.Fatal("empty path: expected error")
+	}
+	if err := c.Do(ctx, http.MethodPost, "projects", func() {}, nil); err == nil {
+		t.Fatal("unencodable body: expected error")
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("calls = %d, want 0", calls.Load())
+	}
+}
@@ -0,0 +1,99 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// snapshotCleanupTimeout bounds deleting the restored project, which runs
+// even after ctx is done.
+const snapshotCleanupTimeout = 30 * time.Second
+
+// SnapshotOptions configures DownloadAtSnapshot.
+type SnapshotOptions struct {
+	Async       bool // export with DownloadAsync instead of Download
+	KeepProject bool // leave the restored project in place instead of deleting it
+}
+
+// SnapshotExport describes a DownloadAtSnapshot run.
+type SnapshotExport struct {
+	BundleURL string
+	ProjectID string // the project the snapshot was restored into
+}
+
+// DownloadAtSnapshot exports the project as it was when snapshotID was
+// taken, so builds can be pinned to a translation snapshot:
+//
+//  1. POST /projects/{id}/snapshots/{snapshot_id}, which restores the
+//     snapshot into a new project (a copy; the source project is untouched)
+//  2. export params from that copy with Download or DownloadAsync
+//  3. DELETE the copy, unless opts.KeepProject is set
+//
+// The restore is never retried, so a flaky call can't leave several copies
+// behind. The copy counts towards the team's project quota while it exists.
+// If deleting it fails, the returned error says which project to remove by
+// hand; the export itself may still have succeeded.
+func (d *Downloader) DownloadAtSnapshot(
+	ctx context.Context,
+	unzipTo, snapshotID string,
+	params DownloadParams,
+	opts SnapshotOptions,
+) (res SnapshotExport, err error) {
+	if d == nil || d.client == nil {
+		return SnapshotExport{}, errors.New(clientIsNilMsg)
+	}
+	snapshotID = strings.TrimSpace(snapshotID)
+	if snapshotID == "" {
+		return SnapshotExport{}, errors.New("download: empty snapshot ID")
+	}
+	if strings.TrimSpace(unzipTo) == "" {
+		return SnapshotExport{}, errors.New("download: empty unzip destination")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	restorer := *d.client
+	restorer.MaxRetries = 0
+
+	var restored struct {
+		ProjectID string `json:"project_id"`
+	}
+	path := utils.ProjectPath(d.client.ProjectID, "snapshots/"+url.PathEscape(snapshotID))
+	if err := restorer.DoJSONWithRetry(ctx, http.MethodPost, path, nil, &restored); err != nil {
+		return SnapshotExport{}, fmt.Errorf("download: restore snapshot %s: %w", snapshotID, err)
+	}
+	res.ProjectID = strings.TrimSpace(restored.ProjectID)
+	if res.ProjectID == "" {
+		return SnapshotExport{}, fmt.Errorf("download: restore snapshot %s: response has no project_id", snapshotID)
+	}
+
+	if !opts.KeepProject {
+		defer func() {
+			dctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), snapshotCleanupTimeout)
+			defer cancel()
+			path := "projects/" + url.PathEscape(res.ProjectID)
+			if derr := d.client.DoJSONWithRetry(dctx, http.MethodDelete, path, nil, nil); derr != nil {
+				err = errors.Join(err, fmt.Errorf("download: delete restored project %s (remove it by hand): %w", res.ProjectID, derr))
+			}
+		}()
+	}
+
+	// A copy of d keeps its options (quota, line endings, ...).
+	sd := *d
+	sd.client = d.client.ForProject(res.ProjectID)
+
+	fetch := sd.Download
+	if opts.Async {
+		fetch = sd.DownloadAsync
+	}
+	res.BundleURL, err = fetch(ctx, unzipTo, params)
+	return res, err
+}
@@ -0,0 +1,175 @@
+package download_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+
+	"github.com/jarcoal/httpmock"
+)
+
+const (
+	restoredProjectID = "999.restored"
+	snapshotCDNURL    = "https://cdn.example.com/snapshot.zip"
+)
+
+const (
+	restoredURL     = "https://api.lokalise.com/api2/projects/" + restoredProjectID
+	restoredDLURL   = restoredURL + "/files/download"
+	restoredCallKey = "DELETE " + restoredURL
+)
+
+// restoreURL is the endpoint restoring snapshot 42 of projectID, which is
+// only known after init.
+func restoreURL() string {
+	return "https://api.lokalise.com/api2/projects/" + projectID + "/snapshots/42"
+}
+
+// snapshotAPI mocks restoring snapshot 42 into restoredProjectID, a sync
+// export from it and its deletion.
+func snapshotAPI(t *testing.T) {
+	t.Helper()
+	httpmock.RegisterResponder("POST", restoreURL(),
+		httpmock.NewStringResponder(200, `{"project_id":"`+restoredProjectID+`","name":"copy"}`))
+	httpmock.RegisterResponder("POST", restoredDLURL,
+		httpmock.NewStringResponder(200, `{"bundle_url":"`+snapshotCDNURL+`"}`))
+	registerZipResponder(t, snapshotCDNURL, buildZip(t, map[string]string{"en/app.json": `{"a":"then"}`}, nil))
+	httpmock.RegisterResponder("DELETE", restoredURL,
+		httpmock.NewStringResponder(200, `{"project_id":"`+restoredProjectID+`","project_deleted":true}`))
+}
+
+func TestDownloader_DownloadAtSnapshot(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	snapshotAPI(t)
+
+	dir := t.TempDir()
+	res, err := newDeltaDownloader(t).DownloadAtSnapshot(context.Background(), dir, " 42 ",
+		download.DownloadParams{"format": "json"}, download.SnapshotOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ProjectID != restoredProjectID || res.BundleURL != snapshotCDNURL {
+		t.Fatalf("result = %+v", res)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "en", "app.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `{"a":"then"}` {
+		t.Fatalf("en/app.json = %s", got)
+	}
+
+	calls := httpmock.GetCallCountInfo()
+	if calls["POST "+restoredDLURL] != 1 {
+		t.Fatalf("export from the restored project: %d calls, want 1", calls["POST "+restoredDLURL])
+	}
+	if calls[restoredCallKey] != 1 {
+		t.Fatalf("delete restored project: %d calls, want 1", calls[restoredCallKey])
+	}
+}
+
+func TestDownloader_DownloadAtSnapshot_KeepProject(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	snapshotAPI(t)
+
+	res, err := newDeltaDownloader(t).DownloadAtSnapshot(context.Background(), t.TempDir(), "42",
+		download.DownloadParams{"format": "json"}, download.SnapshotOptions{KeepProject: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ProjectID != restoredProjectID {
+		t.Fatalf("ProjectID = %q", res.ProjectID)
+	}
+	if n := httpmock.GetCallCountInfo()[restoredCallKey]; n != 0 {
+		t.Fatalf("restored project deleted %d times despite KeepProject", n)
+	}
+}
+
+func TestDownloader_DownloadAtSnapshot_KeepsOptions(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	snapshotAPI(t)
+	registerZipResponder(t, snapshotCDNURL, buildZip(t, map[string]string{"en/app.txt": "a\nb\n"}, nil))
+
+	dir := t.TempDir()
+	d := newDeltaDownloader(t).WithLineEndings(client.LineEndingCRLF)
+	if _, err := d.DownloadAtSnapshot(context.Background(), dir, "42", download.DownloadParams{"format": "json"}, download.SnapshotOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "en", "app.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "a\r\nb\r\n" {
+		t.Fatalf("en/app.txt = %q, want CRLF line endings", got)
+	}
+}
+
+func TestDownloader_DownloadAtSnapshot_RestoreNotRetried(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", restoreURL(),
+		httpmock.NewStringResponder(500, `{"error":{"message":"boom","code":500}}`))
+
+	cli, err := client.NewClient(token, projectID,
+		client.WithMaxRetries(3), client.WithBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = download.NewDownloader(cli).DownloadAtSnapshot(context.Background(), t.TempDir(), "42",
+		download.DownloadParams{"format": "json"}, download.SnapshotOptions{})
+	if err == nil || !strings.Contains(err.Error(), "restore snapshot 42") {
+		t.Fatalf("err = %v, want restore error", err)
+	}
+	if n := httpmock.GetCallCountInfo()["POST "+restoreURL()]; n != 1 {
+		t.Fatalf("restore called %d times, want 1", n)
+	}
+}
+
+func TestDownloader_DownloadAtSnapshot_DeletesAfterFailedExport(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	snapshotAPI(t)
+	httpmock.RegisterResponder("POST", restoredDLURL,
+		httpmock.NewStringResponder(400, `{"error":{"message":"bad format","code":400}}`))
+	httpmock.RegisterResponder("DELETE", restoredURL,
+		httpmock.NewStringResponder(403, `{"error":{"message":"forbidden","code":403}}`))
+
+	_, err := newDeltaDownloader(t).DownloadAtSnapshot(context.Background(), t.TempDir(), "42",
+		download.DownloadParams{"format": "nope"}, download.SnapshotOptions{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "bad format") || !strings.Contains(msg, "delete restored project "+restoredProjectID) {
+		t.Fatalf("err = %v, want both the export and the cleanup failure", err)
+	}
+}
+
+func TestDownloader_DownloadAtSnapshot_Validation(t *testing.T) {
+	t.Parallel()
+	d := newDeltaDownloader(t)
+
+	if _, err := d.DownloadAtSnapshot(context.Background(), t.TempDir(), " ", nil, download.SnapshotOptions{}); err == nil ||
+		!strings.Contains(err.Error(), "empty snapshot ID") {
+		t.Fatalf("err = %v, want empty snapshot ID", err)
+	}
+	if _, err := d.DownloadAtSnapshot(context.Background(), "", "42", nil, download.SnapshotOptions{}); err == nil ||
+		!strings.Contains(err.Error(), "empty unzip destination") {
+		t.Fatalf("err = %v, want empty unzip destination", err)
+	}
+
+	var nilD *download.Downloader
+	if _, err := nilD.DownloadAtSnapshot(context.Background(), t.TempDir(), "42", nil, download.SnapshotOptions{}); err == nil {
+		t.Fatal("expected error for nil downloader")
+	}
+}
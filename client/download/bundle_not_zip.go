@@ -0,0 +1,69 @@
+package download
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+// ErrBundleNotZip matches (via errors.Is) the *BundleNotZipError returned
+// when a bundle GET succeeds but the body is not a zip archive.
+var ErrBundleNotZip = errors.New("download: bundle is not a zip")
+
+// bundleSniffLen is how many leading bytes are inspected and kept for errors.
+const bundleSniffLen = 512
+
+// bundleErrPreview caps the leading bytes quoted in Error().
+const bundleErrPreview = 64
+
+// BundleNotZipError describes a 2xx bundle response that is not a zip,
+// typically an HTML error page served by a CDN edge. Unless a custom retry
+// policy is set, it is retried like a truncated download (see
+// retryableBundleErr); after retries it is returned for debugging.
+type BundleNotZipError struct {
+	ContentType string
+	Head        []byte // up to 512 leading bytes of the body
+}
+
+func (e *BundleNotZipError) Error() string {
+	head := e.Head
+	if len(head) > bundleErrPreview {
+		head = head[:bundleErrPreview]
+	}
+	return fmt.Sprintf("download: bundle is not a zip (content-type %q, first bytes %q)", e.ContentType, head)
+}
+
+// Is reports whether target is ErrBundleNotZip.
+func (e *BundleNotZipError) Is(target error) bool {
+	return target == ErrBundleNotZip
+}
+
+// retryableBundleErr is the retry classification of bundle downloads: the
+// client's default, plus responses that are not zips, which a CDN edge
+// usually stops serving on the next attempt.
+func retryableBundleErr(err error) bool {
+	return errors.Is(err, ErrBundleNotZip) || apierr.IsRetryable(err)
+}
+
+// sniffBundle peeks at the start of body and rejects responses that cannot be
+// a zip: anything served as text/html, or a body that does not start with
+// the "PK" signature. Empty bodies are left to zip validation. The returned
+// reader yields the full body.
+func sniffBundle(contentType string, body io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(body, bundleSniffLen)
+	head, _ := br.Peek(bundleSniffLen)
+
+	media, _, _ := mime.ParseMediaType(contentType)
+	isHTML := strings.EqualFold(media, "text/html")
+
+	if isHTML || (len(head) >= 2 && !bytes.HasPrefix(head, []byte("PK"))) {
+		return nil, &BundleNotZipError{ContentType: contentType, Head: bytes.Clone(head)}
+	}
+	return br, nil
+}
@@ -0,0 +1,96 @@
+package download_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+)
+
+func TestDownloadAndUnzip_HTMLErrorPage_TypedErrorAfterRetries(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	page := "<!DOCTYPE html><html><body>502 Bad Gateway from edge " + strings.Repeat("x", 1000) + "</body></html>"
+	url := "https://cdn.example.com/html.zip"
+	httpmock.RegisterResponder("GET", url, func(*http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(200, page)
+		resp.Header.Set("Content-Type", "text/html; charset=utf-8")
+		return resp, nil
+	})
+
+	cli, _ := client.NewClient(token, projectID,
+		client.WithMaxRetries(2),
+		client.WithBackoff(time.Millisecond, 2*time.Millisecond),
+	)
+
+	err := download.NewDownloader(cli).DownloadAndUnzip(context.Background(), url, t.TempDir())
+	if !errors.Is(err, download.ErrBundleNotZip) {
+		t.Fatalf("error = %v, want ErrBundleNotZip", err)
+	}
+	if got := httpmock.GetCallCountInfo()["GET "+url]; got != 3 {
+		t.Fatalf("attempts = %d, want 3 (retried)", got)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("error = %v, should not match io.ErrUnexpectedEOF", err)
+	}
+
+	var nz *download.BundleNotZipError
+	if !errors.As(err, &nz) {
+		t.Fatalf("error %T is not *BundleNotZipError", err)
+	}
+	if nz.ContentType != "text/html; charset=utf-8" {
+		t.Fatalf("ContentType = %q", nz.ContentType)
+	}
+	if len(nz.Head) != 512 || !strings.HasPrefix(string(nz.Head), "<!DOCTYPE html>") {
+		t.Fatalf("Head = %d bytes %q", len(nz.Head), nz.Head[:min(len(nz.Head), 20)])
+	}
+	if !strings.Contains(err.Error(), `first bytes "<!DOCTYPE html><html><body>502 Bad Gateway`) {
+		t.Fatalf("Error() = %q, want quoted first bytes", err.Error())
+	}
+}
+
+func TestDownloadAndUnzip_HTMLContentTypeRejectedEvenIfZip(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	okZip := buildZip(t, map[string]string{"a.txt": "a"}, nil)
+	url := "https://cdn.example.com/mislabeled.zip"
+	httpmock.RegisterResponder("GET", url, func(*http.Request) (*http.Response, error) {
+		resp := httpmock.NewBytesResponse(200, okZip)
+		resp.Header.Set("Content-Type", "TEXT/HTML")
+		return resp, nil
+	})
+
+	cli, _ := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	err := download.NewDownloader(cli).DownloadAndUnzip(context.Background(), url, t.TempDir())
+	if !errors.Is(err, download.ErrBundleNotZip) {
+		t.Fatalf("error = %v, want ErrBundleNotZip", err)
+	}
+}
+
+func TestDownloadAndUnzip_OctetStreamZipAccepted(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	okZip := buildZip(t, map[string]string{"a.txt": "a"}, nil)
+	url := "https://cdn.example.com/ok.zip"
+	httpmock.RegisterResponder("GET", url, func(*http.Request) (*http.Response, error) {
+		resp := httpmock.NewBytesResponse(200, okZip)
+		resp.Header.Set("Content-Type", "application/zip")
+		return resp, nil
+	})
+
+	cli, _ := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err := download.NewDownloader(cli).DownloadAndUnzip(context.Background(), url, t.TempDir()); err != nil {
+		t.Fatalf("DownloadAndUnzip() error = %v", err)
+	}
+}
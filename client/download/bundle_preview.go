@@ -0,0 +1,111 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// BundleLanguage is a project language as it appears in exported paths.
+// Name is only needed for templates that use %LANG_NAME%.
+type BundleLanguage struct {
+	ISO  string
+	Name string
+}
+
+// BundleLayout describes an export well enough to expand path templates
+// locally, without requesting a bundle.
+type BundleLayout struct {
+	ProjectName string // substituted for %PROJECT_NAME%
+	Format      string // file extension substituted for %FORMAT% (e.g. "json", "yml")
+	Languages   []BundleLanguage
+}
+
+// PreviewPath is one expanded bundle path.
+type PreviewPath struct {
+	LangISO string
+	Path    string
+}
+
+// PreviewStructure expands a bundle_structure template for every language,
+// returning the paths an export with original_filenames=false would produce.
+// The template is checked with ValidateBundleStructure first.
+func (l BundleLayout) PreviewStructure(template string) ([]PreviewPath, error) {
+	if err := ValidateBundleStructure(template); err != nil {
+		return nil, err
+	}
+	return l.expand([]string{template})
+}
+
+// PreviewFilenames expands Lokalise filenames (which may contain placeholders,
+// e.g. "%LANG_ISO%/app.json") for every language, as an export with
+// original_filenames=true would. Use Collisions on the result to spot
+// filenames that several languages would write to.
+func (l BundleLayout) PreviewFilenames(filenames ...string) ([]PreviewPath, error) {
+	if len(filenames) == 0 {
+		return nil, errors.New("bundle preview: no filenames")
+	}
+	return l.expand(filenames)
+}
+
+func (l BundleLayout) expand(templates []string) ([]PreviewPath, error) {
+	if len(l.Languages) == 0 {
+		return nil, errors.New("bundle preview: no languages")
+	}
+
+	out := make([]PreviewPath, 0, len(templates)*len(l.Languages))
+	for _, tpl := range templates {
+		for _, lang := range l.Languages {
+			p, err := l.expandOne(tpl, lang)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, PreviewPath{LangISO: lang.ISO, Path: p})
+		}
+	}
+	return out, nil
+}
+
+func (l BundleLayout) expandOne(tpl string, lang BundleLanguage) (string, error) {
+	if strings.TrimSpace(lang.ISO) == "" {
+		return "", errors.New("bundle preview: language with empty ISO code")
+	}
+
+	values := map[string]string{
+		PlaceholderLangISO:     lang.ISO,
+		PlaceholderLangName:    lang.Name,
+		PlaceholderFormat:      l.Format,
+		PlaceholderProjectName: l.ProjectName,
+	}
+
+	pairs := make([]string, 0, 2*len(values))
+	for ph, v := range values {
+		if !strings.Contains(tpl, ph) {
+			continue
+		}
+		if strings.TrimSpace(v) == "" {
+			return "", fmt.Errorf("bundle preview: %q uses %s but no value is set", tpl, ph)
+		}
+		pairs = append(pairs, ph, v)
+	}
+
+	return path.Clean(strings.NewReplacer(pairs...).Replace(tpl)), nil
+}
+
+// Collisions reports paths that more than one language would be written to,
+// mapped to the ISO codes involved.
+func Collisions(paths []PreviewPath) map[string][]string {
+	byPath := make(map[string][]string)
+	for _, p := range paths {
+		byPath[p.Path] = append(byPath[p.Path], p.LangISO)
+	}
+
+	out := make(map[string][]string)
+	for p, langs := range byPath {
+		if len(langs) > 1 {
+			out[p] = langs
+		}
+	}
+	return out
+}
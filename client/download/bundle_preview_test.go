@@ -0,0 +1,119 @@
+package download_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/download"
+)
+
+var previewLayout = download.BundleLayout{
+	ProjectName: "shop",
+	Format:      "json",
+	Languages: []download.BundleLanguage{
+		{ISO: "en", Name: "English"},
+		{ISO: "fr_CA", Name: "French (Canada)"},
+	},
+}
+
+func TestBundleLayout_PreviewStructure(t *testing.T) {
+	t.Parallel()
+
+	got, err := previewLayout.PreviewStructure("./locales/%PROJECT_NAME%/%LANG_ISO%.%FORMAT%")
+	if err != nil {
+		t.Fatalf("PreviewStructure() error = %v", err)
+	}
+	want := []download.PreviewPath{
+		{LangISO: "en", Path: "locales/shop/en.json"},
+		{LangISO: "fr_CA", Path: "locales/shop/fr_CA.json"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("PreviewStructure() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBundleLayout_PreviewStructure_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		layout  download.BundleLayout
+		tpl     string
+		wantErr string
+	}{
+		{
+			name:    "invalid template",
+			layout:  previewLayout,
+			tpl:     "%LANG%.json",
+			wantErr: "does not contain %LANG_ISO%",
+		},
+		{
+			name:    "no languages",
+			layout:  download.BundleLayout{Format: "json"},
+			tpl:     "%LANG_ISO%.json",
+			wantErr: "no languages",
+		},
+		{
+			name:    "missing format",
+			layout:  download.BundleLayout{Languages: previewLayout.Languages},
+			tpl:     "%LANG_ISO%.%FORMAT%",
+			wantErr: "uses %FORMAT% but no value is set",
+		},
+		{
+			name: "missing language name",
+			layout: download.BundleLayout{
+				Languages: []download.BundleLanguage{{ISO: "en"}},
+			},
+			tpl:     "%LANG_NAME%/%LANG_ISO%.json",
+			wantErr: "uses %LANG_NAME%",
+		},
+		{
+			name: "empty iso",
+			layout: download.BundleLayout{
+				Languages: []download.BundleLanguage{{ISO: " "}},
+			},
+			tpl:     "%LANG_ISO%.json",
+			wantErr: "empty ISO code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.layout.PreviewStructure(tt.tpl)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("PreviewStructure() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBundleLayout_PreviewFilenames(t *testing.T) {
+	t.Parallel()
+
+	got, err := previewLayout.PreviewFilenames("%LANG_ISO%/app.json", "shared.json")
+	if err != nil {
+		t.Fatalf("PreviewFilenames() error = %v", err)
+	}
+	want := []download.PreviewPath{
+		{LangISO: "en", Path: "en/app.json"},
+		{LangISO: "fr_CA", Path: "fr_CA/app.json"},
+		{LangISO: "en", Path: "shared.json"},
+		{LangISO: "fr_CA", Path: "shared.json"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("PreviewFilenames() = %+v, want %+v", got, want)
+	}
+
+	coll := download.Collisions(got)
+	wantColl := map[string][]string{"shared.json": {"en", "fr_CA"}}
+	if !reflect.DeepEqual(coll, wantColl) {
+		t.Fatalf("Collisions() = %v, want %v", coll, wantColl)
+	}
+
+	if _, err := previewLayout.PreviewFilenames(); err == nil {
+		t.Fatal("PreviewFilenames() with no filenames error = nil, want error")
+	}
+}
@@ -0,0 +1,250 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrDeltaFormat is returned by DownloadDelta for export formats whose files
+// it cannot merge.
+var ErrDeltaFormat = errors.New("download: delta: format cannot be merged")
+
+// DeltaResult describes what DownloadDelta did.
+type DeltaResult struct {
+	ChangedKeys []string // names of keys modified since the cutoff, sorted
+	Files       []string // files written or merged, relative to the destination
+	BundleURL   string   // empty when no key changed
+}
+
+// ChangedKeyNames returns the names (every per-platform variant) of keys
+// whose name, attributes or translations changed at or after since.
+// Lokalise timestamps have one-second resolution, so a key modified in the
+// same second as since is included rather than risk missing it. Lokalise
+// has no "updated since" export filter, so this is how a delta is selected.
+func (d *Downloader) ChangedKeyNames(ctx context.Context, since time.Time) ([]string, error) {
+	if d == nil || d.client == nil {
+		return nil, errors.New(clientIsNilMsg)
+	}
+
+	cutoff := since.Unix()
+	seen := make(map[string]struct{})
+	err := d.walkKeyStamps(ctx, func(k keyStamp) {
+		if k.modified() < cutoff {
+			return
+		}
+		for _, name := range k.KeyName.Values() {
+			seen[name] = struct{}{}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("download: changed keys: %w", err)
+	}
+	return slices.Sorted(maps.Keys(seen)), nil
+}
+
+// DeltaOptions configures DownloadDelta.
+type DeltaOptions struct {
+	// Since selects keys whose key or translations were modified at or
+	// after it (see ChangedKeyNames).
+	Since time.Time
+	// Removed lists key names to delete from local files, for example from
+	// "project.keys.deleted" webhooks. Names are dot-separated paths that
+	// match flat ("home.title") or nested ({"home":{"title":...}}) keys.
+	// They are deleted from the files the delta merges, and from the
+	// existing files RemoveFrom selects.
+	Removed []string
+	// RemoveFrom selects the local files, besides those in the delta, that
+	// Removed keys are deleted from: slash-separated path.Match patterns
+	// relative to the destination, such as "*.json" or "*/app.json". Only
+	// files with a merger are considered. Empty means none, so files the
+	// export never produced (e.g. package.json) are left alone.
+	RemoveFrom []string
+	// Mergers maps file extensions (".yml") to mergers, added to and
+	// overriding the built-in JSONMerger for ".json".
+	Mergers map[string]Merger
+}
+
+// DownloadDelta exports only the keys changed after opts.Since (see
+// ChangedKeyNames) and merges them into the files already in unzipTo,
+// instead of replacing the destination wholesale. Keys in existing files
+// that are not part of the delta are kept; files new to the destination are
+// written as-is; opts.Removed keys are deleted from the merged files and
+// from the files opts.RemoveFrom selects. If
+// params already set filter_keys, the delta is narrowed to those keys. The
+// export format needs a merger for its files (json is built in).
+func (d *Downloader) DownloadDelta(ctx context.Context, unzipTo string, params DownloadParams, opts DeltaOptions) (DeltaResult, error) {
+	if d == nil || d.client == nil {
+		return DeltaResult{}, errors.New(clientIsNilMsg)
+	}
+	if strings.TrimSpace(unzipTo) == "" {
+		return DeltaResult{}, errors.New("download: empty unzip destination")
+	}
+
+	mergers := map[string]Merger{".json": JSONMerger{}}
+	maps.Copy(mergers, opts.Mergers)
+	format, _ := params["format"].(string)
+	if !slices.ContainsFunc(extsForFormat(format), func(ext string) bool { return mergers[ext] != nil }) {
+		return DeltaResult{}, fmt.Errorf("%w: %q (no merger for its files)", ErrDeltaFormat, format)
+	}
+	for _, pat := range opts.RemoveFrom {
+		if _, err := path.Match(pat, ""); err != nil {
+			return DeltaResult{}, fmt.Errorf("download: delta: RemoveFrom %q: %w", pat, err)
+		}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	changed, err := d.ChangedKeyNames(ctx, opts.Since)
+	if err != nil {
+		return DeltaResult{}, err
+	}
+	if only, ok := params["filter_keys"].([]string); ok {
+		changed = slices.DeleteFunc(changed, func(name string) bool { return !slices.Contains(only, name) })
+	}
+	res := DeltaResult{ChangedKeys: changed}
+	m := treeMerger{dst: unzipTo, mergers: mergers, removed: opts.Removed, removeFrom: opts.RemoveFrom}
+
+	if len(changed) > 0 {
+		deltaParams := maps.Clone(params)
+		deltaParams["filter_keys"] = changed
+
+		tmpDir, cleanup, err := createDownloadTempDir()
+		if err != nil {
+			return DeltaResult{}, err
+		}
+		defer cleanup()
+
+		res.BundleURL, err = d.Download(ctx, tmpDir, deltaParams)
+		if err != nil {
+			return DeltaResult{}, err
+		}
+		if err := m.mergeTree(tmpDir); err != nil {
+			res.Files = m.written
+			return res, err
+		}
+	}
+
+	err = m.applyRemovals()
+	res.Files = m.written
+	return res, err
+}
+
+// treeMerger merges delta files into dst and records what it wrote.
+type treeMerger struct {
+	dst        string
+	mergers    map[string]Merger
+	removed    []string
+	removeFrom []string // path.Match patterns; see DeltaOptions.RemoveFrom
+	written    []string
+}
+
+// mergeTree merges every file under src into the same path under dst.
+func (m *treeMerger) mergeTree(src string) error {
+	err := filepath.WalkDir(src, func(p string, e fs.DirEntry, err error) error {
+		if err != nil || e.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if err := m.mergeFile(p, rel); err != nil {
+			return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+		}
+		m.written = append(m.written, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("download: delta: merge: %w", err)
+	}
+	return nil
+}
+
+func (m *treeMerger) mergeFile(srcPath, rel string) error {
+	delta, err := os.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+	dstPath := filepath.Join(m.dst, rel)
+	existing, err := os.ReadFile(dstPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := mkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+			return err
+		}
+		return writeHTTPBodyAtomically(dstPath, bytes.NewReader(delta), int64(len(delta)))
+	case err != nil:
+		return err
+	}
+
+	merger := m.mergers[strings.ToLower(filepath.Ext(rel))]
+	if merger == nil {
+		return fmt.Errorf("no merger for %q files", filepath.Ext(rel))
+	}
+	out, err := merger.Merge(existing, delta, m.removed)
+	if err != nil {
+		return err
+	}
+	return writeHTTPBodyAtomically(dstPath, bytes.NewReader(out), int64(len(out)))
+}
+
+// applyRemovals deletes the removed keys from the mergeable files under dst
+// that removeFrom selects and the delta did not touch.
+func (m *treeMerger) applyRemovals() error {
+	if len(m.removed) == 0 || len(m.removeFrom) == 0 {
+		return nil
+	}
+	err := filepath.WalkDir(m.dst, func(p string, e fs.DirEntry, err error) error {
+		if err != nil || e.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(m.dst, p)
+		if err != nil {
+			return err
+		}
+		merger := m.mergers[strings.ToLower(filepath.Ext(rel))]
+		if merger == nil || !m.selected(filepath.ToSlash(rel)) || slices.Contains(m.written, filepath.ToSlash(rel)) {
+			return nil
+		}
+
+		existing, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		out, err := merger.Merge(existing, nil, m.removed)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+		}
+		if bytes.Equal(out, existing) {
+			return nil
+		}
+		if err := writeHTTPBodyAtomically(p, bytes.NewReader(out), int64(len(out))); err != nil {
+			return err
+		}
+		m.written = append(m.written, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("download: delta: remove keys: %w", err)
+	}
+	return nil
+}
+
+// selected reports whether rel matches one of the removeFrom patterns.
+func (m *treeMerger) selected(rel string) bool {
+	return slices.ContainsFunc(m.removeFrom, func(pat string) bool {
+		ok, _ := path.Match(pat, rel)
+		return ok
+	})
+}
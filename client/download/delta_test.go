@@ -0,0 +1,300 @@
+package download_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+
+	"github.com/jarcoal/httpmock"
+)
+
+const deltaKeysPage = `{"keys":[
+	{"key_name":"old","modified_at_timestamp":100,"translations_modified_at_timestamp":100},
+	{"key_name":"home.title","modified_at_timestamp":100,"translations_modified_at_timestamp":300},
+	{"key_name":{"ios":"ios.new","android":"android_new","web":"web.new","other":"web.new"},"modified_at_timestamp":250}
+]}`
+
+// deltaAPI mocks the keys list and a sync export whose bundle is zipEntries;
+// it records the filter_keys of every export.
+func deltaAPI(t *testing.T, zipEntries map[string]string) *[][]string {
+	t.Helper()
+
+	var filters [][]string
+	httpmock.RegisterResponder("GET", `=~/projects/.+/keys`, func(*http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(200, deltaKeysPage), nil
+	})
+
+	cdnURL := "https://cdn.example.com/delta.zip"
+	postURL := fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/files/download", projectID)
+	httpmock.RegisterResponder("POST", postURL, func(req *http.Request) (*http.Response, error) {
+		var body struct {
+			FilterKeys []string `json:"filter_keys"`
+		}
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		filters = append(filters, body.FilterKeys)
+		return httpmock.NewStringResponse(200, `{"bundle_url":"`+cdnURL+`"}`), nil
+	})
+	registerZipResponder(t, cdnURL, buildZip(t, zipEntries, nil))
+	return &filters
+}
+
+func newDeltaDownloader(t *testing.T) *download.Downloader {
+	t.Helper()
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return download.NewDownloader(cli)
+}
+
+func TestDownloader_ChangedKeyNames(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	deltaAPI(t, map[string]string{})
+
+	got, err := newDeltaDownloader(t).ChangedKeyNames(context.Background(), time.Unix(200, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"android_new", "home.title", "ios.new", "web.new"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ChangedKeyNames() = %v, want %v", got, want)
+	}
+}
+
+func TestDownloader_ChangedKeyNames_CutoffSecondIncluded(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	deltaAPI(t, map[string]string{})
+
+	got, err := newDeltaDownloader(t).ChangedKeyNames(context.Background(), time.Unix(300, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"home.title"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ChangedKeyNames(300) = %v, want %v (modified at the cutoff)", got, want)
+	}
+
+	got, err = newDeltaDownloader(t).ChangedKeyNames(context.Background(), time.Unix(301, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("ChangedKeyNames(301) = %v, want none", got)
+	}
+}
+
+func TestDownloader_DownloadDelta_MergesIntoExistingFiles(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	filters := deltaAPI(t, map[string]string{
+		"en.json":         `{"home":{"title":"New <b>title</b>"},"web.new":"Fresh"}`,
+		"locales/fr.json": `{"home":{"title":"Nouveau"}}`,
+	})
+
+	dest := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dest, "en.json"), []byte(`{"old":"Keep","home":{"title":"Old","subtitle":"Sub"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := newDeltaDownloader(t).DownloadDelta(context.Background(), dest, download.DownloadParams{"format": "json"}, download.DeltaOptions{Since: time.Unix(200, 0)})
+	if err != nil {
+		t.Fatalf("DownloadDelta() error = %v", err)
+	}
+	if res.BundleURL == "" || !reflect.DeepEqual(res.Files, []string{"en.json", "locales/fr.json"}) {
+		t.Fatalf("result = %+v", res)
+	}
+	if len(*filters) != 1 || !reflect.DeepEqual((*filters)[0], res.ChangedKeys) {
+		t.Fatalf("export filter_keys = %v, want %v", *filters, res.ChangedKeys)
+	}
+
+	var en map[string]any
+	data, _ := os.ReadFile(filepath.Join(dest, "en.json"))
+	if err := json.Unmarshal(data, &en); err != nil {
+		t.Fatal(err)
+	}
+	wantEN := map[string]any{
+		"old":     "Keep",
+		"home":    map[string]any{"title": "New <b>title</b>", "subtitle": "Sub"},
+		"web.new": "Fresh",
+	}
+	if !reflect.DeepEqual(en, wantEN) {
+		t.Fatalf("merged en.json = %v, want %v", en, wantEN)
+	}
+	if !json.Valid(data) || !strings.Contains(string(data), "<b>") {
+		t.Fatalf("markup was escaped: %s", data)
+	}
+
+	fr, err := os.ReadFile(filepath.Join(dest, "locales", "fr.json"))
+	if err != nil || string(fr) != `{"home":{"title":"Nouveau"}}` {
+		t.Fatalf("new file = %q, %v", fr, err)
+	}
+}
+
+func TestDownloader_DownloadDelta_NothingChanged(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	filters := deltaAPI(t, map[string]string{})
+
+	res, err := newDeltaDownloader(t).DownloadDelta(context.Background(), t.TempDir(), download.DownloadParams{"format": "json"}, download.DeltaOptions{Since: time.Unix(500, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.ChangedKeys) != 0 || res.BundleURL != "" || len(*filters) != 0 {
+		t.Fatalf("result = %+v, exports = %d; want no export", res, len(*filters))
+	}
+}
+
+func TestDownloader_DownloadDelta_NarrowsFilterKeys(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	filters := deltaAPI(t, map[string]string{"en.json": `{}`})
+
+	params := download.DownloadParams{"format": "json", "filter_keys": []string{"home.title", "old"}}
+	res, err := newDeltaDownloader(t).DownloadDelta(context.Background(), t.TempDir(), params, download.DeltaOptions{Since: time.Unix(200, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.ChangedKeys, []string{"home.title"}) || !reflect.DeepEqual(*filters, [][]string{{"home.title"}}) {
+		t.Fatalf("changed = %v, filters = %v", res.ChangedKeys, *filters)
+	}
+	if got := params["filter_keys"].([]string); len(got) != 2 {
+		t.Fatalf("caller params mutated: %v", got)
+	}
+}
+
+func TestDownloader_DownloadDelta_RejectsUnmergeableFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := newDeltaDownloader(t).DownloadDelta(context.Background(), t.TempDir(), download.DownloadParams{"format": "xml"}, download.DeltaOptions{})
+	if !errors.Is(err, download.ErrDeltaFormat) {
+		t.Fatalf("error = %v, want ErrDeltaFormat", err)
+	}
+}
+
+func TestDownloader_DownloadDelta_CorruptExistingFile(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	deltaAPI(t, map[string]string{"en.json": `{"a":"b"}`})
+
+	dest := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dest, "en.json"), []byte(`[1,2]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := newDeltaDownloader(t).DownloadDelta(context.Background(), dest, download.DownloadParams{"format": "json"}, download.DeltaOptions{Since: time.Unix(0, 0)})
+	if err == nil || !strings.Contains(err.Error(), "en.json") {
+		t.Fatalf("error = %v, want merge error naming en.json", err)
+	}
+}
+
+func TestDownloader_DownloadDelta_RemovedKeysAndCustomMerger(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	deltaAPI(t, map[string]string{"en.json": `{"home":{"title":"New"}}`})
+
+	dest := t.TempDir()
+	files := map[string]string{
+		"en.json": `{"home":{"title":"Old"},"gone":"x"}`,
+		"fr.json": `{"gone":"y","stay":"z"}`,
+		"de.txt":  "gone",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dest, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var txtCalls int
+	res, err := newDeltaDownloader(t).DownloadDelta(context.Background(), dest, download.DownloadParams{"format": "json"}, download.DeltaOptions{
+		Since:      time.Unix(200, 0),
+		Removed:    []string{"gone"},
+		RemoveFrom: []string{"*.json", "*.txt"},
+		Mergers: map[string]download.Merger{".txt": download.MergerFunc(func(existing, _ []byte, _ []string) ([]byte, error) {
+			txtCalls++
+			return existing, nil // unchanged: not rewritten or reported
+		})},
+	})
+	if err != nil {
+		t.Fatalf("DownloadDelta() error = %v", err)
+	}
+	if !reflect.DeepEqual(res.Files, []string{"en.json", "fr.json"}) || txtCalls != 1 {
+		t.Fatalf("files = %v, txt merger calls = %d", res.Files, txtCalls)
+	}
+
+	for name, want := range map[string]string{
+		"en.json": `{"home":{"title":"New"}}`,
+		"fr.json": `{"stay":"z"}`,
+	} {
+		got, _ := os.ReadFile(filepath.Join(dest, name))
+		if string(got) != want {
+			t.Fatalf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestDownloader_DownloadDelta_RemovalsLimitedToSelectedFiles(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	deltaAPI(t, map[string]string{"en.json": `{"home":{"title":"New"}}`})
+
+	dest := t.TempDir()
+	files := map[string]string{
+		"en.json":       `{"home":{"title":"Old"},"gone":"x"}`,
+		"fr/app.json":   `{"gone":"y","stay":"z"}`,
+		"package.json":  `{"name":"app","gone":"1.0.0"}`,
+		"fr/data.json":  `[1,2]`,
+		"tsconfig.json": `{"gone":true}`,
+	}
+	for name, body := range files {
+		p := filepath.Join(dest, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := newDeltaDownloader(t).DownloadDelta(context.Background(), dest, download.DownloadParams{"format": "json"}, download.DeltaOptions{
+		Since:      time.Unix(200, 0),
+		Removed:    []string{"gone"},
+		RemoveFrom: []string{"*/app.json"},
+	})
+	if err != nil {
+		t.Fatalf("DownloadDelta() error = %v", err)
+	}
+	if !reflect.DeepEqual(res.Files, []string{"en.json", "fr/app.json"}) {
+		t.Fatalf("files = %v", res.Files)
+	}
+	for name, want := range map[string]string{
+		"en.json":       `{"home":{"title":"New"}}`,
+		"fr/app.json":   `{"stay":"z"}`,
+		"package.json":  files["package.json"],
+		"fr/data.json":  files["fr/data.json"],
+		"tsconfig.json": files["tsconfig.json"],
+	} {
+		got, _ := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+		if string(got) != want {
+			t.Fatalf("%s = %s, want %s", name, got, want)
+		}
+	}
+
+	_, err = newDeltaDownloader(t).DownloadDelta(context.Background(), dest, download.DownloadParams{"format": "json"}, download.DeltaOptions{
+		Removed: []string{"gone"}, RemoveFrom: []string{"["},
+	})
+	if err == nil || !strings.Contains(err.Error(), "RemoveFrom") {
+		t.Fatalf("error = %v, want bad pattern error", err)
+	}
+}
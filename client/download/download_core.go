@@ -29,6 +29,9 @@ import (
 // Construct with NewDownloader; the embedded client must be non-nil.
 type Downloader struct {
 	client *client.Client
+	quota  *ExportQuota // see WithExportQuota
+
+	lineEnding client.LineEnding // see WithLineEndings
 }
 
 // DownloadParams represents the JSON body for /files/download and /files/async-download.
@@ -43,7 +46,7 @@ var downloadAndUnzipFn = func(d *Downloader, ctx context.Context, bundleURL, des
 	return d.DownloadAndUnzip(ctx, bundleURL, destDir)
 }
 
-var encodeJSONBody = utils.EncodeJSONBody
+var encodeJSONBody = utils.EncodeJSONBodyWith
 
 const clientIsNilMsg = "download: downloader/client is nil"
 
@@ -64,11 +67,13 @@ func NewDownloader(c *client.Client) *Downloader {
 //  2. Receive bundle_url
 //  3. Download the zip (with retry/backoff), validate, unzip to unzipTo
 //
-// Returns the bundle_url on success.
-func (d *Downloader) Download(ctx context.Context, unzipTo string, params DownloadParams) (string, error) {
+// Returns the bundle_url on success. opts override client settings for this
+// call only (see client.WithRequestOptions).
+func (d *Downloader) Download(ctx context.Context, unzipTo string, params DownloadParams, opts ...client.RequestOption) (string, error) {
 	if d == nil || d.client == nil {
 		return "", errors.New(clientIsNilMsg)
 	}
+	d = d.WithRequestOptions(opts...)
 	return d.doDownload(ctx, unzipTo, params, d.FetchBundle)
 }
 
@@ -79,14 +84,26 @@ func (d *Downloader) Download(ctx context.Context, unzipTo string, params Downlo
 //  3. Receive download_url from the finished process
 //  4. Download the zip (with retry/backoff), validate, unzip to unzipTo
 //
-// Returns the final download_url on success.
-func (d *Downloader) DownloadAsync(ctx context.Context, unzipTo string, params DownloadParams) (string, error) {
+// Returns the final download_url on success. opts work as in Download.
+func (d *Downloader) DownloadAsync(ctx context.Context, unzipTo string, params DownloadParams, opts ...client.RequestOption) (string, error) {
 	if d == nil || d.client == nil {
 		return "", errors.New(clientIsNilMsg)
 	}
+	d = d.WithRequestOptions(opts...)
 	return d.doDownload(ctx, unzipTo, params, d.FetchBundleAsync)
 }
 
+// WithRequestOptions returns a copy of d whose calls all apply opts (see
+// client.WithRequestOptions), or d itself when opts is empty.
+func (d *Downloader) WithRequestOptions(opts ...client.RequestOption) *Downloader {
+	if d == nil || d.client == nil || len(opts) == 0 {
+		return d
+	}
+	cp := *d
+	cp.client = d.client.WithRequestOptions(opts...)
+	return &cp
+}
+
 // doDownload is the shared pipeline for both sync and async flows.
 // It builds the JSON body, calls fetch() to obtain the bundle URL, downloads
 // and validates the zip, and unzips into unzipTo. The returned string is the
@@ -114,12 +131,15 @@ func (d *Downloader) doDownload(
 		return "", fmt.Errorf("download: context: %w", err)
 	}
 
-	rdr, err := prepareBodyReader(params)
+	rdr, err := prepareBodyReader(d.client.Codec, params)
 	if err != nil {
 		return "", fmt.Errorf("download: %w", err)
 	}
 
 	bundleURL, err := fetch(ctx, rdr)
+	if errors.Is(err, client.ErrDryRun) {
+		return "", nil // the export request was recorded; see client.WithDryRun
+	}
 	if err != nil {
 		return "", err
 	}
@@ -131,7 +151,7 @@ func (d *Downloader) doDownload(
 	return bundleURL, nil
 }
 
-func prepareBodyReader(params DownloadParams) (*bytes.Reader, error) {
+func prepareBodyReader(codec utils.Codec, params DownloadParams) (*bytes.Reader, error) {
 	// copy to avoid mutating caller's map
 	var body map[string]any
 	if len(params) > 0 {
@@ -141,7 +161,7 @@ func prepareBodyReader(params DownloadParams) (*bytes.Reader, error) {
 		body = map[string]any{}
 	}
 
-	rdr, err := encodeJSONBody(body)
+	rdr, err := encodeJSONBody(codec, body)
 	if err != nil {
 		return nil, err
 	}
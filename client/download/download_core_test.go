@@ -16,7 +16,7 @@ import (
 
 	"github.com/bodrovis/lokex/v2/client"
 	"github.com/bodrovis/lokex/v2/client/download"
-	"github.com/bodrovis/lokex/v2/internal/testutils"
+	"github.com/bodrovis/lokex/v2/testutils"
 
 	"github.com/jarcoal/httpmock"
 )
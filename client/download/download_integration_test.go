@@ -10,17 +10,13 @@ import (
 
 	"github.com/bodrovis/lokex/v2/client"
 	"github.com/bodrovis/lokex/v2/client/download"
+	"github.com/bodrovis/lokex/v2/testutils"
 )
 
 func TestIntegration_Download(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in -short mode")
-	}
-	if token == "secret" || projectID == "123.abc" {
-		t.Skip("no real Lokalise credentials; skipping integration test")
-	}
+	creds := testutils.RequireCredentials(t)
 
-	cli, err := client.NewClient(token, projectID, nil)
+	cli, err := client.NewClient(creds.Token, creds.ProjectID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -70,14 +66,9 @@ func TestIntegration_Download(t *testing.T) {
 }
 
 func TestIntegration_DownloadAsync(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in -short mode")
-	}
-	if token == "secret" || projectID == "123.abc" {
-		t.Skip("no real Lokalise credentials; skipping integration test")
-	}
+	creds := testutils.RequireCredentials(t)
 
-	cli, err := client.NewClient(token, projectID, nil)
+	cli, err := client.NewClient(creds.Token, creds.ProjectID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2,10 +2,14 @@ package download
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/bodrovis/lokex/v2/internal/apierr"
 )
@@ -19,35 +23,114 @@ var doDownloadRequestFn = func(
 	return d.doDownloadRequest(ctx, httpc, urlStr, ua)
 }
 
+// bundleDigest is the size and SHA-256 of a downloaded bundle, computed
+// while it is written so nothing has to read the file again.
+type bundleDigest struct {
+	size   int64
+	sha256 string
+}
+
 // downloadOnce performs a single GET of the bundle and writes it to destPath.
 // It writes into a temp file first and renames it on success, so partial downloads
 // never leave broken zips at destPath.
-func (d *Downloader) downloadOnce(ctx context.Context, urlStr, destPath, ua string) error {
+func (d *Downloader) downloadOnce(ctx context.Context, urlStr, destPath, ua string) (bundleDigest, error) {
 	httpc, urlStr, destPath, err := d.downloadOncePrecheck(ctx, urlStr, destPath)
 	if err != nil {
-		return err
+		return bundleDigest{}, err
+	}
+
+	release, err := d.client.AcquireSlot(ctx)
+	if err != nil {
+		return bundleDigest{}, err
+	}
+	defer release()
+
+	if d.client.BundleStreaming || d.client.BundleStallTimeout > 0 {
+		return d.downloadOnceWatched(ctx, httpc, urlStr, destPath, ua)
 	}
 
 	resp, err := doDownloadRequestFn(d, ctx, httpc, urlStr, ua)
 	if err != nil {
-		return err
+		return bundleDigest{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return writeBundleResponse(resp, resp.Body, destPath)
+}
+
+// downloadOnceWatched is downloadOnce guarded by a watchdog that aborts the
+// transfer when it stalls. In streaming mode the http.Client Timeout is
+// dropped and the watchdog also bounds the wait for headers, so a large
+// bundle can take as long as ctx allows while it keeps flowing.
+func (d *Downloader) downloadOnceWatched(ctx context.Context, httpc *http.Client, urlStr, destPath, ua string) (bundleDigest, error) {
+	var header time.Duration
+	stall := d.client.BundleStallTimeout
+	if d.client.BundleStreaming {
+		httpc = streamingClient(httpc)
+		header = d.client.BundleHeaderTimeout
+		if stall <= 0 {
+			stall = defaultStreamStallTimeout
+		}
+	}
+
+	wctx, wd := startWatchdog(ctx, header, stall)
+	defer wd.stop()
+
+	resp, err := doDownloadRequestFn(d, wctx, httpc, urlStr, ua)
+	if err != nil {
+		return bundleDigest{}, watchdogErr(wctx, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	digest, err := writeBundleResponse(resp, wd.body(resp.Body), destPath)
+	return digest, watchdogErr(wctx, err)
+}
+
+// writeBundleResponse checks resp and writes body (resp.Body, possibly
+// wrapped) to destPath, hashing it on the way.
+func writeBundleResponse(resp *http.Response, body io.Reader, destPath string) (bundleDigest, error) {
 	// Non-2xx: read a capped snippet for an APIError and bail.
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		slurp, _ := io.ReadAll(io.LimitReader(resp.Body, apierr.DefaultErrCap))
-		_, _ = io.Copy(io.Discard, resp.Body)
-		return apierr.Parse(slurp, resp.StatusCode)
+		slurp, truncated := apierr.ReadBody(body, apierr.DefaultErrCap)
+		_, _ = io.Copy(io.Discard, body)
+		ae := apierr.Parse(slurp, resp.StatusCode)
+		ae.Truncated = truncated
+		return bundleDigest{}, ae
 	}
 
-	return writeHTTPBodyAtomically(destPath, resp.Body, resp.ContentLength)
+	sniffed, err := sniffBundle(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		// Drain the reader that was read from, so a wrapper (e.g. the
+		// stall watchdog) sees the rest of the body go through it.
+		_, _ = io.Copy(io.Discard, io.LimitReader(body, apierr.DefaultErrCap))
+		return bundleDigest{}, err
+	}
+
+	hr := &hashingReader{r: sniffed, h: sha256.New()}
+	if err := writeHTTPBodyAtomically(destPath, hr, resp.ContentLength); err != nil {
+		return bundleDigest{}, err
+	}
+	return bundleDigest{size: hr.n, sha256: hex.EncodeToString(hr.h.Sum(nil))}, nil
+}
+
+// hashingReader hashes and counts what is read through it.
+type hashingReader struct {
+	r io.Reader
+	h hash.Hash
+	n int64
+}
+
+func (r *hashingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.h.Write(p[:n])
+	r.n += int64(n)
+	return n, err
 }
 
 // downloadOncePrecheck validates inputs and extracts the http.Client.
 // Keeping this separate makes downloadOnce small and avoids nil-panics.
 func (d *Downloader) downloadOncePrecheck(ctx context.Context, urlStr, destPath string) (*http.Client, string, string, error) {
-	if d == nil || d.client == nil || d.client.HTTPClient == nil {
+	if d == nil || d.client == nil || d.client.BundleHTTPClient() == nil {
 		return nil, "", "", fmt.Errorf("download: downloader/client/http client is nil")
 	}
 	if ctx == nil {
@@ -66,5 +149,5 @@ func (d *Downloader) downloadOncePrecheck(ctx context.Context, urlStr, destPath
 		return nil, "", "", fmt.Errorf("download: empty dest path")
 	}
 
-	return d.client.HTTPClient, urlStr, destPath, nil
+	return d.client.BundleHTTPClient(), urlStr, destPath, nil
 }
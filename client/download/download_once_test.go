@@ -1,14 +1,21 @@
 package download_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"io"
 	"net/http"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/bodrovis/lokex/v2/client"
 	"github.com/bodrovis/lokex/v2/client/download"
+	"github.com/bodrovis/lokex/v2/testutils"
 )
 
 func TestDownloadOncePrecheck(t *testing.T) {
@@ -199,3 +206,99 @@ func TestDownloadOnce(t *testing.T) {
 		}
 	})
 }
+
+func TestDownloadOnce_Digest(t *testing.T) {
+	zip := testutils.BuildZip(t, testutils.ZipFile("en.json", `{"a":"b"}`))
+	sum := sha256.Sum256(zip)
+	want := hex.EncodeToString(sum[:])
+
+	restore := download.ExportSetDoDownloadRequestForTest(
+		func(*download.Downloader, context.Context, *http.Client, string, string) (*http.Response, error) {
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        http.Header{"Content-Type": []string{"application/zip"}},
+				Body:          io.NopCloser(bytes.NewReader(zip)),
+				ContentLength: int64(len(zip)),
+			}, nil
+		},
+	)
+	defer restore()
+
+	for name, c := range map[string]*client.Client{
+		"plain":   {HTTPClient: &http.Client{}},
+		"watched": {HTTPClient: &http.Client{}, BundleStallTimeout: time.Minute},
+	} {
+		t.Run(name, func(t *testing.T) {
+			destPath := filepath.Join(t.TempDir(), "bundle.zip")
+			size, got, err := download.ExportDownloadOnceDigest(download.NewDownloader(c), context.Background(),
+				"https://example.com/file.zip", destPath, "test-ua")
+			if err != nil {
+				t.Fatalf("DownloadOnce() error = %v", err)
+			}
+			if size != int64(len(zip)) || got != want {
+				t.Fatalf("digest = %d %s, want %d %s", size, got, len(zip), want)
+			}
+			if data, _ := os.ReadFile(destPath); !bytes.Equal(data, zip) {
+				t.Fatal("written bundle differs from the response body")
+			}
+		})
+	}
+}
+
+func TestDownloadOncePrecheck_PrefersBundleClient(t *testing.T) {
+	t.Parallel()
+
+	api := &http.Client{}
+	bundle := &http.Client{}
+
+	tests := []struct {
+		name string
+		c    *client.Client
+		want *http.Client
+	}{
+		{name: "api client fallback", c: &client.Client{HTTPClient: api}, want: api},
+		{name: "bundle client", c: &client.Client{HTTPClient: api, BundleClient: bundle}, want: bundle},
+		{name: "bundle client only", c: &client.Client{BundleClient: bundle}, want: bundle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			httpc, _, _, err := download.ExportDownloadOncePrecheck(
+				download.NewDownloader(tt.c),
+				context.Background(),
+				"https://example.com/file.zip",
+				"/tmp/file.zip",
+			)
+			if err != nil {
+				t.Fatalf("DownloadOncePrecheck() unexpected error = %v", err)
+			}
+			if httpc != tt.want {
+				t.Fatal("DownloadOncePrecheck() returned the wrong http client")
+			}
+		})
+	}
+}
+
+func TestDownloadOnce_WaitsForConcurrencySlot(t *testing.T) {
+	t.Parallel()
+
+	cli, err := client.NewClient("tok", "p", client.WithMaxConcurrency(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := cli.AcquireSlot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	d := download.NewDownloader(cli)
+	err = download.ExportDownloadOnce(d, ctx, "https://cdn.example.com/bundle.zip", filepath.Join(t.TempDir(), "b.zip"), "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded while another download holds the only slot", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/internal/telemetry"
 	"github.com/bodrovis/lokex/v2/internal/zipx"
 )
 
@@ -20,10 +22,22 @@ var (
 // validates that it's a well-formed zip, and unzips it into destDir with a
 // series of safety checks (zip-slip, entry count, size caps, no symlinks/devs).
 func (d *Downloader) DownloadAndUnzip(ctx context.Context, bundleURL, destDir string) error {
-	ctx, bundleURL, destDir, err := d.downloadAndUnzipPrecheck(ctx, bundleURL, destDir)
+	if err := d.downloadAndUnzip(ctx, bundleURL, destDir); err != nil {
+		return err
+	}
+	d.client.RecordPull()
+	return nil
+}
+
+// downloadAndUnzip is DownloadAndUnzip without recording the pull, for
+// callers that don't update the destination (see Verify).
+func (d *Downloader) downloadAndUnzip(ctx context.Context, bundleURL, destDir string) (err error) {
+	ctx, bundleURL, destDir, err = d.downloadAndUnzipPrecheck(ctx, bundleURL, destDir)
 	if err != nil {
 		return err
 	}
+	ctx, span := telemetry.Start(ctx, d.client.Tracer(), telemetry.SpanDownload)
+	defer func() { telemetry.End(span, err) }()
 
 	if err := ensureDestDir(destDir); err != nil {
 		return err
@@ -37,18 +51,20 @@ func (d *Downloader) DownloadAndUnzip(ctx context.Context, bundleURL, destDir st
 
 	tmpPath := filepath.Join(tmpDir, "bundle.zip")
 
-	if err := d.downloadAndValidateZip(ctx, bundleURL, tmpPath); err != nil {
+	digest, err := d.downloadAndValidateZip(ctx, bundleURL, tmpPath)
+	if err != nil {
 		return err
 	}
+	span.SetAttributes(telemetry.AttrBundleSize.Int64(digest.size))
 
-	return unzipDownloadedBundle(tmpPath, destDir)
+	return unzipDownloadedBundle(tmpPath, destDir, d.lineEnding)
 }
 
 func (d *Downloader) downloadAndUnzipPrecheck(
 	ctx context.Context,
 	bundleURL, destDir string,
 ) (context.Context, string, string, error) {
-	if d == nil || d.client == nil || d.client.HTTPClient == nil {
+	if d == nil || d.client == nil || d.client.BundleHTTPClient() == nil {
 		return nil, "", "", fmt.Errorf("download: downloader/client/http client is nil")
 	}
 	if ctx == nil {
@@ -99,22 +115,29 @@ func createDownloadTempDir() (string, func(), error) {
 func (d *Downloader) downloadAndValidateZip(
 	ctx context.Context,
 	bundleURL, tmpPath string,
-) error {
+) (bundleDigest, error) {
 	ua := d.client.UserAgent
 
-	return d.client.WithExpBackoff(ctx, "download", func(_ int) error {
-		if err := d.downloadOnce(ctx, bundleURL, tmpPath, ua); err != nil {
+	var digest bundleDigest
+	err := d.client.WithExpBackoffContext(ctx, "download", func(actx context.Context, _ int) error {
+		dg, err := d.downloadOnce(actx, bundleURL, tmpPath, ua)
+		if err != nil {
 			return err
 		}
+		d.client.Metrics().DownloadedBytes(dg.size)
 		if err := zipx.Validate(tmpPath); err != nil {
 			return fmt.Errorf("validate zip: %w", err)
 		}
+		digest = dg
 		return nil
-	}, nil)
+	}, retryableBundleErr)
+	return digest, err
 }
 
-func unzipDownloadedBundle(tmpPath, destDir string) error {
-	if err := zipx.Unzip(tmpPath, destDir, zipx.DefaultPolicy()); err != nil {
+func unzipDownloadedBundle(tmpPath, destDir string, le client.LineEnding) error {
+	p := zipx.DefaultPolicy()
+	p.LineEnding = le
+	if err := zipx.Unzip(tmpPath, destDir, p); err != nil {
 		return fmt.Errorf("unzip: %w", err)
 	}
 	return nil
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,6 +15,8 @@ import (
 	"github.com/bodrovis/lokex/v2/client/download"
 	"github.com/bodrovis/lokex/v2/internal/apierr"
 	"github.com/jarcoal/httpmock"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestDownloadAndUnzip_Happy(t *testing.T) {
@@ -45,6 +48,9 @@ func TestDownloadAndUnzip_Happy(t *testing.T) {
 	if err := dl.DownloadAndUnzip(ctx, bundleURL, dest); err != nil {
 		t.Fatalf("DownloadAndUnzip: %v", err)
 	}
+	if cli.Health().LastPull.IsZero() {
+		t.Fatal("download not recorded as a pull in Health")
+	}
 
 	// assert files exist with content and structure preserved
 	checkFile := func(rel, want string) {
@@ -145,6 +151,39 @@ func TestDownloadAndUnzip_HTTPNon2xx(t *testing.T) {
 	}
 }
 
+func TestDownloadAndUnzip_RetryPolicy(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := "https://cdn.example.com/late.zip"
+	httpmock.RegisterResponder("GET", url, httpmock.NewStringResponder(404, "not yet"))
+
+	// The bundle may 404 for a moment after the export; retry that twice.
+	var labels []string
+	cli, err := client.NewClient(token, projectID,
+		client.WithMaxRetries(5),
+		client.WithRetryPolicy(client.RetryPolicyFunc(func(a client.RetryAttempt) (time.Duration, bool) {
+			labels = append(labels, a.Label)
+			var ae *apierr.APIError
+			return 0, errors.As(a.Err, &ae) && ae.Status == http.StatusNotFound && a.Retry < 2
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = download.NewDownloader(cli).DownloadAndUnzip(context.Background(), url, t.TempDir())
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+	if got := httpmock.GetCallCountInfo()["GET "+url]; got != 3 {
+		t.Fatalf("attempts = %d, want 3 (the policy retries a 404 twice)", got)
+	}
+	if len(labels) != 3 || labels[0] != "download" {
+		t.Fatalf("policy asked with labels %q, want download x3", labels)
+	}
+}
+
 func TestDownloadAndUnzip_RetryOn5xxThenSuccess(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
@@ -321,6 +360,9 @@ func TestDownloadAndUnzip_ZipSlipBlocked(t *testing.T) {
 	if err == nil || !strings.Contains(err.Error(), "unsafe path") {
 		t.Fatalf("want unsafe path error, got %v", err)
 	}
+	if !cli.Health().LastPull.IsZero() {
+		t.Fatal("failed unzip recorded as a pull in Health")
+	}
 	// ensure it didn't create evil.txt outside; we can't easily check outside,
 	// but we can ensure it didn't place anything inside dest either.
 	entries, _ := os.ReadDir(dest)
@@ -679,3 +721,70 @@ func TestDownloadAndUnzip(t *testing.T) {
 		}
 	})
 }
+
+func TestDownloadAndUnzip_TracingSpan(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	zb := buildZip(t, map[string]string{"en.json": `{"a":"b"}`}, nil)
+	bundleURL := "https://cdn.example.com/traced.zip"
+	registerZipResponder(t, bundleURL, zb)
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	cli, err := client.NewClient(token, projectID, client.WithTracerProvider(tp))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := download.NewDownloader(cli).DownloadAndUnzip(context.Background(), bundleURL, t.TempDir()); err != nil {
+		t.Fatalf("DownloadAndUnzip: %v", err)
+	}
+
+	var span sdktrace.ReadOnlySpan
+	for _, s := range rec.Ended() {
+		if s.Name() == "lokex.download" {
+			span = s
+		}
+	}
+	if span == nil {
+		t.Fatal("no lokex.download span")
+	}
+	for _, kv := range span.Attributes() {
+		if kv.Key == "lokex.bundle.size" {
+			if kv.Value.AsInt64() != int64(len(zb)) {
+				t.Fatalf("lokex.bundle.size = %d, want %d", kv.Value.AsInt64(), len(zb))
+			}
+			return
+		}
+	}
+	t.Fatal("lokex.download span lacks lokex.bundle.size")
+}
+
+type byteCounter struct {
+	client.MetricsRecorder
+	n atomic.Int64
+}
+
+func (b *byteCounter) DownloadedBytes(n int64) { b.n.Add(n) }
+
+func TestDownloadAndUnzip_ReportsDownloadedBytes(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	zb := buildZip(t, map[string]string{"en.json": `{"a":"b"}`}, nil)
+	bundleURL := "https://cdn.example.com/metered.zip"
+	registerZipResponder(t, bundleURL, zb)
+
+	m := &byteCounter{}
+	cli, err := client.NewClient(token, projectID, client.WithMetrics(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := download.NewDownloader(cli).DownloadAndUnzip(context.Background(), bundleURL, t.TempDir()); err != nil {
+		t.Fatalf("DownloadAndUnzip: %v", err)
+	}
+	if got := m.n.Load(); got != int64(len(zb)) {
+		t.Fatalf("downloaded bytes = %d, want %d", got, len(zb))
+	}
+}
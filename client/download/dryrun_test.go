@@ -0,0 +1,54 @@
+package download_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+)
+
+func TestDownloader_DryRun(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.Error(w, "no", http.StatusTeapot)
+	}))
+	t.Cleanup(srv.Close)
+
+	var log client.DryRunLog
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithDryRun(&log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := download.NewDownloader(cli)
+	dest := filepath.Join(t.TempDir(), "locales")
+	params := download.DownloadParams{"format": "json", "original_filenames": true}
+
+	for _, run := range []func(context.Context, string, download.DownloadParams, ...client.RequestOption) (string, error){dl.Download, dl.DownloadAsync} {
+		url, err := run(context.Background(), dest, params)
+		if err != nil || url != "" {
+			t.Fatalf("dry-run download = %q, %v; want \"\", nil", url, err)
+		}
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Fatalf("destination created in dry-run mode (err = %v)", err)
+	}
+
+	reqs := log.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("recorded = %d, want 2", len(reqs))
+	}
+	if !strings.HasSuffix(reqs[0].Path, "/files/download") || !strings.HasSuffix(reqs[1].Path, "/files/async-download") {
+		t.Fatalf("paths = %q, %q", reqs[0].Path, reqs[1].Path)
+	}
+	if !strings.Contains(string(reqs[0].Body), `"format":"json"`) {
+		t.Fatalf("body = %s", reqs[0].Body)
+	}
+}
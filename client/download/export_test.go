@@ -7,9 +7,11 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/bodrovis/lokex/v2/client"
 	"github.com/bodrovis/lokex/v2/client/internal/background"
+	"github.com/bodrovis/lokex/v2/internal/utils"
 )
 
 type ExportSyncCloseFile interface {
@@ -60,7 +62,19 @@ func ExportDownloadOnce(
 	ctx context.Context,
 	urlStr, destPath, ua string,
 ) error {
-	return d.downloadOnce(ctx, urlStr, destPath, ua)
+	_, err := d.downloadOnce(ctx, urlStr, destPath, ua)
+	return err
+}
+
+// ExportDownloadOnceDigest is ExportDownloadOnce returning the size and
+// SHA-256 computed while downloading.
+func ExportDownloadOnceDigest(
+	d *Downloader,
+	ctx context.Context,
+	urlStr, destPath, ua string,
+) (int64, string, error) {
+	dg, err := d.downloadOnce(ctx, urlStr, destPath, ua)
+	return dg.size, dg.sha256, err
 }
 
 func ExportDownloadOncePrecheck(
@@ -205,8 +219,24 @@ func ExportSetEncodeJSONBodyForTest(
 	fn func(body any) (*bytes.Reader, error),
 ) func() {
 	prev := encodeJSONBody
-	encodeJSONBody = fn
+	encodeJSONBody = func(_ utils.Codec, body any) (*bytes.Reader, error) {
+		return fn(body)
+	}
 	return func() {
 		encodeJSONBody = prev
 	}
 }
+
+func ExportSetProbePageLimitForTest(n int) func() {
+	prev := probePageLimit
+	probePageLimit = n
+	return func() { probePageLimit = prev }
+}
+
+func ExportJitterInterval(interval time.Duration, jitter float64) time.Duration {
+	return jitterInterval(interval, jitter)
+}
+
+func ExportWithRedirectPolicy(hc *http.Client, maxHops int) *http.Client {
+	return withRedirectPolicy(hc, maxHops)
+}
@@ -15,7 +15,7 @@ import (
 // AsyncDownloadResponse is the minimal response payload returned by
 // POST /files/async-download.
 type AsyncDownloadResponse struct {
-	ProcessID string `json:"process_id"`
+	ProcessID string `json:"process_id" lokex:"required"`
 }
 
 var pollProcessesFn = func(
@@ -57,6 +57,32 @@ func (d *Downloader) FetchBundleAsync(ctx context.Context, body io.Reader) (stri
 	return interpretAsyncDownloadProcess(p)
 }
 
+// StartAsync kicks off an async export (POST /files/async-download) and
+// returns a handle to its process without waiting. Once Wait on the handle
+// succeeds, the bundle URL is in QueuedProcess.DownloadURL and can be passed
+// to DownloadAndUnzip.
+func (d *Downloader) StartAsync(ctx context.Context, params DownloadParams) (*client.Process, error) {
+	if d == nil || d.client == nil {
+		return nil, fmt.Errorf("fetch bundle async: nil downloader/client")
+	}
+
+	rdr, err := prepareBodyReader(d.client.Codec, params)
+	if err != nil {
+		return nil, fmt.Errorf("fetch bundle async: %w", err)
+	}
+
+	ctx, err = d.fetchBundleAsyncPrecheck(ctx, rdr)
+	if err != nil {
+		return nil, err
+	}
+
+	pid, err := d.startAsyncDownload(ctx, rdr)
+	if err != nil {
+		return nil, err
+	}
+	return d.client.Process(pid), nil
+}
+
 func (d *Downloader) fetchBundleAsyncPrecheck(ctx context.Context, body io.Reader) (context.Context, error) {
 	if d == nil || d.client == nil {
 		return nil, fmt.Errorf("fetch bundle async: nil downloader/client")
@@ -74,11 +100,15 @@ func (d *Downloader) fetchBundleAsyncPrecheck(ctx context.Context, body io.Reade
 }
 
 func (d *Downloader) startAsyncDownload(ctx context.Context, body io.Reader) (string, error) {
+	if err := d.quota.reserve(ctx); err != nil {
+		return "", fmt.Errorf("fetch bundle async: %w", err)
+	}
+
 	var kickoff AsyncDownloadResponse
 	path := utils.ProjectPath(d.client.ProjectID, "files/async-download")
 
 	if err := d.client.DoJSONWithRetry(ctx, http.MethodPost, path, body, &kickoff); err != nil {
-		return "", fmt.Errorf("fetch bundle async: %w", err)
+		return "", fmt.Errorf("fetch bundle async: %w", d.quotaErr(err))
 	}
 
 	pid := strings.TrimSpace(kickoff.ProcessID)
@@ -117,6 +147,14 @@ func interpretAsyncDownloadProcess(p background.QueuedProcess) (string, error) {
 	default:
 		// Usually means we ran out of polling budget (PollMaxWait) but ctx might still be alive,
 		// or Lokalise is slow and never reached terminal before our poll deadline.
+		if p.Err != nil {
+			return "", fmt.Errorf(
+				"fetch bundle async: process %s did not finish (status=%q): %w",
+				p.ProcessID,
+				st,
+				p.Err,
+			)
+		}
 		return "", fmt.Errorf(
 			"fetch bundle async: process %s did not finish (status=%q)",
 			p.ProcessID,
@@ -147,6 +185,10 @@ func finishedAsyncDownloadURL(p background.QueuedProcess) (string, error) {
 }
 
 func failedAsyncDownloadErr(p background.QueuedProcess) error {
+	if p.Err != nil {
+		return fmt.Errorf("fetch bundle async: process %s failed: %w", p.ProcessID, p.Err)
+	}
+
 	msg := strings.TrimSpace(p.Message)
 	if msg != "" {
 		return fmt.Errorf("fetch bundle async: process %s failed: %s", p.ProcessID, msg)
@@ -13,7 +13,7 @@ import (
 // DownloadBundle is the minimal response payload returned by
 // POST /files/download.
 type DownloadBundle struct {
-	BundleURL string `json:"bundle_url"`
+	BundleURL string `json:"bundle_url" lokex:"required"`
 }
 
 // FetchBundle performs a synchronous export (POST /files/download) and returns the bundle URL.
@@ -31,11 +31,15 @@ func (d *Downloader) FetchBundle(ctx context.Context, body io.Reader) (string, e
 		return "", fmt.Errorf("fetch bundle: nil request body")
 	}
 
+	if err := d.quota.reserve(ctx); err != nil {
+		return "", fmt.Errorf("fetch bundle: %w", err)
+	}
+
 	var bundle DownloadBundle
 	path := utils.ProjectPath(d.client.ProjectID, "files/download")
 
 	if err := d.client.DoJSONWithRetry(ctx, http.MethodPost, path, body, &bundle); err != nil {
-		return "", fmt.Errorf("fetch bundle: %w", err)
+		return "", fmt.Errorf("fetch bundle: %w", d.quotaErr(err))
 	}
 
 	bundleURL := strings.TrimSpace(bundle.BundleURL)
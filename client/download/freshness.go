@@ -0,0 +1,277 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/keys"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// DefaultManifestName is the manifest file DownloadIfChanged keeps in the
+// destination directory when FreshnessOptions.ManifestPath is empty. It has
+// no .json extension so catalog loaders skip it.
+const DefaultManifestName = ".lokex-manifest"
+
+// ManifestNamespace is the client.StateStore namespace DownloadIfChanged
+// keeps manifests in when FreshnessOptions.State is set.
+const ManifestNamespace = "manifest"
+
+// probePageLimit is the GET /keys page size used to probe for changes.
+var probePageLimit = 5000
+
+// Manifest records what the last pull of a destination was based on.
+type Manifest struct {
+	ProjectID  string    `json:"project_id"`
+	Version    string    `json:"version"`     // FreshnessProbe result at pull time
+	ParamsHash string    `json:"params_hash"` // hash of the export params
+	PulledAt   time.Time `json:"pulled_at"`
+}
+
+// ReadManifest reads the manifest at path. A missing file is not an error;
+// ok reports whether one was found.
+func ReadManifest(path string) (m Manifest, ok bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Manifest{}, false, nil
+	}
+	if err != nil {
+		return Manifest{}, false, fmt.Errorf("download: read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, false, fmt.Errorf("download: read manifest %s: %w", path, err)
+	}
+	return m, true, nil
+}
+
+// WriteManifest writes m to path atomically.
+func WriteManifest(path string, m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("download: write manifest: %w", err)
+	}
+	if err := writeHTTPBodyAtomically(path, bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("download: write manifest: %w", err)
+	}
+	return nil
+}
+
+// FreshnessProbe returns an opaque version of the project's content; two
+// equal versions mean nothing relevant to an export changed in between.
+type FreshnessProbe func(ctx context.Context, d *Downloader) (string, error)
+
+// ProbeKeysModified versions the project by its key count and the latest
+// key or translation modification time. It lists keys without translations
+// (one request per 5000 keys), so it is much cheaper than an export and
+// catches every edit, addition and deletion.
+func ProbeKeysModified(ctx context.Context, d *Downloader) (string, error) {
+	if d == nil || d.client == nil {
+		return "", errors.New(clientIsNilMsg)
+	}
+
+	var count int
+	var latest int64
+	err := d.walkKeyStamps(ctx, func(k keyStamp) {
+		count++
+		latest = max(latest, k.modified())
+	})
+	if err != nil {
+		return "", fmt.Errorf("download: probe %w", err)
+	}
+	return fmt.Sprintf("keys:%d:%d", count, latest), nil
+}
+
+// keyStamp is the part of a GET /keys entry needed to tell what changed.
+type keyStamp struct {
+	KeyName                keys.PlatformStrings `json:"key_name"`
+	ModifiedAt             int64                `json:"modified_at_timestamp"`
+	TranslationsModifiedAt int64                `json:"translations_modified_at_timestamp"`
+}
+
+// modified returns the latest change to the key or any of its translations.
+func (k keyStamp) modified() int64 {
+	return max(k.ModifiedAt, k.TranslationsModifiedAt)
+}
+
+// walkKeyStamps calls fn for every key of the project, without translations.
+func (d *Downloader) walkKeyStamps(ctx context.Context, fn func(keyStamp)) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	path := utils.ProjectPath(d.client.ProjectID, "keys")
+	pages := client.NewPaginator[keyStamp](d.client, path, "keys", client.PaginatorOptions{Limit: max(probePageLimit, 1)})
+
+	for page := 1; ; page++ {
+		items, err := pages.Next(ctx)
+		if errors.Is(err, client.ErrNoMorePages) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("keys (page %d): %w", page, err)
+		}
+		for _, k := range items {
+			fn(k)
+		}
+	}
+}
+
+// ProbeStatistics versions the project by its statistics (key and word
+// counts, per-language progress, QA issues) in a single request. It misses
+// edits that don't change any counter, such as rewording a translated
+// string; use ProbeKeysModified when that matters.
+func ProbeStatistics(ctx context.Context, d *Downloader) (string, error) {
+	if d == nil || d.client == nil {
+		return "", errors.New(clientIsNilMsg)
+	}
+
+	var resp struct {
+		Statistics json.RawMessage `json:"statistics"`
+	}
+	if err := d.client.DoJSONWithRetry(ctx, http.MethodGet, "projects/"+url.PathEscape(d.client.ProjectID), nil, &resp); err != nil {
+		return "", fmt.Errorf("download: probe statistics: %w", err)
+	}
+	if len(resp.Statistics) == 0 {
+		return "", errors.New("download: probe statistics: response has no statistics")
+	}
+	sum := sha256.Sum256(resp.Statistics)
+	return "stats:" + hex.EncodeToString(sum[:16]), nil
+}
+
+// FreshnessOptions configures DownloadIfChanged.
+type FreshnessOptions struct {
+	ManifestPath string         // default: DefaultManifestName in the destination
+	Probe        FreshnessProbe // default: ProbeKeysModified
+	Async        bool           // export with DownloadAsync instead of Download
+
+	// State, if set, keeps the manifest in a client.StateStore (under
+	// ManifestNamespace, keyed by project and destination) instead of a file,
+	// so runners sharing a Redis- or DB-backed store share freshness.
+	// ManifestPath is then ignored.
+	State client.StateStore
+}
+
+// DownloadIfChanged pulls into unzipTo only if the project changed since the
+// pull recorded in the manifest, or if params or the project differ from
+// that pull. It reports whether a download happened; after one, the
+// manifest is updated. The version is probed before exporting, so changes
+// made during the export are picked up by the next call.
+func (d *Downloader) DownloadIfChanged(ctx context.Context, unzipTo string, params DownloadParams, opts FreshnessOptions) (bool, error) {
+	if d == nil || d.client == nil {
+		return false, errors.New(clientIsNilMsg)
+	}
+	if strings.TrimSpace(unzipTo) == "" {
+		return false, errors.New("download: empty unzip destination")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	store := manifestStore{state: opts.State, path: opts.ManifestPath}
+	if store.path == "" {
+		store.path = filepath.Join(unzipTo, DefaultManifestName)
+	}
+	store.key = d.client.ProjectID + ":" + filepath.ToSlash(filepath.Clean(unzipTo))
+	probe := opts.Probe
+	if probe == nil {
+		probe = ProbeKeysModified
+	}
+
+	paramsHash, err := hashParams(params)
+	if err != nil {
+		return false, err
+	}
+	version, err := probe(ctx, d)
+	if err != nil {
+		return false, err
+	}
+
+	prev, ok, err := store.read(ctx)
+	if err != nil {
+		return false, err
+	}
+	if ok && prev.ProjectID == d.client.ProjectID && prev.Version == version && prev.ParamsHash == paramsHash {
+		return false, nil
+	}
+
+	pull := d.Download
+	if opts.Async {
+		pull = d.DownloadAsync
+	}
+	if _, err := pull(ctx, unzipTo, params); err != nil {
+		return false, err
+	}
+
+	m := Manifest{
+		ProjectID:  d.client.ProjectID,
+		Version:    version,
+		ParamsHash: paramsHash,
+		PulledAt:   time.Now().UTC(),
+	}
+	if err := store.write(ctx, m); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// manifestStore reads and writes the manifest of one destination, either
+// at path or, when state is set, under key in the state store.
+type manifestStore struct {
+	state client.StateStore
+	path  string
+	key   string
+}
+
+func (s manifestStore) read(ctx context.Context) (Manifest, bool, error) {
+	if s.state == nil {
+		return ReadManifest(s.path)
+	}
+	data, ok, err := s.state.Get(ctx, ManifestNamespace, s.key)
+	if err != nil {
+		return Manifest{}, false, fmt.Errorf("download: read manifest: %w", err)
+	}
+	if !ok {
+		return Manifest{}, false, nil
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, false, fmt.Errorf("download: read manifest %s: %w", s.key, err)
+	}
+	return m, true, nil
+}
+
+func (s manifestStore) write(ctx context.Context, m Manifest) error {
+	if s.state == nil {
+		return WriteManifest(s.path, m)
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("download: write manifest: %w", err)
+	}
+	if err := s.state.Set(ctx, ManifestNamespace, s.key, data); err != nil {
+		return fmt.Errorf("download: write manifest: %w", err)
+	}
+	return nil
+}
+
+// hashParams fingerprints export params; encoding/json sorts map keys, so
+// equal params hash equally.
+func hashParams(params DownloadParams) (string, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("download: hash params: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
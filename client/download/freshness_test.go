@@ -0,0 +1,258 @@
+package download_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestManifest_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "manifest")
+	if _, ok, err := download.ReadManifest(path); err != nil || ok {
+		t.Fatalf("ReadManifest(missing) = ok %v, err %v; want false, nil", ok, err)
+	}
+
+	want := download.Manifest{ProjectID: "p", Version: "keys:1:2", ParamsHash: "h"}
+	if err := download.WriteManifest(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := download.ReadManifest(path)
+	if err != nil || !ok || got != want {
+		t.Fatalf("ReadManifest() = %+v, %v, %v; want %+v", got, ok, err, want)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := download.ReadManifest(path); err == nil {
+		t.Fatal("ReadManifest(corrupt) error = nil")
+	}
+}
+
+// freshnessAPI mocks the keys probe, the sync export and the CDN bundle.
+// keysPage returns the JSON body of the single keys page.
+func freshnessAPI(t *testing.T, keysPage func() string) (exports *atomic.Int32) {
+	t.Helper()
+
+	exports = &atomic.Int32{}
+	keysURL := fmt.Sprintf(`=~^https://api\.lokalise\.com/api2/projects/%s/keys\?limit=5000&page=1$`, projectID)
+	httpmock.RegisterResponder("GET", keysURL, func(*http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(200, keysPage()), nil
+	})
+
+	cdnURL := "https://cdn.example.com/fresh.zip"
+	postURL := fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/files/download", projectID)
+	httpmock.RegisterResponder("POST", postURL, func(*http.Request) (*http.Response, error) {
+		exports.Add(1)
+		return httpmock.NewStringResponse(200, `{"bundle_url":"`+cdnURL+`"}`), nil
+	})
+	registerZipResponder(t, cdnURL, buildZip(t, map[string]string{"en.json": `{"a":"b"}`}, nil))
+	return exports
+}
+
+func TestDownloader_DownloadIfChanged(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var modified atomic.Int64
+	modified.Store(100)
+	exports := freshnessAPI(t, func() string {
+		return fmt.Sprintf(`{"keys":[{"modified_at_timestamp":50,"translations_modified_at_timestamp":%d},{"modified_at_timestamp":70}]}`, modified.Load())
+	})
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := download.NewDownloader(cli)
+	dest := t.TempDir()
+	params := download.DownloadParams{"format": "json"}
+	ctx := context.Background()
+
+	changed, err := dl.DownloadIfChanged(ctx, dest, params, download.FreshnessOptions{})
+	if err != nil || !changed {
+		t.Fatalf("first pull: changed = %v, err = %v; want true, nil", changed, err)
+	}
+	m, ok, err := download.ReadManifest(filepath.Join(dest, download.DefaultManifestName))
+	if err != nil || !ok || m.Version != "keys:2:100" || m.ProjectID != projectID || m.PulledAt.IsZero() {
+		t.Fatalf("manifest = %+v, %v, %v", m, ok, err)
+	}
+
+	changed, err = dl.DownloadIfChanged(ctx, dest, params, download.FreshnessOptions{})
+	if err != nil || changed {
+		t.Fatalf("unchanged project: changed = %v, err = %v; want false, nil", changed, err)
+	}
+	if exports.Load() != 1 {
+		t.Fatalf("exports = %d, want 1", exports.Load())
+	}
+
+	// Different params force a pull even if the project is unchanged.
+	changed, err = dl.DownloadIfChanged(ctx, dest, download.DownloadParams{"format": "json", "filter_langs": []string{"en"}}, download.FreshnessOptions{})
+	if err != nil || !changed {
+		t.Fatalf("changed params: changed = %v, err = %v; want true, nil", changed, err)
+	}
+
+	// A translation edit bumps the version.
+	modified.Store(200)
+	changed, err = dl.DownloadIfChanged(ctx, dest, download.DownloadParams{"format": "json", "filter_langs": []string{"en"}}, download.FreshnessOptions{})
+	if err != nil || !changed {
+		t.Fatalf("modified project: changed = %v, err = %v; want true, nil", changed, err)
+	}
+	if exports.Load() != 3 {
+		t.Fatalf("exports = %d, want 3", exports.Load())
+	}
+}
+
+func TestDownloader_DownloadIfChanged_CustomProbeAndManifest(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	exports := freshnessAPI(t, func() string { return `{"keys":[]}` })
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := download.NewDownloader(cli)
+	manifest := filepath.Join(t.TempDir(), "state", "pull.manifest")
+	if err := os.MkdirAll(filepath.Dir(manifest), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var probes int
+	opts := download.FreshnessOptions{
+		ManifestPath: manifest,
+		Probe: func(context.Context, *download.Downloader) (string, error) {
+			probes++
+			return "v1", nil
+		},
+	}
+	for range 2 {
+		if _, err := dl.DownloadIfChanged(context.Background(), t.TempDir(), download.DownloadParams{"format": "json"}, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if probes != 2 || exports.Load() != 1 {
+		t.Fatalf("probes = %d, exports = %d; want 2, 1", probes, exports.Load())
+	}
+}
+
+func TestDownloader_DownloadIfChanged_StateStore(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	exports := freshnessAPI(t, func() string { return `{"keys":[]}` })
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := download.NewDownloader(cli)
+	state := client.NewMemoryStateStore()
+	opts := download.FreshnessOptions{
+		State: state,
+		Probe: func(context.Context, *download.Downloader) (string, error) { return "v1", nil },
+	}
+
+	// The manifest lives in the store, so a fresh checkout of the
+	// destination doesn't trigger another export.
+	dest := filepath.Join(t.TempDir(), "locales")
+	for range 2 {
+		if _, err := dl.DownloadIfChanged(context.Background(), dest, download.DownloadParams{"format": "json"}, opts); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dest, download.DefaultManifestName)); !os.IsNotExist(err) {
+			t.Fatalf("manifest file written next to the catalogs (err = %v)", err)
+		}
+		if err := os.RemoveAll(dest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if exports.Load() != 1 {
+		t.Fatalf("exports = %d, want 1", exports.Load())
+	}
+	if _, ok, _ := state.Get(context.Background(), download.ManifestNamespace, projectID+":"+filepath.ToSlash(dest)); !ok {
+		t.Fatal("manifest not found in the state store")
+	}
+}
+
+func TestDownloader_DownloadIfChanged_ProbeError(t *testing.T) {
+	t.Parallel()
+
+	cli, err := client.NewClient(token, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := download.NewDownloader(cli)
+	_, err = dl.DownloadIfChanged(context.Background(), t.TempDir(), nil, download.FreshnessOptions{
+		Probe: func(context.Context, *download.Downloader) (string, error) { return "", fmt.Errorf("probe boom") },
+	})
+	if err == nil || !strings.Contains(err.Error(), "probe boom") {
+		t.Fatalf("error = %v, want probe error", err)
+	}
+}
+
+func TestProbeStatistics(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	stats := `{"keys_total":10}`
+	httpmock.RegisterResponder("GET", fmt.Sprintf("https://api.lokalise.com/api2/projects/%s", projectID), func(*http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(200, `{"project_id":"x","statistics":`+stats+`}`), nil
+	})
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := download.NewDownloader(cli)
+
+	v1, err := download.ProbeStatistics(context.Background(), dl)
+	if err != nil || !strings.HasPrefix(v1, "stats:") {
+		t.Fatalf("ProbeStatistics() = %q, %v", v1, err)
+	}
+	v2, _ := download.ProbeStatistics(context.Background(), dl)
+	stats = `{"keys_total":11}`
+	v3, _ := download.ProbeStatistics(context.Background(), dl)
+	if v1 != v2 || v1 == v3 {
+		t.Fatalf("versions = %q, %q, %q; want equal, equal, different", v1, v2, v3)
+	}
+}
+
+func TestProbeKeysModified_Paging(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	defer download.ExportSetProbePageLimitForTest(2)()
+
+	pages := map[string]string{
+		"1": `{"keys":[{"modified_at_timestamp":5},{"modified_at_timestamp":9,"translations_modified_at_timestamp":3}]}`,
+		"2": `{"keys":[{"modified_at_timestamp":1,"translations_modified_at_timestamp":7}]}`,
+	}
+	httpmock.RegisterResponder("GET", `=~/projects/.+/keys`, func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("limit") != "2" {
+			t.Errorf("limit = %q, want 2", req.URL.Query().Get("limit"))
+		}
+		return httpmock.NewStringResponse(200, pages[req.URL.Query().Get("page")]), nil
+	})
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := download.ProbeKeysModified(context.Background(), download.NewDownloader(cli))
+	if err != nil || got != "keys:3:9" {
+		t.Fatalf("ProbeKeysModified() = %q, %v; want keys:3:9", got, err)
+	}
+}
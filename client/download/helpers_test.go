@@ -2,61 +2,26 @@ package download_test
 
 import (
 	"archive/zip"
-	"bytes"
 	"io"
 	"net/http"
-	"os"
-	"strings"
 	"testing"
 
 	"github.com/bodrovis/lokex/v2/internal/utils"
+	"github.com/bodrovis/lokex/v2/testutils"
 
 	"github.com/jarcoal/httpmock"
 )
 
 func buildZip(t *testing.T, entries map[string]string, symlinks map[string]string) []byte {
 	t.Helper()
-	var buf bytes.Buffer
-	zw := zip.NewWriter(&buf)
-
-	// regular files
+	var zes []testutils.ZipEntry
 	for name, content := range entries {
-		fh := &zip.FileHeader{
-			Name:   name,
-			Method: zip.Deflate,
-		}
-		// ensure dirs implied by name are created in archive entries (zip doesn't need explicit dir entries)
-		fh.SetMode(0o644)
-		w, err := zw.CreateHeader(fh)
-		if err != nil {
-			t.Fatalf("CreateHeader(%s): %v", name, err)
-		}
-		if _, err := io.Copy(w, strings.NewReader(content)); err != nil {
-			t.Fatalf("write %s: %v", name, err)
-		}
+		zes = append(zes, testutils.ZipEntry{Name: name, Data: []byte(content), Method: zip.Deflate})
 	}
-
-	// symlinks (write target path as file content; unzipSafe should skip or handle)
 	for link, target := range symlinks {
-		fh := &zip.FileHeader{
-			Name:   link,
-			Method: zip.Store,
-		}
-		// mark as symlink
-		fh.SetMode(os.ModeSymlink | 0o777)
-		w, err := zw.CreateHeader(fh)
-		if err != nil {
-			t.Fatalf("CreateHeader(symlink %s): %v", link, err)
-		}
-		if _, err := io.Copy(w, strings.NewReader(target)); err != nil {
-			t.Fatalf("write symlink %s: %v", link, err)
-		}
-	}
-
-	if err := zw.Close(); err != nil {
-		t.Fatalf("close zip: %v", err)
+		zes = append(zes, testutils.ZipSymlink(link, target))
 	}
-	return buf.Bytes()
+	return testutils.BuildZip(t, zes...)
 }
 
 func registerZipResponder(t *testing.T, url string, zipBytes []byte) {
@@ -0,0 +1,16 @@
+package download
+
+import "github.com/bodrovis/lokex/v2/client"
+
+// WithLineEndings returns a copy of d that rewrites the line endings of
+// extracted files to le, so a sync on Windows and one on macOS produce the
+// same files. Files that look binary are written as downloaded.
+// DownloadToMany applies it too; BundleStore.Extract does not.
+func (d *Downloader) WithLineEndings(le client.LineEnding) *Downloader {
+	if d == nil {
+		return nil
+	}
+	cp := *d
+	cp.lineEnding = le
+	return &cp
+}
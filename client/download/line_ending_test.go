@@ -0,0 +1,75 @@
+package download_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestDownloader_WithLineEndings(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	cdnURL := "https://cdn.example.com/eol.zip"
+	httpmock.RegisterResponder("POST", fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/files/download", projectID),
+		httpmock.NewStringResponder(200, `{"bundle_url":"`+cdnURL+`"}`))
+	registerZipResponder(t, cdnURL, buildZip(t, map[string]string{
+		"en.strings": "\"a\" = \"b\";\r\n\"c\" = \"d\";\n",
+		"en.mo":      "\x00\x01\n",
+	}, nil))
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := download.NewDownloader(cli)
+	params := download.DownloadParams{"format": "strings"}
+
+	read := func(dir, name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	crlf := t.TempDir()
+	if _, err := dl.WithLineEndings(client.LineEndingCRLF).Download(context.Background(), crlf, params); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if got := read(crlf, "en.strings"); got != "\"a\" = \"b\";\r\n\"c\" = \"d\";\r\n" {
+		t.Fatalf("en.strings = %q, want CRLF", got)
+	}
+	if got := read(crlf, "en.mo"); got != "\x00\x01\n" {
+		t.Fatalf("en.mo = %q, want unchanged", got)
+	}
+
+	s, err := download.NewBundleStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lf := t.TempDir()
+	if _, err := dl.WithLineEndings(client.LineEndingLF).DownloadToMany(context.Background(), s, params, lf); err != nil {
+		t.Fatalf("DownloadToMany() error = %v", err)
+	}
+	if got := read(lf, "en.strings"); got != "\"a\" = \"b\";\n\"c\" = \"d\";\n" {
+		t.Fatalf("en.strings = %q, want LF", got)
+	}
+
+	// The original downloader keeps the bundle's line endings.
+	kept := t.TempDir()
+	if _, err := dl.Download(context.Background(), kept, params); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if got := read(kept, "en.strings"); got != "\"a\" = \"b\";\r\n\"c\" = \"d\";\n" {
+		t.Fatalf("en.strings = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,238 @@
+package download
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Merger combines a file from a delta bundle with the local file it updates.
+// incoming is nil when only removals apply. removed lists key names to
+// delete (dot-separated paths, see DeltaOptions.Removed).
+type Merger interface {
+	Merge(existing, incoming []byte, removed []string) ([]byte, error)
+}
+
+// MergerFunc adapts a function to Merger.
+type MergerFunc func(existing, incoming []byte, removed []string) ([]byte, error)
+
+// Merge calls f.
+func (f MergerFunc) Merge(existing, incoming []byte, removed []string) ([]byte, error) {
+	return f(existing, incoming, removed)
+}
+
+// formatExts maps export formats to the extensions of the files they produce.
+var formatExts = map[string][]string{
+	"json": {".json"},
+	"yaml": {".yml", ".yaml"},
+}
+
+func extsForFormat(format string) []string {
+	if exts, ok := formatExts[format]; ok {
+		return exts
+	}
+	return []string{"." + format}
+}
+
+// JSONMerger deep-merges JSON objects. Keys keep their order in the local
+// file, new keys are appended, and the file's indentation and trailing
+// newline are preserved. Removing the last key of an object removes the
+// object too.
+type JSONMerger struct{}
+
+// Merge implements Merger.
+func (JSONMerger) Merge(existing, incoming []byte, removed []string) ([]byte, error) {
+	base, err := parseJSONObject(existing)
+	if err != nil {
+		return nil, fmt.Errorf("existing file: %w", err)
+	}
+	if incoming != nil {
+		patch, err := parseJSONObject(incoming)
+		if err != nil {
+			return nil, fmt.Errorf("incoming file: %w", err)
+		}
+		base.merge(patch)
+	}
+	for _, name := range removed {
+		base.remove(name)
+	}
+
+	indent, multiline := detectJSONIndent(existing)
+	var buf bytes.Buffer
+	base.write(&buf, indent, multiline, 0)
+	if bytes.HasSuffix(bytes.TrimRight(existing, " \t\r"), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonObject is a JSON object that remembers key order. Values are either
+// *jsonObject or compacted json.RawMessage.
+type jsonObject struct {
+	keys []string
+	vals map[string]any
+}
+
+func parseJSONObject(data []byte) (*jsonObject, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil, errors.New("not a JSON object")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // {
+		return nil, err
+	}
+	o := &jsonObject{vals: make(map[string]any)}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		var val any
+		if raw[0] == '{' {
+			if val, err = parseJSONObject(raw); err != nil {
+				return nil, err
+			}
+		} else {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, raw); err != nil {
+				return nil, err
+			}
+			val = json.RawMessage(compact.Bytes())
+		}
+		o.set(key, val)
+	}
+	if _, err := dec.Token(); err != nil { // }
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *jsonObject) set(key string, val any) {
+	if _, ok := o.vals[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.vals[key] = val
+}
+
+func (o *jsonObject) delete(key string) {
+	delete(o.vals, key)
+	o.keys = slices.DeleteFunc(o.keys, func(k string) bool { return k == key })
+}
+
+func (o *jsonObject) merge(patch *jsonObject) {
+	for _, k := range patch.keys {
+		pv := patch.vals[k]
+		if po, ok := pv.(*jsonObject); ok {
+			if bo, ok := o.vals[k].(*jsonObject); ok {
+				bo.merge(po)
+				continue
+			}
+		}
+		o.set(k, pv)
+	}
+}
+
+// remove deletes the key name, which may be stored flat ("home.title") or
+// nested under any split of its dot-separated segments. If the root has a
+// single object member (a locale root such as {"en": {...}}), the name is
+// also looked up under it.
+func (o *jsonObject) remove(name string) {
+	if o.removePath(name) || len(o.keys) != 1 {
+		return
+	}
+	if inner, ok := o.vals[o.keys[0]].(*jsonObject); ok {
+		inner.removePath(name)
+		if len(inner.keys) == 0 {
+			o.delete(o.keys[0])
+		}
+	}
+}
+
+func (o *jsonObject) removePath(path string) bool {
+	if _, ok := o.vals[path]; ok {
+		o.delete(path)
+		return true
+	}
+	for i := range len(path) {
+		if path[i] != '.' {
+			continue
+		}
+		child, ok := o.vals[path[:i]].(*jsonObject)
+		if !ok || !child.removePath(path[i+1:]) {
+			continue
+		}
+		if len(child.keys) == 0 {
+			o.delete(path[:i])
+		}
+		return true
+	}
+	return false
+}
+
+func (o *jsonObject) write(buf *bytes.Buffer, indent string, multiline bool, depth int) {
+	if len(o.keys) == 0 {
+		buf.WriteString("{}")
+		return
+	}
+
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if multiline {
+			buf.WriteByte('\n')
+			buf.WriteString(strings.Repeat(indent, depth+1))
+		}
+		writeJSONString(buf, k)
+		buf.WriteByte(':')
+		if multiline {
+			buf.WriteByte(' ')
+		}
+		switch v := o.vals[k].(type) {
+		case *jsonObject:
+			v.write(buf, indent, multiline, depth+1)
+		case json.RawMessage:
+			buf.Write(v)
+		}
+	}
+	if multiline {
+		buf.WriteByte('\n')
+		buf.WriteString(strings.Repeat(indent, depth))
+	}
+	buf.WriteByte('}')
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	buf.Truncate(buf.Len() - 1) // Encode appends a newline
+}
+
+// detectJSONIndent returns the indentation unit of the first indented line
+// and whether the document spans several lines. It defaults to two spaces.
+func detectJSONIndent(data []byte) (string, bool) {
+	lines := strings.Split(string(bytes.TrimSpace(data)), "\n")
+	if len(lines) < 2 {
+		return "", false
+	}
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if ws := line[:len(line)-len(trimmed)]; ws != "" && trimmed != "" {
+			return ws, true
+		}
+	}
+	return "  ", true
+}
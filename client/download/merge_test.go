@@ -0,0 +1,98 @@
+package download_test
+
+import (
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/download"
+)
+
+func TestJSONMerger(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		existing string
+		incoming string
+		removed  []string
+		want     string
+	}{
+		{
+			name:     "keeps order, indentation and trailing newline",
+			existing: "{\n    \"b\": \"1\",\n    \"a\": {\n        \"x\": \"old\",\n        \"y\": \"keep\"\n    }\n}\n",
+			incoming: `{"a":{"x":"new <b>&</b>","z":"added"},"c":"tail"}`,
+			want:     "{\n    \"b\": \"1\",\n    \"a\": {\n        \"x\": \"new <b>&</b>\",\n        \"y\": \"keep\",\n        \"z\": \"added\"\n    },\n    \"c\": \"tail\"\n}\n",
+		},
+		{
+			name:     "tabs",
+			existing: "{\n\t\"a\": \"1\"\n}",
+			incoming: `{"b":"2"}`,
+			want:     "{\n\t\"a\": \"1\",\n\t\"b\": \"2\"\n}",
+		},
+		{
+			name:     "compact stays compact",
+			existing: `{"a":"1","n":[1, 2]}`,
+			incoming: `{"a":"2"}`,
+			want:     `{"a":"2","n":[1,2]}`,
+		},
+		{
+			name:     "object replaces scalar",
+			existing: `{"a":"1"}`,
+			incoming: `{"a":{"b":"2"}}`,
+			want:     `{"a":{"b":"2"}}`,
+		},
+		{
+			name:     "removes flat and nested keys and prunes empty objects",
+			existing: `{"home.title":"t","menu":{"file":{"open":"o"},"edit":"e"},"keep":"k"}`,
+			removed:  []string{"home.title", "menu.file.open", "missing.key"},
+			want:     `{"menu":{"edit":"e"},"keep":"k"}`,
+		},
+		{
+			name:     "removes under a locale root",
+			existing: `{"en":{"home":{"title":"t"},"other":"o"}}`,
+			removed:  []string{"home.title"},
+			want:     `{"en":{"other":"o"}}`,
+		},
+		{
+			name:     "merge then remove",
+			existing: `{"a":"1","b":"2"}`,
+			incoming: `{"c":"3"}`,
+			removed:  []string{"a"},
+			want:     `{"b":"2","c":"3"}`,
+		},
+		{
+			name:     "empty result",
+			existing: `{"a":"1"}`,
+			removed:  []string{"a"},
+			want:     `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var incoming []byte
+			if tt.incoming != "" {
+				incoming = []byte(tt.incoming)
+			}
+			got, err := download.JSONMerger{}.Merge([]byte(tt.existing), incoming, tt.removed)
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("Merge() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONMerger_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (download.JSONMerger{}).Merge([]byte(`[1]`), nil, nil); err == nil {
+		t.Fatal("array existing: error = nil")
+	}
+	if _, err := (download.JSONMerger{}).Merge([]byte(`{}`), []byte(`{"a":`), nil); err == nil {
+		t.Fatal("truncated incoming: error = nil")
+	}
+}
@@ -0,0 +1,168 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Bundle structure placeholders understood by Lokalise.
+const (
+	PlaceholderLangISO     = "%LANG_ISO%"
+	PlaceholderLangName    = "%LANG_NAME%"
+	PlaceholderFormat      = "%FORMAT%"
+	PlaceholderProjectName = "%PROJECT_NAME%"
+)
+
+var knownPlaceholders = []string{
+	PlaceholderLangISO,
+	PlaceholderLangName,
+	PlaceholderFormat,
+	PlaceholderProjectName,
+}
+
+// Values accepted by export_empty_as.
+const (
+	ExportEmptyAsEmpty = "empty"
+	ExportEmptyAsBase  = "base"
+	ExportEmptyAsSkip  = "skip"
+)
+
+// ParamsBuilder builds DownloadParams for params that are easy to get wrong
+// by hand (wrong JSON shape, typos in placeholders). Methods can be chained;
+// validation errors are collected and returned together by Build.
+type ParamsBuilder struct {
+	params DownloadParams
+	errs   []error
+}
+
+// NewParams starts a builder for the given export format (e.g. "json").
+func NewParams(format string) *ParamsBuilder {
+	b := &ParamsBuilder{params: DownloadParams{}}
+	format = strings.TrimSpace(format)
+	if format == "" {
+		b.errs = append(b.errs, errors.New("format is required"))
+	} else {
+		b.params["format"] = format
+	}
+	return b
+}
+
+// Set stores an arbitrary param as-is, for options without a dedicated method.
+func (b *ParamsBuilder) Set(key string, value any) *ParamsBuilder {
+	b.params[key] = value
+	return b
+}
+
+// FilterLangs limits the export to the given language ISO codes.
+func (b *ParamsBuilder) FilterLangs(langs ...string) *ParamsBuilder {
+	return b.stringList("filter_langs", langs)
+}
+
+// FilterFilenames limits the export to keys assigned to the given filenames.
+// The API expects a JSON array, not a comma-separated string.
+func (b *ParamsBuilder) FilterFilenames(names ...string) *ParamsBuilder {
+	return b.stringList("filter_filenames", names)
+}
+
+// FilterKeys limits the export to the given key names.
+func (b *ParamsBuilder) FilterKeys(names ...string) *ParamsBuilder {
+	return b.stringList("filter_keys", names)
+}
+
+// LanguageMapping exports the project language original under the ISO code
+// custom (e.g. "en_US" as "en"). Calls accumulate.
+func (b *ParamsBuilder) LanguageMapping(original, custom string) *ParamsBuilder {
+	original, custom = strings.TrimSpace(original), strings.TrimSpace(custom)
+	if original == "" || custom == "" {
+		b.errs = append(b.errs, errors.New("language_mapping: original and custom ISO codes are required"))
+		return b
+	}
+
+	mapping, _ := b.params["language_mapping"].([]map[string]string)
+	b.params["language_mapping"] = append(mapping, map[string]string{
+		"original_language_iso": original,
+		"custom_language_iso":   custom,
+	})
+	return b
+}
+
+// ExportEmptyAs sets how untranslated strings are exported: one of
+// ExportEmptyAsEmpty, ExportEmptyAsBase or ExportEmptyAsSkip.
+func (b *ParamsBuilder) ExportEmptyAs(mode string) *ParamsBuilder {
+	switch mode {
+	case ExportEmptyAsEmpty, ExportEmptyAsBase, ExportEmptyAsSkip:
+		b.params["export_empty_as"] = mode
+	default:
+		b.errs = append(b.errs, fmt.Errorf("export_empty_as: unsupported value %q", mode))
+	}
+	return b
+}
+
+// BundleStructure sets the bundle_structure template (for example
+// "locales/%LANG_ISO%.%FORMAT%") and turns original_filenames off, since
+// Lokalise ignores the template otherwise. The template is checked with
+// ValidateBundleStructure.
+func (b *ParamsBuilder) BundleStructure(template string) *ParamsBuilder {
+	if err := ValidateBundleStructure(template); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.params["bundle_structure"] = template
+	b.params["original_filenames"] = false
+	return b
+}
+
+// Build returns a copy of the collected params, or all validation errors.
+func (b *ParamsBuilder) Build() (DownloadParams, error) {
+	if len(b.errs) > 0 {
+		return nil, fmt.Errorf("download params: %w", errors.Join(b.errs...))
+	}
+	return maps.Clone(b.params), nil
+}
+
+func (b *ParamsBuilder) stringList(key string, values []string) *ParamsBuilder {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("%s: at least one non-empty value is required", key))
+		return b
+	}
+	b.params[key] = out
+	return b
+}
+
+// ValidateBundleStructure checks a bundle_structure template: it must not be
+// empty, must contain %LANG_ISO% (otherwise every language is written to the
+// same path), and may only use placeholders Lokalise knows about.
+func ValidateBundleStructure(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return errors.New("bundle_structure: empty template")
+	}
+	if !strings.Contains(template, PlaceholderLangISO) {
+		return fmt.Errorf("bundle_structure: %q does not contain %s", template, PlaceholderLangISO)
+	}
+
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '%')
+		if start < 0 {
+			return nil
+		}
+		end := strings.IndexByte(rest[start+1:], '%')
+		if end < 0 {
+			return fmt.Errorf("bundle_structure: unterminated placeholder in %q", template)
+		}
+		ph := rest[start : start+end+2]
+		if !slices.Contains(knownPlaceholders, ph) {
+			return fmt.Errorf("bundle_structure: unknown placeholder %s in %q", ph, template)
+		}
+		rest = rest[start+end+2:]
+	}
+}
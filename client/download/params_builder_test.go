@@ -0,0 +1,122 @@
+package download_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/download"
+)
+
+func TestParamsBuilder_Build(t *testing.T) {
+	t.Parallel()
+
+	params, err := download.NewParams("json").
+		FilterLangs("en", " fr ").
+		FilterFilenames("app.json", "", "errors.json").
+		FilterKeys("welcome").
+		LanguageMapping("en_US", "en").
+		LanguageMapping("fr_CA", "fr").
+		ExportEmptyAs(download.ExportEmptyAsBase).
+		BundleStructure("locales/%LANG_ISO%/%PROJECT_NAME%.%FORMAT%").
+		Set("include_tags", []string{"release"}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"bundle_structure":"locales/%LANG_ISO%/%PROJECT_NAME%.%FORMAT%",` +
+		`"export_empty_as":"base",` +
+		`"filter_filenames":["app.json","errors.json"],` +
+		`"filter_keys":["welcome"],` +
+		`"filter_langs":["en","fr"],` +
+		`"format":"json",` +
+		`"include_tags":["release"],` +
+		`"language_mapping":[{"custom_language_iso":"en","original_language_iso":"en_US"},` +
+		`{"custom_language_iso":"fr","original_language_iso":"fr_CA"}],` +
+		`"original_filenames":false}`
+	if string(raw) != want {
+		t.Fatalf("params JSON =\n%s\nwant\n%s", raw, want)
+	}
+}
+
+func TestParamsBuilder_BuildReturnsCopy(t *testing.T) {
+	t.Parallel()
+
+	b := download.NewParams("json")
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	first["format"] = "xml"
+
+	second, _ := b.Build()
+	if second["format"] != "json" {
+		t.Fatalf("format = %v, want json", second["format"])
+	}
+}
+
+func TestParamsBuilder_CollectsErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := download.NewParams(" ").
+		FilterFilenames(" ", "").
+		LanguageMapping("en", "").
+		ExportEmptyAs("nothing").
+		BundleStructure("%LANG_ISO%/%FROMAT%").
+		Build()
+	if err == nil {
+		t.Fatal("Build() error = nil, want error")
+	}
+
+	for _, want := range []string{
+		"format is required",
+		"filter_filenames: at least one",
+		"language_mapping:",
+		`export_empty_as: unsupported value "nothing"`,
+		"unknown placeholder %FROMAT%",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestValidateBundleStructure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{name: "simple", in: "%LANG_ISO%.json"},
+		{name: "all placeholders", in: "%PROJECT_NAME%/%LANG_NAME%/%LANG_ISO%.%FORMAT%"},
+		{name: "empty", in: "  ", wantErr: "empty template"},
+		{name: "missing lang iso", in: "locales/%LANG_NAME%.json", wantErr: "does not contain %LANG_ISO%"},
+		{name: "lowercase placeholder", in: "%LANG_ISO%/%format%", wantErr: "unknown placeholder %format%"},
+		{name: "unterminated", in: "%LANG_ISO%/%FORMAT", wantErr: "unterminated placeholder"},
+		{name: "stray percent", in: "%LANG_ISO%/100%.json", wantErr: "unterminated placeholder"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := download.ValidateBundleStructure(tt.in)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateBundleStructure(%q) error = %v", tt.in, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateBundleStructure(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+		})
+	}
+}
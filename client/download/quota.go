@@ -0,0 +1,211 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+// ErrExportQuotaExceeded is matched (via errors.Is) by a *QuotaExceededError.
+var ErrExportQuotaExceeded = errors.New("download: export quota exceeded")
+
+// QuotaExceededError reports that an export was refused, either locally by
+// an ExportQuota (Remote false) or by Lokalise with a 429 (Remote true, Err
+// holds the API error).
+type QuotaExceededError struct {
+	Used    int           // exports counted in the current window (local only)
+	Limit   int           // window limit (local only)
+	ResetIn time.Duration // time until an export may succeed; 0 if unknown
+	Remote  bool
+	Err     error
+}
+
+func (e *QuotaExceededError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrExportQuotaExceeded.Error())
+	if !e.Remote {
+		fmt.Fprintf(&b, " (%d/%d in window)", e.Used, e.Limit)
+	}
+	if e.ResetIn > 0 {
+		fmt.Fprintf(&b, ", retry in %s", e.ResetIn.Round(time.Second))
+	}
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *QuotaExceededError) Is(target error) bool { return target == ErrExportQuotaExceeded }
+
+func (e *QuotaExceededError) Unwrap() error { return e.Err }
+
+// QuotaStatus is a snapshot of an ExportQuota.
+type QuotaStatus struct {
+	Used    int
+	Limit   int
+	Window  time.Duration
+	ResetIn time.Duration // until the oldest counted export leaves the window
+}
+
+// QuotaOptions configures NewExportQuota.
+type QuotaOptions struct {
+	// Throttle waits for a free slot instead of failing with a
+	// *QuotaExceededError.
+	Throttle bool
+	// WarnAt is the usage at which OnWarn is called (default: 80% of the
+	// limit).
+	WarnAt int
+	// OnWarn is called after each export that brings usage to WarnAt or
+	// above. It must not block.
+	OnWarn func(QuotaStatus)
+}
+
+// ExportQuota counts exports (sync and async kickoffs) in a sliding window so
+// callers can see how close they are to Lokalise's export limits and stop
+// before the API starts refusing. Share one quota between all downloaders of
+// a project. It is safe for concurrent use.
+type ExportQuota struct {
+	limit  int
+	window time.Duration
+	opts   QuotaOptions
+
+	mu           sync.Mutex
+	stamps       []time.Time // export times inside the window, oldest first
+	blockedUntil time.Time   // reset time reported by a remote 429
+}
+
+// NewExportQuota allows limit exports per window.
+func NewExportQuota(limit int, window time.Duration, opts QuotaOptions) (*ExportQuota, error) {
+	if limit < 1 {
+		return nil, errors.New("download: quota limit must be at least 1")
+	}
+	if window <= 0 {
+		return nil, errors.New("download: quota window must be positive")
+	}
+	if opts.WarnAt <= 0 {
+		opts.WarnAt = max(1, limit*4/5)
+	}
+	return &ExportQuota{limit: limit, window: window, opts: opts}, nil
+}
+
+// Status returns the current usage.
+func (q *ExportQuota) Status() QuotaStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.statusLocked(time.Now())
+}
+
+func (q *ExportQuota) statusLocked(now time.Time) QuotaStatus {
+	q.pruneLocked(now)
+	st := QuotaStatus{Used: len(q.stamps), Limit: q.limit, Window: q.window}
+	if len(q.stamps) > 0 {
+		st.ResetIn = q.stamps[0].Add(q.window).Sub(now)
+	}
+	return st
+}
+
+func (q *ExportQuota) pruneLocked(now time.Time) {
+	i := 0
+	for i < len(q.stamps) && !now.Before(q.stamps[i].Add(q.window)) {
+		i++
+	}
+	q.stamps = q.stamps[i:]
+}
+
+// reserve counts one export, waiting for a slot if the quota throttles.
+func (q *ExportQuota) reserve(ctx context.Context) error {
+	if q == nil {
+		return nil
+	}
+	for {
+		q.mu.Lock()
+		now := time.Now()
+		st := q.statusLocked(now)
+		var wait time.Duration
+		switch {
+		case now.Before(q.blockedUntil):
+			wait = q.blockedUntil.Sub(now)
+		case st.Used >= q.limit:
+			wait = st.ResetIn
+		default:
+			q.stamps = append(q.stamps, now)
+			st.Used++
+			q.mu.Unlock()
+			if st.Used >= q.opts.WarnAt && q.opts.OnWarn != nil {
+				q.opts.OnWarn(st)
+			}
+			return nil
+		}
+		q.mu.Unlock()
+
+		if !q.opts.Throttle {
+			return &QuotaExceededError{Used: st.Used, Limit: q.limit, ResetIn: wait}
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return fmt.Errorf("download: wait for export quota: %w", ctx.Err())
+		case <-t.C:
+		}
+	}
+}
+
+// block makes the quota refuse exports for d, after the API reported it.
+func (q *ExportQuota) block(d time.Duration) {
+	if q == nil || d <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.blockedUntil = time.Now().Add(d)
+}
+
+// WithExportQuota returns a copy of d that counts its exports against q.
+func (d *Downloader) WithExportQuota(q *ExportQuota) *Downloader {
+	if d == nil {
+		return nil
+	}
+	cp := *d
+	cp.quota = q
+	return &cp
+}
+
+// quotaErr turns a 429 from an export endpoint into a *QuotaExceededError
+// carrying the reset hint, and tells the quota to hold off until then.
+func (d *Downloader) quotaErr(err error) error {
+	var ae *apierr.APIError
+	if !errors.As(err, &ae) || ae.Status != http.StatusTooManyRequests {
+		return err
+	}
+	reset := rateLimitReset(err)
+	d.quota.block(reset)
+	return &QuotaExceededError{ResetIn: reset, Remote: true, Err: err}
+}
+
+// rateLimitReset reads the reset hint from Retry-After or, failing that,
+// from the X-Rate-Limit-Reset epoch header.
+func rateLimitReset(err error) time.Duration {
+	if d, ok := apierr.RetryAfter(err); ok {
+		return d
+	}
+	var ae *apierr.APIError
+	if !errors.As(err, &ae) || ae.Resp == nil {
+		return 0
+	}
+	for _, h := range []string{"X-Rate-Limit-Reset", "X-RateLimit-Reset"} {
+		secs, perr := strconv.ParseInt(strings.TrimSpace(ae.Resp.Header.Get(h)), 10, 64)
+		if perr == nil {
+			return max(time.Until(time.Unix(secs, 0)), 0)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,221 @@
+package download_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+// exportServer answers export kickoffs (sync and async) and counts them.
+// Requests get the status, headers and body from respond.
+func exportServer(t *testing.T, respond func(n int32, w http.ResponseWriter)) (*download.Downloader, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if respond != nil {
+			respond(n, w)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/async-download") {
+			_, _ = w.Write([]byte(`{"process_id":"p1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"bundle_url":"https://cdn.example.com/b.zip"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return download.NewDownloader(cli), &calls
+}
+
+func TestNewExportQuota_Invalid(t *testing.T) {
+	t.Parallel()
+
+	if _, err := download.NewExportQuota(0, time.Hour, download.QuotaOptions{}); err == nil {
+		t.Fatal("NewExportQuota(limit 0) error = nil")
+	}
+	if _, err := download.NewExportQuota(1, 0, download.QuotaOptions{}); err == nil {
+		t.Fatal("NewExportQuota(window 0) error = nil")
+	}
+}
+
+func TestExportQuota_FailsLocallyAndWarns(t *testing.T) {
+	t.Parallel()
+
+	var warnings []download.QuotaStatus
+	q, err := download.NewExportQuota(2, time.Hour, download.QuotaOptions{
+		OnWarn: func(st download.QuotaStatus) { warnings = append(warnings, st) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, calls := exportServer(t, nil)
+	d = d.WithExportQuota(q)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := d.FetchBundle(ctx, strings.NewReader(`{}`)); err != nil {
+			t.Fatalf("FetchBundle() error = %v", err)
+		}
+	}
+	_, err = d.FetchBundle(ctx, strings.NewReader(`{}`))
+	if !errors.Is(err, download.ErrExportQuotaExceeded) {
+		t.Fatalf("third FetchBundle() error = %v, want ErrExportQuotaExceeded", err)
+	}
+	var qe *download.QuotaExceededError
+	if !errors.As(err, &qe) || qe.Remote || qe.Used != 2 || qe.Limit != 2 || qe.ResetIn <= 0 || qe.ResetIn > time.Hour {
+		t.Fatalf("QuotaExceededError = %+v", qe)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("server saw %d exports, want 2", got)
+	}
+
+	// WarnAt defaults to 80% of 2, rounded down: both exports warn.
+	if len(warnings) != 2 || warnings[1].Used != 2 || warnings[1].Limit != 2 {
+		t.Fatalf("warnings = %+v", warnings)
+	}
+	if st := q.Status(); st.Used != 2 || st.Window != time.Hour {
+		t.Fatalf("Status() = %+v", st)
+	}
+}
+
+func TestExportQuota_WindowSlides(t *testing.T) {
+	t.Parallel()
+
+	q, err := download.NewExportQuota(1, 50*time.Millisecond, download.QuotaOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := exportServer(t, nil)
+	d = d.WithExportQuota(q)
+
+	if _, err := download.ExportStartAsyncDownload(d, context.Background(), strings.NewReader(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := download.ExportStartAsyncDownload(d, context.Background(), strings.NewReader(`{}`)); !errors.Is(err, download.ErrExportQuotaExceeded) {
+		t.Fatalf("async export over quota error = %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if st := q.Status(); st.Used != 0 {
+		t.Fatalf("Used after window = %d, want 0", st.Used)
+	}
+	if _, err := download.ExportStartAsyncDownload(d, context.Background(), strings.NewReader(`{}`)); err != nil {
+		t.Fatalf("export after window error = %v", err)
+	}
+}
+
+func TestExportQuota_Throttle(t *testing.T) {
+	t.Parallel()
+
+	q, err := download.NewExportQuota(1, 80*time.Millisecond, download.QuotaOptions{Throttle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, calls := exportServer(t, nil)
+	d = d.WithExportQuota(q)
+
+	start := time.Now()
+	for range 2 {
+		if _, err := d.FetchBundle(context.Background(), strings.NewReader(`{}`)); err != nil {
+			t.Fatalf("FetchBundle() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Fatalf("second export was not throttled (took %v)", elapsed)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("server saw %d exports, want 2", calls.Load())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := d.FetchBundle(ctx, strings.NewReader(`{}`)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("throttled FetchBundle() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestExportQuota_Remote429(t *testing.T) {
+	t.Parallel()
+
+	d, calls := exportServer(t, func(_ int32, w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"Too many requests","code":429}}`))
+	})
+	q, err := download.NewExportQuota(100, time.Hour, download.QuotaOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d = d.WithExportQuota(q)
+
+	_, err = d.FetchBundle(context.Background(), strings.NewReader(`{}`))
+	var qe *download.QuotaExceededError
+	if !errors.As(err, &qe) || !qe.Remote || qe.ResetIn != 30*time.Second {
+		t.Fatalf("FetchBundle() error = %v, want remote quota error with 30s reset", err)
+	}
+	var ae *apierr.APIError
+	if !errors.As(err, &ae) || ae.Status != http.StatusTooManyRequests {
+		t.Fatalf("APIError not reachable: %v", err)
+	}
+	if !strings.Contains(err.Error(), "retry in 30s") {
+		t.Fatalf("error %q has no reset hint", err)
+	}
+
+	// The quota now refuses locally until the reset.
+	_, err = d.FetchBundle(context.Background(), strings.NewReader(`{}`))
+	if !errors.As(err, &qe) || qe.Remote {
+		t.Fatalf("second FetchBundle() error = %v, want local quota error", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("server saw %d exports, want 1", calls.Load())
+	}
+}
+
+func TestExportQuota_RemoteResetHeader(t *testing.T) {
+	t.Parallel()
+
+	reset := time.Now().Add(2 * time.Minute).Unix()
+	d, _ := exportServer(t, func(_ int32, w http.ResponseWriter) {
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := download.ExportStartAsyncDownload(d, context.Background(), strings.NewReader(`{}`))
+	var qe *download.QuotaExceededError
+	if !errors.As(err, &qe) || !qe.Remote {
+		t.Fatalf("error = %v, want remote quota error", err)
+	}
+	if qe.ResetIn < time.Minute || qe.ResetIn > 2*time.Minute {
+		t.Fatalf("ResetIn = %v, want about 2m", qe.ResetIn)
+	}
+}
+
+func TestExportQuota_OtherErrorsPassThrough(t *testing.T) {
+	t.Parallel()
+
+	d, _ := exportServer(t, func(_ int32, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"bad format","code":400}}`))
+	})
+
+	_, err := d.FetchBundle(context.Background(), strings.NewReader(`{}`))
+	if err == nil || errors.Is(err, download.ErrExportQuotaExceeded) {
+		t.Fatalf("error = %v, want plain API error", err)
+	}
+}
@@ -0,0 +1,114 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultBundleMaxRedirects = 5
+
+var (
+	// ErrRedirectLoop means a bundle URL redirected back to a URL it had
+	// already visited.
+	ErrRedirectLoop = errors.New("download: bundle redirect loop")
+	// ErrTooManyRedirects means a bundle URL redirected more often than
+	// Client.BundleMaxRedirects allows.
+	ErrTooManyRedirects = errors.New("download: too many bundle redirects")
+	// ErrInsecureRedirect means a bundle URL redirected to a non-https URL.
+	ErrInsecureRedirect = errors.New("download: bundle redirect leaves https")
+)
+
+// RedirectError is returned (wrapped) when a bundle download refuses to
+// follow a redirect. Err is ErrRedirectLoop, ErrTooManyRedirects,
+// ErrInsecureRedirect or the reason the target host was rejected; it is
+// never retried.
+type RedirectError struct {
+	URL  string // refused target, without its query string
+	Hops int    // redirects followed before it
+	Err  error
+}
+
+func (e *RedirectError) Error() string {
+	return fmt.Sprintf("download: refused redirect to %s after %d hop(s): %v", e.URL, e.Hops, e.Err)
+}
+
+func (e *RedirectError) Unwrap() error { return e.Err }
+
+// credentialHeaders are dropped when a redirect moves to another host.
+// net/http already drops Authorization and Cookie across domains, but not
+// across subdomains, and it knows nothing about X-Api-Token.
+var credentialHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Token",
+}
+
+// withRedirectPolicy returns a copy of hc that follows at most maxHops
+// redirects (0 uses the default, negative refuses every redirect), only to
+// https URLs on hosts validateHost accepts, and without credentials once
+// the host changes. A CheckRedirect already set on hc runs after these
+// checks.
+func withRedirectPolicy(hc *http.Client, maxHops int) *http.Client {
+	if maxHops == 0 {
+		maxHops = defaultBundleMaxRedirects
+	}
+	next := hc.CheckRedirect
+	cp := *hc
+	cp.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := checkRedirect(req, via, maxHops); err != nil {
+			return err
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return nil
+	}
+	return &cp
+}
+
+func checkRedirect(req *http.Request, via []*http.Request, maxHops int) error {
+	hops := len(via) - 1
+	refuse := func(err error) error {
+		return &RedirectError{URL: redactQuery(req.URL), Hops: hops, Err: err}
+	}
+
+	target := req.URL.String()
+	for _, prev := range via {
+		if prev.URL.String() == target {
+			return refuse(ErrRedirectLoop)
+		}
+	}
+	if maxHops < 0 || len(via) > maxHops {
+		return refuse(ErrTooManyRedirects)
+	}
+	if !strings.EqualFold(req.URL.Scheme, "https") {
+		return refuse(ErrInsecureRedirect)
+	}
+	if req.URL.User != nil {
+		return refuse(errors.New("download: url must not contain userinfo"))
+	}
+	if err := validateHost(req.URL.Hostname()); err != nil {
+		return refuse(err)
+	}
+
+	if !strings.EqualFold(req.URL.Hostname(), via[0].URL.Hostname()) {
+		for _, h := range credentialHeaders {
+			req.Header.Del(h)
+		}
+	}
+	return nil
+}
+
+// redactQuery drops the query, which on presigned CDN URLs carries the
+// signature.
+func redactQuery(u *url.URL) string {
+	cp := *u
+	cp.RawQuery = ""
+	cp.ForceQuery = false
+	cp.Fragment = ""
+	return cp.String()
+}
@@ -0,0 +1,183 @@
+package download_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+	"github.com/jarcoal/httpmock"
+)
+
+func redirectResponder(location string) httpmock.Responder {
+	return func(*http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(http.StatusFound, "")
+		resp.Header.Set("Location", location)
+		return resp, nil
+	}
+}
+
+func getBundle(t *testing.T, maxRedirects int, mt *httpmock.MockTransport, url string) (*http.Response, error) {
+	t.Helper()
+	d := download.NewDownloader(&client.Client{BundleMaxRedirects: maxRedirects})
+	hc := &http.Client{Transport: mt}
+	resp, err := download.ExportDoDownloadRequest(d, context.Background(), hc, url, "")
+	if resp != nil {
+		t.Cleanup(func() { _ = resp.Body.Close() })
+	}
+	return resp, err
+}
+
+func TestBundleRedirects_Followed(t *testing.T) {
+	t.Parallel()
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "https://cdn.example.com/a.zip", redirectResponder("https://cdn2.example.com/b.zip?sig=1"))
+	mt.RegisterResponder("GET", "https://cdn2.example.com/b.zip?sig=1", httpmock.NewStringResponder(200, "zip"))
+
+	resp, err := getBundle(t, 0, mt, "https://cdn.example.com/a.zip")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestBundleRedirects_Loop(t *testing.T) {
+	t.Parallel()
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "https://cdn.example.com/a.zip", redirectResponder("https://cdn.example.com/b.zip"))
+	mt.RegisterResponder("GET", "https://cdn.example.com/b.zip", redirectResponder("https://cdn.example.com/a.zip"))
+
+	_, err := getBundle(t, 0, mt, "https://cdn.example.com/a.zip")
+	if !errors.Is(err, download.ErrRedirectLoop) {
+		t.Fatalf("err = %v, want ErrRedirectLoop", err)
+	}
+	var re *download.RedirectError
+	if !errors.As(err, &re) {
+		t.Fatalf("err = %T, want *RedirectError in chain", err)
+	}
+	if re.URL != "https://cdn.example.com/a.zip" || re.Hops != 1 {
+		t.Fatalf("RedirectError = %+v", re)
+	}
+	if got := mt.GetTotalCallCount(); got != 2 {
+		t.Fatalf("calls = %d, want 2", got)
+	}
+}
+
+func TestBundleRedirects_TooMany(t *testing.T) {
+	t.Parallel()
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "https://cdn.example.com/1", redirectResponder("https://cdn.example.com/2"))
+	mt.RegisterResponder("GET", "https://cdn.example.com/2", redirectResponder("https://cdn.example.com/3"))
+	mt.RegisterResponder("GET", "https://cdn.example.com/3", httpmock.NewStringResponder(200, "zip"))
+
+	if _, err := getBundle(t, 1, mt, "https://cdn.example.com/1"); !errors.Is(err, download.ErrTooManyRedirects) {
+		t.Fatalf("max 1: err = %v, want ErrTooManyRedirects", err)
+	}
+	if _, err := getBundle(t, -1, mt, "https://cdn.example.com/1"); !errors.Is(err, download.ErrTooManyRedirects) {
+		t.Fatalf("none: err = %v, want ErrTooManyRedirects", err)
+	}
+	if _, err := getBundle(t, 2, mt, "https://cdn.example.com/1"); err != nil {
+		t.Fatalf("max 2: unexpected error: %v", err)
+	}
+}
+
+func TestBundleRedirects_RejectsUnsafeTargets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, location string
+		want           error
+	}{
+		{"http downgrade", "http://cdn.example.com/b.zip?sig=secret", download.ErrInsecureRedirect},
+		{"loopback", "https://127.0.0.1/b.zip", nil},
+		{"internal host", "https://bucket.internal/b.zip", nil},
+		{"userinfo", "https://user:pw@cdn2.example.com/b.zip", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder("GET", "https://cdn.example.com/a.zip", redirectResponder(tt.location))
+
+			_, err := getBundle(t, 0, mt, "https://cdn.example.com/a.zip")
+			var re *download.RedirectError
+			if !errors.As(err, &re) {
+				t.Fatalf("err = %v, want *RedirectError", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if got := mt.GetTotalCallCount(); got != 1 {
+				t.Fatalf("calls = %d, want 1 (target must not be requested)", got)
+			}
+			if tt.name == "http downgrade" && re.URL != "http://cdn.example.com/b.zip" {
+				t.Fatalf("RedirectError.URL = %q, want query stripped", re.URL)
+			}
+		})
+	}
+}
+
+func TestBundleRedirects_StripsCredentialsAcrossHosts(t *testing.T) {
+	t.Parallel()
+
+	check := download.ExportWithRedirectPolicy(&http.Client{}, 0).CheckRedirect
+	newReq := func(url string) *http.Request {
+		r, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Authorization", "Bearer x")
+		r.Header.Set("X-Api-Token", "tok")
+		r.Header.Set("Cookie", "a=b")
+		r.Header.Set("Accept", "application/zip")
+		return r
+	}
+	via := []*http.Request{newReq("https://cdn.example.com/a.zip")}
+
+	same := newReq("https://cdn.example.com/b.zip")
+	if err := check(same, via); err != nil {
+		t.Fatalf("same host: %v", err)
+	}
+	if same.Header.Get("X-Api-Token") == "" {
+		t.Fatal("same host: credentials stripped, want kept")
+	}
+
+	other := newReq("https://eu.cdn.example.com/b.zip")
+	if err := check(other, via); err != nil {
+		t.Fatalf("other host: %v", err)
+	}
+	for _, h := range []string{"Authorization", "X-Api-Token", "Cookie"} {
+		if v := other.Header.Get(h); v != "" {
+			t.Errorf("%s kept across hosts: %q", h, v)
+		}
+	}
+	if other.Header.Get("Accept") == "" {
+		t.Error("Accept stripped, want kept")
+	}
+}
+
+func TestBundleRedirects_KeepsCallerCheckRedirect(t *testing.T) {
+	t.Parallel()
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "https://cdn.example.com/a.zip", redirectResponder("https://cdn.example.com/b.zip"))
+
+	errStop := errors.New("stop")
+	hc := &http.Client{
+		Transport:     mt,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return errStop },
+	}
+	d := download.NewDownloader(&client.Client{})
+	_, err := download.ExportDoDownloadRequest(d, context.Background(), hc, "https://cdn.example.com/a.zip", "")
+	if !errors.Is(err, errStop) {
+		t.Fatalf("err = %v, want caller's CheckRedirect error", err)
+	}
+}
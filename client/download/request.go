@@ -7,6 +7,7 @@ import (
 )
 
 // doDownloadRequest builds and executes a GET request for downloading raw zip data.
+// Redirects follow the bundle redirect policy; see withRedirectPolicy.
 func (d *Downloader) doDownloadRequest(ctx context.Context, httpc *http.Client, urlStr, ua string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
@@ -19,7 +20,7 @@ func (d *Downloader) doDownloadRequest(ctx context.Context, httpc *http.Client,
 	req.Header.Set("Accept-Encoding", "identity")
 	req.Header.Set("Accept", "application/zip, application/octet-stream, */*")
 
-	resp, err := httpc.Do(req)
+	resp, err := withRedirectPolicy(httpc, d.client.BundleMaxRedirects).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("download request: %w", err)
 	}
@@ -0,0 +1,178 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PullOptions configures StartPeriodicPull.
+type PullOptions struct {
+	// Jitter spreads pulls by a random ±Jitter fraction of the interval
+	// (0.1 = ±10%), so replicas started together don't pull in lockstep.
+	// It must be in [0, 1); zero disables it.
+	Jitter float64
+	// Immediate runs the first pull right away instead of after the first
+	// interval.
+	Immediate bool
+	// Async uses DownloadAsync instead of Download.
+	Async bool
+	// Timeout bounds each pull; zero means only the scheduler's ctx does.
+	Timeout time.Duration
+	// OnResult, if set, is called after every pull and every skipped one,
+	// from the scheduler's goroutines. It must not block for long.
+	OnResult func(PullResult)
+}
+
+// PullResult is the outcome of one scheduled pull.
+type PullResult struct {
+	Start     time.Time
+	Duration  time.Duration
+	BundleURL string
+	Err       error
+	// Skipped is set when the pull was due while the previous one was still
+	// running; nothing was downloaded.
+	Skipped bool
+}
+
+// PeriodicPull is a running StartPeriodicPull schedule.
+type PeriodicPull struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	trigger chan struct{}
+	running atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// StartPeriodicPull downloads params into dest every interval until ctx is
+// done or Stop is called. Pulls never overlap: one that comes due while the
+// previous is still running is skipped and reported with Skipped set.
+// Failed pulls are reported and the schedule carries on.
+func (d *Downloader) StartPeriodicPull(
+	ctx context.Context,
+	interval time.Duration,
+	params DownloadParams,
+	dest string,
+	opts PullOptions,
+) (*PeriodicPull, error) {
+	if d == nil || d.client == nil {
+		return nil, errors.New(clientIsNilMsg)
+	}
+	if interval <= 0 {
+		return nil, errors.New("download: pull interval must be positive")
+	}
+	if opts.Jitter < 0 || opts.Jitter >= 1 {
+		return nil, errors.New("download: pull jitter must be in [0, 1)")
+	}
+	if strings.TrimSpace(dest) == "" {
+		return nil, errors.New("download: empty unzip destination")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &PeriodicPull{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		trigger: make(chan struct{}, 1),
+	}
+	pull := func(ctx context.Context) (string, error) {
+		if opts.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+			defer cancel()
+		}
+		if opts.Async {
+			return d.DownloadAsync(ctx, dest, params)
+		}
+		return d.Download(ctx, dest, params)
+	}
+	go p.loop(ctx, interval, opts, pull)
+	return p, nil
+}
+
+func (p *PeriodicPull) loop(
+	ctx context.Context,
+	interval time.Duration,
+	opts PullOptions,
+	pull func(context.Context) (string, error),
+) {
+	defer close(p.done)
+	defer p.wg.Wait()
+
+	first := jitterInterval(interval, opts.Jitter)
+	if opts.Immediate {
+		first = 0
+	}
+	timer := time.NewTimer(first)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			p.fire(ctx, opts.OnResult, pull)
+			timer.Reset(jitterInterval(interval, opts.Jitter))
+		case <-p.trigger:
+			p.fire(ctx, opts.OnResult, pull)
+		}
+	}
+}
+
+// fire starts a pull unless one is running.
+func (p *PeriodicPull) fire(
+	ctx context.Context,
+	report func(PullResult),
+	pull func(context.Context) (string, error),
+) {
+	if report == nil {
+		report = func(PullResult) {}
+	}
+	start := time.Now()
+	if !p.running.CompareAndSwap(false, true) {
+		report(PullResult{Start: start, Skipped: true})
+		return
+	}
+	p.wg.Go(func() {
+		defer p.running.Store(false)
+		bundleURL, err := pull(ctx)
+		report(PullResult{Start: start, Duration: time.Since(start), BundleURL: bundleURL, Err: err})
+	})
+}
+
+// Trigger asks for a pull now, outside the schedule. Like scheduled pulls,
+// it is skipped if a pull is running. It never blocks.
+func (p *PeriodicPull) Trigger() {
+	select {
+	case p.trigger <- struct{}{}:
+	default: // one is already pending
+	}
+}
+
+// Stop ends the schedule, cancels a running pull and waits for it to
+// return. It is safe to call more than once.
+func (p *PeriodicPull) Stop() {
+	p.cancel()
+	<-p.done
+}
+
+// Done is closed once the schedule has ended (ctx done or Stop) and the
+// last pull has returned.
+func (p *PeriodicPull) Done() <-chan struct{} {
+	return p.done
+}
+
+// jitterInterval returns interval moved by a random ±jitter fraction.
+func jitterInterval(interval time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	delta := (rand.Float64()*2 - 1) * jitter * float64(interval)
+	return max(interval+time.Duration(delta), time.Millisecond)
+}
@@ -0,0 +1,214 @@
+package download_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/download"
+
+	"github.com/jarcoal/httpmock"
+)
+
+const scheduleCDNURL = "https://cdn.example.com/scheduled.zip"
+
+func scheduleDownloadURL() string {
+	return "https://api.lokalise.com/api2/projects/" + projectID + "/files/download"
+}
+
+// scheduleAPI mocks a sync export; each export first runs gate, if set.
+func scheduleAPI(t *testing.T, gate func(*http.Request)) {
+	t.Helper()
+	httpmock.RegisterResponder("POST", scheduleDownloadURL(), func(req *http.Request) (*http.Response, error) {
+		if gate != nil {
+			gate(req)
+			if err := req.Context().Err(); err != nil {
+				return nil, err
+			}
+		}
+		return httpmock.NewStringResponse(200, `{"bundle_url":"`+scheduleCDNURL+`"}`), nil
+	})
+	registerZipResponder(t, scheduleCDNURL, buildZip(t, map[string]string{"en/app.json": `{"a":"b"}`}, nil))
+}
+
+// collect returns an OnResult callback feeding a channel.
+func collect() (func(download.PullResult), chan download.PullResult) {
+	ch := make(chan download.PullResult, 16)
+	return func(r download.PullResult) { ch <- r }, ch
+}
+
+func nextResult(t *testing.T, ch <-chan download.PullResult) download.PullResult {
+	t.Helper()
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("no pull result")
+		return download.PullResult{}
+	}
+}
+
+func TestStartPeriodicPull_Pulls(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	scheduleAPI(t, nil)
+
+	report, results := collect()
+	dir := t.TempDir()
+	p, err := newDeltaDownloader(t).StartPeriodicPull(context.Background(), 20*time.Millisecond,
+		download.DownloadParams{"format": "json"}, dir,
+		download.PullOptions{Immediate: true, Jitter: 0.2, OnResult: report})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	for range 2 {
+		r := nextResult(t, results)
+		if r.Err != nil || r.Skipped || r.BundleURL != scheduleCDNURL {
+			t.Fatalf("result = %+v", r)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "en", "app.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	p.Stop()
+	select {
+	case <-p.Done():
+	default:
+		t.Fatal("Done not closed after Stop")
+	}
+}
+
+func TestStartPeriodicPull_NoOverlap(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	started := make(chan struct{}, 4)
+	release := make(chan struct{})
+	scheduleAPI(t, func(*http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+
+	report, results := collect()
+	p, err := newDeltaDownloader(t).StartPeriodicPull(context.Background(), time.Hour,
+		download.DownloadParams{"format": "json"}, t.TempDir(),
+		download.PullOptions{Immediate: true, OnResult: report})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	<-started
+	p.Trigger()
+	if r := nextResult(t, results); !r.Skipped {
+		t.Fatalf("result while a pull runs = %+v, want skipped", r)
+	}
+
+	close(release)
+	if r := nextResult(t, results); r.Err != nil || r.Skipped {
+		t.Fatalf("first pull = %+v", r)
+	}
+
+	p.Trigger()
+	if r := nextResult(t, results); r.Err != nil || r.Skipped {
+		t.Fatalf("triggered pull = %+v", r)
+	}
+	if n := httpmock.GetCallCountInfo()["POST "+scheduleDownloadURL()]; n != 2 {
+		t.Fatalf("exports = %d, want 2", n)
+	}
+}
+
+func TestStartPeriodicPull_StopCancelsRunningPull(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	started := make(chan struct{}, 1)
+	scheduleAPI(t, func(req *http.Request) {
+		started <- struct{}{}
+		<-req.Context().Done()
+	})
+
+	report, results := collect()
+	p, err := newDeltaDownloader(t).StartPeriodicPull(context.Background(), time.Hour,
+		download.DownloadParams{"format": "json"}, t.TempDir(),
+		download.PullOptions{Immediate: true, OnResult: report})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-started
+	p.Stop()
+	p.Stop() // idempotent
+	if r := nextResult(t, results); !errors.Is(r.Err, context.Canceled) {
+		t.Fatalf("result = %+v, want context.Canceled", r)
+	}
+}
+
+func TestStartPeriodicPull_Timeout(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	scheduleAPI(t, func(req *http.Request) { <-req.Context().Done() })
+
+	report, results := collect()
+	p, err := newDeltaDownloader(t).StartPeriodicPull(context.Background(), time.Hour,
+		download.DownloadParams{"format": "json"}, t.TempDir(),
+		download.PullOptions{Immediate: true, Timeout: 20 * time.Millisecond, OnResult: report})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	if r := nextResult(t, results); !errors.Is(r.Err, context.DeadlineExceeded) {
+		t.Fatalf("result = %+v, want DeadlineExceeded", r)
+	}
+}
+
+func TestStartPeriodicPull_Validation(t *testing.T) {
+	t.Parallel()
+	d := newDeltaDownloader(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		dest     string
+		opts     download.PullOptions
+		want     string
+	}{
+		{"zero interval", 0, "out", download.PullOptions{}, "interval must be positive"},
+		{"jitter too big", time.Second, "out", download.PullOptions{Jitter: 1}, "jitter must be in [0, 1)"},
+		{"negative jitter", time.Second, "out", download.PullOptions{Jitter: -0.1}, "jitter must be in [0, 1)"},
+		{"empty dest", time.Second, " ", download.PullOptions{}, "empty unzip destination"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := d.StartPeriodicPull(ctx, tt.interval, nil, tt.dest, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJitterInterval(t *testing.T) {
+	t.Parallel()
+
+	if got := download.ExportJitterInterval(time.Second, 0); got != time.Second {
+		t.Fatalf("no jitter: %v", got)
+	}
+	for range 200 {
+		got := download.ExportJitterInterval(time.Second, 0.25)
+		if got < 750*time.Millisecond || got > 1250*time.Millisecond {
+			t.Fatalf("jittered interval %v outside ±25%%", got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package download_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestDownloader_StartAsync(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	targetPost := fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/files/async-download", projectID)
+	targetGet := fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/processes/xyz", projectID)
+
+	httpmock.RegisterResponder("POST", targetPost, func(req *http.Request) (*http.Response, error) {
+		var body map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		if body["format"] != "json" {
+			t.Errorf("format = %v, want json", body["format"])
+		}
+		return httpmock.NewStringResponse(200, `{"process_id":"xyz"}`), nil
+	})
+	httpmock.RegisterResponder("GET", targetGet, httpmock.NewStringResponder(200, `{
+		"process": {
+			"process_id":"xyz",
+			"status":"finished",
+			"details": {"download_url":"https://cdn.example.com/async-bundle.zip"}
+		}
+	}`))
+
+	cli, err := client.NewClient(token, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proc, err := download.NewDownloader(cli).StartAsync(context.Background(), download.DownloadParams{"format": "json"})
+	if err != nil {
+		t.Fatalf("StartAsync() error = %v", err)
+	}
+	if proc.ID != "xyz" {
+		t.Fatalf("ID = %q, want xyz", proc.ID)
+	}
+	if n := httpmock.GetCallCountInfo()["GET "+targetGet]; n != 0 {
+		t.Fatalf("GET calls after StartAsync = %d, want 0", n)
+	}
+
+	st, err := proc.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if st.DownloadURL != "https://cdn.example.com/async-bundle.zip" {
+		t.Fatalf("DownloadURL = %q", st.DownloadURL)
+	}
+}
+
+func TestDownloader_StartAsync_NilDownloader(t *testing.T) {
+	t.Parallel()
+
+	var d *download.Downloader
+	if _, err := d.StartAsync(context.Background(), nil); err == nil {
+		t.Fatal("StartAsync() error = nil, want error")
+	}
+}
@@ -0,0 +1,221 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/internal/zipx"
+)
+
+// ErrBundleNotStored is returned by BundleStore methods for unknown hashes.
+var ErrBundleNotStored = errors.New("download: bundle not in store")
+
+// BundleStore is a local content-addressable store of export bundles, keyed
+// by the SHA-256 of the zip. A bundle is downloaded once and can then be
+// extracted into any number of destinations (for example the packages of a
+// monorepo) without re-downloading. It is safe for concurrent use, including
+// by several processes sharing the directory.
+type BundleStore struct {
+	dir string
+}
+
+// NewBundleStore opens (creating if needed) a store in dir.
+func NewBundleStore(dir string) (*BundleStore, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, errors.New("download: store: empty directory")
+	}
+	if err := mkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("download: store: %w", err)
+	}
+	return &BundleStore{dir: dir}, nil
+}
+
+// Path returns the location of the bundle with the given hash, whether or
+// not it is stored.
+func (s *BundleStore) Path(hash string) string {
+	return filepath.Join(s.dir, hash+".zip")
+}
+
+// Has reports whether the bundle is stored.
+func (s *BundleStore) Has(hash string) bool {
+	if !validHash(hash) {
+		return false
+	}
+	_, err := os.Stat(s.Path(hash))
+	return err == nil
+}
+
+// Put validates the zip read from r, stores it and returns its hash. Storing
+// a bundle that is already present is a no-op.
+func (s *BundleStore) Put(r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(s.dir, ".put-*")
+	if err != nil {
+		return "", fmt.Errorf("download: store: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("download: store: %w", err)
+	}
+	if err := zipx.Validate(tmpName); err != nil {
+		return "", fmt.Errorf("download: store: validate zip: %w", err)
+	}
+	return s.commit(tmpName, hex.EncodeToString(h.Sum(nil)))
+}
+
+// commit moves the validated zip at tmpName to its content address.
+func (s *BundleStore) commit(tmpName, hash string) (string, error) {
+	if s.Has(hash) {
+		return hash, nil
+	}
+	if err := renameFile(tmpName, s.Path(hash)); err != nil {
+		return "", fmt.Errorf("download: store: %w", err)
+	}
+	return hash, nil
+}
+
+// Extract unzips the stored bundle into destDir with the same safety checks
+// as DownloadAndUnzip.
+func (s *BundleStore) Extract(hash, destDir string) error {
+	return s.extract(hash, destDir, client.LineEndingKeep)
+}
+
+func (s *BundleStore) extract(hash, destDir string, le client.LineEnding) error {
+	if !s.Has(hash) {
+		return fmt.Errorf("%w: %q", ErrBundleNotStored, hash)
+	}
+	if strings.TrimSpace(destDir) == "" {
+		return errors.New("download: store: empty dest dir")
+	}
+	if err := ensureDestDir(destDir); err != nil {
+		return err
+	}
+	return unzipDownloadedBundle(s.Path(hash), destDir, le)
+}
+
+// Remove deletes the stored bundle. Removing an unknown bundle is a no-op.
+func (s *BundleStore) Remove(hash string) error {
+	if !validHash(hash) {
+		return fmt.Errorf("download: store: invalid hash %q", hash)
+	}
+	if err := os.Remove(s.Path(hash)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("download: store: %w", err)
+	}
+	return nil
+}
+
+// FetchToStore exports params (synchronously, or via the async flow when
+// async is true), downloads the bundle into s with retry/backoff and
+// returns its hash.
+func (d *Downloader) FetchToStore(ctx context.Context, s *BundleStore, params DownloadParams, async bool) (string, error) {
+	hash, err := d.fetchToStore(ctx, s, params, async)
+	if err != nil {
+		return "", err
+	}
+	d.client.RecordPull()
+	return hash, nil
+}
+
+// fetchToStore is FetchToStore without recording the pull, for callers
+// that only count it once the bundle is extracted.
+func (d *Downloader) fetchToStore(ctx context.Context, s *BundleStore, params DownloadParams, async bool) (string, error) {
+	if d == nil || d.client == nil {
+		return "", errors.New(clientIsNilMsg)
+	}
+	if s == nil {
+		return "", errors.New("download: nil bundle store")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rdr, err := prepareBodyReader(d.client.Codec, params)
+	if err != nil {
+		return "", fmt.Errorf("download: %w", err)
+	}
+	fetch := d.FetchBundle
+	if async {
+		fetch = d.FetchBundleAsync
+	}
+	bundleURL, err := fetch(ctx, rdr)
+	if err != nil {
+		return "", err
+	}
+	bundleURL, err = validateBundleURL(strings.TrimSpace(bundleURL))
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".fetch-*")
+	if err != nil {
+		return "", fmt.Errorf("download: store: %w", err)
+	}
+	tmpName := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	// The bundle is hashed while it downloads; no second pass over it.
+	digest, err := d.downloadAndValidateZip(ctx, bundleURL, tmpName)
+	if err != nil {
+		return "", err
+	}
+	return s.commit(tmpName, digest.sha256)
+}
+
+// DownloadToMany exports params once and extracts the bundle into every
+// destination, applying d's line endings. The bundle stays in s; the hash
+// is returned so later runs can extract it again with s.Extract.
+func (d *Downloader) DownloadToMany(ctx context.Context, s *BundleStore, params DownloadParams, destDirs ...string) (string, error) {
+	if len(destDirs) == 0 {
+		return "", errors.New("download: no destinations")
+	}
+	hash, err := d.fetchToStore(ctx, s, params, false)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range destDirs {
+		if err := s.extract(hash, dir, d.lineEnding); err != nil {
+			return hash, fmt.Errorf("download: extract into %s: %w", dir, err)
+		}
+	}
+	d.client.RecordPull()
+	return hash, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
@@ -0,0 +1,143 @@
+package download_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestBundleStore_PutExtractRemove(t *testing.T) {
+	t.Parallel()
+
+	s, err := download.NewBundleStore(filepath.Join(t.TempDir(), "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zb := buildZip(t, map[string]string{"en.json": `{"a":"b"}`}, nil)
+
+	hash, err := s.Put(bytes.NewReader(zb))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if len(hash) != 64 || !s.Has(hash) {
+		t.Fatalf("hash = %q, Has = %v", hash, s.Has(hash))
+	}
+	again, err := s.Put(bytes.NewReader(zb))
+	if err != nil || again != hash {
+		t.Fatalf("second Put() = %q, %v; want same hash", again, err)
+	}
+
+	for _, dir := range []string{"pkg-a", "pkg-b"} {
+		dest := filepath.Join(t.TempDir(), dir)
+		if err := s.Extract(hash, dest); err != nil {
+			t.Fatalf("Extract(%s) error = %v", dir, err)
+		}
+		if b, err := os.ReadFile(filepath.Join(dest, "en.json")); err != nil || string(b) != `{"a":"b"}` {
+			t.Fatalf("%s/en.json = %q, %v", dir, b, err)
+		}
+	}
+
+	if err := s.Remove(hash); err != nil {
+		t.Fatal(err)
+	}
+	if s.Has(hash) {
+		t.Fatal("Has() = true after Remove")
+	}
+	if err := s.Remove(hash); err != nil {
+		t.Fatalf("second Remove() error = %v", err)
+	}
+	if err := s.Extract(hash, t.TempDir()); !errors.Is(err, download.ErrBundleNotStored) {
+		t.Fatalf("Extract(removed) error = %v, want ErrBundleNotStored", err)
+	}
+}
+
+func TestBundleStore_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := download.NewBundleStore(" "); err == nil {
+		t.Fatal("NewBundleStore(empty) error = nil")
+	}
+
+	dir := t.TempDir()
+	s, err := download.NewBundleStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Put(strings.NewReader("not a zip")); err == nil {
+		t.Fatal("Put(non-zip) error = nil")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("store left files behind: %v", entries)
+	}
+	if s.Has("../../etc/passwd") {
+		t.Fatal("Has(invalid hash) = true")
+	}
+	if err := s.Remove("nope"); err == nil {
+		t.Fatal("Remove(invalid hash) error = nil")
+	}
+}
+
+func TestDownloader_DownloadToMany(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	cdnURL := "https://cdn.example.com/many.zip"
+	var posts, gets atomic.Int32
+	httpmock.RegisterResponder("POST", fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/files/download", projectID),
+		func(*http.Request) (*http.Response, error) {
+			posts.Add(1)
+			return httpmock.NewStringResponse(200, `{"bundle_url":"`+cdnURL+`"}`), nil
+		})
+	zb := buildZip(t, map[string]string{"locales/en.json": `{}`}, nil)
+	httpmock.RegisterResponder("GET", cdnURL, func(*http.Request) (*http.Response, error) {
+		gets.Add(1)
+		return httpmock.NewBytesResponse(200, zb), nil
+	})
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl := download.NewDownloader(cli)
+	s, err := download.NewBundleStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	dests := []string{filepath.Join(root, "web"), filepath.Join(root, "mobile"), filepath.Join(root, "admin")}
+	hash, err := dl.DownloadToMany(context.Background(), s, download.DownloadParams{"format": "json"}, dests...)
+	if err != nil {
+		t.Fatalf("DownloadToMany() error = %v", err)
+	}
+	if posts.Load() != 1 || gets.Load() != 1 {
+		t.Fatalf("exports = %d, downloads = %d; want 1, 1", posts.Load(), gets.Load())
+	}
+	for _, d := range dests {
+		if _, err := os.Stat(filepath.Join(d, "locales", "en.json")); err != nil {
+			t.Fatalf("%s not populated: %v", d, err)
+		}
+	}
+	if !s.Has(hash) {
+		t.Fatal("bundle not kept in store")
+	}
+	if cli.Health().LastPull.IsZero() {
+		t.Fatal("DownloadToMany not recorded as a pull in Health")
+	}
+
+	if _, err := dl.DownloadToMany(context.Background(), s, download.DownloadParams{"format": "json"}); err == nil {
+		t.Fatal("DownloadToMany() without destinations error = nil")
+	}
+}
@@ -0,0 +1,157 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultStreamStallTimeout is the stall timeout used in streaming mode when
+// none is configured with client.WithStallTimeout.
+const defaultStreamStallTimeout = 60 * time.Second
+
+// ErrDownloadStalled is matched (via errors.Is) by a *StallError.
+var ErrDownloadStalled = errors.New("download: stalled")
+
+// StallError reports a bundle GET aborted because no bytes arrived for Idle.
+// A slow transfer that keeps delivering data never stalls, however long it
+// takes. Stalls are timeouts, so the download is retried with backoff.
+type StallError struct {
+	Idle     time.Duration // how long the transfer went without data
+	Received int64         // body bytes received before the stall
+}
+
+func (e *StallError) Error() string {
+	return fmt.Sprintf("download: stalled: no data received for %s (after %d bytes)", e.Idle, e.Received)
+}
+
+func (e *StallError) Is(target error) bool { return target == ErrDownloadStalled }
+
+func (e *StallError) Timeout() bool { return true }
+
+// headerTimeoutError reports a streaming bundle GET whose response headers
+// did not arrive in time. It is a timeout, so the download is retried.
+type headerTimeoutError struct {
+	after time.Duration
+}
+
+func (e *headerTimeoutError) Error() string {
+	return fmt.Sprintf("download: no response headers within %s", e.after)
+}
+
+func (e *headerTimeoutError) Timeout() bool { return true }
+
+// watchdog cancels a request context when its timer fires. The timer is
+// armed for the header phase first (if any) and, once reading starts,
+// re-armed on every read that returns data.
+type watchdog struct {
+	cancel   context.CancelCauseFunc
+	header   time.Duration
+	idle     time.Duration
+	received atomic.Int64
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	reading bool
+}
+
+// startWatchdog returns a context that is canceled with a
+// *headerTimeoutError if headers don't arrive within headerTimeout, or with
+// a *StallError if, after reading starts, no bytes arrive within idle.
+// A zero duration disables that phase.
+func startWatchdog(ctx context.Context, headerTimeout, idle time.Duration) (context.Context, *watchdog) {
+	wctx, cancel := context.WithCancelCause(ctx)
+	w := &watchdog{cancel: cancel, header: headerTimeout, idle: idle}
+	if headerTimeout > 0 {
+		w.mu.Lock()
+		w.timer = time.AfterFunc(headerTimeout, w.fire)
+		w.mu.Unlock()
+	}
+	return wctx, w
+}
+
+func (w *watchdog) fire() {
+	w.mu.Lock()
+	reading := w.reading
+	w.mu.Unlock()
+
+	if reading {
+		w.cancel(&StallError{Idle: w.idle, Received: w.received.Load()})
+		return
+	}
+	w.cancel(&headerTimeoutError{after: w.header})
+}
+
+// body switches to the reading phase and wraps r so each read re-arms the
+// stall timer.
+func (w *watchdog) body(r io.Reader) io.Reader {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.reading = true
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if w.idle <= 0 {
+		return r
+	}
+	w.timer = time.AfterFunc(w.idle, w.fire)
+	return &watchdogReader{r: r, w: w}
+}
+
+func (w *watchdog) stop() {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+	w.cancel(nil)
+}
+
+type watchdogReader struct {
+	r io.Reader
+	w *watchdog
+}
+
+func (r *watchdogReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.w.received.Add(int64(n))
+		r.w.mu.Lock()
+		r.w.timer.Reset(r.w.idle)
+		r.w.mu.Unlock()
+	}
+	return n, err
+}
+
+// streamingClient returns a copy of hc without the overall Timeout, which
+// would otherwise cap the whole transfer.
+func streamingClient(hc *http.Client) *http.Client {
+	if hc.Timeout == 0 {
+		return hc
+	}
+	out := *hc
+	out.Timeout = 0
+	return &out
+}
+
+// watchdogErr returns the watchdog's error in place of err when the
+// watchdog caused the failure.
+func watchdogErr(wctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch cause := context.Cause(wctx).(type) {
+	case *StallError:
+		return cause
+	case *headerTimeoutError:
+		return cause
+	}
+	return err
+}
@@ -0,0 +1,233 @@
+package download_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+// chunkedServer writes body in chunks, sleeping pause between them after
+// waiting headerDelay before the headers.
+func chunkedServer(t *testing.T, body []byte, chunks int, headerDelay, pause time.Duration) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(headerDelay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.WriteHeader(http.StatusOK)
+
+		size := (len(body) + chunks - 1) / chunks
+		for rest := body; len(rest) > 0; {
+			n := min(size, len(rest))
+			_, _ = w.Write(rest[:n])
+			w.(http.Flusher).Flush()
+			rest = rest[n:]
+			if len(rest) == 0 {
+				break
+			}
+			select {
+			case <-time.After(pause):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func streamingDownloader(t *testing.T, opts ...client.Option) *download.Downloader {
+	t.Helper()
+
+	c, err := client.NewClient("tok", "proj", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return download.NewDownloader(c)
+}
+
+func TestDownloadOnce_StreamingIgnoresClientTimeout(t *testing.T) {
+	t.Parallel()
+
+	body := buildZip(t, map[string]string{"en.json": strings.Repeat("x", 4096)}, nil)
+	srv := chunkedServer(t, body, 4, 0, 40*time.Millisecond)
+
+	d := streamingDownloader(t,
+		client.WithHTTPTimeout(60*time.Millisecond),
+		client.WithBundleStreaming(time.Second),
+	)
+	dest := filepath.Join(t.TempDir(), "bundle.zip")
+	if err := download.ExportDownloadOnce(d, context.Background(), srv.URL, dest, "ua"); err != nil {
+		t.Fatalf("DownloadOnce() error = %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Fatal("downloaded bytes differ from served bundle")
+	}
+}
+
+func TestDownloadOnce_WithoutStreamingClientTimeoutApplies(t *testing.T) {
+	t.Parallel()
+
+	body := buildZip(t, map[string]string{"en.json": strings.Repeat("x", 4096)}, nil)
+	srv := chunkedServer(t, body, 4, 0, 40*time.Millisecond)
+
+	d := streamingDownloader(t, client.WithHTTPTimeout(60*time.Millisecond))
+	dest := filepath.Join(t.TempDir(), "bundle.zip")
+	if err := download.ExportDownloadOnce(d, context.Background(), srv.URL, dest, "ua"); err == nil {
+		t.Fatal("DownloadOnce() error = nil, want client timeout")
+	}
+}
+
+func TestDownloadOnce_StreamingHeaderTimeout(t *testing.T) {
+	t.Parallel()
+
+	srv := chunkedServer(t, []byte("PK"), 1, time.Second, 0)
+
+	d := streamingDownloader(t, client.WithBundleStreaming(50*time.Millisecond))
+	err := download.ExportDownloadOnce(d, context.Background(), srv.URL, filepath.Join(t.TempDir(), "b.zip"), "ua")
+	if err == nil || !strings.Contains(err.Error(), "no response headers within 50ms") {
+		t.Fatalf("error = %v, want header timeout", err)
+	}
+	if !apierr.IsRetryable(err) {
+		t.Fatal("header timeout should be retryable")
+	}
+}
+
+func TestDownloadOnce_StreamingStall(t *testing.T) {
+	t.Parallel()
+
+	body := buildZip(t, map[string]string{"en.json": strings.Repeat("x", 4096)}, nil)
+	srv := chunkedServer(t, body, 2, 0, time.Second)
+
+	d := streamingDownloader(t,
+		client.WithBundleStreaming(time.Second),
+		client.WithStallTimeout(50*time.Millisecond),
+	)
+	dest := filepath.Join(t.TempDir(), "bundle.zip")
+	err := download.ExportDownloadOnce(d, context.Background(), srv.URL, dest, "ua")
+	if !errors.Is(err, download.ErrDownloadStalled) {
+		t.Fatalf("error = %v, want ErrDownloadStalled", err)
+	}
+	var se *download.StallError
+	if !errors.As(err, &se) || se.Idle != 50*time.Millisecond || se.Received == 0 || se.Received >= int64(len(body)) {
+		t.Fatalf("StallError = %+v, want partial byte count", se)
+	}
+	if !apierr.IsRetryable(err) {
+		t.Fatal("stall should be retryable")
+	}
+	if _, statErr := os.Stat(dest); !os.IsNotExist(statErr) {
+		t.Fatalf("partial bundle left at dest: %v", statErr)
+	}
+}
+
+func TestDownloadOnce_StallTimeoutWithoutStreaming(t *testing.T) {
+	t.Parallel()
+
+	body := buildZip(t, map[string]string{"en.json": strings.Repeat("x", 4096)}, nil)
+	srv := chunkedServer(t, body, 2, 0, time.Second)
+
+	d := streamingDownloader(t, client.WithStallTimeout(50*time.Millisecond))
+	err := download.ExportDownloadOnce(d, context.Background(), srv.URL, filepath.Join(t.TempDir(), "b.zip"), "ua")
+	if !errors.Is(err, download.ErrDownloadStalled) {
+		t.Fatalf("error = %v, want ErrDownloadStalled", err)
+	}
+}
+
+func TestDownloadOnce_SlowTransferIsNotStalled(t *testing.T) {
+	t.Parallel()
+
+	// Total transfer time (~240ms) is well above the stall timeout, but data
+	// keeps arriving more often than that.
+	body := buildZip(t, map[string]string{"en.json": strings.Repeat("x", 4096)}, nil)
+	srv := chunkedServer(t, body, 7, 0, 40*time.Millisecond)
+
+	d := streamingDownloader(t, client.WithStallTimeout(150*time.Millisecond))
+	dest := filepath.Join(t.TempDir(), "bundle.zip")
+	if err := download.ExportDownloadOnce(d, context.Background(), srv.URL, dest, "ua"); err != nil {
+		t.Fatalf("DownloadOnce() error = %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Fatal("downloaded bytes differ from served bundle")
+	}
+}
+
+func TestDownloadOnce_StallIsRetried(t *testing.T) {
+	t.Parallel()
+
+	body := buildZip(t, map[string]string{"en.json": strings.Repeat("x", 4096)}, nil)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		if calls.Add(1) == 1 {
+			_, _ = w.Write(body[:100])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithStallTimeout(50*time.Millisecond),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := download.NewDownloader(c)
+	dest := filepath.Join(t.TempDir(), "bundle.zip")
+
+	err = c.WithExpBackoff(context.Background(), "download", func(int) error {
+		return download.ExportDownloadOnce(d, context.Background(), srv.URL, dest, "ua")
+	}, nil)
+	if err != nil {
+		t.Fatalf("download with retry error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestDownloadOnce_StreamingRespectsContext(t *testing.T) {
+	t.Parallel()
+
+	body := buildZip(t, map[string]string{"en.json": strings.Repeat("x", 4096)}, nil)
+	srv := chunkedServer(t, body, 4, 0, 200*time.Millisecond)
+
+	d := streamingDownloader(t, client.WithBundleStreaming(time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := download.ExportDownloadOnce(d, ctx, srv.URL, filepath.Join(t.TempDir(), "b.zip"), "ua")
+	if err == nil {
+		t.Fatal("DownloadOnce() error = nil, want context deadline")
+	}
+	if apierr.IsRetryable(err) {
+		t.Fatalf("context deadline should not be retryable: %v", err)
+	}
+}
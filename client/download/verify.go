@@ -0,0 +1,148 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// DriftReport compares an export bundle with a local directory. Paths are
+// relative to the directory, slash-separated and sorted.
+type DriftReport struct {
+	Missing   []string // in the bundle, absent locally
+	Changed   []string // present in both with different contents
+	Extra     []string // local files the bundle doesn't have
+	Matched   int      // files identical in both
+	BundleURL string
+}
+
+// InSync reports whether every bundle file exists locally with the same
+// contents. Extra local files don't count as drift: a destination often
+// holds more than one export.
+func (r DriftReport) InSync() bool {
+	return len(r.Missing) == 0 && len(r.Changed) == 0
+}
+
+// Err summarizes the drift as an error, or returns nil if r is in sync.
+func (r DriftReport) Err() error {
+	if r.InSync() {
+		return nil
+	}
+	return fmt.Errorf("%w: %d missing, %d changed", ErrDrift, len(r.Missing), len(r.Changed))
+}
+
+// ErrDrift is matched (via errors.Is) by DriftReport.Err.
+var ErrDrift = errors.New("download: local files differ from Lokalise")
+
+// Verify is the read-only counterpart of Download and DownloadAsync: it
+// exports params, extracts the bundle into a temporary directory and
+// compares each file's SHA-256 with the same path under dir. dir is never
+// written to and may not exist (everything is then Missing). Run it on a
+// schedule to check that deployed translations still match Lokalise; it is
+// not a pull, so it doesn't update Health().LastPull.
+func (d *Downloader) Verify(ctx context.Context, dir string, params DownloadParams, async bool) (DriftReport, error) {
+	if d == nil || d.client == nil {
+		return DriftReport{}, errors.New(clientIsNilMsg)
+	}
+	if strings.TrimSpace(dir) == "" {
+		return DriftReport{}, errors.New("download: empty verify destination")
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tmpDir, cleanup, err := createDownloadTempDir()
+	if err != nil {
+		return DriftReport{}, err
+	}
+	defer cleanup()
+
+	rdr, err := prepareBodyReader(d.client.Codec, params)
+	if err != nil {
+		return DriftReport{}, fmt.Errorf("download: %w", err)
+	}
+	fetch := d.FetchBundle
+	if async {
+		fetch = d.FetchBundleAsync
+	}
+	bundleURL, err := fetch(ctx, rdr)
+	if errors.Is(err, client.ErrDryRun) {
+		return DriftReport{}, nil // the export request was recorded; see client.WithDryRun
+	}
+	if err != nil {
+		return DriftReport{}, err
+	}
+	if err := d.downloadAndUnzip(ctx, bundleURL, tmpDir); err != nil {
+		return DriftReport{}, err
+	}
+
+	rep, err := compareTrees(tmpDir, dir)
+	if err != nil {
+		return DriftReport{}, fmt.Errorf("download: verify: %w", err)
+	}
+	rep.BundleURL = bundleURL
+	return rep, nil
+}
+
+// compareTrees hashes every file under want and compares it with the file at
+// the same path under got.
+func compareTrees(want, got string) (DriftReport, error) {
+	wantFiles, err := treeHashes(want)
+	if err != nil {
+		return DriftReport{}, err
+	}
+	gotFiles, err := treeHashes(got)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return DriftReport{}, err
+	}
+
+	var rep DriftReport
+	for rel, h := range wantFiles {
+		switch local, ok := gotFiles[rel]; {
+		case !ok:
+			rep.Missing = append(rep.Missing, rel)
+		case local != h:
+			rep.Changed = append(rep.Changed, rel)
+		default:
+			rep.Matched++
+		}
+	}
+	for rel := range gotFiles {
+		if _, ok := wantFiles[rel]; !ok {
+			rep.Extra = append(rep.Extra, rel)
+		}
+	}
+	slices.Sort(rep.Missing)
+	slices.Sort(rep.Changed)
+	slices.Sort(rep.Extra)
+	return rep, nil
+}
+
+// treeHashes maps the slash-separated path of every regular file under root
+// to its SHA-256.
+func treeHashes(root string) (map[string]string, error) {
+	out := map[string]string{}
+	err := filepath.WalkDir(root, func(p string, e fs.DirEntry, err error) error {
+		if err != nil || !e.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		h, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		out[filepath.ToSlash(rel)] = h
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,166 @@
+package download_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/download"
+
+	"github.com/jarcoal/httpmock"
+)
+
+// verifyAPI mocks a sync export whose bundle holds entries.
+func verifyAPI(t *testing.T, entries map[string]string) {
+	t.Helper()
+
+	cdnURL := "https://cdn.example.com/verify.zip"
+	postURL := fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/files/download", projectID)
+	httpmock.RegisterResponder("POST", postURL,
+		httpmock.NewStringResponder(200, `{"bundle_url":"`+cdnURL+`"}`))
+	registerZipResponder(t, cdnURL, buildZip(t, entries, nil))
+}
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDownloader_Verify_InSync(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	files := map[string]string{"en/app.json": `{"a":"A"}`, "fr/app.json": `{"a":"Á"}`}
+	verifyAPI(t, files)
+
+	dir := t.TempDir()
+	writeTree(t, dir, files)
+
+	rep, err := newDeltaDownloader(t).Verify(context.Background(), dir, download.DownloadParams{"format": "json"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.InSync() || rep.Err() != nil || rep.Matched != 2 {
+		t.Fatalf("report = %+v, want 2 matched and in sync", rep)
+	}
+	if rep.BundleURL != "https://cdn.example.com/verify.zip" {
+		t.Fatalf("BundleURL = %q", rep.BundleURL)
+	}
+}
+
+func TestDownloader_Verify_ReportsDriftWithoutWriting(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	verifyAPI(t, map[string]string{
+		"en/app.json": `{"a":"A"}`,
+		"fr/app.json": `{"a":"Á"}`,
+		"de/app.json": `{"a":"Ä"}`,
+	})
+
+	dir := t.TempDir()
+	local := map[string]string{
+		"en/app.json": `{"a":"A"}`,
+		"fr/app.json": `{"a":"A (stale)"}`,
+		"README.md":   "not from Lokalise",
+	}
+	writeTree(t, dir, local)
+
+	rep, err := newDeltaDownloader(t).Verify(context.Background(), dir, download.DownloadParams{"format": "json"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := download.DriftReport{
+		Missing:   []string{"de/app.json"},
+		Changed:   []string{"fr/app.json"},
+		Extra:     []string{"README.md"},
+		Matched:   1,
+		BundleURL: "https://cdn.example.com/verify.zip",
+	}
+	if !reflect.DeepEqual(rep, want) {
+		t.Fatalf("report = %+v, want %+v", rep, want)
+	}
+	if rep.InSync() || !errors.Is(rep.Err(), download.ErrDrift) {
+		t.Fatalf("InSync = %v, Err = %v; want drift", rep.InSync(), rep.Err())
+	}
+
+	for rel, content := range local {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil || string(got) != content {
+			t.Fatalf("%s changed: %q, %v", rel, got, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "de")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Verify created de/: %v", err)
+	}
+}
+
+func TestDownloader_Verify_MissingDir(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	verifyAPI(t, map[string]string{"en.json": "{}"})
+
+	dir := filepath.Join(t.TempDir(), "absent")
+	rep, err := newDeltaDownloader(t).Verify(context.Background(), dir, download.DownloadParams{"format": "json"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rep.Missing, []string{"en.json"}) || rep.Extra != nil {
+		t.Fatalf("report = %+v, want en.json missing", rep)
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Verify created the destination: %v", err)
+	}
+}
+
+func TestDownloader_Verify_Errors(t *testing.T) {
+	t.Parallel()
+
+	var nilD *download.Downloader
+	if _, err := nilD.Verify(context.Background(), "x", nil, false); err == nil {
+		t.Fatal("nil downloader: want error")
+	}
+	if _, err := newDeltaDownloader(t).Verify(context.Background(), "  ", nil, false); err == nil {
+		t.Fatal("empty dir: want error")
+	}
+}
+
+func TestDownloader_Verify_NotAPull(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	verifyAPI(t, map[string]string{"en.json": "{}"})
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, download.DefaultManifestName)
+	pulled := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := download.WriteManifest(manifest, download.Manifest{ProjectID: projectID, Version: "v1", PulledAt: pulled}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := download.NewDownloader(cli).Verify(context.Background(), dir, download.DownloadParams{"format": "json"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := cli.Health().LastPull; !got.IsZero() {
+		t.Fatalf("LastPull = %v after Verify, want zero", got)
+	}
+	m, ok, err := download.ReadManifest(manifest)
+	if err != nil || !ok || m.Version != "v1" || !m.PulledAt.Equal(pulled) {
+		t.Fatalf("manifest = %+v (ok %v, err %v), want it unchanged", m, ok, err)
+	}
+}
@@ -0,0 +1,147 @@
+// Package yamlmerge provides a download.Merger for YAML bundles. It is kept
+// out of the download package so that only programs importing it link the
+// YAML parser (gopkg.in/yaml.v3).
+//
+//	dl.DownloadDelta(ctx, dir, params, download.DeltaOptions{
+//		Since:   lastPull,
+//		Mergers: yamlmerge.Mergers(),
+//	})
+package yamlmerge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client/download"
+	"gopkg.in/yaml.v3"
+)
+
+// Merger deep-merges YAML mappings. Keys keep their order in the local file,
+// new keys are appended, and comments and indentation are preserved.
+// Removing the last key of a mapping removes the mapping too.
+type Merger struct{}
+
+// Mergers returns Merger registered for the .yml and .yaml extensions.
+func Mergers() map[string]download.Merger {
+	return map[string]download.Merger{".yml": Merger{}, ".yaml": Merger{}}
+}
+
+// Merge implements download.Merger.
+func (Merger) Merge(existing, incoming []byte, removed []string) ([]byte, error) {
+	base, err := parseMapping(existing)
+	if err != nil {
+		return nil, fmt.Errorf("existing file: %w", err)
+	}
+	if incoming != nil {
+		patch, err := parseMapping(incoming)
+		if err != nil {
+			return nil, fmt.Errorf("incoming file: %w", err)
+		}
+		mergeMappings(base.Content[0], patch.Content[0])
+	}
+	for _, name := range removed {
+		removeKey(base.Content[0], name)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(detectIndent(existing))
+	if err := enc.Encode(base); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func parseMapping(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}, nil
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("not a YAML mapping")
+	}
+	return &doc, nil
+}
+
+// lookup returns the index of key's key node in mapping m, or -1.
+func lookup(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func mergeMappings(base, patch *yaml.Node) {
+	for i := 0; i+1 < len(patch.Content); i += 2 {
+		k, v := patch.Content[i], patch.Content[i+1]
+		j := lookup(base, k.Value)
+		switch {
+		case j < 0:
+			base.Content = append(base.Content, k, v)
+		case v.Kind == yaml.MappingNode && base.Content[j+1].Kind == yaml.MappingNode:
+			mergeMappings(base.Content[j+1], v)
+		default:
+			// Keep the local comments attached to the replaced value.
+			old := base.Content[j+1]
+			v.HeadComment, v.LineComment, v.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			base.Content[j+1] = v
+		}
+	}
+}
+
+// removeKey deletes name, stored flat or nested under any split of its
+// dot-separated segments. If the root has a single mapping member (a locale
+// root such as "en:"), name is also looked up under it.
+func removeKey(root *yaml.Node, name string) {
+	if removePath(root, name) || len(root.Content) != 2 || root.Content[1].Kind != yaml.MappingNode {
+		return
+	}
+	inner := root.Content[1]
+	removePath(inner, name)
+	if len(inner.Content) == 0 {
+		root.Content = nil
+	}
+}
+
+func removePath(m *yaml.Node, path string) bool {
+	if i := lookup(m, path); i >= 0 {
+		m.Content = append(m.Content[:i], m.Content[i+2:]...)
+		return true
+	}
+	for i := range len(path) {
+		if path[i] != '.' {
+			continue
+		}
+		j := lookup(m, path[:i])
+		if j < 0 || m.Content[j+1].Kind != yaml.MappingNode || !removePath(m.Content[j+1], path[i+1:]) {
+			continue
+		}
+		if len(m.Content[j+1].Content) == 0 {
+			m.Content = append(m.Content[:j], m.Content[j+2:]...)
+		}
+		return true
+	}
+	return false
+}
+
+// detectIndent returns the indentation width of the first indented line,
+// defaulting to 2.
+func detectIndent(data []byte) int {
+	for line := range strings.SplitSeq(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if n := len(line) - len(trimmed); n > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "- ") {
+			return n
+		}
+	}
+	return 2
+}
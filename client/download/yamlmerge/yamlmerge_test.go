@@ -0,0 +1,92 @@
+package yamlmerge_test
+
+import (
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/download/yamlmerge"
+)
+
+func TestMerger(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		existing string
+		incoming string
+		removed  []string
+		want     string
+	}{
+		{
+			name: "keeps order and comments, appends new keys",
+			existing: `en:
+    # Landing page
+    home:
+        title: Old # shown in the tab
+        subtitle: Keep
+    about: About
+`,
+			incoming: `en:
+  home:
+    title: New
+    cta: Go
+  footer: Bye
+`,
+			want: `en:
+    # Landing page
+    home:
+        title: New # shown in the tab
+        subtitle: Keep
+        cta: Go
+    about: About
+    footer: Bye
+`,
+		},
+		{
+			name:     "removes nested keys under the locale root and prunes",
+			existing: "en:\n  menu:\n    file:\n      open: Open\n    edit: Edit\n  home.title: Home\n",
+			removed:  []string{"menu.file.open", "home.title"},
+			want:     "en:\n  menu:\n    edit: Edit\n",
+		},
+		{
+			name:     "empty existing file",
+			existing: "",
+			incoming: "a: b\n",
+			want:     "a: b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var incoming []byte
+			if tt.incoming != "" {
+				incoming = []byte(tt.incoming)
+			}
+			got, err := yamlmerge.Merger{}.Merge([]byte(tt.existing), incoming, tt.removed)
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("Merge() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerger_RejectsNonMapping(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (yamlmerge.Merger{}).Merge([]byte("- a\n- b\n"), nil, nil); err == nil {
+		t.Fatal("sequence: error = nil")
+	}
+}
+
+func TestMergers(t *testing.T) {
+	t.Parallel()
+
+	m := yamlmerge.Mergers()
+	if m[".yml"] == nil || m[".yaml"] == nil {
+		t.Fatalf("Mergers() = %v", m)
+	}
+}
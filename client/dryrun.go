@@ -0,0 +1,98 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bodrovis/lokex/v2/internal/redact"
+)
+
+// ErrDryRun is returned, in dry-run mode, by calls that needed the response
+// of a request that was only recorded. Download and Upload don't return it;
+// see WithDryRun.
+var ErrDryRun = errors.New("lokex: dry run: request not sent")
+
+// DryRunRequest is a request recorded instead of sent; see WithDryRun. It
+// is redacted like diagnostics, so plans can be printed or stored.
+type DryRunRequest struct {
+	Method string
+	Path   string      // relative to BaseURL, with the query string
+	URL    string      // full URL
+	Header http.Header // with the API token redacted
+	Body   []byte      // JSON body before compression; file contents ("data") are masked
+}
+
+// DryRunRecorder receives the requests of a dry-run client. It must be safe
+// for concurrent use.
+type DryRunRecorder interface {
+	RecordRequest(DryRunRequest)
+}
+
+// DryRunLog is a DryRunRecorder keeping requests in memory. The zero value
+// is ready to use.
+type DryRunLog struct {
+	mu   sync.Mutex
+	reqs []DryRunRequest
+}
+
+// RecordRequest appends req.
+func (l *DryRunLog) RecordRequest(req DryRunRequest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reqs = append(l.reqs, req)
+}
+
+// Requests returns the recorded requests in order.
+func (l *DryRunLog) Requests() []DryRunRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.reqs)
+}
+
+// WithDryRun turns on dry-run mode, for "plan" steps in CI: requests that
+// would change anything (every method but GET and HEAD) are built and
+// validated as usual, then handed to rec instead of being sent. Read-only
+// requests still go out, so checks such as running-import detection work.
+//
+// Download and DownloadAsync record the export request and return "" without
+// downloading anything. Upload, UploadWithResult, UploadReader and
+// UploadBatch record the upload requests and return empty process IDs
+// without polling. Other calls that need a recorded request's response fail
+// with ErrDryRun. rec must be non-nil.
+func WithDryRun(rec DryRunRecorder) Option {
+	return func(c *Client) error {
+		if rec == nil {
+			return errors.New("dry run recorder cannot be nil")
+		}
+		c.dryRun = rec
+		return nil
+	}
+}
+
+// DryRun reports whether the client is in dry-run mode.
+func (c *Client) DryRun() bool {
+	return c != nil && c.dryRun != nil
+}
+
+// dryRunHook returns the transport hook recording a request of method, or
+// nil if it should be sent.
+func (c *Client) dryRunHook(method string) func(*http.Request, []byte) error {
+	if c.dryRun == nil || method == http.MethodGet || method == http.MethodHead {
+		return nil
+	}
+	return func(req *http.Request, body []byte) error {
+		rd := redact.New(c.Token)
+		path := strings.TrimPrefix(req.URL.String(), strings.TrimSuffix(c.BaseURL, "/")+"/")
+		c.dryRun.RecordRequest(DryRunRequest{
+			Method: req.Method,
+			Path:   path,
+			URL:    req.URL.String(),
+			Header: rd.Header(req.Header),
+			Body:   []byte(rd.Body(string(body))),
+		})
+		return ErrDryRun
+	}
+}
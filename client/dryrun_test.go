@@ -0,0 +1,82 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestWithDryRun(t *testing.T) {
+	t.Parallel()
+
+	var sent atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		if r.Method != http.MethodGet {
+			t.Errorf("%s request reached the server in dry-run mode", r.Method)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var log client.DryRunLog
+	c, err := client.NewClient("secret-token", "p", client.WithBaseURL(srv.URL), client.WithDryRun(&log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.DryRun() {
+		t.Fatal("DryRun() = false")
+	}
+	ctx := context.Background()
+
+	err = c.Do(ctx, http.MethodPost, "projects/{project_id}/files/upload?x=1", map[string]any{"filename": "en.json", "data": "AAAA"}, nil)
+	if !errors.Is(err, client.ErrDryRun) {
+		t.Fatalf("POST error = %v, want ErrDryRun", err)
+	}
+	if err := c.Do(ctx, http.MethodGet, "projects", nil, nil); err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	if sent.Load() != 1 {
+		t.Fatalf("requests sent = %d, want 1 (the GET)", sent.Load())
+	}
+
+	reqs := log.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("recorded = %d, want 1", len(reqs))
+	}
+	r := reqs[0]
+	if r.Method != http.MethodPost || r.Path != "projects/p/files/upload?x=1" || r.URL != srv.URL+"/projects/p/files/upload?x=1" {
+		t.Fatalf("recorded %s %q (%s)", r.Method, r.Path, r.URL)
+	}
+	if got := r.Header.Get("X-Api-Token"); got == "secret-token" || got == "" {
+		t.Fatalf("X-Api-Token = %q, want redacted", got)
+	}
+	if r.Header.Get(client.HeaderIdempotencyKey) == "" || r.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("header = %v", r.Header)
+	}
+	body := string(r.Body)
+	if !strings.Contains(body, `"filename":"en.json"`) || strings.Contains(body, "AAAA") {
+		t.Fatalf("body = %s, want filename kept and data masked", body)
+	}
+}
+
+func TestWithDryRun_Nil(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("token", "p", client.WithDryRun(nil)); err == nil {
+		t.Fatal("WithDryRun(nil) error = nil")
+	}
+	c, err := client.NewClient("token", "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DryRun() {
+		t.Fatal("DryRun() = true without WithDryRun")
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// Environment variables read by NewClientFromEnv.
+const (
+	EnvAPIToken      = "LOKALISE_API_TOKEN"      // required
+	EnvProjectID     = "LOKALISE_PROJECT_ID"     // required
+	EnvAPIHost       = "LOKALISE_API_HOST"       // host ("api.lokalise.com") or base URL
+	EnvHTTPTimeout   = "LOKALISE_HTTP_TIMEOUT"   // duration, e.g. "45s"; see WithHTTPTimeout
+	EnvBundleTimeout = "LOKALISE_BUNDLE_TIMEOUT" // duration; see WithBundleTimeout
+	EnvMaxRetries    = "LOKALISE_MAX_RETRIES"    // integer; see WithMaxRetries
+)
+
+// ErrMissingEnv is matched (via errors.Is) by the error NewClientFromEnv
+// returns when a required variable is unset.
+var ErrMissingEnv = errors.New("missing environment variable")
+
+// NewClientFromEnv builds a Client from LOKALISE_API_TOKEN and
+// LOKALISE_PROJECT_ID, plus the optional LOKALISE_API_HOST,
+// LOKALISE_HTTP_TIMEOUT, LOKALISE_BUNDLE_TIMEOUT and LOKALISE_MAX_RETRIES.
+// opts are applied after the settings from the environment, so they win.
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	token := utils.GetEnv(EnvAPIToken, "")
+	projectID := utils.GetEnv(EnvProjectID, "")
+
+	var missing []string
+	if token == "" {
+		missing = append(missing, EnvAPIToken)
+	}
+	if projectID == "" {
+		missing = append(missing, EnvProjectID)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingEnv, strings.Join(missing, ", "))
+	}
+
+	envOpts, err := optionsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(token, projectID, append(envOpts, opts...)...)
+}
+
+// optionsFromEnv turns the optional variables into options.
+func optionsFromEnv() ([]Option, error) {
+	var opts []Option
+
+	if host := utils.GetEnv(EnvAPIHost, ""); host != "" {
+		opts = append(opts, withEnvName(EnvAPIHost, WithBaseURL(apiHostURL(host))))
+	}
+	for _, v := range []struct {
+		name string
+		opt  func(time.Duration) Option
+	}{
+		{EnvHTTPTimeout, WithHTTPTimeout},
+		{EnvBundleTimeout, WithBundleTimeout},
+	} {
+		raw := utils.GetEnv(v.name, "")
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid duration %q (want e.g. \"30s\")", v.name, raw)
+		}
+		opts = append(opts, withEnvName(v.name, v.opt(d)))
+	}
+	if raw := utils.GetEnv(EnvMaxRetries, ""); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid integer %q", EnvMaxRetries, raw)
+		}
+		opts = append(opts, withEnvName(EnvMaxRetries, WithMaxRetries(n)))
+	}
+	return opts, nil
+}
+
+// apiHostURL expands a bare host into the API base URL; full URLs are kept.
+func apiHostURL(host string) string {
+	if strings.Contains(host, "://") {
+		return host
+	}
+	return "https://" + strings.TrimSuffix(host, "/") + "/api2/"
+}
+
+// withEnvName prefixes opt's error with the variable it came from.
+func withEnvName(name string, opt Option) Option {
+	return func(c *Client) error {
+		if err := opt(c); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package client_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// setEnv sets every client env variable, unsetting those not in vars.
+func setEnv(t *testing.T, vars map[string]string) {
+	t.Helper()
+	for _, k := range []string{
+		client.EnvAPIToken, client.EnvProjectID, client.EnvAPIHost,
+		client.EnvHTTPTimeout, client.EnvBundleTimeout, client.EnvMaxRetries,
+	} {
+		t.Setenv(k, vars[k])
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	setEnv(t, map[string]string{
+		client.EnvAPIToken:      " tok ",
+		client.EnvProjectID:     "123.abc",
+		client.EnvAPIHost:       "api.eu.example.com",
+		client.EnvHTTPTimeout:   "45s",
+		client.EnvBundleTimeout: "2m",
+		client.EnvMaxRetries:    "5",
+	})
+
+	c, err := client.NewClientFromEnv(client.WithMaxRetries(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "tok" || c.ProjectID != "123.abc" {
+		t.Fatalf("Token=%q ProjectID=%q", c.Token, c.ProjectID)
+	}
+	if c.BaseURL != "https://api.eu.example.com/api2/" {
+		t.Fatalf("BaseURL = %q", c.BaseURL)
+	}
+	if c.HTTPClient.Timeout != 45*time.Second || c.BundleClient.Timeout != 2*time.Minute {
+		t.Fatalf("timeouts = %v / %v", c.HTTPClient.Timeout, c.BundleClient.Timeout)
+	}
+	if c.MaxRetries != 1 {
+		t.Fatalf("MaxRetries = %d, want the explicit option to win", c.MaxRetries)
+	}
+}
+
+func TestNewClientFromEnv_Defaults(t *testing.T) {
+	setEnv(t, map[string]string{
+		client.EnvAPIToken:  "tok",
+		client.EnvProjectID: "123.abc",
+		client.EnvAPIHost:   "http://localhost:8080/mock",
+	})
+
+	c, err := client.NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BaseURL != "http://localhost:8080/mock/" {
+		t.Fatalf("BaseURL = %q", c.BaseURL)
+	}
+	if c.HTTPClient.Timeout != 30*time.Second || c.MaxRetries != 3 {
+		t.Fatalf("timeout=%v retries=%d, want defaults", c.HTTPClient.Timeout, c.MaxRetries)
+	}
+}
+
+func TestNewClientFromEnv_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		want string
+	}{
+		{"nothing set", nil, "LOKALISE_API_TOKEN, LOKALISE_PROJECT_ID"},
+		{"blank project", map[string]string{client.EnvAPIToken: "tok", client.EnvProjectID: "  "}, "missing environment variable: LOKALISE_PROJECT_ID"},
+		{"bad timeout", map[string]string{client.EnvAPIToken: "tok", client.EnvProjectID: "p", client.EnvHTTPTimeout: "30"}, `LOKALISE_HTTP_TIMEOUT: invalid duration "30"`},
+		{"negative timeout", map[string]string{client.EnvAPIToken: "tok", client.EnvProjectID: "p", client.EnvBundleTimeout: "-1s"}, "LOKALISE_BUNDLE_TIMEOUT: bundle timeout cannot be negative"},
+		{"bad retries", map[string]string{client.EnvAPIToken: "tok", client.EnvProjectID: "p", client.EnvMaxRetries: "many"}, `LOKALISE_MAX_RETRIES: invalid integer "many"`},
+		{"bad host", map[string]string{client.EnvAPIToken: "tok", client.EnvProjectID: "p", client.EnvAPIHost: "://"}, "LOKALISE_API_HOST: invalid base URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnv(t, tt.vars)
+			_, err := client.NewClientFromEnv()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	setEnv(t, nil)
+	if _, err := client.NewClientFromEnv(); !errors.Is(err, client.ErrMissingEnv) {
+		t.Fatalf("err = %v, want ErrMissingEnv", err)
+	}
+}
@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate limit response headers sent by the Lokalise API. The
+// "X-RateLimit-" spelling used by some proxies is accepted too.
+const (
+	HeaderRateLimitLimit     = "X-Rate-Limit-Limit"
+	HeaderRateLimitRemaining = "X-Rate-Limit-Remaining"
+	HeaderRateLimitReset     = "X-Rate-Limit-Reset"
+)
+
+// Health is a point-in-time view of a client's recent activity, meant for
+// /healthz and readiness handlers of services embedding lokex. Clients
+// derived with ForProject share it.
+type Health struct {
+	LastPull    time.Time `json:"last_pull,omitzero"`    // last bundle downloaded
+	LastPush    time.Time `json:"last_push,omitzero"`    // last upload that went through
+	LastSuccess time.Time `json:"last_success,omitzero"` // last successful API request
+	LastFailure time.Time `json:"last_failure,omitzero"` // last failed API request (see ConsecutiveFailures)
+	LastError   string    `json:"last_error,omitempty"`  // error of LastFailure
+
+	// ConsecutiveFailures counts failed API requests since the last
+	// success. Only failures that say something about reaching Lokalise
+	// count: no response, 5xx, 401, 403 and 429.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+
+	// Circuit is the WithCircuitBreaker state: "closed", "open" or
+	// "half-open" (the next request probes the API); "" when no breaker is
+	// configured.
+	Circuit string `json:"circuit,omitempty"`
+
+	RateLimit RateLimitHeadroom `json:"rate_limit"`
+
+	// InFlight is the number of requests holding a WithMaxConcurrency
+	// slot; 0 without a cap.
+	InFlight int `json:"in_flight,omitempty"`
+}
+
+// RateLimitHeadroom is how much request budget is left.
+type RateLimitHeadroom struct {
+	// Limit, Remaining and Reset come from the X-Rate-Limit-* headers of
+	// the last response that had them; Known reports whether one did.
+	Known     bool      `json:"known"`
+	Limit     int       `json:"limit,omitempty"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset,omitzero"`
+
+	// Local is the number of requests the WithRateLimit bucket would let
+	// through right now (negative while requests queue); 0 without one.
+	Local float64 `json:"local"`
+}
+
+// HealthCheck sets the thresholds of Health.Check. Zero fields are not
+// checked.
+type HealthCheck struct {
+	MaxConsecutiveFailures int           // unhealthy once failures reach this
+	MaxPullAge             time.Duration // unhealthy if no pull succeeded this recently
+	MaxPushAge             time.Duration // unhealthy if no push succeeded this recently
+}
+
+// ErrUnhealthy is matched (via errors.Is) by errors from Health.Check.
+var ErrUnhealthy = errors.New("lokex: unhealthy")
+
+// Check returns nil if h passes hc, or an ErrUnhealthy error listing every
+// threshold it exceeds. A pull or push that never happened fails its age
+// check.
+func (h Health) Check(hc HealthCheck) error {
+	now := time.Now()
+	var reasons []string
+	if hc.MaxConsecutiveFailures > 0 && h.ConsecutiveFailures >= hc.MaxConsecutiveFailures {
+		reasons = append(reasons, fmt.Sprintf("%d consecutive failures (last: %s)", h.ConsecutiveFailures, h.LastError))
+	}
+	age := func(what string, last time.Time, limit time.Duration) {
+		switch {
+		case limit <= 0:
+		case last.IsZero():
+			reasons = append(reasons, "no successful "+what+" yet")
+		case now.Sub(last) > limit:
+			reasons = append(reasons, fmt.Sprintf("last %s %s ago", what, now.Sub(last).Round(time.Second)))
+		}
+	}
+	age("pull", h.LastPull, hc.MaxPullAge)
+	age("push", h.LastPush, hc.MaxPushAge)
+
+	if len(reasons) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnhealthy, strings.Join(reasons, "; "))
+}
+
+// Health returns the client's current Health.
+func (c *Client) Health() Health {
+	if c == nil {
+		return Health{}
+	}
+	h := c.health.snapshot()
+	h.RateLimit.Local = c.limiter.Headroom()
+	h.InFlight = c.concurrency.InFlight()
+	if c.breaker != nil {
+		h.Circuit = c.breaker.State().String()
+	}
+	return h
+}
+
+// HealthHandler returns an http.Handler for /healthz-style probes. It
+// responds with the Health as JSON: 200 if it passes hc, 503 otherwise, with
+// the reason in an "error" field.
+func (c *Client) HealthHandler(hc HealthCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		h := c.Health()
+		resp := struct {
+			Health
+			Error string `json:"error,omitempty"`
+		}{Health: h}
+
+		status := http.StatusOK
+		if err := h.Check(hc); err != nil {
+			status = http.StatusServiceUnavailable
+			resp.Error = err.Error()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// RecordPull marks a successful pull (bundle download) in Health.
+// Downloaders call it; code built on lokex may too.
+func (c *Client) RecordPull() {
+	if c != nil {
+		c.health.mark(func(h *Health, now time.Time) { h.LastPull = now })
+	}
+}
+
+// RecordPush marks a successful push (upload) in Health. Uploaders call it;
+// code built on lokex may too.
+func (c *Client) RecordPush() {
+	if c != nil {
+		c.health.mark(func(h *Health, now time.Time) { h.LastPush = now })
+	}
+}
+
+// healthState is the mutable Health behind a client. A nil *healthState
+// records nothing.
+type healthState struct {
+	mu sync.Mutex
+	h  Health
+}
+
+func (s *healthState) snapshot() Health {
+	if s == nil {
+		return Health{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.h
+}
+
+func (s *healthState) mark(fn func(h *Health, now time.Time)) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.h, time.Now())
+}
+
+// observe records one API request; it is the transport's OnResult hook.
+func (s *healthState) observe(status int, header http.Header, err error) {
+	s.mark(func(h *Health, now time.Time) {
+		if rl, ok := parseRateLimit(header); ok {
+			h.RateLimit.Known = true
+			h.RateLimit.Limit = rl.Limit
+			h.RateLimit.Remaining = rl.Remaining
+			h.RateLimit.Reset = rl.Reset
+		}
+		switch {
+		case err == nil:
+			h.LastSuccess = now
+			h.ConsecutiveFailures = 0
+		case errors.Is(err, context.Canceled):
+			// The caller gave up; that says nothing about Lokalise.
+		case isHealthFailure(status):
+			h.LastFailure = now
+			h.LastError = err.Error()
+			h.ConsecutiveFailures++
+		}
+	})
+}
+
+// isHealthFailure reports whether a failed request with status (0 for no
+// response) suggests Lokalise can't be reached or used, rather than a
+// problem with that one request.
+func isHealthFailure(status int) bool {
+	switch {
+	case status == 0, status >= 500:
+		return true
+	case status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		return true
+	}
+	return false
+}
+
+func parseRateLimit(h http.Header) (RateLimitHeadroom, bool) {
+	get := func(name string) string {
+		if v := strings.TrimSpace(h.Get(name)); v != "" {
+			return v
+		}
+		return strings.TrimSpace(h.Get(strings.Replace(name, "Rate-Limit", "RateLimit", 1)))
+	}
+	rem := get(HeaderRateLimitRemaining)
+	if rem == "" {
+		return RateLimitHeadroom{}, false
+	}
+	var rl RateLimitHeadroom
+	var err error
+	if rl.Remaining, err = strconv.Atoi(rem); err != nil {
+		return RateLimitHeadroom{}, false
+	}
+	rl.Limit, _ = strconv.Atoi(get(HeaderRateLimitLimit))
+	if sec, err := strconv.ParseInt(get(HeaderRateLimitReset), 10, 64); err == nil && sec > 0 {
+		rl.Reset = time.Unix(sec, 0)
+	}
+	return rl, true
+}
@@ -0,0 +1,177 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// newHealthClient returns a client whose API answers each request with the
+// next status from statuses (200 once they run out).
+func newHealthClient(t *testing.T, statuses ...int) *client.Client {
+	t.Helper()
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(n.Add(1)) - 1
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(client.HeaderRateLimitLimit, "6")
+		w.Header().Set(client.HeaderRateLimitRemaining, "4")
+		w.Header().Set("X-RateLimit-Reset", "1760000000") // alternative spelling
+		if i < len(statuses) && statuses[i] != http.StatusOK {
+			w.WriteHeader(statuses[i])
+			_, _ = w.Write([]byte(`{"error":{"code":1,"message":"nope"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient("tok", "p1", client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+func TestHealth_ConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	cli := newHealthClient(t, 500, 404, 429, 200, 503)
+	call := func() { _ = cli.Do(context.Background(), http.MethodGet, "projects/{project_id}", nil, nil) }
+
+	call() // 500
+	call() // 404: a problem with the request, not with reaching Lokalise
+	call() // 429
+	h := cli.Health()
+	if h.ConsecutiveFailures != 2 {
+		t.Fatalf("ConsecutiveFailures = %d, want 2", h.ConsecutiveFailures)
+	}
+	if h.LastFailure.IsZero() || h.LastError != "nope" {
+		t.Fatalf("LastFailure = %v, LastError = %q", h.LastFailure, h.LastError)
+	}
+	if !h.LastSuccess.IsZero() {
+		t.Fatalf("LastSuccess = %v before any success", h.LastSuccess)
+	}
+
+	call() // 200
+	h = cli.Health()
+	if h.ConsecutiveFailures != 0 || h.LastSuccess.IsZero() {
+		t.Fatalf("after success: %+v", h)
+	}
+	want := client.RateLimitHeadroom{Known: true, Limit: 6, Remaining: 4, Reset: time.Unix(1760000000, 0)}
+	if h.RateLimit != want {
+		t.Fatalf("RateLimit = %+v, want %+v", h.RateLimit, want)
+	}
+
+	call() // 503
+	if got := cli.ForProject("p2").Health().ConsecutiveFailures; got != 1 {
+		t.Fatalf("ForProject copy sees %d failures, want the shared 1", got)
+	}
+}
+
+func TestHealth_CanceledNotCounted(t *testing.T) {
+	t.Parallel()
+
+	cli := newHealthClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = cli.Do(ctx, http.MethodGet, "projects/{project_id}", nil, nil)
+	if h := cli.Health(); h.ConsecutiveFailures != 0 {
+		t.Fatalf("canceled request counted: %+v", h)
+	}
+}
+
+func TestHealth_Check(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tests := []struct {
+		name   string
+		h      client.Health
+		hc     client.HealthCheck
+		reason string // "" means healthy
+	}{
+		{"no thresholds", client.Health{ConsecutiveFailures: 10}, client.HealthCheck{}, ""},
+		{"below failures", client.Health{ConsecutiveFailures: 2}, client.HealthCheck{MaxConsecutiveFailures: 3}, ""},
+		{"failures reached", client.Health{ConsecutiveFailures: 3, LastError: "boom"}, client.HealthCheck{MaxConsecutiveFailures: 3}, "3 consecutive failures (last: boom)"},
+		{"fresh pull", client.Health{LastPull: now}, client.HealthCheck{MaxPullAge: time.Hour}, ""},
+		{"stale pull", client.Health{LastPull: now.Add(-2 * time.Hour)}, client.HealthCheck{MaxPullAge: time.Hour}, "last pull 2h0m"},
+		{"never pushed", client.Health{LastPull: now}, client.HealthCheck{MaxPushAge: time.Hour}, "no successful push yet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.h.Check(tt.hc)
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, client.ErrUnhealthy) || !strings.Contains(err.Error(), tt.reason) {
+				t.Fatalf("Check() = %v, want ErrUnhealthy with %q", err, tt.reason)
+			}
+		})
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	t.Parallel()
+
+	cli := newHealthClient(t)
+	hc := client.HealthCheck{MaxPullAge: time.Hour}
+
+	get := func() (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		cli.HealthHandler(hc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("body %q: %v", rec.Body.String(), err)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("Content-Type = %q", ct)
+		}
+		return rec.Code, body
+	}
+
+	code, body := get()
+	if code != http.StatusServiceUnavailable || !strings.Contains(body["error"].(string), "no successful pull yet") {
+		t.Fatalf("before pull: %d %v", code, body)
+	}
+
+	cli.RecordPull()
+	code, body = get()
+	if code != http.StatusOK || body["error"] != nil || body["last_pull"] == nil {
+		t.Fatalf("after pull: %d %v", code, body)
+	}
+	if _, ok := body["last_push"]; ok {
+		t.Fatalf("zero last_push serialized: %v", body)
+	}
+}
+
+func TestHealth_LocalRateLimit(t *testing.T) {
+	t.Parallel()
+
+	cli, err := client.NewClient("tok", "p1", client.WithRateLimit(1, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cli.Health().RateLimit.Local; got < 4.99 {
+		t.Fatalf("Local = %v, want a full bucket of 5", got)
+	}
+
+	var nilClient *client.Client
+	if h := nilClient.Health(); h != (client.Health{}) {
+		t.Fatalf("nil client Health() = %+v", h)
+	}
+	nilClient.RecordPull()
+	nilClient.RecordPush()
+}
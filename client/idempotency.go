@@ -0,0 +1,71 @@
+package client
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HeaderIdempotencyKey carries the idempotency key of a POST request. Every
+// attempt of one call sends the same key, so a server that honors it can
+// tell a retry from a new operation.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// WithIdempotencyKey derives the call's idempotency keys from key instead
+// of generating them. Each POST of the call sends key, a colon and a digest
+// of its method, path and body, so the requests of a multi-request call (an
+// UploadBatch, a chunked key create, a branch sync) get distinct keys while
+// retries and re-runs of the same request send the same one. Reuse a key to
+// mark calls as the same logical operation, e.g. when a CI job is re-run
+// after a crash. An empty key keeps the generated ones.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(c *Client) {
+		c.idempotencyKey = strings.TrimSpace(key)
+	}
+}
+
+// WithUnsafeRetryPosts sets whether failed POST requests are retried
+// (default true). A POST that failed with a 5xx or a dropped connection may
+// still have been processed, so retrying it can start a second upload or
+// export. Pass false to try every POST once and handle failures yourself.
+// Idempotency keys are sent either way.
+func WithUnsafeRetryPosts(on bool) Option {
+	return func(c *Client) error {
+		c.UnsafeRetryPosts = on
+		return nil
+	}
+}
+
+// withIdempotencyKey returns header with an idempotency key for a POST,
+// unless the call set the header itself: one derived from the call's
+// WithIdempotencyKey, or a generated one. header is not modified.
+func (c *Client) withIdempotencyKey(method, path string, body io.Reader, header http.Header) http.Header {
+	if method != http.MethodPost || header.Get(HeaderIdempotencyKey) != "" {
+		return header
+	}
+	key := rand.Text()
+	if c.idempotencyKey != "" {
+		key = c.idempotencyKey + ":" + requestDigest(method, path, body)
+	}
+	h := header.Clone()
+	if h == nil {
+		h = make(http.Header)
+	}
+	h.Set(HeaderIdempotencyKey, key)
+	return h
+}
+
+// requestDigest identifies a request by its method, path and body. A body
+// that can't be re-read gets a random digest: a fresh key is safer than
+// sharing one with a different request.
+func requestDigest(method, path string, body io.Reader) string {
+	bodyHash := hashBody(body)
+	if body != nil && bodyHash == "" {
+		return rand.Text()
+	}
+	sum := sha256.Sum256([]byte(method + " " + path + "\n" + bodyHash))
+	return hex.EncodeToString(sum[:8])
+}
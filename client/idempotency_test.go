@@ -0,0 +1,167 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// flakyServer fails the first fails requests with 503 and records the
+// Idempotency-Key of every request.
+func flakyServer(t *testing.T, fails int) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(client.HeaderIdempotencyKey))
+		n := len(keys)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if n <= fails {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"code":503,"message":"busy"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func TestIdempotencyKey_SameAcrossRetries(t *testing.T) {
+	t.Parallel()
+
+	srv, keys := flakyServer(t, 2)
+	c, err := client.NewClient("token", "p", client.WithBaseURL(srv.URL), client.WithBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := c.Do(ctx, http.MethodPost, "projects/{project_id}/files/upload", map[string]any{"a": 1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Do(ctx, http.MethodPost, "projects/{project_id}/files/upload", map[string]any{"a": 1}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := keys()
+	if len(got) != 4 {
+		t.Fatalf("requests = %d, want 4 (3 attempts + 1)", len(got))
+	}
+	if got[0] == "" || got[0] != got[1] || got[1] != got[2] {
+		t.Fatalf("keys of one call = %q, want one non-empty key", got[:3])
+	}
+	if got[3] == "" || got[3] == got[0] {
+		t.Fatalf("second call key = %q, want a new one", got[3])
+	}
+}
+
+func TestIdempotencyKey_OnlyPost(t *testing.T) {
+	t.Parallel()
+
+	srv, keys := flakyServer(t, 0)
+	c, err := client.NewClient("token", "p", client.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Do(context.Background(), http.MethodGet, "projects", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := keys(); got[0] != "" {
+		t.Fatalf("GET sent idempotency key %q", got[0])
+	}
+}
+
+func TestWithIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	srv, keys := flakyServer(t, 1)
+	c, err := client.NewClient("token", "p", client.WithBaseURL(srv.URL), client.WithBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	post := func(body any) {
+		t.Helper()
+		if err := c.Do(ctx, http.MethodPost, "projects", body, nil, client.WithIdempotencyKey("job-42")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	post(map[string]any{"name": "a"})
+	post(map[string]any{"name": "a"}) // a re-run
+	post(map[string]any{"name": "b"})
+
+	got := keys()
+	if len(got) != 4 {
+		t.Fatalf("requests = %d, want 4 (2 attempts + 2)", len(got))
+	}
+	if !strings.HasPrefix(got[0], "job-42:") || got[0] != got[1] {
+		t.Fatalf("keys of one call = %q, want one key derived from job-42", got[:2])
+	}
+	if got[2] != got[0] {
+		t.Fatalf("re-run key = %q, want %q", got[2], got[0])
+	}
+	if !strings.HasPrefix(got[3], "job-42:") || got[3] == got[0] {
+		t.Fatalf("other request key = %q, want a different key derived from job-42", got[3])
+	}
+}
+
+func TestWithUnsafeRetryPosts(t *testing.T) {
+	t.Parallel()
+
+	srv, keys := flakyServer(t, 1)
+	c, err := client.NewClient("token", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+		client.WithUnsafeRetryPosts(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.UnsafeRetryPosts {
+		t.Fatal("UnsafeRetryPosts = true")
+	}
+
+	ctx := context.Background()
+	if err := c.Do(ctx, http.MethodPost, "projects", nil, nil); err == nil {
+		t.Fatal("POST error = nil, want the 503")
+	}
+	if n := len(keys()); n != 1 {
+		t.Fatalf("POST attempts = %d, want 1", n)
+	}
+
+	// Other methods still retry.
+	srv2, keys2 := flakyServer(t, 1)
+	c.BaseURL = srv2.URL + "/"
+	if err := c.Do(ctx, http.MethodGet, "projects", nil, nil); err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	if n := len(keys2()); n != 2 {
+		t.Fatalf("GET attempts = %d, want 2", n)
+	}
+}
+
+func TestNewClient_UnsafeRetryPostsDefault(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("token", "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.UnsafeRetryPosts {
+		t.Fatal("UnsafeRetryPosts = false by default")
+	}
+}
@@ -3,8 +3,6 @@ package background
 import (
 	"context"
 	"time"
-
-	"github.com/bodrovis/lokex/v2/client"
 )
 
 func ExportBuildResults(ordered []string, processMap map[string]QueuedProcess) []QueuedProcess {
@@ -16,8 +14,8 @@ func ExportApplyRound(
 	pending map[string]struct{},
 	procs []QueuedProcess,
 	errs map[string]error,
-) {
-	applyRound(processMap, pending, procs, errs)
+) error {
+	return applyRound(processMap, pending, procs, errs)
 }
 
 func ExportNewStoppedTimer() *time.Timer {
@@ -34,14 +32,16 @@ func ExportSleepBetweenPollRounds(
 	timer *time.Timer,
 	sleep time.Duration,
 ) (bool, error) {
-	return sleepBetweenPollRounds(ctx, pollCtx, timer, sleep)
+	return sleepBetweenPollRounds(ctx, pollCtx, timer, sleep, nil)
 }
 
 func ExportSetPollRoundForTest(
-	fn func(context.Context, *client.Client, map[string]struct{}, int) ([]QueuedProcess, map[string]error),
+	fn func(context.Context, map[string]struct{}, int) ([]QueuedProcess, map[string]error),
 ) func() {
 	prev := pollRoundFn
-	pollRoundFn = fn
+	pollRoundFn = func(ctx context.Context, _ *pollRequests, pending map[string]struct{}, n int) ([]QueuedProcess, map[string]error) {
+		return fn(ctx, pending, n)
+	}
 	return func() {
 		pollRoundFn = prev
 	}
@@ -78,3 +78,11 @@ func ExportSetNextSleepWaitForTest(
 func ExportNextPollWait(wait time.Duration, deadline time.Time) time.Duration {
 	return nextPollWait(wait, deadline)
 }
+
+func ExportPollRequestsDo(src Source, ids []string, ctx context.Context, id string, v any) error {
+	set := make(map[string]struct{}, len(ids))
+	for _, x := range ids {
+		set[x] = struct{}{}
+	}
+	return newPollRequests(src.PollConfig(), set).do(ctx, id, v)
+}
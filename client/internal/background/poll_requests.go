@@ -0,0 +1,51 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/bodrovis/lokex/v2/client/internal/ratelimit"
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// pollRequests caches one prepared GET per unique process ID, so long-running
+// polls reuse the same URL and headers instead of rebuilding them every round.
+// It is built before polling starts and only read afterwards (no locking).
+type pollRequests struct {
+	reqr transport.Requester
+	byID map[string]preparedPoll
+}
+
+type preparedPoll struct {
+	req *transport.PreparedRequest
+	err error
+}
+
+func newPollRequests(cfg Config, ids map[string]struct{}) *pollRequests {
+	pr := &pollRequests{
+		reqr: cfg.Requester,
+		byID: make(map[string]preparedPoll, len(ids)),
+	}
+	// Polls yield to user-initiated calls on a shared rate limiter.
+	pr.reqr.Priority = ratelimit.Low
+	for id := range ids {
+		path := utils.ProjectPath(cfg.ProjectID, fmt.Sprintf("processes/%s", id))
+		req, err := pr.reqr.PrepareJSON(http.MethodGet, path)
+		pr.byID[id] = preparedPoll{req: req, err: err}
+	}
+	return pr
+}
+
+// do fetches the current state of process id into v.
+func (pr *pollRequests) do(ctx context.Context, id string, v any) error {
+	p, ok := pr.byID[id]
+	if !ok {
+		return fmt.Errorf("poll: no prepared request for process %s", id)
+	}
+	if p.err != nil {
+		return p.err
+	}
+	return pr.reqr.DoPrepared(ctx, p.req, v)
+}
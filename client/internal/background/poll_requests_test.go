@@ -0,0 +1,34 @@
+package background_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+)
+
+func TestPollRequests_Do(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unknown id", func(t *testing.T) {
+		t.Parallel()
+
+		c, _ := client.NewClient("tok", "proj")
+		err := background.ExportPollRequestsDo(c, []string{"p1"}, context.Background(), "p2", nil)
+		if err == nil || err.Error() != "poll: no prepared request for process p2" {
+			t.Fatalf("error = %v, want missing prepared request error", err)
+		}
+	})
+
+	t.Run("prepare error is returned on use", func(t *testing.T) {
+		t.Parallel()
+
+		c := &client.Client{BaseURL: "http://[::1", ProjectID: "proj"}
+		err := background.ExportPollRequestsDo(c, []string{"p1"}, context.Background(), "p1", nil)
+		if err == nil || !strings.HasPrefix(err.Error(), "join url: ") {
+			t.Fatalf("error = %v, want join url error", err)
+		}
+	})
+}
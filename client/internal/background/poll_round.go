@@ -5,10 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/bodrovis/lokex/v2/client"
 	"github.com/bodrovis/lokex/v2/internal/apierr"
-	"github.com/bodrovis/lokex/v2/internal/utils"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -17,7 +16,7 @@ import (
 // Workers never block on send because resCh is buffered to len(ids).
 func pollRound(
 	ctx context.Context,
-	c *client.Client,
+	reqs *pollRequests,
 	pending map[string]struct{},
 	maxConcurrent int,
 ) ([]QueuedProcess, map[string]error) {
@@ -26,8 +25,6 @@ func pollRound(
 		ids = append(ids, id)
 	}
 
-	reqr := c.Requester()
-
 	resCh := make(chan pollResult, len(ids))
 
 	g, gctx := errgroup.WithContext(ctx)
@@ -36,10 +33,8 @@ func pollRound(
 	for _, id := range ids {
 		cur := id
 		g.Go(func() error {
-			path := utils.ProjectPath(c.ProjectID, fmt.Sprintf("processes/%s", cur))
-
 			var resp processResponse
-			if err := reqr.DoJSON(gctx, http.MethodGet, path, nil, &resp); err != nil {
+			if err := reqs.do(gctx, cur, &resp); err != nil {
 				resCh <- pollResult{id: cur, err: err}
 				return nil
 			}
@@ -68,12 +63,14 @@ func pollRound(
 }
 
 // applyRound updates processMap/pending based on successful statuses and errors.
+// It returns an error when polling can't go on for any ID: the token was
+// rejected (401).
 func applyRound(
 	processMap map[string]QueuedProcess,
 	pending map[string]struct{},
 	procs []QueuedProcess,
 	errs map[string]error,
-) {
+) error {
 	// Successful statuses update the latest view and remove terminal IDs.
 	for _, p := range procs {
 		processMap[p.ProcessID] = p
@@ -82,7 +79,17 @@ func applyRound(
 		}
 	}
 
+	// A rejected token fails every request alike; stop instead of polling
+	// until the budget runs out.
+	for id, err := range errs {
+		var ae *apierr.APIError
+		if errors.As(err, &ae) && ae.Status == http.StatusUnauthorized {
+			return fmt.Errorf("poll process %s: %w", id, err)
+		}
+	}
+
 	// Errors: retryable stays pending; non-retryable is marked failed and removed.
+	// Either way the error is kept on the process.
 	// Context cancellation/deadline errors are ignored here because the caller is stopping polling.
 	for id, err := range errs {
 		// defensive; PollProcesses should return ctx.Err earlier
@@ -92,10 +99,30 @@ func applyRound(
 		}
 
 		if apierr.IsRetryable(err) {
+			p := processMap[id]
+			p.Err = err
+			processMap[id] = p
 			continue
 		}
 
-		processMap[id] = QueuedProcess{ProcessID: id, Status: StatusFailed}
+		processMap[id] = QueuedProcess{ProcessID: id, Status: StatusFailed, Err: err}
 		delete(pending, id)
 	}
+	return nil
+}
+
+// roundRetryAfter returns the longest Retry-After among this round's
+// rate-limited (429/503) poll errors.
+func roundRetryAfter(errs map[string]error) (time.Duration, bool) {
+	var (
+		longest time.Duration
+		found   bool
+	)
+	for _, err := range errs {
+		if d, ok := apierr.RetryAfter(err); ok {
+			longest = max(longest, d)
+			found = true
+		}
+	}
+	return longest, found
 }
@@ -2,8 +2,11 @@ package background_test
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"testing"
 
+	"github.com/bodrovis/lokex/v2/client"
 	"github.com/bodrovis/lokex/v2/client/internal/background"
 )
 
@@ -40,4 +43,52 @@ func TestApplyRound(t *testing.T) {
 			t.Fatal(`pending["deadline"] missing, want it to remain pending`)
 		}
 	})
+	t.Run("errors are kept on the process until a status arrives", func(t *testing.T) {
+		t.Parallel()
+
+		transient := &client.APIError{Status: http.StatusBadGateway}
+		gone := &client.APIError{Status: http.StatusNotFound}
+		processMap := map[string]background.QueuedProcess{
+			"a": {ProcessID: "a", Status: background.StatusQueued},
+			"b": {ProcessID: "b", Status: background.StatusQueued},
+		}
+		pending := map[string]struct{}{"a": {}, "b": {}}
+
+		err := background.ExportApplyRound(processMap, pending, nil, map[string]error{"a": transient, "b": gone})
+		if err != nil {
+			t.Fatalf("applyRound() error = %v", err)
+		}
+		if p := processMap["a"]; p.Status != background.StatusQueued || p.Err != transient {
+			t.Fatalf("a = %+v, want queued with the 502", p)
+		}
+		if _, ok := pending["a"]; !ok {
+			t.Fatal("a left pending after a transient error")
+		}
+		if p := processMap["b"]; p.Status != background.StatusFailed || p.Err != gone {
+			t.Fatalf("b = %+v, want failed with the 404", p)
+		}
+		if _, ok := pending["b"]; ok {
+			t.Fatal("b still pending after a 404")
+		}
+
+		running := background.QueuedProcess{ProcessID: "a", Status: "running"}
+		if err := background.ExportApplyRound(processMap, pending, []background.QueuedProcess{running}, nil); err != nil {
+			t.Fatalf("applyRound() error = %v", err)
+		}
+		if p := processMap["a"]; p.Status != "running" || p.Err != nil {
+			t.Fatalf("a = %+v, want running without error", p)
+		}
+	})
+
+	t.Run("401 aborts the poll", func(t *testing.T) {
+		t.Parallel()
+
+		pending := map[string]struct{}{"a": {}}
+		err := background.ExportApplyRound(map[string]background.QueuedProcess{}, pending, nil,
+			map[string]error{"a": &client.APIError{Status: http.StatusUnauthorized}})
+		var ae *client.APIError
+		if !errors.As(err, &ae) || ae.Status != http.StatusUnauthorized {
+			t.Fatalf("applyRound() error = %v, want the 401", err)
+		}
+	})
 }
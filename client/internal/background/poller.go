@@ -4,10 +4,18 @@ import (
 	"context"
 	"time"
 
-	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+	"github.com/bodrovis/lokex/v2/internal/metrics"
+	"github.com/bodrovis/lokex/v2/internal/telemetry"
 	"github.com/bodrovis/lokex/v2/internal/utils"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+// DefaultConcurrency is how many statuses a polling round fetches at once
+// unless Config.Concurrency says otherwise.
+const DefaultConcurrency = 6
+
 const (
 	// Queued process statuses
 	StatusQueued   = "queued"
@@ -15,6 +23,29 @@ const (
 	StatusFailed   = "failed"
 )
 
+// Config carries the client settings PollProcesses needs.
+type Config struct {
+	Requester   transport.Requester
+	ProjectID   string
+	InitialWait time.Duration    // initial wait between rounds
+	MaxWait     time.Duration    // overall polling budget
+	Concurrency int              // status requests per round in flight; <= 0 means DefaultConcurrency
+	Tracer      trace.Tracer     // optional; wraps PollProcesses in a span
+	Metrics     metrics.Recorder // optional; told when PollProcesses returns
+
+	// Wake, if set, returns a channel whose closing ends the current wait
+	// between rounds early (see Waker). It is called before each round, so
+	// a wake-up during a round isn't lost.
+	Wake func() <-chan struct{}
+}
+
+// Source provides polling settings. *client.Client implements it; the
+// interface keeps this package from importing client, so client itself
+// can poll.
+type Source interface {
+	PollConfig() Config
+}
+
 type pollResult struct {
 	id   string
 	proc QueuedProcess
@@ -30,7 +61,7 @@ var (
 
 // PollProcesses polls one or more Lokalise async process IDs until each reaches a
 // terminal status ("finished" or "failed"), or until the overall polling budget
-// (PollMaxWait) is exhausted.
+// (Config.MaxWait) is exhausted.
 //
 // Ordering rules:
 //   - Returns one result per NON-empty input ID
@@ -39,22 +70,59 @@ var (
 //
 // Error handling rules:
 //   - Transient request errors do NOT abort polling; that ID stays pending and
-//     will be retried in the next round.
-//   - Non-retryable errors for an ID mark ONLY that process as "failed" and
-//     remove it from pending; polling continues for other IDs.
+//     will be retried in the next round. Its latest error is kept in
+//     QueuedProcess.Err until a request succeeds.
+//   - A 429/503 with Retry-After delays the next round for ALL pending IDs
+//     by at least that long (within the polling budget).
+//   - Non-retryable errors for an ID (e.g. 404) mark ONLY that process as
+//     "failed", with the error in QueuedProcess.Err, and remove it from
+//     pending; polling continues for other IDs.
+//   - A 401 aborts the whole poll and returns that error: a revoked token
+//     would otherwise be polled until the budget runs out.
 //   - Context cancellation / deadline aborts the whole poll and returns ctx error.
 //
 // Implementation notes:
-//   - Each polling round does parallel GETs with a fixed concurrency cap.
+//   - Each polling round does parallel GETs, at most Config.Concurrency at a time.
+//   - Request URLs/headers are prepared once per process and reused by every round.
 //   - We buffer the result channel so workers never block on send.
 //   - We enforce an overall polling budget via context.WithDeadline and return
 //     best-effort results when that budget expires.
-func PollProcesses(ctx context.Context, processIDs []string, c *client.Client) ([]QueuedProcess, error) {
+//   - With Config.Wake, a wake-up (e.g. from a webhook) ends the wait
+//     between rounds early; the backoff carries on as before.
+func PollProcesses(ctx context.Context, processIDs []string, src Source) ([]QueuedProcess, error) {
+	return PollProcessesWithProgress(ctx, processIDs, src, nil)
+}
+
+// PollProcessesWithProgress is PollProcesses calling progress with the new
+// state of a process whenever its status changes, including the first
+// status fetched, e.g. queued -> running -> finished. It also fires when
+// polling marks a process failed after a non-retryable error. Calls come
+// from the polling goroutine, between rounds and in input order, so a slow
+// callback delays polling. A nil progress is allowed.
+func PollProcessesWithProgress(
+	ctx context.Context,
+	processIDs []string,
+	src Source,
+	progress func(QueuedProcess),
+) (_ []QueuedProcess, err error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	wait, deadline, pollCtx, cancel := newPollContext(ctx, c)
+	cfg := src.PollConfig()
+	ctx, span := telemetry.Start(ctx, cfg.Tracer, telemetry.SpanPoll,
+		telemetry.AttrProcessCount.Int(len(processIDs)))
+	rounds, start := 0, time.Now()
+	var pending map[string]struct{}
+	defer func() {
+		span.SetAttributes(telemetry.AttrPollRounds.Int(rounds), telemetry.AttrPollPending.Int(len(pending)))
+		telemetry.End(span, err)
+		if cfg.Metrics != nil {
+			cfg.Metrics.Poll(len(processIDs), rounds, time.Since(start))
+		}
+	}()
+
+	wait, deadline, pollCtx, cancel := newPollContext(ctx, cfg)
 	defer cancel()
 
 	ordered, processMap, pending := normalizeProcessIDs(processIDs)
@@ -63,7 +131,14 @@ func PollProcesses(ctx context.Context, processIDs []string, c *client.Client) (
 	}
 
 	// Bound parallelism so we don't spam Lokalise or overload the client.
-	const maxConcurrent = 6
+	maxConcurrent := cfg.Concurrency
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultConcurrency
+	}
+
+	// Build per-process requests once; every round reuses them.
+	reqs := newPollRequests(cfg, pending)
+	reporter := newProgressReporter(ordered, progress)
 
 	// Reuse a timer to avoid allocating time.After() on each round.
 	timer := newStoppedTimer()
@@ -79,8 +154,14 @@ func PollProcesses(ctx context.Context, processIDs []string, c *client.Client) (
 			break
 		}
 
+		var wake <-chan struct{}
+		if cfg.Wake != nil {
+			wake = cfg.Wake()
+		}
+
 		// One round: fetch all pending statuses concurrently (bounded).
-		procs, errs := pollRoundFn(pollCtx, c, pending, maxConcurrent)
+		rounds++
+		procs, errs := pollRoundFn(pollCtx, reqs, pending, maxConcurrent)
 
 		// If caller ctx died during the round, surface that (real error).
 		if err := callerContextErr(ctx); err != nil {
@@ -88,7 +169,10 @@ func PollProcesses(ctx context.Context, processIDs []string, c *client.Client) (
 		}
 
 		// Apply outcomes to processMap/pending (single goroutine mutates maps => no locks).
-		applyRound(processMap, pending, procs, errs)
+		if err := applyRound(processMap, pending, procs, errs); err != nil {
+			return nil, err
+		}
+		reporter.report(processMap)
 
 		if len(pending) == 0 {
 			break
@@ -103,8 +187,13 @@ func PollProcesses(ctx context.Context, processIDs []string, c *client.Client) (
 		if !ok {
 			break
 		}
+		if pause, limited := roundRetryAfter(errs); limited {
+			// Rate limited: the whole loop honors Retry-After, not just the
+			// throttled IDs, clipped to the remaining budget.
+			sleep = max(sleep, min(pause, time.Until(deadline)))
+		}
 
-		stopped, err := sleepBetweenPollRounds(ctx, pollCtx, timer, sleep)
+		stopped, err := sleepBetweenPollRounds(ctx, pollCtx, timer, sleep, wake)
 		if err != nil {
 			return nil, err
 		}
@@ -119,12 +208,12 @@ func PollProcesses(ctx context.Context, processIDs []string, c *client.Client) (
 	return buildResults(ordered, processMap), nil
 }
 
-func newPollContext(ctx context.Context, c *client.Client) (time.Duration, time.Time, context.Context, context.CancelFunc) {
-	wait := c.PollInitialWait
-	maxWait := c.PollMaxWait
+func newPollContext(ctx context.Context, cfg Config) (time.Duration, time.Time, context.Context, context.CancelFunc) {
+	wait := cfg.InitialWait
+	maxWait := cfg.MaxWait
 	deadline := time.Now().Add(maxWait)
 
-	// pollCtx enforces the polling budget (PollMaxWait). When it expires,
+	// pollCtx enforces the polling budget (Config.MaxWait). When it expires,
 	// we should stop polling and return best-effort results (not an error),
 	// unless the caller's ctx itself is canceled/deadline-exceeded.
 	pollCtx, cancel := context.WithDeadline(ctx, deadline)
@@ -171,14 +260,21 @@ func sleepBetweenPollRounds(
 	pollCtx context.Context,
 	timer *time.Timer,
 	sleep time.Duration,
+	wake <-chan struct{},
 ) (bool, error) {
-	if err := sleepWithTimer(pollCtx, timer, sleep); err != nil {
+	sleepCtx, cancel := wakeContext(pollCtx, wake)
+	defer cancel()
+
+	if err := sleepWithTimer(sleepCtx, timer, sleep); err != nil {
 		// If caller ctx is canceled/deadline-exceeded -> error.
 		if cerr := ctx.Err(); cerr != nil {
 			return false, cerr
 		}
-		// Otherwise it's our polling budget -> best-effort return.
-		return true, nil
+		// Our polling budget -> best-effort return.
+		if pollBudgetExpired(pollCtx) {
+			return true, nil
+		}
+		// Woken up: poll again right away.
 	}
 
 	return false, nil
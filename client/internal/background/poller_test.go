@@ -259,11 +259,138 @@ func TestPollProcesses_NonRetryableError_MarksFailedAndContinues(t *testing.T) {
 	if got[0].ProcessID != "x" || got[0].Status != background.StatusFailed {
 		t.Fatalf("x: got=%#v, want failed", got[0])
 	}
-	if got[1].ProcessID != "y" || got[1].Status != background.StatusFinished {
+	var ae *client.APIError
+	if !errors.As(got[0].Err, &ae) || ae.Status != http.StatusNotFound {
+		t.Fatalf("x: Err = %v, want the 404", got[0].Err)
+	}
+	if got[1].ProcessID != "y" || got[1].Status != background.StatusFinished || got[1].Err != nil {
 		t.Fatalf("y: got=%#v, want finished", got[1])
 	}
 }
 
+func TestPollProcesses_ErrorBodyEchoingToken_IsRedacted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxy echoing the request back, token included.
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"message":"no process for token ` + r.Header.Get("X-Api-Token") + `","code":404}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t,
+		withServer(srv),
+		withProjectID("p"),
+	)
+
+	got, err := background.PollProcesses(context.Background(), []string{"x"}, c)
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if len(got) != 1 || got[0].Err == nil {
+		t.Fatalf("got = %#v, want x with the 404", got)
+	}
+	var ae *client.APIError
+	if !errors.As(got[0].Err, &ae) {
+		t.Fatalf("Err = %v, want an APIError", got[0].Err)
+	}
+	for name, s := range map[string]string{"Error()": got[0].Err.Error(), "Raw": ae.Raw, "Message": ae.Message} {
+		if strings.Contains(s, "test-token") {
+			t.Fatalf("%s leaks the token: %s", name, s)
+		}
+	}
+}
+
+func TestPollProcesses_Unauthorized_Aborts(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"Invalid token","code":401}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t,
+		withServer(srv),
+		withProjectID("p"),
+	)
+
+	got, err := background.PollProcesses(context.Background(), []string{"x", "y"}, c)
+	var ae *client.APIError
+	if !errors.As(err, &ae) || ae.Status != http.StatusUnauthorized {
+		t.Fatalf("err = %v, want the 401", err)
+	}
+	if got != nil {
+		t.Fatalf("got = %#v, want nil", got)
+	}
+	// One round, no retries of a rejected token.
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("hits = %d, want 2", n)
+	}
+}
+
+func TestPollProcesses_Concurrency_BoundsInFlightAndKeepsOrder(t *testing.T) {
+	const workers, procs = 4, 20
+
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		id := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"process":{"process_id":%q,"status":"finished"}}`, id)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, withServer(srv), withPollWait(time.Millisecond, 5*time.Second))
+	c.PollConcurrency = workers
+
+	ids := make([]string, procs)
+	for i := range ids {
+		ids[procs-1-i] = fmt.Sprintf("p%02d", i)
+	}
+	got, err := background.PollProcesses(context.Background(), ids, c)
+	if err != nil {
+		t.Fatalf("PollProcesses() error = %v", err)
+	}
+	for i, p := range got {
+		if p.ProcessID != ids[i] || p.Status != background.StatusFinished {
+			t.Fatalf("got[%d] = %+v, want finished %s", i, p, ids[i])
+		}
+	}
+	if p := peak.Load(); p != workers {
+		t.Fatalf("peak in-flight = %d, want %d", p, workers)
+	}
+}
+
+func TestPollProcesses_DefaultConcurrency(t *testing.T) {
+	var got int
+	restore := background.ExportSetPollRoundForTest(
+		func(_ context.Context, pending map[string]struct{}, n int) ([]background.QueuedProcess, map[string]error) {
+			got = n
+			return []background.QueuedProcess{{ProcessID: "p1", Status: background.StatusFinished}}, nil
+		},
+	)
+	defer restore()
+
+	c := newTestClient(t)
+	c.PollConcurrency = 0
+	if _, err := background.PollProcesses(context.Background(), []string{"p1"}, c); err != nil {
+		t.Fatal(err)
+	}
+	if got != background.DefaultConcurrency {
+		t.Fatalf("round concurrency = %d, want %d", got, background.DefaultConcurrency)
+	}
+}
+
 func TestPollProcesses_PollBudgetExpires_ReturnsQueued(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -354,7 +481,7 @@ func TestPollProcesses(t *testing.T) {
 
 	t.Run("poll budget expired during round stops with best effort results", func(t *testing.T) {
 		restorePollRound := background.ExportSetPollRoundForTest(
-			func(ctx context.Context, _ *client.Client, pending map[string]struct{}, _ int) ([]background.QueuedProcess, map[string]error) {
+			func(ctx context.Context, pending map[string]struct{}, _ int) ([]background.QueuedProcess, map[string]error) {
 				<-ctx.Done()
 
 				return []background.QueuedProcess{
@@ -401,7 +528,7 @@ func TestPollProcesses(t *testing.T) {
 
 	t.Run("next sleep wait false stops polling with best effort results", func(t *testing.T) {
 		restorePollRound := background.ExportSetPollRoundForTest(
-			func(_ context.Context, _ *client.Client, pending map[string]struct{}, _ int) ([]background.QueuedProcess, map[string]error) {
+			func(_ context.Context, pending map[string]struct{}, _ int) ([]background.QueuedProcess, map[string]error) {
 				return []background.QueuedProcess{
 					{ProcessID: "p1", Status: background.StatusQueued},
 				}, nil
@@ -434,7 +561,7 @@ func TestPollProcesses(t *testing.T) {
 
 	t.Run("sleep stop breaks polling with best effort results", func(t *testing.T) {
 		restorePollRound := background.ExportSetPollRoundForTest(
-			func(_ context.Context, _ *client.Client, pending map[string]struct{}, _ int) ([]background.QueuedProcess, map[string]error) {
+			func(_ context.Context, pending map[string]struct{}, _ int) ([]background.QueuedProcess, map[string]error) {
 				return []background.QueuedProcess{
 					{ProcessID: "p1", Status: background.StatusQueued},
 				}, nil
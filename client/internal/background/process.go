@@ -1,39 +1,247 @@
 package background
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"strings"
+	"time"
 
+	"github.com/bodrovis/lokex/v2/internal/schema"
 	"github.com/bodrovis/lokex/v2/internal/utils"
 )
 
 // QueuedProcess is a normalized view over Lokalise "processes/*" responses.
-// DownloadURL is populated when the process produces a file (e.g., download).
+// DownloadURL is populated when the process produces a file (e.g., download);
+// Files when it imported files (uploads).
+//
+// Details holds everything the API reported under "details", typed where
+// lokex knows the fields; DownloadURL and Files repeat its fields of the
+// same name.
+//
+// Type (e.g. "file-import", "async-export") and CreatedAt are set when the
+// API reports them, as it does in process lists.
+//
+// Err is set when the last request for the process failed: it is why
+// polling marked the process failed (e.g. a 404 for a deleted process), or
+// why it is still queued when the polling budget ran out. It is nil when
+// the status came from Lokalise.
 type QueuedProcess struct {
-	ProcessID   string `json:"process_id"`
-	Status      string `json:"status"`
-	DownloadURL string `json:"download_url,omitempty"`
-	Message     string `json:"message,omitempty"`
+	ProcessID   string         `json:"process_id"`
+	Type        string         `json:"type,omitempty"`
+	Status      string         `json:"status"`
+	DownloadURL string         `json:"download_url,omitempty"`
+	Message     string         `json:"message,omitempty"`
+	CreatedAt   time.Time      `json:"created_at,omitzero"`
+	Files       []ProcessFile  `json:"files,omitempty"`
+	Details     ProcessDetails `json:"details,omitzero"`
+	Err         error          `json:"-"`
+}
+
+// ProcessDetails is the "details" object of a process. Which fields are set
+// depends on the process type: Files for uploads (file-import), DownloadURL,
+// FileSizeKB and TotalKeys for async exports.
+type ProcessDetails struct {
+	DownloadURL string        `json:"download_url,omitempty"`
+	FileSizeKB  int           `json:"file_size_kb,omitempty"`
+	TotalKeys   int           `json:"total_number_of_keys,omitempty"`
+	Files       []ProcessFile `json:"files,omitempty"`
+	// Raw is the whole object as decoded, numbers as json.Number, for
+	// fields not listed above and process types lokex doesn't know.
+	Raw map[string]any `json:"-"`
+}
+
+// ImportSummary adds up the per-file counts of an upload process.
+type ImportSummary struct {
+	Files       int // files reported
+	FilesFailed int // files whose status isn't "finished"
+	Words       int
+	Keys        int
+	Inserted    int
+	Updated     int
+	Skipped     int
+	Warnings    int
+}
+
+// Summary adds up d.Files.
+func (d ProcessDetails) Summary() ImportSummary {
+	s := ImportSummary{Files: len(d.Files)}
+	for _, f := range d.Files {
+		if f.Status != StatusFinished {
+			s.FilesFailed++
+		}
+		s.Words += f.WordCountTotal
+		s.Keys += f.KeyCountTotal
+		s.Inserted += f.KeyCountInserted
+		s.Updated += f.KeyCountUpdated
+		s.Skipped += f.KeyCountSkipped
+		s.Warnings += len(f.Warnings)
+	}
+	return s
+}
+
+// ProcessFile is the outcome of one file of an upload process
+// (details.files[] in the API response).
+type ProcessFile struct {
+	Name             string   `json:"name_original"`
+	CustomName       string   `json:"name_custom,omitempty"`
+	Status           string   `json:"status"`
+	Message          string   `json:"message,omitempty"`
+	WordCountTotal   int      `json:"word_count_total"`
+	KeyCountTotal    int      `json:"key_count_total"`
+	KeyCountInserted int      `json:"key_count_inserted"`
+	KeyCountUpdated  int      `json:"key_count_updated"`
+	KeyCountSkipped  int      `json:"key_count_skipped"`
+	Warnings         Warnings `json:"warnings,omitempty"`
+}
+
+// Warnings are the messages of a ProcessFile. The API sends them as strings
+// or as objects with a "message"; both decode to plain strings.
+type Warnings []string
+
+// UnmarshalJSON accepts a list of strings and/or {"message": ...} objects.
+func (w *Warnings) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	out := make(Warnings, 0, len(raw))
+	for _, item := range raw {
+		var s string
+		if err := json.Unmarshal(item, &s); err == nil {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+			continue
+		}
+		var obj struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(item, &obj); err != nil {
+			return err
+		}
+		if msg := strings.TrimSpace(obj.Message); msg != "" {
+			out = append(out, msg)
+		} else {
+			out = append(out, strings.TrimSpace(string(item)))
+		}
+	}
+	*w = out
+	return nil
+}
+
+// processObject is a process as the API sends it, alone or in a list.
+type processObject struct {
+	ProcessID string          `json:"process_id" lokex:"required"`
+	Type      string          `json:"type"`
+	Status    string          `json:"status" lokex:"required"`
+	Message   string          `json:"message"`
+	CreatedAt int64           `json:"created_at_timestamp"`
+	Details   json.RawMessage `json:"details"`
 }
 
 // processResponse mirrors the subset of the Lokalise response we care about.
 // It stays unexported; callers use QueuedProcess instead.
 type processResponse struct {
-	Process struct {
-		ProcessID string `json:"process_id"`
-		Status    string `json:"status"`
-		Message   string `json:"message"`
-		Details   struct {
-			DownloadURL string `json:"download_url"`
-		} `json:"details"`
-	} `json:"process"`
+	Process processObject `json:"process" lokex:"required"`
 }
 
 // ToQueuedProcess converts a typed API response into a flattened QueuedProcess.
 func (pr *processResponse) ToQueuedProcess() QueuedProcess {
-	return QueuedProcess{
-		ProcessID:   pr.Process.ProcessID,
-		Status:      utils.NormalizeString(pr.Process.Status),
-		Message:     strings.TrimSpace(pr.Process.Message),
-		DownloadURL: pr.Process.Details.DownloadURL,
+	return pr.Process.toQueuedProcess()
+}
+
+func (po *processObject) toQueuedProcess() QueuedProcess {
+	d := parseDetails(po.Details)
+	qp := QueuedProcess{
+		ProcessID:   po.ProcessID,
+		Type:        utils.NormalizeString(po.Type),
+		Status:      utils.NormalizeString(po.Status),
+		Message:     strings.TrimSpace(po.Message),
+		DownloadURL: d.DownloadURL,
+		Files:       d.Files,
+		Details:     d,
+	}
+	if po.CreatedAt > 0 {
+		qp.CreatedAt = time.Unix(po.CreatedAt, 0).UTC()
+	}
+	return qp
+}
+
+// DecodeProcess converts one item of a process list (GET processes) into
+// a QueuedProcess. The item must carry a process_id and a status.
+func DecodeProcess(raw []byte) (QueuedProcess, error) {
+	var po processObject
+	if err := json.Unmarshal(raw, &po); err != nil {
+		return QueuedProcess{}, err
+	}
+	if err := schema.Check(raw, &po); err != nil {
+		return QueuedProcess{}, err
+	}
+	return po.toQueuedProcess(), nil
+}
+
+// parseDetails decodes a "details" object. Anything else, such as the
+// empty array the API sends for processes without details, or fields of
+// unexpected types, yields what could be decoded.
+func parseDetails(raw json.RawMessage) ProcessDetails {
+	var d ProcessDetails
+	if len(raw) == 0 || raw[0] != '{' {
+		return d
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&d.Raw); err != nil {
+		return ProcessDetails{}
+	}
+
+	d.DownloadURL, _ = d.Raw["download_url"].(string)
+	d.FileSizeKB = rawInt(d.Raw["file_size_kb"])
+	d.TotalKeys = rawInt(d.Raw["total_number_of_keys"])
+	if _, ok := d.Raw["files"]; ok {
+		var typed struct {
+			Files []ProcessFile `json:"files"`
+		}
+		if err := json.Unmarshal(raw, &typed); err == nil { // else still in Raw
+			d.Files = normalizeFiles(typed.Files)
+		}
+	}
+	return d
+}
+
+func rawInt(v any) int {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return 0
+	}
+	return int(i)
+}
+
+func normalizeFiles(files []ProcessFile) []ProcessFile {
+	for i := range files {
+		files[i].Status = utils.NormalizeString(files[i].Status)
+		files[i].Message = strings.TrimSpace(files[i].Message)
+	}
+	return files
+}
+
+// DoFunc performs one JSON API call; Client.DoJSONWithRetry satisfies it.
+type DoFunc func(ctx context.Context, method, path string, body io.Reader, v any) error
+
+// FetchProcess reads the current state of process id once via do.
+func FetchProcess(ctx context.Context, do DoFunc, projectID, id string) (QueuedProcess, error) {
+	var resp processResponse
+	path := utils.ProjectPath(projectID, fmt.Sprintf("processes/%s", id))
+	if err := do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return QueuedProcess{}, err
 	}
+	return resp.ToQueuedProcess(), nil
 }
@@ -0,0 +1,118 @@
+package background_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+)
+
+func TestWarnings_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	var w background.Warnings
+	in := `["skipped 2 keys", {"message":" invalid plural "}, {"code":7}]`
+	if err := json.Unmarshal([]byte(in), &w); err != nil {
+		t.Fatal(err)
+	}
+	want := background.Warnings{"skipped 2 keys", "invalid plural", `{"code":7}`}
+	if !reflect.DeepEqual(w, want) {
+		t.Fatalf("warnings = %q, want %q", w, want)
+	}
+
+	if err := json.Unmarshal([]byte(`"nope"`), &w); err == nil {
+		t.Fatal("expected error for a non-list")
+	}
+}
+
+func TestFetchProcess_Details(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		details string
+		check   func(t *testing.T, p background.QueuedProcess)
+	}{
+		{
+			name: "upload",
+			details: `{"files":[
+				{"name_original":"en.json","status":"Finished","word_count_total":10,"key_count_total":4,"key_count_inserted":3,"key_count_updated":1,"key_count_skipped":0},
+				{"name_original":"de.json","status":"failed","message":" bad plural ","key_count_total":2,"key_count_skipped":2,"warnings":["x"]}
+			],"total_files":2}`,
+			check: func(t *testing.T, p background.QueuedProcess) {
+				want := background.ImportSummary{Files: 2, FilesFailed: 1, Words: 10, Keys: 6, Inserted: 3, Updated: 1, Skipped: 2, Warnings: 1}
+				if got := p.Details.Summary(); got != want {
+					t.Fatalf("Summary() = %+v, want %+v", got, want)
+				}
+				if !reflect.DeepEqual(p.Files, p.Details.Files) || p.Files[1].Message != "bad plural" || p.Files[0].Status != "finished" {
+					t.Fatalf("Files = %+v", p.Files)
+				}
+				if n, ok := p.Details.Raw["total_files"].(json.Number); !ok || n.String() != "2" {
+					t.Fatalf("Raw[total_files] = %#v", p.Details.Raw["total_files"])
+				}
+			},
+		},
+		{
+			name:    "export",
+			details: `{"download_url":"https://cdn.example.com/b.zip","file_size_kb":12,"total_number_of_keys":345}`,
+			check: func(t *testing.T, p background.QueuedProcess) {
+				d := p.Details
+				if d.DownloadURL != "https://cdn.example.com/b.zip" || p.DownloadURL != d.DownloadURL || d.FileSizeKB != 12 || d.TotalKeys != 345 {
+					t.Fatalf("Details = %+v", d)
+				}
+			},
+		},
+		{
+			name:    "empty array",
+			details: `[]`,
+			check: func(t *testing.T, p background.QueuedProcess) {
+				if p.Details.Raw != nil || p.Status != "finished" {
+					t.Fatalf("got = %+v", p)
+				}
+			},
+		},
+		{
+			name:    "unknown shape",
+			details: `{"files":"n/a","translated":{"count":9007199254740993}}`,
+			check: func(t *testing.T, p background.QueuedProcess) {
+				if p.Files != nil || p.Details.Raw["files"] != "n/a" {
+					t.Fatalf("Details = %+v", p.Details)
+				}
+				count := p.Details.Raw["translated"].(map[string]any)["count"].(json.Number)
+				if count.String() != "9007199254740993" {
+					t.Fatalf("count = %s, want exact", count)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			do := func(_ context.Context, _, _ string, _ io.Reader, v any) error {
+				body := `{"process":{"process_id":"p1","status":"finished","details":` + tt.details + `}}`
+				return json.Unmarshal([]byte(body), v)
+			}
+			p, err := background.FetchProcess(context.Background(), do, "proj", "p1")
+			if err != nil {
+				t.Fatalf("FetchProcess() error = %v", err)
+			}
+			tt.check(t, p)
+		})
+	}
+}
+
+func TestQueuedProcess_JSONDetails(t *testing.T) {
+	t.Parallel()
+
+	raw, err := json.Marshal(background.QueuedProcess{ProcessID: "p1", Status: "queued"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != `{"process_id":"p1","status":"queued"}` {
+		t.Fatalf("json = %s, want no details", raw)
+	}
+}
@@ -0,0 +1,41 @@
+package background
+
+// progressReporter tells a progress callback about status changes. Like
+// processMap, it is only used by the polling goroutine.
+type progressReporter struct {
+	fn   func(QueuedProcess)
+	ids  []string          // unique non-empty IDs in input order
+	last map[string]string // status last reported per ID
+}
+
+func newProgressReporter(ordered []string, fn func(QueuedProcess)) *progressReporter {
+	r := &progressReporter{fn: fn}
+	if fn == nil {
+		return r
+	}
+	r.last = make(map[string]string, len(ordered))
+	for _, id := range ordered {
+		if _, seen := r.last[id]; id != "" && !seen {
+			r.last[id] = ""
+			r.ids = append(r.ids, id)
+		}
+	}
+	return r
+}
+
+// report calls fn for every process whose status differs from the one last
+// reported. A process nothing is known about yet (still the queued
+// placeholder, or only errors so far) isn't reported.
+func (r *progressReporter) report(processMap map[string]QueuedProcess) {
+	if r.fn == nil {
+		return
+	}
+	for _, id := range r.ids {
+		p, ok := processMap[id]
+		if !ok || p.Status == r.last[id] || (r.last[id] == "" && p.Status == StatusQueued && p.Err != nil) {
+			continue
+		}
+		r.last[id] = p.Status
+		r.fn(p)
+	}
+}
@@ -0,0 +1,72 @@
+package background_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+)
+
+func TestPollProcessesWithProgress_ReportsChanges(t *testing.T) {
+	rounds := [][]background.QueuedProcess{
+		{{ProcessID: "p1", Status: "queued"}},
+		{{ProcessID: "p1", Status: "queued"}},
+		{{ProcessID: "p1", Status: "running"}},
+		{{ProcessID: "p1", Status: "finished"}},
+	}
+	// p2 fails transiently first (not reported), then for good (reported).
+	roundErrs := []map[string]error{
+		{"p2": &client.APIError{Status: http.StatusBadGateway}},
+		{"p2": &client.APIError{Status: http.StatusNotFound}},
+		nil,
+		nil,
+	}
+	round := 0
+	restore := background.ExportSetPollRoundForTest(
+		func(context.Context, map[string]struct{}, int) ([]background.QueuedProcess, map[string]error) {
+			i := min(round, len(rounds)-1)
+			round++
+			return rounds[i], roundErrs[i]
+		},
+	)
+	defer restore()
+
+	cli := newTestClient(t, withPollWait(time.Millisecond, time.Second))
+
+	var events []string
+	got, err := background.PollProcessesWithProgress(context.Background(), []string{"p2", "p1", " "}, cli, func(p background.QueuedProcess) {
+		events = append(events, p.ProcessID+":"+p.Status)
+		if p.ProcessID == "p2" && p.Err == nil {
+			t.Errorf("p2 reported without its error")
+		}
+	})
+	if err != nil {
+		t.Fatalf("PollProcessesWithProgress() error = %v", err)
+	}
+	if want := []string{"p1:queued", "p2:failed", "p1:running", "p1:finished"}; !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	var ae *client.APIError
+	if len(got) != 2 || !errors.As(got[0].Err, &ae) || got[1].Status != "finished" {
+		t.Fatalf("got = %+v", got)
+	}
+}
+
+func TestPollProcessesWithProgress_NilProgress(t *testing.T) {
+	restore := background.ExportSetPollRoundForTest(
+		func(context.Context, map[string]struct{}, int) ([]background.QueuedProcess, map[string]error) {
+			return []background.QueuedProcess{{ProcessID: "p1", Status: "finished"}}, nil
+		},
+	)
+	defer restore()
+
+	got, err := background.PollProcessesWithProgress(context.Background(), []string{"p1"}, newTestClient(t), nil)
+	if err != nil || len(got) != 1 || got[0].Status != "finished" {
+		t.Fatalf("got = %+v, err = %v", got, err)
+	}
+}
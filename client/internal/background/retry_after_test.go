@@ -0,0 +1,95 @@
+package background_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+)
+
+func TestPollProcesses_RetryAfterPausesWholeLoop(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		hits      = map[string][]time.Time{}
+		throttled bool
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len(r.URL.Path)-1:]
+
+		mu.Lock()
+		hits[id] = append(hits[id], time.Now())
+		first := id == "a" && !throttled
+		if first {
+			throttled = true
+		}
+		mu.Unlock()
+
+		if first {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, `{"message":"slow down"}`, http.StatusTooManyRequests)
+			return
+		}
+		status := "finished"
+		if id == "b" && len(hits["b"]) == 1 {
+			status = "running"
+		}
+		_, _ = w.Write([]byte(`{"process":{"process_id":"` + id + `","status":"` + status + `"}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t,
+		withServer(srv),
+		withPollWait(5*time.Millisecond, 5*time.Second),
+	)
+
+	start := time.Now()
+	res, err := background.PollProcesses(context.Background(), []string{"a", "b"}, c)
+	if err != nil {
+		t.Fatalf("PollProcesses() error = %v", err)
+	}
+	for _, p := range res {
+		if p.Status != background.StatusFinished {
+			t.Fatalf("result %+v, want finished", p)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hits["a"]) != 2 || len(hits["b"]) != 2 {
+		t.Fatalf("hits = a:%d b:%d, want 2 each", len(hits["a"]), len(hits["b"]))
+	}
+	// b was not throttled itself, but its second poll must also wait.
+	if gap := hits["b"][1].Sub(start); gap < 900*time.Millisecond {
+		t.Fatalf("second round started after %v, want >= ~1s Retry-After", gap)
+	}
+}
+
+func TestPollProcesses_RetryAfterClippedToBudget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t,
+		withServer(srv),
+		withPollWait(5*time.Millisecond, 100*time.Millisecond),
+	)
+
+	start := time.Now()
+	res, err := background.PollProcesses(context.Background(), []string{"a"}, c)
+	if err != nil {
+		t.Fatalf("PollProcesses() error = %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("PollProcesses() took %v, want it to stop at the 100ms budget", d)
+	}
+	if len(res) != 1 || res[0].Status != background.StatusQueued {
+		t.Fatalf("res = %+v, want single queued result", res)
+	}
+}
@@ -0,0 +1,76 @@
+package background
+
+import (
+	"context"
+	"sync"
+)
+
+// Waker lets something outside the poll loop, such as a webhook receiver,
+// cut the wait between polling rounds short. Pollers of a project take its
+// channel before each round; Wake closes it, so every poller of that
+// project starts its next round at once instead of sleeping out the
+// backoff. Polling itself is unchanged, so a missed event costs latency,
+// not correctness.
+//
+// The zero value is ready to use; a nil *Waker never wakes anyone.
+type Waker struct {
+	mu sync.Mutex
+	ch map[string]chan struct{} // project ID -> channel closed by Wake
+}
+
+// C returns the channel the next Wake(projectID) closes. It returns nil,
+// which blocks forever, on a nil Waker.
+func (w *Waker) C(projectID string) <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ch == nil {
+		w.ch = make(map[string]chan struct{})
+	}
+	ch, ok := w.ch[projectID]
+	if !ok {
+		ch = make(chan struct{})
+		w.ch[projectID] = ch
+	}
+	return ch
+}
+
+// Wake wakes the pollers of projectID, or of every project when projectID
+// is empty, and reports whether any channel was waiting.
+func (w *Waker) Wake(projectID string) bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	woke := false
+	for id, ch := range w.ch {
+		if projectID == "" || id == projectID {
+			close(ch)
+			delete(w.ch, id)
+			woke = true
+		}
+	}
+	return woke
+}
+
+// wakeContext returns a child of ctx that is canceled when wake is closed.
+// With a nil wake it is a plain cancelable child.
+func wakeContext(ctx context.Context, wake <-chan struct{}) (context.Context, context.CancelFunc) {
+	wctx, cancel := context.WithCancel(ctx)
+	if wake == nil {
+		return wctx, cancel
+	}
+	go func() {
+		select {
+		case <-wake:
+			cancel()
+		case <-wctx.Done():
+		}
+	}()
+	return wctx, cancel
+}
@@ -0,0 +1,112 @@
+package background_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+)
+
+func TestWaker(t *testing.T) {
+	t.Parallel()
+
+	var w background.Waker
+	if w.Wake("proj") {
+		t.Fatal("Wake() with no waiters = true, want false")
+	}
+
+	a, b := w.C("proj"), w.C("other")
+	if w.C("proj") != a {
+		t.Fatal("C() returned a new channel before any Wake")
+	}
+	if !w.Wake("proj") {
+		t.Fatal("Wake(proj) = false, want true")
+	}
+	select {
+	case <-a:
+	default:
+		t.Fatal("proj channel not closed by Wake(proj)")
+	}
+	select {
+	case <-b:
+		t.Fatal("other channel closed by Wake(proj)")
+	default:
+	}
+	if w.C("proj") == a {
+		t.Fatal("C() returned the closed channel after Wake")
+	}
+
+	if !w.Wake("") {
+		t.Fatal(`Wake("") = false, want true`)
+	}
+	select {
+	case <-b:
+	default:
+		t.Fatal(`other channel not closed by Wake("")`)
+	}
+
+	var nilWaker *background.Waker
+	if nilWaker.C("proj") != nil || nilWaker.Wake("proj") {
+		t.Fatal("nil Waker should have no channel and wake nothing")
+	}
+}
+
+// wakeSource is a client's poll config with long waits and Wake set.
+type wakeSource struct {
+	cfg background.Config
+}
+
+func (s wakeSource) PollConfig() background.Config { return s.cfg }
+
+func TestPollProcesses_WakeEndsWaitEarly(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		status := "queued"
+		if hits.Add(1) > 1 {
+			status = "finished"
+		}
+		_, _ = w.Write([]byte(`{"process":{"process_id":"p1","status":"` + status + `"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj", client.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waker := new(background.Waker)
+	cfg := c.PollConfig()
+	cfg.InitialWait, cfg.MaxWait = time.Hour, 2*time.Hour
+	cfg.Wake = func() <-chan struct{} { return waker.C("proj") }
+
+	done := make(chan []background.QueuedProcess, 1)
+	go func() {
+		procs, _ := background.PollProcesses(context.Background(), []string{"p1"}, wakeSource{cfg})
+		done <- procs
+	}()
+
+	tick := time.NewTicker(5 * time.Millisecond)
+	defer tick.Stop()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case procs := <-done:
+			if len(procs) != 1 || procs[0].Status != background.StatusFinished {
+				t.Fatalf("procs = %+v, want p1 finished", procs)
+			}
+			return
+		case <-tick.C:
+			if hits.Load() > 0 {
+				waker.Wake("proj")
+			}
+		case <-timeout:
+			t.Fatal("PollProcesses still sleeping after Wake")
+		}
+	}
+}
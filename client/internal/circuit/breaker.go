@@ -0,0 +1,149 @@
+// Package circuit provides the circuit breaker shared by every request a
+// client sends. After threshold consecutive failures it opens and rejects
+// requests until cooldown has passed; then a single probe request is let
+// through, and its outcome closes the breaker again or reopens it.
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOpen is matched (via errors.Is) by the error of a request rejected
+// because the breaker is open.
+var ErrOpen = errors.New("circuit breaker open")
+
+// State is the state of a Breaker.
+type State int
+
+const (
+	// Closed lets every request through.
+	Closed State = iota
+	// Open rejects requests until the cooldown has passed.
+	Open
+	// HalfOpen lets one probe request through.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Outcome is how a request that was let through ended.
+type Outcome int
+
+const (
+	// Ignored says nothing about the server, e.g. the caller canceled; it
+	// leaves the failure count alone.
+	Ignored Outcome = iota
+	// Success resets the failure count and closes a half-open breaker.
+	Success
+	// Failure counts towards the threshold and reopens a half-open breaker.
+	Failure
+)
+
+// Breaker is a consecutive-failure circuit breaker. A nil *Breaker lets
+// everything through. It is safe for concurrent use.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// New returns a breaker that opens after threshold consecutive failures
+// and stays open for cooldown.
+func New(threshold int, cooldown time.Duration) (*Breaker, error) {
+	if threshold < 1 {
+		return nil, errors.New("threshold must be at least 1")
+	}
+	if cooldown <= 0 {
+		return nil, errors.New("cooldown must be positive")
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown}, nil
+}
+
+// Allow reports whether a request may be sent. If it may, done must be
+// called exactly once with the request's outcome; otherwise the error
+// matches ErrOpen and says when the next probe is allowed.
+func (b *Breaker) Allow() (done func(Outcome), err error) {
+	if b == nil {
+		return func(Outcome) {}, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if wait := b.cooldown - time.Since(b.openedAt); wait > 0 {
+			return nil, fmt.Errorf("%w: retry in %s", ErrOpen, wait.Round(time.Millisecond))
+		}
+		b.state = HalfOpen
+		b.probing = false
+		fallthrough
+	case HalfOpen:
+		if b.probing {
+			return nil, fmt.Errorf("%w: probe request in flight", ErrOpen)
+		}
+		b.probing = true
+		return b.doneFunc(true), nil
+	}
+	return b.doneFunc(false), nil
+}
+
+func (b *Breaker) doneFunc(probe bool) func(Outcome) {
+	var once sync.Once
+	return func(o Outcome) {
+		once.Do(func() { b.record(o, probe) })
+	}
+}
+
+func (b *Breaker) record(o Outcome, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+	switch o {
+	case Success:
+		b.failures = 0
+		if probe {
+			b.state = Closed
+		}
+	case Failure:
+		b.failures++
+		if probe || (b.state == Closed && b.failures >= b.threshold) {
+			b.state = Open
+			b.openedAt = time.Now()
+		}
+	}
+}
+
+// State returns the current state. An open breaker whose cooldown has
+// passed reports HalfOpen: the next request will be the probe.
+func (b *Breaker) State() State {
+	if b == nil {
+		return Closed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && time.Since(b.openedAt) >= b.cooldown {
+		return HalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,126 @@
+package circuit_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/circuit"
+)
+
+func allow(t *testing.T, b *circuit.Breaker) func(circuit.Outcome) {
+	t.Helper()
+	done, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	return done
+}
+
+func TestNew_Validates(t *testing.T) {
+	t.Parallel()
+
+	if _, err := circuit.New(0, time.Second); err == nil {
+		t.Fatal("New(0, 1s) error = nil, want error")
+	}
+	if _, err := circuit.New(1, 0); err == nil {
+		t.Fatal("New(1, 0) error = nil, want error")
+	}
+}
+
+func TestBreaker_NilAllowsEverything(t *testing.T) {
+	t.Parallel()
+
+	var b *circuit.Breaker
+	for range 3 {
+		allow(t, b)(circuit.Failure)
+	}
+	if got := b.State(); got != circuit.Closed {
+		t.Fatalf("State() = %v, want closed", got)
+	}
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	b, err := circuit.New(3, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allow(t, b)(circuit.Failure)
+	allow(t, b)(circuit.Failure)
+	allow(t, b)(circuit.Success) // resets the count
+	allow(t, b)(circuit.Failure)
+	allow(t, b)(circuit.Ignored) // neither resets nor counts
+	allow(t, b)(circuit.Failure)
+	if got := b.State(); got != circuit.Closed {
+		t.Fatalf("State() after 2 failures = %v, want closed", got)
+	}
+
+	allow(t, b)(circuit.Failure)
+	if got := b.State(); got != circuit.Open {
+		t.Fatalf("State() after 3 failures = %v, want open", got)
+	}
+	if _, err := b.Allow(); !errors.Is(err, circuit.ErrOpen) {
+		t.Fatalf("Allow() when open error = %v, want ErrOpen", err)
+	}
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	t.Parallel()
+
+	const cooldown = 20 * time.Millisecond
+	b, err := circuit.New(1, cooldown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allow(t, b)(circuit.Failure)
+
+	time.Sleep(cooldown + 5*time.Millisecond)
+	if got := b.State(); got != circuit.HalfOpen {
+		t.Fatalf("State() after cooldown = %v, want half-open", got)
+	}
+
+	// Only one probe at a time.
+	probe := allow(t, b)
+	if _, err := b.Allow(); !errors.Is(err, circuit.ErrOpen) {
+		t.Fatalf("Allow() during probe error = %v, want ErrOpen", err)
+	}
+
+	// A failed probe reopens for another cooldown.
+	probe(circuit.Failure)
+	probe(circuit.Success) // done is idempotent
+	if got := b.State(); got != circuit.Open {
+		t.Fatalf("State() after failed probe = %v, want open", got)
+	}
+
+	time.Sleep(cooldown + 5*time.Millisecond)
+
+	// An ignored probe frees the slot without deciding anything.
+	allow(t, b)(circuit.Ignored)
+	if got := b.State(); got != circuit.HalfOpen {
+		t.Fatalf("State() after ignored probe = %v, want half-open", got)
+	}
+
+	allow(t, b)(circuit.Success)
+	if got := b.State(); got != circuit.Closed {
+		t.Fatalf("State() after successful probe = %v, want closed", got)
+	}
+	allow(t, b)(circuit.Success)
+}
+
+func TestState_String(t *testing.T) {
+	t.Parallel()
+
+	for s, want := range map[circuit.State]string{
+		circuit.Closed:    "closed",
+		circuit.Open:      "open",
+		circuit.HalfOpen:  "half-open",
+		circuit.State(42): "State(42)",
+	} {
+		if got := s.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", int(s), got, want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package ratelimit provides the token-bucket limiter shared by every request
+// a client sends, and the semaphore capping how many of them run at once.
+// Limiter waiters are served in two tiers: High (user-initiated calls)
+// always goes before Low (background polling), FIFO within a tier.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Priority selects the tier a request waits in.
+type Priority int
+
+const (
+	// High is used for user-initiated API calls.
+	High Priority = iota
+	// Low is used for background traffic such as process polling.
+	Low
+)
+
+type waiter struct {
+	ch      chan struct{}
+	granted bool
+}
+
+// Limiter is a token bucket refilled at a fixed rate up to burst tokens.
+// A nil *Limiter never blocks. It is safe for concurrent use.
+type Limiter struct {
+	mu       sync.Mutex
+	perToken time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+	queues   [2][]*waiter
+	stop     func() bool // cancels the pending dispatch, nil if none
+}
+
+// New returns a limiter allowing perSecond requests on average with bursts
+// of up to burst requests. The bucket starts full.
+func New(perSecond float64, burst int) (*Limiter, error) {
+	if perSecond <= 0 {
+		return nil, errors.New("perSecond must be positive")
+	}
+	if burst < 1 {
+		return nil, errors.New("burst must be at least 1")
+	}
+	return &Limiter{
+		perToken: time.Duration(float64(time.Second) / perSecond),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}, nil
+}
+
+// Wait blocks until a token is available for priority p or ctx is done.
+// High waiters are always served before Low ones.
+func (l *Limiter) Wait(ctx context.Context, p Priority) error {
+	if l == nil {
+		return nil
+	}
+	if p != High {
+		p = Low
+	}
+
+	l.mu.Lock()
+	l.refill()
+	if l.tokens >= 1 && l.aheadOf(p) == 0 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{ch: make(chan struct{})}
+	l.queues[p] = append(l.queues[p], w)
+	l.dispatchLocked()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ch:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.granted {
+		// Lost the race with dispatch: hand the token to the next waiter.
+		l.tokens++
+		l.dispatchLocked()
+		return ctx.Err()
+	}
+	if i := slices.Index(l.queues[p], w); i >= 0 {
+		l.queues[p] = slices.Delete(l.queues[p], i, i+1)
+	}
+	return ctx.Err()
+}
+
+// Headroom returns the tokens available right now, minus queued waiters
+// (negative when requests are waiting). A nil *Limiter reports 0.
+func (l *Limiter) Headroom() float64 {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill()
+	return l.tokens - float64(l.aheadOf(Low))
+}
+
+// aheadOf counts queued waiters that must be served before a new p waiter.
+func (l *Limiter) aheadOf(p Priority) int {
+	if p == High {
+		return len(l.queues[High])
+	}
+	return len(l.queues[High]) + len(l.queues[Low])
+}
+
+func (l *Limiter) refill() {
+	t := time.Now()
+	if elapsed := t.Sub(l.last); elapsed > 0 {
+		l.tokens = min(l.burst, l.tokens+float64(elapsed)/float64(l.perToken))
+	}
+	l.last = t
+}
+
+// dispatchLocked grants available tokens to queued waiters, High first, and
+// arms a timer for the next token if anyone is still waiting.
+func (l *Limiter) dispatchLocked() {
+	l.refill()
+	for l.tokens >= 1 {
+		w := l.popLocked()
+		if w == nil {
+			break
+		}
+		l.tokens--
+		w.granted = true
+		close(w.ch)
+	}
+	l.scheduleLocked()
+}
+
+func (l *Limiter) popLocked() *waiter {
+	for p := range l.queues {
+		if len(l.queues[p]) > 0 {
+			w := l.queues[p][0]
+			l.queues[p] = l.queues[p][1:]
+			return w
+		}
+	}
+	return nil
+}
+
+func (l *Limiter) scheduleLocked() {
+	if l.stop != nil {
+		l.stop()
+		l.stop = nil
+	}
+	if l.aheadOf(Low) == 0 {
+		return
+	}
+
+	wait := time.Duration((1 - l.tokens) * float64(l.perToken))
+	l.stop = time.AfterFunc(max(wait, time.Millisecond), func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.stop = nil
+		l.dispatchLocked()
+	}).Stop
+}
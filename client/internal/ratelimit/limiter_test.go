@@ -0,0 +1,160 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/ratelimit"
+)
+
+func TestNew_Validates(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ratelimit.New(0, 1); err == nil {
+		t.Fatal("New(0, 1) error = nil, want error")
+	}
+	if _, err := ratelimit.New(1, 0); err == nil {
+		t.Fatal("New(1, 0) error = nil, want error")
+	}
+}
+
+func TestLimiter_NilNeverBlocks(t *testing.T) {
+	t.Parallel()
+
+	var l *ratelimit.Limiter
+	if err := l.Wait(context.Background(), ratelimit.Low); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+}
+
+func TestLimiter_BurstThenPaced(t *testing.T) {
+	t.Parallel()
+
+	l, err := ratelimit.New(50, 3) // one token per 20ms
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(context.Background(), ratelimit.High); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if d := time.Since(start); d > 15*time.Millisecond {
+		t.Fatalf("burst took %v, want immediate", d)
+	}
+
+	for range 3 {
+		if err := l.Wait(context.Background(), ratelimit.High); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("6 waits took %v, want >= ~60ms after the burst", d)
+	}
+}
+
+func TestLimiter_HighBeforeLow(t *testing.T) {
+	t.Parallel()
+
+	l, err := ratelimit.New(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Drain the bucket so everyone below has to queue.
+	if err := l.Wait(context.Background(), ratelimit.High); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	run := func(name string, p ratelimit.Priority) {
+		defer wg.Done()
+		if err := l.Wait(context.Background(), p); err != nil {
+			t.Errorf("Wait(%s) error = %v", name, err)
+			return
+		}
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+	}
+
+	wg.Add(2)
+	go run("low1", ratelimit.Low)
+	go run("low2", ratelimit.Low)
+	time.Sleep(2 * time.Millisecond) // let the low waiters queue first
+	wg.Add(1)
+	go run("high", ratelimit.High)
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != "high" {
+		t.Fatalf("order = %v, want high served first", order)
+	}
+}
+
+func TestLimiter_ContextCancel(t *testing.T) {
+	t.Parallel()
+
+	l, err := ratelimit.New(1, 1) // next token in 1s
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l.Wait(context.Background(), ratelimit.High)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx, ratelimit.Low); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() error = %v, want DeadlineExceeded", err)
+	}
+
+	// The canceled waiter must not block later callers in its tier.
+	l2, _ := ratelimit.New(200, 1)
+	_ = l2.Wait(context.Background(), ratelimit.High)
+	cctx, ccancel := context.WithCancel(context.Background())
+	ccancel()
+	_ = l2.Wait(cctx, ratelimit.Low)
+
+	done := make(chan error, 1)
+	go func() { done <- l2.Wait(context.Background(), ratelimit.Low) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait() blocked behind a canceled waiter")
+	}
+}
+
+func TestLimiter_Headroom(t *testing.T) {
+	t.Parallel()
+
+	var nilLimiter *ratelimit.Limiter
+	if got := nilLimiter.Headroom(); got != 0 {
+		t.Fatalf("nil Headroom() = %v, want 0", got)
+	}
+
+	l, err := ratelimit.New(0.001, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Headroom(); got < 2.99 || got > 3 {
+		t.Fatalf("Headroom() = %v, want 3 on a full bucket", got)
+	}
+	for range 3 {
+		if err := l.Wait(context.Background(), ratelimit.High); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := l.Headroom(); got >= 1 {
+		t.Fatalf("Headroom() = %v after draining the bucket", got)
+	}
+}
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Semaphore caps how many requests are in flight at once. A nil *Semaphore
+// never blocks. It is safe for concurrent use.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// NewSemaphore returns a semaphore with n slots.
+func NewSemaphore(n int) (*Semaphore, error) {
+	if n < 1 {
+		return nil, errors.New("concurrency must be at least 1")
+	}
+	return &Semaphore{slots: make(chan struct{}, n)}, nil
+}
+
+// Acquire blocks until a slot is free or ctx is done. release gives the
+// slot back; it is safe to call more than once.
+func (s *Semaphore) Acquire(ctx context.Context) (release func(), err error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return func() {}, context.Cause(ctx)
+	}
+	return sync.OnceFunc(func() { <-s.slots }), nil
+}
+
+// InFlight returns how many slots are taken.
+func (s *Semaphore) InFlight() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.slots)
+}
+
+// Cap returns the number of slots; zero for a nil semaphore.
+func (s *Semaphore) Cap() int {
+	if s == nil {
+		return 0
+	}
+	return cap(s.slots)
+}
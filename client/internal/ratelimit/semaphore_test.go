@@ -0,0 +1,73 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/ratelimit"
+)
+
+func TestNewSemaphore_Validates(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ratelimit.NewSemaphore(0); err == nil {
+		t.Fatal("NewSemaphore(0) error = nil, want error")
+	}
+}
+
+func TestSemaphore_NilNeverBlocks(t *testing.T) {
+	t.Parallel()
+
+	var s *ratelimit.Semaphore
+	release, err := s.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	release()
+	if s.InFlight() != 0 || s.Cap() != 0 {
+		t.Fatalf("InFlight = %d, Cap = %d; want 0, 0", s.InFlight(), s.Cap())
+	}
+}
+
+func TestSemaphore_BlocksWhenFull(t *testing.T) {
+	t.Parallel()
+
+	s, err := ratelimit.NewSemaphore(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	r1, _ := s.Acquire(ctx)
+	r2, _ := s.Acquire(ctx)
+	if s.InFlight() != 2 || s.Cap() != 2 {
+		t.Fatalf("InFlight = %d, Cap = %d; want 2, 2", s.InFlight(), s.Cap())
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(tctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() on a full semaphore: err = %v, want DeadlineExceeded", err)
+	}
+
+	got := make(chan struct{})
+	go func() {
+		release, err := s.Acquire(ctx)
+		if err == nil {
+			release()
+		}
+		close(got)
+	}()
+	r1()
+	r1() // a second release must not free another slot
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("waiter not woken after release")
+	}
+	if n := s.InFlight(); n != 1 {
+		t.Fatalf("InFlight = %d, want 1", n)
+	}
+	r2()
+}
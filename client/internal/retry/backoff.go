@@ -2,33 +2,48 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/bodrovis/lokex/v2/internal/apierr"
+	"github.com/bodrovis/lokex/v2/internal/telemetry"
 	"github.com/bodrovis/lokex/v2/internal/utils"
 )
 
 var jitteredBackoff = apierr.JitteredBackoff
 
-// WithExpBackoff runs op with retries using exponential backoff + jitter.
-// MaxRetries is the number of retries after the initial attempt.
-// If isRetryable is nil, apierr.IsRetryable is used.
+// WithExpBackoff runs op with retries using exponential backoff + jitter
+// (see Config.Jitter).
+// cfg.MaxRetries is the number of retries after the initial attempt. op gets
+// ctx, carrying the attempt's span when cfg.Tracer is set.
+// While retries remain, the policy decides whether and when to retry:
+// cfg.Policy if set, else an isRetryable callback (with the default delay)
+// if given, else DefaultPolicy.
 // If ctx is canceled or its deadline is exceeded, ctx.Err() is returned
-// wrapped with label context when label is provided.
+// wrapped with cfg.Label context when a label is provided. A spent
+// cfg.Budget ends it with ErrRetryBudgetExhausted and the last error.
 func WithExpBackoff(
 	ctx context.Context,
-	label string,
-	maxRetries int,
-	initialBackoff time.Duration,
-	maxBackoff time.Duration,
-	op func(attempt int) error,
+	cfg Config,
+	op func(ctx context.Context, attempt int) error,
 	isRetryable func(error) bool,
 ) error {
-	isRetryable = resolveRetryable(isRetryable)
+	policy := resolvePolicy(cfg.Policy, isRetryable)
+
+	var deadline time.Time
+	bctx := ctx
+	if cfg.Budget > 0 {
+		deadline = time.Now().Add(cfg.Budget)
+		var cancel context.CancelFunc
+		bctx, cancel = context.WithDeadlineCause(ctx, deadline, ErrRetryBudgetExhausted)
+		defer cancel()
+	}
 
+	label, maxRetries, maxBackoff := cfg.Label, cfg.MaxRetries, cfg.MaxBackoff
 	totalAttempts := maxRetries + 1
-	backoff := initialBackoff
+	backoff := cfg.InitialBackoff
+	var prevDelay time.Duration
 
 	timer := newStoppedTimer()
 	defer stopAndDrainTimer(timer)
@@ -38,20 +53,54 @@ func WithExpBackoff(
 			return err
 		}
 
-		err := op(attempt)
+		actx, span := telemetry.Start(bctx, cfg.Tracer, telemetry.SpanAttempt,
+			telemetry.AttrRetryLabel.String(label),
+			telemetry.AttrRetryAttempt.Int(attempt),
+		)
+		err := runAttempt(actx, cfg.AttemptTimeout, attempt, op)
 		if err == nil {
+			telemetry.End(span, nil)
 			return nil
 		}
 
 		if err := contextAttemptErr(ctx, label, attempt, totalAttempts); err != nil {
+			telemetry.End(span, err)
 			return err
 		}
+		if bctx.Err() != nil {
+			telemetry.End(span, err)
+			return wrapBudgetErr(label, attempt, totalAttempts, cfg.Budget, err)
+		}
 
-		if shouldStopRetry(attempt, maxRetries, err, isRetryable) {
+		if attempt >= maxRetries {
+			telemetry.End(span, err)
 			return wrapErr(label, attempt, totalAttempts, err)
 		}
 
-		delay := computeRetryDelay(backoff, maxBackoff)
+		prevDelay = cfg.Jitter.delay(backoff, cfg.InitialBackoff, prevDelay, maxBackoff)
+		delay, ok := policy.Retry(Attempt{
+			Label:      label,
+			Method:     cfg.Method,
+			Path:       cfg.Path,
+			Retry:      attempt,
+			MaxRetries: maxRetries,
+			Err:        err,
+			Delay:      honorRetryAfter(prevDelay, err, cfg.MaxRetryAfter),
+		})
+		if !ok {
+			telemetry.End(span, err)
+			return wrapErr(label, attempt, totalAttempts, err)
+		}
+		delay = max(delay, 0)
+		if !deadline.IsZero() && time.Until(deadline) <= delay {
+			telemetry.End(span, err)
+			return wrapBudgetErr(label, attempt, totalAttempts, cfg.Budget, err)
+		}
+		span.SetAttributes(telemetry.AttrRetryDelay.Int64(delay.Milliseconds()))
+		telemetry.End(span, err)
+		if cfg.Metrics != nil {
+			cfg.Metrics.Retry(label, attempt+1, delay)
+		}
 		if err := utils.SleepWithTimer(ctx, timer, delay); err != nil {
 			return wrapCtxErr(label, attempt, totalAttempts, err)
 		}
@@ -60,6 +109,55 @@ func WithExpBackoff(
 	}
 }
 
+// ErrRetryBudgetExhausted is matched (via errors.Is) by the error of an
+// operation that ran out of Config.Budget. The error also wraps the last
+// attempt's error.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// wrapBudgetErr reports the last attempt's err once the budget is spent.
+func wrapBudgetErr(label string, attempt, total int, budget time.Duration, err error) error {
+	return wrapErr(label, attempt, total, fmt.Errorf("%w (%s): %w", ErrRetryBudgetExhausted, budget, err))
+}
+
+// ErrAttemptTimeout is matched (via errors.Is) by the error of an attempt
+// that ran out of Config.AttemptTimeout.
+var ErrAttemptTimeout = errors.New("attempt timed out")
+
+// attemptTimeoutError reports an attempt cut off by Config.AttemptTimeout.
+// It deliberately doesn't unwrap to context.DeadlineExceeded, which would
+// make it look like the caller's deadline and stop the retries.
+type attemptTimeoutError struct {
+	timeout time.Duration
+	err     error
+}
+
+func (e *attemptTimeoutError) Error() string {
+	return fmt.Sprintf("%s after %s: %v", ErrAttemptTimeout, e.timeout, e.err)
+}
+
+func (e *attemptTimeoutError) Timeout() bool { return true }
+
+func (e *attemptTimeoutError) Is(target error) bool { return target == ErrAttemptTimeout }
+
+// runAttempt calls op, bounded by timeout when positive.
+func runAttempt(
+	ctx context.Context,
+	timeout time.Duration,
+	attempt int,
+	op func(ctx context.Context, attempt int) error,
+) error {
+	if timeout <= 0 {
+		return op(ctx, attempt)
+	}
+	actx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := op(actx, attempt)
+	if err != nil && ctx.Err() == nil && errors.Is(actx.Err(), context.DeadlineExceeded) {
+		return &attemptTimeoutError{timeout: timeout, err: err}
+	}
+	return err
+}
+
 func resolveRetryable(fn func(error) bool) func(error) bool {
 	if fn != nil {
 		return fn
@@ -94,15 +192,6 @@ func contextAttemptErr(
 	return nil
 }
 
-func shouldStopRetry(
-	attempt int,
-	maxRetries int,
-	err error,
-	isRetryable func(error) bool,
-) bool {
-	return !isRetryable(err) || attempt >= maxRetries
-}
-
 func computeRetryDelay(backoff, maxBackoff time.Duration) time.Duration {
 	delay := jitteredBackoff(backoff)
 	if delay <= 0 {
@@ -114,6 +203,18 @@ func computeRetryDelay(backoff, maxBackoff time.Duration) time.Duration {
 	return delay
 }
 
+// honorRetryAfter raises delay to the server's Retry-After (capped at
+// maxRetryAfter), which may exceed the backoff cap.
+func honorRetryAfter(delay time.Duration, err error, maxRetryAfter time.Duration) time.Duration {
+	if maxRetryAfter <= 0 {
+		return delay
+	}
+	if d, ok := apierr.RetryAfter(err); ok {
+		return max(delay, min(d, maxRetryAfter))
+	}
+	return delay
+}
+
 func nextBackoff(backoff, maxBackoff time.Duration) time.Duration {
 	backoff *= 2
 	if backoff > maxBackoff {
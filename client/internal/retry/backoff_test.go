@@ -3,10 +3,12 @@ package retry_test
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/bodrovis/lokex/v2/client/internal/retry"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
 )
 
 func TestWithExpBackoff(t *testing.T) {
@@ -21,11 +23,13 @@ func TestWithExpBackoff(t *testing.T) {
 		called := false
 		err := retry.WithExpBackoff(
 			ctx,
-			"download bundle",
-			2,
-			time.Millisecond,
-			10*time.Millisecond,
-			func(_ int) error {
+			retry.Config{
+				Label:          "download bundle",
+				MaxRetries:     2,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     10 * time.Millisecond,
+			},
+			func(_ context.Context, _ int) error {
 				called = true
 				return nil
 			},
@@ -47,6 +51,71 @@ func TestWithExpBackoff(t *testing.T) {
 	})
 }
 
+func TestHonorRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	limited := func(status int, header string) error {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if header != "" {
+			resp.Header.Set("Retry-After", header)
+		}
+		return &apierr.APIError{Status: status, Resp: resp}
+	}
+
+	tests := []struct {
+		name          string
+		delay         time.Duration
+		err           error
+		maxRetryAfter time.Duration
+		want          time.Duration
+	}{
+		{"429 raises delay", time.Second, limited(http.StatusTooManyRequests, "3"), time.Minute, 3 * time.Second},
+		{"503 raises delay", time.Second, limited(http.StatusServiceUnavailable, "2"), time.Minute, 2 * time.Second},
+		{"capped", time.Second, limited(http.StatusTooManyRequests, "120"), 10 * time.Second, 10 * time.Second},
+		{"shorter header keeps backoff", 4 * time.Second, limited(http.StatusTooManyRequests, "1"), time.Minute, 4 * time.Second},
+		{"disabled", time.Second, limited(http.StatusTooManyRequests, "30"), 0, time.Second},
+		{"no header", time.Second, limited(http.StatusTooManyRequests, ""), time.Minute, time.Second},
+		{"other status", time.Second, limited(http.StatusBadGateway, "30"), time.Minute, time.Second},
+		{"not an API error", time.Second, errors.New("boom"), time.Minute, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := retry.ExportHonorRetryAfter(tt.delay, tt.err, tt.maxRetryAfter); got != tt.want {
+				t.Fatalf("honorRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithExpBackoff_WaitsForRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"5"}}}
+	limited := &apierr.APIError{Status: http.StatusTooManyRequests, Resp: resp}
+
+	var times []time.Time
+	err := retry.WithExpBackoff(context.Background(),
+		retry.Config{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetryAfter: 80 * time.Millisecond},
+		func(_ context.Context, attempt int) error {
+			times = append(times, time.Now())
+			if attempt == 0 {
+				return limited
+			}
+			return nil
+		}, nil)
+	if err != nil {
+		t.Fatalf("WithExpBackoff() error = %v", err)
+	}
+	if len(times) != 2 {
+		t.Fatalf("attempts = %d, want 2", len(times))
+	}
+	if gap := times[1].Sub(times[0]); gap < 80*time.Millisecond {
+		t.Fatalf("waited %v between attempts, want at least the capped Retry-After (80ms)", gap)
+	}
+}
+
 func TestResolveRetryable(t *testing.T) {
 	t.Parallel()
 
@@ -242,3 +311,153 @@ func TestWrapCtxErr(t *testing.T) {
 		}
 	})
 }
+
+func TestWithExpBackoff_AttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	cfg := retry.Config{
+		Label:          "request",
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		AttemptTimeout: 20 * time.Millisecond,
+	}
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	t.Run("timed out attempt is retried", func(t *testing.T) {
+		t.Parallel()
+		calls := 0
+		err := retry.WithExpBackoff(context.Background(), cfg, func(ctx context.Context, attempt int) error {
+			calls++
+			if attempt == 0 {
+				return slow(ctx)
+			}
+			return nil
+		}, nil)
+		if err != nil || calls != 2 {
+			t.Fatalf("err = %v, calls = %d; want success on the second attempt", err, calls)
+		}
+	})
+
+	t.Run("every attempt times out", func(t *testing.T) {
+		t.Parallel()
+		calls := 0
+		err := retry.WithExpBackoff(context.Background(), cfg, func(ctx context.Context, _ int) error {
+			calls++
+			return slow(ctx)
+		}, nil)
+		if !errors.Is(err, retry.ErrAttemptTimeout) {
+			t.Fatalf("err = %v, want ErrAttemptTimeout", err)
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v matches context.DeadlineExceeded", err)
+		}
+		if calls != 3 {
+			t.Fatalf("calls = %d, want 3", calls)
+		}
+	})
+
+	t.Run("caller deadline is not an attempt timeout", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		cfg := cfg
+		cfg.AttemptTimeout = time.Second
+		err := retry.WithExpBackoff(ctx, cfg, func(ctx context.Context, _ int) error {
+			return slow(ctx)
+		}, nil)
+		if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, retry.ErrAttemptTimeout) {
+			t.Fatalf("err = %v, want the caller's DeadlineExceeded", err)
+		}
+	})
+}
+
+func TestWithExpBackoff_Budget(t *testing.T) {
+	t.Parallel()
+
+	errBusy := errors.New("busy")
+
+	t.Run("stops before a wait that overruns the budget", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		start := time.Now()
+		err := retry.WithExpBackoff(
+			context.Background(),
+			retry.Config{
+				Label:          "request",
+				MaxRetries:     10,
+				InitialBackoff: time.Second,
+				MaxBackoff:     time.Second,
+				Budget:         100 * time.Millisecond,
+			},
+			func(context.Context, int) error {
+				calls++
+				return errBusy
+			},
+			func(error) bool { return true },
+		)
+		if !errors.Is(err, retry.ErrRetryBudgetExhausted) || !errors.Is(err, errBusy) {
+			t.Fatalf("err = %v, want ErrRetryBudgetExhausted wrapping the last error", err)
+		}
+		if calls != 1 {
+			t.Fatalf("calls = %d, want 1", calls)
+		}
+		if took := time.Since(start); took > 500*time.Millisecond {
+			t.Fatalf("took %v, want an immediate failure", took)
+		}
+	})
+
+	t.Run("retries while the budget lasts", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		err := retry.WithExpBackoff(
+			context.Background(),
+			retry.Config{
+				MaxRetries:     3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+				Budget:         time.Minute,
+			},
+			func(context.Context, int) error {
+				if calls++; calls < 3 {
+					return errBusy
+				}
+				return nil
+			},
+			func(error) bool { return true },
+		)
+		if err != nil || calls != 3 {
+			t.Fatalf("err = %v, calls = %d; want success on attempt 3", err, calls)
+		}
+	})
+
+	t.Run("cancels an attempt still running when the budget is spent", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.WithExpBackoff(
+			context.Background(),
+			retry.Config{
+				MaxRetries:     5,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+				Budget:         50 * time.Millisecond,
+			},
+			func(ctx context.Context, _ int) error {
+				<-ctx.Done()
+				if !errors.Is(context.Cause(ctx), retry.ErrRetryBudgetExhausted) {
+					t.Errorf("cause = %v, want ErrRetryBudgetExhausted", context.Cause(ctx))
+				}
+				return ctx.Err()
+			},
+			func(error) bool { return true },
+		)
+		if !errors.Is(err, retry.ErrRetryBudgetExhausted) {
+			t.Fatalf("err = %v, want ErrRetryBudgetExhausted", err)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"io"
 	"time"
 )
@@ -11,23 +12,23 @@ type ExportRetryBodyFactory interface {
 
 func ExportMakeAttemptOp(
 	body io.Reader,
-	op func(attempt int, body io.Reader) error,
-) (func(attempt int) error, func(), error) {
+	op func(ctx context.Context, attempt int, body io.Reader) error,
+) (func(ctx context.Context, attempt int) error, func(), error) {
 	return makeAttemptOp(body, op)
 }
 
 func ExportAttemptOpFromReadSeeker(
 	body io.Reader,
 	rs io.ReadSeeker,
-	op func(attempt int, body io.Reader) error,
-) (func(attempt int) error, func()) {
+	op func(ctx context.Context, attempt int, body io.Reader) error,
+) (func(ctx context.Context, attempt int) error, func()) {
 	return attemptOpFromReadSeeker(body, rs, op)
 }
 
 func ExportAttemptOpFromBufferedBody(
 	body io.Reader,
-	op func(attempt int, body io.Reader) error,
-) (func(attempt int) error, error) {
+	op func(ctx context.Context, attempt int, body io.Reader) error,
+) (func(ctx context.Context, attempt int) error, error) {
 	return attemptOpFromBufferedBody(body, op)
 }
 
@@ -39,6 +40,10 @@ func ExportComputeRetryDelay(backoff, maxBackoff time.Duration) time.Duration {
 	return computeRetryDelay(backoff, maxBackoff)
 }
 
+func ExportHonorRetryAfter(delay time.Duration, err error, maxRetryAfter time.Duration) time.Duration {
+	return honorRetryAfter(delay, err, maxRetryAfter)
+}
+
 func ExportWrapErr(label string, attempt, total int, err error) error {
 	return wrapErr(label, attempt, total, err)
 }
@@ -54,3 +59,7 @@ func ExportSetJitteredBackoffForTest(fn func(time.Duration) time.Duration) func(
 		jitteredBackoff = prev
 	}
 }
+
+func ExportJitterDelay(j Jitter, backoff, initial, prev, maxBackoff time.Duration) time.Duration {
+	return j.delay(backoff, initial, prev, maxBackoff)
+}
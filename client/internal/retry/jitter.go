@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter selects how a retry's delay is randomized around the exponential
+// backoff b. The zero value is JitterDefault.
+type Jitter int
+
+const (
+	// JitterDefault waits a uniform [b/2, 3b/2).
+	JitterDefault Jitter = iota
+	// JitterNone waits exactly b.
+	JitterNone
+	// JitterFull waits a uniform [0, b).
+	JitterFull
+	// JitterEqual waits a uniform [b/2, b).
+	JitterEqual
+	// JitterDecorrelated waits a uniform [initial, 3*previous delay),
+	// spreading workers that failed together further apart with every
+	// retry. The exponential backoff itself is not used.
+	JitterDecorrelated
+)
+
+// String returns the strategy's name.
+func (j Jitter) String() string {
+	switch j {
+	case JitterDefault:
+		return "default"
+	case JitterNone:
+		return "none"
+	case JitterFull:
+		return "full"
+	case JitterEqual:
+		return "equal"
+	case JitterDecorrelated:
+		return "decorrelated"
+	default:
+		return fmt.Sprintf("Jitter(%d)", int(j))
+	}
+}
+
+// Valid reports whether j is one of the defined strategies.
+func (j Jitter) Valid() bool {
+	return j >= JitterDefault && j <= JitterDecorrelated
+}
+
+// delay returns the wait before the next retry, at least 1ms and at most
+// maxBackoff. prev is the previous delay, zero before the first retry.
+func (j Jitter) delay(backoff, initial, prev, maxBackoff time.Duration) time.Duration {
+	var d time.Duration
+	switch j {
+	case JitterNone:
+		d = backoff
+	case JitterFull:
+		d = randBetween(0, backoff)
+	case JitterEqual:
+		d = randBetween(backoff/2, backoff)
+	case JitterDecorrelated:
+		if prev <= 0 {
+			prev = initial
+		}
+		d = randBetween(initial, 3*prev)
+	default:
+		return computeRetryDelay(backoff, maxBackoff)
+	}
+	return min(max(d, time.Millisecond), maxBackoff)
+}
+
+// randBetween returns a uniform duration in [lo, hi), or lo if the range is
+// empty.
+func randBetween(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + rand.N(hi-lo)
+}
@@ -0,0 +1,73 @@
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/retry"
+)
+
+func TestJitterDelay(t *testing.T) {
+	t.Parallel()
+
+	const (
+		initial = 100 * time.Millisecond
+		b       = 400 * time.Millisecond
+		maxB    = 10 * time.Second
+	)
+	tests := []struct {
+		j      retry.Jitter
+		prev   time.Duration
+		lo, hi time.Duration // [lo, hi]
+	}{
+		{retry.JitterDefault, 0, b / 2, 3 * b / 2},
+		{retry.JitterNone, 0, b, b},
+		{retry.JitterFull, 0, time.Millisecond, b},
+		{retry.JitterEqual, 0, b / 2, b},
+		{retry.JitterDecorrelated, 0, initial, 3 * initial},
+		{retry.JitterDecorrelated, time.Second, initial, 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.j.String(), func(t *testing.T) {
+			t.Parallel()
+
+			seen := map[time.Duration]bool{}
+			for range 200 {
+				d := retry.ExportJitterDelay(tt.j, b, initial, tt.prev, maxB)
+				if d < tt.lo || d > tt.hi {
+					t.Fatalf("delay = %v, want within [%v, %v]", d, tt.lo, tt.hi)
+				}
+				seen[d] = true
+			}
+			if tt.lo != tt.hi && len(seen) < 2 {
+				t.Fatal("delay is not randomized")
+			}
+		})
+	}
+}
+
+func TestJitterDelay_CappedByMaxBackoff(t *testing.T) {
+	t.Parallel()
+
+	for _, j := range []retry.Jitter{retry.JitterNone, retry.JitterFull, retry.JitterEqual, retry.JitterDecorrelated} {
+		for range 50 {
+			if d := retry.ExportJitterDelay(j, time.Minute, time.Second, time.Minute, 2*time.Second); d > 2*time.Second {
+				t.Fatalf("%v: delay = %v, want <= 2s", j, d)
+			}
+		}
+	}
+}
+
+func TestJitter_StringAndValid(t *testing.T) {
+	t.Parallel()
+
+	if got := retry.JitterDecorrelated.String(); got != "decorrelated" {
+		t.Fatalf("String() = %q", got)
+	}
+	if retry.Jitter(42).Valid() || !retry.JitterNone.Valid() {
+		t.Fatal("Valid() mismatch")
+	}
+	if got := retry.Jitter(42).String(); got != "Jitter(42)" {
+		t.Fatalf("String() = %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+package retry
+
+import "time"
+
+// Attempt describes a failed attempt for a Policy.
+type Attempt struct {
+	Label      string // operation label: "request", "download", ...
+	Method     string // HTTP method of API requests; "" for other operations
+	Path       string // API path of requests (no query); "" for other operations
+	Retry      int    // retries made so far: 0 after the first attempt failed
+	MaxRetries int    // the configured retry cap
+	Err        error  // the attempt's error
+
+	// Delay is the default wait before the next attempt: jittered
+	// exponential backoff, raised to the server's Retry-After.
+	Delay time.Duration
+}
+
+// Policy decides whether a failed attempt is retried and how long to wait
+// first. It is only asked while retries remain under the cap.
+type Policy interface {
+	Retry(a Attempt) (delay time.Duration, retry bool)
+}
+
+// PolicyFunc adapts a function to Policy.
+type PolicyFunc func(a Attempt) (time.Duration, bool)
+
+// Retry calls f(a).
+func (f PolicyFunc) Retry(a Attempt) (time.Duration, bool) { return f(a) }
+
+// retryableFunc is the Policy behind an isRetryable callback: the default
+// delay for errors fn accepts.
+type retryableFunc func(error) bool
+
+func (fn retryableFunc) Retry(a Attempt) (time.Duration, bool) {
+	return a.Delay, fn(a.Err)
+}
+
+// DefaultPolicy retries transient failures (apierr.IsRetryable) after the
+// default delay.
+var DefaultPolicy Policy = retryableFunc(resolveRetryable(nil))
+
+// resolvePolicy picks the policy for one WithExpBackoff call: cfg.Policy,
+// the user's choice (see WithRetryPolicy), wins over an isRetryable
+// callback (a call site's default notion of retryable), which wins over
+// DefaultPolicy.
+func resolvePolicy(p Policy, isRetryable func(error) bool) Policy {
+	if p != nil {
+		return p
+	}
+	if isRetryable != nil {
+		return retryableFunc(isRetryable)
+	}
+	return DefaultPolicy
+}
@@ -0,0 +1,119 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/retry"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func TestWithExpBackoff_Policy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("policy sees attempts and sets delay", func(t *testing.T) {
+		t.Parallel()
+
+		var seen []retry.Attempt
+		cfg := retry.Config{
+			Label:          "request",
+			Method:         http.MethodGet,
+			Path:           "projects/p/keys",
+			MaxRetries:     5,
+			InitialBackoff: time.Hour,
+			MaxBackoff:     time.Hour,
+			Policy: retry.PolicyFunc(func(a retry.Attempt) (time.Duration, bool) {
+				seen = append(seen, a)
+				return -time.Second, a.Retry < 1
+			}),
+		}
+		calls := 0
+		boom := errors.New("boom")
+		err := retry.WithExpBackoff(context.Background(), cfg, func(context.Context, int) error {
+			calls++
+			return boom
+		}, nil)
+		if !errors.Is(err, boom) || err.Error() != "request (attempt 2/6): boom" {
+			t.Fatalf("err = %v", err)
+		}
+		if calls != 2 || len(seen) != 2 {
+			t.Fatalf("calls = %d, policy calls = %d; want 2 and 2", calls, len(seen))
+		}
+		a := seen[1]
+		if a.Label != "request" || a.Method != http.MethodGet || a.Path != "projects/p/keys" ||
+			a.Retry != 1 || a.MaxRetries != 5 || !errors.Is(a.Err, boom) || a.Delay <= 0 {
+			t.Fatalf("attempt = %+v", a)
+		}
+	})
+
+	t.Run("cap still applies", func(t *testing.T) {
+		t.Parallel()
+
+		asked := 0
+		cfg := retry.Config{
+			MaxRetries: 1,
+			Policy: retry.PolicyFunc(func(retry.Attempt) (time.Duration, bool) {
+				asked++
+				return 0, true
+			}),
+		}
+		calls := 0
+		_ = retry.WithExpBackoff(context.Background(), cfg, func(context.Context, int) error {
+			calls++
+			return errors.New("always")
+		}, nil)
+		if calls != 2 || asked != 1 {
+			t.Fatalf("calls = %d, asked = %d; want 2 and 1", calls, asked)
+		}
+	})
+
+	t.Run("policy wins over isRetryable", func(t *testing.T) {
+		t.Parallel()
+
+		asked := 0
+		cfg := retry.Config{
+			MaxRetries: 1,
+			Policy: retry.PolicyFunc(func(retry.Attempt) (time.Duration, bool) {
+				asked++
+				return 0, false
+			}),
+		}
+		calls := 0
+		_ = retry.WithExpBackoff(context.Background(), cfg, func(context.Context, int) error {
+			calls++
+			return errors.New("x")
+		}, func(error) bool { return true })
+		if calls != 1 || asked != 1 {
+			t.Fatalf("calls = %d, asked = %d; want 1 and 1", calls, asked)
+		}
+	})
+
+	t.Run("isRetryable without policy", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := retry.Config{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+		calls := 0
+		_ = retry.WithExpBackoff(context.Background(), cfg, func(context.Context, int) error {
+			calls++
+			return errors.New("x")
+		}, func(error) bool { return true })
+		if calls != 2 {
+			t.Fatalf("calls = %d, want 2", calls)
+		}
+	})
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	t.Parallel()
+
+	d, ok := retry.DefaultPolicy.Retry(retry.Attempt{Err: &apierr.APIError{Status: 503}, Delay: time.Second})
+	if !ok || d != time.Second {
+		t.Fatalf("503: delay=%v retry=%v, want 1s true", d, ok)
+	}
+	if _, ok := retry.DefaultPolicy.Retry(retry.Attempt{Err: &apierr.APIError{Status: 404}}); ok {
+		t.Fatal("404 retried by default")
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"io"
 	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/metrics"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type retryBodyFactory interface {
@@ -18,6 +22,38 @@ type Config struct {
 	MaxRetries     int
 	InitialBackoff time.Duration
 	MaxBackoff     time.Duration
+
+	// Jitter randomizes each delay; the zero value is JitterDefault.
+	Jitter Jitter
+
+	// MaxRetryAfter caps the delay taken from a 429/503 Retry-After header,
+	// which is used as the minimum wait before the next attempt. Zero
+	// ignores the header.
+	MaxRetryAfter time.Duration
+
+	// Tracer, when set, wraps every attempt in a span.
+	Tracer trace.Tracer
+
+	// Metrics, when set, is told about every retry.
+	Metrics metrics.Recorder
+
+	// Policy decides retries, even when WithExpBackoff gets an isRetryable
+	// callback; nil leaves it to the callback or DefaultPolicy.
+	Policy Policy
+
+	// Method and Path identify the API request being retried, for Policy.
+	Method string
+	Path   string
+
+	// AttemptTimeout, when positive, bounds each attempt; an attempt that
+	// runs out fails with ErrAttemptTimeout, which is retryable.
+	AttemptTimeout time.Duration
+
+	// Budget, when positive, caps the whole operation, attempts and
+	// backoff included. Retrying stops with ErrRetryBudgetExhausted as
+	// soon as the next wait would overrun it, and an attempt still running
+	// when it is spent is canceled.
+	Budget time.Duration
 }
 
 // DoWithRetry executes one operation with retries according to cfg.
@@ -27,7 +63,7 @@ func DoWithRetry(
 	ctx context.Context,
 	cfg Config,
 	body io.Reader,
-	op func(attempt int, body io.Reader) error,
+	op func(ctx context.Context, attempt int, body io.Reader) error,
 	isRetryable func(error) bool,
 ) error {
 	if op == nil {
@@ -42,21 +78,13 @@ func DoWithRetry(
 		defer cleanup()
 	}
 
-	return WithExpBackoff(
-		ctx,
-		cfg.Label,
-		cfg.MaxRetries,
-		cfg.InitialBackoff,
-		cfg.MaxBackoff,
-		attemptOp,
-		isRetryable,
-	)
+	return WithExpBackoff(ctx, cfg, attemptOp, isRetryable)
 }
 
 func makeAttemptOp(
 	body io.Reader,
-	op func(attempt int, body io.Reader) error,
-) (func(attempt int) error, func(), error) {
+	op func(ctx context.Context, attempt int, body io.Reader) error,
+) (func(ctx context.Context, attempt int) error, func(), error) {
 	if f, ok := body.(retryBodyFactory); ok {
 		return attemptOpFromFactory(f, op), nil, nil
 	}
@@ -72,48 +100,48 @@ func makeAttemptOp(
 
 func attemptOpFromFactory(
 	f retryBodyFactory,
-	op func(attempt int, body io.Reader) error,
-) func(attempt int) error {
-	return func(attempt int) error {
+	op func(ctx context.Context, attempt int, body io.Reader) error,
+) func(ctx context.Context, attempt int) error {
+	return func(ctx context.Context, attempt int) error {
 		rc, err := f.NewBody()
 		if err != nil {
 			return fmt.Errorf("create request body: %w", err)
 		}
-		return op(attempt, rc)
+		return op(ctx, attempt, rc)
 	}
 }
 
 func attemptOpFromReadSeeker(
 	body io.Reader,
 	rs io.ReadSeeker,
-	op func(attempt int, body io.Reader) error,
-) (func(attempt int) error, func()) {
+	op func(ctx context.Context, attempt int, body io.Reader) error,
+) (func(ctx context.Context, attempt int) error, func()) {
 	if cl, ok := body.(io.Closer); ok {
 		cleanup := func() { _ = cl.Close() }
 
-		attemptOp := func(attempt int) error {
+		attemptOp := func(ctx context.Context, attempt int) error {
 			if _, err := rs.Seek(0, io.SeekStart); err != nil {
 				return fmt.Errorf("rewind body: %w", err)
 			}
 			rdr := struct{ io.Reader }{rs} // hide Close
-			return op(attempt, rdr)
+			return op(ctx, attempt, rdr)
 		}
 		return attemptOp, cleanup
 	}
 
-	attemptOp := func(attempt int) error {
+	attemptOp := func(ctx context.Context, attempt int) error {
 		if _, err := rs.Seek(0, io.SeekStart); err != nil {
 			return fmt.Errorf("rewind body: %w", err)
 		}
-		return op(attempt, rs)
+		return op(ctx, attempt, rs)
 	}
 	return attemptOp, nil
 }
 
 func attemptOpFromBufferedBody(
 	body io.Reader,
-	op func(attempt int, body io.Reader) error,
-) (func(attempt int) error, error) {
+	op func(ctx context.Context, attempt int, body io.Reader) error,
+) (func(ctx context.Context, attempt int) error, error) {
 	var payload []byte
 	if body != nil {
 		b, err := io.ReadAll(body)
@@ -126,12 +154,12 @@ func attemptOpFromBufferedBody(
 		payload = b
 	}
 
-	attemptOp := func(attempt int) error {
+	attemptOp := func(ctx context.Context, attempt int) error {
 		var rdr io.Reader
 		if payload != nil {
 			rdr = bytes.NewReader(payload)
 		}
-		return op(attempt, rdr)
+		return op(ctx, attempt, rdr)
 	}
 	return attemptOp, nil
 }
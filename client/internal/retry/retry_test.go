@@ -126,7 +126,7 @@ func TestDoWithRetry(t *testing.T) {
 				MaxBackoff:     time.Millisecond,
 			},
 			errReader{err: readErr},
-			func(_ int, _ io.Reader) error { return nil },
+			func(_ context.Context, _ int, _ io.Reader) error { return nil },
 			nil,
 		)
 		if err == nil {
@@ -156,7 +156,7 @@ func TestDoWithRetry(t *testing.T) {
 				MaxBackoff:     time.Millisecond,
 			},
 			body,
-			func(_ int, rdr io.Reader) error {
+			func(_ context.Context, _ int, rdr io.Reader) error {
 				b, err := io.ReadAll(rdr)
 				if err != nil {
 					return err
@@ -195,7 +195,7 @@ func TestMakeAttemptOp(t *testing.T) {
 
 		attemptOp, cleanup, err := retry.ExportMakeAttemptOp(
 			body,
-			func(_ int, rdr io.Reader) error {
+			func(_ context.Context, _ int, rdr io.Reader) error {
 				b, err := io.ReadAll(rdr)
 				if err != nil {
 					return err
@@ -213,7 +213,7 @@ func TestMakeAttemptOp(t *testing.T) {
 			t.Fatal("cleanup != nil, want nil for factory body")
 		}
 
-		if err := attemptOp(1); err != nil {
+		if err := attemptOp(context.Background(), 1); err != nil {
 			t.Fatalf("attemptOp() unexpected error = %v", err)
 		}
 		if factoryCalls != 1 {
@@ -226,7 +226,7 @@ func TestMakeAttemptOp(t *testing.T) {
 
 		attemptOp, cleanup, err := retry.ExportMakeAttemptOp(
 			strings.NewReader("buffered-body"),
-			func(_ int, rdr io.Reader) error {
+			func(_ context.Context, _ int, rdr io.Reader) error {
 				b, err := io.ReadAll(rdr)
 				if err != nil {
 					return err
@@ -244,7 +244,7 @@ func TestMakeAttemptOp(t *testing.T) {
 			t.Fatal("cleanup != nil, want nil for buffered body")
 		}
 
-		if err := attemptOp(1); err != nil {
+		if err := attemptOp(context.Background(), 1); err != nil {
 			t.Fatalf("attemptOp() unexpected error = %v", err)
 		}
 	})
@@ -256,7 +256,7 @@ func TestMakeAttemptOp(t *testing.T) {
 
 		attemptOp, cleanup, err := retry.ExportMakeAttemptOp(
 			errReader{err: readErr},
-			func(_ int, _ io.Reader) error { return nil },
+			func(_ context.Context, _ int, _ io.Reader) error { return nil },
 		)
 		if err == nil {
 			t.Fatal("MakeAttemptOp() error = nil, want non-nil")
@@ -291,7 +291,7 @@ func TestAttemptOpFromReadSeeker(t *testing.T) {
 		attemptOp, cleanup := retry.ExportAttemptOpFromReadSeeker(
 			body,
 			body,
-			func(_ int, rdr io.Reader) error {
+			func(_ context.Context, _ int, rdr io.Reader) error {
 				_, gotCloser = rdr.(io.Closer)
 
 				b, err := io.ReadAll(rdr)
@@ -306,7 +306,7 @@ func TestAttemptOpFromReadSeeker(t *testing.T) {
 		if cleanup == nil {
 			t.Fatal("cleanup = nil, want non-nil")
 		}
-		if err := attemptOp(1); err != nil {
+		if err := attemptOp(context.Background(), 1); err != nil {
 			t.Fatalf("attemptOp() unexpected error = %v", err)
 		}
 		if gotBody != "abc" {
@@ -331,13 +331,13 @@ func TestAttemptOpFromReadSeeker(t *testing.T) {
 		attemptOp, cleanup := retry.ExportAttemptOpFromReadSeeker(
 			rs,
 			rs,
-			func(_ int, _ io.Reader) error { return nil },
+			func(_ context.Context, _ int, _ io.Reader) error { return nil },
 		)
 		if cleanup != nil {
 			t.Fatal("cleanup != nil, want nil")
 		}
 
-		err := attemptOp(1)
+		err := attemptOp(context.Background(), 1)
 		if err == nil {
 			t.Fatal("attemptOp() error = nil, want non-nil")
 		}
@@ -360,14 +360,14 @@ func TestAttemptOpFromReadSeeker(t *testing.T) {
 		attemptOp, cleanup := retry.ExportAttemptOpFromReadSeeker(
 			body,
 			body,
-			func(_ int, _ io.Reader) error { return nil },
+			func(_ context.Context, _ int, _ io.Reader) error { return nil },
 		)
 
 		if cleanup == nil {
 			t.Fatal("cleanup = nil, want non-nil")
 		}
 
-		err := attemptOp(1)
+		err := attemptOp(context.Background(), 1)
 		if err == nil {
 			t.Fatal("attemptOp() error = nil, want non-nil")
 		}
@@ -390,7 +390,7 @@ func TestAttemptOpFromBufferedBody(t *testing.T) {
 
 		attemptOp, err := retry.ExportAttemptOpFromBufferedBody(
 			nil,
-			func(_ int, rdr io.Reader) error {
+			func(_ context.Context, _ int, rdr io.Reader) error {
 				if rdr != nil {
 					t.Fatal("reader != nil, want nil")
 				}
@@ -401,7 +401,7 @@ func TestAttemptOpFromBufferedBody(t *testing.T) {
 			t.Fatalf("AttemptOpFromBufferedBody() unexpected error = %v", err)
 		}
 
-		if err := attemptOp(1); err != nil {
+		if err := attemptOp(context.Background(), 1); err != nil {
 			t.Fatalf("attemptOp() unexpected error = %v", err)
 		}
 	})
@@ -417,7 +417,7 @@ func TestAttemptOpFromBufferedBody(t *testing.T) {
 
 		attemptOp, err := retry.ExportAttemptOpFromBufferedBody(
 			body,
-			func(_ int, rdr io.Reader) error {
+			func(_ context.Context, _ int, rdr io.Reader) error {
 				b, err := io.ReadAll(rdr)
 				if err != nil {
 					return err
@@ -435,10 +435,10 @@ func TestAttemptOpFromBufferedBody(t *testing.T) {
 			t.Fatal("body was not closed after buffering")
 		}
 
-		if err := attemptOp(1); err != nil {
+		if err := attemptOp(context.Background(), 1); err != nil {
 			t.Fatalf("attemptOp() unexpected error = %v", err)
 		}
-		if err := attemptOp(2); err != nil {
+		if err := attemptOp(context.Background(), 2); err != nil {
 			t.Fatalf("attemptOp() unexpected error on second call = %v", err)
 		}
 	})
@@ -448,7 +448,7 @@ func TestAttemptOpFromBufferedBody(t *testing.T) {
 
 		attemptOp, err := retry.ExportAttemptOpFromBufferedBody(
 			errReader{err: errors.New("read boom")},
-			func(_ int, _ io.Reader) error { return nil },
+			func(_ context.Context, _ int, _ io.Reader) error { return nil },
 		)
 		if err == nil {
 			t.Fatal("AttemptOpFromBufferedBody() error = nil, want non-nil")
@@ -0,0 +1,80 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/circuit"
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+)
+
+func TestRequester_Breaker(t *testing.T) {
+	t.Parallel()
+
+	statuses := []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusNotFound, http.StatusInternalServerError, http.StatusBadGateway}
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := hits.Add(1)
+		w.WriteHeader(statuses[n-1])
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	b, err := circuit.New(2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &transport.Requester{BaseURL: srv.URL, HTTPClient: srv.Client(), Breaker: b}
+	p, err := r.PrepareJSON(http.MethodGet, "projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 429 is ignored, 502 counts, 404 resets, then 500 and 502 trip it.
+	for range 3 {
+		_ = transport.ExportDo(r, context.Background(), http.MethodGet, "projects", nil, nil, nil)
+	}
+	if got := b.State(); got != circuit.Closed {
+		t.Fatalf("State() = %v, want closed", got)
+	}
+	_ = r.DoPrepared(context.Background(), p, nil)
+	_ = transport.ExportDo(r, context.Background(), http.MethodGet, "projects", nil, nil, nil)
+	if got := b.State(); got != circuit.Open {
+		t.Fatalf("State() = %v, want open", got)
+	}
+
+	if err := transport.ExportDo(r, context.Background(), http.MethodGet, "projects", nil, nil, nil); !errors.Is(err, circuit.ErrOpen) {
+		t.Fatalf("do() error = %v, want ErrOpen", err)
+	}
+	if err := r.DoPrepared(context.Background(), p, nil); !errors.Is(err, circuit.ErrOpen) {
+		t.Fatalf("DoPrepared() error = %v, want ErrOpen", err)
+	}
+	if n := hits.Load(); n != 5 {
+		t.Fatalf("requests sent = %d, want 5", n)
+	}
+}
+
+func TestRequester_Breaker_NoResponseCounts(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close() // connections are refused from now on
+
+	b, err := circuit.New(1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &transport.Requester{BaseURL: url, HTTPClient: &http.Client{}, Breaker: b}
+	if err := transport.ExportDo(r, context.Background(), http.MethodGet, "projects", nil, nil, nil); err == nil {
+		t.Fatal("do() error = nil, want connection error")
+	}
+	if got := b.State(); got != circuit.Open {
+		t.Fatalf("State() = %v, want open", got)
+	}
+}
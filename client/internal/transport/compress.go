@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// compress gzips req.Body when it holds at least r.CompressMin bytes and sets
+// Content-Encoding. Bodies of unknown size are peeked up to the threshold,
+// so small ones are sent as-is and large ones are compressed as they stream,
+// without buffering the whole body.
+func (r *Requester) compress(req *http.Request) error {
+	if r.CompressMin <= 0 || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	if req.ContentLength > 0 && req.ContentLength < r.CompressMin {
+		return nil
+	}
+
+	body := req.Body
+	head := make([]byte, r.CompressMin)
+	n, err := io.ReadFull(body, head)
+	head = head[:n]
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		// Below the threshold: send what was read as-is.
+		_ = body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(head))
+		req.ContentLength = int64(n)
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(head)), nil
+		}
+		return nil
+	case err != nil:
+		_ = body.Close()
+		return fmt.Errorf("compress request: read body: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer func() { _ = body.Close() }()
+		zw := gzip.NewWriter(pw)
+		_, err := io.Copy(zw, io.MultiReader(bytes.NewReader(head), body))
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	req.Body = pr
+	req.ContentLength = -1
+	req.GetBody = nil
+	req.Header.Set("Content-Encoding", "gzip")
+	return nil
+}
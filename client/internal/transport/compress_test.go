@@ -0,0 +1,116 @@
+package transport_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+)
+
+// echoEncoding returns a server that records the Content-Encoding and the
+// decoded body of each request.
+func echoEncoding(t *testing.T) (*httptest.Server, func() (string, string, int64)) {
+	t.Helper()
+	var enc, body string
+	var length int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enc, length = r.Header.Get("Content-Encoding"), r.ContentLength
+		var rd io.Reader = r.Body
+		if enc == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("gzip.NewReader: %v", err)
+				return
+			}
+			rd = zr
+		}
+		b, err := io.ReadAll(rd)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		body = string(b)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() (string, string, int64) { return enc, body, length }
+}
+
+func TestRequester_Compression(t *testing.T) {
+	t.Parallel()
+
+	big := `{"data":"` + strings.Repeat("QUJD", 512) + `"}`
+	small := `{"a":1}`
+
+	tests := []struct {
+		name     string
+		min      int64
+		body     func() io.Reader
+		want     string
+		wantGzip bool
+	}{
+		{"disabled", 0, func() io.Reader { return strings.NewReader(big) }, big, false},
+		{"known size below threshold", 64, func() io.Reader { return strings.NewReader(small) }, small, false},
+		{"known size above threshold", 64, func() io.Reader { return bytes.NewReader([]byte(big)) }, big, true},
+		{"unknown size below threshold", 64, func() io.Reader {
+			return io.MultiReader(strings.NewReader(`{"a":`), strings.NewReader(`1}`))
+		}, small, false},
+		{"unknown size above threshold", 64, func() io.Reader {
+			return io.MultiReader(strings.NewReader(big[:10]), strings.NewReader(big[10:]))
+		}, big, true},
+		{"exactly at threshold", int64(len(small)), func() io.Reader { return io.MultiReader(strings.NewReader(small)) }, small, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv, got := echoEncoding(t)
+			r := &transport.Requester{BaseURL: srv.URL, Token: "tok", HTTPClient: srv.Client(), CompressMin: tt.min}
+			if err := r.DoJSON(context.Background(), http.MethodPost, "x", tt.body(), nil); err != nil {
+				t.Fatalf("DoJSON() error = %v", err)
+			}
+			enc, body, length := got()
+			if (enc == "gzip") != tt.wantGzip {
+				t.Fatalf("Content-Encoding = %q, want gzip=%v", enc, tt.wantGzip)
+			}
+			if body != tt.want {
+				t.Fatalf("decoded body = %.40q..., want %.40q...", body, tt.want)
+			}
+			if !tt.wantGzip && length != int64(len(tt.want)) {
+				t.Fatalf("ContentLength = %d, want %d", length, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestRequester_Compression_SignerSeesWireBody(t *testing.T) {
+	t.Parallel()
+
+	srv, got := echoEncoding(t)
+	var signed []byte
+	r := &transport.Requester{
+		BaseURL: srv.URL, Token: "tok", HTTPClient: srv.Client(), CompressMin: 8,
+		Signer: func(_ *http.Request, body []byte) error {
+			signed = body
+			return nil
+		},
+	}
+	payload := strings.Repeat("x", 100)
+	if err := r.DoJSON(context.Background(), http.MethodPost, "x", strings.NewReader(payload), nil); err != nil {
+		t.Fatal(err)
+	}
+	if enc, body, _ := got(); enc != "gzip" || body != payload {
+		t.Fatalf("encoding=%q body=%q", enc, body)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(signed))
+	if err != nil {
+		t.Fatalf("signer did not get the gzipped body: %v", err)
+	}
+	if b, _ := io.ReadAll(zr); string(b) != payload {
+		t.Fatalf("signed body decodes to %q", b)
+	}
+}
@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/redact"
+)
+
+// captureBody wraps a response body, keeping a copy of the first max bytes
+// read through it.
+type captureBody struct {
+	io.ReadCloser
+	buf bytes.Buffer
+	max int
+}
+
+func (c *captureBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if room := c.max - c.buf.Len(); room > 0 {
+		c.buf.Write(p[:min(n, room)])
+	}
+	return n, err
+}
+
+// dump writes one attempt to r.Debug as a single Write: the request line,
+// headers and body, then the response status, headers and body (or the
+// send error). Everything goes through a Redactor; bodies are cut at
+// maxRecordedBody. resp and respBody may be nil.
+func (r *Requester) dump(req *http.Request, reqBody string, resp *http.Response, respBody *captureBody, err error, took time.Duration) {
+	if r.Debug == nil || req == nil {
+		return
+	}
+	rd := redact.New(r.Token)
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "--> %s %s\n", req.Method, rd.String(req.URL.Redacted()))
+	writeHeader(&b, rd.Header(req.Header))
+	switch {
+	case reqBody != "":
+		fmt.Fprintf(&b, "\n%s\n", rd.Body(reqBody))
+	case req.Body != nil && req.Body != http.NoBody:
+		b.WriteString("\n(streamed body not shown)\n")
+	}
+
+	if resp == nil {
+		fmt.Fprintf(&b, "<-- error (%s): %s\n\n", took.Round(time.Millisecond), rd.String(fmt.Sprint(err)))
+		_, _ = r.Debug.Write(b.Bytes())
+		return
+	}
+	fmt.Fprintf(&b, "<-- %s (%s)\n", resp.Status, took.Round(time.Millisecond))
+	writeHeader(&b, rd.Header(resp.Header))
+	if respBody != nil && respBody.buf.Len() > 0 {
+		body := rd.Body(respBody.buf.String())
+		if respBody.buf.Len() == respBody.max {
+			body += "…"
+		}
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "error: %s\n", rd.String(err.Error()))
+	}
+	b.WriteString("\n")
+	_, _ = r.Debug.Write(b.Bytes())
+}
+
+func writeHeader(b *bytes.Buffer, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(b, "%s: %s\n", k, v)
+		}
+	}
+}
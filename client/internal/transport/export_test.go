@@ -14,7 +14,8 @@ func ExportDo(
 	v any,
 	headers http.Header,
 ) error {
-	return r.do(ctx, method, path, body, v, headers)
+	_, err := r.do(ctx, method, path, body, v, headers)
+	return err
 }
 
 func ExportNewRequest(
@@ -32,9 +33,17 @@ func ExportMergeHeaders(dst, src http.Header) {
 }
 
 func ExportHandleResponse(resp *http.Response, v any) error {
-	return handleResponse(resp, v)
+	return handleResponse(resp, v, DecodeOptions{}, 0)
+}
+
+func ExportParseAPIError(resp *http.Response, limit int64) error {
+	return parseAPIError(resp, limit)
 }
 
 func ExportDecodeJSONResponse(resp *http.Response, v any) error {
-	return decodeJSONResponse(resp, v)
+	return decodeJSONResponse(resp, v, DecodeOptions{})
+}
+
+func ExportDecodeJSONResponseWithOptions(resp *http.Response, v any, opts DecodeOptions) error {
+	return decodeJSONResponse(resp, v, opts)
 }
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+	"github.com/bodrovis/lokex/v2/internal/redact"
+)
+
+// maxRecordedBody caps how many request body bytes a FailedExchange keeps.
+const maxRecordedBody = apierr.DefaultErrCap
+
+// FailedExchange is a sanitized snapshot of one failed request/response pair:
+// a non-2xx response or a send error. Credentials, the API token and base64
+// payloads are redacted (see internal/redact) and bodies are truncated, so it
+// is safe to log or persist.
+type FailedExchange struct {
+	Time time.Time `json:"time"`
+
+	Method        string      `json:"method"`
+	URL           string      `json:"url"`
+	RequestHeader http.Header `json:"request_header,omitempty"`
+	RequestBody   string      `json:"request_body,omitempty"`
+
+	StatusCode     int         `json:"status_code,omitempty"`
+	ResponseHeader http.Header `json:"response_header,omitempty"`
+	ResponseBody   string      `json:"response_body,omitempty"`
+
+	// Err is the error returned to the caller.
+	Err string `json:"error"`
+}
+
+// FailureRecorder receives failed exchanges. It is called synchronously on
+// the request goroutine and must not block for long.
+type FailureRecorder func(FailedExchange)
+
+// snapshotBody returns up to maxRecordedBody bytes of body without consuming
+// it. Only in-memory readers are supported; other readers yield "".
+func snapshotBody(body io.Reader) string {
+	var (
+		ra   io.ReaderAt
+		off  int64
+		size int64
+	)
+	switch b := body.(type) {
+	case *bytes.Reader:
+		ra, off, size = b, b.Size()-int64(b.Len()), b.Size()
+	case *strings.Reader:
+		ra, off, size = b, b.Size()-int64(b.Len()), b.Size()
+	default:
+		return ""
+	}
+
+	n := min(size-off, maxRecordedBody)
+	if n <= 0 {
+		return ""
+	}
+	buf := make([]byte, n)
+	read, _ := ra.ReadAt(buf, off)
+	return string(buf[:read])
+}
+
+// recordFailure reports a failed exchange to r.OnFailure, if set.
+// resp may be nil when the request was never answered.
+func (r *Requester) recordFailure(req *http.Request, reqBody string, resp *http.Response, err error) {
+	if r.OnFailure == nil || req == nil || err == nil {
+		return
+	}
+
+	rd := redact.New(r.Token)
+	fe := FailedExchange{
+		Time:          time.Now(),
+		Method:        req.Method,
+		URL:           rd.String(req.URL.Redacted()),
+		RequestHeader: rd.Header(req.Header),
+		RequestBody:   rd.Body(reqBody),
+		Err:           rd.String(err.Error()),
+	}
+	if resp != nil {
+		fe.StatusCode = resp.StatusCode
+		fe.ResponseHeader = rd.Header(resp.Header)
+	}
+
+	var ae *apierr.APIError
+	if errors.As(err, &ae) {
+		fe.ResponseBody = rd.Body(ae.Raw)
+	}
+
+	r.OnFailure(fe)
+}
@@ -0,0 +1,187 @@
+package transport_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func TestRequester_OnFailure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not called on success", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer srv.Close()
+
+		var calls int
+		r := &transport.Requester{
+			BaseURL:    srv.URL,
+			HTTPClient: srv.Client(),
+			OnFailure:  func(transport.FailedExchange) { calls++ },
+		}
+		if err := r.DoJSON(context.Background(), http.MethodGet, "ok", nil, nil); err != nil {
+			t.Fatalf("DoJSON() error = %v", err)
+		}
+		if calls != 0 {
+			t.Fatalf("OnFailure calls = %d, want 0", calls)
+		}
+	})
+
+	t.Run("api error keeps unread body and redacts token", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("upstream down"))
+		}))
+		defer srv.Close()
+
+		var got []transport.FailedExchange
+		r := &transport.Requester{
+			BaseURL:    srv.URL,
+			Token:      "tok",
+			HTTPClient: srv.Client(),
+			OnFailure:  func(fe transport.FailedExchange) { got = append(got, fe) },
+		}
+
+		body := bytes.NewReader([]byte(`xx{"a":1}`))
+		_, _ = body.Seek(2, 0)
+		if err := r.DoJSON(context.Background(), http.MethodPost, "p?q=1", body, nil); err == nil {
+			t.Fatal("DoJSON() error = nil, want error")
+		}
+
+		if len(got) != 1 {
+			t.Fatalf("OnFailure calls = %d, want 1", len(got))
+		}
+		fe := got[0]
+		if fe.StatusCode != http.StatusBadGateway || fe.ResponseBody != "upstream down" {
+			t.Fatalf("status/body = %d/%q", fe.StatusCode, fe.ResponseBody)
+		}
+		if fe.RequestBody != `{"a":1}` {
+			t.Fatalf("RequestBody = %q, want unread remainder", fe.RequestBody)
+		}
+		if !strings.HasSuffix(fe.URL, "/p?q=1") {
+			t.Fatalf("URL = %q", fe.URL)
+		}
+		if v := fe.RequestHeader.Get("X-Api-Token"); v != "[REDACTED]" {
+			t.Fatalf("X-Api-Token = %q, want redacted", v)
+		}
+		if fe.Time.IsZero() || fe.Err == "" {
+			t.Fatalf("Time/Err not set: %+v", fe)
+		}
+	})
+
+	t.Run("send error without response", func(t *testing.T) {
+		t.Parallel()
+
+		var got []transport.FailedExchange
+		r := &transport.Requester{
+			BaseURL: "http://example.invalid",
+			HTTPClient: &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+				return nil, errors.New("dial boom")
+			})},
+			OnFailure: func(fe transport.FailedExchange) { got = append(got, fe) },
+		}
+
+		err := r.DoJSON(context.Background(), http.MethodGet, "x", strings.NewReader("body"), nil)
+		if err == nil {
+			t.Fatal("DoJSON() error = nil, want error")
+		}
+		if len(got) != 1 {
+			t.Fatalf("OnFailure calls = %d, want 1", len(got))
+		}
+		if got[0].StatusCode != 0 || got[0].ResponseHeader != nil {
+			t.Fatalf("unexpected response data: %+v", got[0])
+		}
+		if got[0].RequestBody != "body" || !strings.Contains(got[0].Err, "dial boom") {
+			t.Fatalf("unexpected exchange: %+v", got[0])
+		}
+	})
+
+	t.Run("prepared request", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		var calls int
+		r := &transport.Requester{
+			BaseURL:    srv.URL,
+			HTTPClient: srv.Client(),
+			OnFailure:  func(transport.FailedExchange) { calls++ },
+		}
+		p, err := r.PrepareJSON(http.MethodGet, "x")
+		if err != nil {
+			t.Fatalf("PrepareJSON() error = %v", err)
+		}
+		_ = r.DoPrepared(context.Background(), p, nil)
+		if calls != 1 {
+			t.Fatalf("OnFailure calls = %d, want 1", calls)
+		}
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestRequester_OnFailure_NoSecretLeaks(t *testing.T) {
+	t.Parallel()
+
+	const token = "tok-5ecret"
+	const payload = "c2VjcmV0IGZpbGUgY29udGVudHM="
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A misbehaving proxy echoing the request back.
+		w.Header().Set("X-Echo-Token", r.Header.Get("X-Api-Token"))
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"bad token ` + r.Header.Get("X-Api-Token") + `","data":"` + payload + `"}`))
+	}))
+	defer srv.Close()
+
+	var got []transport.FailedExchange
+	r := &transport.Requester{
+		BaseURL:    srv.URL,
+		Token:      token,
+		HTTPClient: srv.Client(),
+		OnFailure:  func(fe transport.FailedExchange) { got = append(got, fe) },
+	}
+	body := strings.NewReader(`{"filename":"en.json","data":"` + payload + `"}`)
+	err := r.DoJSON(context.Background(), http.MethodPost, "projects/p/files/upload", body, nil)
+	if err == nil {
+		t.Fatal("DoJSON() error = nil, want error")
+	}
+	var ae *apierr.APIError
+	if !errors.As(err, &ae) || strings.Contains(err.Error(), token) || strings.Contains(ae.Raw, token) || strings.Contains(ae.Raw, payload) {
+		t.Fatalf("returned error leaks secrets: %v (raw %q)", err, ae.Raw)
+	}
+	if len(got) != 1 {
+		t.Fatalf("OnFailure calls = %d, want 1", len(got))
+	}
+
+	dump, err := json.Marshal(got[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{token, payload} {
+		if strings.Contains(string(dump), secret) {
+			t.Fatalf("recorded exchange leaks %q: %s", secret, dump)
+		}
+	}
+	if !strings.Contains(got[0].RequestBody, `"filename":"en.json"`) {
+		t.Fatalf("RequestBody over-redacted: %q", got[0].RequestBody)
+	}
+}
@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/circuit"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+	"github.com/bodrovis/lokex/v2/internal/telemetry"
+)
+
+// PreparedRequest is a body-less request template that can be sent many times
+// (e.g. polling the same process) without rebuilding URL and headers per send.
+// It is safe for concurrent use: each send works on a shallow copy, and the
+// shared URL/header values are never mutated.
+type PreparedRequest struct {
+	req          *http.Request
+	method, path string // as passed to PrepareJSON, for error hints
+}
+
+// PrepareJSON builds a body-less JSON request template for method and path.
+func (r *Requester) PrepareJSON(method, path string) (*PreparedRequest, error) {
+	req, err := r.newRequest(context.Background(), method, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PreparedRequest{req: req, method: method, path: path}, nil
+}
+
+// DoPrepared sends p bound to ctx and decodes the response like DoJSON.
+func (r *Requester) DoPrepared(ctx context.Context, p *PreparedRequest, v any) (err error) {
+	if p == nil || p.req == nil {
+		return fmt.Errorf("send request: nil prepared request")
+	}
+	ctx, span := r.startSpan(ctx, p.req.Method)
+	span.SetAttributes(telemetry.AttrURLPath.String(p.req.URL.Path))
+	defer func() { telemetry.End(span, err) }()
+
+	if r.HTTPClient == nil {
+		return fmt.Errorf("send request: nil http client")
+	}
+
+	done, err := r.Breaker.Allow()
+	if err != nil {
+		return err
+	}
+	outcome := circuit.Ignored
+	defer func() { done(outcome) }()
+
+	release, err := r.Concurrency.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("max concurrency: %w", err)
+	}
+	defer release()
+
+	if err := r.Limiter.Wait(ctx, r.Priority); err != nil {
+		return fmt.Errorf("rate limit: %w", err)
+	}
+
+	req := p.req.WithContext(ctx)
+	if r.Signer != nil {
+		// Signers set headers; never touch the shared template.
+		req = p.req.Clone(ctx)
+		if err := r.sign(req); err != nil {
+			return err
+		}
+	}
+
+	status := 0
+	defer r.observe(req, &status, time.Now())
+
+	sent := time.Now()
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("send request: %w", err)
+		outcome = breakerOutcome(0, err)
+		r.recordFailure(req, "", nil, err)
+		r.dump(req, "", nil, nil, err, time.Since(sent))
+		r.result(req, 0, nil, err)
+		return err
+	}
+	var respBody *captureBody
+	if r.Debug != nil {
+		respBody = &captureBody{ReadCloser: resp.Body, max: maxRecordedBody}
+		resp.Body = respBody
+	}
+	defer func() { _ = resp.Body.Close() }()
+	status = resp.StatusCode
+	outcome = breakerOutcome(status, nil)
+	span.SetAttributes(telemetry.AttrHTTPStatusCode.Int(resp.StatusCode))
+
+	err = handleResponse(resp, v, r.Decode, r.ErrBodyLimit)
+	r.redactAPIError(err)
+	if resp.StatusCode == http.StatusForbidden {
+		err = apierr.WithPermissionHint(err, p.method, p.path)
+	}
+	if isAPIStatusFailure(resp) {
+		r.recordFailure(req, "", resp, err)
+	}
+	r.dump(req, "", resp, respBody, err, time.Since(sent))
+	r.result(req, resp.StatusCode, resp.Header, err)
+	return err
+}
@@ -0,0 +1,164 @@
+package transport_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+)
+
+func TestRequester_PreparedRequest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reused across sends", func(t *testing.T) {
+		t.Parallel()
+
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			if r.URL.Path != "/projects/p/processes/x" {
+				t.Errorf("path = %q", r.URL.Path)
+			}
+			if got := r.Header.Get("X-Api-Token"); got != "tok" {
+				t.Errorf("X-Api-Token = %q, want tok", got)
+			}
+			if got := r.Header.Get("User-Agent"); got != "ua" {
+				t.Errorf("User-Agent = %q, want ua", got)
+			}
+			_, _ = w.Write([]byte(`{"n":1}`))
+		}))
+		defer srv.Close()
+
+		r := &transport.Requester{
+			BaseURL:    srv.URL + "/",
+			Token:      "tok",
+			UserAgent:  "ua",
+			HTTPClient: srv.Client(),
+		}
+
+		p, err := r.PrepareJSON(http.MethodGet, "projects/p/processes/x")
+		if err != nil {
+			t.Fatalf("PrepareJSON() error = %v", err)
+		}
+
+		for range 3 {
+			var out struct {
+				N int `json:"n"`
+			}
+			if err := r.DoPrepared(context.Background(), p, &out); err != nil {
+				t.Fatalf("DoPrepared() error = %v", err)
+			}
+			if out.N != 1 {
+				t.Fatalf("n = %d, want 1", out.N)
+			}
+		}
+		if hits.Load() != 3 {
+			t.Fatalf("hits = %d, want 3", hits.Load())
+		}
+	})
+
+	t.Run("prepare error", func(t *testing.T) {
+		t.Parallel()
+
+		r := &transport.Requester{BaseURL: "http://[::1"}
+		if _, err := r.PrepareJSON(http.MethodGet, "x"); err == nil || !strings.HasPrefix(err.Error(), "join url: ") {
+			t.Fatalf("error = %v, want join url error", err)
+		}
+	})
+
+	t.Run("nil prepared request", func(t *testing.T) {
+		t.Parallel()
+
+		r := &transport.Requester{HTTPClient: http.DefaultClient}
+		err := r.DoPrepared(context.Background(), nil, nil)
+		if err == nil || err.Error() != "send request: nil prepared request" {
+			t.Fatalf("error = %v, want nil prepared request error", err)
+		}
+	})
+
+	t.Run("nil http client", func(t *testing.T) {
+		t.Parallel()
+
+		r := &transport.Requester{BaseURL: "https://example.com/"}
+		p, err := r.PrepareJSON(http.MethodGet, "x")
+		if err != nil {
+			t.Fatalf("PrepareJSON() error = %v", err)
+		}
+		err = r.DoPrepared(context.Background(), p, nil)
+		if err == nil || err.Error() != "send request: nil http client" {
+			t.Fatalf("error = %v, want nil http client error", err)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		r := &transport.Requester{BaseURL: "https://example.com/", HTTPClient: http.DefaultClient}
+		p, _ := r.PrepareJSON(http.MethodGet, "x")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := r.DoPrepared(ctx, p, nil); err == nil || !strings.Contains(err.Error(), "context canceled") {
+			t.Fatalf("error = %v, want context canceled", err)
+		}
+	})
+}
+
+func TestRequester_DoPrepared_Debug(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			http.Error(w, `{"error":{"message":"not found","code":404}}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"process":{"status":"running"}}`))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	r := &transport.Requester{
+		BaseURL:    srv.URL + "/",
+		Token:      "tok",
+		HTTPClient: srv.Client(),
+		Debug:      &buf,
+	}
+	for _, path := range []string{"projects/p/processes/x", "projects/p/processes/gone"} {
+		p, err := r.PrepareJSON(http.MethodGet, path)
+		if err != nil {
+			t.Fatalf("PrepareJSON() error = %v", err)
+		}
+		_ = r.DoPrepared(context.Background(), p, &struct{}{})
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"--> GET " + srv.URL + "/projects/p/processes/x",
+		`{"process":{"status":"running"}}`,
+		"<-- 404 Not Found",
+		"not found",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "tok\n") || strings.Contains(out, ": tok") {
+		t.Errorf("debug output leaks the token:\n%s", out)
+	}
+
+	// A send error is dumped too.
+	buf.Reset()
+	srv.Close()
+	p, _ := r.PrepareJSON(http.MethodGet, "projects/p/processes/x")
+	if err := r.DoPrepared(context.Background(), p, nil); err == nil {
+		t.Fatal("DoPrepared() to a closed server error = nil")
+	}
+	if !strings.Contains(buf.String(), "<-- error") {
+		t.Errorf("debug output lacks the send error:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,69 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/internal/ratelimit"
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+)
+
+type closeTracker struct {
+	*strings.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRequester_Limiter(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	l, err := ratelimit.New(0.001, 1) // one token, then effectively never
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &transport.Requester{BaseURL: srv.URL, HTTPClient: srv.Client(), Limiter: l}
+
+	if err := r.DoJSON(context.Background(), http.MethodGet, "x", nil, nil); err != nil {
+		t.Fatalf("first DoJSON() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	body := &closeTracker{Reader: strings.NewReader("{}")}
+	err = r.DoJSON(ctx, http.MethodPost, "x", body, nil)
+	if !errors.Is(err, context.Canceled) || !strings.HasPrefix(err.Error(), "rate limit: ") {
+		t.Fatalf("DoJSON() error = %v, want rate limit context error", err)
+	}
+	if !body.closed {
+		t.Fatal("body was not closed after limiter error")
+	}
+
+	p, err := r.PrepareJSON(http.MethodGet, "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DoPrepared(ctx, p, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("DoPrepared() error = %v, want context.Canceled", err)
+	}
+
+	if hits.Load() != 1 {
+		t.Fatalf("hits = %d, want 1", hits.Load())
+	}
+}
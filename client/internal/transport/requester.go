@@ -10,8 +10,19 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
+	"github.com/bodrovis/lokex/v2/client/internal/circuit"
+	"github.com/bodrovis/lokex/v2/client/internal/ratelimit"
 	"github.com/bodrovis/lokex/v2/internal/apierr"
+	"github.com/bodrovis/lokex/v2/internal/metrics"
+	"github.com/bodrovis/lokex/v2/internal/redact"
+	"github.com/bodrovis/lokex/v2/internal/schema"
+	"github.com/bodrovis/lokex/v2/internal/telemetry"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Requester struct {
@@ -19,6 +30,83 @@ type Requester struct {
 	Token      string
 	UserAgent  string
 	HTTPClient *http.Client
+	Decode     DecodeOptions
+
+	// Header holds extra headers sent with every request. They override
+	// the defaults (Accept, User-Agent) but not per-request headers such
+	// as Content-Type.
+	Header http.Header
+
+	// ErrBodyLimit caps how many bytes of a non-2xx body are kept in
+	// APIError.Raw; zero means apierr.DefaultErrCap.
+	ErrBodyLimit int64
+
+	// OnFailure, when set, receives a sanitized copy of every failed exchange.
+	OnFailure FailureRecorder
+
+	// Signer, when set, may add auth headers to every attempt. Request bodies
+	// are buffered in memory so the signer can see them.
+	Signer Signer
+
+	// Limiter, when set, paces every attempt; Priority selects its tier
+	// (High for user calls, Low for background polling).
+	Limiter  *ratelimit.Limiter
+	Priority ratelimit.Priority
+
+	// Concurrency, when set, caps how many sends run at once; a slot is
+	// held until the response has been handled.
+	Concurrency *ratelimit.Semaphore
+
+	// Breaker, when set, rejects sends while it is open and is told how
+	// each send ended (see breakerOutcome).
+	Breaker *circuit.Breaker
+
+	// Tracer, when set, wraps every send in a span (see internal/telemetry).
+	Tracer trace.Tracer
+
+	// Metrics, when set, receives the outcome and duration of every send.
+	Metrics metrics.Recorder
+
+	// CompressMin, when positive, gzips request bodies of at least this
+	// many bytes (see compress).
+	CompressMin int64
+
+	// OnResult, when set, is called after every send with the request,
+	// the status code (0 if no response arrived), the response header and
+	// the error.
+	OnResult func(req *http.Request, status int, header http.Header, err error)
+
+	// DryRun, when set, gets every request, with its body read into
+	// memory, instead of the network; do returns its error.
+	DryRun func(req *http.Request, body []byte) error
+
+	// Debug, when set, gets a sanitized dump of every attempt (see dump).
+	// Each dump is a single Write; the writer must be safe for concurrent
+	// use.
+	Debug io.Writer
+}
+
+// DecodeOptions tunes how successful JSON responses are decoded.
+type DecodeOptions struct {
+	// UseNumber decodes numbers into json.Number instead of float64 when the
+	// target is an interface value (e.g. map[string]any), so large IDs keep
+	// their exact value.
+	UseNumber bool
+
+	// DisallowUnknownFields rejects response fields that have no matching
+	// struct field in the target.
+	DisallowUnknownFields bool
+
+	// Codec creates the JSON decoder; nil means encoding/json.
+	Codec utils.Codec
+
+	// Strict checks the response against the target's `lokex:"required"`
+	// fields after decoding (see internal/schema).
+	Strict bool
+
+	// Envelope, when set, gets the top-level project_id and branch of every
+	// successful JSON response ("" when absent).
+	Envelope func(projectID, branch string)
 }
 
 // DoJSON performs one HTTP request expecting a JSON API response.
@@ -30,6 +118,18 @@ func (r *Requester) DoJSON(
 	body io.Reader,
 	v any,
 ) error {
+	_, err := r.DoJSONHeader(ctx, method, path, body, v)
+	return err
+}
+
+// DoJSONHeader is DoJSON that also returns the response header (nil if no
+// response arrived), e.g. for pagination headers.
+func (r *Requester) DoJSONHeader(
+	ctx context.Context,
+	method, path string,
+	body io.Reader,
+	v any,
+) (http.Header, error) {
 	headers := make(http.Header)
 	if body != nil {
 		headers.Set("Content-Type", "application/json")
@@ -37,33 +137,185 @@ func (r *Requester) DoJSON(
 	return r.do(ctx, method, path, body, v, headers)
 }
 
-// do performs a single HTTP request (no retries).
-// Body is sent as-is. If v is nil, the response body is drained and discarded;
-// otherwise it is decoded as JSON.
+// do performs a single HTTP request (no retries) and returns the response
+// header. Body is sent as-is. If v is nil, the response body is drained and
+// discarded; otherwise it is decoded as JSON.
 func (r *Requester) do(
 	ctx context.Context,
 	method, path string,
 	body io.Reader,
 	v any,
 	headers http.Header,
-) error {
+) (_ http.Header, err error) {
+	if r.DryRun != nil {
+		return nil, r.dryRun(ctx, method, path, body, headers)
+	}
+
+	ctx, span := r.startSpan(ctx, method)
+	defer func() { telemetry.End(span, err) }()
+
+	done, err := r.Breaker.Allow()
+	if err != nil {
+		if cl, ok := body.(io.Closer); ok {
+			_ = cl.Close()
+		}
+		return nil, err
+	}
+	outcome := circuit.Ignored
+	defer func() { done(outcome) }()
+
+	release, err := r.Concurrency.Acquire(ctx)
+	if err != nil {
+		if cl, ok := body.(io.Closer); ok {
+			_ = cl.Close()
+		}
+		return nil, fmt.Errorf("max concurrency: %w", err)
+	}
+	defer release()
+
+	if err := r.Limiter.Wait(ctx, r.Priority); err != nil {
+		if cl, ok := body.(io.Closer); ok {
+			_ = cl.Close()
+		}
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+
 	req, err := r.newRequest(ctx, method, path, body, headers)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	span.SetAttributes(telemetry.AttrURLPath.String(req.URL.Path))
 
 	if r.HTTPClient == nil {
-		return fmt.Errorf("send request: nil http client")
+		return nil, fmt.Errorf("send request: nil http client")
+	}
+	status := 0
+	defer r.observe(req, &status, time.Now())
+
+	var reqBody string
+	if r.OnFailure != nil || r.Debug != nil {
+		reqBody = snapshotBody(body)
+	}
+
+	if err := r.compress(req); err != nil {
+		return nil, err
+	}
+	if err := r.sign(req); err != nil {
+		return nil, err
 	}
 
+	sent := time.Now()
 	resp, err := r.HTTPClient.Do(req)
 	if err != nil {
 		// after Do() net/http already handled closing the request body.
-		return fmt.Errorf("send request: %w", err)
+		err = fmt.Errorf("send request: %w", err)
+		outcome = breakerOutcome(0, err)
+		r.recordFailure(req, reqBody, nil, err)
+		r.dump(req, reqBody, nil, nil, err, time.Since(sent))
+		r.result(req, 0, nil, err)
+		return nil, err
+	}
+	var respBody *captureBody
+	if r.Debug != nil {
+		respBody = &captureBody{ReadCloser: resp.Body, max: maxRecordedBody}
+		resp.Body = respBody
 	}
 	defer func() { _ = resp.Body.Close() }()
+	status = resp.StatusCode
+	outcome = breakerOutcome(status, nil)
+	span.SetAttributes(telemetry.AttrHTTPStatusCode.Int(resp.StatusCode))
 
-	return handleResponse(resp, v)
+	err = handleResponse(resp, v, r.Decode, r.ErrBodyLimit)
+	r.redactAPIError(err)
+	if resp.StatusCode == http.StatusForbidden {
+		err = apierr.WithPermissionHint(err, method, path)
+	}
+	if isAPIStatusFailure(resp) {
+		r.recordFailure(req, reqBody, resp, err)
+	}
+	r.dump(req, reqBody, resp, respBody, err, time.Since(sent))
+	r.result(req, resp.StatusCode, resp.Header, err)
+	return resp.Header, err
+}
+
+// dryRun builds the request as do would and hands it to r.DryRun.
+func (r *Requester) dryRun(ctx context.Context, method, path string, body io.Reader, headers http.Header) error {
+	req, err := r.newRequest(ctx, method, path, body, headers)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if req.Body != nil {
+		data, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read request body: %w", err)
+		}
+	}
+	return r.DryRun(req, data)
+}
+
+// startSpan starts the span for one send; the URL path is added once the
+// request is built.
+func (r *Requester) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	if r.Tracer == nil {
+		return telemetry.Start(ctx, nil, "")
+	}
+	attrs := []attribute.KeyValue{telemetry.AttrHTTPMethod.String(method)}
+	if u, err := url.Parse(r.BaseURL); err == nil && u.Host != "" {
+		attrs = append(attrs, telemetry.AttrServerAddress.String(u.Hostname()))
+	}
+	return telemetry.Start(ctx, r.Tracer, telemetry.SpanRequest, attrs...)
+}
+
+// observe reports one send to r.Metrics; status 0 means no response.
+func (r *Requester) observe(req *http.Request, status *int, start time.Time) {
+	if r.Metrics != nil {
+		r.Metrics.Request(req.Method, metrics.Route(req.URL.Path), *status, time.Since(start))
+	}
+}
+
+// breakerOutcome classifies a send for r.Breaker; status is 0 if no
+// response arrived. 5xx responses and sends that got no response
+// (timeouts, refused connections) are failures; other responses, 429
+// aside, show the API is up. A send the caller canceled says nothing.
+func breakerOutcome(status int, err error) circuit.Outcome {
+	switch {
+	case status >= 500:
+		return circuit.Failure
+	case status == http.StatusTooManyRequests:
+		return circuit.Ignored
+	case status > 0:
+		return circuit.Success
+	case errors.Is(err, context.Canceled):
+		return circuit.Ignored
+	}
+	return circuit.Failure
+}
+
+// result reports one send to r.OnResult.
+func (r *Requester) result(req *http.Request, status int, header http.Header, err error) {
+	if r.OnResult != nil {
+		r.OnResult(req, status, header, err)
+	}
+}
+
+// redactAPIError masks the token and payload fields that a server or proxy
+// echoed back, so they can't end up in logs via the returned error.
+func (r *Requester) redactAPIError(err error) {
+	var ae *apierr.APIError
+	if !errors.As(err, &ae) {
+		return
+	}
+	rd := redact.New(r.Token)
+	ae.Message = rd.String(ae.Message)
+	ae.Reason = rd.String(ae.Reason)
+	ae.Raw = rd.Body(ae.Raw)
+	rd.Map(ae.Details)
+}
+
+func isAPIStatusFailure(resp *http.Response) bool {
+	return resp.StatusCode < 200 || resp.StatusCode >= 300
 }
 
 func (r *Requester) newRequest(
@@ -81,11 +333,17 @@ func (r *Requester) newRequest(
 		}
 	}
 
+	// path may carry a query string; JoinPath would escape the '?'.
+	path, rawQuery, _ := strings.Cut(path, "?")
+
 	fullURL, err := url.JoinPath(r.BaseURL, path)
 	if err != nil {
 		closeBody()
 		return nil, fmt.Errorf("join url: %w", err)
 	}
+	if rawQuery != "" {
+		fullURL += "?" + rawQuery
+	}
 
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
 	if err != nil {
@@ -99,6 +357,7 @@ func (r *Requester) newRequest(
 	req.Header.Set("User-Agent", r.UserAgent)
 	req.Header.Set("Accept", "application/json")
 
+	mergeHeaders(req.Header, r.Header)
 	mergeHeaders(req.Header, headers)
 
 	return req, nil
@@ -123,31 +382,58 @@ func mergeHeaders(dst, src http.Header) {
 	}
 }
 
-func handleResponse(resp *http.Response, v any) error {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return parseAPIError(resp)
+func handleResponse(resp *http.Response, v any, opts DecodeOptions, errLimit int64) error {
+	if isAPIStatusFailure(resp) {
+		return parseAPIError(resp, errLimit)
 	}
 
 	if v == nil {
+		if opts.Envelope != nil {
+			raw, _ := io.ReadAll(resp.Body)
+			reportEnvelope(raw, opts.Envelope)
+			return nil
+		}
 		_, _ = io.Copy(io.Discard, resp.Body)
 		return nil
 	}
 
-	return decodeJSONResponse(resp, v)
+	return decodeJSONResponse(resp, v, opts)
 }
 
-func parseAPIError(resp *http.Response) error {
-	slurp, _ := io.ReadAll(io.LimitReader(resp.Body, apierr.DefaultErrCap))
+func parseAPIError(resp *http.Response, limit int64) error {
+	slurp, truncated := apierr.ReadBody(resp.Body, limit)
 	_, _ = io.Copy(io.Discard, resp.Body)
 
 	ae := apierr.Parse(slurp, resp.StatusCode)
+	ae.Truncated = truncated
+	ae.Meta = apierr.MetaFromResponse(resp)
 	ae.Resp = resp
 	return ae
 }
 
-func decodeJSONResponse(resp *http.Response, v any) error {
-	cr := &countingReader{r: resp.Body}
-	dec := json.NewDecoder(cr)
+// DecodeJSON decodes raw, part of a response already read, into v like a
+// response body: with opts' codec, number and unknown-field settings and
+// strict checks. The envelope hook is not called.
+func DecodeJSON(raw []byte, v any, opts DecodeOptions) error {
+	opts.Envelope = nil
+	resp := &http.Response{Body: io.NopCloser(bytes.NewReader(raw)), ContentLength: int64(len(raw))}
+	return decodeJSONResponse(resp, v, opts)
+}
+
+func decodeJSONResponse(resp *http.Response, v any, opts DecodeOptions) error {
+	var body io.Reader = resp.Body
+	var raw bytes.Buffer
+	if opts.Strict || opts.Envelope != nil {
+		body = io.TeeReader(resp.Body, &raw)
+	}
+	cr := &countingReader{r: body}
+	dec := utils.CodecOrDefault(opts.Codec).NewDecoder(cr)
+	if opts.UseNumber {
+		dec.UseNumber()
+	}
+	if opts.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
 
 	if err := dec.Decode(v); err != nil {
 		if errors.Is(err, io.EOF) {
@@ -171,5 +457,29 @@ func decodeJSONResponse(resp *http.Response, v any) error {
 		return fmt.Errorf("decode response: %w", err)
 	}
 
+	if opts.Strict {
+		if err := schema.Check(raw.Bytes(), v); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
+	if opts.Envelope != nil {
+		reportEnvelope(raw.Bytes(), opts.Envelope)
+	}
 	return nil
 }
+
+// reportEnvelope passes the project_id and branch of a JSON object to fn.
+// Bodies that aren't objects, and non-string values, report "".
+func reportEnvelope(raw []byte, fn func(projectID, branch string)) {
+	var env struct {
+		ProjectID json.RawMessage `json:"project_id"`
+		Branch    json.RawMessage `json:"branch"`
+	}
+	_ = json.Unmarshal(raw, &env)
+	str := func(m json.RawMessage) string {
+		var s string
+		_ = json.Unmarshal(m, &s)
+		return s
+	}
+	fn(str(env.ProjectID), str(env.Branch))
+}
@@ -2,6 +2,7 @@ package transport_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/bodrovis/lokex/v2/client/internal/transport"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+	"github.com/bodrovis/lokex/v2/internal/schema"
 )
 
 type closeTrackingReader struct {
@@ -227,6 +230,74 @@ func TestHandleResponse(t *testing.T) {
 	})
 }
 
+func TestParseAPIError_BodyLimit(t *testing.T) {
+	t.Parallel()
+
+	body := `{"error":{"message":"Validation failed","code":400,"details":{"keys":["a","b","c"]}}}`
+	tests := []struct {
+		name          string
+		limit         int64
+		wantTruncated bool
+		wantRaw       string
+	}{
+		{"fits", int64(len(body)), false, body},
+		{"cut mid-JSON", 20, true, body[:20]},
+		{"zero uses default", 0, false, body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}
+			err := transport.ExportParseAPIError(resp, tt.limit)
+			var ae *apierr.APIError
+			if !errors.As(err, &ae) {
+				t.Fatalf("error = %v, want *APIError", err)
+			}
+			if ae.Truncated != tt.wantTruncated || ae.Raw != tt.wantRaw {
+				t.Fatalf("Truncated = %v, Raw = %q; want %v, %q", ae.Truncated, ae.Raw, tt.wantTruncated, tt.wantRaw)
+			}
+			if !tt.wantTruncated && ae.Message != "Validation failed" {
+				t.Fatalf("Message = %q", ae.Message)
+			}
+			if rest, _ := io.ReadAll(resp.Body); len(rest) != 0 {
+				t.Fatalf("body not drained: %q left", rest)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONResponse_Strict(t *testing.T) {
+	t.Parallel()
+
+	type target struct {
+		ID   int64  `json:"id" lokex:"required"`
+		Name string `json:"name"`
+	}
+	resp := func(body string) *http.Response {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	var ok target
+	if err := transport.ExportDecodeJSONResponseWithOptions(resp(`{"id":1,"extra":"x"}`), &ok, transport.DecodeOptions{Strict: true}); err != nil || ok.ID != 1 {
+		t.Fatalf("strict decode of a valid body: %+v, %v", ok, err)
+	}
+
+	var missing target
+	err := transport.ExportDecodeJSONResponseWithOptions(resp(`{"name":"a"}`), &missing, transport.DecodeOptions{Strict: true})
+	if !errors.Is(err, schema.ErrMismatch) || !strings.Contains(err.Error(), "decode response: id: missing required field") {
+		t.Fatalf("strict decode error = %v", err)
+	}
+
+	var lax target
+	if err := transport.ExportDecodeJSONResponseWithOptions(resp(`{"name":"a"}`), &lax, transport.DecodeOptions{}); err != nil {
+		t.Fatalf("non-strict decode error = %v", err)
+	}
+}
+
 func TestDecodeJSONResponse(t *testing.T) {
 	t.Parallel()
 
@@ -315,3 +386,91 @@ func TestDecodeJSONResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestRequester_NewRequest_QueryString(t *testing.T) {
+	t.Parallel()
+
+	r := &transport.Requester{
+		BaseURL: "https://example.com/api2/",
+		Token:   "tok",
+	}
+
+	req, err := transport.ExportNewRequest(
+		r,
+		context.Background(),
+		http.MethodGet,
+		"projects/p 1/keys?limit=10&filter_filenames=a%2Fb.json",
+		nil,
+		http.Header{},
+	)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if got := req.URL.Path; got != "/api2/projects/p 1/keys" {
+		t.Fatalf("path = %q, want %q", got, "/api2/projects/p 1/keys")
+	}
+	if got := req.URL.Query().Get("filter_filenames"); got != "a/b.json" {
+		t.Fatalf("filter_filenames = %q, want %q", got, "a/b.json")
+	}
+	if got := req.URL.Query().Get("limit"); got != "10" {
+		t.Fatalf("limit = %q, want %q", got, "10")
+	}
+}
+
+func TestDecodeJSONResponse_Options(t *testing.T) {
+	t.Parallel()
+
+	const payload = `{"key_id":9007199254740993,"extra":true}`
+
+	newResp := func() *http.Response {
+		return &http.Response{
+			StatusCode:    200,
+			ContentLength: int64(len(payload)),
+			Body:          io.NopCloser(strings.NewReader(payload)),
+		}
+	}
+
+	t.Run("default decodes numbers as float64", func(t *testing.T) {
+		t.Parallel()
+
+		var v map[string]any
+		if err := transport.ExportDecodeJSONResponse(newResp(), &v); err != nil {
+			t.Fatalf("DecodeJSONResponse() error = %v", err)
+		}
+		if _, ok := v["key_id"].(float64); !ok {
+			t.Fatalf("key_id type = %T, want float64", v["key_id"])
+		}
+	})
+
+	t.Run("use number keeps exact value", func(t *testing.T) {
+		t.Parallel()
+
+		var v map[string]any
+		err := transport.ExportDecodeJSONResponseWithOptions(newResp(), &v, transport.DecodeOptions{UseNumber: true})
+		if err != nil {
+			t.Fatalf("DecodeJSONResponse() error = %v", err)
+		}
+		n, ok := v["key_id"].(json.Number)
+		if !ok {
+			t.Fatalf("key_id type = %T, want json.Number", v["key_id"])
+		}
+		if n.String() != "9007199254740993" {
+			t.Fatalf("key_id = %s, want 9007199254740993", n)
+		}
+	})
+
+	t.Run("disallow unknown fields", func(t *testing.T) {
+		t.Parallel()
+
+		var v struct {
+			KeyID int64 `json:"key_id"`
+		}
+		err := transport.ExportDecodeJSONResponseWithOptions(newResp(), &v, transport.DecodeOptions{DisallowUnknownFields: true})
+		if err == nil {
+			t.Fatal("DecodeJSONResponse() error = nil, want unknown field error")
+		}
+		if !strings.HasPrefix(err.Error(), "decode response: ") || !strings.Contains(err.Error(), `"extra"`) {
+			t.Fatalf("error = %q, want wrapped unknown field error", err.Error())
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package transport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Signer is called once per attempt, after the standard headers are set and
+// right before the request is sent. body is the complete request body (nil
+// for body-less requests). Signer may add or replace headers on req but must
+// not modify body or read req.Body.
+type Signer func(req *http.Request, body []byte) error
+
+// sign buffers req.Body so the signer can see it, then runs r.Signer.
+// The buffered body replaces req.Body, so nothing is read twice.
+func (r *Requester) sign(req *http.Request) error {
+	if r.Signer == nil {
+		return nil
+	}
+
+	var buf []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("sign request: read body: %w", err)
+		}
+		buf = b
+		req.Body = io.NopCloser(bytes.NewReader(buf))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(buf)), nil
+		}
+		req.ContentLength = int64(len(buf))
+	}
+
+	if err := r.Signer(req, buf); err != nil {
+		return fmt.Errorf("sign request: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+)
+
+func TestRequester_Signer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sees body and sets headers", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"a":1}` {
+				t.Errorf("server body = %q", body)
+			}
+			if r.ContentLength != int64(len(body)) {
+				t.Errorf("ContentLength = %d, want %d", r.ContentLength, len(body))
+			}
+			if got := r.Header.Get("X-Signature"); got != "sig:"+string(body) {
+				t.Errorf("X-Signature = %q", got)
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer srv.Close()
+
+		r := &transport.Requester{
+			BaseURL:    srv.URL,
+			Token:      "tok",
+			HTTPClient: srv.Client(),
+			Signer: func(req *http.Request, body []byte) error {
+				if req.Header.Get("X-Api-Token") != "tok" {
+					t.Errorf("signer ran before standard headers were set")
+				}
+				req.Header.Set("X-Signature", "sig:"+string(body))
+				return nil
+			},
+		}
+
+		// A plain io.Reader has no known length and must still be buffered.
+		body := io.MultiReader(strings.NewReader(`{"a":`), strings.NewReader(`1}`))
+		if err := r.DoJSON(context.Background(), http.MethodPost, "x", body, nil); err != nil {
+			t.Fatalf("DoJSON() error = %v", err)
+		}
+	})
+
+	t.Run("nil body", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer srv.Close()
+
+		var called bool
+		r := &transport.Requester{
+			BaseURL:    srv.URL,
+			HTTPClient: srv.Client(),
+			Signer: func(_ *http.Request, body []byte) error {
+				called = true
+				if body != nil {
+					t.Errorf("body = %q, want nil", body)
+				}
+				return nil
+			},
+		}
+		if err := r.DoJSON(context.Background(), http.MethodGet, "x", nil, nil); err != nil {
+			t.Fatalf("DoJSON() error = %v", err)
+		}
+		if !called {
+			t.Fatal("signer was not called")
+		}
+	})
+
+	t.Run("error aborts send", func(t *testing.T) {
+		t.Parallel()
+
+		var hits int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits++
+		}))
+		defer srv.Close()
+
+		r := &transport.Requester{
+			BaseURL:    srv.URL,
+			HTTPClient: srv.Client(),
+			Signer: func(*http.Request, []byte) error {
+				return errors.New("no key")
+			},
+		}
+		err := r.DoJSON(context.Background(), http.MethodGet, "x", nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "sign request: no key") {
+			t.Fatalf("DoJSON() error = %v, want sign error", err)
+		}
+		if hits != 0 {
+			t.Fatalf("server hits = %d, want 0", hits)
+		}
+	})
+
+	t.Run("prepared template stays unsigned", func(t *testing.T) {
+		t.Parallel()
+
+		var sigs []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sigs = append(sigs, strings.Join(r.Header.Values("X-Signature"), ","))
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer srv.Close()
+
+		n := 0
+		r := &transport.Requester{
+			BaseURL:    srv.URL,
+			HTTPClient: srv.Client(),
+			Signer: func(req *http.Request, _ []byte) error {
+				n++
+				req.Header.Add("X-Signature", strings.Repeat("s", n))
+				return nil
+			},
+		}
+		p, err := r.PrepareJSON(http.MethodGet, "x")
+		if err != nil {
+			t.Fatalf("PrepareJSON() error = %v", err)
+		}
+		for range 2 {
+			if err := r.DoPrepared(context.Background(), p, nil); err != nil {
+				t.Fatalf("DoPrepared() error = %v", err)
+			}
+		}
+		if len(sigs) != 2 || sigs[0] != "s" || sigs[1] != "ss" {
+			t.Fatalf("signatures = %q, want [s ss]", sigs)
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package keys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/internal/retry"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// PartialResult is the outcome of a bulk create or update. Lokalise answers
+// these with 200 even when some items are rejected: the accepted keys come
+// back in "keys" and the rejected ones in "errors".
+type PartialResult struct {
+	// Keys are the keys the API created or updated.
+	Keys []Key
+	// Failed are the rejected items. Index is the item's position in the
+	// slice passed to CreateKeys/UpdateKeys, or -1 if the error could not
+	// be matched to an item.
+	Failed []client.ItemIssue
+	// Retried counts items resubmitted by automatic item retries (see
+	// Manager.WithItemRetries); an item resubmitted twice counts twice.
+	Retried int
+
+	sent int // items whose request completed
+}
+
+// HasErrors reports whether any item was rejected.
+func (r PartialResult) HasErrors() bool { return len(r.Failed) > 0 }
+
+// FailedIndexes returns the positions of the rejected items that could be
+// matched, in ascending order.
+func (r PartialResult) FailedIndexes() []int {
+	var out []int
+	for _, it := range r.Failed {
+		if it.Index >= 0 && !slices.Contains(out, it.Index) {
+			out = append(out, it.Index)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Err summarizes the rejected items as an error, or returns nil if there
+// are none.
+func (r PartialResult) Err() error {
+	if !r.HasErrors() {
+		return nil
+	}
+	msgs := make([]string, len(r.Failed))
+	for i, it := range r.Failed {
+		msgs[i] = it.Message
+	}
+	return fmt.Errorf("%d key(s) rejected: %s", len(r.Failed), strings.Join(msgs, "; "))
+}
+
+// errItemsPending tells the backoff loop that retryable items remain.
+var errItemsPending = errors.New("keys: retryable items pending")
+
+// WithItemRetries returns a copy of m whose bulk creates and updates
+// (CreateKeys, UpdateKeys, Apply) resubmit rejected items up to n more
+// times, with the client's backoff between rounds. Only items whose error
+// is transient (ItemIssue.Retryable, e.g. code 429 or 5xx) and that can be
+// matched to a request item are resubmitted; accepted items are never sent
+// twice. n <= 0 disables item retries (the default).
+func (m *Manager) WithItemRetries(n int) *Manager {
+	if m == nil {
+		return nil
+	}
+	cp := *m
+	cp.itemRetries = max(n, 0)
+	return &cp
+}
+
+// CreateKeys creates keys in bulk requests of up to 500. Items the API
+// rejects are reported in the result, not as an error, so they can be
+// fixed and resubmitted on their own; the error is for failed requests.
+// On error the result holds what earlier requests did.
+func (m *Manager) CreateKeys(ctx context.Context, desired []DesiredKey) (PartialResult, error) {
+	if m == nil || m.client == nil {
+		return PartialResult{}, errors.New(managerIsNilMsg)
+	}
+	res, err := m.create(ctx, desired)
+	if err != nil {
+		return res, fmt.Errorf("keys: create: %w", err)
+	}
+	return res, nil
+}
+
+func (m *Manager) create(ctx context.Context, desired []DesiredKey) (PartialResult, error) {
+	return m.withItemRetries(ctx, len(desired), func(idx []int) (PartialResult, error) {
+		return m.createOnce(ctx, pick(desired, idx))
+	})
+}
+
+func (m *Manager) createOnce(ctx context.Context, desired []DesiredKey) (PartialResult, error) {
+	var res PartialResult
+	offset := 0
+	for chunk := range slices.Chunk(desired, chunkSize()) {
+		resp, err := m.bulk(ctx, http.MethodPost, createBody(chunk))
+		if err != nil {
+			return res, err
+		}
+		res.add(resp, offset, func(it client.ItemIssue) int {
+			return slices.IndexFunc(chunk, func(d DesiredKey) bool { return d.Name == it.Key })
+		})
+		offset += len(chunk)
+		res.sent = offset
+	}
+	return res, nil
+}
+
+// UpdateKeys updates keys in bulk requests of up to 500, sending only the
+// fields listed in each update's Changes. Rejected items are reported as
+// in CreateKeys.
+func (m *Manager) UpdateKeys(ctx context.Context, updates []KeyUpdate) (PartialResult, error) {
+	if m == nil || m.client == nil {
+		return PartialResult{}, errors.New(managerIsNilMsg)
+	}
+	res, err := m.update(ctx, updates)
+	if err != nil {
+		return res, fmt.Errorf("keys: update: %w", err)
+	}
+	return res, nil
+}
+
+func (m *Manager) update(ctx context.Context, updates []KeyUpdate) (PartialResult, error) {
+	return m.withItemRetries(ctx, len(updates), func(idx []int) (PartialResult, error) {
+		return m.updateOnce(ctx, pick(updates, idx))
+	})
+}
+
+func (m *Manager) updateOnce(ctx context.Context, updates []KeyUpdate) (PartialResult, error) {
+	var res PartialResult
+	offset := 0
+	for chunk := range slices.Chunk(updates, chunkSize()) {
+		resp, err := m.bulk(ctx, http.MethodPut, updateBody(chunk))
+		if err != nil {
+			return res, err
+		}
+		res.add(resp, offset, func(it client.ItemIssue) int {
+			return slices.IndexFunc(chunk, func(u KeyUpdate) bool {
+				return (it.KeyID != 0 && u.KeyID == it.KeyID) || (it.KeyID == 0 && it.Key != "" && u.Desired.Name == it.Key)
+			})
+		})
+		offset += len(chunk)
+		res.sent = offset
+	}
+	return res, nil
+}
+
+// withItemRetries calls send with all n items, then again with the items
+// that failed retryably, up to m.itemRetries times. send gets positions in
+// the original request and reports Failed indexes relative to them.
+func (m *Manager) withItemRetries(ctx context.Context, n int, send func(idx []int) (PartialResult, error)) (PartialResult, error) {
+	pending := make([]int, n)
+	for i := range pending {
+		pending[i] = i
+	}
+
+	var res PartialResult
+	cfg := m.client.RetryConfig("bulk-items")
+	cfg.MaxRetries = m.itemRetries
+	cfg.Policy = nil       // retry pending items, not the client's errors
+	cfg.AttemptTimeout = 0 // each request already gets its own
+	err := retry.WithExpBackoff(ctx, cfg,
+		func(_ context.Context, attempt int) error {
+			if attempt > 0 {
+				res.Retried += len(pending)
+			}
+			sub, err := send(pending)
+			res.merge(sub, pending)
+			if err != nil {
+				return err
+			}
+			if pending = res.retryableIndexes(); len(pending) > 0 {
+				return errItemsPending
+			}
+			return nil
+		},
+		func(err error) bool { return errors.Is(err, errItemsPending) },
+	)
+	if errors.Is(err, errItemsPending) {
+		err = nil // out of item retries; the failures are in res
+	}
+	return res, err
+}
+
+// merge adds the result of sending the items at positions idx, replacing
+// the earlier failures of those that got an answer.
+func (r *PartialResult) merge(sub PartialResult, idx []int) {
+	done := idx[:sub.sent]
+	r.Failed = slices.DeleteFunc(r.Failed, func(it client.ItemIssue) bool {
+		return it.Index >= 0 && slices.Contains(done, it.Index)
+	})
+	r.Keys = append(r.Keys, sub.Keys...)
+	for _, it := range sub.Failed {
+		if it.Index >= 0 {
+			it.Index = idx[it.Index]
+		}
+		r.Failed = append(r.Failed, it)
+	}
+}
+
+// retryableIndexes returns the positions of matched items that failed
+// transiently.
+func (r PartialResult) retryableIndexes() []int {
+	var out []int
+	for _, it := range r.Failed {
+		if it.Index >= 0 && it.Retryable() && !slices.Contains(out, it.Index) {
+			out = append(out, it.Index)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func pick[T any](items []T, idx []int) []T {
+	out := make([]T, len(idx))
+	for i, j := range idx {
+		out[i] = items[j]
+	}
+	return out
+}
+
+// add merges one bulk response; match finds an issue's position in the
+// chunk starting at offset.
+func (r *PartialResult) add(resp bulkResponse, offset int, match func(client.ItemIssue) int) {
+	r.Keys = append(r.Keys, resp.Keys...)
+	claimed := map[int]bool{}
+	for _, it := range apierr.ParseItems(map[string]any{"errors": resp.Errors}) {
+		it.Index = -1
+		if i := match(it); i >= 0 && !claimed[i] {
+			claimed[i] = true
+			it.Index = offset + i
+		}
+		r.Failed = append(r.Failed, it)
+	}
+}
+
+type bulkResponse struct {
+	Keys   []Key `json:"keys"`
+	Errors any   `json:"errors"`
+}
+
+// bulk sends one /keys bulk request.
+func (m *Manager) bulk(ctx context.Context, method string, body map[string]any) (bulkResponse, error) {
+	rdr, err := utils.EncodeJSONBodyWith(m.client.Codec, body)
+	if err != nil {
+		return bulkResponse{}, err
+	}
+
+	var raw struct {
+		Keys   []Key           `json:"keys"`
+		Errors json.RawMessage `json:"errors"`
+	}
+	path := utils.ProjectPath(m.client.ProjectID, "keys")
+	if err := m.client.DoJSONWithRetry(ctx, method, path, rdr, &raw); err != nil {
+		return bulkResponse{}, err
+	}
+
+	resp := bulkResponse{Keys: raw.Keys}
+	if len(raw.Errors) > 0 {
+		// Decoded separately with UseNumber so key IDs in errors stay exact.
+		dec := json.NewDecoder(strings.NewReader(string(raw.Errors)))
+		dec.UseNumber()
+		if err := dec.Decode(&resp.Errors); err != nil {
+			return bulkResponse{}, fmt.Errorf("decode errors: %w", err)
+		}
+	}
+	return resp, nil
+}
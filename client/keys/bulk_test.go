@@ -0,0 +1,389 @@
+package keys_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/keys"
+	"github.com/bodrovis/lokex/v2/internal/metrics"
+)
+
+// rejectingKeysAPI creates or updates every submitted key except those
+// named in reject, which come back in "errors" like Lokalise reports them.
+type rejectingKeysAPI struct {
+	mu     sync.Mutex
+	reject map[string]string // key name or ID -> message
+	bodies []string
+}
+
+func (f *rejectingKeysAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.bodies = append(f.bodies, fmt.Sprint(len(body.Keys)))
+	f.mu.Unlock()
+
+	var (
+		ok   []map[string]any
+		errs []map[string]any
+	)
+	for _, k := range body.Keys {
+		id := fmt.Sprint(k["key_id"])
+		name, _ := k["key_name"].(string)
+		switch {
+		case f.reject[name] != "":
+			errs = append(errs, map[string]any{
+				"message": f.reject[name],
+				"code":    400,
+				"key":     map[string]any{"key_name": map[string]any{"ios": name, "android": name, "web": name, "other": name}},
+			})
+		case f.reject[id] != "":
+			errs = append(errs, map[string]any{"message": f.reject[id], "code": 400, "key": map[string]any{"key_id": k["key_id"]}})
+		default:
+			if name == "" {
+				name = "key-" + id
+			}
+			ok = append(ok, map[string]any{"key_id": k["key_id"], "key_name": name})
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"keys": ok, "errors": errs})
+}
+
+func TestManager_CreateKeys_PartialResult(t *testing.T) {
+	restore := keys.ExportSetApplyChunkSizeForTest(2)
+	defer restore()
+
+	api := &rejectingKeysAPI{reject: map[string]string{
+		"b": "This key name is already taken",
+		"d": "Invalid platform",
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	desired := []keys.DesiredKey{
+		{Name: "a", Platforms: []string{"web"}},
+		{Name: "b", Platforms: []string{"web"}},
+		{Name: "c", Platforms: []string{"web"}},
+		{Name: "d", Platforms: []string{"web"}},
+		{Name: "e", Platforms: []string{"web"}},
+	}
+	res, err := keys.NewManager(newTestClient(t, srv)).CreateKeys(context.Background(), desired)
+	if err != nil {
+		t.Fatalf("CreateKeys() error = %v", err)
+	}
+
+	if len(res.Keys) != 3 {
+		t.Fatalf("Keys = %+v, want 3", res.Keys)
+	}
+	if !res.HasErrors() {
+		t.Fatal("HasErrors() = false")
+	}
+	if got := res.FailedIndexes(); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Fatalf("FailedIndexes() = %v, want [1 3]", got)
+	}
+	if res.Failed[0].Key != "b" || res.Failed[0].Code != 400 || res.Failed[1].Message != "Invalid platform" {
+		t.Fatalf("Failed = %+v", res.Failed)
+	}
+	if err := res.Err(); err == nil || !strings.Contains(err.Error(), "2 key(s) rejected: This key name is already taken; Invalid platform") {
+		t.Fatalf("Err() = %v", err)
+	}
+	if !reflect.DeepEqual(api.bodies, []string{"2", "2", "1"}) {
+		t.Fatalf("request sizes = %v, want [2 2 1]", api.bodies)
+	}
+}
+
+func TestManager_UpdateKeys_MatchesByKeyID(t *testing.T) {
+	t.Parallel()
+
+	api := &rejectingKeysAPI{reject: map[string]string{"9007199254740993": "Key is locked"}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	updates := []keys.KeyUpdate{
+		{KeyID: 1, Desired: keys.DesiredKey{Name: "a", Tags: []string{"x"}}, Changes: []string{"tags"}},
+		{KeyID: 9007199254740993, Desired: keys.DesiredKey{Name: "b", Tags: []string{"x"}}, Changes: []string{"tags"}},
+	}
+	res, err := keys.NewManager(newTestClient(t, srv)).UpdateKeys(context.Background(), updates)
+	if err != nil {
+		t.Fatalf("UpdateKeys() error = %v", err)
+	}
+	if len(res.Keys) != 1 || len(res.Failed) != 1 {
+		t.Fatalf("result = %+v", res)
+	}
+	if it := res.Failed[0]; it.Index != 1 || it.KeyID != 9007199254740993 || it.Message != "Key is locked" {
+		t.Fatalf("Failed[0] = %+v", it)
+	}
+}
+
+func TestManager_CreateKeys_UnmatchedError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"keys":[],"errors":[{"message":"Something went wrong","code":500}]}`)
+	}))
+	defer srv.Close()
+
+	res, err := keys.NewManager(newTestClient(t, srv)).CreateKeys(context.Background(), []keys.DesiredKey{{Name: "a", Platforms: []string{"web"}}})
+	if err != nil {
+		t.Fatalf("CreateKeys() error = %v", err)
+	}
+	if len(res.Failed) != 1 || res.Failed[0].Index != -1 || res.Failed[0].Code != 500 {
+		t.Fatalf("Failed = %+v", res.Failed)
+	}
+	if got := res.FailedIndexes(); got != nil {
+		t.Fatalf("FailedIndexes() = %v, want none", got)
+	}
+}
+
+func TestManager_CreateKeys_RequestError(t *testing.T) {
+	restore := keys.ExportSetApplyChunkSizeForTest(1)
+	defer restore()
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls > 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"error":{"message":"Invalid keys","code":400}}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"keys":[{"key_id":1,"key_name":"a"}]}`)
+	}))
+	defer srv.Close()
+
+	res, err := keys.NewManager(newTestClient(t, srv)).CreateKeys(context.Background(), []keys.DesiredKey{
+		{Name: "a", Platforms: []string{"web"}},
+		{Name: "b", Platforms: []string{"web"}},
+	})
+	var ae *client.APIError
+	if !errors.As(err, &ae) || ae.Status != http.StatusBadRequest {
+		t.Fatalf("CreateKeys() error = %v, want *client.APIError", err)
+	}
+	if len(res.Keys) != 1 || res.HasErrors() {
+		t.Fatalf("result = %+v, want the first chunk's key", res)
+	}
+}
+
+func TestManager_BulkKeys_NilManager(t *testing.T) {
+	t.Parallel()
+
+	var m *keys.Manager
+	if _, err := m.CreateKeys(context.Background(), nil); err == nil {
+		t.Fatal("CreateKeys() on nil manager: error = nil")
+	}
+	if _, err := m.UpdateKeys(context.Background(), nil); err == nil {
+		t.Fatal("UpdateKeys() on nil manager: error = nil")
+	}
+}
+
+func TestPartialResult_NoErrors(t *testing.T) {
+	t.Parallel()
+
+	var r keys.PartialResult
+	if r.HasErrors() || r.Err() != nil || r.FailedIndexes() != nil {
+		t.Fatalf("zero PartialResult reports errors: %+v", r)
+	}
+}
+
+// flakyKeysAPI rejects keys by name with a code for a number of attempts
+// (-1: always) and records the names sent in each request.
+type flakyKeysAPI struct {
+	mu       sync.Mutex
+	failures map[string]struct{ code, times int }
+	requests [][]string
+}
+
+func (f *flakyKeysAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Keys []struct {
+			KeyName string `json:"key_name"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var (
+		names []string
+		ok    []map[string]any
+		errs  []map[string]any
+	)
+	for _, k := range body.Keys {
+		names = append(names, k.KeyName)
+		if fl, found := f.failures[k.KeyName]; found && fl.times != 0 {
+			fl.times--
+			f.failures[k.KeyName] = fl
+			errs = append(errs, map[string]any{"message": "failed " + k.KeyName, "code": fl.code, "key": map[string]any{"key_name": k.KeyName}})
+			continue
+		}
+		ok = append(ok, map[string]any{"key_id": len(f.requests)*100 + len(ok) + 1, "key_name": k.KeyName})
+	}
+	f.requests = append(f.requests, names)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"keys": ok, "errors": errs})
+}
+
+func TestManager_WithItemRetries(t *testing.T) {
+	t.Parallel()
+
+	api := &flakyKeysAPI{failures: map[string]struct{ code, times int }{
+		"b": {http.StatusTooManyRequests, 1},     // succeeds on the first retry
+		"c": {http.StatusBadRequest, -1},         // never retried
+		"d": {http.StatusServiceUnavailable, -1}, // retried until the limit
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(0),
+		client.WithBackoff(time.Millisecond, 2*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := keys.NewManager(c)
+
+	desired := []keys.DesiredKey{
+		{Name: "a", Platforms: []string{"web"}},
+		{Name: "b", Platforms: []string{"web"}},
+		{Name: "c", Platforms: []string{"web"}},
+		{Name: "d", Platforms: []string{"web"}},
+	}
+	res, err := m.WithItemRetries(2).CreateKeys(context.Background(), desired)
+	if err != nil {
+		t.Fatalf("CreateKeys() error = %v", err)
+	}
+
+	wantRequests := [][]string{{"a", "b", "c", "d"}, {"b", "d"}, {"d"}}
+	if !reflect.DeepEqual(api.requests, wantRequests) {
+		t.Fatalf("requests = %v, want %v", api.requests, wantRequests)
+	}
+	if len(res.Keys) != 2 || res.Retried != 3 {
+		t.Fatalf("Keys = %d, Retried = %d; want 2, 3", len(res.Keys), res.Retried)
+	}
+	if got := res.FailedIndexes(); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Fatalf("FailedIndexes() = %v, want [2 3]", got)
+	}
+	if len(res.Failed) != 2 {
+		t.Fatalf("Failed = %+v, want one issue per failed item", res.Failed)
+	}
+}
+
+func TestManager_WithItemRetries_Disabled(t *testing.T) {
+	t.Parallel()
+
+	api := &flakyKeysAPI{failures: map[string]struct{ code, times int }{"a": {http.StatusTooManyRequests, 1}}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	m := keys.NewManager(newTestClient(t, srv))
+	res, err := m.WithItemRetries(-1).CreateKeys(context.Background(), []keys.DesiredKey{{Name: "a", Platforms: []string{"web"}}})
+	if err != nil {
+		t.Fatalf("CreateKeys() error = %v", err)
+	}
+	if len(api.requests) != 1 || res.Retried != 0 || !res.Failed[0].Retryable() {
+		t.Fatalf("requests = %v, result = %+v", api.requests, res)
+	}
+}
+
+// retryRecorder records the labels of retries reported to the client.
+type retryRecorder struct {
+	metrics.Nop
+	mu     sync.Mutex
+	labels []string
+}
+
+func (r *retryRecorder) Retry(label string, _ int, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.labels = append(r.labels, label)
+}
+
+func TestManager_WithItemRetries_UsesClientRetryConfig(t *testing.T) {
+	t.Parallel()
+
+	api := &flakyKeysAPI{failures: map[string]struct{ code, times int }{"a": {http.StatusTooManyRequests, 1}}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	rec := &retryRecorder{}
+	noRetries := client.RetryPolicyFunc(func(client.RetryAttempt) (time.Duration, bool) { return 0, false })
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithBackoff(time.Millisecond, 2*time.Millisecond),
+		client.WithMetrics(rec),
+		client.WithRetryPolicy(noRetries), // applies to requests, not to item rounds
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := keys.NewManager(c).WithItemRetries(1).CreateKeys(context.Background(), []keys.DesiredKey{{Name: "a", Platforms: []string{"web"}}})
+	if err != nil {
+		t.Fatalf("CreateKeys() error = %v", err)
+	}
+	if len(res.Keys) != 1 || res.Retried != 1 {
+		t.Fatalf("Keys = %d, Retried = %d; want 1, 1", len(res.Keys), res.Retried)
+	}
+	if want := []string{"bulk-items"}; !reflect.DeepEqual(rec.labels, want) {
+		t.Fatalf("retry labels = %v, want %v", rec.labels, want)
+	}
+}
+
+func TestManager_WithItemRetries_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	api := &flakyKeysAPI{failures: map[string]struct{ code, times int }{"a": {http.StatusServiceUnavailable, -1}}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj", client.WithBaseURL(srv.URL), client.WithBackoff(time.Hour, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	res, err := keys.NewManager(c).WithItemRetries(5).CreateKeys(ctx, []keys.DesiredKey{{Name: "a", Platforms: []string{"web"}}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CreateKeys() error = %v, want deadline exceeded", err)
+	}
+	if len(res.Failed) != 1 {
+		t.Fatalf("Failed = %+v, want the first round's failure", res.Failed)
+	}
+}
+
+func TestManager_WithItemRetries_NilManager(t *testing.T) {
+	t.Parallel()
+
+	var m *keys.Manager
+	if m.WithItemRetries(1) != nil {
+		t.Fatal("WithItemRetries on nil manager returned non-nil")
+	}
+}
@@ -0,0 +1,179 @@
+package keys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheStore holds encoded List results. Implementations must be safe for
+// concurrent use; a persistent store (Redis, disk, ...) only needs to honour
+// ttl on Set and drop everything on Clear.
+type CacheStore interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Clear()
+}
+
+// MemoryStore is an in-memory CacheStore with per-entry expiry.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+var cacheNow = time.Now
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Get returns the value for key if it has not expired.
+func (s *MemoryStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !cacheNow().Before(e.expires) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores value for ttl.
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{value: value, expires: cacheNow().Add(ttl)}
+}
+
+// Clear drops every entry.
+func (s *MemoryStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.entries)
+}
+
+// CachedManager is a read-through cache in front of Manager.List, for
+// applications that look keys and translations up at runtime. Concurrent
+// misses for the same query share one API call. Call Invalidate after
+// uploads, or feed webhook events to InvalidateOnEvent.
+type CachedManager struct {
+	m     *Manager
+	ttl   time.Duration
+	store CacheStore
+	group singleflight.Group
+	gen   atomic.Uint64 // bumped on invalidation; stale fetches are not stored
+}
+
+// NewCachedManager caches m's List results for ttl in store. A nil store
+// uses a new MemoryStore.
+func NewCachedManager(m *Manager, ttl time.Duration, store CacheStore) (*CachedManager, error) {
+	if m == nil || m.client == nil {
+		return nil, errors.New(managerIsNilMsg)
+	}
+	if ttl <= 0 {
+		return nil, errors.New("keys: cache: ttl must be positive")
+	}
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &CachedManager{m: m, ttl: ttl, store: store}, nil
+}
+
+// List returns the cached result for params, calling the API on a miss.
+// A caller whose ctx ends stops waiting, but the shared call goes on for
+// the others. Callers must not modify the returned slice.
+func (c *CachedManager) List(ctx context.Context, params ListParams) ([]Key, error) {
+	key := c.cacheKey(params)
+	if raw, ok := c.store.Get(key); ok {
+		var out []Key
+		if err := json.Unmarshal(raw, &out); err == nil {
+			return out, nil
+		}
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// The fetch is shared, so it must not die with whichever caller started
+	// it: it runs detached (the client's timeouts still bound it), and each
+	// caller stops waiting when its own ctx is done.
+	fetchCtx := context.WithoutCancel(ctx)
+	ch := c.group.DoChan(key, func() (any, error) {
+		gen := c.gen.Load()
+		out, err := c.m.List(fetchCtx, params)
+		if err != nil {
+			return nil, err
+		}
+		if raw, err := json.Marshal(out); err == nil && c.gen.Load() == gen {
+			c.store.Set(key, raw, c.ttl)
+		}
+		return out, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("keys: cache: %w", ctx.Err())
+	case r := <-ch:
+		if r.Err != nil {
+			return nil, fmt.Errorf("keys: cache: %w", r.Err)
+		}
+		return r.Val.([]Key), nil
+	}
+}
+
+// Invalidate drops every cached result, including ones being fetched.
+func (c *CachedManager) Invalidate() {
+	c.gen.Add(1)
+	c.store.Clear()
+}
+
+// InvalidateOnEvent invalidates the cache if a Lokalise webhook event can
+// change keys or translations (project.imported, project.key.*,
+// project.keys.*, project.translation.*, project.branch.merged, ...) and
+// reports whether it did.
+func (c *CachedManager) InvalidateOnEvent(event string) bool {
+	event = strings.ToLower(strings.TrimSpace(event))
+	for _, p := range []string{
+		"project.imported",
+		"project.key.",
+		"project.keys.",
+		"project.translation.",
+		"project.translations.",
+		"project.language.",
+		"project.languages.",
+		"project.branch.merged",
+		"project.task.closed",
+	} {
+		if event == p || (strings.HasSuffix(p, ".") && strings.HasPrefix(event, p)) {
+			c.Invalidate()
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CachedManager) cacheKey(params ListParams) string {
+	q := make(url.Values, len(params))
+	for k, v := range params {
+		q.Set(strings.TrimSpace(k), v)
+	}
+	return c.m.client.ProjectID + "?" + q.Encode()
+}
@@ -0,0 +1,220 @@
+package keys_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/keys"
+)
+
+func countingKeysServer(t *testing.T, hits *atomic.Int32, gate chan struct{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		if gate != nil {
+			<-gate
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"keys":[{"key_id":%d,"key_name":%q}]}`, n, r.URL.Query().Get("filter_tags"))
+	}))
+}
+
+func TestCachedManager_ReadThroughAndTTL(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	restore := keys.ExportSetCacheNowForTest(func() time.Time { return now })
+	defer restore()
+
+	var hits atomic.Int32
+	srv := countingKeysServer(t, &hits, nil)
+	defer srv.Close()
+
+	cm, err := keys.NewCachedManager(keys.NewManager(newTestClient(t, srv)), time.Minute, nil)
+	if err != nil {
+		t.Fatalf("NewCachedManager() error = %v", err)
+	}
+	ctx := context.Background()
+
+	first, err := cm.List(ctx, keys.ListParams{"filter_tags": "a"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	second, _ := cm.List(ctx, keys.ListParams{" filter_tags": "a"})
+	if hits.Load() != 1 || first[0].KeyID != second[0].KeyID {
+		t.Fatalf("hits = %d, keys %d/%d; want one API call", hits.Load(), first[0].KeyID, second[0].KeyID)
+	}
+
+	if _, err := cm.List(ctx, keys.ListParams{"filter_tags": "b"}); err != nil || hits.Load() != 2 {
+		t.Fatalf("different params: hits = %d, err = %v; want a new call", hits.Load(), err)
+	}
+
+	now = now.Add(time.Minute)
+	third, _ := cm.List(ctx, keys.ListParams{"filter_tags": "a"})
+	if hits.Load() != 3 || third[0].KeyID != 3 {
+		t.Fatalf("after ttl: hits = %d, key = %d; want refetch", hits.Load(), third[0].KeyID)
+	}
+
+	cm.Invalidate()
+	if _, _ = cm.List(ctx, keys.ListParams{"filter_tags": "a"}); hits.Load() != 4 {
+		t.Fatalf("after Invalidate: hits = %d, want 4", hits.Load())
+	}
+}
+
+func TestCachedManager_SharesConcurrentMisses(t *testing.T) {
+	var hits atomic.Int32
+	gate := make(chan struct{})
+	srv := countingKeysServer(t, &hits, gate)
+	defer srv.Close()
+
+	cm, err := keys.NewCachedManager(keys.NewManager(newTestClient(t, srv)), time.Minute, keys.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			if _, err := cm.List(context.Background(), nil); err != nil {
+				t.Errorf("List() error = %v", err)
+			}
+		})
+	}
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+
+	if hits.Load() != 1 {
+		t.Fatalf("hits = %d, want 1 shared call", hits.Load())
+	}
+}
+
+func TestCachedManager_CanceledCallerDoesNotFailOthers(t *testing.T) {
+	var hits atomic.Int32
+	gate := make(chan struct{})
+	srv := countingKeysServer(t, &hits, gate)
+	defer srv.Close()
+
+	cm, err := keys.NewCachedManager(keys.NewManager(newTestClient(t, srv)), time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := cm.List(ctx, nil)
+		first <- err
+	}()
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan error, 1)
+	go func() {
+		_, err := cm.List(context.Background(), nil)
+		second <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled caller: error = %v, want context.Canceled", err)
+	}
+	close(gate)
+	if err := <-second; err != nil {
+		t.Fatalf("waiting caller: error = %v, want the shared result", err)
+	}
+	if hits.Load() != 1 {
+		t.Fatalf("hits = %d, want 1 shared call", hits.Load())
+	}
+}
+
+func TestCachedManager_InvalidateDuringFetchIsNotCached(t *testing.T) {
+	var hits atomic.Int32
+	gate := make(chan struct{})
+	srv := countingKeysServer(t, &hits, gate)
+	defer srv.Close()
+
+	cm, err := keys.NewCachedManager(keys.NewManager(newTestClient(t, srv)), time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = cm.List(context.Background(), nil)
+	}()
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cm.Invalidate()
+	close(gate)
+	<-done
+
+	if _, err := cm.List(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("hits = %d, want 2 (stale result must not be cached)", hits.Load())
+	}
+}
+
+func TestCachedManager_InvalidateOnEvent(t *testing.T) {
+	t.Parallel()
+
+	store := keys.NewMemoryStore()
+	c, err := client.NewClient("tok", "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm, err := keys.NewCachedManager(keys.NewManager(c), time.Minute, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"project.imported":             true,
+		"project.key.added":            true,
+		"project.keys.deleted":         true,
+		"Project.Translation.Updated":  true,
+		"project.branch.merged":        true,
+		"project.contributor.added":    false,
+		"project.exported":             false,
+		"project.imported.extra_thing": false,
+	}
+	for ev, want := range tests {
+		store.Set("k", []byte("[]"), time.Minute)
+		if got := cm.InvalidateOnEvent(ev); got != want {
+			t.Errorf("InvalidateOnEvent(%q) = %v, want %v", ev, got, want)
+		}
+		if _, cached := store.Get("k"); cached == want {
+			t.Errorf("%q: cached = %v after event", ev, cached)
+		}
+	}
+}
+
+func TestNewCachedManager_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := keys.NewCachedManager(nil, time.Minute, nil); err == nil {
+		t.Fatal("nil manager: error = nil")
+	}
+	c, err := client.NewClient("tok", "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.NewCachedManager(keys.NewManager(c), 0, nil); err == nil {
+		t.Fatal("zero ttl: error = nil")
+	}
+}
@@ -0,0 +1,70 @@
+// Package keys provides typed helpers for the Lokalise Keys API.
+//
+// It is intentionally small: it wraps the endpoints lokex itself needs
+// (listing keys for upload safety checks and similar flows) and reuses the
+// client's retry/backoff and error handling.
+//
+// Numeric Lokalise IDs (key_id, translation_id, task_id, ...) are declared as
+// int64 in every typed struct: they can exceed 2^53, so float64 would silently
+// corrupt them.
+package keys
+
+import (
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// Manager wraps a *Client to work with project keys.
+// Construct with NewManager; the embedded client must be non-nil.
+type Manager struct {
+	client      *client.Client
+	itemRetries int // see WithItemRetries
+}
+
+// NewManager creates a new Manager bound to c.
+func NewManager(c *client.Client) *Manager {
+	if c == nil {
+		panic("lokex/keys: nil client passed to NewManager")
+	}
+	return &Manager{
+		client: c,
+	}
+}
+
+const managerIsNilMsg = "keys: manager/client is nil"
+
+// Key is a subset of the Lokalise key object.
+type Key struct {
+	KeyID       int64           `json:"key_id" lokex:"required"`
+	KeyName     PlatformStrings `json:"key_name" lokex:"required"`
+	Filenames   PlatformStrings `json:"filenames"`
+	Description string          `json:"description,omitempty"`
+	Platforms   []string        `json:"platforms,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	// Translations is only filled when listing with include_translations=1.
+	Translations []Translation `json:"translations,omitempty"`
+}
+
+// Translation is a subset of the Lokalise translation object. Plural
+// translations are a JSON-encoded object in Translation.
+type Translation struct {
+	TranslationID int64  `json:"translation_id" lokex:"required"`
+	LanguageISO   string `json:"language_iso" lokex:"required"`
+	Translation   string `json:"translation"`
+	IsReviewed    bool   `json:"is_reviewed"`
+	IsUnverified  bool   `json:"is_unverified"`
+}
+
+// Translation returns the key's translation for the language, if listed.
+func (k Key) Translation(langISO string) (Translation, bool) {
+	for _, t := range k.Translations {
+		if t.LanguageISO == langISO {
+			return t, true
+		}
+	}
+	return Translation{}, false
+}
+
+// Names returns the distinct non-empty per-platform names of the key.
+func (k Key) Names() []string {
+	return k.KeyName.Values()
+}
@@ -0,0 +1,99 @@
+package keys_test
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/keys"
+)
+
+func TestKey_IDsAbove2Pow53(t *testing.T) {
+	t.Parallel()
+
+	ids := []int64{
+		1<<53 + 1,
+		1<<62 + 7,
+		math.MaxInt64,
+	}
+
+	for _, id := range ids {
+		t.Run(strconv.FormatInt(id, 10), func(t *testing.T) {
+			t.Parallel()
+
+			raw := `{"key_id":` + strconv.FormatInt(id, 10) + `,"key_name":{"web":"k"}}`
+
+			var k keys.Key
+			if err := json.Unmarshal([]byte(raw), &k); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if k.KeyID != id {
+				t.Fatalf("KeyID = %d, want %d", k.KeyID, id)
+			}
+
+			out, err := json.Marshal(k)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			var back keys.Key
+			if err := json.Unmarshal(out, &back); err != nil {
+				t.Fatalf("Unmarshal(round-trip) error = %v", err)
+			}
+			if back.KeyID != id {
+				t.Fatalf("round-trip KeyID = %d, want %d", back.KeyID, id)
+			}
+		})
+	}
+}
+
+func TestKey_IDFieldsAreInt64(t *testing.T) {
+	t.Parallel()
+
+	// Guard against someone "simplifying" an ID to int/float64 later.
+	typ := reflect.TypeFor[keys.Key]()
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !strings.HasSuffix(name, "_id") {
+			continue
+		}
+		if f.Type.Kind() != reflect.Int64 {
+			t.Fatalf("%s.%s has type %s, want int64", typ.Name(), f.Name, f.Type)
+		}
+	}
+}
+
+func TestKey_Names(t *testing.T) {
+	t.Parallel()
+
+	k := keys.Key{KeyName: keys.PlatformStrings{IOS: "a", Web: "b", Other: "b"}}
+	got := k.Names()
+	want := []string{"a", "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Names() = %#v, want %#v", got, want)
+	}
+}
+
+func TestKey_Translation(t *testing.T) {
+	t.Parallel()
+
+	raw := `{"key_id":1,"key_name":"k","translations":[
+		{"translation_id":10,"language_iso":"en","translation":"Hi","is_reviewed":true},
+		{"translation_id":11,"language_iso":"fr","translation":"Salut"}
+	]}`
+	var k keys.Key
+	if err := json.Unmarshal([]byte(raw), &k); err != nil {
+		t.Fatal(err)
+	}
+	tr, ok := k.Translation("en")
+	if !ok || tr.TranslationID != 10 || tr.Translation != "Hi" || !tr.IsReviewed {
+		t.Fatalf("Translation(en) = %+v, %v", tr, ok)
+	}
+	if _, ok := k.Translation("de"); ok {
+		t.Fatal("Translation(de) found")
+	}
+}
@@ -0,0 +1,27 @@
+package keys
+
+import "time"
+
+func ExportSetListPageLimitForTest(n int) func() {
+	prev := listPageLimit
+	listPageLimit = n
+	return func() {
+		listPageLimit = prev
+	}
+}
+
+func ExportSetApplyChunkSizeForTest(n int) func() {
+	prev := applyChunkSize
+	applyChunkSize = n
+	return func() {
+		applyChunkSize = prev
+	}
+}
+
+func ExportSetCacheNowForTest(fn func() time.Time) func() {
+	prev := cacheNow
+	cacheNow = fn
+	return func() {
+		cacheNow = prev
+	}
+}
@@ -0,0 +1,61 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// listPageLimit is the maximum page size accepted by GET /keys.
+var listPageLimit = 5000
+
+// ListParams holds query parameters for GET /keys, e.g. filter_filenames,
+// filter_tags or include_translations. Paging params (page, limit) are
+// managed by List and ignored if present.
+type ListParams map[string]string
+
+// List returns all keys matching params, walking every page.
+func (m *Manager) List(ctx context.Context, params ListParams) ([]Key, error) {
+	if m == nil || m.client == nil {
+		return nil, errors.New(managerIsNilMsg)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	path := utils.WithQuery(utils.ProjectPath(m.client.ProjectID, "keys"), listQuery(params))
+	pages := client.NewPaginator[Key](m.client, path, "keys", client.PaginatorOptions{Limit: max(listPageLimit, 1)})
+
+	var out []Key
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("keys: list: context: %w", err)
+		}
+
+		items, err := pages.Next(ctx)
+		if errors.Is(err, client.ErrNoMorePages) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("keys: list (page %d): %w", page, err)
+		}
+		out = append(out, items...)
+	}
+}
+
+func listQuery(params ListParams) url.Values {
+	q := make(url.Values, len(params))
+	for k, v := range params {
+		k = strings.TrimSpace(k)
+		if k == "" || k == "page" || k == "limit" {
+			continue
+		}
+		q.Set(k, v)
+	}
+	return q
+}
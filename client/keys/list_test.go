@@ -0,0 +1,167 @@
+package keys_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/keys"
+)
+
+func newTestClient(t *testing.T, srv *httptest.Server) *client.Client {
+	t.Helper()
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithHTTPClient(srv.Client()),
+		client.WithMaxRetries(0),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+func TestNewManager_NilClientPanics(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewManager(nil) did not panic")
+		}
+	}()
+	_ = keys.NewManager(nil)
+}
+
+func TestManager_List(t *testing.T) {
+	t.Run("nil manager", func(t *testing.T) {
+		t.Parallel()
+
+		var m *keys.Manager
+		_, err := m.List(context.Background(), nil)
+		if err == nil || err.Error() != "keys: manager/client is nil" {
+			t.Fatalf("error = %v, want nil manager error", err)
+		}
+	})
+
+	t.Run("walks pages and forwards filters", func(t *testing.T) {
+		restore := keys.ExportSetListPageLimitForTest(2)
+		defer restore()
+
+		var hits int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+
+			if r.URL.Path != "/projects/proj/keys" {
+				t.Errorf("path = %q, want /projects/proj/keys", r.URL.Path)
+			}
+			q := r.URL.Query()
+			if got := q.Get("filter_filenames"); got != "en.json" {
+				t.Errorf("filter_filenames = %q, want en.json", got)
+			}
+			if got := q.Get("limit"); got != "2" {
+				t.Errorf("limit = %q, want 2", got)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			switch q.Get("page") {
+			case "1":
+				_, _ = fmt.Fprint(w, `{"keys":[{"key_id":1,"key_name":{"web":"a"}},{"key_id":2,"key_name":"b"}]}`)
+			case "2":
+				_, _ = fmt.Fprint(w, `{"keys":[{"key_id":9007199254740993,"key_name":{"other":"c"}}]}`)
+			default:
+				t.Errorf("unexpected page %q", q.Get("page"))
+				_, _ = fmt.Fprint(w, `{"keys":[]}`)
+			}
+		}))
+		defer srv.Close()
+
+		m := keys.NewManager(newTestClient(t, srv))
+		got, err := m.List(context.Background(), keys.ListParams{
+			"filter_filenames": "en.json",
+			"page":             "7",
+		})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if atomic.LoadInt32(&hits) != 2 {
+			t.Fatalf("hits = %d, want 2", hits)
+		}
+		if len(got) != 3 {
+			t.Fatalf("len(keys) = %d, want 3", len(got))
+		}
+		if got[1].KeyName.Web != "b" {
+			t.Fatalf("key[1] web name = %q, want b", got[1].KeyName.Web)
+		}
+		if got[2].KeyID != 9007199254740993 {
+			t.Fatalf("key[2] id = %d, want 9007199254740993", got[2].KeyID)
+		}
+	})
+
+	t.Run("api error is wrapped with page", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"error":{"message":"Not Found","code":404}}`)
+		}))
+		defer srv.Close()
+
+		m := keys.NewManager(newTestClient(t, srv))
+		_, err := m.List(context.Background(), nil)
+		if err == nil {
+			t.Fatal("List() error = nil, want non-nil")
+		}
+		if !strings.HasPrefix(err.Error(), "keys: list (page 1): ") {
+			t.Fatalf("error = %q, want page prefix", err.Error())
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		c, _ := client.NewClient("tok", "proj")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := keys.NewManager(c).List(ctx, nil)
+		if err == nil || err.Error() != "keys: list: context: context canceled" {
+			t.Fatalf("error = %v, want context error", err)
+		}
+	})
+}
+
+func TestManager_List_StrictDecoding(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"keys":[{"key_id":1,"key_name":"a"},{"id":2,"key_name":"b"}]}`))
+	}))
+	defer srv.Close()
+
+	lax, err := keys.NewManager(newTestClient(t, srv)).List(context.Background(), nil)
+	if err != nil || len(lax) != 2 || lax[1].KeyID != 0 {
+		t.Fatalf("List() = %+v, %v; want the renamed field silently dropped", lax, err)
+	}
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(0),
+		client.WithStrictDecoding(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = keys.NewManager(c).List(context.Background(), nil)
+	var se *client.SchemaError
+	if !errors.As(err, &se) || se.Path != "keys[1].key_id" || !errors.Is(err, client.ErrSchemaMismatch) {
+		t.Fatalf("strict List() error = %v, want schema error at keys[1].key_id", err)
+	}
+}
@@ -0,0 +1,363 @@
+package keys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ErrPlanRejected is returned by Apply when the confirm callback declines a
+// non-empty plan.
+var ErrPlanRejected = errors.New("keys: plan rejected")
+
+// ErrPlanConflict is returned by Plan when two desired keys match the same
+// remote key by name, e.g. through different per-platform names of it.
+var ErrPlanConflict = errors.New("keys: plan conflict")
+
+// applyChunkSize caps keys per bulk create/update/delete request.
+var applyChunkSize = 500
+
+// DesiredKey declares a key as it should exist in the project. Platforms and
+// Tags are compared as sets. Description is only managed when non-empty, so
+// descriptions edited in Lokalise are kept unless the file sets one.
+type DesiredKey struct {
+	Name        string   `json:"name"`
+	Platforms   []string `json:"platforms"`
+	Tags        []string `json:"tags,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// KeyUpdate is a planned change to an existing key.
+type KeyUpdate struct {
+	KeyID   int64
+	Desired DesiredKey
+	Changes []string // changed fields: "name", "platforms", "tags", "description"
+
+	// FromName is the key's current name when Changes includes "name";
+	// see PlanOptions.Rules.
+	FromName string
+}
+
+// Plan lists the changes Apply would make. Build it with Manager.Plan and
+// review it (String) before applying.
+type Plan struct {
+	Create []DesiredKey
+	Update []KeyUpdate
+	Delete []Key
+}
+
+// PlanOptions controls how Manager.Plan compares desired and remote keys.
+type PlanOptions struct {
+	// Filter narrows the remote keys considered (e.g. filter_tags), so a
+	// desired-state file can own part of a project.
+	Filter ListParams
+	// Prune schedules remote keys that are not declared for deletion.
+	// Without it the plan never deletes anything.
+	Prune bool
+	// Rules rewrite names before keys are compared. Desired keys are
+	// created under their rewritten names, and a remote key whose
+	// rewritten name matches a desired key (with no exact match) is
+	// renamed to it, so a migration's rename pass is part of the plan.
+	Rules NameRules
+}
+
+// ApplyResult counts the keys changed by Apply.
+type ApplyResult struct {
+	Created int
+	Updated int
+	Deleted int
+}
+
+// LoadDesired decodes a desired-state file: a JSON array of keys, or an
+// object with a "keys" array.
+func LoadDesired(r io.Reader) ([]DesiredKey, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("keys: load desired: %w", err)
+	}
+
+	var out []DesiredKey
+	if err := json.Unmarshal(raw, &out); err != nil {
+		var wrapped struct {
+			Keys []DesiredKey `json:"keys"`
+		}
+		if err2 := json.Unmarshal(raw, &wrapped); err2 != nil {
+			return nil, fmt.Errorf("keys: load desired: %w", err)
+		}
+		out = wrapped.Keys
+	}
+
+	if err := validateDesired(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func validateDesired(desired []DesiredKey) error {
+	seen := make(map[string]bool, len(desired))
+	for i, d := range desired {
+		name := strings.TrimSpace(d.Name)
+		if name == "" {
+			return fmt.Errorf("keys: desired key %d: empty name", i)
+		}
+		if len(d.Platforms) == 0 {
+			return fmt.Errorf("keys: desired key %q: no platforms", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("keys: desired key %q declared twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// Empty reports whether the plan makes no changes.
+func (p Plan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Update) == 0 && len(p.Delete) == 0
+}
+
+// String renders the plan for review, one change per line.
+func (p Plan) String() string {
+	if p.Empty() {
+		return "no changes"
+	}
+
+	var b strings.Builder
+	for _, d := range p.Create {
+		fmt.Fprintf(&b, "+ %s (platforms: %s)\n", d.Name, strings.Join(d.Platforms, ", "))
+	}
+	for _, u := range p.Update {
+		name := u.Desired.Name
+		if u.FromName != "" {
+			name = u.FromName + " -> " + name
+		}
+		fmt.Fprintf(&b, "~ %s [%d] (%s)\n", name, u.KeyID, strings.Join(u.Changes, ", "))
+	}
+	for _, k := range p.Delete {
+		fmt.Fprintf(&b, "- %s [%d]\n", strings.Join(k.Names(), "/"), k.KeyID)
+	}
+	fmt.Fprintf(&b, "%d to create, %d to update, %d to delete", len(p.Create), len(p.Update), len(p.Delete))
+	return b.String()
+}
+
+// Plan compares desired keys with the project and returns the changes needed
+// to converge. It never modifies the project. A remote key matches a desired
+// one if any of its per-platform names equals the desired name, or, with
+// opts.Rules, rewrites to it. Two desired keys matching one remote key by
+// name fail with ErrPlanConflict.
+func (m *Manager) Plan(ctx context.Context, desired []DesiredKey, opts PlanOptions) (Plan, error) {
+	if m == nil || m.client == nil {
+		return Plan{}, errors.New(managerIsNilMsg)
+	}
+	if len(opts.Rules) > 0 {
+		desired = slices.Clone(desired)
+		for i := range desired {
+			desired[i].Name = strings.TrimSpace(opts.Rules.Apply(strings.TrimSpace(desired[i].Name)))
+		}
+	}
+	if err := validateDesired(desired); err != nil {
+		return Plan{}, err
+	}
+
+	remote, err := m.List(ctx, opts.Filter)
+	if err != nil {
+		return Plan{}, fmt.Errorf("keys: plan: %w", err)
+	}
+
+	byName := make(map[string]Key, len(remote))
+	byRule := make(map[string]Key)
+	for _, k := range remote {
+		for _, n := range k.Names() {
+			if _, dup := byName[n]; !dup {
+				byName[n] = k
+			}
+			if len(opts.Rules) > 0 {
+				if rn := opts.Rules.Apply(n); rn != n {
+					if _, dup := byRule[rn]; !dup {
+						byRule[rn] = k
+					}
+				}
+			}
+		}
+	}
+
+	var plan Plan
+	matched := make(map[int64]bool, len(desired))
+	claimedBy := make(map[int64]string, len(desired))
+	var renames []DesiredKey
+	for _, d := range desired {
+		d.Name = strings.TrimSpace(d.Name)
+		k, ok := byName[d.Name]
+		if !ok {
+			renames = append(renames, d)
+			continue
+		}
+		if matched[k.KeyID] {
+			return Plan{}, fmt.Errorf("%w: desired keys %q and %q both match key %d (%s)",
+				ErrPlanConflict, claimedBy[k.KeyID], d.Name, k.KeyID, strings.Join(k.Names(), "/"))
+		}
+		matched[k.KeyID] = true
+		claimedBy[k.KeyID] = d.Name
+		if changes := diffKey(k, d); len(changes) > 0 {
+			plan.Update = append(plan.Update, KeyUpdate{KeyID: k.KeyID, Desired: d, Changes: changes})
+		}
+	}
+	// Keys matched only through the rules go second, so an exact match
+	// always wins and no key is claimed twice.
+	for _, d := range renames {
+		k, ok := byRule[d.Name]
+		if !ok || matched[k.KeyID] {
+			plan.Create = append(plan.Create, d)
+			continue
+		}
+		matched[k.KeyID] = true
+		changes := append([]string{"name"}, diffKey(k, d)...)
+		plan.Update = append(plan.Update, KeyUpdate{
+			KeyID: k.KeyID, Desired: d, Changes: changes, FromName: strings.Join(k.Names(), "/"),
+		})
+	}
+
+	if opts.Prune {
+		for _, k := range remote {
+			if !matched[k.KeyID] {
+				plan.Delete = append(plan.Delete, k)
+			}
+		}
+	}
+	return plan, nil
+}
+
+func diffKey(k Key, d DesiredKey) []string {
+	var changes []string
+	if !sameSet(k.Platforms, d.Platforms) {
+		changes = append(changes, "platforms")
+	}
+	if !sameSet(k.Tags, d.Tags) {
+		changes = append(changes, "tags")
+	}
+	if d.Description != "" && d.Description != k.Description {
+		changes = append(changes, "description")
+	}
+	return changes
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// Apply executes plan after confirm approves it. A nil confirm rejects every
+// non-empty plan; an empty plan is a no-op and confirm is not called. Keys
+// are created, then updated, then deleted, in bulk requests; on error the
+// result counts what was done before the failure. Unlike CreateKeys and
+// UpdateKeys, Apply treats rejected items as an error.
+func (m *Manager) Apply(
+	ctx context.Context,
+	plan Plan,
+	confirm func(ctx context.Context, plan Plan) (bool, error),
+) (ApplyResult, error) {
+	if m == nil || m.client == nil {
+		return ApplyResult{}, errors.New(managerIsNilMsg)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if plan.Empty() {
+		return ApplyResult{}, nil
+	}
+
+	if confirm == nil {
+		return ApplyResult{}, ErrPlanRejected
+	}
+	ok, err := confirm(ctx, plan)
+	if err != nil {
+		return ApplyResult{}, fmt.Errorf("keys: apply: confirm: %w", err)
+	}
+	if !ok {
+		return ApplyResult{}, ErrPlanRejected
+	}
+
+	var res ApplyResult
+	created, err := m.create(ctx, plan.Create)
+	res.Created = len(created.Keys)
+	if err == nil {
+		err = created.Err()
+	}
+	if err != nil {
+		return res, fmt.Errorf("keys: apply: create: %w", err)
+	}
+	updated, err := m.update(ctx, plan.Update)
+	res.Updated = len(updated.Keys)
+	if err == nil {
+		err = updated.Err()
+	}
+	if err != nil {
+		return res, fmt.Errorf("keys: apply: update: %w", err)
+	}
+	for chunk := range slices.Chunk(plan.Delete, chunkSize()) {
+		ids := make([]int64, len(chunk))
+		for i, k := range chunk {
+			ids[i] = k.KeyID
+		}
+		if _, err := m.bulk(ctx, http.MethodDelete, map[string]any{"keys": ids}); err != nil {
+			return res, fmt.Errorf("keys: apply: delete: %w", err)
+		}
+		res.Deleted += len(chunk)
+	}
+	return res, nil
+}
+
+func chunkSize() int {
+	return max(applyChunkSize, 1)
+}
+
+func createBody(chunk []DesiredKey) map[string]any {
+	items := make([]map[string]any, len(chunk))
+	for i, d := range chunk {
+		item := map[string]any{
+			"key_name":  d.Name,
+			"platforms": d.Platforms,
+			"tags":      nonNil(d.Tags),
+		}
+		if d.Description != "" {
+			item["description"] = d.Description
+		}
+		items[i] = item
+	}
+	return map[string]any{"keys": items}
+}
+
+func updateBody(chunk []KeyUpdate) map[string]any {
+	items := make([]map[string]any, len(chunk))
+	for i, u := range chunk {
+		item := map[string]any{"key_id": u.KeyID}
+		for _, c := range u.Changes {
+			switch c {
+			case "name":
+				item["key_name"] = u.Desired.Name
+			case "platforms":
+				item["platforms"] = u.Desired.Platforms
+			case "tags":
+				item["tags"] = nonNil(u.Desired.Tags)
+			case "description":
+				item["description"] = u.Desired.Description
+			}
+		}
+		items[i] = item
+	}
+	return map[string]any{"keys": items}
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
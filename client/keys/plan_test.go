@@ -0,0 +1,301 @@
+package keys_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/keys"
+)
+
+// fakeKeysAPI keeps an in-memory key set and serves the bulk /keys endpoints.
+type fakeKeysAPI struct {
+	mu     sync.Mutex
+	keys   []keys.Key
+	nextID int64
+	calls  []string
+}
+
+func (f *fakeKeysAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, r.Method)
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Keys json.RawMessage `json:"keys"`
+	}
+	if r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": f.keys})
+	case http.MethodPost:
+		var items []struct {
+			KeyName     string   `json:"key_name"`
+			Platforms   []string `json:"platforms"`
+			Tags        []string `json:"tags"`
+			Description string   `json:"description"`
+		}
+		_ = json.Unmarshal(body.Keys, &items)
+		var created []keys.Key
+		for _, it := range items {
+			f.nextID++
+			k := keys.Key{
+				KeyID:       f.nextID,
+				KeyName:     keys.PlatformStrings{IOS: it.KeyName, Android: it.KeyName, Web: it.KeyName, Other: it.KeyName},
+				Platforms:   it.Platforms,
+				Tags:        it.Tags,
+				Description: it.Description,
+			}
+			f.keys = append(f.keys, k)
+			created = append(created, k)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": created})
+	case http.MethodPut:
+		var items []map[string]json.RawMessage
+		_ = json.Unmarshal(body.Keys, &items)
+		var updated []keys.Key
+		for _, it := range items {
+			var id int64
+			_ = json.Unmarshal(it["key_id"], &id)
+			for i := range f.keys {
+				if f.keys[i].KeyID != id {
+					continue
+				}
+				if v, ok := it["key_name"]; ok {
+					var name string
+					_ = json.Unmarshal(v, &name)
+					f.keys[i].KeyName = keys.PlatformStrings{IOS: name, Android: name, Web: name, Other: name}
+				}
+				if v, ok := it["platforms"]; ok {
+					_ = json.Unmarshal(v, &f.keys[i].Platforms)
+				}
+				if v, ok := it["tags"]; ok {
+					_ = json.Unmarshal(v, &f.keys[i].Tags)
+				}
+				if v, ok := it["description"]; ok {
+					_ = json.Unmarshal(v, &f.keys[i].Description)
+				}
+				updated = append(updated, f.keys[i])
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": updated})
+	case http.MethodDelete:
+		var ids []int64
+		_ = json.Unmarshal(body.Keys, &ids)
+		kept := f.keys[:0]
+		for _, k := range f.keys {
+			if !slices.Contains(ids, k.KeyID) {
+				kept = append(kept, k)
+			}
+		}
+		f.keys = kept
+		_, _ = fmt.Fprint(w, `{"keys_removed":true}`)
+	}
+}
+
+func TestManager_PlanApply_Converges(t *testing.T) {
+	restore := keys.ExportSetApplyChunkSizeForTest(1)
+	defer restore()
+
+	api := &fakeKeysAPI{
+		nextID: 100,
+		keys: []keys.Key{
+			{KeyID: 1, KeyName: keys.PlatformStrings{Web: "home.title"}, Platforms: []string{"web"}, Tags: []string{"b", "a"}, Description: "kept"},
+			{KeyID: 2, KeyName: keys.PlatformStrings{Web: "home.subtitle"}, Platforms: []string{"web"}},
+			{KeyID: 3, KeyName: keys.PlatformStrings{Web: "old.key"}, Platforms: []string{"web"}},
+		},
+	}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	m := keys.NewManager(newTestClient(t, srv))
+
+	desired, err := keys.LoadDesired(strings.NewReader(`{"keys":[
+		{"name":"home.title","platforms":["web"],"tags":["a","b"]},
+		{"name":"home.subtitle","platforms":["web","ios"],"description":"Shown under the title"},
+		{"name":"new.key","platforms":["web"],"tags":["new"]},
+		{"name":"new.other","platforms":["ios"]}
+	]}`))
+	if err != nil {
+		t.Fatalf("LoadDesired() error = %v", err)
+	}
+
+	plan, err := m.Plan(context.Background(), desired, keys.PlanOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	wantPlan := "+ new.key (platforms: web)\n" +
+		"+ new.other (platforms: ios)\n" +
+		"~ home.subtitle [2] (platforms, description)\n" +
+		"- old.key [3]\n" +
+		"2 to create, 1 to update, 1 to delete"
+	if plan.String() != wantPlan {
+		t.Fatalf("plan =\n%s\nwant\n%s", plan, wantPlan)
+	}
+
+	var confirmed bool
+	res, err := m.Apply(context.Background(), plan, func(_ context.Context, p keys.Plan) (bool, error) {
+		confirmed = true
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if !confirmed {
+		t.Fatal("confirm was not called")
+	}
+	if want := (keys.ApplyResult{Created: 2, Updated: 1, Deleted: 1}); res != want {
+		t.Fatalf("Apply() = %+v, want %+v", res, want)
+	}
+	wantCalls := []string{"GET", "POST", "POST", "PUT", "DELETE"}
+	if !reflect.DeepEqual(api.calls, wantCalls) {
+		t.Fatalf("calls = %v, want %v", api.calls, wantCalls)
+	}
+
+	again, err := m.Plan(context.Background(), desired, keys.PlanOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("second Plan() error = %v", err)
+	}
+	if !again.Empty() || again.String() != "no changes" {
+		t.Fatalf("second plan = %s, want no changes", again)
+	}
+
+	res, err = m.Apply(context.Background(), again, nil)
+	if err != nil || res != (keys.ApplyResult{}) {
+		t.Fatalf("Apply(empty) = %+v, %v, want no-op", res, err)
+	}
+}
+
+func TestManager_Plan_NoPruneKeepsUndeclared(t *testing.T) {
+	api := &fakeKeysAPI{keys: []keys.Key{
+		{KeyID: 3, KeyName: keys.PlatformStrings{Web: "old.key"}, Platforms: []string{"web"}},
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	plan, err := keys.NewManager(newTestClient(t, srv)).Plan(context.Background(), nil, keys.PlanOptions{})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if !plan.Empty() {
+		t.Fatalf("plan = %s, want empty without Prune", plan)
+	}
+}
+
+func TestManager_Plan_TwoDesiredMatchOneKey(t *testing.T) {
+	api := &fakeKeysAPI{keys: []keys.Key{
+		{KeyID: 3, KeyName: keys.PlatformStrings{Web: "home.title", IOS: "HomeTitle"}, Platforms: []string{"web", "ios"}},
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	_, err := keys.NewManager(newTestClient(t, srv)).Plan(context.Background(), []keys.DesiredKey{
+		{Name: "home.title", Platforms: []string{"web"}},
+		{Name: "HomeTitle", Platforms: []string{"ios"}},
+	}, keys.PlanOptions{})
+	if !errors.Is(err, keys.ErrPlanConflict) || !strings.Contains(err.Error(), `"home.title" and "HomeTitle"`) {
+		t.Fatalf("Plan() error = %v, want ErrPlanConflict naming both keys", err)
+	}
+}
+
+func TestManager_Apply_Rejected(t *testing.T) {
+	api := &fakeKeysAPI{}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	m := keys.NewManager(newTestClient(t, srv))
+	plan := keys.Plan{Create: []keys.DesiredKey{{Name: "a", Platforms: []string{"web"}}}}
+
+	if _, err := m.Apply(context.Background(), plan, nil); !errors.Is(err, keys.ErrPlanRejected) {
+		t.Fatalf("nil confirm: error = %v, want ErrPlanRejected", err)
+	}
+
+	_, err := m.Apply(context.Background(), plan, func(context.Context, keys.Plan) (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, keys.ErrPlanRejected) {
+		t.Fatalf("declined: error = %v, want ErrPlanRejected", err)
+	}
+
+	boom := errors.New("boom")
+	_, err = m.Apply(context.Background(), plan, func(context.Context, keys.Plan) (bool, error) {
+		return false, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("confirm error = %v, want boom", err)
+	}
+	if len(api.calls) != 0 {
+		t.Fatalf("calls = %v, want none", api.calls)
+	}
+}
+
+func TestManager_Apply_ReportsItemErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"keys":[{"key_id":1,"key_name":"a"}],"errors":[{"message":"This key name is already taken"}]}`)
+	}))
+	defer srv.Close()
+
+	plan := keys.Plan{Create: []keys.DesiredKey{
+		{Name: "a", Platforms: []string{"web"}},
+		{Name: "b", Platforms: []string{"web"}},
+	}}
+	res, err := keys.NewManager(newTestClient(t, srv)).Apply(context.Background(), plan,
+		func(context.Context, keys.Plan) (bool, error) { return true, nil })
+	if err == nil || !strings.Contains(err.Error(), "1 key(s) rejected: This key name is already taken") {
+		t.Fatalf("Apply() error = %v, want rejected item error", err)
+	}
+	if res.Created != 1 {
+		t.Fatalf("Created = %d, want 1", res.Created)
+	}
+}
+
+func TestLoadDesired_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"not json":       `nope`,
+		"empty name":     `[{"name":" ","platforms":["web"]}]`,
+		"no platforms":   `[{"name":"a"}]`,
+		"duplicate name": `[{"name":"a","platforms":["web"]},{"name":"a","platforms":["ios"]}]`,
+	}
+	for name, in := range tests {
+		if _, err := keys.LoadDesired(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: LoadDesired() error = nil", name)
+		}
+	}
+
+	got, err := keys.LoadDesired(strings.NewReader(`[{"name":"a","platforms":["web"]}]`))
+	if err != nil || len(got) != 1 || got[0].Name != "a" {
+		t.Fatalf("LoadDesired(array) = %+v, %v", got, err)
+	}
+}
+
+func TestManager_PlanApply_NilManager(t *testing.T) {
+	t.Parallel()
+
+	var m *keys.Manager
+	if _, err := m.Plan(context.Background(), nil, keys.PlanOptions{}); err == nil {
+		t.Fatal("Plan() on nil manager error = nil")
+	}
+	if _, err := m.Apply(context.Background(), keys.Plan{}, nil); err == nil {
+		t.Fatal("Apply() on nil manager error = nil")
+	}
+}
@@ -0,0 +1,83 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// PlatformStrings holds per-platform values such as key names and filenames.
+// Lokalise returns these as an object, but accepts (and sometimes returns)
+// a plain string when the project is not per-platform.
+type PlatformStrings struct {
+	IOS     string `json:"ios,omitempty"`
+	Android string `json:"android,omitempty"`
+	Web     string `json:"web,omitempty"`
+	Other   string `json:"other,omitempty"`
+}
+
+// UnmarshalJSON accepts either an object with platform fields or a string,
+// in which case the same value is used for every platform.
+func (p *PlatformStrings) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		*p = PlatformStrings{}
+		return nil
+	}
+
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*p = PlatformStrings{IOS: s, Android: s, Web: s, Other: s}
+		return nil
+	}
+
+	type plain PlatformStrings
+	var v plain
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("platform strings: %w", err)
+	}
+	*p = PlatformStrings(v)
+	return nil
+}
+
+// Values returns the distinct non-empty values in ios, android, web, other order.
+func (p PlatformStrings) Values() []string {
+	out := make([]string, 0, 4)
+	seen := make(map[string]struct{}, 4)
+	for _, v := range []string{p.IOS, p.Android, p.Web, p.Other} {
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
+
+// For returns the value for platform (ios, android, web or other), falling
+// back to the first non-empty value when that platform has none.
+func (p PlatformStrings) For(platform string) string {
+	var v string
+	switch platform {
+	case "ios":
+		v = p.IOS
+	case "android":
+		v = p.Android
+	case "web":
+		v = p.Web
+	case "other":
+		v = p.Other
+	}
+	if v == "" {
+		if vals := p.Values(); len(vals) > 0 {
+			v = vals[0]
+		}
+	}
+	return v
+}
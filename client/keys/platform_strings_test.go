@@ -0,0 +1,88 @@
+package keys_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/keys"
+)
+
+func TestPlatformStrings_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want keys.PlatformStrings
+	}{
+		{
+			name: "object",
+			in:   `{"ios":"a.ios","android":"a_android","web":"a.web","other":"a.other"}`,
+			want: keys.PlatformStrings{IOS: "a.ios", Android: "a_android", Web: "a.web", Other: "a.other"},
+		},
+		{
+			name: "string fans out to every platform",
+			in:   `"welcome"`,
+			want: keys.PlatformStrings{IOS: "welcome", Android: "welcome", Web: "welcome", Other: "welcome"},
+		},
+		{
+			name: "null",
+			in:   `null`,
+			want: keys.PlatformStrings{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got keys.PlatformStrings
+			if err := json.Unmarshal([]byte(tt.in), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid type", func(t *testing.T) {
+		t.Parallel()
+
+		var got keys.PlatformStrings
+		if err := json.Unmarshal([]byte(`42`), &got); err == nil {
+			t.Fatal("Unmarshal() error = nil, want non-nil")
+		}
+	})
+}
+
+func TestPlatformStrings_Values(t *testing.T) {
+	t.Parallel()
+
+	p := keys.PlatformStrings{IOS: "a", Android: "b", Web: "a", Other: ""}
+	got := p.Values()
+	want := []string{"a", "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Values() = %#v, want %#v", got, want)
+	}
+}
+
+func TestPlatformStrings_For(t *testing.T) {
+	t.Parallel()
+
+	p := keys.PlatformStrings{IOS: "a.ios", Web: "a.web"}
+	for platform, want := range map[string]string{
+		"ios":     "a.ios",
+		"web":     "a.web",
+		"android": "a.ios", // empty: first non-empty value
+		"":        "a.ios",
+	} {
+		if got := p.For(platform); got != want {
+			t.Fatalf("For(%q) = %q, want %q", platform, got, want)
+		}
+	}
+	if got := (keys.PlatformStrings{}).For("web"); got != "" {
+		t.Fatalf("empty For() = %q", got)
+	}
+}
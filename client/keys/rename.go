@@ -0,0 +1,162 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrRenameCollision is returned by RenameKeys when the mapping would give
+// two keys the same name. Nothing is renamed.
+var ErrRenameCollision = errors.New("keys: rename collision")
+
+// RenameOptions controls RenameKeys.
+type RenameOptions struct {
+	// Filter narrows the remote keys looked up (e.g. filter_filenames).
+	// Collisions are only detected among these keys.
+	Filter ListParams
+	// DryRun returns the plan without renaming anything.
+	DryRun bool
+}
+
+// Rename is one planned key rename.
+type Rename struct {
+	KeyID int64
+	From  string
+	To    string
+}
+
+// RenamePlan lists what RenameKeys does, or would do in a dry run.
+type RenamePlan struct {
+	// Renames are ordered by From.
+	Renames []Rename
+	// Missing are the mapping's names that match no remote key; they are
+	// skipped.
+	Missing []string
+	// Collisions describe targets that are taken, by an existing key or by
+	// another rename. Any collision fails the whole run.
+	Collisions []string
+}
+
+// RenameResult is the outcome of RenameKeys.
+type RenameResult struct {
+	Plan RenamePlan
+	// PartialResult holds the renamed keys and the items Lokalise rejected;
+	// it is empty in a dry run.
+	PartialResult
+}
+
+// String renders the plan for review, one rename per line.
+func (p RenamePlan) String() string {
+	var b strings.Builder
+	for _, r := range p.Renames {
+		fmt.Fprintf(&b, "~ %s -> %s [%d]\n", r.From, r.To, r.KeyID)
+	}
+	for _, n := range p.Missing {
+		fmt.Fprintf(&b, "? %s (not found)\n", n)
+	}
+	for _, c := range p.Collisions {
+		fmt.Fprintf(&b, "! %s\n", c)
+	}
+	fmt.Fprintf(&b, "%d to rename, %d missing, %d collisions", len(p.Renames), len(p.Missing), len(p.Collisions))
+	return b.String()
+}
+
+// RenameKeys renames keys per mapping (current name to new name), in bulk
+// requests of up to 500, for one-off migrations. Unlike Plan with Rules, it
+// touches nothing but names.
+//
+// The whole mapping is checked before anything is sent: if a new name is
+// already used by another key, or two keys would get the same name, it
+// returns ErrRenameCollision along with the plan. Renaming onto a name that
+// another entry frees, as in a swap, counts as a collision too; split such
+// mappings into separate runs. Identity entries are ignored.
+//
+// Rejected items are reported in the result, as in UpdateKeys; the error is
+// for collisions and failed requests.
+func (m *Manager) RenameKeys(ctx context.Context, mapping map[string]string, opts RenameOptions) (RenameResult, error) {
+	if m == nil || m.client == nil {
+		return RenameResult{}, errors.New(managerIsNilMsg)
+	}
+
+	from := make([]string, 0, len(mapping))
+	for f, to := range mapping {
+		f, to = strings.TrimSpace(f), strings.TrimSpace(to)
+		if f == "" || to == "" {
+			return RenameResult{}, fmt.Errorf("keys: rename: empty name in %q -> %q", f, to)
+		}
+		if f != to {
+			from = append(from, f)
+		}
+	}
+	slices.Sort(from)
+
+	remote, err := m.List(ctx, opts.Filter)
+	if err != nil {
+		return RenameResult{}, fmt.Errorf("keys: rename: %w", err)
+	}
+
+	plan := planRenames(remote, mapping, from)
+	res := RenameResult{Plan: plan}
+	if len(plan.Collisions) > 0 {
+		return res, fmt.Errorf("%w: %s", ErrRenameCollision, strings.Join(plan.Collisions, "; "))
+	}
+	if opts.DryRun || len(plan.Renames) == 0 {
+		return res, nil
+	}
+
+	updates := make([]KeyUpdate, len(plan.Renames))
+	for i, r := range plan.Renames {
+		updates[i] = KeyUpdate{KeyID: r.KeyID, Desired: DesiredKey{Name: r.To}, Changes: []string{"name"}, FromName: r.From}
+	}
+	res.PartialResult, err = m.update(ctx, updates)
+	if err != nil {
+		return res, fmt.Errorf("keys: rename: %w", err)
+	}
+	return res, nil
+}
+
+// planRenames matches the names in from (sorted keys of mapping, trimmed)
+// to remote keys and checks the new names for collisions.
+func planRenames(remote []Key, mapping map[string]string, from []string) RenamePlan {
+	byName := make(map[string]Key, len(remote))
+	for _, k := range remote {
+		for _, n := range k.Names() {
+			if _, dup := byName[n]; !dup {
+				byName[n] = k
+			}
+		}
+	}
+	target := make(map[string]string, len(mapping))
+	for f, to := range mapping {
+		target[strings.TrimSpace(f)] = strings.TrimSpace(to)
+	}
+
+	var plan RenamePlan
+	claimed := make(map[string]string, len(from)) // new name -> old name
+	renamed := make(map[int64]string, len(from))
+	for _, f := range from {
+		k, ok := byName[f]
+		if !ok {
+			plan.Missing = append(plan.Missing, f)
+			continue
+		}
+		to := target[f]
+		if prev, dup := renamed[k.KeyID]; dup {
+			plan.Collisions = append(plan.Collisions, fmt.Sprintf("%s and %s are names of the same key [%d]", prev, f, k.KeyID))
+			continue
+		}
+		renamed[k.KeyID] = f
+		if other, taken := byName[to]; taken && other.KeyID != k.KeyID {
+			plan.Collisions = append(plan.Collisions, fmt.Sprintf("%s -> %s: name taken by key [%d]", f, to, other.KeyID))
+		}
+		if prev, dup := claimed[to]; dup {
+			plan.Collisions = append(plan.Collisions, fmt.Sprintf("%s and %s both renamed to %s", prev, f, to))
+		}
+		claimed[to] = f
+		plan.Renames = append(plan.Renames, Rename{KeyID: k.KeyID, From: f, To: to})
+	}
+	return plan
+}
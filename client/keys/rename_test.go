@@ -0,0 +1,117 @@
+package keys_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/keys"
+)
+
+func renameFixture(t *testing.T) (*fakeKeysAPI, *keys.Manager) {
+	t.Helper()
+	api := &fakeKeysAPI{
+		keys: []keys.Key{
+			{KeyID: 1, KeyName: keys.PlatformStrings{Web: "old.title"}, Platforms: []string{"web"}},
+			{KeyID: 2, KeyName: keys.PlatformStrings{Web: "old.subtitle"}, Platforms: []string{"web"}},
+			{KeyID: 3, KeyName: keys.PlatformStrings{IOS: "ios.footer", Web: "web.footer"}, Platforms: []string{"ios", "web"}},
+			{KeyID: 4, KeyName: keys.PlatformStrings{Web: "home.title"}, Platforms: []string{"web"}},
+		},
+	}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return api, keys.NewManager(newTestClient(t, srv))
+}
+
+func TestManager_RenameKeys(t *testing.T) {
+	restore := keys.ExportSetApplyChunkSizeForTest(1)
+	defer restore()
+
+	api, m := renameFixture(t)
+	mapping := map[string]string{
+		"old.title":      "home.heading",
+		" old.subtitle ": "home.subtitle",
+		"gone":           "whatever",
+		"home.title":     "home.title", // identity, ignored
+	}
+
+	dry, err := m.RenameKeys(context.Background(), mapping, keys.RenameOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("RenameKeys(dry run) error = %v", err)
+	}
+	want := "~ old.subtitle -> home.subtitle [2]\n" +
+		"~ old.title -> home.heading [1]\n" +
+		"? gone (not found)\n" +
+		"2 to rename, 1 missing, 0 collisions"
+	if got := dry.Plan.String(); got != want {
+		t.Fatalf("plan =\n%s\nwant\n%s", got, want)
+	}
+	if len(dry.Keys) != 0 || len(api.calls) != 1 {
+		t.Fatalf("dry run sent %v, renamed %d keys", api.calls, len(dry.Keys))
+	}
+
+	res, err := m.RenameKeys(context.Background(), mapping, keys.RenameOptions{})
+	if err != nil {
+		t.Fatalf("RenameKeys() error = %v", err)
+	}
+	if len(res.Keys) != 2 || res.HasErrors() {
+		t.Fatalf("result = %+v, want 2 renamed", res.PartialResult)
+	}
+	// The dry run's listing, this run's, then one PUT per key with a chunk size of 1.
+	if want := []string{"GET", "GET", "PUT", "PUT"}; !reflect.DeepEqual(api.calls, want) {
+		t.Fatalf("calls = %v, want %v", api.calls, want)
+	}
+	var names []string
+	for _, k := range api.keys {
+		names = append(names, k.KeyName.Web)
+	}
+	if want := []string{"home.heading", "home.subtitle", "web.footer", "home.title"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+}
+
+func TestManager_RenameKeys_Collisions(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		want    string
+	}{
+		{"taken by another key", map[string]string{"old.title": "home.title"}, "old.title -> home.title: name taken by key [4]"},
+		{"same target", map[string]string{"old.title": "new", "old.subtitle": "new"}, "old.subtitle and old.title both renamed to new"},
+		{"swap", map[string]string{"old.title": "old.subtitle", "old.subtitle": "old.title"}, "name taken by key [2]"},
+		{"two names of one key", map[string]string{"ios.footer": "a", "web.footer": "b"}, "ios.footer and web.footer are names of the same key [3]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, m := renameFixture(t)
+
+			res, err := m.RenameKeys(context.Background(), tt.mapping, keys.RenameOptions{})
+			if !errors.Is(err, keys.ErrRenameCollision) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("RenameKeys() error = %v, want ErrRenameCollision with %q", err, tt.want)
+			}
+			if len(res.Plan.Collisions) == 0 {
+				t.Fatal("plan has no collisions")
+			}
+			if len(api.calls) != 1 {
+				t.Fatalf("calls = %v, want only the listing", api.calls)
+			}
+		})
+	}
+
+	t.Run("a key's own other name is free", func(t *testing.T) {
+		_, m := renameFixture(t)
+		if _, err := m.RenameKeys(context.Background(), map[string]string{"ios.footer": "web.footer"}, keys.RenameOptions{DryRun: true}); err != nil {
+			t.Fatalf("RenameKeys() error = %v", err)
+		}
+	})
+}
+
+func TestManager_RenameKeys_EmptyName(t *testing.T) {
+	_, m := renameFixture(t)
+	if _, err := m.RenameKeys(context.Background(), map[string]string{"old.title": " "}, keys.RenameOptions{}); err == nil {
+		t.Fatal("RenameKeys() error = nil, want error for an empty name")
+	}
+}
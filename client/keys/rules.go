@@ -0,0 +1,180 @@
+package keys
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// NameRule rewrites a key name, e.g. as part of migrating keys from another
+// tool into Lokalise's naming scheme.
+type NameRule func(name string) string
+
+// NameRules applies its rules in order. Build them from StripPrefix,
+// ReplaceSeparators, MapCase and your own NameRule funcs:
+//
+//	rules := keys.NameRules{
+//		keys.StripPrefix("legacy_", "old_"),
+//		keys.ReplaceSeparators(".", "::", "/"),
+//		keys.MapCase(keys.CaseSnake),
+//	}
+//	rules.Apply("old_HomeScreen::TitleText") // "home_screen.title_text"
+type NameRules []NameRule
+
+// Apply returns name rewritten by every rule in turn. Nil rules are
+// skipped.
+func (rs NameRules) Apply(name string) string {
+	for _, r := range rs {
+		if r != nil {
+			name = r(name)
+		}
+	}
+	return name
+}
+
+// StripPrefix removes the first of prefixes that name starts with, once.
+// Empty prefixes are ignored.
+func StripPrefix(prefixes ...string) NameRule {
+	return func(name string) string {
+		for _, p := range prefixes {
+			if p != "" && strings.HasPrefix(name, p) {
+				return strings.TrimPrefix(name, p)
+			}
+		}
+		return name
+	}
+}
+
+// ReplaceSeparators replaces every occurrence of the from separators with
+// to. Longer separators win where they overlap, so "::" is replaced as a
+// whole rather than as two ":".
+func ReplaceSeparators(to string, from ...string) NameRule {
+	from = slices.Clone(from)
+	slices.SortStableFunc(from, func(a, b string) int { return len(b) - len(a) })
+	var pairs []string
+	for _, f := range from {
+		if f != "" {
+			pairs = append(pairs, f, to)
+		}
+	}
+	if len(pairs) == 0 {
+		return func(name string) string { return name }
+	}
+	r := strings.NewReplacer(pairs...)
+	return r.Replace
+}
+
+// Case is a case style for MapCase.
+type Case int
+
+const (
+	// CaseLower lowercases the whole name.
+	CaseLower Case = iota
+	// CaseUpper uppercases the whole name.
+	CaseUpper
+	// CaseSnake joins lowercased words with "_": "home_title".
+	CaseSnake
+	// CaseKebab joins lowercased words with "-": "home-title".
+	CaseKebab
+	// CaseCamel joins words capitalized after the first: "homeTitle".
+	CaseCamel
+)
+
+func (c Case) String() string {
+	switch c {
+	case CaseLower:
+		return "lower"
+	case CaseUpper:
+		return "upper"
+	case CaseSnake:
+		return "snake"
+	case CaseKebab:
+		return "kebab"
+	case CaseCamel:
+		return "camel"
+	}
+	return fmt.Sprintf("Case(%d)", int(c))
+}
+
+// segmentSeps separate the levels of nested key names; MapCase keeps them
+// and converts each level on its own.
+const segmentSeps = "./"
+
+// MapCase converts name to case c; an unknown Case leaves it as is. For
+// the word-based cases, words are split at "_", "-", spaces and camel humps
+// ("HTMLParser" is "HTML" and "Parser"), within each "."- or "/"-separated
+// level of the name, so "Home.TitleText" becomes "home.title_text" in
+// CaseSnake.
+func MapCase(c Case) NameRule {
+	return func(name string) string {
+		switch c {
+		case CaseLower:
+			return strings.ToLower(name)
+		case CaseUpper:
+			return strings.ToUpper(name)
+		case CaseSnake, CaseKebab, CaseCamel:
+		default:
+			return name
+		}
+
+		var b strings.Builder
+		start := 0
+		for i, r := range name {
+			if strings.ContainsRune(segmentSeps, r) {
+				b.WriteString(joinWords(splitWords(name[start:i]), c))
+				b.WriteRune(r)
+				start = i + 1
+			}
+		}
+		b.WriteString(joinWords(splitWords(name[start:]), c))
+		return b.String()
+	}
+}
+
+// splitWords splits s at "_", "-", spaces and camel humps.
+func splitWords(s string) []string {
+	rs := []rune(s)
+	var words []string
+	cur := 0
+	flush := func(end int) {
+		if end > cur {
+			words = append(words, string(rs[cur:end]))
+		}
+	}
+	for i, r := range rs {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			flush(i)
+			cur = i + 1
+		case i > cur && unicode.IsUpper(r):
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush(i)
+				cur = i
+			}
+		}
+	}
+	flush(len(rs))
+	return words
+}
+
+func joinWords(words []string, c Case) string {
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if c == CaseCamel && i > 0 {
+			r := []rune(w)
+			r[0] = unicode.ToUpper(r[0])
+			w = string(r)
+		}
+		words[i] = w
+	}
+	switch c {
+	case CaseKebab:
+		return strings.Join(words, "-")
+	case CaseCamel:
+		return strings.Join(words, "")
+	}
+	return strings.Join(words, "_")
+}
@@ -0,0 +1,129 @@
+package keys_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/keys"
+)
+
+func TestNameRules_Apply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		rules keys.NameRules
+		in    string
+		want  string
+	}{
+		{"empty", nil, "Home.Title", "Home.Title"},
+		{"strip first matching prefix once", keys.NameRules{keys.StripPrefix("", "old_", "legacy_")}, "old_old_title", "old_title"},
+		{"no prefix", keys.NameRules{keys.StripPrefix("old_")}, "title_old_", "title_old_"},
+		{"separators, longest first", keys.NameRules{keys.ReplaceSeparators(".", ":", "::", "/")}, "a::b:c/d", "a.b.c.d"},
+		{"no separators", keys.NameRules{keys.ReplaceSeparators(".")}, "a::b", "a::b"},
+		{"lower", keys.NameRules{keys.MapCase(keys.CaseLower)}, "Home.TITLE", "home.title"},
+		{"upper", keys.NameRules{keys.MapCase(keys.CaseUpper)}, "home.title", "HOME.TITLE"},
+		{"snake", keys.NameRules{keys.MapCase(keys.CaseSnake)}, "HomeScreen.HTMLParser/item2Count", "home_screen.html_parser/item2_count"},
+		{"snake from kebab and spaces", keys.NameRules{keys.MapCase(keys.CaseSnake)}, "sign-in  button", "sign_in_button"},
+		{"kebab", keys.NameRules{keys.MapCase(keys.CaseKebab)}, "signIn_Button", "sign-in-button"},
+		{"camel", keys.NameRules{keys.MapCase(keys.CaseCamel)}, "sign_in_button.ÉTAT_final", "signInButton.étatFinal"},
+		{"unknown case", keys.NameRules{keys.MapCase(keys.Case(42))}, "Sign_In", "Sign_In"},
+		{"nil rule skipped", keys.NameRules{nil, keys.MapCase(keys.CaseLower)}, "A", "a"},
+		{
+			"pipeline",
+			keys.NameRules{keys.StripPrefix("legacy_", "old_"), keys.ReplaceSeparators(".", "::", "/"), keys.MapCase(keys.CaseSnake)},
+			"old_HomeScreen::TitleText",
+			"home_screen.title_text",
+		},
+		{
+			"custom rule",
+			keys.NameRules{keys.NameRule(strings.TrimSpace), func(n string) string { return "app." + n }},
+			" title ",
+			"app.title",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.rules.Apply(tt.in); got != tt.want {
+				t.Fatalf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCase_String(t *testing.T) {
+	t.Parallel()
+
+	for c, want := range map[keys.Case]string{
+		keys.CaseLower: "lower", keys.CaseUpper: "upper", keys.CaseSnake: "snake",
+		keys.CaseKebab: "kebab", keys.CaseCamel: "camel", keys.Case(9): "Case(9)",
+	} {
+		if got := c.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestManager_Plan_Rules(t *testing.T) {
+	api := &fakeKeysAPI{
+		nextID: 100,
+		keys: []keys.Key{
+			{KeyID: 1, KeyName: keys.PlatformStrings{Web: "old_HomeTitle"}, Platforms: []string{"web"}},
+			{KeyID: 2, KeyName: keys.PlatformStrings{Web: "home_subtitle"}, Platforms: []string{"web"}},
+			{KeyID: 3, KeyName: keys.PlatformStrings{Web: "old_HomeSubtitle"}, Platforms: []string{"web"}},
+			{KeyID: 4, KeyName: keys.PlatformStrings{Web: "old_Footer"}, Platforms: []string{"web"}},
+		},
+	}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	m := keys.NewManager(newTestClient(t, srv))
+
+	rules := keys.NameRules{keys.StripPrefix("old_"), keys.MapCase(keys.CaseSnake)}
+	desired := []keys.DesiredKey{
+		{Name: "old_HomeTitle", Platforms: []string{"web", "ios"}},
+		{Name: "HomeSubtitle", Platforms: []string{"web"}},
+		{Name: "SignIn", Platforms: []string{"web"}},
+	}
+	opts := keys.PlanOptions{Rules: rules, Prune: true}
+
+	plan, err := m.Plan(context.Background(), desired, opts)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	// home_subtitle matches key 2 exactly, so key 3 isn't renamed onto it
+	// and is deleted like any undeclared key.
+	want := "+ sign_in (platforms: web)\n" +
+		"~ old_HomeTitle -> home_title [1] (name, platforms)\n" +
+		"- old_HomeSubtitle [3]\n" +
+		"- old_Footer [4]\n" +
+		"1 to create, 1 to update, 2 to delete"
+	if plan.String() != want {
+		t.Fatalf("plan =\n%s\nwant\n%s", plan, want)
+	}
+	if u := plan.Update[0]; u.FromName != "old_HomeTitle" || u.Desired.Name != "home_title" {
+		t.Fatalf("update = %+v", u)
+	}
+	if desired[0].Name != "old_HomeTitle" {
+		t.Fatalf("Plan() modified desired: %q", desired[0].Name)
+	}
+
+	if _, err := m.Apply(context.Background(), plan, func(context.Context, keys.Plan) (bool, error) { return true, nil }); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	again, err := m.Plan(context.Background(), desired, opts)
+	if err != nil {
+		t.Fatalf("second Plan() error = %v", err)
+	}
+	if !again.Empty() {
+		t.Fatalf("second plan = %s, want no changes", again)
+	}
+
+	// Names that collapse under the rules are duplicates.
+	dup := []keys.DesiredKey{{Name: "SignIn", Platforms: []string{"web"}}, {Name: "sign_in", Platforms: []string{"web"}}}
+	if _, err := m.Plan(context.Background(), dup, opts); err == nil || !strings.Contains(err.Error(), "declared twice") {
+		t.Fatalf("Plan(collapsing names) error = %v, want declared twice", err)
+	}
+}
@@ -0,0 +1,17 @@
+package client
+
+import "github.com/bodrovis/lokex/v2/internal/eol"
+
+// LineEnding is a line ending style that uploaders apply to file data
+// before sending it and downloaders apply to extracted files; see
+// upload.Uploader.WithLineEndings and download.Downloader.WithLineEndings.
+// Files that look binary (a NUL byte in the first 8000 bytes, as in UTF-16
+// text) are never changed.
+type LineEnding = eol.Style
+
+// Line ending styles.
+const (
+	LineEndingKeep = eol.Keep // leave line endings alone (the default)
+	LineEndingLF   = eol.LF   // "\n"
+	LineEndingCRLF = eol.CRLF // "\r\n"
+)
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrLocked is returned by Locker.TryLock when another owner holds the key.
+var ErrLocked = errors.New("lock: held by another owner")
+
+// Locker is a lease-based lock shared by every runner that must not work on
+// the same thing at once, e.g. CI jobs on different machines pushing to one
+// project. Back it with Redis (SET NX PX), a database row or similar.
+// Leases expire after their TTL, so a crashed holder can't block others
+// forever. Implementations must be safe for concurrent use.
+type Locker interface {
+	// TryLock takes key for ttl if it is free or its lease has expired,
+	// returning a token that identifies this holder. It returns ErrLocked,
+	// without waiting, if someone else holds key.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (token string, err error)
+	// Unlock releases key if token still holds it; releasing a lease that
+	// expired or was taken over is not an error.
+	Unlock(ctx context.Context, key, token string) error
+}
+
+// Lease is a lock held through AcquireLock.
+type Lease struct {
+	locker Locker
+	Key    string
+	Token  string
+}
+
+// AcquireLock takes key in l, retrying every retryEvery (default 1s) while
+// another owner holds it, until ctx is done.
+func AcquireLock(ctx context.Context, l Locker, key string, ttl, retryEvery time.Duration) (*Lease, error) {
+	if l == nil {
+		return nil, errors.New("lock: nil locker")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("lock: ttl must be positive")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if retryEvery <= 0 {
+		retryEvery = time.Second
+	}
+	for {
+		token, err := l.TryLock(ctx, key, ttl)
+		if err == nil {
+			return &Lease{locker: l, Key: key, Token: token}, nil
+		}
+		if !errors.Is(err, ErrLocked) {
+			return nil, fmt.Errorf("lock %s: %w", key, err)
+		}
+		t := time.NewTimer(retryEvery)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, fmt.Errorf("lock %s: %w", key, context.Cause(ctx))
+		case <-t.C:
+		}
+	}
+}
+
+// Release gives the lease back. It is safe to call on a nil *Lease.
+func (l *Lease) Release(ctx context.Context) error {
+	if l == nil || l.locker == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := l.locker.Unlock(ctx, l.Key, l.Token); err != nil {
+		return fmt.Errorf("unlock %s: %w", l.Key, err)
+	}
+	return nil
+}
+
+// MemoryLocker is a Locker for runners within one process, and for tests.
+// The zero value is ready to use.
+type MemoryLocker struct {
+	mu     sync.Mutex
+	leases map[string]memoryLease
+}
+
+type memoryLease struct {
+	token   string
+	expires time.Time
+}
+
+// NewMemoryLocker returns an empty MemoryLocker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{}
+}
+
+// TryLock takes key for ttl unless an unexpired lease holds it.
+func (m *MemoryLocker) TryLock(_ context.Context, key string, ttl time.Duration) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if l, ok := m.leases[key]; ok && now.Before(l.expires) {
+		return "", ErrLocked
+	}
+	token, err := newLockToken()
+	if err != nil {
+		return "", err
+	}
+	if m.leases == nil {
+		m.leases = make(map[string]memoryLease)
+	}
+	m.leases[key] = memoryLease{token: token, expires: now.Add(ttl)}
+	return token, nil
+}
+
+// Unlock releases key if token holds it.
+func (m *MemoryLocker) Unlock(_ context.Context, key, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.leases[key]; ok && l.token == token {
+		delete(m.leases, key)
+	}
+	return nil
+}
+
+func newLockToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("lock: token: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,136 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestMemoryLocker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var l client.MemoryLocker
+
+	tok, err := l.TryLock(ctx, "k", time.Minute)
+	if err != nil || tok == "" {
+		t.Fatalf("TryLock() = %q, %v; want a token", tok, err)
+	}
+	if _, err := l.TryLock(ctx, "k", time.Minute); !errors.Is(err, client.ErrLocked) {
+		t.Fatalf("TryLock(held) error = %v, want ErrLocked", err)
+	}
+	if _, err := l.TryLock(ctx, "other", time.Minute); err != nil {
+		t.Fatalf("TryLock(other key) error = %v", err)
+	}
+
+	// A stale token doesn't release someone else's lease.
+	if err := l.Unlock(ctx, "k", "stale"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.TryLock(ctx, "k", time.Minute); !errors.Is(err, client.ErrLocked) {
+		t.Fatalf("TryLock() after stale Unlock error = %v, want ErrLocked", err)
+	}
+
+	if err := l.Unlock(ctx, "k", tok); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.TryLock(ctx, "k", time.Minute); err != nil {
+		t.Fatalf("TryLock() after Unlock error = %v", err)
+	}
+}
+
+func TestMemoryLocker_Expiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	l := client.NewMemoryLocker()
+	if _, err := l.TryLock(ctx, "k", 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := l.TryLock(ctx, "k", time.Minute); err != nil {
+		t.Fatalf("TryLock() after expiry error = %v", err)
+	}
+}
+
+func TestAcquireLock_WaitsForRelease(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	l := client.NewMemoryLocker()
+	first, err := client.AcquireLock(ctx, l, "k", time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(chan error, 1)
+	go func() {
+		lease, err := client.AcquireLock(ctx, l, "k", time.Minute, 5*time.Millisecond)
+		if err == nil {
+			err = lease.Release(ctx)
+		}
+		got <- err
+	}()
+
+	select {
+	case err := <-got:
+		t.Fatalf("AcquireLock() returned %v while the lock was held", err)
+	case <-time.After(30 * time.Millisecond):
+	}
+	if err := first.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Fatalf("AcquireLock() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("AcquireLock() did not return after release")
+	}
+}
+
+func TestAcquireLock_ContextDone(t *testing.T) {
+	t.Parallel()
+
+	l := client.NewMemoryLocker()
+	if _, err := l.TryLock(context.Background(), "k", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.AcquireLock(ctx, l, "k", time.Minute, 5*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("AcquireLock() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+type failingLocker struct{}
+
+func (failingLocker) TryLock(context.Context, string, time.Duration) (string, error) {
+	return "", errors.New("redis down")
+}
+
+func (failingLocker) Unlock(context.Context, string, string) error { return nil }
+
+func TestAcquireLock_Errors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	if _, err := client.AcquireLock(ctx, nil, "k", time.Minute, 0); err == nil {
+		t.Error("AcquireLock(nil locker) error = nil")
+	}
+	if _, err := client.AcquireLock(ctx, client.NewMemoryLocker(), "k", 0, 0); err == nil {
+		t.Error("AcquireLock(ttl 0) error = nil")
+	}
+	if _, err := client.AcquireLock(ctx, failingLocker{}, "k", time.Minute, 0); err == nil || errors.Is(err, client.ErrLocked) {
+		t.Errorf("AcquireLock(failing locker) error = %v, want the locker's error", err)
+	}
+
+	var lease *client.Lease
+	if err := lease.Release(ctx); err != nil {
+		t.Errorf("nil Lease.Release() = %v", err)
+	}
+}
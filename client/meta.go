@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+// HeaderRequestID is the response header carrying the ID Lokalise support
+// asks for.
+const HeaderRequestID = apierr.HeaderRequestID
+
+// ResponseMeta is what lokex keeps from the headers of an API response:
+// the request ID and the rate-limit headers. APIError.Meta holds it for
+// failed calls; see WithResponseHook and WithResponseMeta for the others.
+type ResponseMeta = apierr.ResponseMeta
+
+// WithResponseHook calls fn with the ResponseMeta of every API response,
+// failed ones and retried attempts included; requests that got no
+// response are skipped. fn runs synchronously on the request path, so it
+// must be fast, and it must be safe for concurrent use. fn must be
+// non-nil.
+func WithResponseHook(fn func(ResponseMeta)) Option {
+	return func(c *Client) error {
+		if fn == nil {
+			return errors.New("response hook cannot be nil")
+		}
+		c.responseHook = fn
+		return nil
+	}
+}
+
+// WithResponseMeta stores the ResponseMeta of the call's last API response
+// in dst, e.g. to log the request ID of a successful upload. dst must not
+// be shared with calls running at the same time. A nil dst is ignored.
+func WithResponseMeta(dst *ResponseMeta) RequestOption {
+	return func(c *Client) {
+		if dst != nil {
+			c.responseMeta = dst
+		}
+	}
+}
+
+// onResult returns the transport's OnResult hook: it updates Health and
+// feeds WithResponseHook and WithResponseMeta.
+func (c *Client) onResult() func(req *http.Request, status int, header http.Header, err error) {
+	return func(req *http.Request, status int, header http.Header, err error) {
+		c.health.observe(status, header, err)
+		if status == 0 || (c.responseHook == nil && c.responseMeta == nil) {
+			return
+		}
+		m := apierr.NewResponseMeta(req, status, header)
+		if c.responseHook != nil {
+			c.responseHook(m)
+		}
+		if c.responseMeta != nil {
+			*c.responseMeta = m
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// requestIDServer answers 503 to the first request and 200 afterwards,
+// numbering request IDs.
+func requestIDServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		i := n.Add(1)
+		w.Header().Set("X-Request-Id", "req-"+strconv.Itoa(int(i)))
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(10-int(i)))
+		w.Header().Set("Content-Type", "application/json")
+		if i == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"message":"down","code":503}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWithResponseHook(t *testing.T) {
+	t.Parallel()
+
+	srv := requestIDServer(t)
+	var (
+		mu   sync.Mutex
+		seen []client.ResponseMeta
+	)
+	c, err := client.NewClient("tok", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+		client.WithResponseHook(func(m client.ResponseMeta) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen = append(seen, m)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("hook calls = %d, want 2 (one per attempt)", len(seen))
+	}
+	if seen[0].Status != http.StatusServiceUnavailable || seen[0].RequestID != "req-1" {
+		t.Fatalf("first = %+v", seen[0])
+	}
+	if seen[1].Status != http.StatusOK || seen[1].RequestID != "req-2" || seen[1].Method != http.MethodGet {
+		t.Fatalf("second = %+v", seen[1])
+	}
+	if seen[1].RateLimit.Get("X-Rate-Limit-Remaining") != "8" {
+		t.Fatalf("RateLimit = %v", seen[1].RateLimit)
+	}
+
+	if _, err := client.NewClient("tok", "p", client.WithResponseHook(nil)); err == nil {
+		t.Fatal("WithResponseHook(nil): expected error")
+	}
+}
+
+func TestWithResponseMeta(t *testing.T) {
+	t.Parallel()
+
+	srv := requestIDServer(t)
+	c, err := client.NewClient("tok", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var meta client.ResponseMeta
+	if err := c.Do(context.Background(), http.MethodGet, "projects", nil, nil, client.WithResponseMeta(&meta)); err != nil {
+		t.Fatal(err)
+	}
+	if meta.RequestID != "req-2" || meta.Status != http.StatusOK {
+		t.Fatalf("meta = %+v, want the last response", meta)
+	}
+}
+
+func TestAPIError_Meta(t *testing.T) {
+	t.Parallel()
+
+	srv := requestIDServer(t)
+	c, err := client.NewClient("tok", "p", client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects", nil, nil)
+	var ae *client.APIError
+	if !errors.As(err, &ae) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if ae.Meta.RequestID != "req-1" || ae.Meta.Path != "/projects" || ae.Meta.RateLimit.Get("X-Rate-Limit-Remaining") != "9" {
+		t.Fatalf("Meta = %+v", ae.Meta)
+	}
+}
+
+func TestWithResponseHook_Polls(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := processServer(t, "", "running", "finished")
+	var calls atomic.Int32
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithPollWait(time.Millisecond, time.Second),
+		client.WithResponseHook(func(m client.ResponseMeta) {
+			if m.Status == http.StatusOK {
+				calls.Add(1)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Process("pid").Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("hook calls = %d, want 2 (one per poll)", got)
+	}
+	if h := c.Health(); h.LastSuccess.IsZero() {
+		t.Fatalf("Health() = %+v, want the polls recorded", h)
+	}
+}
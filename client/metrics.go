@@ -0,0 +1,42 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/bodrovis/lokex/v2/internal/metrics"
+)
+
+// MetricsRecorder receives request, retry, polling and download
+// measurements; see WithMetrics. Implementations must be safe for concurrent
+// use and must not block.
+type MetricsRecorder = metrics.Recorder
+
+// WithMetrics reports measurements to r:
+//
+//   - Request: every API request (retries and process polls included),
+//     with method, route, status code (0 without a response) and duration
+//   - Retry: every retry, with the operation label, retry number and delay
+//   - Poll: every PollProcesses/WaitAll call, with the process count,
+//     rounds and duration
+//   - DownloadedBytes: the size of every bundle downloaded
+//
+// lokex has no metrics dependency; adapt r to Prometheus or any other
+// library. r must be non-nil.
+func WithMetrics(r MetricsRecorder) Option {
+	return func(c *Client) error {
+		if r == nil {
+			return errors.New("metrics recorder cannot be nil")
+		}
+		c.metrics = r
+		return nil
+	}
+}
+
+// Metrics returns the recorder set with WithMetrics, or one that discards
+// everything. Packages built on the client report through it.
+func (c *Client) Metrics() MetricsRecorder {
+	if c == nil || c.metrics == nil {
+		return metrics.Nop{}
+	}
+	return c.metrics
+}
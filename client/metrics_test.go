@@ -0,0 +1,163 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// recordingMetrics keeps every measurement as a string.
+type recordingMetrics struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (m *recordingMetrics) add(format string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, fmt.Sprintf(format, args...))
+}
+
+func (m *recordingMetrics) Request(method, route string, status int, d time.Duration) {
+	if d < 0 {
+		m.add("negative duration")
+	}
+	m.add("request %s %s %d", method, route, status)
+}
+
+func (m *recordingMetrics) Retry(label string, attempt int, delay time.Duration) {
+	m.add("retry %s %d", label, attempt)
+}
+
+func (m *recordingMetrics) Poll(processes, rounds int, d time.Duration) {
+	m.add("poll %d %d", processes, rounds)
+}
+
+func (m *recordingMetrics) DownloadedBytes(n int64) { m.add("bytes %d", n) }
+
+func (m *recordingMetrics) Events() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.events)
+}
+
+func TestWithMetrics_Nil(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("tok", "proj", client.WithMetrics(nil)); err == nil {
+		t.Fatal("NewClient() error = nil, want error for nil recorder")
+	}
+}
+
+func TestClient_Metrics_DefaultDiscards(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok", "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Metrics().DownloadedBytes(1) // must not panic
+}
+
+func TestMetrics_RequestsAndRetries(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	m := &recordingMetrics{}
+	c, err := client.NewClient("tok", "123.abc",
+		client.WithBaseURL(srv.URL+"/api2/"),
+		client.WithMetrics(m),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects/123.abc/keys/42?page=2", nil, nil); err != nil {
+		t.Fatalf("DoJSONWithRetry() error = %v", err)
+	}
+
+	want := []string{
+		"request GET /api2/projects/{id}/keys/{id} 503",
+		"retry request 1",
+		"request GET /api2/projects/{id}/keys/{id} 200",
+	}
+	if got := m.Events(); !slices.Equal(got, want) {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+}
+
+func TestMetrics_SendErrorHasNoStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close() // nothing listens any more
+
+	m := &recordingMetrics{}
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(url),
+		client.WithMetrics(m),
+		client.WithMaxRetries(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodPost, "projects", nil, nil); err == nil {
+		t.Fatal("DoJSONWithRetry() error = nil, want send error")
+	}
+	if got, want := m.Events(), []string{"request POST /projects 0"}; !slices.Equal(got, want) {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+}
+
+func TestMetrics_Poll(t *testing.T) {
+	t.Parallel()
+
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "running"
+		if polls.Add(1) > 2 {
+			status = "finished"
+		}
+		_, _ = w.Write([]byte(`{"process":{"process_id":"p1","status":"` + status + `"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	m := &recordingMetrics{}
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithMetrics(m),
+		client.WithPollWait(time.Millisecond, 5*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.WaitAll(context.Background(), []string{"p1"}); err != nil {
+		t.Fatalf("WaitAll() error = %v", err)
+	}
+
+	got := m.Events()
+	if len(got) != 4 || got[3] != "poll 1 3" {
+		t.Fatalf("events = %q, want 3 requests then \"poll 1 3\"", got)
+	}
+	if got[0] != "request GET /projects/proj/processes/{id} 200" {
+		t.Fatalf("poll request = %q", got[0])
+	}
+}
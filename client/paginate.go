@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+)
+
+// Pagination response headers sent by Lokalise list endpoints.
+const (
+	HeaderTotalCount = "X-Pagination-Total-Count"
+	HeaderPageCount  = "X-Pagination-Page-Count"
+	HeaderLimit      = "X-Pagination-Limit"
+	HeaderPage       = "X-Pagination-Page"
+	HeaderNextCursor = "X-Pagination-Next-Cursor"
+)
+
+// defaultPageLimit is the page size a Paginator asks for by default.
+const defaultPageLimit = 100
+
+// ErrNoMorePages is returned by Paginator.Next once every page was read.
+var ErrNoMorePages = errors.New("pagination: no more pages")
+
+// PageInfo holds the pagination headers of the last page read. Counts are
+// zero when the endpoint didn't send them (cursor pagination has no totals).
+type PageInfo struct {
+	TotalCount int
+	PageCount  int
+	Limit      int
+	Page       int
+	NextCursor string
+}
+
+// PaginatorOptions configures NewPaginator.
+type PaginatorOptions struct {
+	// Limit is the page size (default 100). The endpoint caps it.
+	Limit int
+	// Cursor switches to cursor pagination (pagination=cursor), which
+	// endpoints such as keys support and which stays fast on large lists.
+	Cursor bool
+}
+
+// Paginator walks a Lokalise list endpoint page by page, using page/limit
+// or cursor pagination, and decodes the items under a JSON field with the
+// client's codec and decoding options. It is not safe for concurrent use.
+type Paginator[T any] struct {
+	client *Client
+	path   string
+	query  url.Values
+	field  string
+	limit  int
+	cursor bool
+
+	page int    // last page read (page mode)
+	next string // cursor of the next page (cursor mode)
+	done bool
+	info PageInfo
+}
+
+// NewPaginator returns a Paginator over GET path (relative to BaseURL; it
+// may carry a query string, whose page, limit and cursor are overridden).
+// field names the response property holding the items, e.g. "keys" for
+// projects/{id}/keys.
+func NewPaginator[T any](c *Client, path, field string, opts PaginatorOptions) *Paginator[T] {
+	path, rawQuery, _ := strings.Cut(path, "?")
+	q, _ := url.ParseQuery(rawQuery)
+	if q == nil {
+		q = url.Values{}
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	return &Paginator[T]{
+		client: c,
+		path:   path,
+		query:  q,
+		field:  field,
+		limit:  limit,
+		cursor: opts.Cursor,
+	}
+}
+
+// Next fetches the next page. It returns ErrNoMorePages once the last page
+// was read; a page may be empty only if the whole list is.
+func (p *Paginator[T]) Next(ctx context.Context) ([]T, error) {
+	if p == nil || p.client == nil {
+		return nil, errors.New("pagination: paginator/client is nil")
+	}
+	if p.done {
+		return nil, ErrNoMorePages
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	q := maps.Clone(p.query)
+	q.Set("limit", strconv.Itoa(p.limit))
+	if p.cursor {
+		q.Set("pagination", "cursor")
+		if p.next != "" {
+			q.Set("cursor", p.next)
+		}
+	} else {
+		q.Set("page", strconv.Itoa(p.page+1))
+	}
+
+	var resp map[string]json.RawMessage
+	header, err := p.client.doJSONWithRetry(ctx, http.MethodGet, p.path+"?"+q.Encode(), nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("pagination: %s: %w", p.path, err)
+	}
+
+	var items []T
+	if raw, ok := resp[p.field]; ok {
+		if err := transport.DecodeJSON(raw, &items, p.client.decodeOptions()); err != nil {
+			var se *SchemaError
+			if errors.As(err, &se) {
+				se.Path = p.field + se.Path // "[1].key_id" -> "keys[1].key_id"
+			}
+			return nil, fmt.Errorf("pagination: %s: decode %q: %w", p.path, p.field, err)
+		}
+	} else if p.client.StrictDecoding {
+		return nil, fmt.Errorf("pagination: %s: response has no %q field", p.path, p.field)
+	}
+
+	p.info = parsePageInfo(header)
+	p.page++
+	p.next = p.info.NextCursor
+	p.done = p.lastPage(len(items))
+	return items, nil
+}
+
+// lastPage reports whether the page just read, holding n items, was the
+// last one.
+func (p *Paginator[T]) lastPage(n int) bool {
+	if n == 0 {
+		return true
+	}
+	if p.cursor {
+		return p.next == ""
+	}
+	if p.info.PageCount > 0 {
+		return p.page >= p.info.PageCount
+	}
+	return n < p.limit
+}
+
+// Info returns the pagination headers of the last page read.
+func (p *Paginator[T]) Info() PageInfo {
+	return p.info
+}
+
+// Done reports whether every page was read.
+func (p *Paginator[T]) Done() bool {
+	return p.done
+}
+
+// All returns an iterator over the remaining items, fetching pages as
+// needed. A failed page is yielded as a zero item with the error, after
+// which iteration stops.
+func (p *Paginator[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			items, err := p.Next(ctx)
+			if errors.Is(err, ErrNoMorePages) {
+				return
+			}
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, it := range items {
+				if !yield(it, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func parsePageInfo(h http.Header) PageInfo {
+	atoi := func(key string) int {
+		n, _ := strconv.Atoi(strings.TrimSpace(h.Get(key)))
+		return n
+	}
+	return PageInfo{
+		TotalCount: atoi(HeaderTotalCount),
+		PageCount:  atoi(HeaderPageCount),
+		Limit:      atoi(HeaderLimit),
+		Page:       atoi(HeaderPage),
+		NextCursor: strings.TrimSpace(h.Get(HeaderNextCursor)),
+	}
+}
@@ -0,0 +1,254 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+type pagedKey struct {
+	KeyID int `json:"key_id"`
+}
+
+// pagedServer serves total keys from /projects/p/keys, honoring page/limit or
+// cursor pagination. It sends page-count headers unless noCounts is set, and
+// records each query string.
+func pagedServer(t *testing.T, total int, noCounts bool) (*client.Client, func() []string) {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		queries []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+
+		q := r.URL.Query()
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		start := 0
+		if q.Get("pagination") == "cursor" {
+			if c := q.Get("cursor"); c != "" {
+				start, _ = strconv.Atoi(strings.TrimPrefix(c, "c"))
+			}
+		} else {
+			page, _ := strconv.Atoi(q.Get("page"))
+			start = (page - 1) * limit
+		}
+		end := min(start+limit, total)
+
+		var items []string
+		for id := start + 1; id <= end; id++ {
+			items = append(items, fmt.Sprintf(`{"key_id":%d}`, id))
+		}
+
+		h := w.Header()
+		h.Set("Content-Type", "application/json")
+		h.Set(client.HeaderLimit, strconv.Itoa(limit))
+		if q.Get("pagination") == "cursor" {
+			if end < total {
+				h.Set(client.HeaderNextCursor, fmt.Sprintf("c%d", end))
+			}
+		} else if !noCounts {
+			h.Set(client.HeaderTotalCount, strconv.Itoa(total))
+			h.Set(client.HeaderPageCount, strconv.Itoa((total+limit-1)/limit))
+			h.Set(client.HeaderPage, q.Get("page"))
+		}
+		_, _ = fmt.Fprintf(w, `{"project_id":"p","keys":[%s]}`, strings.Join(items, ","))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("tok", "p", client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), queries...)
+	}
+}
+
+func collectIDs(t *testing.T, p *client.Paginator[pagedKey]) []int {
+	t.Helper()
+	var ids []int
+	for k, err := range p.All(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, k.KeyID)
+	}
+	return ids
+}
+
+func seq(n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = i + 1
+	}
+	return out
+}
+
+func TestPaginator_PageMode(t *testing.T) {
+	t.Parallel()
+	c, queries := pagedServer(t, 5, false)
+
+	p := client.NewPaginator[pagedKey](c, "projects/p/keys?include_translations=1", "keys", client.PaginatorOptions{Limit: 2})
+	if got := collectIDs(t, p); !reflect.DeepEqual(got, seq(5)) {
+		t.Fatalf("ids = %v", got)
+	}
+	want := []string{
+		"include_translations=1&limit=2&page=1",
+		"include_translations=1&limit=2&page=2",
+		"include_translations=1&limit=2&page=3",
+	}
+	if got := queries(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("queries = %q, want %q", got, want)
+	}
+	if info := p.Info(); info.TotalCount != 5 || info.PageCount != 3 || info.Page != 3 || info.Limit != 2 {
+		t.Fatalf("info = %+v", info)
+	}
+	if !p.Done() {
+		t.Fatal("Done() = false after the last page")
+	}
+	if _, err := p.Next(context.Background()); !errors.Is(err, client.ErrNoMorePages) {
+		t.Fatalf("Next after last page: %v", err)
+	}
+}
+
+func TestPaginator_PageModeExactMultiple(t *testing.T) {
+	t.Parallel()
+	c, queries := pagedServer(t, 4, false)
+
+	p := client.NewPaginator[pagedKey](c, "projects/p/keys", "keys", client.PaginatorOptions{Limit: 2})
+	if got := collectIDs(t, p); !reflect.DeepEqual(got, seq(4)) {
+		t.Fatalf("ids = %v", got)
+	}
+	if n := len(queries()); n != 2 {
+		t.Fatalf("requests = %d, want 2 (page count header ends it)", n)
+	}
+}
+
+func TestPaginator_PageModeWithoutHeaders(t *testing.T) {
+	t.Parallel()
+	c, queries := pagedServer(t, 4, true)
+
+	p := client.NewPaginator[pagedKey](c, "projects/p/keys", "keys", client.PaginatorOptions{Limit: 2})
+	if got := collectIDs(t, p); !reflect.DeepEqual(got, seq(4)) {
+		t.Fatalf("ids = %v", got)
+	}
+	if n := len(queries()); n != 3 {
+		t.Fatalf("requests = %d, want 3 (ends on the empty page)", n)
+	}
+}
+
+func TestPaginator_CursorMode(t *testing.T) {
+	t.Parallel()
+	c, queries := pagedServer(t, 5, false)
+
+	p := client.NewPaginator[pagedKey](c, "projects/p/keys", "keys", client.PaginatorOptions{Limit: 2, Cursor: true})
+
+	first, err := p.Next(context.Background())
+	if err != nil || len(first) != 2 {
+		t.Fatalf("first page = %v, %v", first, err)
+	}
+	if p.Info().NextCursor != "c2" {
+		t.Fatalf("next cursor = %q", p.Info().NextCursor)
+	}
+
+	rest := collectIDs(t, p)
+	if !reflect.DeepEqual(rest, []int{3, 4, 5}) {
+		t.Fatalf("rest = %v", rest)
+	}
+	want := []string{
+		"limit=2&pagination=cursor",
+		"cursor=c2&limit=2&pagination=cursor",
+		"cursor=c4&limit=2&pagination=cursor",
+	}
+	if got := queries(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("queries = %q, want %q", got, want)
+	}
+}
+
+func TestPaginator_StopEarly(t *testing.T) {
+	t.Parallel()
+	c, queries := pagedServer(t, 10, false)
+
+	p := client.NewPaginator[pagedKey](c, "projects/p/keys", "keys", client.PaginatorOptions{Limit: 3})
+	n := 0
+	for _, err := range p.All(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 4 {
+			break
+		}
+	}
+	if len(queries()) != 2 {
+		t.Fatalf("requests = %d, want 2", len(queries()))
+	}
+}
+
+func TestPaginator_Error(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"message":"Not Found","code":404}}`))
+	}))
+	t.Cleanup(srv.Close)
+	c, err := client.NewClient("tok", "p", client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := client.NewPaginator[pagedKey](c, "projects/p/keys", "keys", client.PaginatorOptions{})
+	var errs []error
+	for _, err := range p.All(context.Background()) {
+		errs = append(errs, err)
+	}
+	var ae *client.APIError
+	if len(errs) != 1 || !errors.As(errs[0], &ae) || ae.Status != http.StatusNotFound {
+		t.Fatalf("errs = %v, want one *APIError 404", errs)
+	}
+}
+
+func TestPaginator_DecodeOptions(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"keys":[{"key_id":9007199254740993,"extra":true}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("tok", "p", client.WithBaseURL(srv.URL), client.WithUseNumber(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := client.NewPaginator[map[string]any](c, "projects/p/keys", "keys", client.PaginatorOptions{}).Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := items[0]["key_id"].(json.Number); !ok || id.String() != "9007199254740993" {
+		t.Fatalf("key_id = %#v, want the exact json.Number", items[0]["key_id"])
+	}
+
+	strict, err := client.NewClient("tok", "p", client.WithBaseURL(srv.URL), client.WithDisallowUnknownFields(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewPaginator[pagedKey](strict, "projects/p/keys", "keys", client.PaginatorOptions{}).Next(context.Background()); err == nil || !strings.Contains(err.Error(), "extra") {
+		t.Fatalf("Next() error = %v, want the unknown field rejected", err)
+	}
+}
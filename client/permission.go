@@ -0,0 +1,18 @@
+package client
+
+import "github.com/bodrovis/lokex/v2/internal/apierr"
+
+// PermissionError is returned when the API answers 403. Besides the API
+// error it names the token access (read or read/write) and the project
+// permission the endpoint most likely needs, so the fix is obvious.
+type PermissionError = apierr.PermissionError
+
+// ErrPermissionDenied is matched (via errors.Is) by a *PermissionError.
+var ErrPermissionDenied = apierr.ErrPermissionDenied
+
+// PermissionHint returns the token access and project permission an API
+// call most likely requires; path is relative to the API base URL. Use it
+// to check a token's scope up front or to document a workflow's needs.
+func PermissionHint(method, path string) (access, permission string) {
+	return apierr.PermissionHint(method, path)
+}
@@ -0,0 +1,70 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestPermissionError_On403(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"message":"Forbidden","code":403}}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj", client.WithBaseURL(srv.URL), client.WithDiagnostics(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.DoJSONWithRetry(context.Background(), http.MethodPost, "projects/proj/screenshots", bytes.NewReader([]byte(`{}`)), nil)
+
+	var pe *client.PermissionError
+	if !errors.As(err, &pe) || !errors.Is(err, client.ErrPermissionDenied) {
+		t.Fatalf("err = %v, want *client.PermissionError", err)
+	}
+	if pe.Access != "read/write" || pe.Permission != "screenshots" || pe.Err.Status != http.StatusForbidden {
+		t.Fatalf("PermissionError = %+v", pe)
+	}
+	if d := c.Diagnostics(); len(d) != 1 || !strings.Contains(d[0].Err, `"screenshots" project permission`) {
+		t.Fatalf("diagnostics = %+v", d)
+	}
+
+	if access, perm := client.PermissionHint(http.MethodGet, "projects/proj/keys"); access != "read" || perm != "keys" {
+		t.Fatalf("PermissionHint() = %q, %q", access, perm)
+	}
+}
+
+func TestPermissionError_On403WhilePolling(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"message":"Forbidden","code":403}}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj", client.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Process("pid").Wait(context.Background())
+
+	var pe *client.PermissionError
+	if !errors.As(err, &pe) {
+		t.Fatalf("err = %v, want *client.PermissionError", err)
+	}
+	if pe.Method != http.MethodGet || pe.Path != "projects/proj/processes/pid" || pe.Access != "read" {
+		t.Fatalf("PermissionError = %+v", pe)
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PingStatus is the outcome of Ping.
+type PingStatus int
+
+const (
+	// PingOK means the token works and can read the project.
+	PingOK PingStatus = iota
+	// PingBadToken means Lokalise rejected the token (401).
+	PingBadToken
+	// PingForbidden means the token is valid but may not access the
+	// project (403).
+	PingForbidden
+	// PingProjectNotFound means the project doesn't exist, or the token's
+	// user isn't a contributor (404).
+	PingProjectNotFound
+	// PingUnreachable means no response arrived: DNS, connection or TLS
+	// failures, timeouts, an open circuit breaker.
+	PingUnreachable
+	// PingFailed means Lokalise responded with another error, such as a
+	// 5xx, a 429 or an undecodable body; the configuration may well be
+	// fine.
+	PingFailed
+)
+
+func (s PingStatus) String() string {
+	switch s {
+	case PingOK:
+		return "ok"
+	case PingBadToken:
+		return "bad token"
+	case PingForbidden:
+		return "forbidden"
+	case PingProjectNotFound:
+		return "project not found"
+	case PingUnreachable:
+		return "unreachable"
+	case PingFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("PingStatus(%d)", int(s))
+}
+
+// PingResult is what Ping found out.
+type PingResult struct {
+	Status      PingStatus
+	ProjectID   string        // as reported by Lokalise; "" unless PingOK
+	ProjectName string        // "" unless PingOK
+	Latency     time.Duration // of the request, failed or not
+	Err         error         // nil for PingOK
+}
+
+// Ping checks the client's configuration by fetching its project once,
+// without retries, so tools can fail fast before starting a long sync.
+// The result tells a bad token, a missing project and a network failure
+// apart; the error is non-nil unless Status is PingOK, and wraps the
+// underlying error (an *APIError when Lokalise responded).
+func (c *Client) Ping(ctx context.Context) (PingResult, error) {
+	if c == nil {
+		return PingResult{}, errors.New("ping: client is nil")
+	}
+
+	var resp struct {
+		ProjectID string `json:"project_id"`
+		Name      string `json:"name"`
+	}
+	var meta ResponseMeta
+	start := time.Now()
+	err := c.Do(ctx, http.MethodGet, "projects/"+projectIDPlaceholder, nil, &resp, WithNoRetry(), WithResponseMeta(&meta))
+	res := PingResult{Status: pingStatus(meta.Status, err), Latency: time.Since(start)}
+	if err != nil {
+		res.Err = fmt.Errorf("ping: %s: %w", res.Status, err)
+		return res, res.Err
+	}
+	res.ProjectID, res.ProjectName = resp.ProjectID, resp.Name
+	return res, nil
+}
+
+// pingStatus classifies err; status is that of the response, 0 if none
+// arrived.
+func pingStatus(status int, err error) PingStatus {
+	switch {
+	case err == nil:
+		return PingOK
+	case status == 0:
+		return PingUnreachable
+	case status == http.StatusUnauthorized:
+		return PingBadToken
+	case status == http.StatusForbidden:
+		return PingForbidden
+	case status == http.StatusNotFound:
+		return PingProjectNotFound
+	}
+	return PingFailed
+}
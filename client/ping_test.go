@@ -0,0 +1,108 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestClient_Ping(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method != http.MethodGet {
+			t.Errorf("method = %s, want GET", r.Method)
+		}
+		reply := func(status int, body string) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}
+		switch {
+		case r.Header.Get("X-Api-Token") == "bad":
+			reply(http.StatusUnauthorized, `{"error":{"message":"Invalid X-Api-Token header","code":401}}`)
+		case r.URL.Path == "/projects/p.1":
+			reply(http.StatusOK, `{"project_id":"p.1","name":"Web app"}`)
+		case r.URL.Path == "/projects/locked":
+			reply(http.StatusForbidden, `{"error":{"message":"Forbidden","code":403}}`)
+		case r.URL.Path == "/projects/flaky":
+			reply(http.StatusBadGateway, `{"error":{"message":"Bad gateway","code":502}}`)
+		case r.URL.Path == "/projects/garbled":
+			reply(http.StatusOK, `{"project_id":`)
+		default:
+			reply(http.StatusNotFound, `{"error":{"message":"Not Found","code":404}}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		token, project string
+		want           client.PingStatus
+	}{
+		{"tok", "p.1", client.PingOK},
+		{"bad", "p.1", client.PingBadToken},
+		{"tok", "locked", client.PingForbidden},
+		{"tok", "missing", client.PingProjectNotFound},
+		{"tok", "flaky", client.PingFailed},
+		{"tok", "garbled", client.PingFailed},
+	}
+	for _, tt := range tests {
+		c, err := client.NewClient(tt.token, tt.project, client.WithBaseURL(srv.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		calls.Store(0)
+		res, err := c.Ping(context.Background())
+		if res.Status != tt.want {
+			t.Errorf("%s/%s: Status = %v, want %v (err %v)", tt.token, tt.project, res.Status, tt.want, err)
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("%s/%s: requests = %d, want 1 (no retries)", tt.token, tt.project, n)
+		}
+		if res.Latency <= 0 {
+			t.Errorf("%s/%s: Latency = %v", tt.token, tt.project, res.Latency)
+		}
+		if tt.want == client.PingOK {
+			if err != nil || res.Err != nil {
+				t.Fatalf("Ping() error = %v", err)
+			}
+			if res.ProjectID != "p.1" || res.ProjectName != "Web app" {
+				t.Fatalf("Ping() = %+v", res)
+			}
+			continue
+		}
+		if err == nil || !errors.Is(err, res.Err) {
+			t.Errorf("%s/%s: error = %v, res.Err = %v", tt.token, tt.project, err, res.Err)
+		}
+		var ae *client.APIError
+		if tt.project != "garbled" && !errors.As(err, &ae) {
+			t.Errorf("%s/%s: error %v does not wrap *APIError", tt.token, tt.project, err)
+		}
+	}
+}
+
+func TestClient_Ping_Unreachable(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	c, err := client.NewClient("tok", "p", client.WithBaseURL(url))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Ping(context.Background())
+	if res.Status != client.PingUnreachable || err == nil {
+		t.Fatalf("Ping() = %v, %v; want unreachable", res.Status, err)
+	}
+	if got := res.Status.String(); got != "unreachable" {
+		t.Fatalf("String() = %q", got)
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+)
+
+var (
+	// ErrProcessFailed is returned by Process.Wait when the process failed.
+	ErrProcessFailed = errors.New("process failed")
+
+	// ErrProcessNotFinished is returned by Process.Wait when the process was
+	// still running after the PollMaxWait budget.
+	ErrProcessNotFinished = errors.New("process did not finish")
+)
+
+// Process is a handle to an async Lokalise process (upload, async export, ...).
+// It is returned by kickoff helpers such as Uploader.Enqueue and
+// Downloader.StartAsync, or built from a stored ID with Client.Process.
+type Process struct {
+	ID     string
+	client *Client
+}
+
+// Process returns a handle for an existing process ID, for example one saved
+// by an earlier run.
+func (c *Client) Process(id string) *Process {
+	return &Process{ID: strings.TrimSpace(id), client: c}
+}
+
+func (p *Process) check() error {
+	if p == nil || p.client == nil {
+		return errors.New("process: handle/client is nil")
+	}
+	if p.ID == "" {
+		return errors.New("process: empty process id")
+	}
+	return nil
+}
+
+// Status fetches the current state of the process once (with the client's
+// retry policy) without waiting for it to finish.
+func (p *Process) Status(ctx context.Context) (QueuedProcess, error) {
+	if err := p.check(); err != nil {
+		return QueuedProcess{}, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	qp, err := background.FetchProcess(ctx, p.client.DoJSONWithRetry, p.client.ProjectID, p.ID)
+	if err != nil {
+		return QueuedProcess{}, fmt.Errorf("process %s: status: %w", p.ID, err)
+	}
+	return qp, nil
+}
+
+// Wait polls the process until it reaches a terminal status or the client's
+// PollMaxWait budget is spent. The last known state is always returned; the
+// error wraps ErrProcessFailed or ErrProcessNotFinished unless the process
+// finished.
+func (p *Process) Wait(ctx context.Context) (QueuedProcess, error) {
+	return p.wait(ctx, nil)
+}
+
+func (p *Process) wait(ctx context.Context, progress func(QueuedProcess)) (QueuedProcess, error) {
+	if err := p.check(); err != nil {
+		return QueuedProcess{}, err
+	}
+
+	procs, err := p.client.WaitAllWithProgress(ctx, []string{p.ID}, progress)
+	if err != nil {
+		return QueuedProcess{}, fmt.Errorf("process %s: %w", p.ID, err)
+	}
+	if len(procs) == 0 {
+		return QueuedProcess{}, fmt.Errorf("process %s: no result returned", p.ID)
+	}
+
+	qp := procs[0]
+	switch qp.Status {
+	case ProcessFinished:
+		return qp, nil
+	case ProcessFailed:
+		if qp.Err != nil {
+			return qp, fmt.Errorf("process %s: %w: %w", p.ID, ErrProcessFailed, qp.Err)
+		}
+		if qp.Message != "" {
+			return qp, fmt.Errorf("process %s: %w: %s", p.ID, ErrProcessFailed, qp.Message)
+		}
+		return qp, fmt.Errorf("process %s: %w", p.ID, ErrProcessFailed)
+	default:
+		if qp.Err != nil {
+			return qp, fmt.Errorf("process %s: %w (status=%q): %w", p.ID, ErrProcessNotFinished, qp.Status, qp.Err)
+		}
+		return qp, fmt.Errorf("process %s: %w (status=%q)", p.ID, ErrProcessNotFinished, qp.Status)
+	}
+}
+
+// Cancel is reserved for cancelling a running process. The Lokalise API has
+// no cancel endpoint for processes, so it always returns an error wrapping
+// errors.ErrUnsupported.
+func (p *Process) Cancel(_ context.Context) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	return fmt.Errorf("process %s: cancel: %w", p.ID, errors.ErrUnsupported)
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Lokalise webhook events that end a process wait early: an upload or an
+// export has completed somewhere in the project.
+const (
+	EventProjectImported = "project.imported"
+	EventProjectExported = "project.exported"
+)
+
+// processEventsBodyLimit caps the webhook payloads ProcessEventsHandler reads.
+const processEventsBodyLimit = 1 << 20
+
+// NotifyProcessEvent tells the client that a Lokalise webhook event
+// arrived, for services that already receive webhooks; ProcessEventsHandler
+// does it for you otherwise. On project.imported or project.exported it
+// wakes every wait polling processes of projectID (any branch; every
+// project if empty), through this client or its ForProject copies, so the
+// next status fetch happens now instead of after the current backoff. It
+// reports whether a wait was woken; other events are ignored.
+//
+// Lokalise doesn't say which process an event belongs to, so a wake-up
+// only triggers a status fetch: polling still decides when a process is
+// done, and works unchanged when events are missed.
+func (c *Client) NotifyProcessEvent(event, projectID string) bool {
+	if c == nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(event)) {
+	case EventProjectImported, EventProjectExported:
+	default:
+		return false
+	}
+	projectID, _, _ = strings.Cut(strings.TrimSpace(projectID), branchSep)
+	return c.waker.Wake(projectID)
+}
+
+// ProcessEventsHandler returns an http.Handler to register as the URL of a
+// Lokalise webhook subscribed to project.imported and project.exported. It
+// passes the events to NotifyProcessEvent and answers 200, including to
+// Lokalise's ping when the webhook is created.
+//
+// Requests whose X-Secret header doesn't match secret get 401. An empty
+// secret skips the check; a forged event only costs a status fetch.
+func (c *Client) ProcessEventsHandler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Secret")), []byte(secret)) != 1 {
+			http.Error(w, "invalid secret", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, processEventsBodyLimit))
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, "read body", status)
+			return
+		}
+
+		var payload struct {
+			Event   string `json:"event"`
+			Project struct {
+				ID string `json:"id"`
+			} `json:"project"`
+		}
+		if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "{") {
+			if err := json.Unmarshal(body, &payload); err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+		} // else a ping (["ping"]) or an empty body
+
+		event := payload.Event
+		if event == "" {
+			event = r.Header.Get("X-Event")
+		}
+		c.NotifyProcessEvent(event, payload.Project.ID)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// wakeChannel returns PollConfig's Wake: the channel NotifyProcessEvent
+// closes for this client's project.
+func (c *Client) wakeChannel() func() <-chan struct{} {
+	if c.waker == nil {
+		return nil
+	}
+	projectID := c.BaseProjectID()
+	return func() <-chan struct{} { return c.waker.C(projectID) }
+}
@@ -0,0 +1,125 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func postEvent(t *testing.T, h http.Handler, secret, body string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/lokalise", strings.NewReader(body))
+	if secret != "" {
+		req.Header.Set("X-Secret", secret)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestProcessEventsHandler_Requests(t *testing.T) {
+	t.Parallel()
+
+	c, _ := client.NewClient("tok", "proj")
+	h := c.ProcessEventsHandler("s3cret")
+
+	tests := []struct {
+		name   string
+		secret string
+		body   string
+		want   int
+	}{
+		{"event", "s3cret", `{"event":"project.imported","project":{"id":"proj"}}`, http.StatusOK},
+		{"ping", "s3cret", `["ping"]`, http.StatusOK},
+		{"other event", "s3cret", `{"event":"project.key.added","project":{"id":"proj"}}`, http.StatusOK},
+		{"wrong secret", "nope", `{"event":"project.imported"}`, http.StatusUnauthorized},
+		{"no secret", "", `{"event":"project.imported"}`, http.StatusUnauthorized},
+		{"bad payload", "s3cret", `{"event":`, http.StatusBadRequest},
+		{"too large", "s3cret", `{"event":"` + strings.Repeat("x", 2<<20) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		if got := postEvent(t, h, tt.secret, tt.body); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lokalise", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: status = %d, want 405", rec.Code)
+	}
+
+	if got := postEvent(t, c.ProcessEventsHandler(""), "", `{"event":"project.exported"}`); got != http.StatusOK {
+		t.Errorf("no secret configured: status = %d, want 200", got)
+	}
+}
+
+func TestNotifyProcessEvent_Ignored(t *testing.T) {
+	t.Parallel()
+
+	c, _ := client.NewClient("tok", "proj")
+	if c.NotifyProcessEvent(client.EventProjectImported, "proj") {
+		t.Fatal("NotifyProcessEvent() with no waits = true, want false")
+	}
+	if c.NotifyProcessEvent("project.key.added", "") {
+		t.Fatal("NotifyProcessEvent(project.key.added) = true, want false")
+	}
+
+	var nilClient *client.Client
+	if nilClient.NotifyProcessEvent(client.EventProjectImported, "") {
+		t.Fatal("nil client NotifyProcessEvent() = true, want false")
+	}
+}
+
+// TestProcessEvents_WakeWait waits with an hour between polls: only the
+// webhook event can make the second status fetch happen in time.
+func TestProcessEvents_WakeWait(t *testing.T) {
+	t.Parallel()
+
+	srv, hits := processServer(t, "", "queued", "finished")
+	c, err := client.NewClient("tok", "proj:main",
+		client.WithBaseURL(srv.URL),
+		client.WithPollWait(time.Hour, 2*time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// processServer expects /projects/proj/; wait through a copy on that
+	// project while events name the branch, as Lokalise may.
+	waiter := c.ForProject("proj")
+	h := c.ProcessEventsHandler("s3cret")
+
+	type result struct {
+		qp  client.QueuedProcess
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		qp, err := waiter.Process("pid").Wait(context.Background())
+		done <- result{qp, err}
+	}()
+
+	tick := time.NewTicker(5 * time.Millisecond)
+	defer tick.Stop()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case r := <-done:
+			if r.err != nil || r.qp.Status != client.ProcessFinished {
+				t.Fatalf("Wait() = %+v, %v; want finished", r.qp, r.err)
+			}
+			return
+		case <-tick.C:
+			if hits.Load() > 0 {
+				postEvent(t, h, "s3cret", `{"event":"project.imported","project":{"id":"proj:main"}}`)
+			}
+		case <-timeout:
+			t.Fatal("Wait() still sleeping after project.imported")
+		}
+	}
+}
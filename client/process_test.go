@@ -0,0 +1,140 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// processServer reports the given statuses for every process, one per GET;
+// the last status repeats.
+func processServer(t *testing.T, message string, statuses ...string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.Contains(r.URL.Path, "/projects/proj/processes/") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		n := int(hits.Add(1)) - 1
+		st := statuses[min(n, len(statuses)-1)]
+		id := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+		_, _ = w.Write([]byte(`{"process":{"process_id":"` + id + `","status":"` + st + `","message":"` + message + `"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func newProcessClient(t *testing.T, srv *httptest.Server) *client.Client {
+	t.Helper()
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithPollWait(time.Millisecond, 200*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+func TestProcess_Status(t *testing.T) {
+	t.Parallel()
+
+	srv, hits := processServer(t, "", "running", "finished")
+	p := newProcessClient(t, srv).Process(" pid-1 ")
+
+	if p.ID != "pid-1" {
+		t.Fatalf("ID = %q, want trimmed pid-1", p.ID)
+	}
+	got, err := p.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if got.ProcessID != "pid-1" || got.Status != "running" {
+		t.Fatalf("Status() = %+v, want pid-1 running", got)
+	}
+	if hits.Load() != 1 {
+		t.Fatalf("hits = %d, want 1 (no waiting)", hits.Load())
+	}
+}
+
+func TestProcess_Wait(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		statuses []string
+		message  string
+		wantErr  error
+		wantMsg  string
+	}{
+		{name: "finished", statuses: []string{"queued", "running", "finished"}},
+		{name: "failed", statuses: []string{"failed"}, message: "bad file", wantErr: client.ErrProcessFailed, wantMsg: "bad file"},
+		{name: "still running", statuses: []string{"running"}, wantErr: client.ErrProcessNotFinished, wantMsg: `status="running"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv, _ := processServer(t, tt.message, tt.statuses...)
+			got, err := newProcessClient(t, srv).Process("pid").Wait(context.Background())
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Wait() error = %v", err)
+				}
+				if got.Status != client.ProcessFinished {
+					t.Fatalf("Status = %q, want finished", got.Status)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Fatalf("Wait() error = %v, want %v containing %q", err, tt.wantErr, tt.wantMsg)
+			}
+			if got.ProcessID != "pid" {
+				t.Fatalf("Wait() state = %+v, want last known state", got)
+			}
+		})
+	}
+}
+
+func TestProcess_Cancel_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	c, _ := client.NewClient("tok", "proj")
+	err := c.Process("pid").Cancel(context.Background())
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Cancel() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestProcess_InvalidHandle(t *testing.T) {
+	t.Parallel()
+
+	c, _ := client.NewClient("tok", "proj")
+	var nilHandle *client.Process
+
+	for name, p := range map[string]*client.Process{
+		"nil":       nilHandle,
+		"no client": {ID: "pid"},
+		"empty id":  c.Process("  "),
+	} {
+		if _, err := p.Status(context.Background()); err == nil {
+			t.Errorf("%s: Status() error = nil, want error", name)
+		}
+		if _, err := p.Wait(context.Background()); err == nil {
+			t.Errorf("%s: Wait() error = nil, want error", name)
+		}
+		if err := p.Cancel(context.Background()); err == nil {
+			t.Errorf("%s: Cancel() error = nil, want error", name)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// Processes reads and waits on the project's async processes by ID, so a
+// process started elsewhere (say, by an earlier CLI run that only printed
+// its ID) can be resumed without going through Upload or Download.
+// Get it from Client.Processes.
+type Processes struct {
+	client *Client
+}
+
+// Processes returns the process service of c.
+func (c *Client) Processes() *Processes {
+	return &Processes{client: c}
+}
+
+// ProcessListOptions controls Processes.List.
+type ProcessListOptions struct {
+	// Limit is the page size (default 100). The API caps it.
+	Limit int
+	// Max stops listing once this many processes were read; 0 reads every
+	// page.
+	Max int
+}
+
+// ProcessWaitOptions controls Processes.Wait.
+type ProcessWaitOptions struct {
+	// MaxWait overrides the client's PollMaxWait for this wait.
+	MaxWait time.Duration
+	// Progress, if set, is called whenever the process changes status, as
+	// in WaitAllWithProgress.
+	Progress func(QueuedProcess)
+}
+
+func (s *Processes) check() error {
+	if s == nil || s.client == nil {
+		return errors.New("processes: service/client is nil")
+	}
+	return nil
+}
+
+// Get fetches the current state of process id once, without waiting.
+func (s *Processes) Get(ctx context.Context, id string) (QueuedProcess, error) {
+	if err := s.check(); err != nil {
+		return QueuedProcess{}, err
+	}
+	return s.client.Process(id).Status(ctx)
+}
+
+// List returns the project's processes as Lokalise reports them, newest
+// first, reading page by page. Lokalise only keeps recent processes.
+func (s *Processes) List(ctx context.Context, opts ProcessListOptions) ([]QueuedProcess, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	if opts.Max < 0 {
+		return nil, fmt.Errorf("processes: list: negative max %d", opts.Max)
+	}
+
+	p := NewPaginator[json.RawMessage](s.client, utils.ProjectPath(s.client.ProjectID, "processes"), "processes",
+		PaginatorOptions{Limit: opts.Limit})
+
+	var out []QueuedProcess
+	for raw, err := range p.All(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("processes: list: %w", err)
+		}
+		qp, err := background.DecodeProcess(raw)
+		if err != nil {
+			return nil, fmt.Errorf("processes: list: item %d: %w", len(out), err)
+		}
+		out = append(out, qp)
+		if opts.Max > 0 && len(out) >= opts.Max {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Wait polls process id until it finishes or fails, like Process.Wait, with
+// the overrides in opts. The last known state is always returned; the error
+// wraps ErrProcessFailed or ErrProcessNotFinished unless the process
+// finished.
+func (s *Processes) Wait(ctx context.Context, id string, opts ProcessWaitOptions) (QueuedProcess, error) {
+	if err := s.check(); err != nil {
+		return QueuedProcess{}, err
+	}
+	if opts.MaxWait < 0 {
+		return QueuedProcess{}, fmt.Errorf("processes: wait: negative max wait %s", opts.MaxWait)
+	}
+
+	c := s.client
+	if opts.MaxWait > 0 {
+		cp := *c // a cheap copy, as in ForProject
+		cp.PollMaxWait = opts.MaxWait
+		c = &cp
+	}
+	return c.Process(id).wait(ctx, opts.Progress)
+}
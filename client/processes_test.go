@@ -0,0 +1,182 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// processListServer serves total processes, newest first, in pages of the
+// requested limit, and records the pages asked for.
+func processListServer(t *testing.T, total int) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/projects/proj/processes" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		pages = append(pages, r.URL.Query().Get("page"))
+
+		var items []string
+		for i := (page - 1) * limit; i < min(page*limit, total); i++ {
+			items = append(items, fmt.Sprintf(
+				`{"process_id":"p%d","type":"File-Import","status":"Finished","message":"","created_at_timestamp":%d,"details":{"files":[]}}`,
+				i, 1760000000-i))
+		}
+		_, _ = w.Write([]byte(`{"project_id":"proj","processes":[` + strings.Join(items, ",") + `]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &pages
+}
+
+func TestProcesses_List(t *testing.T) {
+	t.Parallel()
+
+	srv, pages := processListServer(t, 5)
+	got, err := newProcessClient(t, srv).Processes().List(context.Background(), client.ProcessListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 5 {
+		t.Fatalf("List() returned %d processes, want 5", len(got))
+	}
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(*pages, want) {
+		t.Fatalf("pages = %v, want %v", *pages, want)
+	}
+
+	first := got[0]
+	if first.ProcessID != "p0" || first.Type != "file-import" || first.Status != client.ProcessFinished {
+		t.Fatalf("first = %+v, want p0 file-import finished", first)
+	}
+	if want := time.Unix(1760000000, 0).UTC(); !first.CreatedAt.Equal(want) {
+		t.Fatalf("CreatedAt = %v, want %v", first.CreatedAt, want)
+	}
+	if got[4].ProcessID != "p4" {
+		t.Fatalf("last = %+v, want p4", got[4])
+	}
+}
+
+func TestProcesses_List_Max(t *testing.T) {
+	t.Parallel()
+
+	srv, pages := processListServer(t, 5)
+	got, err := newProcessClient(t, srv).Processes().List(context.Background(), client.ProcessListOptions{Limit: 2, Max: 3})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 3 || got[2].ProcessID != "p2" {
+		t.Fatalf("List() = %+v, want p0..p2", got)
+	}
+	if len(*pages) != 2 {
+		t.Fatalf("pages = %v, want to stop after the second", *pages)
+	}
+
+	if _, err := newProcessClient(t, srv).Processes().List(context.Background(), client.ProcessListOptions{Max: -1}); err == nil {
+		t.Fatal("List(Max: -1) error = nil, want error")
+	}
+}
+
+func TestProcesses_List_InvalidItem(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"processes":[{"process_id":"p0","status":"queued"},{"process_id":"p1"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	_, err := newProcessClient(t, srv).Processes().List(context.Background(), client.ProcessListOptions{})
+	if err == nil || !strings.Contains(err.Error(), "item 1") {
+		t.Fatalf("List() error = %v, want an error about item 1", err)
+	}
+}
+
+func TestProcesses_Get(t *testing.T) {
+	t.Parallel()
+
+	srv, hits := processServer(t, "", "running", "finished")
+	got, err := newProcessClient(t, srv).Processes().Get(context.Background(), " pid ")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.ProcessID != "pid" || got.Status != "running" || hits.Load() != 1 {
+		t.Fatalf("Get() = %+v after %d requests, want pid running after 1", got, hits.Load())
+	}
+}
+
+func TestProcesses_Wait(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := processServer(t, "", "queued", "running", "finished")
+	var seen []string
+	got, err := newProcessClient(t, srv).Processes().Wait(context.Background(), "pid", client.ProcessWaitOptions{
+		Progress: func(qp client.QueuedProcess) { seen = append(seen, qp.Status) },
+	})
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if got.Status != client.ProcessFinished {
+		t.Fatalf("Status = %q, want finished", got.Status)
+	}
+	if want := []string{"queued", "running", "finished"}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("progress = %v, want %v", seen, want)
+	}
+}
+
+func TestProcesses_Wait_MaxWait(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := processServer(t, "", "running")
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithPollWait(time.Millisecond, time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	start := time.Now()
+	got, err := c.Processes().Wait(context.Background(), "pid", client.ProcessWaitOptions{MaxWait: 50 * time.Millisecond})
+	if !errors.Is(err, client.ErrProcessNotFinished) {
+		t.Fatalf("Wait() error = %v, want ErrProcessNotFinished", err)
+	}
+	if got.Status != "running" {
+		t.Fatalf("Status = %q, want the last known running", got.Status)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Wait() took %v, want MaxWait to cut it short", elapsed)
+	}
+	if c.PollMaxWait != time.Hour {
+		t.Fatalf("PollMaxWait = %v, want the client left unchanged", c.PollMaxWait)
+	}
+
+	if _, err := c.Processes().Wait(context.Background(), "pid", client.ProcessWaitOptions{MaxWait: -1}); err == nil {
+		t.Fatal("Wait(MaxWait: -1) error = nil, want error")
+	}
+}
+
+func TestProcesses_NilService(t *testing.T) {
+	t.Parallel()
+
+	var s *client.Processes
+	if _, err := s.Get(context.Background(), "pid"); err == nil {
+		t.Error("Get() error = nil, want error")
+	}
+	if _, err := s.List(context.Background(), client.ProcessListOptions{}); err == nil {
+		t.Error("List() error = nil, want error")
+	}
+	if _, err := s.Wait(context.Background(), "pid", client.ProcessWaitOptions{}); err == nil {
+		t.Error("Wait() error = nil, want error")
+	}
+}
@@ -0,0 +1,24 @@
+package client
+
+import "strings"
+
+// ForProject returns a copy of c scoped to another project. The copy is
+// cheap: it shares the HTTP clients, rate limiter, diagnostics, audit sink,
+// metrics and every other setting with c, so one configured client can
+// drive any number of projects. A branch on c is not carried over; pass
+// "<project ID>:<branch>" to target one.
+//
+// projectID is trimmed. An ID with no project part (blank, or just
+// ":<branch>") would build "projects//" paths, so the copy keeps c's
+// project instead. Like c, the copy must not be mutated once in use.
+func (c *Client) ForProject(projectID string) *Client {
+	if c == nil {
+		return nil
+	}
+	cp := *c
+	projectID = strings.TrimSpace(projectID)
+	if base, _, _ := strings.Cut(projectID, branchSep); strings.TrimSpace(base) != "" {
+		cp.ProjectID = projectID
+	}
+	return &cp
+}
@@ -0,0 +1,100 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestForProject(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.EscapedPath())
+		mu.Unlock()
+		if r.URL.Path == "/projects/p2/fail" {
+			http.Error(w, `{"error":{"code":400,"message":"bad"}}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	base, err := client.NewClient("tok", "p1:feature",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(0),
+		client.WithDiagnostics(4),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := base.ForProject(" p2 ")
+	if other == base {
+		t.Fatal("ForProject returned the receiver")
+	}
+	if other.ProjectID != "p2" || other.Branch() != "" {
+		t.Fatalf("ProjectID = %q, branch = %q", other.ProjectID, other.Branch())
+	}
+	if base.ProjectID != "p1:feature" {
+		t.Fatalf("receiver changed: ProjectID = %q", base.ProjectID)
+	}
+	if other.HTTPClient != base.HTTPClient || other.BaseURL != base.BaseURL || other.Token != base.Token {
+		t.Fatal("copy does not share the receiver's settings")
+	}
+
+	ctx := context.Background()
+	if err := base.Do(ctx, http.MethodGet, "projects/{project_id}/keys", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Do(ctx, http.MethodGet, "projects/{project_id}/keys", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	_ = other.Do(ctx, http.MethodGet, "projects/{project_id}/fail", nil, nil)
+
+	mu.Lock()
+	got := slices.Clone(paths)
+	mu.Unlock()
+	want := []string{"/projects/p1:feature/keys", "/projects/p2/keys", "/projects/p2/fail"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("paths = %q, want %q", got, want)
+	}
+
+	// Diagnostics are shared, so the copy's failure shows up on the receiver.
+	if n := len(base.Diagnostics()); n != 1 {
+		t.Fatalf("receiver diagnostics = %d, want 1", n)
+	}
+}
+
+func TestForProject_Nil(t *testing.T) {
+	t.Parallel()
+	var c *client.Client
+	if c.ForProject("p") != nil {
+		t.Fatal("nil receiver should return nil")
+	}
+}
+
+func TestForProject_EmptyIDKeepsProject(t *testing.T) {
+	t.Parallel()
+
+	base, err := client.NewClient("tok", "p1:feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"", "   ", ":main", " : main"} {
+		if got := base.ForProject(id).ProjectID; got != "p1:feature" {
+			t.Errorf("ForProject(%q).ProjectID = %q, want the receiver's p1:feature", id, got)
+		}
+	}
+}
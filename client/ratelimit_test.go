@@ -0,0 +1,109 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestWithRateLimit_Invalid(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("t", "p", client.WithRateLimit(0, 1)); err == nil {
+		t.Fatal("WithRateLimit(0, 1) error = nil, want error")
+	}
+	if _, err := client.NewClient("t", "p", client.WithRateLimit(6, 0)); err == nil {
+		t.Fatal("WithRateLimit(6, 0) error = nil, want error")
+	}
+}
+
+func TestWithRateLimit_PacesRequests(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("t", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithRateLimit(50, 1), // 20ms per request
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	start := time.Now()
+	for range 4 {
+		if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "x", nil, nil); err != nil {
+			t.Fatalf("DoJSONWithRetry() error = %v", err)
+		}
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("4 requests took %v, want >= ~60ms", d)
+	}
+}
+
+func TestWithRateLimit_UserCallsBeatPolling(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		order = append(order, r.URL.Path)
+		mu.Unlock()
+		if strings.Contains(r.URL.Path, "/processes/") {
+			polls.Add(1)
+			id := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+			_, _ = w.Write([]byte(`{"process":{"process_id":"` + id + `","status":"finished"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("t", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithRateLimit(20, 1), // 50ms per request
+		client.WithPollWait(time.Millisecond, 5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	// Drain the single token so every request below queues.
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "warmup", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		if _, err := c.WaitAll(context.Background(), []string{"a", "b", "c"}); err != nil {
+			t.Errorf("WaitAll() error = %v", err)
+		}
+	})
+	time.Sleep(10 * time.Millisecond) // polls are queued on the limiter now
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "user", nil, nil); err != nil {
+		t.Fatalf("DoJSONWithRetry() error = %v", err)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) < 2 || !strings.HasSuffix(order[1], "/user") {
+		t.Fatalf("request order = %v, want user call right after warmup", order)
+	}
+	if polls.Load() != 3 {
+		t.Fatalf("polls = %d, want 3", polls.Load())
+	}
+}
@@ -0,0 +1,18 @@
+package client
+
+import "github.com/bodrovis/lokex/v2/internal/redact"
+
+// Redactor masks credentials and payloads before anything is logged: the
+// X-Api-Token, Authorization and cookie headers, the API token wherever it
+// appears, and JSON fields such as "data" (base64 file contents) and
+// "secret". Diagnostics and audit entries already go through it.
+type Redactor = redact.Redactor
+
+// Redactor returns a Redactor that also masks the client's API token. Use
+// it to sanitize your own logs of lokex requests and errors.
+func (c *Client) Redactor() *Redactor {
+	if c == nil {
+		return redact.New()
+	}
+	return redact.New(c.Token)
+}
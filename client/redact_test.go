@@ -0,0 +1,98 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func TestClient_Redactor(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok-abc", "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rd := c.Redactor()
+	if got := rd.String("sent tok-abc"); got != "sent [REDACTED]" {
+		t.Fatalf("String() = %q", got)
+	}
+	if got := rd.Body(`{"data":"QUJD","lang_iso":"en"}`); got != `{"data":"[REDACTED]","lang_iso":"en"}` {
+		t.Fatalf("Body() = %q", got)
+	}
+
+	var nilClient *client.Client
+	if got := nilClient.Redactor().String("x"); got != "x" {
+		t.Fatalf("nil client Redactor().String() = %q", got)
+	}
+}
+
+// TestNoTokenLeaks checks every sink lokex writes to (returned errors,
+// diagnostics, audit entries) when the server echoes the token and payload.
+func TestNoTokenLeaks(t *testing.T) {
+	t.Parallel()
+
+	const token = "tok-very-secret"
+	const payload = "ZmlsZSBjb250ZW50cw=="
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Echo", r.Header.Get("X-Api-Token"))
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"error":{"message":"invalid token ` + token + `","code":422,"details":{"data":"` + payload + `"}}}`))
+	}))
+	defer srv.Close()
+
+	var audit bytes.Buffer
+	c, err := client.NewClient(token, "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(0),
+		client.WithDiagnostics(5),
+		client.WithAudit(client.NewJSONLinesSink(&audit)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := bytes.NewReader([]byte(`{"filename":"en.json","data":"` + payload + `"}`))
+	callErr := c.DoJSONWithRetry(context.Background(), http.MethodPost, "projects/proj/files/upload", body, nil)
+	if callErr == nil {
+		t.Fatal("expected error")
+	}
+
+	var ae *apierr.APIError
+	if !errors.As(callErr, &ae) {
+		t.Fatalf("error = %v, want *APIError", callErr)
+	}
+	details, err := json.Marshal(ae.Details)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diag, err := json.Marshal(c.Diagnostics())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, out := range map[string]string{
+		"error":       callErr.Error(),
+		"raw body":    ae.Raw,
+		"details":     string(details),
+		"diagnostics": string(diag),
+		"audit":       audit.String(),
+	} {
+		if out == "" || out == "null" {
+			t.Fatalf("%s: nothing recorded", name)
+		}
+		for _, secret := range []string{token, payload} {
+			if strings.Contains(out, secret) {
+				t.Fatalf("%s leaks %q: %s", name, secret, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client/internal/retry"
+)
+
+// ErrAttemptTimeout is matched (via errors.Is) by the error of an attempt
+// cut off by WithAttemptTimeout. Such attempts are retried like other
+// timeouts.
+var ErrAttemptTimeout = retry.ErrAttemptTimeout
+
+// ErrRetryBudgetExhausted is matched (via errors.Is) by the error of an
+// operation that ran out of its retry budget; see WithRetryBudget.
+var ErrRetryBudgetExhausted = retry.ErrRetryBudgetExhausted
+
+// RequestOption overrides a client setting for a single call; see
+// WithRequestOptions. Unlike Option, it never touches state shared with
+// the client it is applied to.
+type RequestOption func(*Client)
+
+// WithHeader sends an extra header with every API request of the call,
+// replacing a previous value. It may override Accept and User-Agent but not
+// Content-Type. An empty key is ignored.
+func WithHeader(key, value string) RequestOption {
+	return func(c *Client) {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return
+		}
+		if c.headers == nil {
+			c.headers = make(http.Header)
+		}
+		c.headers.Set(key, value)
+	}
+}
+
+// WithNoRetry makes the call try every request once.
+func WithNoRetry() RequestOption {
+	return func(c *Client) {
+		c.MaxRetries = 0
+	}
+}
+
+// WithAttemptTimeout bounds each attempt of the call's API requests and
+// bundle downloads at d; an attempt that runs out fails with
+// ErrAttemptTimeout and is retried. The overall deadline still comes from
+// ctx. Zero or negative d removes the bound.
+func WithAttemptTimeout(d time.Duration) RequestOption {
+	return func(c *Client) {
+		c.attemptTimeout = max(d, 0)
+	}
+}
+
+// WithRequestOptions returns a copy of c with opts applied, sharing
+// everything else (like ForProject), or c itself when opts is empty.
+// Downloader and Uploader calls accept the same options.
+func (c *Client) WithRequestOptions(opts ...RequestOption) *Client {
+	if c == nil || len(opts) == 0 {
+		return c
+	}
+	cp := *c
+	cp.headers = c.headers.Clone()
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cp)
+		}
+	}
+	return &cp
+}
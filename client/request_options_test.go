@@ -0,0 +1,151 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestRequestOptions_Header(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu   sync.Mutex
+		seen []http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Clone())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient("tok", "p1", client.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	err = cli.Do(ctx, http.MethodPost, "projects/{project_id}/keys", map[string]any{"k": 1}, nil,
+		client.WithHeader("X-Request-Id", "abc"),
+		client.WithHeader("User-Agent", "sync-job/1"),
+		client.WithHeader("Content-Type", "text/plain"),
+		client.WithHeader(" ", "ignored"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Do(ctx, http.MethodGet, "projects/{project_id}", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	first, second := seen[0], seen[1]
+	if first.Get("X-Request-Id") != "abc" || first.Get("User-Agent") != "sync-job/1" {
+		t.Fatalf("first request headers = %v", first)
+	}
+	if ct := first.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q, want the request's own", ct)
+	}
+	if first.Get("X-Api-Token") != "tok" {
+		t.Fatal("token header lost")
+	}
+	if second.Get("X-Request-Id") != "" || second.Get("User-Agent") == "sync-job/1" {
+		t.Fatalf("options leaked into the next call: %v", second)
+	}
+}
+
+func TestRequestOptions_NoRetry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient("tok", "p1",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(2),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = cli.Do(context.Background(), http.MethodGet, "projects", nil, nil, client.WithNoRetry())
+	if n := calls.Swap(0); n != 1 {
+		t.Fatalf("calls with WithNoRetry = %d, want 1", n)
+	}
+	_ = cli.Do(context.Background(), http.MethodGet, "projects", nil, nil)
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("calls without options = %d, want 3", n)
+	}
+	if cli.MaxRetries != 2 {
+		t.Fatalf("client mutated: MaxRetries = %d", cli.MaxRetries)
+	}
+}
+
+func TestRequestOptions_AttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 || r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient("tok", "p1",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(1),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := client.WithAttemptTimeout(50 * time.Millisecond)
+
+	if err := cli.Do(context.Background(), http.MethodGet, "fast", nil, nil, opt); err != nil {
+		t.Fatalf("err = %v, want the retry after the slow first attempt to succeed", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("calls = %d, want 2", n)
+	}
+
+	err = cli.Do(context.Background(), http.MethodGet, "slow", nil, nil, opt)
+	if !errors.Is(err, client.ErrAttemptTimeout) {
+		t.Fatalf("err = %v, want ErrAttemptTimeout", err)
+	}
+}
+
+func TestWithRequestOptions_NoOptions(t *testing.T) {
+	t.Parallel()
+	cli, err := client.NewClient("tok", "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.WithRequestOptions() != cli {
+		t.Fatal("WithRequestOptions() without options should return the receiver")
+	}
+	if cp := cli.WithRequestOptions(client.WithNoRetry()); cp == cli || cp.MaxRetries != 0 || cli.MaxRetries == 0 {
+		t.Fatal("WithRequestOptions(WithNoRetry()) should change only the copy")
+	}
+}
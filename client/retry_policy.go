@@ -0,0 +1,68 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bodrovis/lokex/v2/client/internal/retry"
+)
+
+// RetryPolicy decides whether a failed attempt is retried and how long to
+// wait first; see WithRetryPolicy.
+type RetryPolicy = retry.Policy
+
+// RetryAttempt describes a failed attempt for a RetryPolicy. Method and Path
+// are set for API requests (label "request"); bundle downloads use the label
+// "download".
+type RetryAttempt = retry.Attempt
+
+// RetryPolicyFunc adapts a function to RetryPolicy.
+type RetryPolicyFunc = retry.PolicyFunc
+
+// DefaultRetryPolicy is the policy used without WithRetryPolicy: transient
+// failures (timeouts, reset or refused connections, broken pipes, temporary
+// DNS failures, 408/425/429/5xx) are retried after RetryAttempt.Delay.
+var DefaultRetryPolicy RetryPolicy = retry.DefaultPolicy
+
+// WithRetryPolicy lets p decide, for every failed API request and bundle
+// download, whether to retry and after what delay. MaxRetries still caps
+// the number of retries; p is only asked while retries remain. A negative
+// delay means no wait. p also overrides the isRetryable passed to
+// WithExpBackoff. Polling async processes is not affected.
+// p must be non-nil.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) error {
+		if p == nil {
+			return errors.New("retry policy cannot be nil")
+		}
+		c.retryPolicy = p
+		return nil
+	}
+}
+
+// Jitter selects how retry delays are randomized around the exponential
+// backoff; see WithJitter.
+type Jitter = retry.Jitter
+
+// Jitter strategies for a backoff b.
+const (
+	JitterDefault      = retry.JitterDefault      // uniform [b/2, 3b/2)
+	JitterNone         = retry.JitterNone         // exactly b
+	JitterFull         = retry.JitterFull         // uniform [0, b)
+	JitterEqual        = retry.JitterEqual        // uniform [b/2, b)
+	JitterDecorrelated = retry.JitterDecorrelated // uniform [initial backoff, 3*previous delay)
+)
+
+// WithJitter sets how retry delays are randomized. Many workers sharing
+// one rate limit should use JitterDecorrelated or JitterFull, so their
+// retries after a shared failure spread out instead of arriving together.
+// Delays stay capped by the max backoff and raised by Retry-After.
+func WithJitter(j Jitter) Option {
+	return func(c *Client) error {
+		if !j.Valid() {
+			return fmt.Errorf("unknown jitter strategy %v", j)
+		}
+		c.Jitter = j
+		return nil
+	}
+}
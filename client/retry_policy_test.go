@@ -0,0 +1,130 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// noPostRetries never retries POSTs, retries a bundle 404 once, and
+// otherwise defers to the default policy.
+var noPostRetries = client.RetryPolicyFunc(func(a client.RetryAttempt) (time.Duration, bool) {
+	switch {
+	case a.Method == http.MethodPost:
+		return 0, false
+	case a.Label == "download":
+		var ae *client.APIError
+		if errors.As(a.Err, &ae) && ae.Status == http.StatusNotFound {
+			return 0, a.Retry == 0
+		}
+	}
+	return client.DefaultRetryPolicy.Retry(a)
+})
+
+func TestWithRetryPolicy_Requests(t *testing.T) {
+	t.Parallel()
+
+	var posts, gets atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts.Add(1)
+		} else {
+			gets.Add(1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":{"message":"down","code":503}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("tok", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(2),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+		client.WithRetryPolicy(noPostRetries),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := c.DoJSONWithRetry(ctx, http.MethodPost, "projects/p/files/upload", strings.NewReader(`{}`), nil); err == nil {
+		t.Fatal("POST: expected error")
+	}
+	if err := c.DoJSONWithRetry(ctx, http.MethodGet, "projects/p/keys", nil, nil); err == nil {
+		t.Fatal("GET: expected error")
+	}
+	if posts.Load() != 1 || gets.Load() != 3 {
+		t.Fatalf("posts = %d, gets = %d; want 1 and 3", posts.Load(), gets.Load())
+	}
+}
+
+func TestWithRetryPolicy_Download404Once(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok", "p",
+		client.WithMaxRetries(3),
+		client.WithRetryPolicy(noPostRetries),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	err = c.WithExpBackoff(context.Background(), "download", func(int) error {
+		calls++
+		return &client.APIError{Status: http.StatusNotFound}
+	}, nil)
+	if err == nil || calls != 2 {
+		t.Fatalf("err = %v, calls = %d; want an error after 2 calls", err, calls)
+	}
+}
+
+func TestWithRetryPolicy_Nil(t *testing.T) {
+	t.Parallel()
+	if _, err := client.NewClient("tok", "p", client.WithRetryPolicy(nil)); err == nil {
+		t.Fatal("expected error for nil policy")
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	var delays []time.Duration
+	c, err := client.NewClient("tok", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithMaxRetries(2),
+		client.WithBackoff(time.Millisecond, time.Second),
+		client.WithJitter(client.JitterNone),
+		client.WithRetryPolicy(client.RetryPolicyFunc(func(a client.RetryAttempt) (time.Duration, bool) {
+			delays = append(delays, a.Delay)
+			return 0, true
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Jitter != client.JitterNone {
+		t.Fatalf("Jitter = %v, want none", c.Jitter)
+	}
+	_ = c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects", nil, nil)
+
+	if len(delays) != 2 || delays[0] != time.Millisecond || delays[1] != 2*time.Millisecond {
+		t.Fatalf("delays = %v, want [1ms 2ms] without jitter", delays)
+	}
+
+	if _, err := client.NewClient("tok", "p", client.WithJitter(client.Jitter(99))); err == nil {
+		t.Fatal("WithJitter(99): expected error")
+	}
+}
@@ -0,0 +1,10 @@
+package client
+
+import "github.com/bodrovis/lokex/v2/internal/schema"
+
+// SchemaError is returned (wrapped) under WithStrictDecoding when a response
+// lacks a field lokex relies on. Path locates it, e.g. "keys[2].key_id".
+type SchemaError = schema.Error
+
+// ErrSchemaMismatch is matched (via errors.Is) by a *SchemaError.
+var ErrSchemaMismatch = schema.ErrMismatch
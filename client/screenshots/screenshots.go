@@ -0,0 +1,252 @@
+// Package screenshots uploads screenshots to a Lokalise project and links
+// them to keys.
+//
+// The main entry point is Manager.LinkDir: given a directory of images named
+// after key names (or tags), it resolves each file to key IDs via the Keys
+// API, uploads it via the Screenshots API with those keys attached, and
+// returns a report of what was linked, skipped or failed.
+package screenshots
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/keys"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// Manager wraps a *Client to work with project screenshots.
+// Construct with NewManager; the embedded client must be non-nil.
+type Manager struct {
+	client *client.Client
+}
+
+// NewManager creates a new Manager bound to c.
+func NewManager(c *client.Client) *Manager {
+	if c == nil {
+		panic("lokex/screenshots: nil client passed to NewManager")
+	}
+	return &Manager{
+		client: c,
+	}
+}
+
+const managerIsNilMsg = "screenshots: manager/client is nil"
+
+// imageTypes maps supported file extensions to MIME types.
+var imageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+}
+
+// NewScreenshot is a screenshot to create. Data is the raw image; MIME is
+// its content type (image/png, image/jpeg or image/gif).
+type NewScreenshot struct {
+	Data        []byte
+	MIME        string
+	Title       string
+	Description string
+	KeyIDs      []int64
+	Tags        []string
+}
+
+// Screenshot is a subset of the Lokalise screenshot object.
+type Screenshot struct {
+	ScreenshotID int64    `json:"screenshot_id" lokex:"required"`
+	KeyIDs       []int64  `json:"key_ids"`
+	Title        string   `json:"title"`
+	URL          string   `json:"url"`
+	Tags         []string `json:"tags"`
+}
+
+// Create uploads one screenshot, linking it to s.KeyIDs.
+func (m *Manager) Create(ctx context.Context, s NewScreenshot) (Screenshot, error) {
+	if m == nil || m.client == nil {
+		return Screenshot{}, errors.New(managerIsNilMsg)
+	}
+	if len(s.Data) == 0 {
+		return Screenshot{}, errors.New("screenshots: create: empty image data")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	item := map[string]any{
+		"data": "data:" + s.MIME + ";base64," + base64.StdEncoding.EncodeToString(s.Data),
+	}
+	if s.Title != "" {
+		item["title"] = s.Title
+	}
+	if s.Description != "" {
+		item["description"] = s.Description
+	}
+	if len(s.KeyIDs) > 0 {
+		item["key_ids"] = s.KeyIDs
+	}
+	if len(s.Tags) > 0 {
+		item["tags"] = s.Tags
+	}
+	body, err := m.client.EncodeJSON(map[string]any{"screenshots": []any{item}})
+	if err != nil {
+		return Screenshot{}, fmt.Errorf("screenshots: create: %w", err)
+	}
+
+	var resp struct {
+		Screenshots []Screenshot `json:"screenshots" lokex:"required"`
+	}
+	path := utils.ProjectPath(m.client.ProjectID, "screenshots")
+	if err := m.client.DoJSONWithRetry(ctx, http.MethodPost, path, body, &resp); err != nil {
+		return Screenshot{}, fmt.Errorf("screenshots: create: %w", err)
+	}
+	if len(resp.Screenshots) == 0 {
+		return Screenshot{}, errors.New("screenshots: create: empty response")
+	}
+	return resp.Screenshots[0], nil
+}
+
+// MatchBy selects how LinkDir maps file names to keys.
+type MatchBy int
+
+const (
+	// MatchKeyName links a file to the keys whose name (on any platform)
+	// equals the file name without extension.
+	MatchKeyName MatchBy = iota
+	// MatchTag links a file to every key tagged with the file name.
+	MatchTag
+)
+
+// LinkOptions configures LinkDir.
+type LinkOptions struct {
+	Match MatchBy
+	// Filter narrows the keys considered (e.g. filter_filenames).
+	Filter keys.ListParams
+	// Tags are added to every uploaded screenshot.
+	Tags []string
+	// DryRun resolves the mapping without uploading anything.
+	DryRun bool
+}
+
+// Linked is a file uploaded (or, in a dry run, to be uploaded) and the keys
+// it is linked to.
+type Linked struct {
+	File         string // relative to the directory
+	ScreenshotID int64  // 0 in a dry run
+	KeyIDs       []int64
+}
+
+// Failed is a file that matched keys but could not be uploaded.
+type Failed struct {
+	File string
+	Err  error
+}
+
+// LinkReport is the result of LinkDir. Unless ctx ends the run early, every
+// image file in the directory is listed in exactly one of Linked, Unmatched
+// and Failed.
+type LinkReport struct {
+	Linked    []Linked
+	Unmatched []string // images whose name matched no key
+	Failed    []Failed
+}
+
+// HasErrors reports whether any upload failed.
+func (r LinkReport) HasErrors() bool { return len(r.Failed) > 0 }
+
+// LinkDir uploads every image in dir (non-recursive; .png, .jpg, .jpeg,
+// .gif) and links it to the keys its file name matches. Images that match
+// nothing are reported, not uploaded. A failed upload does not stop the
+// others; an error is returned only if listing fails or ctx is done.
+func (m *Manager) LinkDir(ctx context.Context, dir string, opts LinkOptions) (LinkReport, error) {
+	if m == nil || m.client == nil {
+		return LinkReport{}, errors.New(managerIsNilMsg)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return LinkReport{}, fmt.Errorf("screenshots: link: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && imageTypes[strings.ToLower(filepath.Ext(e.Name()))] != "" {
+			files = append(files, e.Name())
+		}
+	}
+	if len(files) == 0 {
+		return LinkReport{}, nil
+	}
+
+	all, err := keys.NewManager(m.client).List(ctx, opts.Filter)
+	if err != nil {
+		return LinkReport{}, fmt.Errorf("screenshots: link: %w", err)
+	}
+	index := indexKeys(all, opts.Match)
+
+	var rep LinkReport
+	for _, name := range files {
+		ext := filepath.Ext(name)
+		base := strings.TrimSuffix(name, ext)
+		ids := index[base]
+		if len(ids) == 0 {
+			rep.Unmatched = append(rep.Unmatched, name)
+			continue
+		}
+		if opts.DryRun {
+			rep.Linked = append(rep.Linked, Linked{File: name, KeyIDs: ids})
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			var s Screenshot
+			s, err = m.Create(ctx, NewScreenshot{
+				Data:   data,
+				MIME:   imageTypes[strings.ToLower(ext)],
+				Title:  base,
+				KeyIDs: ids,
+				Tags:   opts.Tags,
+			})
+			if err == nil {
+				rep.Linked = append(rep.Linked, Linked{File: name, ScreenshotID: s.ScreenshotID, KeyIDs: ids})
+				continue
+			}
+		}
+		rep.Failed = append(rep.Failed, Failed{File: name, Err: err})
+		if ctx.Err() != nil {
+			return rep, fmt.Errorf("screenshots: link: %w", ctx.Err())
+		}
+	}
+	return rep, nil
+}
+
+// indexKeys maps key names or tags to the sorted IDs of the matching keys.
+func indexKeys(all []keys.Key, by MatchBy) map[string][]int64 {
+	index := make(map[string][]int64)
+	for _, k := range all {
+		labels := k.Names()
+		if by == MatchTag {
+			labels = k.Tags
+		}
+		for _, l := range labels {
+			if !slices.Contains(index[l], k.KeyID) {
+				index[l] = append(index[l], k.KeyID)
+			}
+		}
+	}
+	for _, ids := range index {
+		slices.Sort(ids)
+	}
+	return index
+}
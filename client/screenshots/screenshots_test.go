@@ -0,0 +1,215 @@
+package screenshots_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/screenshots"
+)
+
+const keysJSON = `{"keys":[
+	{"key_id":1,"key_name":{"ios":"home.title","android":"home_title","web":"home.title","other":""},"tags":["home"]},
+	{"key_id":2,"key_name":"home.subtitle","tags":["home"]},
+	{"key_id":3,"key_name":"settings.title","tags":["settings"]}
+]}`
+
+type uploaded struct {
+	Data   string   `json:"data"`
+	Title  string   `json:"title"`
+	KeyIDs []int64  `json:"key_ids"`
+	Tags   []string `json:"tags"`
+}
+
+// fakeProject serves GET /keys and POST /screenshots. Uploads titled with a
+// name in failTitles get a 400.
+func fakeProject(t *testing.T, failTitles ...string) (*screenshots.Manager, func() []uploaded) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var ups []uploaded
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/proj/keys":
+			_, _ = w.Write([]byte(keysJSON))
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/proj/screenshots":
+			var body struct {
+				Screenshots []uploaded `json:"screenshots"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Screenshots) != 1 {
+				http.Error(w, "bad body", http.StatusBadRequest)
+				return
+			}
+			s := body.Screenshots[0]
+			if slices.Contains(failTitles, s.Title) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":{"message":"invalid image","code":400}}`))
+				return
+			}
+			mu.Lock()
+			ups = append(ups, s)
+			id := len(ups) * 100
+			mu.Unlock()
+			resp, _ := json.Marshal(map[string]any{"screenshots": []any{map[string]any{
+				"screenshot_id": id, "key_ids": s.KeyIDs, "title": s.Title,
+			}}})
+			_, _ = w.Write(resp)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient("tok", "proj", client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return screenshots.NewManager(c), func() []uploaded {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(ups)
+	}
+}
+
+func writeFiles(t *testing.T, names ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, n := range names {
+		if err := os.WriteFile(filepath.Join(dir, n), []byte("img:"+n), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestNewManager_NilClientPanics(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewManager(nil) did not panic")
+		}
+	}()
+	_ = screenshots.NewManager(nil)
+}
+
+func TestManager_LinkDir_ByKeyName(t *testing.T) {
+	t.Parallel()
+
+	m, uploads := fakeProject(t)
+	dir := writeFiles(t, "home.title.png", "home_title.jpg", "missing.png", "notes.txt")
+	if err := os.Mkdir(filepath.Join(dir, "sub.png"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := m.LinkDir(context.Background(), dir, screenshots.LinkOptions{Tags: []string{"ci"}})
+	if err != nil {
+		t.Fatalf("LinkDir() error = %v", err)
+	}
+	if len(rep.Linked) != 2 || rep.HasErrors() {
+		t.Fatalf("report = %+v", rep)
+	}
+	for _, l := range rep.Linked {
+		if !slices.Equal(l.KeyIDs, []int64{1}) || l.ScreenshotID == 0 {
+			t.Fatalf("linked = %+v", l)
+		}
+	}
+	if !slices.Equal(rep.Unmatched, []string{"missing.png"}) {
+		t.Fatalf("Unmatched = %v", rep.Unmatched)
+	}
+
+	ups := uploads()
+	if len(ups) != 2 {
+		t.Fatalf("uploads = %d, want 2", len(ups))
+	}
+	if ups[0].Title != "home.title" || !slices.Equal(ups[0].Tags, []string{"ci"}) {
+		t.Fatalf("upload = %+v", ups[0])
+	}
+	data, ok := strings.CutPrefix(ups[0].Data, "data:image/png;base64,")
+	if raw, _ := base64.StdEncoding.DecodeString(data); !ok || string(raw) != "img:home.title.png" {
+		t.Fatalf("data = %q", ups[0].Data)
+	}
+	if !strings.HasPrefix(ups[1].Data, "data:image/jpeg;base64,") {
+		t.Fatalf("jpg uploaded as %q", ups[1].Data[:24])
+	}
+}
+
+func TestManager_LinkDir_ByTagAndDryRun(t *testing.T) {
+	t.Parallel()
+
+	m, uploads := fakeProject(t)
+	dir := writeFiles(t, "home.png", "settings.gif")
+
+	rep, err := m.LinkDir(context.Background(), dir, screenshots.LinkOptions{Match: screenshots.MatchTag, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []screenshots.Linked{
+		{File: "home.png", KeyIDs: []int64{1, 2}},
+		{File: "settings.gif", KeyIDs: []int64{3}},
+	}
+	if len(rep.Linked) != len(want) {
+		t.Fatalf("Linked = %+v", rep.Linked)
+	}
+	for i := range want {
+		if rep.Linked[i].File != want[i].File || !slices.Equal(rep.Linked[i].KeyIDs, want[i].KeyIDs) || rep.Linked[i].ScreenshotID != 0 {
+			t.Fatalf("Linked[%d] = %+v, want %+v", i, rep.Linked[i], want[i])
+		}
+	}
+	if n := len(uploads()); n != 0 {
+		t.Fatalf("dry run uploaded %d screenshots", n)
+	}
+}
+
+func TestManager_LinkDir_PartialFailure(t *testing.T) {
+	t.Parallel()
+
+	m, _ := fakeProject(t, "home.subtitle")
+	dir := writeFiles(t, "home.subtitle.png", "settings.title.png")
+
+	rep, err := m.LinkDir(context.Background(), dir, screenshots.LinkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.HasErrors() || len(rep.Failed) != 1 || rep.Failed[0].File != "home.subtitle.png" {
+		t.Fatalf("Failed = %+v", rep.Failed)
+	}
+	if !strings.Contains(rep.Failed[0].Err.Error(), "invalid image") {
+		t.Fatalf("Failed err = %v", rep.Failed[0].Err)
+	}
+	if len(rep.Linked) != 1 || rep.Linked[0].File != "settings.title.png" {
+		t.Fatalf("Linked = %+v", rep.Linked)
+	}
+}
+
+func TestManager_LinkDir_Errors(t *testing.T) {
+	t.Parallel()
+
+	var nilM *screenshots.Manager
+	if _, err := nilM.LinkDir(context.Background(), t.TempDir(), screenshots.LinkOptions{}); err == nil {
+		t.Fatal("nil manager error = nil")
+	}
+
+	m, _ := fakeProject(t)
+	if _, err := m.LinkDir(context.Background(), filepath.Join(t.TempDir(), "nope"), screenshots.LinkOptions{}); err == nil {
+		t.Fatal("missing dir error = nil")
+	}
+	rep, err := m.LinkDir(context.Background(), writeFiles(t, "readme.md"), screenshots.LinkOptions{})
+	if err != nil || len(rep.Linked)+len(rep.Unmatched)+len(rep.Failed) != 0 {
+		t.Fatalf("no images: %+v, %v", rep, err)
+	}
+	if _, err := m.Create(context.Background(), screenshots.NewScreenshot{MIME: "image/png"}); err == nil {
+		t.Fatal("Create(empty) error = nil")
+	}
+}
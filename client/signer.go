@@ -0,0 +1,26 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/bodrovis/lokex/v2/client/internal/transport"
+)
+
+// Signer computes per-attempt auth headers (HMAC signatures, proxy tokens, ...).
+// It runs after the standard headers are set and right before each send,
+// including retries. body is the full request body, or nil for body-less
+// requests; the signer must not modify it.
+type Signer = transport.Signer
+
+// WithSigner installs a request Signer for API calls. With a signer set,
+// request bodies (including file uploads) are buffered in memory per attempt.
+// Bundle downloads from the CDN are not signed. The signer must be non-nil.
+func WithSigner(s Signer) Option {
+	return func(c *Client) error {
+		if s == nil {
+			return errors.New("signer cannot be nil")
+		}
+		c.Signer = s
+		return nil
+	}
+}
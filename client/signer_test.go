@@ -0,0 +1,72 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestWithSigner_Nil(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("t", "p", client.WithSigner(nil)); err == nil {
+		t.Fatal("WithSigner(nil) error = nil, want error")
+	}
+}
+
+func TestWithSigner_SignsEveryAttempt(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("shared-secret")
+	mac := func(body []byte) string {
+		h := hmac.New(sha256.New, key)
+		h.Write(body)
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got := r.Header.Get("X-Body-HMAC"); got != mac(body) {
+			t.Errorf("X-Body-HMAC = %q, want %q", got, mac(body))
+		}
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	var signed atomic.Int32
+	c, err := client.NewClient("t", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithHTTPClient(srv.Client()),
+		client.WithBackoff(1, 1),
+		client.WithSigner(func(req *http.Request, body []byte) error {
+			signed.Add(1)
+			req.Header.Set("X-Body-HMAC", mac(body))
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	body := bytes.NewReader([]byte(`{"keys":[1,2]}`))
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodPost, "keys", body, nil); err != nil {
+		t.Fatalf("DoJSONWithRetry() error = %v", err)
+	}
+	if hits.Load() != 2 || signed.Load() != 2 {
+		t.Fatalf("hits/signed = %d/%d, want 2/2", hits.Load(), signed.Load())
+	}
+}
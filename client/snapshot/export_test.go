@@ -0,0 +1,9 @@
+package snapshot
+
+func ExportSetListPageLimitForTest(n int) func() {
+	prev := listPageLimit
+	listPageLimit = n
+	return func() {
+		listPageLimit = prev
+	}
+}
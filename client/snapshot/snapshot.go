@@ -0,0 +1,287 @@
+// Package snapshot exports a project's configuration (languages, custom
+// translation statuses, webhooks and settings) to a local JSON snapshot and
+// re-applies it to another project, e.g. to clone staging into production.
+//
+// Applying is additive and idempotent: missing languages, statuses and
+// webhooks are created, nothing is deleted or overwritten. Project settings
+// cannot be changed through the API, so they are only compared.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// Version is the snapshot format version written by Export.
+const Version = 1
+
+// listPageLimit is the page size used when listing project resources.
+var listPageLimit = 5000
+
+// Manager wraps a *Client to export and apply project snapshots.
+// Construct with NewManager; the embedded client must be non-nil.
+type Manager struct {
+	client *client.Client
+}
+
+// NewManager creates a new Manager bound to c.
+func NewManager(c *client.Client) *Manager {
+	if c == nil {
+		panic("lokex/snapshot: nil client passed to NewManager")
+	}
+	return &Manager{
+		client: c,
+	}
+}
+
+const managerIsNilMsg = "snapshot: manager/client is nil"
+
+// Snapshot is the portable configuration of a project.
+type Snapshot struct {
+	Version         int            `json:"version"`
+	SourceProjectID string         `json:"source_project_id"`
+	Name            string         `json:"name"`
+	Description     string         `json:"description,omitempty"`
+	BaseLanguageISO string         `json:"base_language_iso,omitempty"`
+	Settings        map[string]any `json:"settings,omitempty"`
+	Languages       []Language     `json:"languages"`
+	CustomStatuses  []CustomStatus `json:"custom_translation_statuses"`
+	Webhooks        []Webhook      `json:"webhooks"`
+}
+
+// Language is a project language. Apply creates missing languages under
+// LangName; Lokalise derives the text direction from the ISO code.
+type Language struct {
+	LangISO  string `json:"lang_iso"`
+	LangName string `json:"lang_name,omitempty"`
+}
+
+// CustomStatus is a custom translation status.
+type CustomStatus struct {
+	Title string `json:"title"`
+	Color string `json:"color"`
+}
+
+// Webhook is a project webhook. The signing secret is not exported; the
+// target project gets a new one when the webhook is created.
+type Webhook struct {
+	URL          string            `json:"url"`
+	Events       []string          `json:"events"`
+	EventLangMap []json.RawMessage `json:"event_lang_map,omitempty"`
+}
+
+// ApplyResult reports what Apply created and which settings differ.
+type ApplyResult struct {
+	LanguagesAdded      []string
+	StatusesAdded       []string
+	WebhooksAdded       []string
+	SettingsDifferences []string // setting names whose values differ; not applied
+}
+
+// Export reads the project's configuration into a Snapshot.
+func (m *Manager) Export(ctx context.Context) (Snapshot, error) {
+	if m == nil || m.client == nil {
+		return Snapshot{}, errors.New(managerIsNilMsg)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var project struct {
+		Name            string         `json:"name"`
+		Description     string         `json:"description"`
+		BaseLanguageISO string         `json:"base_language_iso"`
+		Settings        map[string]any `json:"settings"`
+	}
+	if err := m.client.DoJSONWithRetry(ctx, http.MethodGet, m.projectPath(), nil, &project); err != nil {
+		return Snapshot{}, fmt.Errorf("snapshot: export: project: %w", err)
+	}
+
+	s := Snapshot{
+		Version:         Version,
+		SourceProjectID: m.client.ProjectID,
+		Name:            project.Name,
+		Description:     project.Description,
+		BaseLanguageISO: project.BaseLanguageISO,
+		Settings:        project.Settings,
+	}
+
+	var err error
+	if s.Languages, err = listAll[Language](ctx, m, "languages", "languages"); err != nil {
+		return Snapshot{}, fmt.Errorf("snapshot: export: %w", err)
+	}
+	// Custom statuses are a paid feature; projects without it have none.
+	if s.CustomStatuses, err = listAll[CustomStatus](ctx, m, "custom_translation_statuses", "custom_translation_statuses"); err != nil && !unavailable(err) {
+		return Snapshot{}, fmt.Errorf("snapshot: export: %w", err)
+	}
+	if s.Webhooks, err = listAll[Webhook](ctx, m, "webhooks", "webhooks"); err != nil {
+		return Snapshot{}, fmt.Errorf("snapshot: export: %w", err)
+	}
+	return s, nil
+}
+
+// Apply creates the snapshot's languages, custom statuses and webhooks that
+// the project lacks (matched by ISO code, title and URL respectively), and
+// reports settings whose values differ.
+func (m *Manager) Apply(ctx context.Context, s Snapshot) (ApplyResult, error) {
+	return m.apply(ctx, s, false)
+}
+
+// Plan reports what Apply would create and which settings differ, without
+// changing the project.
+func (m *Manager) Plan(ctx context.Context, s Snapshot) (ApplyResult, error) {
+	return m.apply(ctx, s, true)
+}
+
+func (m *Manager) apply(ctx context.Context, s Snapshot, dryRun bool) (ApplyResult, error) {
+	op := "apply"
+	if dryRun {
+		op = "plan"
+	}
+	if m == nil || m.client == nil {
+		return ApplyResult{}, errors.New(managerIsNilMsg)
+	}
+	if s.Version != Version {
+		return ApplyResult{}, fmt.Errorf("snapshot: %s: unsupported version %d", op, s.Version)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	current, err := m.Export(ctx)
+	if err != nil {
+		return ApplyResult{}, fmt.Errorf("snapshot: %s: %w", op, err)
+	}
+
+	var res ApplyResult
+	res.SettingsDifferences = diffSettings(s.Settings, current.Settings)
+
+	var (
+		langs []map[string]any
+		isos  []string
+	)
+	for _, l := range s.Languages {
+		if !slices.ContainsFunc(current.Languages, func(c Language) bool { return strings.EqualFold(c.LangISO, l.LangISO) }) {
+			lang := map[string]any{"lang_iso": l.LangISO}
+			if l.LangName != "" {
+				lang["custom_name"] = l.LangName
+			}
+			langs = append(langs, lang)
+			isos = append(isos, l.LangISO)
+		}
+	}
+	if len(langs) > 0 && !dryRun {
+		if err := m.post(ctx, "languages", map[string]any{"languages": langs}); err != nil {
+			return res, fmt.Errorf("snapshot: apply: languages: %w", err)
+		}
+	}
+	res.LanguagesAdded = isos
+
+	for _, st := range s.CustomStatuses {
+		if slices.ContainsFunc(current.CustomStatuses, func(c CustomStatus) bool { return c.Title == st.Title }) {
+			continue
+		}
+		if !dryRun {
+			if err := m.post(ctx, "custom_translation_statuses", st); err != nil {
+				return res, fmt.Errorf("snapshot: apply: custom status %q: %w", st.Title, err)
+			}
+		}
+		res.StatusesAdded = append(res.StatusesAdded, st.Title)
+	}
+
+	for _, wh := range s.Webhooks {
+		if slices.ContainsFunc(current.Webhooks, func(c Webhook) bool { return c.URL == wh.URL }) {
+			continue
+		}
+		if !dryRun {
+			if err := m.post(ctx, "webhooks", wh); err != nil {
+				return res, fmt.Errorf("snapshot: apply: webhook %q: %w", wh.URL, err)
+			}
+		}
+		res.WebhooksAdded = append(res.WebhooksAdded, wh.URL)
+	}
+	return res, nil
+}
+
+// unavailable reports whether err says the endpoint is not available to the
+// project (403 or 404), e.g. a feature its plan lacks.
+func unavailable(err error) bool {
+	var ae *client.APIError
+	return errors.As(err, &ae) && (ae.Status == http.StatusForbidden || ae.Status == http.StatusNotFound)
+}
+
+// Write encodes s as indented JSON.
+func Write(w io.Writer, s Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("snapshot: write: %w", err)
+	}
+	return nil
+}
+
+// Read decodes a snapshot written by Write.
+func Read(r io.Reader) (Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return Snapshot{}, fmt.Errorf("snapshot: read: %w", err)
+	}
+	if s.Version != Version {
+		return Snapshot{}, fmt.Errorf("snapshot: read: unsupported version %d", s.Version)
+	}
+	return s, nil
+}
+
+func diffSettings(want, have map[string]any) []string {
+	var out []string
+	for k, v := range want {
+		if !reflect.DeepEqual(v, have[k]) {
+			out = append(out, k)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func (m *Manager) projectPath() string {
+	return "projects/" + url.PathEscape(m.client.ProjectID)
+}
+
+func (m *Manager) post(ctx context.Context, resource string, body any) error {
+	rdr, err := utils.EncodeJSONBodyWith(m.client.Codec, body)
+	if err != nil {
+		return err
+	}
+	return m.client.DoJSONWithRetry(ctx, http.MethodPost, utils.ProjectPath(m.client.ProjectID, resource), rdr, nil)
+}
+
+// listAll walks every page of a project resource and decodes the items found
+// under field.
+func listAll[T any](ctx context.Context, m *Manager, resource, field string) ([]T, error) {
+	path := utils.ProjectPath(m.client.ProjectID, resource)
+	pages := client.NewPaginator[T](m.client, path, field, client.PaginatorOptions{Limit: max(listPageLimit, 1)})
+
+	var out []T
+	for page := 1; ; page++ {
+		items, err := pages.Next(ctx)
+		if errors.Is(err, client.ErrNoMorePages) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s (page %d): %w", resource, page, err)
+		}
+		out = append(out, items...)
+	}
+}
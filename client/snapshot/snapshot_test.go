@@ -0,0 +1,327 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/snapshot"
+)
+
+func newTestClient(t *testing.T, srv *httptest.Server, projectID string) *client.Client {
+	t.Helper()
+
+	c, err := client.NewClient("tok", projectID,
+		client.WithBaseURL(srv.URL),
+		client.WithHTTPClient(srv.Client()),
+		client.WithMaxRetries(0),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+// fakeProject serves one project's configuration endpoints.
+type fakeProject struct {
+	tb        testing.TB
+	mu        sync.Mutex
+	project   string
+	languages []string
+	statuses  []string
+	webhooks  []string
+	posts     []string
+
+	noStatuses    bool // answer 403 for custom statuses, as without the feature
+	failLanguages bool // answer 500 to creating languages
+}
+
+func (f *fakeProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	resource := strings.TrimPrefix(r.URL.Path, "/projects/proj/")
+
+	if resource == "custom_translation_statuses" && f.noStatuses {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprint(w, `{"error":{"message":"Custom translation statuses are not enabled","code":403}}`)
+		return
+	}
+	if r.Method == http.MethodPost && resource == "languages" && f.failLanguages {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprint(w, `{"error":{"message":"boom","code":500}}`)
+		return
+	}
+	if r.Method == http.MethodPost {
+		body, _ := io.ReadAll(r.Body)
+		f.posts = append(f.posts, resource+" "+strings.TrimSpace(string(body)))
+		switch resource {
+		case "languages":
+			var req struct {
+				Languages []struct {
+					LangISO string `json:"lang_iso"`
+				} `json:"languages"`
+			}
+			_ = json.Unmarshal(body, &req)
+			for _, l := range req.Languages {
+				f.languages = append(f.languages, fmt.Sprintf(`{"lang_iso":%q}`, l.LangISO))
+			}
+		case "custom_translation_statuses":
+			f.statuses = append(f.statuses, string(body))
+		case "webhooks":
+			f.webhooks = append(f.webhooks, string(body))
+		}
+		_, _ = fmt.Fprint(w, `{}`)
+		return
+	}
+
+	if r.URL.Path == "/projects/proj" {
+		_, _ = fmt.Fprint(w, f.project)
+		return
+	}
+	items := map[string][]string{
+		"languages":                   f.languages,
+		"custom_translation_statuses": f.statuses,
+		"webhooks":                    f.webhooks,
+	}
+	list, ok := items[resource]
+	if !ok {
+		f.tb.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if page < 1 || limit < 1 {
+		f.tb.Errorf("bad paging: page=%d limit=%d", page, limit)
+	}
+	from := min((page-1)*limit, len(list))
+	to := min(from+limit, len(list))
+	_, _ = fmt.Fprintf(w, `{"project_id":"proj",%q:[%s]}`, resource, strings.Join(list[from:to], ","))
+}
+
+func TestManager_ExportApply_RoundTrip(t *testing.T) {
+	source := &fakeProject{
+		tb:        t,
+		project:   `{"project_id":"proj","name":"Staging","base_language_iso":"en","settings":{"per_platform_key_names":false,"branching":true}}`,
+		languages: []string{`{"lang_id":640,"lang_iso":"en","lang_name":"English"}`, `{"lang_iso":"ar","lang_name":"Arabic","is_rtl":true}`},
+		statuses:  []string{`{"status_id":1,"title":"Reviewed by legal","color":"#f2d600"}`},
+		webhooks:  []string{`{"webhook_id":"w1","url":"https://hooks.example.com/lokalise","secret":"s3cret","events":["project.imported"]}`},
+	}
+	srcSrv := httptest.NewServer(source)
+	defer srcSrv.Close()
+
+	snap, err := snapshot.NewManager(newTestClient(t, srcSrv, "proj")).Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := snapshot.Write(&buf, snap); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "s3cret") {
+		t.Fatal("snapshot contains the webhook secret")
+	}
+
+	snap, err = snapshot.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if snap.Name != "Staging" || snap.BaseLanguageISO != "en" || snap.SourceProjectID != "proj" {
+		t.Fatalf("snapshot header = %+v", snap)
+	}
+	wantLangs := []snapshot.Language{{LangISO: "en", LangName: "English"}, {LangISO: "ar", LangName: "Arabic"}}
+	if !reflect.DeepEqual(snap.Languages, wantLangs) {
+		t.Fatalf("Languages = %+v, want %+v", snap.Languages, wantLangs)
+	}
+
+	target := &fakeProject{
+		tb:        t,
+		project:   `{"project_id":"proj","name":"Production","settings":{"per_platform_key_names":false,"branching":false}}`,
+		languages: []string{`{"lang_iso":"EN"}`},
+	}
+	dstSrv := httptest.NewServer(target)
+	defer dstSrv.Close()
+
+	m := snapshot.NewManager(newTestClient(t, dstSrv, "proj"))
+	res, err := m.Apply(context.Background(), snap)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	want := snapshot.ApplyResult{
+		LanguagesAdded:      []string{"ar"},
+		StatusesAdded:       []string{"Reviewed by legal"},
+		WebhooksAdded:       []string{"https://hooks.example.com/lokalise"},
+		SettingsDifferences: []string{"branching"},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("Apply() = %+v, want %+v", res, want)
+	}
+
+	wantPosts := []string{
+		`languages {"languages":[{"custom_name":"Arabic","lang_iso":"ar"}]}`,
+		`custom_translation_statuses {"title":"Reviewed by legal","color":"#f2d600"}`,
+		`webhooks {"url":"https://hooks.example.com/lokalise","events":["project.imported"]}`,
+	}
+	if !reflect.DeepEqual(target.posts, wantPosts) {
+		t.Fatalf("posts = %q, want %q", target.posts, wantPosts)
+	}
+
+	res, err = m.Apply(context.Background(), snap)
+	if err != nil {
+		t.Fatalf("second Apply() error = %v", err)
+	}
+	if len(res.LanguagesAdded)+len(res.StatusesAdded)+len(res.WebhooksAdded) != 0 || len(target.posts) != 3 {
+		t.Fatalf("second Apply() = %+v, posts = %d; want nothing new", res, len(target.posts))
+	}
+}
+
+func TestManager_Plan(t *testing.T) {
+	t.Parallel()
+
+	target := &fakeProject{
+		tb:        t,
+		project:   `{"project_id":"proj","settings":{"branching":false}}`,
+		languages: []string{`{"lang_iso":"en"}`},
+		statuses:  []string{`{"title":"Legal"}`},
+	}
+	srv := httptest.NewServer(target)
+	defer srv.Close()
+
+	snap := snapshot.Snapshot{
+		Version:        snapshot.Version,
+		Settings:       map[string]any{"branching": true},
+		Languages:      []snapshot.Language{{LangISO: "en"}, {LangISO: "fr"}},
+		CustomStatuses: []snapshot.CustomStatus{{Title: "Legal"}, {Title: "Done"}},
+		Webhooks:       []snapshot.Webhook{{URL: "https://hooks.example.com/l"}},
+	}
+	res, err := snapshot.NewManager(newTestClient(t, srv, "proj")).Plan(context.Background(), snap)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	want := snapshot.ApplyResult{
+		LanguagesAdded:      []string{"fr"},
+		StatusesAdded:       []string{"Done"},
+		WebhooksAdded:       []string{"https://hooks.example.com/l"},
+		SettingsDifferences: []string{"branching"},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("Plan() = %+v, want %+v", res, want)
+	}
+	if len(target.posts) != 0 {
+		t.Fatalf("Plan() sent %q, want nothing", target.posts)
+	}
+}
+
+func TestManager_Export_WalksPages(t *testing.T) {
+	restore := snapshot.ExportSetListPageLimitForTest(1)
+	defer restore()
+
+	srv := httptest.NewServer(&fakeProject{
+		tb:        t,
+		project:   `{"name":"P"}`,
+		languages: []string{`{"lang_iso":"en"}`, `{"lang_iso":"de"}`, `{"lang_iso":"fr"}`},
+	})
+	defer srv.Close()
+
+	snap, err := snapshot.NewManager(newTestClient(t, srv, "proj")).Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(snap.Languages) != 3 || snap.Languages[2].LangISO != "fr" {
+		t.Fatalf("Languages = %+v, want 3 across pages", snap.Languages)
+	}
+	if snap.Webhooks != nil || snap.CustomStatuses != nil {
+		t.Fatalf("empty resources = %v / %v, want nil", snap.Webhooks, snap.CustomStatuses)
+	}
+}
+
+func TestManager_Export_WithoutCustomStatuses(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(&fakeProject{tb: t, project: `{"name":"P"}`, languages: []string{`{"lang_iso":"en"}`}, noStatuses: true})
+	defer srv.Close()
+
+	snap, err := snapshot.NewManager(newTestClient(t, srv, "proj")).Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export() error = %v, want custom statuses treated as optional", err)
+	}
+	if len(snap.Languages) != 1 || snap.CustomStatuses != nil {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+}
+
+func TestManager_Apply_LanguagesFailKeepsResult(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(&fakeProject{
+		tb:            t,
+		project:       `{"settings":{"branching":false}}`,
+		failLanguages: true,
+	})
+	defer srv.Close()
+
+	res, err := snapshot.NewManager(newTestClient(t, srv, "proj")).Apply(context.Background(), snapshot.Snapshot{
+		Version:   snapshot.Version,
+		Settings:  map[string]any{"branching": true},
+		Languages: []snapshot.Language{{LangISO: "fr"}},
+	})
+	if err == nil {
+		t.Fatal("Apply() error = nil, want the languages failure")
+	}
+	if len(res.LanguagesAdded) != 0 || !reflect.DeepEqual(res.SettingsDifferences, []string{"branching"}) {
+		t.Fatalf("Apply() = %+v, want the settings difference and no languages", res)
+	}
+}
+
+func TestManager_Errors(t *testing.T) {
+	t.Parallel()
+
+	var m *snapshot.Manager
+	if _, err := m.Export(context.Background()); err == nil || err.Error() != "snapshot: manager/client is nil" {
+		t.Fatalf("Export() on nil manager error = %v", err)
+	}
+	if _, err := m.Apply(context.Background(), snapshot.Snapshot{Version: snapshot.Version}); err == nil {
+		t.Fatal("Apply() on nil manager error = nil")
+	}
+
+	c, err := client.NewClient("tok", "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := snapshot.NewManager(c).Apply(context.Background(), snapshot.Snapshot{Version: 99}); err == nil ||
+		!strings.Contains(err.Error(), "unsupported version 99") {
+		t.Fatalf("Apply(v99) error = %v", err)
+	}
+	if _, err := snapshot.Read(strings.NewReader(`{"version":2}`)); err == nil {
+		t.Fatal("Read(v2) error = nil")
+	}
+	if _, err := snapshot.Read(strings.NewReader(`{`)); err == nil {
+		t.Fatal("Read(bad json) error = nil")
+	}
+}
+
+func TestNewManager_NilClientPanics(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewManager(nil) did not panic")
+		}
+	}()
+	_ = snapshot.NewManager(nil)
+}
@@ -0,0 +1,222 @@
+// Package spreadsheet turns a project's keys and translations into a table
+// for people who review translations in spreadsheets, and writes it as CSV
+// or XLSX.
+//
+// One row is written per key: the selected key columns (name, description,
+// tags, ...) followed by one column per language.
+package spreadsheet
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/keys"
+	"github.com/bodrovis/lokex/v2/internal/xlsx"
+)
+
+// Manager wraps a *Client to build translation spreadsheets.
+// Construct with NewManager; the embedded client must be non-nil.
+type Manager struct {
+	client *client.Client
+}
+
+// NewManager creates a new Manager bound to c.
+func NewManager(c *client.Client) *Manager {
+	if c == nil {
+		panic("lokex/spreadsheet: nil client passed to NewManager")
+	}
+	return &Manager{
+		client: c,
+	}
+}
+
+const managerIsNilMsg = "spreadsheet: manager/client is nil"
+
+// Column is a per-key column placed before the translation columns.
+type Column string
+
+const (
+	ColumnKeyID       Column = "key_id"
+	ColumnKeyName     Column = "key_name"
+	ColumnDescription Column = "description"
+	ColumnTags        Column = "tags"      // comma-separated
+	ColumnPlatforms   Column = "platforms" // comma-separated
+	ColumnFilename    Column = "filename"
+)
+
+var knownColumns = []Column{ColumnKeyID, ColumnKeyName, ColumnDescription, ColumnTags, ColumnPlatforms, ColumnFilename}
+
+// DefaultColumns are used when Options.Columns is empty.
+var DefaultColumns = []Column{ColumnKeyName, ColumnDescription}
+
+// Options configures Build and FromKeys.
+type Options struct {
+	// Columns are the key columns, in order (default: DefaultColumns).
+	Columns []Column
+	// Languages are the translation columns, in order, by language ISO code.
+	// Default: every language found, sorted.
+	Languages []string
+	// Platform picks which per-platform key name and filename are shown
+	// (ios, android, web, other). Default: the first one set.
+	Platform string
+	// Filter narrows the keys listed by Build (e.g. filter_tags).
+	Filter keys.ListParams
+}
+
+// Table is a header row plus one row per key.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Build lists the project's keys with translations and tabulates them.
+func (m *Manager) Build(ctx context.Context, opts Options) (Table, error) {
+	if m == nil || m.client == nil {
+		return Table{}, errors.New(managerIsNilMsg)
+	}
+
+	params := keys.ListParams{}
+	maps.Copy(params, opts.Filter)
+	params["include_translations"] = "1"
+	all, err := keys.NewManager(m.client).List(ctx, params)
+	if err != nil {
+		return Table{}, fmt.Errorf("spreadsheet: %w", err)
+	}
+	return FromKeys(all, opts)
+}
+
+// FromKeys tabulates keys listed with include_translations=1. Keys keep
+// their order; missing translations are empty cells.
+func FromKeys(list []keys.Key, opts Options) (Table, error) {
+	cols := opts.Columns
+	if len(cols) == 0 {
+		cols = DefaultColumns
+	}
+	for _, c := range cols {
+		if !slices.Contains(knownColumns, c) {
+			return Table{}, fmt.Errorf("spreadsheet: unknown column %q", c)
+		}
+	}
+	langs := opts.Languages
+	if len(langs) == 0 {
+		seen := map[string]struct{}{}
+		for _, k := range list {
+			for _, t := range k.Translations {
+				seen[t.LanguageISO] = struct{}{}
+			}
+		}
+		langs = slices.Sorted(maps.Keys(seen))
+	}
+
+	t := Table{Header: make([]string, 0, len(cols)+len(langs))}
+	for _, c := range cols {
+		t.Header = append(t.Header, string(c))
+	}
+	t.Header = append(t.Header, langs...)
+
+	for _, k := range list {
+		row := make([]string, 0, len(t.Header))
+		for _, c := range cols {
+			row = append(row, keyCell(k, c, opts.Platform))
+		}
+		for _, iso := range langs {
+			tr, _ := k.Translation(iso)
+			row = append(row, tr.Translation)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+func keyCell(k keys.Key, c Column, platform string) string {
+	switch c {
+	case ColumnKeyID:
+		return strconv.FormatInt(k.KeyID, 10)
+	case ColumnKeyName:
+		return k.KeyName.For(platform)
+	case ColumnDescription:
+		return k.Description
+	case ColumnTags:
+		return strings.Join(k.Tags, ", ")
+	case ColumnPlatforms:
+		return strings.Join(k.Platforms, ", ")
+	case ColumnFilename:
+		return k.Filenames.For(platform)
+	}
+	return ""
+}
+
+// WriteCSV writes the table as CSV, header first. Cells that a spreadsheet
+// app would run as a formula (starting with =, +, -, @, tab or CR) are
+// prefixed with a single quote; ReadCSV removes it.
+func (t Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(escapeRow(t.Header)); err != nil {
+		return fmt.Errorf("spreadsheet: csv: %w", err)
+	}
+	for _, row := range t.Rows {
+		if err := cw.Write(escapeRow(row)); err != nil {
+			return fmt.Errorf("spreadsheet: csv: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("spreadsheet: csv: %w", err)
+	}
+	return nil
+}
+
+// ReadCSV reads a table written by WriteCSV, e.g. after review, taking the
+// first record as the header and undoing the formula escaping.
+func ReadCSV(r io.Reader) (Table, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	recs, err := cr.ReadAll()
+	if err != nil {
+		return Table{}, fmt.Errorf("spreadsheet: csv: %w", err)
+	}
+	if len(recs) == 0 {
+		return Table{}, errors.New("spreadsheet: csv: no header row")
+	}
+	t := Table{Header: unescapeRow(recs[0])}
+	for _, rec := range recs[1:] {
+		t.Rows = append(t.Rows, unescapeRow(rec))
+	}
+	return t, nil
+}
+
+func escapeRow(row []string) []string {
+	out := make([]string, len(row))
+	for i, cell := range row {
+		out[i] = xlsx.EscapeFormula(cell)
+	}
+	return out
+}
+
+func unescapeRow(row []string) []string {
+	for i, cell := range row {
+		row[i] = xlsx.UnescapeFormula(cell)
+	}
+	return row
+}
+
+// WriteXLSX writes the table as a single-sheet workbook with a bold, frozen
+// header row. Every cell is text, so values such as "00123" are shown as
+// written; cells starting a formula are escaped as in WriteCSV.
+func (t Table) WriteXLSX(w io.Writer, sheet string) error {
+	rows := make([][]string, 0, len(t.Rows)+1)
+	rows = append(rows, t.Header)
+	rows = append(rows, t.Rows...)
+	if err := xlsx.Write(w, sheet, rows, true); err != nil {
+		return fmt.Errorf("spreadsheet: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package spreadsheet_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/keys"
+	"github.com/bodrovis/lokex/v2/client/spreadsheet"
+	"github.com/bodrovis/lokex/v2/testutils"
+)
+
+var sample = []keys.Key{
+	{
+		KeyID:       1,
+		KeyName:     keys.PlatformStrings{IOS: "home.title", Android: "home_title"},
+		Filenames:   keys.PlatformStrings{IOS: "Localizable.strings", Android: "strings.xml"},
+		Description: "Main screen title",
+		Platforms:   []string{"ios", "android"},
+		Tags:        []string{"home", "v2"},
+		Translations: []keys.Translation{
+			{LanguageISO: "fr", Translation: "Accueil"},
+			{LanguageISO: "en", Translation: "Home"},
+		},
+	},
+	{
+		KeyID:        2,
+		KeyName:      keys.PlatformStrings{IOS: "bye", Android: "bye", Web: "bye", Other: "bye"},
+		Translations: []keys.Translation{{LanguageISO: "en", Translation: "=Bye, \"friend\""}},
+	},
+}
+
+func TestFromKeys_Defaults(t *testing.T) {
+	t.Parallel()
+
+	tbl, err := spreadsheet.FromKeys(sample, spreadsheet.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"key_name", "description", "en", "fr"}; !slices.Equal(tbl.Header, want) {
+		t.Fatalf("Header = %v, want %v", tbl.Header, want)
+	}
+	want := [][]string{
+		{"home.title", "Main screen title", "Home", "Accueil"},
+		{"bye", "", "=Bye, \"friend\"", ""},
+	}
+	for i := range want {
+		if !slices.Equal(tbl.Rows[i], want[i]) {
+			t.Fatalf("Rows[%d] = %q, want %q", i, tbl.Rows[i], want[i])
+		}
+	}
+}
+
+func TestFromKeys_ColumnsLanguagesPlatform(t *testing.T) {
+	t.Parallel()
+
+	tbl, err := spreadsheet.FromKeys(sample, spreadsheet.Options{
+		Columns:   []spreadsheet.Column{spreadsheet.ColumnKeyID, spreadsheet.ColumnKeyName, spreadsheet.ColumnFilename, spreadsheet.ColumnTags, spreadsheet.ColumnPlatforms},
+		Languages: []string{"fr", "de"},
+		Platform:  "android",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"key_id", "key_name", "filename", "tags", "platforms", "fr", "de"}; !slices.Equal(tbl.Header, want) {
+		t.Fatalf("Header = %v", tbl.Header)
+	}
+	if want := []string{"1", "home_title", "strings.xml", "home, v2", "ios, android", "Accueil", ""}; !slices.Equal(tbl.Rows[0], want) {
+		t.Fatalf("Rows[0] = %q, want %q", tbl.Rows[0], want)
+	}
+
+	if _, err := spreadsheet.FromKeys(sample, spreadsheet.Options{Columns: []spreadsheet.Column{"nope"}}); err == nil {
+		t.Fatal("unknown column error = nil")
+	}
+}
+
+func TestTable_WriteCSV(t *testing.T) {
+	t.Parallel()
+
+	tbl, err := spreadsheet.FromKeys(sample, spreadsheet.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tbl.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"bye", "", "'=Bye, \"friend\"", ""}; len(recs) != 3 || !slices.Equal(recs[0], tbl.Header) || !slices.Equal(recs[2], want) {
+		t.Fatalf("csv = %q, want the formula escaped", recs)
+	}
+}
+
+func TestTable_CSVRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tbl := spreadsheet.Table{
+		Header: []string{"key_name", "en"},
+		Rows: [][]string{
+			{"formula", "=HYPERLINK(\"http://x\",\"y\")"},
+			{"plus", "+1 555"},
+			{"minus", "-5"},
+			{"at", "@SUM(A1)"},
+			{"tab", "\tindented"},
+			{"cr", "\rline"},
+			{"quoted", "'=already quoted"},
+			{"apostrophe", "'tis"},
+			{"plain", "Hello"},
+			{"empty", ""},
+		},
+	}
+	var buf bytes.Buffer
+	if err := tbl.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range raw[1:] {
+		if v := rec[1]; v != "" && strings.ContainsAny(v[:1], "=+-@\t\r") {
+			t.Fatalf("cell %q starts a formula", v)
+		}
+	}
+
+	got, err := spreadsheet.ReadCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Header, tbl.Header) || len(got.Rows) != len(tbl.Rows) {
+		t.Fatalf("ReadCSV = %+v", got)
+	}
+	for i := range tbl.Rows {
+		if !slices.Equal(got.Rows[i], tbl.Rows[i]) {
+			t.Fatalf("row %d = %q, want %q", i, got.Rows[i], tbl.Rows[i])
+		}
+	}
+
+	if _, err := spreadsheet.ReadCSV(strings.NewReader("")); err == nil {
+		t.Fatal("ReadCSV(empty) error = nil")
+	}
+}
+
+func TestTable_WriteCSV_Golden(t *testing.T) {
+	t.Parallel()
+
+	tbl, err := spreadsheet.FromKeys(sample, spreadsheet.Options{
+		Columns:  []spreadsheet.Column{spreadsheet.ColumnKeyID, spreadsheet.ColumnKeyName, spreadsheet.ColumnTags},
+		Platform: "android",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tbl.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	testutils.Golden(t, "table.csv", buf.Bytes())
+}
+
+func TestTable_WriteXLSX(t *testing.T) {
+	t.Parallel()
+
+	tbl, err := spreadsheet.FromKeys(sample, spreadsheet.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tbl.WriteXLSX(&buf, "Translations"); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	sheet, _ := io.ReadAll(f)
+	testutils.Golden(t, "sheet1.xml", sheet)
+	for _, want := range []string{`<c r="A1" t="inlineStr" s="1">`, "Accueil", "&#39;=Bye, &#34;friend&#34;", `<row r="3">`} {
+		if !strings.Contains(string(sheet), want) {
+			t.Fatalf("sheet1.xml lacks %q:\n%s", want, sheet)
+		}
+	}
+}
+
+func TestManager_Build(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/projects/proj/keys" || q.Get("include_translations") != "1" || q.Get("filter_tags") != "home" {
+			http.Error(w, "unexpected request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"keys":[{"key_id":1,"key_name":"home.title","translations":[
+			{"translation_id":5,"language_iso":"en","translation":"Home"},
+			{"translation_id":6,"language_iso":"de","translation":"Start"}]}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj", client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := keys.ListParams{"filter_tags": "home"}
+	tbl, err := spreadsheet.NewManager(c).Build(context.Background(), spreadsheet.Options{Filter: filter})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if want := []string{"home.title", "", "Start", "Home"}; len(tbl.Rows) != 1 || !slices.Equal(tbl.Rows[0], want) {
+		t.Fatalf("Rows = %q, want [%q]", tbl.Rows, want)
+	}
+	if _, ok := filter["include_translations"]; ok {
+		t.Fatal("Build mutated opts.Filter")
+	}
+
+	var nilM *spreadsheet.Manager
+	if _, err := nilM.Build(context.Background(), spreadsheet.Options{}); err == nil {
+		t.Fatal("nil manager error = nil")
+	}
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// StateStore persists the small pieces of state lokex keeps between runs
+// (pull manifests, content hashes, resume data) under namespaced keys.
+// Back it with Redis or a database to share that state between runners;
+// MemoryStateStore and FileStateStore cover single processes and machines.
+// Implementations must be safe for concurrent use.
+type StateStore interface {
+	// Get returns the value of key in namespace; ok is false if there is
+	// none.
+	Get(ctx context.Context, namespace, key string) (value []byte, ok bool, err error)
+	// Set stores value, replacing any previous one.
+	Set(ctx context.Context, namespace, key string, value []byte) error
+	// Delete removes key; deleting a missing key is not an error.
+	Delete(ctx context.Context, namespace, key string) error
+}
+
+// MemoryStateStore is a StateStore held in memory. The zero value is ready
+// to use.
+type MemoryStateStore struct {
+	mu sync.Mutex
+	ns map[string]map[string][]byte
+}
+
+// NewMemoryStateStore returns an empty MemoryStateStore.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{}
+}
+
+// Get returns a copy of the stored value.
+func (s *MemoryStateStore) Get(_ context.Context, namespace, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.ns[namespace][key]
+	return slices.Clone(v), ok, nil
+}
+
+// Set stores a copy of value.
+func (s *MemoryStateStore) Set(_ context.Context, namespace, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ns == nil {
+		s.ns = make(map[string]map[string][]byte)
+	}
+	if s.ns[namespace] == nil {
+		s.ns[namespace] = make(map[string][]byte)
+	}
+	s.ns[namespace][key] = slices.Clone(value)
+	return nil
+}
+
+// Delete removes key.
+func (s *MemoryStateStore) Delete(_ context.Context, namespace, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ns[namespace], key)
+	return nil
+}
+
+// FileStateStore is a StateStore keeping one file per key under
+// <dir>/<namespace>/. Writes are atomic (temp file + rename), so readers
+// never see a partial value. Namespaces must be plain names (letters,
+// digits, '-', '_' and '.'); keys may be any string.
+type FileStateStore struct {
+	dir string
+}
+
+// NewFileStateStore returns a FileStateStore rooted at dir, creating it if
+// needed.
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, errors.New("state: empty directory")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("state: %w", err)
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+// Get reads the value of key.
+func (s *FileStateStore) Get(_ context.Context, namespace, key string) ([]byte, bool, error) {
+	path, err := s.path(namespace, key)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("state: get %s/%s: %w", namespace, key, err)
+	}
+	return data, true, nil
+}
+
+// Set writes value atomically.
+func (s *FileStateStore) Set(_ context.Context, namespace, key string, value []byte) error {
+	path, err := s.path(namespace, key)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomically(path, value); err != nil {
+		return fmt.Errorf("state: set %s/%s: %w", namespace, key, err)
+	}
+	return nil
+}
+
+// Delete removes the file of key.
+func (s *FileStateStore) Delete(_ context.Context, namespace, key string) error {
+	path, err := s.path(namespace, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("state: delete %s/%s: %w", namespace, key, err)
+	}
+	return nil
+}
+
+// path maps a key to its file. Keys are hashed, so any string is a safe,
+// fixed-length file name on every OS.
+func (s *FileStateStore) path(namespace, key string) (string, error) {
+	if !validNamespace(namespace) {
+		return "", fmt.Errorf("state: invalid namespace %q", namespace)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, namespace, hex.EncodeToString(sum[:])), nil
+}
+
+func validNamespace(ns string) bool {
+	if ns == "" || ns == "." || ns == ".." {
+		return false
+	}
+	for _, r := range ns {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func writeFileAtomically(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,115 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// testStateStore checks the StateStore contract shared by every
+// implementation.
+func testStateStore(t *testing.T, s client.StateStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, ok, err := s.Get(ctx, "manifest", "p:locales"); err != nil || ok {
+		t.Fatalf("Get(missing) = ok %v, err %v; want false, nil", ok, err)
+	}
+
+	value := []byte(`{"v":1}`)
+	if err := s.Set(ctx, "manifest", "p:locales", value); err != nil {
+		t.Fatal(err)
+	}
+	value[0] = 'X' // the store must not alias the caller's slice
+	got, ok, err := s.Get(ctx, "manifest", "p:locales")
+	if err != nil || !ok || string(got) != `{"v":1}` {
+		t.Fatalf("Get() = %q, %v, %v; want {\"v\":1}, true, nil", got, ok, err)
+	}
+
+	// Namespaces are separate.
+	if _, ok, _ := s.Get(ctx, "hashes", "p:locales"); ok {
+		t.Fatal("Get(other namespace) found the value")
+	}
+
+	if err := s.Set(ctx, "manifest", "p:locales", []byte("v2")); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := s.Get(ctx, "manifest", "p:locales"); !bytes.Equal(got, []byte("v2")) {
+		t.Fatalf("Get() after overwrite = %q, want v2", got)
+	}
+
+	if err := s.Delete(ctx, "manifest", "p:locales"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.Get(ctx, "manifest", "p:locales"); ok {
+		t.Fatal("Get() after Delete found the value")
+	}
+	if err := s.Delete(ctx, "manifest", "p:locales"); err != nil {
+		t.Fatalf("Delete(missing) = %v, want nil", err)
+	}
+}
+
+func TestMemoryStateStore(t *testing.T) {
+	t.Parallel()
+	testStateStore(t, client.NewMemoryStateStore())
+	testStateStore(t, &client.MemoryStateStore{})
+}
+
+func TestFileStateStore(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "state")
+	s, err := client.NewFileStateStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStateStore(t, s)
+
+	// Values survive a new store over the same directory.
+	ctx := context.Background()
+	if err := s.Set(ctx, "manifest", "../../etc/passwd", []byte("kept")); err != nil {
+		t.Fatal(err)
+	}
+	s2, err := client.NewFileStateStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok, err := s2.Get(ctx, "manifest", "../../etc/passwd"); err != nil || !ok || string(got) != "kept" {
+		t.Fatalf("Get() from reopened store = %q, %v, %v", got, ok, err)
+	}
+
+	// Keys never escape the namespace directory.
+	entries, err := os.ReadDir(filepath.Join(dir, "manifest"))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("namespace dir entries = %v, err %v; want one file", entries, err)
+	}
+}
+
+func TestFileStateStore_InvalidNamespace(t *testing.T) {
+	t.Parallel()
+
+	s, err := client.NewFileStateStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ns := range []string{"", ".", "..", "a/b", `a\b`, "a b"} {
+		if err := s.Set(context.Background(), ns, "k", nil); err == nil {
+			t.Errorf("Set(namespace %q) error = nil", ns)
+		}
+		if _, _, err := s.Get(context.Background(), ns, "k"); err == nil {
+			t.Errorf("Get(namespace %q) error = nil", ns)
+		}
+	}
+}
+
+func TestNewFileStateStore_EmptyDir(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewFileStateStore("  "); err == nil {
+		t.Fatal("NewFileStateStore(\"\") error = nil")
+	}
+}
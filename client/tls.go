@@ -0,0 +1,174 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// tlsSettings collects WithMinTLSVersion / WithPinnedCertificates and is
+// applied to the client's HTTP transports once all options have run.
+type tlsSettings struct {
+	minVersion uint16
+	pins       map[string][][sha256.Size]byte // host pattern -> SPKI hashes
+}
+
+// WithMinTLSVersion rejects connections negotiated below v
+// (e.g. tls.VersionTLS13) for both API calls and bundle downloads.
+//
+// TLS options can only configure an *http.Transport (or the default one).
+// If WithHTTPClient or WithBundleHTTPClient set a wrapping RoundTripper
+// (tracing, logging, ...), NewClient fails: configure TLS on the inner
+// *http.Transport yourself before wrapping it instead.
+func WithMinTLSVersion(v uint16) Option {
+	return func(c *Client) error {
+		if v < tls.VersionTLS12 || v > tls.VersionTLS13 {
+			return fmt.Errorf("min TLS version %#x: must be TLS 1.2 or 1.3", v)
+		}
+		c.tlsSettings().minVersion = v
+		return nil
+	}
+}
+
+// WithPinnedCertificates pins host to the given public keys. Each pin is the
+// base64 SHA-256 of a certificate's SubjectPublicKeyInfo, optionally prefixed
+// with "sha256/" (the HPKP / curl --pinnedpubkey format). A connection to host
+// succeeds only if the verified chain contains a pinned key. host may be a
+// wildcard like "*.lokalise.com"; call once per host to pin the API host and
+// the CDN host separately. Hosts without pins use normal verification only.
+// Hosts are matched by TLS server name, so IP-literal URLs cannot be pinned.
+// Like WithMinTLSVersion, it needs the clients' transports to be
+// *http.Transport.
+func WithPinnedCertificates(host string, pins ...string) Option {
+	return func(c *Client) error {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			return errors.New("pinned certificates: empty host")
+		}
+		if len(pins) == 0 {
+			return fmt.Errorf("pinned certificates for %s: no pins", host)
+		}
+
+		hashes := make([][sha256.Size]byte, 0, len(pins))
+		for _, p := range pins {
+			raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(p), "sha256/"))
+			if err != nil || len(raw) != sha256.Size {
+				return fmt.Errorf("pinned certificates for %s: invalid pin %q", host, p)
+			}
+			hashes = append(hashes, [sha256.Size]byte(raw))
+		}
+
+		s := c.tlsSettings()
+		if s.pins == nil {
+			s.pins = make(map[string][][sha256.Size]byte)
+		}
+		s.pins[host] = append(s.pins[host], hashes...)
+		return nil
+	}
+}
+
+// PinForCertificate returns the pin string for cert's public key in the
+// format accepted by WithPinnedCertificates.
+func PinForCertificate(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (c *Client) tlsSettings() *tlsSettings {
+	if c.tls == nil {
+		c.tls = &tlsSettings{}
+	}
+	return c.tls
+}
+
+// withTLS returns a copy of hc whose transport enforces s. hc itself and its
+// transport are not modified. Only *http.Transport (or a nil transport,
+// meaning http.DefaultTransport) can be configured.
+func (s *tlsSettings) withTLS(hc *http.Client) (*http.Client, error) {
+	if s == nil || hc == nil {
+		return hc, nil
+	}
+
+	var tr *http.Transport
+	switch t := hc.Transport.(type) {
+	case nil:
+		tr = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		tr = t.Clone()
+	default:
+		return nil, fmt.Errorf("TLS settings: cannot configure transport of type %T; "+
+			"set TLSClientConfig on the *http.Transport it wraps instead", hc.Transport)
+	}
+
+	cfg := tr.TLSClientConfig
+	if cfg == nil {
+		cfg = &tls.Config{}
+	}
+	if cfg.InsecureSkipVerify && len(s.pins) > 0 {
+		return nil, errors.New("TLS settings: certificate pinning requires verification (InsecureSkipVerify is set)")
+	}
+	if s.minVersion > cfg.MinVersion {
+		cfg.MinVersion = s.minVersion
+	}
+	if len(s.pins) > 0 {
+		prev := cfg.VerifyConnection
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if prev != nil {
+				if err := prev(cs); err != nil {
+					return err
+				}
+			}
+			return s.verifyPins(cs)
+		}
+	}
+	tr.TLSClientConfig = cfg
+
+	out := *hc
+	out.Transport = tr
+	return &out, nil
+}
+
+func (s *tlsSettings) verifyPins(cs tls.ConnectionState) error {
+	want := s.pinsFor(cs.ServerName)
+	if want == nil {
+		return nil
+	}
+
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			for _, w := range want {
+				if sum == w {
+					return nil
+				}
+			}
+		}
+	}
+	return fmt.Errorf("TLS: no pinned public key matched for %s", cs.ServerName)
+}
+
+// pinsFor returns the pins for host: an exact entry wins over wildcards, and
+// the most specific matching wildcard wins over broader ones.
+func (s *tlsSettings) pinsFor(host string) [][sha256.Size]byte {
+	host = strings.ToLower(host)
+	if p, ok := s.pins[host]; ok {
+		return p
+	}
+
+	var best string
+	for pattern := range s.pins {
+		suffix, ok := strings.CutPrefix(pattern, "*")
+		if ok && strings.HasSuffix(host, suffix) && len(pattern) > len(best) {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	return s.pins[best]
+}
@@ -0,0 +1,179 @@
+package client_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func newTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// exampleComClient returns an HTTP client trusting srv that sends every
+// connection to srv, so requests can use https://example.com (a name the
+// test certificate covers; pins are matched by SNI host name).
+func exampleComClient(srv *httptest.Server) *http.Client {
+	tr := srv.Client().Transport.(*http.Transport).Clone()
+	addr := srv.Listener.Addr().String()
+	tr.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+	return &http.Client{Transport: tr}
+}
+
+func TestWithPinnedCertificates(t *testing.T) {
+	t.Parallel()
+
+	srv := newTLSServer(t)
+	good := client.PinForCertificate(srv.Certificate())
+	other := "sha256/" + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name    string
+		host    string
+		pins    []string
+		wantErr bool
+	}{
+		{name: "matching pin", host: "example.com", pins: []string{other, good}},
+		{name: "raw base64 pin", host: "EXAMPLE.com", pins: []string{strings.TrimPrefix(good, "sha256/")}},
+		{name: "wrong pin", host: "example.com", pins: []string{other}, wantErr: true},
+		{name: "pins for another host", host: "api.lokalise.com", pins: []string{other}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := client.NewClient("tok", "proj",
+				client.WithBaseURL("https://example.com/"),
+				client.WithHTTPClient(exampleComClient(srv)),
+				client.WithMaxRetries(0),
+				client.WithPinnedCertificates(tt.host, tt.pins...),
+			)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			err = c.DoJSONWithRetry(context.Background(), http.MethodGet, "ping", nil, nil)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "no pinned public key matched for example.com") {
+					t.Fatalf("error = %v, want pin mismatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+}
+
+func TestWithPinnedCertificates_WildcardAndOrder(t *testing.T) {
+	t.Parallel()
+
+	srv := newTLSServer(t)
+	other := "sha256/" + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	// Pins are applied after all options, so a later WithHTTPClient keeps them.
+	c, err := client.NewClient("tok", "proj",
+		client.WithPinnedCertificates("*.com", other),
+		client.WithBaseURL("https://example.com/"),
+		client.WithHTTPClient(exampleComClient(srv)),
+		client.WithMaxRetries(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "ping", nil, nil); err == nil {
+		t.Fatal("wildcard pin mismatch: error = nil")
+	}
+
+	if tr := srv.Client().Transport.(*http.Transport); tr.TLSClientConfig.VerifyConnection != nil {
+		t.Fatal("caller's transport was modified")
+	}
+}
+
+func TestWithMinTLSVersion(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithHTTPClient(srv.Client()),
+		client.WithMaxRetries(0),
+		client.WithMinTLSVersion(tls.VersionTLS13),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "ping", nil, nil); err == nil {
+		t.Fatal("TLS 1.2-only server: error = nil, want handshake failure")
+	}
+
+	tr, ok := c.HTTPClient.Transport.(*http.Transport)
+	if !ok || tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("transport MinVersion not set: %#v", c.HTTPClient.Transport)
+	}
+}
+
+func TestWithMinTLSVersion_AppliesToBundleClient(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithBundleTimeout(0),
+		client.WithMinTLSVersion(tls.VersionTLS13),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, ok := c.BundleHTTPClient().Transport.(*http.Transport)
+	if !ok || tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("bundle transport MinVersion not set: %#v", c.BundleHTTPClient().Transport)
+	}
+}
+
+func TestTLSOptions_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]client.Option{
+		"old version": {client.WithMinTLSVersion(tls.VersionTLS10)},
+		"empty host":  {client.WithPinnedCertificates(" ", "x")},
+		"no pins":     {client.WithPinnedCertificates("api.lokalise.com")},
+		"bad pin":     {client.WithPinnedCertificates("api.lokalise.com", "sha256/not-base64!")},
+		"short pin":   {client.WithPinnedCertificates("api.lokalise.com", "sha256/AAAA")},
+		"custom rt":   {client.WithHTTPClient(&http.Client{Transport: roundTripperFunc(nil)}), client.WithMinTLSVersion(tls.VersionTLS12)},
+		"bundle rt":   {client.WithBundleHTTPClient(&http.Client{Transport: roundTripperFunc(nil)}), client.WithMinTLSVersion(tls.VersionTLS12)},
+		"skip verify": {client.WithHTTPClient(&http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}), client.WithPinnedCertificates("h", "sha256/"+base64.StdEncoding.EncodeToString(make([]byte, 32)))},
+	}
+	for name, opts := range tests {
+		if _, err := client.NewClient("tok", "proj", opts...); err == nil {
+			t.Errorf("%s: NewClient() error = nil", name)
+		}
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/bodrovis/lokex/v2/internal/telemetry"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// WithTracerProvider turns on OpenTelemetry tracing. lokex then starts spans
+// (instrumentation scope "github.com/bodrovis/lokex/v2") for:
+//
+//   - lokex.request: every API request, with method, URL path and status code
+//   - lokex.attempt: every retry attempt, with its label, number and delay
+//   - lokex.poll: PollProcesses, with the process count and rounds polled
+//   - lokex.download: DownloadAndUnzip, with the bundle size
+//   - lokex.upload: Upload, with the filename and process ID
+//
+// Spans are children of the span in the caller's context. Errors are
+// recorded on the span that failed; they are redacted like returned errors.
+// tp must be non-nil.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) error {
+		if tp == nil {
+			return errors.New("tracer provider cannot be nil")
+		}
+		c.tracer = telemetry.Tracer(tp)
+		return nil
+	}
+}
+
+// Tracer returns the tracer set up by WithTracerProvider, or a no-op tracer.
+// Packages built on the client use it for their own spans.
+func (c *Client) Tracer() trace.Tracer {
+	if c == nil || c.tracer == nil {
+		return noop.NewTracerProvider().Tracer(telemetry.ScopeName)
+	}
+	return c.tracer
+}
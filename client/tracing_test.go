@@ -0,0 +1,197 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTracedClient(t *testing.T, url string, opts ...client.Option) (*client.Client, *tracetest.SpanRecorder) {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	opts = append([]client.Option{
+		client.WithBaseURL(url),
+		client.WithTracerProvider(tp),
+		client.WithBackoff(time.Millisecond, time.Millisecond),
+	}, opts...)
+	c, err := client.NewClient("test-token", "proj", opts...)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c, rec
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range s.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func spansNamed(rec *tracetest.SpanRecorder, name string) []sdktrace.ReadOnlySpan {
+	var out []sdktrace.ReadOnlySpan
+	for _, s := range rec.Ended() {
+		if s.Name() == name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestWithTracerProvider_Nil(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("tok", "proj", client.WithTracerProvider(nil)); err == nil {
+		t.Fatal("NewClient() error = nil, want error for nil provider")
+	}
+}
+
+func TestClient_Tracer_NoopByDefault(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok", "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := c.Tracer().Start(context.Background(), "x")
+	defer span.End()
+	if span.IsRecording() {
+		t.Fatal("default tracer records spans, want no-op")
+	}
+}
+
+func TestTracing_RequestAndAttemptSpans(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"message":"busy","code":503}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, rec := newTracedClient(t, srv.URL+"/api2/", client.WithMaxRetries(2))
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects/proj/keys?page=2", nil, nil); err != nil {
+		t.Fatalf("DoJSONWithRetry() error = %v", err)
+	}
+
+	attempts := spansNamed(rec, "lokex.attempt")
+	requests := spansNamed(rec, "lokex.request")
+	if len(attempts) != 2 || len(requests) != 2 {
+		t.Fatalf("spans: %d attempts, %d requests; want 2 and 2", len(attempts), len(requests))
+	}
+
+	for i, req := range requests {
+		if req.Parent().SpanID() != attempts[i].SpanContext().SpanID() {
+			t.Errorf("request %d is not a child of attempt %d", i, i)
+		}
+		if v, _ := spanAttr(req, "url.path"); v.AsString() != "/api2/projects/proj/keys" {
+			t.Errorf("url.path = %q", v.AsString())
+		}
+		if v, _ := spanAttr(req, "http.request.method"); v.AsString() != http.MethodGet {
+			t.Errorf("http.request.method = %q", v.AsString())
+		}
+		if v, _ := spanAttr(attempts[i], "lokex.retry.attempt"); v.AsInt64() != int64(i) {
+			t.Errorf("attempt %d: lokex.retry.attempt = %d", i, v.AsInt64())
+		}
+	}
+
+	if v, _ := spanAttr(requests[0], "http.response.status_code"); v.AsInt64() != 503 {
+		t.Errorf("first status = %d, want 503", v.AsInt64())
+	}
+	if requests[0].Status().Code != codes.Error || attempts[0].Status().Code != codes.Error {
+		t.Errorf("failed attempt not marked as error: %v / %v", requests[0].Status(), attempts[0].Status())
+	}
+	if _, ok := spanAttr(attempts[0], "lokex.retry.delay_ms"); !ok {
+		t.Error("retried attempt lacks lokex.retry.delay_ms")
+	}
+	if v, _ := spanAttr(requests[1], "http.response.status_code"); v.AsInt64() != 200 {
+		t.Errorf("second status = %d, want 200", v.AsInt64())
+	}
+	if requests[1].Status().Code == codes.Error {
+		t.Errorf("successful request marked as error")
+	}
+}
+
+func TestTracing_ErrorsAreRedacted(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"bad token test-token","code":400}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, rec := newTracedClient(t, srv.URL)
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects", nil, nil); err == nil {
+		t.Fatal("DoJSONWithRetry() error = nil, want 400")
+	}
+	for _, s := range rec.Ended() {
+		if strings.Contains(s.Status().Description, "test-token") {
+			t.Fatalf("%s status leaks the token: %q", s.Name(), s.Status().Description)
+		}
+		for _, ev := range s.Events() {
+			for _, kv := range ev.Attributes {
+				if strings.Contains(kv.Value.Emit(), "test-token") {
+					t.Fatalf("%s event leaks the token: %q", s.Name(), kv.Value.Emit())
+				}
+			}
+		}
+	}
+}
+
+func TestTracing_PollSpan(t *testing.T) {
+	t.Parallel()
+
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "running"
+		if polls.Add(1) > 1 {
+			status = "finished"
+		}
+		_, _ = w.Write([]byte(`{"process":{"process_id":"p1","status":"` + status + `"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, rec := newTracedClient(t, srv.URL, client.WithPollWait(time.Millisecond, 5*time.Second))
+	if _, err := c.WaitAll(context.Background(), []string{"p1"}); err != nil {
+		t.Fatalf("WaitAll() error = %v", err)
+	}
+
+	spans := spansNamed(rec, "lokex.poll")
+	if len(spans) != 1 {
+		t.Fatalf("poll spans = %d, want 1", len(spans))
+	}
+	poll := spans[0]
+	if v, _ := spanAttr(poll, "lokex.poll.rounds"); v.AsInt64() != 2 {
+		t.Errorf("lokex.poll.rounds = %d, want 2", v.AsInt64())
+	}
+	if v, _ := spanAttr(poll, "lokex.process.count"); v.AsInt64() != 1 {
+		t.Errorf("lokex.process.count = %d, want 1", v.AsInt64())
+	}
+	for _, req := range spansNamed(rec, "lokex.request") {
+		if req.Parent().SpanID() != poll.SpanContext().SpanID() {
+			t.Errorf("poll request is not a child of the poll span")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// WithTransport tunes the *http.Transport of API calls and bundle downloads,
+// e.g. connection pooling for tools that poll many processes at once:
+//
+//	client.WithTransport(func(t *http.Transport) {
+//		t.MaxIdleConnsPerHost = 32 // default 2: extra connections get closed
+//		t.IdleConnTimeout = 90 * time.Second
+//		t.ForceAttemptHTTP2 = true
+//	})
+//
+// fn gets a clone of the configured transport (http.DefaultTransport if none
+// was set), so transports shared with other code are never modified. It
+// runs once all options are applied, before WithMinTLSVersion and
+// WithPinnedCertificates; calls accumulate and run in order. A client
+// whose transport isn't an *http.Transport fails to build.
+func WithTransport(fn func(*http.Transport)) Option {
+	return func(c *Client) error {
+		if fn == nil {
+			return errors.New("transport tuning func cannot be nil")
+		}
+		c.tuneTransport = append(c.tuneTransport, fn)
+		return nil
+	}
+}
+
+// applyTransportTuning replaces the transports of HTTPClient and
+// BundleClient with tuned clones. A bundle client sharing HTTPClient's
+// transport keeps sharing the tuned one, and with it the connection pool.
+func (c *Client) applyTransportTuning() error {
+	if len(c.tuneTransport) == 0 {
+		return nil
+	}
+	tuned := make(map[*http.Transport]*http.Transport) // original (nil: default) -> tuned clone
+	tune := func(hc *http.Client) (*http.Client, error) {
+		if hc == nil {
+			return nil, nil
+		}
+		var orig *http.Transport
+		switch t := hc.Transport.(type) {
+		case nil:
+		case *http.Transport:
+			orig = t
+		default:
+			return nil, fmt.Errorf("transport tuning: cannot configure transport of type %T", hc.Transport)
+		}
+
+		tr, ok := tuned[orig]
+		if !ok {
+			if orig == nil {
+				tr = http.DefaultTransport.(*http.Transport).Clone()
+			} else {
+				tr = orig.Clone()
+			}
+			for _, fn := range c.tuneTransport {
+				fn(tr)
+			}
+			tuned[orig] = tr
+		}
+
+		out := *hc
+		out.Transport = tr
+		return &out, nil
+	}
+
+	hc, err := tune(c.HTTPClient)
+	if err != nil {
+		return err
+	}
+	bc, err := tune(c.BundleClient)
+	if err != nil {
+		return fmt.Errorf("bundle client: %w", err)
+	}
+	c.HTTPClient, c.BundleClient = hc, bc
+	return nil
+}
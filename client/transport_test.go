@@ -0,0 +1,102 @@
+package client_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestWithTransport(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("token", "project",
+		client.WithTransport(func(tr *http.Transport) { tr.MaxIdleConnsPerHost = 32 }),
+		client.WithTransport(func(tr *http.Transport) { tr.IdleConnTimeout = 7 * time.Second }),
+		client.WithBundleTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, ok := c.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport = %T, want *http.Transport", c.HTTPClient.Transport)
+	}
+	if tr.MaxIdleConnsPerHost != 32 || tr.IdleConnTimeout != 7*time.Second {
+		t.Fatalf("MaxIdleConnsPerHost = %d, IdleConnTimeout = %v", tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+	if c.BundleClient.Transport != c.HTTPClient.Transport {
+		t.Fatal("bundle client no longer shares the API transport")
+	}
+	if c.HTTPClient.Timeout == 0 || c.BundleClient.Timeout != time.Minute {
+		t.Fatalf("timeouts = %v, %v; want kept", c.HTTPClient.Timeout, c.BundleClient.Timeout)
+	}
+	if http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost == 32 {
+		t.Fatal("http.DefaultTransport was modified")
+	}
+}
+
+func TestWithTransport_ClonesCustomTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	own := &http.Transport{MaxIdleConnsPerHost: 4}
+	hc := &http.Client{Transport: own}
+	c, err := client.NewClient("token", "project",
+		client.WithBaseURL(srv.URL),
+		client.WithHTTPClient(hc),
+		client.WithTransport(func(tr *http.Transport) { tr.MaxIdleConnsPerHost = 64 }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if own.MaxIdleConnsPerHost != 4 || hc.Transport != own {
+		t.Fatal("caller's transport was modified")
+	}
+	if got := c.HTTPClient.Transport.(*http.Transport).MaxIdleConnsPerHost; got != 64 {
+		t.Fatalf("MaxIdleConnsPerHost = %d, want 64", got)
+	}
+	if err := c.DoJSONWithRetry(context.Background(), http.MethodGet, "projects", nil, nil); err != nil {
+		t.Fatalf("request through tuned transport: %v", err)
+	}
+}
+
+func TestWithTransport_WithTLS(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("token", "project",
+		client.WithTransport(func(tr *http.Transport) { tr.ForceAttemptHTTP2 = false }),
+		client.WithMinTLSVersion(tls.VersionTLS13),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := c.HTTPClient.Transport.(*http.Transport)
+	if tr.ForceAttemptHTTP2 || tr.TLSClientConfig == nil || tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("tuning or TLS settings lost: ForceAttemptHTTP2 = %v, TLS = %#v", tr.ForceAttemptHTTP2, tr.TLSClientConfig)
+	}
+}
+
+func TestWithTransport_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := client.NewClient("token", "project", client.WithTransport(nil)); err == nil {
+		t.Error("WithTransport(nil) error = nil")
+	}
+
+	custom := &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, nil })}
+	if _, err := client.NewClient("token", "project",
+		client.WithHTTPClient(custom),
+		client.WithTransport(func(*http.Transport) {}),
+	); err == nil {
+		t.Error("tuning a non-*http.Transport error = nil")
+	}
+}
@@ -0,0 +1,161 @@
+package upload
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// AnnotationLevel is the severity of an Annotation.
+type AnnotationLevel string
+
+const (
+	AnnotationNotice  AnnotationLevel = "notice"
+	AnnotationWarning AnnotationLevel = "warning"
+	AnnotationError   AnnotationLevel = "error"
+)
+
+// Annotation is a file-level finding meant to be shown inline in code
+// review. Build them with BatchUploadResult.Annotations or
+// CleanupPreview.Annotations and write them with WriteGitHubAnnotations or
+// WriteGitLabCodeQuality.
+type Annotation struct {
+	Level   AnnotationLevel
+	File    string
+	Title   string
+	Message string
+}
+
+// Annotations returns one error annotation per failed item, pointing at the
+// item's local source path.
+func (r BatchUploadResult) Annotations() []Annotation {
+	var out []Annotation
+	for _, item := range r.Items {
+		if item.Err == nil {
+			continue
+		}
+		out = append(out, Annotation{
+			Level:   AnnotationError,
+			File:    item.SrcPath,
+			Title:   "Lokalise upload failed",
+			Message: item.Err.Error(),
+		})
+	}
+	return out
+}
+
+// Annotations returns a warning listing the keys an upload with cleanup_mode
+// would delete, or nil if there are none. file is the local path to point
+// at; when empty, the remote filename is used.
+func (p CleanupPreview) Annotations(file string) []Annotation {
+	if len(p.Removed) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(file) == "" {
+		file = p.Filename
+	}
+
+	names := make([]string, 0, len(p.Removed))
+	for _, k := range p.Removed {
+		names = append(names, strings.Join(k.Names(), "/"))
+	}
+	return []Annotation{{
+		Level:   AnnotationWarning,
+		File:    file,
+		Title:   fmt.Sprintf("Lokalise cleanup would delete %d key(s)", len(p.Removed)),
+		Message: strings.Join(names, "\n"),
+	}}
+}
+
+// WriteGitHubAnnotations writes annotations as GitHub Actions workflow
+// commands (::error file=...::message). Printed to a job's stdout, they show
+// up on the pull request's changed files.
+func WriteGitHubAnnotations(w io.Writer, annotations []Annotation) error {
+	for _, a := range annotations {
+		level := a.Level
+		if level == "" {
+			level = AnnotationNotice
+		}
+
+		var props []string
+		if a.File != "" {
+			props = append(props, "file="+escapeGitHubProperty(a.File))
+		}
+		if a.Title != "" {
+			props = append(props, "title="+escapeGitHubProperty(a.Title))
+		}
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(props, ","), escapeGitHubData(a.Message)); err != nil {
+			return fmt.Errorf("upload: write annotations: %w", err)
+		}
+	}
+	return nil
+}
+
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// WriteGitLabCodeQuality writes annotations as a GitLab Code Quality report.
+// Publish the file as an artifacts:reports:codequality artifact to have the
+// findings shown in the merge request.
+func WriteGitLabCodeQuality(w io.Writer, annotations []Annotation) error {
+	issues := make([]codeQualityIssue, 0, len(annotations))
+	for _, a := range annotations {
+		desc := a.Title
+		if a.Message != "" {
+			desc = strings.TrimSpace(desc + ": " + a.Message)
+		}
+		sum := md5.Sum([]byte(string(a.Level) + "\x00" + a.File + "\x00" + a.Title + "\x00" + a.Message))
+
+		issues = append(issues, codeQualityIssue{
+			Description: desc,
+			CheckName:   "lokex",
+			Fingerprint: hex.EncodeToString(sum[:]),
+			Severity:    codeQualitySeverity(a.Level),
+			Location:    codeQualityLocation{Path: a.File, Lines: codeQualityLines{Begin: 1}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(issues); err != nil {
+		return fmt.Errorf("upload: write code quality report: %w", err)
+	}
+	return nil
+}
+
+func codeQualitySeverity(l AnnotationLevel) string {
+	switch l {
+	case AnnotationError:
+		return "major"
+	case AnnotationWarning:
+		return "minor"
+	default:
+		return "info"
+	}
+}
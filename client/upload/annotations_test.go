@@ -0,0 +1,131 @@
+package upload_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/keys"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+func TestBatchUploadResult_Annotations(t *testing.T) {
+	t.Parallel()
+
+	res := upload.BatchUploadResult{Items: []upload.BatchUploadResultItem{
+		{Index: 0, SrcPath: "locales/en.json", ProcessID: "p1"},
+		{Index: 1, SrcPath: "locales/fr.json", Err: errors.New("upload: process p2 failed: bad file")},
+	}}
+
+	want := []upload.Annotation{{
+		Level:   upload.AnnotationError,
+		File:    "locales/fr.json",
+		Title:   "Lokalise upload failed",
+		Message: "upload: process p2 failed: bad file",
+	}}
+	if got := res.Annotations(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Annotations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCleanupPreview_Annotations(t *testing.T) {
+	t.Parallel()
+
+	p := upload.CleanupPreview{
+		Filename: "%LANG_ISO%.json",
+		Removed: []keys.Key{
+			{KeyName: keys.PlatformStrings{Web: "home.title"}},
+			{KeyName: keys.PlatformStrings{IOS: "ios_key", Android: "android_key"}},
+		},
+	}
+
+	got := p.Annotations("locales/en.json")
+	want := []upload.Annotation{{
+		Level:   upload.AnnotationWarning,
+		File:    "locales/en.json",
+		Title:   "Lokalise cleanup would delete 2 key(s)",
+		Message: "home.title\nios_key/android_key",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Annotations() = %+v, want %+v", got, want)
+	}
+
+	if got := p.Annotations(""); got[0].File != "%LANG_ISO%.json" {
+		t.Fatalf("File = %q, want remote filename", got[0].File)
+	}
+	if got := (upload.CleanupPreview{Filename: "x"}).Annotations("x"); got != nil {
+		t.Fatalf("Annotations() with nothing removed = %v, want nil", got)
+	}
+}
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := upload.WriteGitHubAnnotations(&buf, []upload.Annotation{
+		{Level: upload.AnnotationError, File: "a,b:c.json", Title: "Upload failed", Message: "100% bad\nline two"},
+		{Message: "plain"},
+	})
+	if err != nil {
+		t.Fatalf("WriteGitHubAnnotations() error = %v", err)
+	}
+
+	want := "::error file=a%2Cb%3Ac.json,title=Upload failed::100%25 bad%0Aline two\n" +
+		"::notice ::plain\n"
+	if buf.String() != want {
+		t.Fatalf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteGitLabCodeQuality(t *testing.T) {
+	t.Parallel()
+
+	anns := []upload.Annotation{
+		{Level: upload.AnnotationError, File: "en.json", Title: "Upload failed", Message: "boom"},
+		{Level: upload.AnnotationWarning, File: "fr.json", Title: "Cleanup"},
+	}
+
+	var buf bytes.Buffer
+	if err := upload.WriteGitLabCodeQuality(&buf, anns); err != nil {
+		t.Fatalf("WriteGitLabCodeQuality() error = %v", err)
+	}
+
+	var issues []struct {
+		Description string `json:"description"`
+		CheckName   string `json:"check_name"`
+		Fingerprint string `json:"fingerprint"`
+		Severity    string `json:"severity"`
+		Location    struct {
+			Path  string `json:"path"`
+			Lines struct {
+				Begin int `json:"begin"`
+			} `json:"lines"`
+		} `json:"location"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("issues = %d, want 2", len(issues))
+	}
+
+	first := issues[0]
+	if first.Description != "Upload failed: boom" || first.Severity != "major" || first.CheckName != "lokex" ||
+		first.Location.Path != "en.json" || first.Location.Lines.Begin != 1 || len(first.Fingerprint) != 32 {
+		t.Fatalf("issue[0] = %+v", first)
+	}
+	if issues[1].Description != "Cleanup" || issues[1].Severity != "minor" {
+		t.Fatalf("issue[1] = %+v", issues[1])
+	}
+	if first.Fingerprint == issues[1].Fingerprint {
+		t.Fatal("fingerprints must differ")
+	}
+
+	buf.Reset()
+	if err := upload.WriteGitLabCodeQuality(&buf, nil); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Fatalf("empty report = %q, %v, want []", buf.String(), err)
+	}
+}
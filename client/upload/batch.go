@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/bodrovis/lokex/v2/client"
 	"github.com/bodrovis/lokex/v2/client/internal/background"
 )
 
@@ -33,11 +35,17 @@ type BatchUploadItem struct {
 }
 
 // BatchUploadResultItem contains the result for a single batch item.
-// Index always matches the position in the input slice.
+// Index always matches the position in the input slice. Format is the
+// explicit "format" param or the format inferred from the file ("" if unknown).
+// Files and Warnings are filled in from the finished process when polling
+// (see UploadResult).
 type BatchUploadResultItem struct {
 	Index     int
 	SrcPath   string
+	Format    string
 	ProcessID string
+	Files     []client.ProcessFile
+	Warnings  []string
 	Err       error
 }
 
@@ -70,6 +78,12 @@ func (r BatchUploadResult) SuccessfulProcessIDs() []string {
 
 // UploadBatch uploads many files without failing the whole batch on per-file errors.
 // Behavior:
+//   - With WithPushLock, the push lock is taken first and held until return.
+//   - With WithImportConflict, imports already running in the project are
+//     handled first (see ImportConflictPolicy).
+//   - With WithBranchSync, the branch is created before the kickoff and, if
+//     poll is true and every item succeeded, merged afterwards. A failed
+//     merge is returned as the error, alongside the full result.
 //   - Kickoff phase uses uploadSingle(..., poll=false) for each item.
 //   - At most 6 uploads are kicked off in parallel (Lokalise API limit).
 //   - If poll is false, it returns immediately after kickoff with per-item process IDs/errors.
@@ -77,18 +91,25 @@ func (r BatchUploadResult) SuccessfulProcessIDs() []string {
 //     per-item completion errors without discarding successful uploads.
 //
 // The returned BatchUploadResult always preserves the input order.
-// A non-nil error is returned only for fatal batch-level problems (nil client, canceled
-// context before start, etc.). Per-item failures are stored in result.Items[i].Err.
-func (u *Uploader) UploadBatch(ctx context.Context, items []BatchUploadItem, poll bool) (BatchUploadResult, error) {
+// A non-nil error is returned only for fatal batch-level problems (nil client,
+// canceled context before start, two items with the same remote filename and
+// lang_iso, a running import under ImportConflictFail, a failed branch create
+// or merge, etc.). Per-item failures are stored in result.Items[i].Err.
+// opts apply to every request of the batch, as in Upload.
+func (u *Uploader) UploadBatch(ctx context.Context, items []BatchUploadItem, poll bool, opts ...client.RequestOption) (BatchUploadResult, error) {
 	if u == nil || u.client == nil {
 		return BatchUploadResult{}, errors.New("upload: batch: uploader/client is nil")
 	}
+	u = u.WithRequestOptions(opts...)
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	if err := ctx.Err(); err != nil {
 		return BatchUploadResult{}, err
 	}
+	if coll := findFilenameCollisions(items); len(coll) > 0 {
+		return BatchUploadResult{}, &DuplicateFilenameError{Collisions: coll}
+	}
 
 	results := make([]BatchUploadResultItem, len(items))
 	for i, item := range items {
@@ -99,13 +120,39 @@ func (u *Uploader) UploadBatch(ctx context.Context, items []BatchUploadItem, pol
 		return BatchUploadResult{Items: results}, nil
 	}
 
+	unlock, err := u.lockPush(ctx)
+	if err != nil {
+		return BatchUploadResult{}, fmt.Errorf("upload: batch: %w", err)
+	}
+	defer unlock()
+
+	if err := u.CheckImports(ctx, u.importConflict); err != nil {
+		return BatchUploadResult{}, fmt.Errorf("upload: batch: %w", err)
+	}
+
+	if err := ignoreDryRun(u.prepareBranch(ctx)); err != nil {
+		return BatchUploadResult{}, fmt.Errorf("upload: batch: %w", err)
+	}
+
 	u.kickoffBatchUploads(ctx, items, results)
 
 	if poll {
 		u.pollBatchResults(ctx, results)
 	}
 
-	return BatchUploadResult{Items: results}, nil
+	res := BatchUploadResult{Items: results}
+	if u.client.DryRun() {
+		return res, nil
+	}
+	if slices.ContainsFunc(results, func(r BatchUploadResultItem) bool { return r.Err == nil }) {
+		u.client.RecordPush()
+	}
+	if poll && !res.HasErrors() {
+		if err := u.finishBranch(ctx); err != nil {
+			return res, fmt.Errorf("upload: batch: %w", err)
+		}
+	}
+	return res, nil
 }
 
 func newBatchUploadResultItem(index int, item BatchUploadItem) BatchUploadResultItem {
@@ -155,9 +202,15 @@ func (u *Uploader) kickoffBatchUploadItem(
 	}
 	defer releaseBatchUploadSlot(sem)
 
+	var warning error
+	result.Format, warning = inspectFormat(item.Params, item.SrcPath)
+	if warning != nil {
+		result.Warnings = append(result.Warnings, warning.Error())
+	}
+
 	processID, err := batchUploadSingleFn(u, ctx, item.Params, item.SrcPath)
 	result.ProcessID = strings.TrimSpace(processID)
-	result.Err = err
+	result.Err = ignoreDryRun(err)
 }
 
 func releaseBatchUploadSlot(sem chan struct{}) {
@@ -212,6 +265,12 @@ func applyPolledBatchResults(
 		_, err := batchHandleProcessStatusFn(processID, p.Status, p.Message)
 		if err != nil {
 			markBatchItemError(results, indexes, err)
+			continue
+		}
+		warnings := fileWarnings(p.Files)
+		for _, idx := range indexes {
+			results[idx].Files = p.Files
+			results[idx].Warnings = append(results[idx].Warnings, warnings...)
 		}
 	}
 
@@ -0,0 +1,101 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrDuplicateFilename matches (via errors.Is) the *DuplicateFilenameError
+// returned by UploadBatch.
+var ErrDuplicateFilename = errors.New("upload: duplicate remote filename")
+
+// FilenameCollision lists batch items that would upload to the same remote
+// filename for the same language, so later imports would overwrite earlier ones.
+type FilenameCollision struct {
+	Filename string // normalized remote filename
+	LangISO  string
+	Indexes  []int // positions in the batch input
+}
+
+// DuplicateFilenameError is returned by UploadBatch before anything is sent
+// when two or more items collide on remote filename and language.
+type DuplicateFilenameError struct {
+	Collisions []FilenameCollision
+}
+
+func (e *DuplicateFilenameError) Error() string {
+	var b strings.Builder
+	b.WriteString("upload: batch: duplicate remote filename")
+	for i, c := range e.Collisions {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "%q", c.Filename)
+		if c.LangISO != "" {
+			fmt.Fprintf(&b, " (lang %s)", c.LangISO)
+		}
+		b.WriteString(" in items ")
+		for j, idx := range c.Indexes {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(strconv.Itoa(idx))
+		}
+	}
+	return b.String()
+}
+
+// Is reports whether target is ErrDuplicateFilename.
+func (e *DuplicateFilenameError) Is(target error) bool {
+	return target == ErrDuplicateFilename
+}
+
+// findFilenameCollisions groups items by normalized remote filename and
+// lang_iso. Items without a usable filename are skipped; they fail on their own.
+func findFilenameCollisions(items []BatchUploadItem) []FilenameCollision {
+	type key struct{ filename, lang string }
+
+	var order []key
+	byKey := make(map[key][]int, len(items))
+	for i, item := range items {
+		raw, _ := item.Params["filename"].(string)
+		name := normalizeRemoteFilename(raw)
+		if name == "" {
+			continue
+		}
+		lang, _ := item.Params["lang_iso"].(string)
+
+		k := key{filename: name, lang: strings.TrimSpace(lang)}
+		if _, seen := byKey[k]; !seen {
+			order = append(order, k)
+		}
+		byKey[k] = append(byKey[k], i)
+	}
+
+	var out []FilenameCollision
+	for _, k := range order {
+		if idx := byKey[k]; len(idx) > 1 {
+			out = append(out, FilenameCollision{Filename: k.filename, LangISO: k.lang, Indexes: idx})
+		}
+	}
+	return out
+}
+
+// normalizeRemoteFilename maps spellings Lokalise treats as the same file
+// ("./a/en.json", `a\en.json`, "a//en.json") to one form.
+func normalizeRemoteFilename(name string) string {
+	name = strings.TrimSpace(strings.ReplaceAll(name, `\`, "/"))
+	if name == "" {
+		return ""
+	}
+	name = path.Clean(name)
+	if name == "." {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,92 @@
+package upload_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+func TestUploader_UploadBatch_DuplicateRemoteFilename(t *testing.T) {
+	var calls atomic.Int32
+	restore := upload.ExportSetBatchUploadSingleForTest(
+		func(*upload.Uploader, context.Context, upload.UploadParams, string) (string, error) {
+			calls.Add(1)
+			return "pid", nil
+		},
+	)
+	defer restore()
+
+	cli, err := client.NewClient(token, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := []upload.BatchUploadItem{
+		{Params: upload.UploadParams{"filename": "locales/en.json", "lang_iso": "en"}, SrcPath: "a/en.json"},
+		{Params: upload.UploadParams{"filename": "locales/en.json", "lang_iso": "fr"}, SrcPath: "a/fr.json"},
+		{Params: upload.UploadParams{"filename": "./locales//en.json", "lang_iso": "en"}, SrcPath: "b/en.json"},
+		{Params: upload.UploadParams{"filename": `strings\app.xml`}},
+		{Params: upload.UploadParams{"filename": "strings/app.xml"}},
+		{Params: upload.UploadParams{"lang_iso": "en"}}, // no filename: fails per item, not a collision
+	}
+
+	res, err := upload.NewUploader(cli).UploadBatch(context.Background(), items, false)
+	if !errors.Is(err, upload.ErrDuplicateFilename) {
+		t.Fatalf("UploadBatch() error = %v, want ErrDuplicateFilename", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("uploads started = %d, want 0", calls.Load())
+	}
+	if len(res.Items) != 0 {
+		t.Fatalf("res.Items = %v, want empty on fatal error", res.Items)
+	}
+
+	var dup *upload.DuplicateFilenameError
+	if !errors.As(err, &dup) {
+		t.Fatalf("error %T is not *DuplicateFilenameError", err)
+	}
+	want := []upload.FilenameCollision{
+		{Filename: "locales/en.json", LangISO: "en", Indexes: []int{0, 2}},
+		{Filename: "strings/app.xml", Indexes: []int{3, 4}},
+	}
+	if !reflect.DeepEqual(dup.Collisions, want) {
+		t.Fatalf("Collisions = %+v, want %+v", dup.Collisions, want)
+	}
+
+	wantMsg := `upload: batch: duplicate remote filename: "locales/en.json" (lang en) in items 0, 2; "strings/app.xml" in items 3, 4`
+	if err.Error() != wantMsg {
+		t.Fatalf("Error() = %q, want %q", err.Error(), wantMsg)
+	}
+}
+
+func TestUploader_UploadBatch_SameFilenameDifferentLanguages(t *testing.T) {
+	var calls atomic.Int32
+	restore := upload.ExportSetBatchUploadSingleForTest(
+		func(*upload.Uploader, context.Context, upload.UploadParams, string) (string, error) {
+			calls.Add(1)
+			return "pid", nil
+		},
+	)
+	defer restore()
+
+	cli, err := client.NewClient(token, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = upload.NewUploader(cli).UploadBatch(context.Background(), []upload.BatchUploadItem{
+		{Params: upload.UploadParams{"filename": "strings.xml", "lang_iso": "en"}, SrcPath: "values/strings.xml"},
+		{Params: upload.UploadParams{"filename": "strings.xml", "lang_iso": "fr"}, SrcPath: "values-fr/strings.xml"},
+	}, false)
+	if err != nil {
+		t.Fatalf("UploadBatch() error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("uploads started = %d, want 2", calls.Load())
+	}
+}
@@ -0,0 +1,99 @@
+package upload
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"strings"
+)
+
+// ParamsOverride sets params for the batch items whose local source path
+// (SrcPath) or remote filename matches Pattern. Patterns use path.Match
+// syntax with "/" separators, plus "**" as a whole segment matching any
+// number of directories, e.g. "android/**" or "**/*.strings".
+type ParamsOverride struct {
+	Pattern string
+	Params  UploadParams
+}
+
+// ApplyParamOverrides returns a copy of items with every matching override
+// merged over the item's own params. Overrides are applied in order, so a
+// later override wins over an earlier one for the same key. Input items and
+// their params are not modified. Pass the result to UploadBatch.
+func ApplyParamOverrides(items []BatchUploadItem, overrides []ParamsOverride) ([]BatchUploadItem, error) {
+	for i, o := range overrides {
+		if err := validateOverridePattern(o.Pattern); err != nil {
+			return nil, fmt.Errorf("upload: override %d: %w", i, err)
+		}
+	}
+
+	out := make([]BatchUploadItem, len(items))
+	for i, item := range items {
+		params := make(UploadParams, len(item.Params))
+		maps.Copy(params, item.Params)
+
+		for _, o := range overrides {
+			if overrideMatches(o.Pattern, item) {
+				maps.Copy(params, o.Params)
+			}
+		}
+
+		out[i] = BatchUploadItem{Params: params, SrcPath: item.SrcPath}
+	}
+	return out, nil
+}
+
+func validateOverridePattern(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	for seg := range strings.SplitSeq(pattern, "/") {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func overrideMatches(pattern string, item BatchUploadItem) bool {
+	pat := strings.Split(strings.Trim(strings.TrimSpace(pattern), "/"), "/")
+
+	filename, _ := item.Params["filename"].(string)
+	for _, candidate := range []string{item.SrcPath, filename} {
+		name := normalizeRemoteFilename(candidate)
+		if name == "" {
+			continue
+		}
+		if matchSegments(pat, strings.Split(strings.TrimPrefix(name, "/"), "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment consumes zero or more path segments.
+func matchSegments(pat, name []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pat[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], name[0]); !ok {
+			return false
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0
+}
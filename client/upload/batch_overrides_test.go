@@ -0,0 +1,102 @@
+package upload_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+func TestApplyParamOverrides(t *testing.T) {
+	t.Parallel()
+
+	base := upload.UploadParams{"lang_iso": "en", "tags": []string{"base"}}
+	items := []upload.BatchUploadItem{
+		{Params: cloneParams(base, "filename", "%LANG_ISO%.xml"), SrcPath: "res/android/values/strings.xml"},
+		{Params: cloneParams(base, "filename", "ios/en.lproj/Localizable.strings")},
+		{Params: cloneParams(base, "filename", "web/en.json")},
+	}
+
+	overrides := []upload.ParamsOverride{
+		{Pattern: "**/android/**", Params: upload.UploadParams{"tags": []string{"android"}, "convert_placeholders": false}},
+		{Pattern: "ios/**/*.strings", Params: upload.UploadParams{"tags": []string{"ios"}}},
+		{Pattern: "**/*.strings", Params: upload.UploadParams{"convert_placeholders": true}},
+	}
+
+	got, err := upload.ApplyParamOverrides(items, overrides)
+	if err != nil {
+		t.Fatalf("ApplyParamOverrides() error = %v", err)
+	}
+
+	want := []upload.UploadParams{
+		{"filename": "%LANG_ISO%.xml", "lang_iso": "en", "tags": []string{"android"}, "convert_placeholders": false},
+		{"filename": "ios/en.lproj/Localizable.strings", "lang_iso": "en", "tags": []string{"ios"}, "convert_placeholders": true},
+		{"filename": "web/en.json", "lang_iso": "en", "tags": []string{"base"}},
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i].Params, want[i]) {
+			t.Errorf("item %d params = %v, want %v", i, got[i].Params, want[i])
+		}
+	}
+	if got[0].SrcPath != items[0].SrcPath {
+		t.Errorf("SrcPath = %q, want %q", got[0].SrcPath, items[0].SrcPath)
+	}
+
+	if _, ok := items[0].Params["convert_placeholders"]; ok {
+		t.Fatal("input params were modified")
+	}
+}
+
+func TestApplyParamOverrides_Patterns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{pattern: "*.json", name: "en.json", want: true},
+		{pattern: "*.json", name: "web/en.json", want: false},
+		{pattern: "**/*.json", name: "en.json", want: true},
+		{pattern: "web/**", name: "web/a/b/en.json", want: true},
+		{pattern: "web/**", name: "./web//en.json", want: true},
+		{pattern: "web/**", name: `web\en.json`, want: true},
+		{pattern: "web/**", name: "mobile/en.json", want: false},
+		{pattern: "a/**/z.json", name: "a/z.json", want: true},
+	}
+
+	for _, tt := range tests {
+		items := []upload.BatchUploadItem{{Params: upload.UploadParams{"filename": tt.name}}}
+		got, err := upload.ApplyParamOverrides(items, []upload.ParamsOverride{
+			{Pattern: tt.pattern, Params: upload.UploadParams{"hit": true}},
+		})
+		if err != nil {
+			t.Fatalf("%q: error = %v", tt.pattern, err)
+		}
+		if _, hit := got[0].Params["hit"]; hit != tt.want {
+			t.Errorf("pattern %q vs %q: matched = %v, want %v", tt.pattern, tt.name, hit, tt.want)
+		}
+	}
+}
+
+func TestApplyParamOverrides_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []string{"", "  ", "[a-"} {
+		_, err := upload.ApplyParamOverrides(nil, []upload.ParamsOverride{{Pattern: p}})
+		if err == nil || !strings.Contains(err.Error(), "override 0") {
+			t.Errorf("pattern %q: error = %v, want override error", p, err)
+		}
+	}
+}
+
+func cloneParams(base upload.UploadParams, kv ...any) upload.UploadParams {
+	out := make(upload.UploadParams, len(base)+len(kv)/2)
+	for k, v := range base {
+		out[k] = v
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		out[kv[i].(string)] = kv[i+1]
+	}
+	return out
+}
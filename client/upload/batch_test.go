@@ -2,9 +2,13 @@ package upload_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -1021,3 +1025,38 @@ func TestKickoffBatchUploadItemForTest_SetsErrorWhenAcquireSlotFails(t *testing.
 		t.Fatalf("result.SrcPath = %q, want %q", result.SrcPath, "a.json")
 	}
 }
+
+func TestUploader_UploadBatch_IdempotencyKeyPerFile(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		keys = map[string]string{} // filename -> key
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Filename string `json:"filename"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		keys[body.Filename] = r.Header.Get(client.HeaderIdempotencyKey)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"process":{"process_id":"p-` + body.Filename + `","status":"queued"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := upload.NewUploader(cli).UploadBatch(context.Background(), []upload.BatchUploadItem{
+		{Params: upload.UploadParams{"filename": "en.json", "lang_iso": "en", "data": "e30="}},
+		{Params: upload.UploadParams{"filename": "fr.json", "lang_iso": "fr", "data": "e30="}},
+	}, false, client.WithIdempotencyKey("job-42"))
+	if err != nil || res.HasErrors() {
+		t.Fatalf("UploadBatch() = %+v, %v", res, err)
+	}
+
+	en, fr := keys["en.json"], keys["fr.json"]
+	if !strings.HasPrefix(en, "job-42:") || !strings.HasPrefix(fr, "job-42:") || en == fr {
+		t.Fatalf("keys = %q, want distinct keys derived from job-42", keys)
+	}
+}
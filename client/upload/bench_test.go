@@ -0,0 +1,68 @@
+package upload_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+// BenchmarkUploadBatch measures kickoff throughput of parallel batch uploads
+// against a local server (no polling), i.e. encoding + transport overhead.
+func BenchmarkUploadBatch(b *testing.B) {
+	var n atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"process":{"process_id":"p%d"}}`, n.Add(1))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithHTTPClient(srv.Client()),
+	)
+	if err != nil {
+		b.Fatalf("NewClient() error = %v", err)
+	}
+	u := upload.NewUploader(c)
+
+	dir := b.TempDir()
+	const files = 12
+	items := make([]upload.BatchUploadItem, 0, files)
+	for i := range files {
+		path := writeBenchFile(b, dir, fmt.Sprintf("f%02d.json", i), 64<<10)
+		items = append(items, upload.BatchUploadItem{
+			Params: upload.UploadParams{"filename": path, "lang_iso": "en"},
+		})
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		res, err := u.UploadBatch(context.Background(), items, false)
+		if err != nil {
+			b.Fatalf("UploadBatch() error = %v", err)
+		}
+		if res.HasErrors() {
+			b.Fatalf("UploadBatch() item errors: %+v", res.Items)
+		}
+	}
+}
+
+func writeBenchFile(b *testing.B, dir, name string, size int) string {
+	b.Helper()
+
+	path := filepath.Join(dir, name)
+	data := bytes.Repeat([]byte("x"), size)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		b.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+
+	"github.com/bodrovis/lokex/v2/internal/utils"
 )
 
-func newUploadBody(ctx context.Context, params UploadParams, cleanPath string) (io.ReadCloser, error) {
+func newUploadBody(ctx context.Context, codec utils.Codec, params UploadParams, cleanPath string, filter dataFilter) (io.ReadCloser, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -16,6 +18,7 @@ func newUploadBody(ctx context.Context, params UploadParams, cleanPath string) (
 	if err != nil {
 		return nil, err
 	}
+	spec.filter = filter
 
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -51,7 +54,7 @@ func newUploadBody(ctx context.Context, params UploadParams, cleanPath string) (
 			}
 		}()
 
-		werr = writeUploadJSON(bw, params, cleanPath, spec)
+		werr = writeUploadJSON(bw, codec, params, cleanPath, spec)
 	}()
 
 	return pr, nil
@@ -0,0 +1,137 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// DefaultMaxBodySize is the largest upload request body sent without
+// WithMaxBodySize. Lokalise rejects bigger bodies with 413 Request Entity
+// Too Large; file data grows by a third when base64-encoded, so the file
+// itself must be somewhat smaller.
+const DefaultMaxBodySize int64 = 50 << 20
+
+// ErrBodyTooLarge is matched (via errors.Is) by *BodyTooLargeError.
+var ErrBodyTooLarge = errors.New("upload: request body too large")
+
+// BodyTooLargeError is returned before sending an upload whose JSON body
+// would exceed the size limit.
+type BodyTooLargeError struct {
+	Filename string // remote filename of the upload
+	Size     int64  // encoded body size in bytes
+	Limit    int64  // limit in bytes; see WithMaxBodySize
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf(
+		"upload %s: request body would be %s, over the %s limit; split the file (e.g. by language or namespace) and send the parts with UploadBatch",
+		e.Filename, formatSize(e.Size), formatSize(e.Limit),
+	)
+}
+
+// Is makes errors.Is(err, ErrBodyTooLarge) match.
+func (e *BodyTooLargeError) Is(target error) bool { return target == ErrBodyTooLarge }
+
+// WithMaxBodySize returns a copy of u that refuses, before sending, uploads
+// whose encoded JSON body is larger than n bytes (DefaultMaxBodySize
+// unless set). Zero or negative turns the check off, e.g. for a plan with
+// a higher limit.
+func (u *Uploader) WithMaxBodySize(n int64) *Uploader {
+	if u == nil {
+		return nil
+	}
+	cp := *u
+	cp.maxBodySize = n
+	if n <= 0 {
+		cp.maxBodySize = -1
+	}
+	return &cp
+}
+
+// checkBodySize fails with a *BodyTooLargeError if the body
+// writeUploadJSON would produce for params is over the limit.
+func (u *Uploader) checkBodySize(params UploadParams, readPath, filename string) error {
+	limit := u.maxBodySize
+	if limit < 0 {
+		return nil
+	}
+	if limit == 0 {
+		limit = DefaultMaxBodySize
+	}
+	size, err := uploadBodySize(u.client.Codec, params, readPath, u.data)
+	if err != nil {
+		return err
+	}
+	if size > limit {
+		return &BodyTooLargeError{Filename: filename, Size: size, Limit: limit}
+	}
+	return nil
+}
+
+// uploadBodySize returns the exact size of the JSON body writeUploadJSON
+// produces, without encoding the file data. The data is only read through
+// when filter transcodes UTF-16 or rewrites line endings.
+func uploadBodySize(codec utils.Codec, params UploadParams, readPath string, filter dataFilter) (int64, error) {
+	spec, err := parseUploadDataSpec(params)
+	if err != nil {
+		return 0, err
+	}
+
+	size := int64(len(`{}`))
+	for k, v := range params {
+		if k == "data" {
+			continue
+		}
+		kb, vb, err := marshalUploadKV(codec, k, v)
+		if err != nil {
+			return 0, err
+		}
+		size += int64(len(kb)+len(":")+len(vb)) + int64(len(","))
+	}
+	size += int64(len(`"data":""`))
+
+	switch {
+	case spec.useFile:
+		n, err := fileDataLen(readPath, filter)
+		if err != nil {
+			return 0, err
+		}
+		size += int64(base64.StdEncoding.EncodedLen(int(n)))
+	case spec.dataWasBytes:
+		n, err := filter.dataLen(bytes.NewReader(spec.dataBytes), int64(len(spec.dataBytes)))
+		if err != nil {
+			return 0, err
+		}
+		size += int64(base64.StdEncoding.EncodedLen(int(n)))
+	default:
+		size += int64(len(spec.dataString))
+	}
+	return size, nil
+}
+
+// fileDataLen returns the length of the file at path once normalized.
+func fileDataLen(path string, filter dataFilter) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return filter.dataLen(f, fi.Size())
+}
+
+func formatSize(n int64) string {
+	const mib = 1 << 20
+	if n >= mib {
+		return fmt.Sprintf("%.1f MiB", float64(n)/mib)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,193 @@
+package upload_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+func TestUploadBodySize_MatchesEncodedBody(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	path := write("en.json", []byte(`{"greeting":"héllo <world> & \"you\""}`))
+	bomPath := write("bom.json", append([]byte("\xEF\xBB\xBF"), `{"a":"b"}`...))
+	utf16Path := write("fr.strings", utf16LE("\"greeting\" = \"日本語 😀\";\r\n\"b\" = \"c\";\n"))
+	crlfPath := write("de.strings", []byte("\"a\" = \"b\";\r\n\"c\" = \"d\";\n"))
+
+	tests := []struct {
+		name     string
+		params   upload.UploadParams
+		readPath string
+	}{
+		{
+			name:     "file",
+			params:   upload.UploadParams{"filename": "en.json", "lang_iso": "en", "tags": []string{"a", "<b>"}},
+			readPath: path,
+		},
+		{
+			name:     "file with BOM",
+			params:   upload.UploadParams{"filename": "en.json", "lang_iso": "en"},
+			readPath: bomPath,
+		},
+		{
+			name:     "UTF-16 file",
+			params:   upload.UploadParams{"filename": "fr.strings", "lang_iso": "fr"},
+			readPath: utf16Path,
+		},
+		{
+			name:     "mixed line endings",
+			params:   upload.UploadParams{"filename": "de.strings", "lang_iso": "de"},
+			readPath: crlfPath,
+		},
+		{
+			name:   "UTF-16 bytes",
+			params: upload.UploadParams{"filename": "fr.strings", "lang_iso": "fr", "data": utf16BE(`"a" = "ß";`)},
+		},
+		{
+			name:   "bytes",
+			params: upload.UploadParams{"filename": "en.json", "lang_iso": "en", "data": []byte("ünïcode\x00bytes")},
+		},
+		{
+			name: "base64 string",
+			params: upload.UploadParams{
+				"filename":                      "fr.json",
+				"lang_iso":                      "fr",
+				"data":                          "eyJhIjoiYiJ9",
+				"replace_modified":              true,
+				"custom_translation_status_ids": []int{1, 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, enc := range []upload.Encoding{upload.EncodingStripBOM, upload.EncodingUTF8, upload.EncodingRaw} {
+			for _, le := range []client.LineEnding{client.LineEndingKeep, client.LineEndingLF, client.LineEndingCRLF} {
+				t.Run(fmt.Sprintf("%s/%d/%s", tt.name, enc, le), func(t *testing.T) {
+					t.Parallel()
+					got, err := upload.ExportUploadBodySize(tt.params, tt.readPath, enc, le)
+					if err != nil {
+						t.Fatalf("uploadBodySize() error = %v", err)
+					}
+					rc, err := upload.ExportNewUploadBodyFiltered(context.Background(), tt.params, tt.readPath, enc, le)
+					if err != nil {
+						t.Fatal(err)
+					}
+					defer rc.Close()
+					body, err := io.ReadAll(rc)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got != int64(len(body)) {
+						t.Fatalf("uploadBodySize() = %d, encoded body is %d bytes: %s", got, len(body), body)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestUploader_MaxBodySize(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = io.WriteString(w, `{"process":{"process_id":"upl_1"}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := upload.UploadParams{"filename": "en.json", "lang_iso": "en", "data": []byte(strings.Repeat("x", 300))}
+
+	base := upload.NewUploader(cli)
+	limited := base.WithMaxBodySize(100)
+	_, err = limited.Upload(context.Background(), params, "", false)
+	if !errors.Is(err, upload.ErrBodyTooLarge) {
+		t.Fatalf("Upload() error = %v, want ErrBodyTooLarge", err)
+	}
+	var tooLarge *upload.BodyTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("Upload() error = %T, want *BodyTooLargeError", err)
+	}
+	if tooLarge.Filename != "en.json" || tooLarge.Limit != 100 || tooLarge.Size <= 400 {
+		t.Fatalf("BodyTooLargeError = %+v", tooLarge)
+	}
+	for _, want := range []string{"en.json", "100 bytes", "UploadBatch"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("requests sent = %d, want 0", n)
+	}
+
+	// The original uploader keeps the default limit; 0 disables the check.
+	for _, u := range []*upload.Uploader{base, limited.WithMaxBodySize(0)} {
+		if _, err := u.Upload(context.Background(), params, "", false); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("requests sent = %d, want 2", n)
+	}
+}
+
+// rawHTMLCodec is encoding/json without HTML escaping.
+type rawHTMLCodec struct{ client.StdCodec }
+
+func (c rawHTMLCodec) NewEncoder(w io.Writer) client.JSONEncoder {
+	enc := c.StdCodec.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc
+}
+
+func TestUploader_ParamsUseClientCodec(t *testing.T) {
+	t.Parallel()
+
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		_, _ = io.WriteString(w, `{"process":{"process_id":"upl_1"}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithMaxRetries(0),
+		client.WithCodec(rawHTMLCodec{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := upload.UploadParams{"filename": "en.json", "lang_iso": "en", "tags": []string{"<b>"}, "data": []byte("{}")}
+	if _, err := upload.NewUploader(cli).Upload(context.Background(), params, "", false); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if !strings.Contains(body, `"tags":["<b>"]`) {
+		t.Fatalf("body = %s, want tags encoded by the client codec", body)
+	}
+
+	size, err := upload.ExportUploadBodySizeWithCodec(rawHTMLCodec{}, params)
+	if err != nil || size != int64(len(body)) {
+		t.Fatalf("body size = %d (err %v), want %d", size, err, len(body))
+	}
+}
@@ -0,0 +1,178 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// ErrNoBranch is returned by the branch helpers when the client isn't
+// pointed at a branch (see client.WithBranch).
+var ErrNoBranch = errors.New("upload: client has no branch; use client.WithBranch")
+
+// branchPageLimit is the page size used when listing branches.
+var branchPageLimit = 500
+
+// BranchSync models the feature-branch workflow: upload to a branch that is
+// created on demand, then merge it back once the upload went through. It
+// only applies when the client points at a branch (client.WithBranch).
+type BranchSync struct {
+	// Create creates the branch before uploading if the project lacks it.
+	Create bool
+	// Merge merges the branch into MergeInto after a successful polled
+	// Upload or UploadBatch. Uploads that aren't polled are never merged:
+	// their imports may still be running.
+	Merge bool
+	// MergeInto names the target branch; "" means the main branch.
+	MergeInto string
+	// Resolve decides merge conflicts: "source" (the branch wins) or
+	// "target". "" leaves it to Lokalise, which fails on conflicts.
+	Resolve string
+}
+
+// Branch is a project branch.
+type Branch struct {
+	BranchID int64  `json:"branch_id"`
+	Name     string `json:"name"`
+}
+
+// WithBranchSync returns a copy of u whose Upload, Enqueue and UploadBatch
+// apply s.
+func (u *Uploader) WithBranchSync(s BranchSync) *Uploader {
+	if u == nil {
+		return nil
+	}
+	cp := *u
+	cp.branchSync = s
+	return &cp
+}
+
+// EnsureBranch creates the client's branch unless the project already has
+// it, and reports whether it did.
+func (u *Uploader) EnsureBranch(ctx context.Context) (bool, error) {
+	if u == nil || u.client == nil {
+		return false, errors.New("upload: uploader/client is nil")
+	}
+	name := u.client.Branch()
+	if name == "" {
+		return false, ErrNoBranch
+	}
+	branches, err := u.Branches(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := findBranch(branches, name); ok {
+		return false, nil
+	}
+
+	rdr, err := utils.EncodeJSONBodyWith(u.client.Codec, map[string]any{"name": name})
+	if err != nil {
+		return false, fmt.Errorf("upload: create branch %q: %w", name, err)
+	}
+	path := utils.ProjectPath(u.client.BaseProjectID(), "branches")
+	if err := u.client.DoJSONWithRetry(ctx, http.MethodPost, path, rdr, nil); err != nil {
+		return false, fmt.Errorf("upload: create branch %q: %w", name, err)
+	}
+	return true, nil
+}
+
+// MergeBranch merges the client's branch as configured by s (only
+// MergeInto and Resolve are used).
+func (u *Uploader) MergeBranch(ctx context.Context, s BranchSync) error {
+	if u == nil || u.client == nil {
+		return errors.New("upload: uploader/client is nil")
+	}
+	name := u.client.Branch()
+	if name == "" {
+		return ErrNoBranch
+	}
+
+	branches, err := u.Branches(ctx)
+	if err != nil {
+		return err
+	}
+	src, ok := findBranch(branches, name)
+	if !ok {
+		return fmt.Errorf("upload: merge branch %q: branch not found", name)
+	}
+
+	body := map[string]any{}
+	if s.Resolve != "" {
+		body["force_conflict_resolve_using"] = s.Resolve
+	}
+	if s.MergeInto != "" {
+		dst, ok := findBranch(branches, s.MergeInto)
+		if !ok {
+			return fmt.Errorf("upload: merge branch %q: target branch %q not found", name, s.MergeInto)
+		}
+		body["target_branch_id"] = dst.BranchID
+	}
+
+	rdr, err := utils.EncodeJSONBodyWith(u.client.Codec, body)
+	if err != nil {
+		return fmt.Errorf("upload: merge branch %q: %w", name, err)
+	}
+	path := utils.ProjectPath(u.client.BaseProjectID(), "branches/"+strconv.FormatInt(src.BranchID, 10)+"/merge")
+	var resp struct {
+		BranchMerged bool `json:"branch_merged"`
+	}
+	if err := u.client.DoJSONWithRetry(ctx, http.MethodPost, path, rdr, &resp); err != nil {
+		return fmt.Errorf("upload: merge branch %q: %w", name, err)
+	}
+	if !resp.BranchMerged {
+		return fmt.Errorf("upload: merge branch %q: not merged", name)
+	}
+	return nil
+}
+
+// Branches lists the project's branches.
+func (u *Uploader) Branches(ctx context.Context) ([]Branch, error) {
+	if u == nil || u.client == nil {
+		return nil, errors.New("upload: uploader/client is nil")
+	}
+	path := utils.ProjectPath(u.client.BaseProjectID(), "branches")
+	pages := client.NewPaginator[Branch](u.client, path, "branches", client.PaginatorOptions{Limit: max(branchPageLimit, 1)})
+
+	var out []Branch
+	for page := 1; ; page++ {
+		items, err := pages.Next(ctx)
+		if errors.Is(err, client.ErrNoMorePages) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("upload: list branches (page %d): %w", page, err)
+		}
+		out = append(out, items...)
+	}
+}
+
+func findBranch(branches []Branch, name string) (Branch, bool) {
+	for _, b := range branches {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return Branch{}, false
+}
+
+// prepareBranch applies BranchSync.Create before an upload.
+func (u *Uploader) prepareBranch(ctx context.Context) error {
+	if !u.branchSync.Create || u.client.Branch() == "" {
+		return nil
+	}
+	_, err := u.EnsureBranch(ctx)
+	return err
+}
+
+// finishBranch applies BranchSync.Merge after a successful polled upload.
+func (u *Uploader) finishBranch(ctx context.Context) error {
+	if !u.branchSync.Merge || u.client.Branch() == "" {
+		return nil
+	}
+	return u.MergeBranch(ctx, u.branchSync)
+}
@@ -0,0 +1,241 @@
+package upload_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+// branchServer fakes the Branches API of projectID with the given branches
+// (IDs 1, 2, ...), plus uploads and process polling. It records every
+// request as "METHOD path" and every merge body.
+type branchServer struct {
+	mu       sync.Mutex
+	branches []upload.Branch
+	calls    []string
+	merges   []map[string]any
+	mergeOK  bool
+}
+
+func newBranchServer(t *testing.T, names ...string) (*branchServer, *httptest.Server) {
+	t.Helper()
+
+	s := &branchServer{mergeOK: true}
+	for i, n := range names {
+		s.branches = append(s.branches, upload.Branch{BranchID: int64(i + 1), Name: n})
+	}
+	base := "/projects/" + projectID
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		path := r.URL.EscapedPath()
+		s.calls = append(s.calls, r.Method+" "+path)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && path == base+"/branches":
+			_ = json.NewEncoder(w).Encode(map[string]any{"branches": s.branches})
+		case r.Method == http.MethodPost && path == base+"/branches":
+			var body struct{ Name string }
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			b := upload.Branch{BranchID: int64(len(s.branches) + 1), Name: body.Name}
+			s.branches = append(s.branches, b)
+			_ = json.NewEncoder(w).Encode(map[string]any{"branch": b})
+		case r.Method == http.MethodPost && strings.HasPrefix(path, base+"/branches/") && strings.HasSuffix(path, "/merge"):
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			s.merges = append(s.merges, body)
+			_, _ = fmt.Fprintf(w, `{"branch_merged":%t}`, s.mergeOK)
+		case r.Method == http.MethodPost && strings.HasSuffix(path, "/files/upload"):
+			_, _ = io.Copy(io.Discard, r.Body)
+			_, _ = fmt.Fprint(w, `{"process":{"process_id":"p1"}}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(path, "/processes/p1"):
+			_, _ = fmt.Fprint(w, `{"process":{"process_id":"p1","status":"finished"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, path)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *branchServer) snapshot() ([]string, []map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.calls), slices.Clone(s.merges)
+}
+
+func newBranchUploader(t *testing.T, srv *httptest.Server, branch string) *upload.Uploader {
+	t.Helper()
+	opts := []client.Option{client.WithBaseURL(srv.URL), client.WithMaxRetries(0)}
+	if branch != "" {
+		opts = append(opts, client.WithBranch(branch))
+	}
+	cli, err := client.NewClient(token, projectID, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return upload.NewUploader(cli)
+}
+
+var branchUploadParams = upload.UploadParams{"filename": "en.json", "lang_iso": "en", "data": "e30="}
+
+func TestUploader_BranchSync_CreateUploadMerge(t *testing.T) {
+	t.Parallel()
+	s, srv := newBranchServer(t, "master", "release")
+
+	u := newBranchUploader(t, srv, "feature-x").WithBranchSync(upload.BranchSync{
+		Create: true, Merge: true, MergeInto: "release", Resolve: "source",
+	})
+	pid, err := u.Upload(context.Background(), branchUploadParams, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != "p1" {
+		t.Fatalf("process id = %q", pid)
+	}
+
+	calls, merges := s.snapshot()
+	base := "/projects/" + projectID
+	want := []string{
+		"GET " + base + "/branches",
+		"POST " + base + "/branches",
+		"POST " + base + ":feature-x/files/upload",
+		"GET " + base + ":feature-x/processes/p1",
+		"GET " + base + "/branches",
+		"POST " + base + "/branches/3/merge",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls:\n got %q\nwant %q", calls, want)
+	}
+	wantMerge := map[string]any{"force_conflict_resolve_using": "source", "target_branch_id": float64(2)}
+	if len(merges) != 1 || !reflect.DeepEqual(merges[0], wantMerge) {
+		t.Fatalf("merge bodies = %v", merges)
+	}
+}
+
+func TestUploader_BranchSync_ExistingBranchNotCreated(t *testing.T) {
+	t.Parallel()
+	s, srv := newBranchServer(t, "master", "feature-x")
+
+	created, err := newBranchUploader(t, srv, "feature-x").EnsureBranch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Fatal("created = true for an existing branch")
+	}
+	if calls, _ := s.snapshot(); len(calls) != 1 {
+		t.Fatalf("calls = %q, want just the listing", calls)
+	}
+}
+
+func TestUploader_BranchSync_NoMergeWithoutPoll(t *testing.T) {
+	t.Parallel()
+	s, srv := newBranchServer(t, "master", "feature-x")
+
+	u := newBranchUploader(t, srv, "feature-x").WithBranchSync(upload.BranchSync{Merge: true})
+	if _, err := u.Upload(context.Background(), branchUploadParams, "", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, merges := s.snapshot(); len(merges) != 0 {
+		t.Fatalf("merged %d times without polling", len(merges))
+	}
+}
+
+func TestUploader_BranchSync_BatchMergesIntoMain(t *testing.T) {
+	t.Parallel()
+	s, srv := newBranchServer(t, "master", "feature-x")
+
+	u := newBranchUploader(t, srv, "feature-x").WithBranchSync(upload.BranchSync{Merge: true})
+	res, err := u.UploadBatch(context.Background(), []upload.BatchUploadItem{{Params: branchUploadParams}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.HasErrors() {
+		t.Fatalf("items = %+v", res.Items)
+	}
+	calls, merges := s.snapshot()
+	if len(merges) != 1 || len(merges[0]) != 0 {
+		t.Fatalf("merge bodies = %v, want one empty body", merges)
+	}
+	if last := calls[len(calls)-1]; last != "POST /projects/"+projectID+"/branches/2/merge" {
+		t.Fatalf("last call = %q", last)
+	}
+}
+
+func TestUploader_BranchSync_MergeFailures(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not merged", func(t *testing.T) {
+		t.Parallel()
+		s, srv := newBranchServer(t, "master", "feature-x")
+		s.mergeOK = false
+		u := newBranchUploader(t, srv, "feature-x").WithBranchSync(upload.BranchSync{Merge: true})
+		pid, err := u.Upload(context.Background(), branchUploadParams, "", true)
+		if err == nil || !strings.Contains(err.Error(), "not merged") {
+			t.Fatalf("err = %v, want not merged", err)
+		}
+		if pid != "p1" {
+			t.Fatalf("process id = %q, want it returned with the merge error", pid)
+		}
+	})
+
+	t.Run("missing target", func(t *testing.T) {
+		t.Parallel()
+		_, srv := newBranchServer(t, "master", "feature-x")
+		u := newBranchUploader(t, srv, "feature-x")
+		err := u.MergeBranch(context.Background(), upload.BranchSync{MergeInto: "nope"})
+		if err == nil || !strings.Contains(err.Error(), `target branch "nope" not found`) {
+			t.Fatalf("err = %v", err)
+		}
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		t.Parallel()
+		_, srv := newBranchServer(t, "master")
+		u := newBranchUploader(t, srv, "feature-x")
+		err := u.MergeBranch(context.Background(), upload.BranchSync{})
+		if err == nil || !strings.Contains(err.Error(), "branch not found") {
+			t.Fatalf("err = %v", err)
+		}
+	})
+}
+
+func TestUploader_BranchSync_NoBranch(t *testing.T) {
+	t.Parallel()
+	s, srv := newBranchServer(t, "master")
+	u := newBranchUploader(t, srv, "")
+
+	if _, err := u.EnsureBranch(context.Background()); !errors.Is(err, upload.ErrNoBranch) {
+		t.Fatalf("EnsureBranch err = %v, want ErrNoBranch", err)
+	}
+	if err := u.MergeBranch(context.Background(), upload.BranchSync{}); !errors.Is(err, upload.ErrNoBranch) {
+		t.Fatalf("MergeBranch err = %v, want ErrNoBranch", err)
+	}
+
+	// Without a branch the policy is a no-op.
+	u = u.WithBranchSync(upload.BranchSync{Create: true, Merge: true})
+	if _, err := u.Upload(context.Background(), branchUploadParams, "", true); err != nil {
+		t.Fatal(err)
+	}
+	calls, _ := s.snapshot()
+	for _, c := range calls {
+		if strings.Contains(c, "/branches") {
+			t.Fatalf("unexpected branch call %q", c)
+		}
+	}
+}
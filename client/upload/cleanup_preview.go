@@ -0,0 +1,165 @@
+package upload
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client/keys"
+)
+
+// ErrCleanupRejected is returned by UploadWithCleanupCheck when the confirm
+// callback declines an upload that would delete keys.
+var ErrCleanupRejected = errors.New("upload: cleanup rejected")
+
+// CleanupPreview lists the remote keys that an upload with cleanup_mode
+// enabled would delete: keys assigned to Filename that are not present
+// in the local file.
+type CleanupPreview struct {
+	Filename string
+	Removed  []keys.Key
+}
+
+// CleanupConfirmFunc decides whether an upload that would delete the keys in
+// preview may proceed. It is only called when preview.Removed is non-empty.
+type CleanupConfirmFunc func(ctx context.Context, preview CleanupPreview) (bool, error)
+
+// PreviewCleanup queries the keys assigned to the remote filename and returns
+// those whose names are missing from localKeys. It never modifies the project.
+//
+// A key is considered present if any of its per-platform names is listed in
+// localKeys.
+func (u *Uploader) PreviewCleanup(ctx context.Context, filename string, localKeys []string) (CleanupPreview, error) {
+	if u == nil || u.client == nil {
+		return CleanupPreview{}, errors.New("upload: uploader/client is nil")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	filename = strings.TrimSpace(filename)
+	if filename == "" {
+		return CleanupPreview{}, errors.New("upload: cleanup preview: empty filename")
+	}
+
+	remote, err := keys.NewManager(u.client).List(ctx, keys.ListParams{
+		"filter_filenames": filename,
+	})
+	if err != nil {
+		return CleanupPreview{}, fmt.Errorf("upload: cleanup preview: %w", err)
+	}
+
+	local := make(map[string]struct{}, len(localKeys))
+	for _, k := range localKeys {
+		local[k] = struct{}{}
+	}
+
+	preview := CleanupPreview{Filename: filename}
+	for _, k := range remote {
+		if !keyPresent(k, local) {
+			preview.Removed = append(preview.Removed, k)
+		}
+	}
+	return preview, nil
+}
+
+// UploadWithCleanupCheck behaves like Upload, but when params enable
+// cleanup_mode it first runs PreviewCleanup and asks confirm before sending
+// anything. A nil confirm rejects every upload that would delete keys.
+// If confirm declines, ErrCleanupRejected is returned and nothing is uploaded.
+func (u *Uploader) UploadWithCleanupCheck(
+	ctx context.Context,
+	params UploadParams,
+	srcPath string,
+	localKeys []string,
+	confirm CleanupConfirmFunc,
+	poll bool,
+) (string, error) {
+	if u == nil || u.client == nil {
+		return "", errors.New("upload: uploader/client is nil")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if paramEnabled(params["cleanup_mode"]) {
+		filename, _ := params["filename"].(string)
+
+		preview, err := u.PreviewCleanup(ctx, filename, localKeys)
+		if err != nil {
+			return "", err
+		}
+
+		if len(preview.Removed) > 0 {
+			if confirm == nil {
+				return "", fmt.Errorf("%w: %d key(s) would be deleted", ErrCleanupRejected, len(preview.Removed))
+			}
+			ok, err := confirm(ctx, preview)
+			if err != nil {
+				return "", fmt.Errorf("upload: cleanup confirm: %w", err)
+			}
+			if !ok {
+				return "", ErrCleanupRejected
+			}
+		}
+	}
+
+	return u.Upload(ctx, params, srcPath, poll)
+}
+
+// PromptConfirm writes the keys preview would delete to w, asks whether to
+// proceed and reads the answer, one line, from r. Only "y" or "yes" (in
+// any case) confirm; an empty answer or end of input declines. Wrap it in
+// a CleanupConfirmFunc to ask on a terminal:
+//
+//	confirm := func(_ context.Context, p upload.CleanupPreview) (bool, error) {
+//		return upload.PromptConfirm(os.Stdin, os.Stderr, p)
+//	}
+func PromptConfirm(r io.Reader, w io.Writer, preview CleanupPreview) (bool, error) {
+	fmt.Fprintf(w, "Uploading %s will delete %d key(s):\n", preview.Filename, len(preview.Removed))
+	for _, k := range preview.Removed {
+		fmt.Fprintf(w, "  - %s [%d]\n", strings.Join(k.Names(), "/"), k.KeyID)
+	}
+	if _, err := fmt.Fprint(w, "Proceed? [y/N] "); err != nil {
+		return false, fmt.Errorf("upload: prompt: %w", err)
+	}
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("upload: prompt: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func keyPresent(k keys.Key, local map[string]struct{}) bool {
+	for _, name := range k.Names() {
+		if _, ok := local[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// paramEnabled reports whether a boolean-ish upload param is switched on.
+func paramEnabled(v any) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(t))
+		return err == nil && b
+	case int:
+		return t != 0
+	default:
+		return false
+	}
+}
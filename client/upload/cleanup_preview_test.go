@@ -0,0 +1,259 @@
+package upload_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/keys"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+func newCleanupTestServer(t *testing.T, uploads *int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/keys"):
+			if got := r.URL.Query().Get("filter_filenames"); got != "en.json" {
+				t.Errorf("filter_filenames = %q, want en.json", got)
+			}
+			_, _ = fmt.Fprint(w, `{"keys":[
+				{"key_id":1,"key_name":{"web":"keep"}},
+				{"key_id":2,"key_name":{"web":"stale"}},
+				{"key_id":3,"key_name":"gone"}
+			]}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/files/upload"):
+			atomic.AddInt32(uploads, 1)
+			_, _ = fmt.Fprint(w, `{"process":{"process_id":"p1"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newCleanupTestUploader(t *testing.T, srv *httptest.Server) *upload.Uploader {
+	t.Helper()
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithHTTPClient(srv.Client()),
+		client.WithMaxRetries(0),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return upload.NewUploader(c)
+}
+
+func TestUploader_PreviewCleanup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lists remote keys missing locally", func(t *testing.T) {
+		t.Parallel()
+
+		var uploads int32
+		srv := newCleanupTestServer(t, &uploads)
+		defer srv.Close()
+
+		u := newCleanupTestUploader(t, srv)
+
+		preview, err := u.PreviewCleanup(context.Background(), " en.json ", []string{"keep"})
+		if err != nil {
+			t.Fatalf("PreviewCleanup() error = %v", err)
+		}
+		if preview.Filename != "en.json" {
+			t.Fatalf("Filename = %q, want en.json", preview.Filename)
+		}
+		if len(preview.Removed) != 2 {
+			t.Fatalf("len(Removed) = %d, want 2", len(preview.Removed))
+		}
+		if preview.Removed[0].KeyID != 2 || preview.Removed[1].KeyID != 3 {
+			t.Fatalf("Removed = %#v, want key ids 2 and 3", preview.Removed)
+		}
+		if uploads != 0 {
+			t.Fatalf("uploads = %d, want 0", uploads)
+		}
+	})
+
+	t.Run("empty filename", func(t *testing.T) {
+		t.Parallel()
+
+		c, _ := client.NewClient("tok", "proj")
+		_, err := upload.NewUploader(c).PreviewCleanup(context.Background(), "  ", nil)
+		if err == nil || err.Error() != "upload: cleanup preview: empty filename" {
+			t.Fatalf("error = %v, want empty filename error", err)
+		}
+	})
+
+	t.Run("nil uploader", func(t *testing.T) {
+		t.Parallel()
+
+		var u *upload.Uploader
+		_, err := u.PreviewCleanup(context.Background(), "en.json", nil)
+		if err == nil || err.Error() != "upload: uploader/client is nil" {
+			t.Fatalf("error = %v, want nil uploader error", err)
+		}
+	})
+}
+
+func TestUploader_UploadWithCleanupCheck(t *testing.T) {
+	t.Parallel()
+
+	params := func(cleanup any) upload.UploadParams {
+		return upload.UploadParams{
+			"filename":     "en.json",
+			"lang_iso":     "en",
+			"data":         "e30=",
+			"cleanup_mode": cleanup,
+		}
+	}
+
+	t.Run("confirm receives preview and allows upload", func(t *testing.T) {
+		t.Parallel()
+
+		var uploads int32
+		srv := newCleanupTestServer(t, &uploads)
+		defer srv.Close()
+
+		u := newCleanupTestUploader(t, srv)
+
+		var seen int
+		pid, err := u.UploadWithCleanupCheck(context.Background(), params(true), "", []string{"keep", "gone"},
+			func(_ context.Context, p upload.CleanupPreview) (bool, error) {
+				seen = len(p.Removed)
+				return true, nil
+			}, false)
+		if err != nil {
+			t.Fatalf("UploadWithCleanupCheck() error = %v", err)
+		}
+		if pid != "p1" {
+			t.Fatalf("process id = %q, want p1", pid)
+		}
+		if seen != 1 {
+			t.Fatalf("confirm saw %d keys, want 1", seen)
+		}
+		if uploads != 1 {
+			t.Fatalf("uploads = %d, want 1", uploads)
+		}
+	})
+
+	t.Run("declined confirm blocks upload", func(t *testing.T) {
+		t.Parallel()
+
+		var uploads int32
+		srv := newCleanupTestServer(t, &uploads)
+		defer srv.Close()
+
+		u := newCleanupTestUploader(t, srv)
+
+		_, err := u.UploadWithCleanupCheck(context.Background(), params("true"), "", nil,
+			func(context.Context, upload.CleanupPreview) (bool, error) { return false, nil }, false)
+		if !errors.Is(err, upload.ErrCleanupRejected) {
+			t.Fatalf("error = %v, want ErrCleanupRejected", err)
+		}
+		if uploads != 0 {
+			t.Fatalf("uploads = %d, want 0", uploads)
+		}
+	})
+
+	t.Run("nil confirm rejects deletions", func(t *testing.T) {
+		t.Parallel()
+
+		var uploads int32
+		srv := newCleanupTestServer(t, &uploads)
+		defer srv.Close()
+
+		u := newCleanupTestUploader(t, srv)
+
+		_, err := u.UploadWithCleanupCheck(context.Background(), params(true), "", nil, nil, false)
+		if !errors.Is(err, upload.ErrCleanupRejected) {
+			t.Fatalf("error = %v, want ErrCleanupRejected", err)
+		}
+		if !strings.Contains(err.Error(), "3 key(s) would be deleted") {
+			t.Fatalf("error = %q, want key count", err.Error())
+		}
+		if uploads != 0 {
+			t.Fatalf("uploads = %d, want 0", uploads)
+		}
+	})
+
+	t.Run("confirm error is wrapped", func(t *testing.T) {
+		t.Parallel()
+
+		var uploads int32
+		srv := newCleanupTestServer(t, &uploads)
+		defer srv.Close()
+
+		u := newCleanupTestUploader(t, srv)
+		boom := errors.New("boom")
+
+		_, err := u.UploadWithCleanupCheck(context.Background(), params(true), "", nil,
+			func(context.Context, upload.CleanupPreview) (bool, error) { return false, boom }, false)
+		if !errors.Is(err, boom) {
+			t.Fatalf("error = %v, want wrapped boom", err)
+		}
+	})
+
+	t.Run("no cleanup skips preview", func(t *testing.T) {
+		t.Parallel()
+
+		var uploads int32
+		srv := newCleanupTestServer(t, &uploads)
+		defer srv.Close()
+
+		u := newCleanupTestUploader(t, srv)
+
+		pid, err := u.UploadWithCleanupCheck(context.Background(), params(false), "", nil, nil, false)
+		if err != nil {
+			t.Fatalf("UploadWithCleanupCheck() error = %v", err)
+		}
+		if pid != "p1" || uploads != 1 {
+			t.Fatalf("pid = %q uploads = %d, want p1 and 1", pid, uploads)
+		}
+	})
+}
+
+func TestPromptConfirm(t *testing.T) {
+	t.Parallel()
+
+	preview := upload.CleanupPreview{Filename: "en.json", Removed: []keys.Key{
+		{KeyID: 2, KeyName: keys.PlatformStrings{Web: "stale"}},
+		{KeyID: 3, KeyName: keys.PlatformStrings{IOS: "old_ios", Web: "old.web"}},
+	}}
+
+	for _, tc := range []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{" YES \r\n", true},
+		{"yes", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+		{"yeah\n", false},
+	} {
+		var out strings.Builder
+		ok, err := upload.PromptConfirm(strings.NewReader(tc.input), &out, preview)
+		if err != nil || ok != tc.want {
+			t.Errorf("PromptConfirm(%q) = %v, %v; want %v", tc.input, ok, err, tc.want)
+		}
+		want := "Uploading en.json will delete 2 key(s):\n" +
+			"  - stale [2]\n" +
+			"  - old_ios/old.web [3]\n" +
+			"Proceed? [y/N] "
+		if out.String() != want {
+			t.Fatalf("prompt =\n%q\nwant\n%q", out.String(), want)
+		}
+	}
+}
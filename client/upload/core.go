@@ -16,12 +16,21 @@ import (
 	"strings"
 
 	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/internal/telemetry"
+	"github.com/bodrovis/lokex/v2/internal/utils"
 )
 
 // Uploader wraps a *Client to perform Lokalise file uploads.
 // Construct with NewUploader; the embedded client must be non-nil.
 type Uploader struct {
-	client *client.Client
+	client         *client.Client
+	importConflict ImportConflictPolicy // see WithImportConflict
+	branchSync     BranchSync           // see WithBranchSync
+	filenameTpl    string               // see WithFilenameTemplate
+	filenameVars   map[string]string
+	pushLock       PushLock   // see WithPushLock
+	maxBodySize    int64      // 0: DefaultMaxBodySize, negative: unchecked; see WithMaxBodySize
+	data           dataFilter // see WithEncoding, WithLineEndings
 }
 
 // UploadParams represents the JSON body for /files/upload.
@@ -38,6 +47,8 @@ type uploadBodyFactory struct {
 	ctx      context.Context
 	params   UploadParams
 	readPath string
+	data     dataFilter
+	codec    utils.Codec // encodes the params; see client.WithCodec
 }
 
 type uploadDataSpec struct {
@@ -45,10 +56,11 @@ type uploadDataSpec struct {
 	dataWasBytes bool
 	dataString   string
 	dataBytes    []byte
+	filter       dataFilter
 }
 
 func (f uploadBodyFactory) NewBody() (io.ReadCloser, error) {
-	return newUploadBody(f.ctx, f.params, f.readPath)
+	return newUploadBody(f.ctx, f.codec, f.params, f.readPath, f.data)
 }
 
 var kickoffUploadStreamingFn = func(
@@ -85,16 +97,114 @@ var ErrNoProcessID = errors.New("upload: no process id returned")
 // If poll is true, it will call PollProcesses on that process and only return
 // when the process reaches "finished" (otherwise it errors). If poll is false,
 // it returns immediately after kickoff with the process id.
-func (u *Uploader) Upload(ctx context.Context, params UploadParams, srcPath string, poll bool) (string, error) {
+//
+// With WithBranchSync, the branch is created first and, after a successful
+// polled upload, merged; a failed merge is returned with the process id.
+//
+// opts override client settings for this call only (see
+// client.WithRequestOptions).
+func (u *Uploader) Upload(ctx context.Context, params UploadParams, srcPath string, poll bool, opts ...client.RequestOption) (string, error) {
+	res, err := u.WithRequestOptions(opts...).upload(ctx, params, srcPath, poll)
+	return res.ProcessID, err
+}
+
+// UploadWithResult is Upload with poll=true that also returns what Lokalise
+// reported for each imported file, including warnings such as skipped keys.
+// Warnings don't make it fail; check UploadResult.Warnings.
+func (u *Uploader) UploadWithResult(ctx context.Context, params UploadParams, srcPath string, opts ...client.RequestOption) (UploadResult, error) {
+	return u.WithRequestOptions(opts...).upload(ctx, params, srcPath, true)
+}
+
+// WithRequestOptions returns a copy of u whose calls all apply opts (see
+// client.WithRequestOptions), or u itself when opts is empty.
+func (u *Uploader) WithRequestOptions(opts ...client.RequestOption) *Uploader {
+	if u == nil || u.client == nil || len(opts) == 0 {
+		return u
+	}
+	cp := *u
+	cp.client = u.client.WithRequestOptions(opts...)
+	return &cp
+}
+
+func (u *Uploader) upload(ctx context.Context, params UploadParams, srcPath string, poll bool) (_ UploadResult, err error) {
+	if err := validateUploadSingleInput(u, ctx); err != nil {
+		return UploadResult{}, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	filename, _ := params["filename"].(string)
+	ctx, span := telemetry.Start(ctx, u.client.Tracer(), telemetry.SpanUpload,
+		telemetry.AttrFilename.String(filename),
+		telemetry.AttrUploadPoll.Bool(poll),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	unlock, err := u.lockPush(ctx)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	defer unlock()
+
+	if err := ignoreDryRun(u.prepareBranch(ctx)); err != nil {
+		return UploadResult{}, err
+	}
+
 	processID, err := u.uploadSingle(ctx, params, srcPath, poll)
+	if errors.Is(err, client.ErrDryRun) {
+		return UploadResult{}, nil // recorded; see client.WithDryRun
+	}
 	if err != nil {
-		return "", err
+		return UploadResult{}, err
 	}
+	span.SetAttributes(telemetry.AttrProcessID.String(processID))
 
+	var warnings []string
+	if _, warning := inspectFormat(params, srcPath); warning != nil {
+		warnings = []string{warning.Error()}
+	}
 	if !poll {
-		return processID, nil
+		u.client.RecordPush()
+		return UploadResult{ProcessID: processID, Warnings: warnings}, nil
 	}
-	return u.pollUntilFinished(ctx, processID)
+	res, err := u.pollResult(ctx, processID)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	res.Warnings = append(warnings, res.Warnings...)
+	u.client.RecordPush()
+	if err := u.finishBranch(ctx); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// Enqueue starts an upload and returns a handle to its process without
+// waiting for the import to complete. Call Wait on the handle, or pass its ID
+// (now or later, from any process) to Client.WaitAll, to verify the outcome.
+// Unlike Upload with poll=false, a response without a process ID is reported
+// as ErrNoProcessID.
+func (u *Uploader) Enqueue(ctx context.Context, params UploadParams, srcPath string) (*client.Process, error) {
+	if err := validateUploadSingleInput(u, ctx); err != nil {
+		return nil, err
+	}
+	unlock, err := u.lockPush(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := u.prepareBranch(ctx); err != nil {
+		return nil, err
+	}
+	processID, err := u.uploadSingle(ctx, params, srcPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if processID == "" {
+		return nil, ErrNoProcessID
+	}
+	return u.client.Process(processID), nil
 }
 
 func (u *Uploader) uploadSingle(ctx context.Context, params UploadParams, srcPath string, poll bool) (string, error) {
@@ -115,6 +225,13 @@ func (u *Uploader) uploadSingle(ctx context.Context, params UploadParams, srcPat
 		return "", err
 	}
 
+	if _, _, err := resolveUploadFormat(body, filename, readPath); err != nil {
+		return "", err
+	}
+	if err := u.checkBodySize(body, readPath, filename); err != nil {
+		return "", err
+	}
+
 	processID, err := kickoffUploadStreamingFn(u, ctx, body, readPath)
 	if err != nil {
 		return handleUploadKickoffError(err, poll)
@@ -123,6 +240,15 @@ func (u *Uploader) uploadSingle(ctx context.Context, params UploadParams, srcPat
 	return processID, nil
 }
 
+// ignoreDryRun drops client.ErrDryRun, for steps whose recorded request
+// doesn't keep the upload from going on.
+func ignoreDryRun(err error) error {
+	if errors.Is(err, client.ErrDryRun) {
+		return nil
+	}
+	return err
+}
+
 func validateUploadSingleInput(u *Uploader, ctx context.Context) error {
 	if u == nil || u.client == nil {
 		return errors.New("upload: uploader/client is nil")
@@ -18,8 +18,11 @@ import (
 
 	"github.com/bodrovis/lokex/v2/client"
 	"github.com/bodrovis/lokex/v2/client/upload"
-	"github.com/bodrovis/lokex/v2/internal/testutils"
+	"github.com/bodrovis/lokex/v2/testutils"
 	"github.com/jarcoal/httpmock"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 var (
@@ -697,7 +700,7 @@ func TestUploader_Upload_ContextCancelDuringBodyBuild(t *testing.T) {
 	})
 
 	cli, _ := client.NewClient(token, projectID, nil)
-	u := upload.NewUploader(cli)
+	u := upload.NewUploader(cli).WithMaxBodySize(0) // the body must be big enough to be slow
 
 	dir := t.TempDir()
 	fp := filepath.Join(dir, "f.json")
@@ -872,3 +875,64 @@ func TestUploader_Upload_ReadFilePermissionDeniedAfterStat(t *testing.T) {
 		t.Fatalf("want permission denied, got %v", err)
 	}
 }
+
+func TestUploader_Upload_TracingSpan(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	targetPost := fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/files/upload", projectID)
+	httpmock.RegisterResponder("POST", targetPost,
+		httpmock.NewStringResponder(200, `{"process":{"process_id":"upl_789"}}`))
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	cli, err := client.NewClient(token, projectID, client.WithTracerProvider(tp))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = upload.NewUploader(cli).Upload(context.Background(), upload.UploadParams{
+		"filename": "en.json",
+		"data":     "e30=",
+		"lang_iso": "en",
+	}, "", false)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	var up sdktrace.ReadOnlySpan
+	var attemptChild bool
+	for _, s := range rec.Ended() {
+		if s.Name() == "lokex.upload" {
+			up = s
+		}
+	}
+	if up == nil {
+		t.Fatal("no lokex.upload span")
+	}
+	for _, s := range rec.Ended() {
+		if s.Name() == "lokex.attempt" && s.Parent().SpanID() == up.SpanContext().SpanID() {
+			attemptChild = true
+		}
+	}
+	if !attemptChild {
+		t.Error("upload request attempts are not children of the upload span")
+	}
+
+	want := map[attribute.Key]string{
+		"lokex.upload.filename": "en.json",
+		"lokex.upload.poll":     "false",
+		"lokex.process.id":      "upl_789",
+	}
+	for _, kv := range up.Attributes() {
+		if w, ok := want[kv.Key]; ok {
+			if kv.Value.Emit() != w {
+				t.Errorf("%s = %q, want %q", kv.Key, kv.Value.Emit(), w)
+			}
+			delete(want, kv.Key)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing attributes: %v", want)
+	}
+}
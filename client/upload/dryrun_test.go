@@ -0,0 +1,70 @@
+package upload_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+func TestUploader_DryRun(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.Error(w, "no", http.StatusTeapot)
+	}))
+	t.Cleanup(srv.Close)
+
+	var log client.DryRunLog
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithDryRun(&log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := upload.NewUploader(cli)
+
+	src := filepath.Join(t.TempDir(), "en.json")
+	if err := os.WriteFile(src, []byte(`{"hello":"Hello"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	params := upload.UploadParams{"filename": "en.json", "lang_iso": "en"}
+
+	id, err := u.Upload(context.Background(), params, src, true)
+	if err != nil || id != "" {
+		t.Fatalf("Upload() = %q, %v; want \"\", nil", id, err)
+	}
+	res, err := u.UploadBatch(context.Background(), []upload.BatchUploadItem{
+		{Params: upload.UploadParams{"filename": "en.json", "lang_iso": "en"}, SrcPath: src},
+		{Params: upload.UploadParams{"filename": "fr.json", "lang_iso": "fr"}, SrcPath: src},
+	}, true)
+	if err != nil || res.HasErrors() {
+		t.Fatalf("UploadBatch() = %+v, %v", res, err)
+	}
+	if !cli.Health().LastPush.IsZero() {
+		t.Fatal("dry run recorded a push")
+	}
+
+	reqs := log.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("recorded = %d, want 3", len(reqs))
+	}
+	for _, r := range reqs {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.Path, "/files/upload") {
+			t.Fatalf("recorded %s %s", r.Method, r.Path)
+		}
+	}
+	if !strings.Contains(string(reqs[0].Body), `"lang_iso":"en"`) {
+		t.Fatalf("body = %s", reqs[0].Body)
+	}
+
+	// Validation still runs.
+	if _, err := u.Upload(context.Background(), upload.UploadParams{"filename": "missing.json"}, "", false); err == nil {
+		t.Fatal("Upload(missing file) error = nil in dry-run mode")
+	}
+}
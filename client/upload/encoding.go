@@ -0,0 +1,121 @@
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/internal/eol"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Encoding decides how file data (a local file or a []byte "data" param)
+// is normalized before it is base64-encoded. A base64 "data" string is
+// sent as given.
+type Encoding int
+
+const (
+	// EncodingStripBOM drops a leading UTF-8 byte order mark, which
+	// Lokalise would otherwise import as part of the first key (the
+	// default).
+	EncodingStripBOM Encoding = iota
+	// EncodingUTF8 also transcodes UTF-16 content starting with a byte
+	// order mark, little- or big-endian, to UTF-8. Apple .strings files
+	// are often saved that way, and Lokalise imports them as mangled text.
+	EncodingUTF8
+	// EncodingRaw sends file data byte for byte.
+	EncodingRaw
+)
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// WithEncoding returns a copy of u that normalizes file data according to
+// e before uploading it.
+func (u *Uploader) WithEncoding(e Encoding) *Uploader {
+	if u == nil {
+		return nil
+	}
+	cp := *u
+	cp.data.encoding = e
+	return &cp
+}
+
+// WithLineEndings returns a copy of u that rewrites the line endings of
+// file data to le before uploading it, so files edited on Windows and
+// elsewhere upload the same. UTF-16 data is only rewritten once
+// EncodingUTF8 has transcoded it.
+func (u *Uploader) WithLineEndings(le client.LineEnding) *Uploader {
+	if u == nil {
+		return nil
+	}
+	cp := *u
+	cp.data.lineEnding = le
+	return &cp
+}
+
+// dataFilter is how file data is normalized before it is base64-encoded.
+type dataFilter struct {
+	encoding   Encoding
+	lineEnding client.LineEnding
+}
+
+func (f dataFilter) reader(r io.Reader) io.Reader {
+	return eol.NewReader(normalizeReader(r, f.encoding), f.lineEnding)
+}
+
+// dataLen returns how many bytes f.reader yields for r, which holds size
+// bytes.
+func (f dataFilter) dataLen(r io.Reader, size int64) (int64, error) {
+	if f.lineEnding == client.LineEndingKeep {
+		return normalizedLen(r, size, f.encoding)
+	}
+	return io.Copy(io.Discard, f.reader(r))
+}
+
+// normalizeReader returns r with the byte order mark stripped and, for
+// EncodingUTF8, UTF-16 transcoded.
+func normalizeReader(r io.Reader, e Encoding) io.Reader {
+	if e == EncodingRaw {
+		return r
+	}
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(utf8BOM)) // a read error resurfaces on Read
+	switch {
+	case bytes.HasPrefix(head, utf8BOM):
+		_, _ = br.Discard(len(utf8BOM))
+	case e == EncodingUTF8 && isUTF16BOM(head):
+		dec := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()
+		return transform.NewReader(br, dec)
+	}
+	return br
+}
+
+// normalizedLen returns how many bytes normalizeReader yields for r, which
+// holds size bytes. Only UTF-16 content is read through.
+func normalizedLen(r io.Reader, size int64, e Encoding) (int64, error) {
+	if e == EncodingRaw {
+		return size, nil
+	}
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(utf8BOM))
+	switch {
+	case bytes.HasPrefix(head, utf8BOM):
+		return size - int64(len(utf8BOM)), nil
+	case e == EncodingUTF8 && isUTF16BOM(head):
+		return io.Copy(io.Discard, normalizeReader(br, e))
+	case err != nil && err != io.EOF:
+		return 0, err
+	}
+	return size, nil
+}
+
+func isUTF16BOM(head []byte) bool {
+	return bytes.HasPrefix(head, utf16LEBOM) || bytes.HasPrefix(head, utf16BEBOM)
+}
@@ -0,0 +1,144 @@
+package upload_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+// utf16LE encodes s as UTF-16LE with a byte order mark.
+func utf16LE(s string) []byte {
+	b := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+// utf16BE encodes s as UTF-16BE with a byte order mark.
+func utf16BE(s string) []byte {
+	b := []byte{0xFE, 0xFF}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.BigEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+// uploadedData decodes the "data" field of an upload body.
+func uploadedData(t *testing.T, body []byte) []byte {
+	t.Helper()
+	var payload struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(payload.Data)
+	if err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	return data
+}
+
+func TestEncoding_Normalizes(t *testing.T) {
+	t.Parallel()
+
+	const text = `"greeting" = "Grüße 日本 😀";`
+	bom := append([]byte{0xEF, 0xBB, 0xBF}, text...)
+	le, be := utf16LE(text), utf16BE(text)
+	invalid := []byte{'a', 0xFF, 'b'}
+
+	tests := []struct {
+		name string
+		in   []byte
+		want map[upload.Encoding][]byte
+	}{
+		{"UTF-8 BOM", bom, map[upload.Encoding][]byte{
+			upload.EncodingStripBOM: []byte(text), upload.EncodingUTF8: []byte(text), upload.EncodingRaw: bom,
+		}},
+		{"UTF-16LE", le, map[upload.Encoding][]byte{
+			upload.EncodingStripBOM: le, upload.EncodingUTF8: []byte(text), upload.EncodingRaw: le,
+		}},
+		{"UTF-16BE", be, map[upload.Encoding][]byte{
+			upload.EncodingStripBOM: be, upload.EncodingUTF8: []byte(text), upload.EncodingRaw: be,
+		}},
+		{"plain", []byte(text), map[upload.Encoding][]byte{
+			upload.EncodingStripBOM: []byte(text), upload.EncodingUTF8: []byte(text), upload.EncodingRaw: []byte(text),
+		}},
+		{"invalid UTF-8 kept", invalid, map[upload.Encoding][]byte{
+			upload.EncodingStripBOM: invalid, upload.EncodingUTF8: invalid, upload.EncodingRaw: invalid,
+		}},
+		{"short", []byte{0xEF}, map[upload.Encoding][]byte{
+			upload.EncodingStripBOM: {0xEF}, upload.EncodingUTF8: {0xEF}, upload.EncodingRaw: {0xEF},
+		}},
+	}
+	for _, tt := range tests {
+		for enc, want := range tt.want {
+			params := upload.UploadParams{"filename": "en.strings", "data": tt.in}
+			rc, err := upload.ExportNewUploadBodyFiltered(context.Background(), params, "", enc, client.LineEndingKeep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(rc)
+			_ = rc.Close()
+			if err != nil {
+				t.Fatalf("%s/%d: read body: %v", tt.name, enc, err)
+			}
+			if got := uploadedData(t, body); !bytes.Equal(got, want) {
+				t.Errorf("%s/%d: data = %q, want %q", tt.name, enc, got, want)
+			}
+		}
+	}
+}
+
+func TestUploader_WithEncoding(t *testing.T) {
+	t.Parallel()
+
+	bodies := make(chan []byte, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+		_, _ = io.WriteString(w, `{"process":{"process_id":"upl_1"}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const text = `"title" = "Привет";`
+	path := filepath.Join(t.TempDir(), "Localizable.strings")
+	if err := os.WriteFile(path, utf16LE(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	params := upload.UploadParams{"filename": "Localizable.strings", "lang_iso": "ru"}
+
+	base := upload.NewUploader(cli)
+	if _, err := base.WithEncoding(upload.EncodingUTF8).Upload(context.Background(), params, path, false); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if got := uploadedData(t, <-bodies); string(got) != text {
+		t.Fatalf("uploaded data = %q, want %q", got, text)
+	}
+
+	// The original uploader only strips a UTF-8 BOM, so UTF-16 goes as is.
+	if _, err := base.Upload(context.Background(), params, path, false); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if got := uploadedData(t, <-bodies); !bytes.Equal(got, utf16LE(text)) {
+		t.Fatalf("uploaded data = %q, want UTF-16 unchanged", got)
+	}
+}
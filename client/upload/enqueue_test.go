@@ -0,0 +1,103 @@
+package upload_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestUploader_Enqueue_ThenWaitAll(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	base := fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/", projectID)
+	kickoffs := 0
+	httpmock.RegisterResponder("POST", base+"files/upload", func(*http.Request) (*http.Response, error) {
+		kickoffs++
+		return httpmock.NewStringResponse(200, fmt.Sprintf(`{"process":{"process_id":"upl_%d"}}`, kickoffs)), nil
+	})
+	httpmock.RegisterResponder("GET", base+"processes/upl_1",
+		httpmock.NewStringResponder(200, `{"process":{"process_id":"upl_1","status":"finished"}}`))
+	httpmock.RegisterResponder("GET", base+"processes/upl_2",
+		httpmock.NewStringResponder(200, `{"process":{"process_id":"upl_2","status":"failed","message":"bad file"}}`))
+
+	cli, err := client.NewClient(token, projectID, client.WithPollWait(time.Millisecond, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := upload.NewUploader(cli)
+	ctx := context.Background()
+
+	var ids []string
+	for _, lang := range []string{"en", "fr"} {
+		proc, err := u.Enqueue(ctx, upload.UploadParams{
+			"filename": lang + ".json",
+			"data":     "e30=",
+			"lang_iso": lang,
+		}, "")
+		if err != nil {
+			t.Fatalf("Enqueue(%s) error = %v", lang, err)
+		}
+		ids = append(ids, proc.ID)
+	}
+	if httpmock.GetTotalCallCount() != 2 {
+		t.Fatalf("calls after Enqueue = %d, want 2 (no polling)", httpmock.GetTotalCallCount())
+	}
+
+	first := cli.Process(ids[0])
+	if st, err := first.Wait(ctx); err != nil || st.Status != client.ProcessFinished {
+		t.Fatalf("Wait() = %+v, %v; want finished", st, err)
+	}
+
+	procs, err := cli.WaitAll(ctx, ids)
+	if err != nil {
+		t.Fatalf("WaitAll() error = %v", err)
+	}
+	if len(procs) != 2 {
+		t.Fatalf("len(procs) = %d, want 2", len(procs))
+	}
+	if procs[0].ProcessID != "upl_1" || procs[0].Status != client.ProcessFinished {
+		t.Fatalf("procs[0] = %+v, want upl_1 finished", procs[0])
+	}
+	if procs[1].ProcessID != "upl_2" || procs[1].Status != client.ProcessFailed || procs[1].Message != "bad file" {
+		t.Fatalf("procs[1] = %+v, want upl_2 failed", procs[1])
+	}
+}
+
+func TestUploader_Enqueue_NoProcessID(t *testing.T) {
+	restore := upload.ExportSetKickoffUploadStreamingForTest(
+		func(*upload.Uploader, context.Context, upload.UploadParams, string) (string, error) {
+			return "", upload.ErrNoProcessID
+		},
+	)
+	defer restore()
+
+	cli, err := client.NewClient(token, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = upload.NewUploader(cli).Enqueue(context.Background(), upload.UploadParams{
+		"filename": "en.json",
+		"data":     "e30=",
+	}, "")
+	if !errors.Is(err, upload.ErrNoProcessID) {
+		t.Fatalf("Enqueue() error = %v, want ErrNoProcessID", err)
+	}
+}
+
+func TestUploader_Enqueue_NilUploader(t *testing.T) {
+	t.Parallel()
+
+	var u *upload.Uploader
+	if _, err := u.Enqueue(context.Background(), upload.UploadParams{}, ""); err == nil {
+		t.Fatal("Enqueue() error = nil, want error")
+	}
+}
@@ -23,7 +23,7 @@ func ExportPollUntilFinished(u *Uploader, ctx context.Context, processID string)
 }
 
 func ExportNewUploadBody(ctx context.Context, params UploadParams, cleanPath string) (io.ReadCloser, error) {
-	return newUploadBody(ctx, params, cleanPath)
+	return newUploadBody(ctx, nil, params, cleanPath, dataFilter{})
 }
 
 func ExportEnsureFileIsRegular(readPath string) error {
@@ -39,11 +39,11 @@ func ExportSetStatFileForTest(fn func(string) (os.FileInfo, error)) func() {
 }
 
 func ExportWriteUploadJSON(w *bufio.Writer, params UploadParams, cleanPath string, spec uploadDataSpec) error {
-	return writeUploadJSON(w, params, cleanPath, spec)
+	return writeUploadJSON(w, nil, params, cleanPath, spec)
 }
 
 func ExportWriteUploadKV(w *bufio.Writer, k string, v any, first *bool) error {
-	return writeUploadKV(w, k, v, first)
+	return writeUploadKV(w, nil, k, v, first)
 }
 
 func ExportWriteUploadData(w *bufio.Writer, cleanPath string, spec uploadDataSpec) error {
@@ -203,3 +203,26 @@ func ExportKickoffBatchUploadItemForTest(
 ) {
 	u.kickoffBatchUploadItem(ctx, sem, item, result)
 }
+
+func ExportSetGitForTest(
+	run func(ctx context.Context, dir string, args ...string) (string, error),
+	env func(string) string,
+) func() {
+	prevRun, prevEnv := runGitFn, lookupEnvFn
+	runGitFn, lookupEnvFn = run, env
+	return func() {
+		runGitFn, lookupEnvFn = prevRun, prevEnv
+	}
+}
+
+func ExportUploadBodySize(params UploadParams, readPath string, enc Encoding, le client.LineEnding) (int64, error) {
+	return uploadBodySize(nil, params, readPath, dataFilter{encoding: enc, lineEnding: le})
+}
+
+func ExportNewUploadBodyFiltered(ctx context.Context, params UploadParams, cleanPath string, enc Encoding, le client.LineEnding) (io.ReadCloser, error) {
+	return newUploadBody(ctx, nil, params, cleanPath, dataFilter{encoding: enc, lineEnding: le})
+}
+
+func ExportUploadBodySizeWithCodec(codec client.Codec, params UploadParams) (int64, error) {
+	return uploadBodySize(codec, params, "", dataFilter{})
+}
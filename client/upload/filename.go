@@ -0,0 +1,174 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"unicode"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// maxFilenameLen is the longest remote filename Lokalise stores.
+const maxFilenameLen = 255
+
+// ErrInvalidFilename is matched (via errors.Is) by errors from
+// ValidateFilename and ExpandFilename.
+var ErrInvalidFilename = errors.New("upload: invalid remote filename")
+
+// ValidateFilename checks a remote filename against the rules Lokalise
+// applies to uploads: a relative, slash-separated path of at most 255 bytes
+// without empty, "." or ".." segments, backslashes or control characters.
+// Lokalise placeholders such as %LANG_ISO% are allowed.
+func ValidateFilename(name string) error {
+	bad := func(why string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidFilename, name, why)
+	}
+	switch {
+	case strings.TrimSpace(name) == "":
+		return bad("empty")
+	case len(name) > maxFilenameLen:
+		return bad(fmt.Sprintf("longer than %d bytes", maxFilenameLen))
+	case strings.HasPrefix(name, "/"):
+		return bad("absolute path")
+	case strings.Contains(name, `\`):
+		return bad("backslash; use / to separate directories")
+	case strings.ContainsFunc(name, unicode.IsControl):
+		return bad("control character")
+	case strings.ContainsAny(name, "{}"):
+		return bad("unexpanded template braces")
+	}
+	for seg := range strings.SplitSeq(name, "/") {
+		switch seg {
+		case "":
+			return bad("empty path segment")
+		case ".", "..":
+			return bad(fmt.Sprintf("%q path segment", seg))
+		}
+	}
+	return nil
+}
+
+// ExpandFilename replaces every {name} placeholder in tpl with vars[name]
+// and validates the result with ValidateFilename. Names are letters, digits
+// and underscores; an unknown name, an empty value or an unbalanced brace is
+// an error.
+func ExpandFilename(tpl string, vars map[string]string) (string, error) {
+	var b strings.Builder
+	rest := tpl
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			b.WriteString(rest)
+			break
+		}
+		if rest[open] == '}' {
+			return "", fmt.Errorf("%w: template %q: unbalanced '}'", ErrInvalidFilename, tpl)
+		}
+		b.WriteString(rest[:open])
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("%w: template %q: unclosed '{'", ErrInvalidFilename, tpl)
+		}
+		name := rest[open+1 : open+end]
+		if !validVarName(name) {
+			return "", fmt.Errorf("%w: template %q: bad placeholder {%s}", ErrInvalidFilename, tpl, name)
+		}
+		val, ok := vars[name]
+		if !ok {
+			return "", fmt.Errorf("%w: template %q: unknown placeholder {%s}", ErrInvalidFilename, tpl, name)
+		}
+		if strings.TrimSpace(val) == "" {
+			return "", fmt.Errorf("%w: template %q: empty value for {%s}", ErrInvalidFilename, tpl, name)
+		}
+		b.WriteString(val)
+		rest = rest[open+end+1:]
+	}
+
+	out := b.String()
+	if err := ValidateFilename(out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func validVarName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithFilenameTemplate returns a copy of u whose UploadReader names uploads
+// without a "filename" param by expanding tpl (e.g. "{app}/{lang}.json").
+// vars supplies the placeholders; {lang} defaults to the call's lang_iso.
+func (u *Uploader) WithFilenameTemplate(tpl string, vars map[string]string) *Uploader {
+	if u == nil {
+		return nil
+	}
+	cp := *u
+	cp.filenameTpl = tpl
+	cp.filenameVars = maps.Clone(vars)
+	return &cp
+}
+
+// UploadReader uploads generated content read from r, like Upload with the
+// bytes in "data". The remote filename is params["filename"] or, if absent,
+// the template set with WithFilenameTemplate; either way it is checked with
+// ValidateFilename before anything is sent. r is read to the end. opts work
+// as in Upload.
+func (u *Uploader) UploadReader(ctx context.Context, r io.Reader, params UploadParams, poll bool, opts ...client.RequestOption) (string, error) {
+	if err := validateUploadSingleInput(u, ctx); err != nil {
+		return "", err
+	}
+	if r == nil {
+		return "", errors.New("upload: nil reader")
+	}
+
+	name, err := u.readerFilename(params)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("upload: read content: %w", err)
+	}
+
+	body := maps.Clone(params)
+	if body == nil {
+		body = UploadParams{}
+	}
+	body["filename"] = name
+	body["data"] = data
+	return u.Upload(ctx, body, "", poll, opts...)
+}
+
+func (u *Uploader) readerFilename(params UploadParams) (string, error) {
+	if raw, ok := params["filename"]; ok {
+		name, _ := raw.(string)
+		name = strings.TrimSpace(name)
+		if err := ValidateFilename(name); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	if u.filenameTpl == "" {
+		return "", errors.New("upload: missing 'filename' param and no filename template")
+	}
+
+	vars := make(map[string]string, len(u.filenameVars)+1)
+	if lang, _ := params["lang_iso"].(string); strings.TrimSpace(lang) != "" {
+		vars["lang"] = strings.TrimSpace(lang)
+	}
+	maps.Copy(vars, u.filenameVars)
+	return ExpandFilename(u.filenameTpl, vars)
+}
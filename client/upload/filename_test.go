@@ -0,0 +1,181 @@
+package upload_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+func TestValidateFilename(t *testing.T) {
+	t.Parallel()
+
+	ok := []string{"en.json", "web/en.json", "%LANG_ISO%/app.yml", "a b/ü.json"}
+	for _, name := range ok {
+		if err := upload.ValidateFilename(name); err != nil {
+			t.Errorf("ValidateFilename(%q) = %v, want nil", name, err)
+		}
+	}
+
+	bad := map[string]string{
+		"":                       "empty",
+		"  ":                     "empty",
+		"/abs/en.json":           "absolute",
+		`web\en.json`:            "backslash",
+		"web//en.json":           "empty path segment",
+		"web/":                   "empty path segment",
+		"../en.json":             `".." path segment`,
+		"./en.json":              `"." path segment`,
+		"en\n.json":              "control character",
+		"{app}/en.json":          "braces",
+		strings.Repeat("a", 256): "longer than 255",
+	}
+	for name, want := range bad {
+		err := upload.ValidateFilename(name)
+		if !errors.Is(err, upload.ErrInvalidFilename) || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateFilename(%q) = %v, want ErrInvalidFilename mentioning %q", name, err, want)
+		}
+	}
+}
+
+func TestExpandFilename(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]string{"app": "web", "lang": "fr", "blank": " ", "up": ".."}
+	got, err := upload.ExpandFilename("{app}/{lang}.json", vars)
+	if err != nil || got != "web/fr.json" {
+		t.Fatalf("ExpandFilename = %q, %v; want web/fr.json", got, err)
+	}
+	if got, err := upload.ExpandFilename("static.json", nil); err != nil || got != "static.json" {
+		t.Fatalf("no placeholders: %q, %v", got, err)
+	}
+
+	bad := map[string]string{
+		"{app/en.json":    "unclosed",
+		"app}/en.json":    "unbalanced",
+		"{}/en.json":      "bad placeholder",
+		"{a-b}/en.json":   "bad placeholder",
+		"{nope}/en.json":  "unknown placeholder {nope}",
+		"{blank}/en.json": "empty value for {blank}",
+		"{up}/en.json":    `".." path segment`,
+	}
+	for tpl, want := range bad {
+		_, err := upload.ExpandFilename(tpl, vars)
+		if !errors.Is(err, upload.ErrInvalidFilename) || !strings.Contains(err.Error(), want) {
+			t.Errorf("ExpandFilename(%q) = %v, want ErrInvalidFilename mentioning %q", tpl, err, want)
+		}
+	}
+}
+
+// uploadCaptureServer accepts uploads and records their JSON bodies.
+func uploadCaptureServer(t *testing.T) (*upload.Uploader, func() []map[string]any) {
+	t.Helper()
+
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode upload body: %v", err)
+		}
+		mu.Lock()
+		bodies = append(bodies, body)
+		n := len(bodies)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"process":{"process_id":"p%d"}}`, n)
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return upload.NewUploader(cli), func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		return bodies
+	}
+}
+
+func TestUploader_UploadReader_Template(t *testing.T) {
+	t.Parallel()
+	u, bodies := uploadCaptureServer(t)
+	u = u.WithFilenameTemplate("{app}/{lang}.json", map[string]string{"app": "web"})
+
+	for _, lang := range []string{"en", "fr"} {
+		content := `{"hello":"` + lang + `"}`
+		if _, err := u.UploadReader(context.Background(), strings.NewReader(content),
+			upload.UploadParams{"lang_iso": lang}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := bodies()
+	if len(got) != 2 {
+		t.Fatalf("uploads = %d, want 2", len(got))
+	}
+	for i, lang := range []string{"en", "fr"} {
+		if got[i]["filename"] != "web/"+lang+".json" || got[i]["lang_iso"] != lang {
+			t.Fatalf("upload %d = %v", i, got[i])
+		}
+		data, _ := base64.StdEncoding.DecodeString(got[i]["data"].(string))
+		if string(data) != `{"hello":"`+lang+`"}` {
+			t.Fatalf("upload %d data = %s", i, data)
+		}
+	}
+}
+
+func TestUploader_UploadReader_ExplicitFilename(t *testing.T) {
+	t.Parallel()
+	u, bodies := uploadCaptureServer(t)
+	u = u.WithFilenameTemplate("{app}/{lang}.json", nil)
+
+	if _, err := u.UploadReader(context.Background(), strings.NewReader("a: b"),
+		upload.UploadParams{"filename": "generated/en.yml", "lang_iso": "en"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := bodies(); len(got) != 1 || got[0]["filename"] != "generated/en.yml" {
+		t.Fatalf("bodies = %v", got)
+	}
+}
+
+func TestUploader_UploadReader_RejectedBeforeSending(t *testing.T) {
+	t.Parallel()
+	u, bodies := uploadCaptureServer(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		name   string
+		up     *upload.Uploader
+		params upload.UploadParams
+		want   string
+	}{
+		{"invalid explicit", u, upload.UploadParams{"filename": "../en.json"}, `".." path segment`},
+		{"no filename or template", u, upload.UploadParams{"lang_iso": "en"}, "no filename template"},
+		{"missing lang", u.WithFilenameTemplate("{lang}.json", nil), upload.UploadParams{}, "unknown placeholder {lang}"},
+	}
+	for _, tc := range cases {
+		_, err := tc.up.UploadReader(ctx, strings.NewReader("{}"), tc.params, false)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+	if _, err := u.UploadReader(ctx, nil, upload.UploadParams{"filename": "en.json"}, false); err == nil {
+		t.Error("nil reader: expected error")
+	}
+	if n := len(bodies()); n != 0 {
+		t.Fatalf("%d uploads sent, want none", n)
+	}
+}
@@ -0,0 +1,183 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedFormat is returned when the "format" param is empty or
+	// not a well-formed Lokalise format name.
+	ErrUnsupportedFormat = errors.New("upload: unsupported format")
+
+	// ErrFormatMismatch describes a "format" param that disagrees with the
+	// format inferred from the file extension or content. Uploads go ahead;
+	// the mismatch is reported in UploadResult.Warnings and
+	// BatchUploadResultItem.Warnings.
+	ErrFormatMismatch = errors.New("upload: format does not match file")
+)
+
+// formatAliases maps file extensions (and accepted spellings of the "format"
+// param) to the canonical Lokalise format name. It only lists what can be
+// inferred from a file; Lokalise accepts more formats (json_structured,
+// ios_sdk, ...), which pass through unchanged.
+var formatAliases = map[string]string{
+	"arb":         "arb",
+	"csv":         "csv",
+	"docx":        "docx",
+	"htm":         "html",
+	"html":        "html",
+	"ini":         "ini",
+	"js":          "js",
+	"json":        "json",
+	"php":         "php",
+	"plist":       "plist",
+	"po":          "po",
+	"pot":         "po",
+	"properties":  "properties",
+	"resx":        "resx",
+	"srt":         "srt",
+	"strings":     "strings",
+	"stringsdict": "stringsdict",
+	"ts":          "ts",
+	"xcstrings":   "xcstrings",
+	"xlf":         "xliff",
+	"xliff":       "xliff",
+	"xlsx":        "xlsx",
+	"xml":         "xml",
+	"yaml":        "yml",
+	"yml":         "yml",
+}
+
+// sniffLimit is how many leading bytes of a file are inspected.
+const sniffLimit = 512
+
+// ValidateFormat normalizes a "format" value: known spellings get their
+// canonical name (e.g. "yaml" -> "yml"), and other well-formed names, such
+// as "json_structured", are returned lowercased for Lokalise to judge. Only
+// empty values and names with characters no format uses are rejected.
+func ValidateFormat(format string) (string, error) {
+	f := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+	if canon, ok := formatAliases[f]; ok {
+		return canon, nil
+	}
+	if f == "" || strings.ContainsFunc(f, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' && r != '-'
+	}) {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	return f, nil
+}
+
+// InferFormat guesses the Lokalise format of a file from its extension and,
+// when the extension is missing or unknown, from its leading bytes. It
+// returns "" when neither gives a confident answer.
+func InferFormat(filename string, head []byte) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(strings.TrimSpace(filename)), "."))
+	if canon, ok := formatAliases[ext]; ok {
+		return canon
+	}
+	return sniffFormat(head)
+}
+
+func sniffFormat(head []byte) string {
+	b := bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")))
+	if len(b) == 0 {
+		return ""
+	}
+
+	switch b[0] {
+	case '{', '[':
+		return "json"
+	case '<':
+		lower := bytes.ToLower(b)
+		switch {
+		case bytes.Contains(lower, []byte("<xliff")):
+			return "xliff"
+		case bytes.Contains(lower, []byte("<plist")):
+			return "plist"
+		case bytes.Contains(lower, []byte("<resources")):
+			return "xml"
+		case bytes.Contains(b, []byte("<TS")):
+			return "ts"
+		case bytes.Contains(lower, []byte("<html")), bytes.HasPrefix(lower, []byte("<!doctype html")):
+			return "html"
+		}
+		return "xml"
+	}
+
+	switch {
+	case bytes.HasPrefix(b, []byte("---")):
+		return "yml"
+	case bytes.HasPrefix(b, []byte("msgid")), bytes.Contains(b, []byte("\nmsgid ")):
+		return "po"
+	}
+	return ""
+}
+
+// resolveUploadFormat returns the format an upload will be imported as. An
+// explicit "format" param is validated; if it is one lokex can infer and the
+// file looks like another, the mismatch comes back as a warning, not an
+// error. Without the param the format is inferred, and "" means unknown.
+func resolveUploadFormat(params UploadParams, filename, readPath string) (format string, warning error, err error) {
+	inferred := InferFormat(filename, readHead(readPath))
+
+	raw, ok := params["format"]
+	if !ok {
+		return inferred, nil, nil
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("upload: 'format' must be a string")
+	}
+	format, err = ValidateFormat(s)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, known := formatAliases[format]; known && inferred != "" && inferred != format {
+		warning = fmt.Errorf("%w: format %q, but %q looks like %q", ErrFormatMismatch, format, filename, inferred)
+	}
+	return format, warning, nil
+}
+
+// inspectFormat is resolveUploadFormat for an upload's params and source
+// path before they are validated; invalid values are left for uploadSingle
+// to reject.
+func inspectFormat(params UploadParams, srcPath string) (format string, warning error) {
+	filename, _ := params["filename"].(string)
+	filename = strings.TrimSpace(filename)
+
+	readPath := ""
+	if _, hasData := params["data"]; !hasData {
+		readPath = strings.TrimSpace(srcPath)
+		if readPath == "" {
+			readPath = filename
+		}
+	}
+
+	format, warning, _ = resolveUploadFormat(params, filename, readPath)
+	return format, warning
+}
+
+// readHead returns up to sniffLimit leading bytes of path, or nil if it
+// cannot be read.
+func readHead(path string) []byte {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, sniffLimit)
+	n, _ := io.ReadFull(f, buf)
+	return buf[:n]
+}
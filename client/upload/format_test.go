@@ -0,0 +1,202 @@
+package upload_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+func TestValidateFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "json", want: "json"},
+		{in: " .YAML ", want: "yml"},
+		{in: "xlf", want: "xliff"},
+		{in: "strings", want: "strings"},
+		{in: "json_structured", want: "json_structured"},
+		{in: " iOS_SDK ", want: "ios_sdk"},
+		{in: "", wantErr: true},
+		{in: "json; rm", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := upload.ValidateFormat(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, upload.ErrUnsupportedFormat) {
+				t.Errorf("ValidateFormat(%q) error = %v, want ErrUnsupportedFormat", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ValidateFormat(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestInferFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		head     string
+		want     string
+	}{
+		{name: "extension wins", filename: "en.json", head: "<resources/>", want: "json"},
+		{name: "alias extension", filename: "locales/en.YAML", want: "yml"},
+		{name: "json content", filename: "en.txt", head: "\xef\xbb\xbf  {\"a\": 1}", want: "json"},
+		{name: "android xml", filename: "strings", head: `<?xml version="1.0"?><resources>`, want: "xml"},
+		{name: "xliff content", filename: "", head: `<?xml version="1.0"?><xliff version="1.2">`, want: "xliff"},
+		{name: "plist content", filename: "x", head: `<?xml version="1.0"?><plist>`, want: "plist"},
+		{name: "qt ts content", filename: "x", head: `<?xml version="1.0"?><TS version="2.1">`, want: "ts"},
+		{name: "yaml content", filename: "x", head: "---\nen:\n", want: "yml"},
+		{name: "po content", filename: "x", head: "# comment\nmsgid \"\"\n", want: "po"},
+		{name: "unknown", filename: "notes.txt", head: "hello", want: ""},
+		{name: "empty", filename: "", head: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := upload.InferFormat(tt.filename, []byte(tt.head)); got != tt.want {
+			t.Errorf("%s: InferFormat(%q) = %q, want %q", tt.name, tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestUploader_Upload_FormatValidation(t *testing.T) {
+	var kicked int
+	restore := upload.ExportSetKickoffUploadStreamingForTest(
+		func(*upload.Uploader, context.Context, upload.UploadParams, string) (string, error) {
+			kicked++
+			return "pid", nil
+		},
+	)
+	defer restore()
+
+	cli, err := client.NewClient(token, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := upload.NewUploader(cli)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "en.json")
+	if err := os.WriteFile(src, []byte(`{"a":"b"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = u.Upload(context.Background(), upload.UploadParams{"filename": "en.json", "format": "json; rm"}, src, false)
+	if !errors.Is(err, upload.ErrUnsupportedFormat) {
+		t.Fatalf("malformed: error = %v, want ErrUnsupportedFormat", err)
+	}
+
+	_, err = u.Upload(context.Background(), upload.UploadParams{"filename": "en.json", "format": 1}, src, false)
+	if err == nil || !strings.Contains(err.Error(), "'format' must be a string") {
+		t.Fatalf("non-string: error = %v", err)
+	}
+	if kicked != 0 {
+		t.Fatalf("kickoffs = %d, want 0 for invalid formats", kicked)
+	}
+
+	pid, err := u.Upload(context.Background(), upload.UploadParams{"filename": "en.json", "format": "JSON"}, src, false)
+	if err != nil || pid != "pid" {
+		t.Fatalf("matching format: Upload() = %q, %v", pid, err)
+	}
+
+	// A format lokex doesn't list goes to Lokalise as is.
+	pid, err = u.Upload(context.Background(), upload.UploadParams{"filename": "en.json", "format": "json_structured"}, src, false)
+	if err != nil || pid != "pid" {
+		t.Fatalf("json_structured: Upload() = %q, %v", pid, err)
+	}
+}
+
+func TestUploader_Upload_FormatMismatchWarns(t *testing.T) {
+	restoreKick := upload.ExportSetKickoffUploadStreamingForTest(
+		func(*upload.Uploader, context.Context, upload.UploadParams, string) (string, error) {
+			return "pid", nil
+		},
+	)
+	defer restoreKick()
+	restorePoll := upload.ExportSetPollProcessesForTest(
+		func(context.Context, []string, *client.Client) ([]upload.ExportQueuedProcessForTest, error) {
+			return []upload.ExportQueuedProcessForTest{{ProcessID: "pid", Status: "finished"}}, nil
+		},
+	)
+	defer restorePoll()
+
+	cli, err := client.NewClient(token, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(t.TempDir(), "en.json")
+	if err := os.WriteFile(src, []byte(`{"a":"b"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	u := upload.NewUploader(cli)
+
+	for _, filename := range []string{"en.json", "en"} { // extension, then content
+		res, err := u.UploadWithResult(context.Background(), upload.UploadParams{"filename": filename, "format": "xml"}, src)
+		if err != nil {
+			t.Fatalf("%s: UploadWithResult() error = %v, want the upload to go ahead", filename, err)
+		}
+		if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], upload.ErrFormatMismatch.Error()) {
+			t.Fatalf("%s: warnings = %q, want the format mismatch", filename, res.Warnings)
+		}
+	}
+}
+
+func TestUploader_UploadBatch_ReportsFormat(t *testing.T) {
+	restore := upload.ExportSetBatchUploadSingleForTest(
+		func(*upload.Uploader, context.Context, upload.UploadParams, string) (string, error) {
+			return "pid", nil
+		},
+	)
+	defer restore()
+
+	cli, err := client.NewClient(token, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	sniffed := filepath.Join(dir, "android_strings")
+	if err := os.WriteFile(sniffed, []byte(`<?xml version="1.0"?><resources></resources>`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	items := []upload.BatchUploadItem{
+		{Params: upload.UploadParams{"filename": "en.yaml"}},
+		{Params: upload.UploadParams{"filename": "strings"}, SrcPath: sniffed},
+		{Params: upload.UploadParams{"filename": "app", "format": "xlf", "data": "PHhsaWZmLz4="}},
+		{Params: upload.UploadParams{"filename": "notes.txt"}},
+		{Params: upload.UploadParams{"filename": "fr.json", "format": "json_structured", "data": "e30="}},
+		{Params: upload.UploadParams{"filename": "de.json", "format": "xml", "data": "e30="}},
+	}
+
+	res, err := upload.NewUploader(cli).UploadBatch(context.Background(), items, false)
+	if err != nil {
+		t.Fatalf("UploadBatch() error = %v", err)
+	}
+
+	want := []string{"yml", "xml", "xliff", "", "json_structured", "xml"}
+	for i, w := range want {
+		if got := res.Items[i].Format; got != w {
+			t.Errorf("Items[%d].Format = %q, want %q", i, got, w)
+		}
+	}
+	if w := res.Items[5].Warnings; len(w) != 1 || !strings.Contains(w[0], upload.ErrFormatMismatch.Error()) {
+		t.Errorf("Items[5].Warnings = %q, want the format mismatch", w)
+	}
+	if w := res.Items[4].Warnings; len(w) != 0 {
+		t.Errorf("Items[4].Warnings = %q, want none for json_structured", w)
+	}
+}
@@ -0,0 +1,142 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GitMetadata identifies the source revision an upload was made from.
+type GitMetadata struct {
+	Commit string // full commit SHA
+	Branch string // "" for a detached HEAD
+}
+
+// gitCommitTagLen is how many SHA characters go into the commit tag.
+const gitCommitTagLen = 12
+
+var runGitFn = func(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+var lookupEnvFn = os.Getenv
+
+// DetectGit reads the current commit and branch of the repository containing
+// dir ("" means the working directory). When git is unavailable, or HEAD is
+// detached as on most CI runners, the GitHub Actions and GitLab CI variables
+// (GITHUB_SHA, GITHUB_REF_NAME, CI_COMMIT_SHA, CI_COMMIT_REF_NAME) are used.
+func DetectGit(ctx context.Context, dir string) (GitMetadata, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if strings.TrimSpace(dir) == "" {
+		dir = "."
+	}
+
+	var m GitMetadata
+	commit, gitErr := runGitFn(ctx, dir, "rev-parse", "HEAD")
+	if gitErr == nil {
+		m.Commit = commit
+		if branch, err := runGitFn(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+			m.Branch = branch
+		}
+	}
+
+	if m.Commit == "" {
+		m.Commit = firstEnv("GITHUB_SHA", "CI_COMMIT_SHA")
+	}
+	if m.Branch == "" {
+		m.Branch = firstEnv("GITHUB_REF_NAME", "CI_COMMIT_REF_NAME")
+	}
+
+	if m.Commit == "" {
+		if gitErr == nil {
+			gitErr = errors.New("empty commit")
+		}
+		return GitMetadata{}, fmt.Errorf("upload: detect git: %w", gitErr)
+	}
+	return m, nil
+}
+
+func firstEnv(names ...string) string {
+	for _, n := range names {
+		if v := strings.TrimSpace(lookupEnvFn(n)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Tags returns the Lokalise tags for m: "git-commit:<sha prefix>" and, when
+// known, "git-branch:<branch>".
+func (m GitMetadata) Tags() []string {
+	var tags []string
+	if c := strings.TrimSpace(m.Commit); c != "" {
+		tags = append(tags, "git-commit:"+c[:min(len(c), gitCommitTagLen)])
+	}
+	if b := strings.TrimSpace(m.Branch); b != "" {
+		tags = append(tags, "git-branch:"+b)
+	}
+	return tags
+}
+
+// Note returns a one-line description of the revision, suitable for a key
+// description or comment, e.g. "source: main@3f2a9c1d0b7e".
+func (m GitMetadata) Note() string {
+	tags := m.Tags()
+	if len(tags) == 0 {
+		return ""
+	}
+	ref := strings.TrimPrefix(tags[0], "git-commit:")
+	if b := strings.TrimSpace(m.Branch); b != "" {
+		ref = b + "@" + ref
+	}
+	return "source: " + ref
+}
+
+// WithGitTags returns a copy of params that tags the keys an upload inserts or
+// updates with m.Tags(). Existing "tags" are kept; tag_inserted_keys and
+// tag_updated_keys default to true unless already set.
+func WithGitTags(params UploadParams, m GitMetadata) UploadParams {
+	out := make(UploadParams, len(params)+3)
+	maps.Copy(out, params)
+
+	gitTags := m.Tags()
+	if len(gitTags) == 0 {
+		return out
+	}
+
+	var tags []any
+	switch v := out["tags"].(type) {
+	case []string:
+		for _, t := range v {
+			tags = append(tags, t)
+		}
+	case []any:
+		tags = append(tags, v...)
+	case string:
+		if v != "" {
+			tags = append(tags, v)
+		}
+	}
+	for _, t := range gitTags {
+		tags = append(tags, t)
+	}
+	out["tags"] = tags
+
+	for _, k := range []string{"tag_inserted_keys", "tag_updated_keys"} {
+		if _, ok := out[k]; !ok {
+			out[k] = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package upload_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+const testSHA = "3f2a9c1d0b7e4a5f6c7d8e9f0a1b2c3d4e5f6a7b"
+
+func fakeGit(branch string, err error) func(context.Context, string, ...string) (string, error) {
+	return func(_ context.Context, _ string, args ...string) (string, error) {
+		if err != nil {
+			return "", err
+		}
+		if strings.Join(args, " ") == "rev-parse --abbrev-ref HEAD" {
+			return branch, nil
+		}
+		return testSHA, nil
+	}
+}
+
+func envMap(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+func TestDetectGit(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(context.Context, string, ...string) (string, error)
+		env     map[string]string
+		want    upload.GitMetadata
+		wantErr bool
+	}{
+		{
+			name: "branch checkout",
+			run:  fakeGit("main", nil),
+			env:  map[string]string{"GITHUB_REF_NAME": "ignored"},
+			want: upload.GitMetadata{Commit: testSHA, Branch: "main"},
+		},
+		{
+			name: "detached head uses ci branch",
+			run:  fakeGit("HEAD", nil),
+			env:  map[string]string{"CI_COMMIT_REF_NAME": "feature/x"},
+			want: upload.GitMetadata{Commit: testSHA, Branch: "feature/x"},
+		},
+		{
+			name: "no git falls back to env",
+			run:  fakeGit("", errors.New("exec: git not found")),
+			env:  map[string]string{"GITHUB_SHA": "abc", "GITHUB_REF_NAME": "main"},
+			want: upload.GitMetadata{Commit: "abc", Branch: "main"},
+		},
+		{
+			name:    "nothing available",
+			run:     fakeGit("", errors.New("not a git repository")),
+			env:     map[string]string{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := upload.ExportSetGitForTest(tt.run, envMap(tt.env))
+			defer restore()
+
+			got, err := upload.DetectGit(context.Background(), "")
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "not a git repository") {
+					t.Fatalf("DetectGit() error = %v, want git error", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("DetectGit() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitMetadata_TagsAndNote(t *testing.T) {
+	t.Parallel()
+
+	m := upload.GitMetadata{Commit: testSHA, Branch: "main"}
+	if got, want := m.Tags(), []string{"git-commit:3f2a9c1d0b7e", "git-branch:main"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tags() = %v, want %v", got, want)
+	}
+	if got := m.Note(); got != "source: main@3f2a9c1d0b7e" {
+		t.Fatalf("Note() = %q", got)
+	}
+
+	short := upload.GitMetadata{Commit: "abc"}
+	if got := short.Note(); got != "source: abc" {
+		t.Fatalf("Note() without branch = %q", got)
+	}
+	if tags := (upload.GitMetadata{}).Tags(); tags != nil {
+		t.Fatalf("empty Tags() = %v, want nil", tags)
+	}
+}
+
+func TestWithGitTags(t *testing.T) {
+	t.Parallel()
+
+	m := upload.GitMetadata{Commit: testSHA, Branch: "main"}
+	params := upload.UploadParams{"filename": "en.json", "tags": []string{"release"}, "tag_updated_keys": false}
+
+	got := upload.WithGitTags(params, m)
+	want := upload.UploadParams{
+		"filename":          "en.json",
+		"tags":              []any{"release", "git-commit:3f2a9c1d0b7e", "git-branch:main"},
+		"tag_inserted_keys": true,
+		"tag_updated_keys":  false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("WithGitTags() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(params["tags"], []string{"release"}) {
+		t.Fatalf("input params modified: %v", params)
+	}
+
+	if got := upload.WithGitTags(params, upload.GitMetadata{}); !reflect.DeepEqual(got, params) {
+		t.Fatalf("WithGitTags(empty) = %v, want unchanged copy", got)
+	}
+}
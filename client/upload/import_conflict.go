@@ -0,0 +1,144 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+// ErrImportInProgress is matched (via errors.Is) by an *ImportInProgressError.
+var ErrImportInProgress = errors.New("upload: import already in progress")
+
+// ImportInProgressError lists the imports that were still running when an
+// upload refused to start.
+type ImportInProgressError struct {
+	ProcessIDs []string
+}
+
+func (e *ImportInProgressError) Error() string {
+	return fmt.Sprintf("upload: %d import(s) already in progress: %s", len(e.ProcessIDs), strings.Join(e.ProcessIDs, ", "))
+}
+
+func (e *ImportInProgressError) Is(target error) bool { return target == ErrImportInProgress }
+
+// ImportConflictPolicy decides what UploadBatch does when the project
+// already has imports running (started by another job, a teammate, or an
+// earlier run), which would otherwise interleave with the batch.
+type ImportConflictPolicy int
+
+const (
+	// ImportConflictIgnore starts the batch without checking (the default).
+	ImportConflictIgnore ImportConflictPolicy = iota
+	// ImportConflictWait waits for the imports running at check time to
+	// finish, then starts the batch.
+	ImportConflictWait
+	// ImportConflictQueue waits until no import is running at all, so
+	// imports started while waiting also go first.
+	ImportConflictQueue
+	// ImportConflictFail returns an *ImportInProgressError instead of
+	// starting the batch.
+	ImportConflictFail
+)
+
+// importProcessType is the Lokalise process type of file uploads.
+const importProcessType = "file-import"
+
+var finishedProcessStatuses = []string{"finished", "failed", "cancelled", "canceled"}
+
+// WithImportConflict returns a copy of u whose UploadBatch checks for running
+// imports first and handles them according to p.
+func (u *Uploader) WithImportConflict(p ImportConflictPolicy) *Uploader {
+	if u == nil {
+		return nil
+	}
+	cp := *u
+	cp.importConflict = p
+	return &cp
+}
+
+// RunningImports returns the project's file imports that have not finished
+// yet, as reported by GET /processes.
+func (u *Uploader) RunningImports(ctx context.Context) ([]client.QueuedProcess, error) {
+	if u == nil || u.client == nil {
+		return nil, errors.New("upload: uploader/client is nil")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var resp struct {
+		Processes []struct {
+			ProcessID string `json:"process_id" lokex:"required"`
+			Type      string `json:"type"`
+			Status    string `json:"status"`
+			Message   string `json:"message"`
+		} `json:"processes" lokex:"required"`
+	}
+	path := utils.ProjectPath(u.client.ProjectID, "processes")
+	if err := u.client.DoJSONWithRetry(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, fmt.Errorf("upload: list processes: %w", err)
+	}
+
+	var out []client.QueuedProcess
+	for _, p := range resp.Processes {
+		status := utils.NormalizeString(p.Status)
+		if utils.NormalizeString(p.Type) != importProcessType || slices.Contains(finishedProcessStatuses, status) {
+			continue
+		}
+		out = append(out, client.QueuedProcess{
+			ProcessID: p.ProcessID,
+			Status:    status,
+			Message:   strings.TrimSpace(p.Message),
+		})
+	}
+	return out, nil
+}
+
+// CheckImports applies p once: it returns nil when the upload may start,
+// after waiting if p says so.
+func (u *Uploader) CheckImports(ctx context.Context, p ImportConflictPolicy) error {
+	if p == ImportConflictIgnore {
+		return nil
+	}
+
+	for {
+		running, err := u.RunningImports(ctx)
+		if err != nil {
+			return err
+		}
+		if len(running) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(running))
+		for i, r := range running {
+			ids[i] = r.ProcessID
+		}
+		if p == ImportConflictFail {
+			return &ImportInProgressError{ProcessIDs: ids}
+		}
+
+		procs, err := pollProcessesFn(ctx, ids, u.client)
+		if err != nil {
+			return fmt.Errorf("upload: wait for running imports: %w", err)
+		}
+		var still []string
+		for _, proc := range procs {
+			if !slices.Contains(finishedProcessStatuses, proc.Status) {
+				still = append(still, proc.ProcessID)
+			}
+		}
+		if len(still) > 0 {
+			return fmt.Errorf("still running after the poll budget: %w", &ImportInProgressError{ProcessIDs: still})
+		}
+		if p == ImportConflictWait {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package upload_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+type fakeProcess struct {
+	ProcessID string `json:"process_id"`
+	Type      string `json:"type"`
+	Status    string `json:"status"`
+}
+
+// processesServer serves GET /projects/{id}/processes, returning lists[i] on
+// the i-th call (and the last list afterwards).
+func processesServer(t *testing.T, lists ...[]fakeProcess) (*upload.Uploader, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/projects/"+projectID+"/processes") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		i := min(int(calls.Add(1))-1, len(lists)-1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"processes": lists[i]})
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return upload.NewUploader(cli), &calls
+}
+
+func TestUploader_RunningImports(t *testing.T) {
+	u, _ := processesServer(t, []fakeProcess{
+		{ProcessID: "a", Type: "file-import", Status: "running"},
+		{ProcessID: "b", Type: "file-import", Status: "finished"},
+		{ProcessID: "c", Type: "file-export", Status: "queued"},
+		{ProcessID: "d", Type: "File-Import", Status: "Queued"},
+		{ProcessID: "e", Type: "file-import", Status: "cancelled"},
+	})
+
+	got, err := u.RunningImports(context.Background())
+	if err != nil {
+		t.Fatalf("RunningImports() error = %v", err)
+	}
+	want := []client.QueuedProcess{
+		{ProcessID: "a", Status: "running"},
+		{ProcessID: "d", Status: "queued"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RunningImports() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUploader_CheckImports(t *testing.T) {
+	running := []fakeProcess{{ProcessID: "p1", Type: "file-import", Status: "running"}}
+
+	t.Run("fail", func(t *testing.T) {
+		u, _ := processesServer(t, running)
+
+		err := u.CheckImports(context.Background(), upload.ImportConflictFail)
+		if !errors.Is(err, upload.ErrImportInProgress) {
+			t.Fatalf("error = %v, want ErrImportInProgress", err)
+		}
+		var ie *upload.ImportInProgressError
+		if !errors.As(err, &ie) || !reflect.DeepEqual(ie.ProcessIDs, []string{"p1"}) {
+			t.Fatalf("ImportInProgressError = %+v", ie)
+		}
+	})
+
+	t.Run("nothing running", func(t *testing.T) {
+		u, calls := processesServer(t, nil)
+
+		if err := u.CheckImports(context.Background(), upload.ImportConflictFail); err != nil {
+			t.Fatalf("CheckImports() error = %v", err)
+		}
+		if calls.Load() != 1 {
+			t.Fatalf("list calls = %d, want 1", calls.Load())
+		}
+	})
+
+	t.Run("ignore does not call the API", func(t *testing.T) {
+		u, calls := processesServer(t, running)
+
+		if err := u.CheckImports(context.Background(), upload.ImportConflictIgnore); err != nil {
+			t.Fatalf("CheckImports() error = %v", err)
+		}
+		if calls.Load() != 0 {
+			t.Fatalf("list calls = %d, want 0", calls.Load())
+		}
+	})
+
+	t.Run("wait polls the running imports once", func(t *testing.T) {
+		var polled [][]string
+		restore := upload.ExportSetPollProcessesForTest(func(_ context.Context, ids []string, _ *client.Client) ([]upload.ExportQueuedProcessForTest, error) {
+			polled = append(polled, ids)
+			return []upload.ExportQueuedProcessForTest{{ProcessID: ids[0], Status: "finished"}}, nil
+		})
+		defer restore()
+
+		u, calls := processesServer(t, running, []fakeProcess{{ProcessID: "p2", Type: "file-import", Status: "queued"}})
+
+		if err := u.CheckImports(context.Background(), upload.ImportConflictWait); err != nil {
+			t.Fatalf("CheckImports() error = %v", err)
+		}
+		if calls.Load() != 1 || !reflect.DeepEqual(polled, [][]string{{"p1"}}) {
+			t.Fatalf("list calls = %d, polled = %v", calls.Load(), polled)
+		}
+	})
+
+	t.Run("queue waits until nothing is running", func(t *testing.T) {
+		var polled [][]string
+		restore := upload.ExportSetPollProcessesForTest(func(_ context.Context, ids []string, _ *client.Client) ([]upload.ExportQueuedProcessForTest, error) {
+			polled = append(polled, ids)
+			return []upload.ExportQueuedProcessForTest{{ProcessID: ids[0], Status: "failed"}}, nil
+		})
+		defer restore()
+
+		u, calls := processesServer(t, running, []fakeProcess{{ProcessID: "p2", Type: "file-import", Status: "queued"}}, nil)
+
+		if err := u.CheckImports(context.Background(), upload.ImportConflictQueue); err != nil {
+			t.Fatalf("CheckImports() error = %v", err)
+		}
+		if calls.Load() != 3 || !reflect.DeepEqual(polled, [][]string{{"p1"}, {"p2"}}) {
+			t.Fatalf("list calls = %d, polled = %v", calls.Load(), polled)
+		}
+	})
+
+	t.Run("wait budget exhausted", func(t *testing.T) {
+		restore := upload.ExportSetPollProcessesForTest(func(_ context.Context, ids []string, _ *client.Client) ([]upload.ExportQueuedProcessForTest, error) {
+			return []upload.ExportQueuedProcessForTest{{ProcessID: ids[0], Status: "running"}}, nil
+		})
+		defer restore()
+
+		u, _ := processesServer(t, running)
+
+		err := u.CheckImports(context.Background(), upload.ImportConflictWait)
+		if !errors.Is(err, upload.ErrImportInProgress) || !strings.Contains(err.Error(), "still running") {
+			t.Fatalf("error = %v, want still-running ErrImportInProgress", err)
+		}
+	})
+
+	t.Run("poll error", func(t *testing.T) {
+		restore := upload.ExportSetPollProcessesForTest(func(context.Context, []string, *client.Client) ([]upload.ExportQueuedProcessForTest, error) {
+			return nil, errors.New("poll boom")
+		})
+		defer restore()
+
+		u, _ := processesServer(t, running)
+
+		err := u.CheckImports(context.Background(), upload.ImportConflictWait)
+		if err == nil || !strings.Contains(err.Error(), "poll boom") {
+			t.Fatalf("error = %v, want poll error", err)
+		}
+	})
+}
+
+func TestUploader_UploadBatch_ImportConflict(t *testing.T) {
+	var kicked atomic.Int32
+	restore := upload.ExportSetBatchUploadSingleForTest(
+		func(*upload.Uploader, context.Context, upload.UploadParams, string) (string, error) {
+			kicked.Add(1)
+			return "proc", nil
+		},
+	)
+	defer restore()
+
+	u, _ := processesServer(t, []fakeProcess{{ProcessID: "p1", Type: "file-import", Status: "running"}})
+	items := []upload.BatchUploadItem{{Params: upload.UploadParams{"filename": "en.json", "data": "dGVzdA=="}}}
+
+	_, err := u.WithImportConflict(upload.ImportConflictFail).UploadBatch(context.Background(), items, false)
+	if !errors.Is(err, upload.ErrImportInProgress) {
+		t.Fatalf("UploadBatch() error = %v, want ErrImportInProgress", err)
+	}
+	if kicked.Load() != 0 {
+		t.Fatal("uploads were started despite a running import")
+	}
+
+	// The original uploader is unaffected by WithImportConflict.
+	if _, err := u.UploadBatch(context.Background(), items, false); err != nil {
+		t.Fatalf("UploadBatch() error = %v", err)
+	}
+	if kicked.Load() != 1 {
+		t.Fatalf("kickoffs = %d, want 1", kicked.Load())
+	}
+}
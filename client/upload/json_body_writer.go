@@ -4,24 +4,25 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"io"
 	"os"
+
+	"github.com/bodrovis/lokex/v2/internal/utils"
 )
 
 var openFile = func(name string) (io.ReadCloser, error) {
 	return os.Open(name)
 }
 
-func writeUploadJSON(w *bufio.Writer, params UploadParams, cleanPath string, spec uploadDataSpec) error {
+func writeUploadJSON(w *bufio.Writer, codec utils.Codec, params UploadParams, cleanPath string, spec uploadDataSpec) error {
 	// Manually build JSON to avoid buffering the whole payload in memory.
 	if _, err := w.WriteString("{"); err != nil {
 		return err
 	}
 
 	first := true
-	if err := writeUploadParams(w, params, &first); err != nil {
+	if err := writeUploadParams(w, codec, params, &first); err != nil {
 		return err
 	}
 	if err := writeUploadDataField(w, cleanPath, spec, &first); err != nil {
@@ -32,13 +33,13 @@ func writeUploadJSON(w *bufio.Writer, params UploadParams, cleanPath string, spe
 	return err
 }
 
-func writeUploadParams(w *bufio.Writer, params UploadParams, first *bool) error {
+func writeUploadParams(w *bufio.Writer, codec utils.Codec, params UploadParams, first *bool) error {
 	// Write all params except "data" (handled separately for streaming).
 	for k, v := range params {
 		if k == "data" {
 			continue
 		}
-		if err := writeUploadKV(w, k, v, first); err != nil {
+		if err := writeUploadKV(w, codec, k, v, first); err != nil {
 			return err
 		}
 	}
@@ -70,16 +71,13 @@ func writeUploadComma(w *bufio.Writer, first *bool) error {
 	return err
 }
 
-func writeUploadKV(w *bufio.Writer, k string, v any, first *bool) error {
+func writeUploadKV(w *bufio.Writer, codec utils.Codec, k string, v any, first *bool) error {
 	// Write "key":value pair with proper comma handling.
 	if err := writeUploadComma(w, first); err != nil {
 		return err
 	}
 
-	// json.Marshal(string) cannot fail
-	kb, _ := json.Marshal(k)
-
-	vb, err := json.Marshal(v)
+	kb, vb, err := marshalUploadKV(codec, k, v)
 	if err != nil {
 		return err
 	}
@@ -94,6 +92,23 @@ func writeUploadKV(w *bufio.Writer, k string, v any, first *bool) error {
 	return err
 }
 
+// marshalUploadKV encodes a param's key and value with codec (StdCodec if
+// nil), without the encoder's trailing newline.
+func marshalUploadKV(codec utils.Codec, k string, v any) ([]byte, []byte, error) {
+	codec = utils.CodecOrDefault(codec)
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf)
+	if err := enc.Encode(k); err != nil {
+		return nil, nil, err
+	}
+	n := buf.Len()
+	if err := enc.Encode(v); err != nil {
+		return nil, nil, err
+	}
+	b := buf.Bytes()
+	return bytes.TrimSuffix(b[:n], []byte("\n")), bytes.TrimSuffix(b[n:], []byte("\n")), nil
+}
+
 func writeUploadData(w *bufio.Writer, cleanPath string, spec uploadDataSpec) error {
 	// If caller already provided base64 string, write it directly.
 	if !spec.useFile && !spec.dataWasBytes {
@@ -109,7 +124,7 @@ func writeUploadData(w *bufio.Writer, cleanPath string, spec uploadDataSpec) err
 
 	enc := base64.NewEncoder(base64.StdEncoding, w)
 
-	_, err = io.Copy(enc, r)
+	_, err = io.Copy(enc, spec.filter.reader(r))
 	if closeFn != nil {
 		err = joinErr(err, closeFn())
 	}
@@ -14,8 +14,8 @@ import (
 // UploadResponse mirrors the minimal shape we expect from /files/upload.
 type UploadResponse struct {
 	Process struct {
-		ProcessID string `json:"process_id"`
-	} `json:"process"`
+		ProcessID string `json:"process_id" lokex:"required"`
+	} `json:"process" lokex:"required"`
 }
 
 // Read exists only so uploadBodyFactory satisfies io.Reader.
@@ -44,6 +44,8 @@ func (u *Uploader) kickoffUploadStreaming(ctx context.Context, body UploadParams
 		ctx:      ctx,
 		params:   body,
 		readPath: cleanPath,
+		data:     u.data,
+		codec:    u.client.Codec,
 	}
 
 	if err := u.client.DoJSONWithRetry(ctx, http.MethodPost, path, factory, &resp); err != nil {
@@ -0,0 +1,80 @@
+package upload_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+func TestUploader_WithLineEndings(t *testing.T) {
+	t.Parallel()
+
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+		_, _ = io.WriteString(w, `{"process":{"process_id":"upl_1"}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name string
+		u    *upload.Uploader
+		path string
+		want string
+	}{
+		{
+			name: "CRLF to LF",
+			u:    upload.NewUploader(cli).WithLineEndings(client.LineEndingLF),
+			path: write("en.json", []byte("{\r\n  \"a\": \"b\"\r\n}\r\n")),
+			want: "{\n  \"a\": \"b\"\n}\n",
+		},
+		{
+			name: "LF to CRLF",
+			u:    upload.NewUploader(cli).WithLineEndings(client.LineEndingCRLF),
+			path: write("fr.json", []byte("{\n  \"a\": \"b\"\r\n}")),
+			want: "{\r\n  \"a\": \"b\"\r\n}",
+		},
+		{
+			name: "UTF-16 after transcoding",
+			u:    upload.NewUploader(cli).WithEncoding(upload.EncodingUTF8).WithLineEndings(client.LineEndingLF),
+			path: write("Localizable.strings", utf16LE("\"a\" = \"ü\";\r\n\"b\" = \"c\";\r\n")),
+			want: "\"a\" = \"ü\";\n\"b\" = \"c\";\n",
+		},
+		{
+			name: "UTF-16 left alone without transcoding",
+			u:    upload.NewUploader(cli).WithLineEndings(client.LineEndingLF),
+			path: write("InfoPlist.strings", utf16LE("\"a\" = \"b\";\r\n")),
+			want: string(utf16LE("\"a\" = \"b\";\r\n")),
+		},
+	}
+	for _, tt := range tests {
+		params := upload.UploadParams{"filename": filepath.Base(tt.path), "lang_iso": "en"}
+		if _, err := tt.u.Upload(context.Background(), params, tt.path, false); err != nil {
+			t.Fatalf("%s: Upload() error = %v", tt.name, err)
+		}
+		if got := string(uploadedData(t, <-bodies)); got != tt.want {
+			t.Errorf("%s: uploaded data = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -19,24 +19,74 @@ var pollProcessesFn = func(
 	return background.PollProcesses(ctx, processIDs, c)
 }
 
+// UploadResult is the outcome of a finished upload: what Lokalise reported
+// for each imported file.
+type UploadResult struct {
+	ProcessID string
+	Files     []client.ProcessFile
+	// Warnings lists a "format" param that disagrees with the file (see
+	// ErrFormatMismatch), then every file's warnings (skipped keys, invalid
+	// plurals, ...) and the messages of files that did not import cleanly,
+	// each prefixed with the file name.
+	Warnings []string
+	// Summary adds up the files' key and word counts.
+	Summary client.ImportSummary
+}
+
 // pollUntilFinished polls a single process until it reaches a terminal status.
 // It returns the process ID on "finished" and an error otherwise.
 func (u *Uploader) pollUntilFinished(ctx context.Context, processID string) (string, error) {
+	res, err := u.pollResult(ctx, processID)
+	return res.ProcessID, err
+}
+
+// pollResult is pollUntilFinished returning the process details too.
+func (u *Uploader) pollResult(ctx context.Context, processID string) (UploadResult, error) {
 	processID = strings.TrimSpace(processID)
 	if processID == "" {
-		return "", errors.New("upload: empty process_id")
+		return UploadResult{}, errors.New("upload: empty process_id")
 	}
 
 	results, err := pollProcessesFn(ctx, []string{processID}, u.client)
 	if err != nil {
-		return "", fmt.Errorf("upload: poll processes: %w", err)
+		return UploadResult{}, fmt.Errorf("upload: poll processes: %w", err)
 	}
 	if len(results) == 0 {
-		return "", fmt.Errorf("upload: no process results returned (process_id=%s)", processID)
+		return UploadResult{}, fmt.Errorf("upload: no process results returned (process_id=%s)", processID)
 	}
 
 	p := results[0]
-	return handleProcessStatus(processID, p.Status, p.Message)
+	if _, err := handleProcessStatus(processID, p.Status, p.Message); err != nil {
+		if p.Err != nil {
+			return UploadResult{}, fmt.Errorf("%w: %w", err, p.Err)
+		}
+		return UploadResult{}, err
+	}
+	return UploadResult{
+		ProcessID: processID,
+		Files:     p.Files,
+		Warnings:  fileWarnings(p.Files),
+		Summary:   p.Details.Summary(),
+	}, nil
+}
+
+// fileWarnings flattens the warnings of files, and the messages of files
+// that didn't finish, into "<file>: <message>" lines.
+func fileWarnings(files []client.ProcessFile) []string {
+	var out []string
+	for _, f := range files {
+		name := f.Name
+		if name == "" {
+			name = f.CustomName
+		}
+		for _, w := range f.Warnings {
+			out = append(out, name+": "+w)
+		}
+		if f.Message != "" && f.Status != background.StatusFinished {
+			out = append(out, name+": "+f.Message)
+		}
+	}
+	return out
 }
 
 func handleProcessStatus(processID, status, message string) (string, error) {
@@ -0,0 +1,71 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// defaultPushLockTTL is the lease used when PushLock.TTL is zero.
+const defaultPushLockTTL = 15 * time.Minute
+
+// PushLock makes uploads to a project take a lock first, so runners on
+// different machines (two CI jobs, say) never push to it at the same time.
+// It complements ImportConflictPolicy, which only sees imports that have
+// already started.
+type PushLock struct {
+	// Locker holds the lock; use one shared by every runner, e.g. backed by
+	// Redis. The lock key is "lokex:push:<project id>", without the branch:
+	// branch merges touch the main branch too.
+	Locker client.Locker
+	// TTL is the lease, which should outlast the longest upload including
+	// polling (default 15m). A runner that dies holding the lock blocks
+	// others for at most this long.
+	TTL time.Duration
+	// RetryEvery is how often a busy lock is retried (default 1s). Bound the
+	// wait with the upload's context.
+	RetryEvery time.Duration
+}
+
+// WithPushLock returns a copy of u whose Upload, UploadWithResult,
+// UploadReader and UploadBatch hold l's lock from before the kickoff until
+// they return, polling included; Enqueue holds it for the kickoff only. A
+// zero PushLock turns locking off.
+func (u *Uploader) WithPushLock(l PushLock) *Uploader {
+	if u == nil {
+		return nil
+	}
+	cp := *u
+	cp.pushLock = l
+	return &cp
+}
+
+// PushLockKey returns the Locker key uploads to projectID (without branch)
+// take, for code that wants to hold it around its own pushes.
+func PushLockKey(projectID string) string {
+	return "lokex:push:" + projectID
+}
+
+// lockPush takes the push lock, if one is configured. The returned release
+// unlocks with a fresh context, so a canceled upload still unlocks; if
+// unlocking fails, the lease simply runs out.
+func (u *Uploader) lockPush(ctx context.Context) (release func(), err error) {
+	if u.pushLock.Locker == nil {
+		return func() {}, nil
+	}
+	ttl := u.pushLock.TTL
+	if ttl <= 0 {
+		ttl = defaultPushLockTTL
+	}
+	lease, err := client.AcquireLock(ctx, u.pushLock.Locker, PushLockKey(u.client.BaseProjectID()), ttl, u.pushLock.RetryEvery)
+	if err != nil {
+		return nil, fmt.Errorf("upload: push lock: %w", err)
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		_ = lease.Release(ctx)
+	}, nil
+}
@@ -0,0 +1,74 @@
+package upload_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestUploader_WithPushLock(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	locker := client.NewMemoryLocker()
+	key := upload.PushLockKey(projectID)
+
+	base := fmt.Sprintf("https://api.lokalise.com/api2/projects/%s/", projectID)
+	httpmock.RegisterResponder("POST", base+"files/upload", func(*http.Request) (*http.Response, error) {
+		// The lock is held while the upload runs.
+		if _, err := locker.TryLock(context.Background(), key, time.Minute); !errors.Is(err, client.ErrLocked) {
+			t.Errorf("TryLock() during upload error = %v, want ErrLocked", err)
+		}
+		return httpmock.NewStringResponse(200, `{"process":{"process_id":"upl_1"}}`), nil
+	})
+
+	cli, err := client.NewClient(token, projectID, client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := upload.NewUploader(cli).WithPushLock(upload.PushLock{Locker: locker, RetryEvery: 5 * time.Millisecond})
+	params := upload.UploadParams{"filename": "en.json", "data": "e30=", "lang_iso": "en"}
+
+	if _, err := u.Upload(context.Background(), params, "", false); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, err := u.UploadBatch(context.Background(), []upload.BatchUploadItem{{Params: params}}, false); err != nil {
+		t.Fatalf("UploadBatch() error = %v", err)
+	}
+
+	// Released afterwards.
+	tok, err := locker.TryLock(context.Background(), key, time.Minute)
+	if err != nil {
+		t.Fatalf("TryLock() after upload error = %v", err)
+	}
+
+	// Another runner holds it: the upload waits, here until ctx is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	calls := httpmock.GetTotalCallCount()
+	if _, err := u.Upload(ctx, params, "", false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Upload() with lock held error = %v, want DeadlineExceeded", err)
+	}
+	if _, err := u.Enqueue(ctx, params, ""); err == nil {
+		t.Fatal("Enqueue() with lock held error = nil")
+	}
+	if httpmock.GetTotalCallCount() != calls {
+		t.Fatal("upload sent while another runner held the lock")
+	}
+	_ = locker.Unlock(context.Background(), key, tok)
+}
+
+func TestPushLockKey(t *testing.T) {
+	t.Parallel()
+
+	if got := upload.PushLockKey("123.abc"); got != "lokex:push:123.abc" {
+		t.Fatalf("PushLockKey() = %q", got)
+	}
+}
@@ -0,0 +1,139 @@
+package upload_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/client/upload"
+)
+
+const processWithFiles = `{"process":{"process_id":"p1","status":"finished","details":{"files":[
+	{"name_original":"en.json","status":"finished","word_count_total":12,
+	 "key_count_total":5,"key_count_inserted":3,"key_count_skipped":2,
+	 "warnings":["2 keys skipped: duplicate"]},
+	{"name_original":"fr.json","status":"failed","message":" invalid plural forms "}
+]}}}`
+
+func newResultUploader(t *testing.T) (*upload.Uploader, *client.Client) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/files/upload"):
+			_, _ = io.Copy(io.Discard, r.Body)
+			_, _ = fmt.Fprint(w, `{"process":{"process_id":"p1"}}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/processes/p1"):
+			_, _ = fmt.Fprint(w, processWithFiles)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return upload.NewUploader(cli), cli
+}
+
+var wantResultWarnings = []string{"en.json: 2 keys skipped: duplicate", "fr.json: invalid plural forms"}
+
+func TestUploader_UploadWithResult(t *testing.T) {
+	t.Parallel()
+
+	u, cli := newResultUploader(t)
+	res, err := u.UploadWithResult(context.Background(), branchUploadParams, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.Health().LastPush.IsZero() {
+		t.Fatal("upload not recorded as a push in Health")
+	}
+	if res.ProcessID != "p1" {
+		t.Fatalf("process id = %q", res.ProcessID)
+	}
+	if len(res.Files) != 2 {
+		t.Fatalf("files = %+v", res.Files)
+	}
+	en := res.Files[0]
+	if en.Name != "en.json" || en.WordCountTotal != 12 || en.KeyCountInserted != 3 || en.KeyCountSkipped != 2 {
+		t.Fatalf("files[0] = %+v", en)
+	}
+	if res.Files[1].Status != "failed" {
+		t.Fatalf("files[1].Status = %q", res.Files[1].Status)
+	}
+	if !reflect.DeepEqual(res.Warnings, wantResultWarnings) {
+		t.Fatalf("warnings:\n got %q\nwant %q", res.Warnings, wantResultWarnings)
+	}
+	want := client.ImportSummary{Files: 2, FilesFailed: 1, Words: 12, Keys: 5, Inserted: 3, Skipped: 2, Warnings: 1}
+	if res.Summary != want {
+		t.Fatalf("summary = %+v, want %+v", res.Summary, want)
+	}
+}
+
+func TestUploader_UploadBatch_Warnings(t *testing.T) {
+	t.Parallel()
+
+	u, _ := newResultUploader(t)
+	res, err := u.UploadBatch(context.Background(), []upload.BatchUploadItem{{Params: branchUploadParams}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.HasErrors() {
+		t.Fatalf("items = %+v", res.Items)
+	}
+	item := res.Items[0]
+	if len(item.Files) != 2 || !reflect.DeepEqual(item.Warnings, wantResultWarnings) {
+		t.Fatalf("item = %+v", item)
+	}
+}
+
+func TestUploader_RequestOptions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu  sync.Mutex
+		ids []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids = append(ids, r.Header.Get("X-Request-Id"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			_, _ = io.Copy(io.Discard, r.Body)
+			_, _ = fmt.Fprint(w, `{"process":{"process_id":"p1"}}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"process":{"process_id":"p1","status":"finished"}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := client.NewClient(token, projectID, client.WithBaseURL(srv.URL), client.WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := upload.NewUploader(cli)
+	if _, err := u.Upload(context.Background(), branchUploadParams, "", true, client.WithHeader("X-Request-Id", "run-7")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Upload(context.Background(), branchUploadParams, "", false); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"run-7", "run-7", ""}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("X-Request-Id per request = %q, want %q", ids, want)
+	}
+}
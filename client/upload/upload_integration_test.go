@@ -9,17 +9,13 @@ import (
 
 	"github.com/bodrovis/lokex/v2/client"
 	"github.com/bodrovis/lokex/v2/client/upload"
+	"github.com/bodrovis/lokex/v2/testutils"
 )
 
 func TestIntegration_Upload(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in -short mode")
-	}
-	if token == "secret" || projectID == "123.abc" {
-		t.Skip("no real Lokalise credentials; skipping integration test")
-	}
+	creds := testutils.RequireCredentials(t)
 
-	cli, err := client.NewClient(token, projectID, nil)
+	cli, err := client.NewClient(creds.Token, creds.ProjectID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -51,14 +47,9 @@ func TestIntegration_Upload(t *testing.T) {
 }
 
 func TestIntegration_UploadBatch_AllSuccess(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in -short mode")
-	}
-	if token == "secret" || projectID == "123.abc" {
-		t.Skip("no real Lokalise credentials; skipping integration test")
-	}
+	creds := testutils.RequireCredentials(t)
 
-	cli, err := client.NewClient(token, projectID, nil)
+	cli, err := client.NewClient(creds.Token, creds.ProjectID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -140,14 +131,9 @@ func TestIntegration_UploadBatch_AllSuccess(t *testing.T) {
 }
 
 func TestIntegration_UploadBatch_PartialFailure(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in -short mode")
-	}
-	if token == "secret" || projectID == "123.abc" {
-		t.Skip("no real Lokalise credentials; skipping integration test")
-	}
+	creds := testutils.RequireCredentials(t)
 
-	cli, err := client.NewClient(token, projectID, nil)
+	cli, err := client.NewClient(creds.Token, creds.ProjectID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bodrovis/lokex/v2/client/internal/background"
+)
+
+// QueuedProcess is the state of an async Lokalise process (upload, export, ...).
+// DownloadURL is set for finished exports, Files for uploads.
+type QueuedProcess = background.QueuedProcess
+
+// ProcessFile is the outcome of one file of an upload process: its status,
+// word and key counts, and warnings.
+type ProcessFile = background.ProcessFile
+
+// ProcessDetails is everything a process reported under "details": typed
+// fields for uploads and exports, and Raw for the rest.
+type ProcessDetails = background.ProcessDetails
+
+// ImportSummary adds up the per-file counts of an upload; see
+// ProcessDetails.Summary.
+type ImportSummary = background.ImportSummary
+
+// Terminal and initial process statuses, as reported in QueuedProcess.Status.
+const (
+	ProcessQueued   = background.StatusQueued
+	ProcessFinished = background.StatusFinished
+	ProcessFailed   = background.StatusFailed
+)
+
+// WaitAll polls the given process IDs (for example from Uploader.Enqueue,
+// possibly obtained by another program) until each one finishes or fails, or
+// until the PollMaxWait budget is spent.
+//
+// Results follow the input order: empty IDs are skipped and duplicates are
+// kept. Processes still running when the budget runs out are returned with
+// their last known status, so check Status rather than relying on the error.
+// A process whose status couldn't be fetched carries the request error in
+// Err: marked failed for a non-retryable one such as a 404, still queued
+// for a transient one. An error is returned only when ctx is canceled or
+// its deadline passes, or when Lokalise rejects the token (401).
+func (c *Client) WaitAll(ctx context.Context, processIDs []string) ([]QueuedProcess, error) {
+	if c == nil {
+		return nil, errors.New("wait: client is nil")
+	}
+
+	return c.WaitAllWithProgress(ctx, processIDs, nil)
+}
+
+// WaitAllWithProgress is WaitAll calling progress whenever a process
+// changes status, starting with the first status fetched, so CLIs can show
+// live status (queued, pre_processing, running, finished) instead of
+// blocking silently. A process that polling marks failed is reported too.
+// progress runs on the polling goroutine, between rounds and in input
+// order; keep it fast. A nil progress is allowed.
+func (c *Client) WaitAllWithProgress(
+	ctx context.Context,
+	processIDs []string,
+	progress func(QueuedProcess),
+) ([]QueuedProcess, error) {
+	if c == nil {
+		return nil, errors.New("wait: client is nil")
+	}
+
+	procs, err := background.PollProcessesWithProgress(ctx, processIDs, c, progress)
+	if err != nil {
+		return nil, fmt.Errorf("wait: %w", err)
+	}
+	return procs, nil
+}
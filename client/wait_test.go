@@ -0,0 +1,121 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+func TestClient_WaitAll(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+		_, _ = w.Write([]byte(`{"process":{"process_id":"` + id + `","status":"finished"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithPollWait(time.Millisecond, time.Second),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	got, err := c.WaitAll(context.Background(), []string{"b", "", "a", "b"})
+	if err != nil {
+		t.Fatalf("WaitAll() error = %v", err)
+	}
+
+	var ids []string
+	for _, p := range got {
+		if p.Status != client.ProcessFinished {
+			t.Fatalf("status of %s = %q, want finished", p.ProcessID, p.Status)
+		}
+		ids = append(ids, p.ProcessID)
+	}
+	if strings.Join(ids, ",") != "b,a,b" {
+		t.Fatalf("ids = %v, want [b a b]", ids)
+	}
+}
+
+func TestClient_WaitAllWithProgress(t *testing.T) {
+	t.Parallel()
+
+	statuses := map[string][]string{
+		"a": {"queued", "pre_processing", "running", "finished"},
+		"b": {"queued", "queued", "finished"},
+	}
+	var (
+		mu    sync.Mutex
+		polls = map[string]int{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+		mu.Lock()
+		st := statuses[id][min(polls[id], len(statuses[id])-1)]
+		polls[id]++
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"process":{"process_id":"` + id + `","status":"` + st + `"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient("tok", "proj",
+		client.WithBaseURL(srv.URL),
+		client.WithPollWait(time.Millisecond, 5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var events []string
+	got, err := c.WaitAllWithProgress(context.Background(), []string{"a", "b", "a"}, func(p client.QueuedProcess) {
+		events = append(events, p.ProcessID+":"+p.Status)
+	})
+	if err != nil {
+		t.Fatalf("WaitAllWithProgress() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d results, want 3", len(got))
+	}
+
+	// Round by round, in input order, each change once.
+	want := "a:queued b:queued a:pre_processing a:running b:finished a:finished"
+	if strings.Join(events, " ") != want {
+		t.Fatalf("events = %v, want %s", events, want)
+	}
+}
+
+func TestClient_WaitAll_Canceled(t *testing.T) {
+	t.Parallel()
+
+	c, err := client.NewClient("tok", "proj")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = c.WaitAll(ctx, []string{"p1"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitAll() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestClient_WaitAll_NilClient(t *testing.T) {
+	t.Parallel()
+
+	var c *client.Client
+	if _, err := c.WaitAll(context.Background(), []string{"p1"}); err == nil {
+		t.Fatal("WaitAll() error = nil, want error")
+	}
+}
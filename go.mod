@@ -7,3 +7,26 @@ require github.com/joho/godotenv v1.5.1
 require github.com/jarcoal/httpmock v1.4.1
 
 require golang.org/x/sync v0.22.0
+
+require golang.org/x/text v0.40.0
+
+require github.com/nicksnyder/go-i18n/v2 v2.6.1
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require go.opentelemetry.io/otel v1.46.0
+
+require go.opentelemetry.io/otel/trace v1.46.0
+
+require go.opentelemetry.io/otel/sdk v1.46.0
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel/metric v1.46.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+)
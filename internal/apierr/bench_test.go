@@ -0,0 +1,41 @@
+package apierr_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func BenchmarkParse(b *testing.B) {
+	cases := []struct {
+		name   string
+		body   []byte
+		status int
+	}{
+		{
+			name:   "top-level",
+			body:   []byte(`{"message":"Too many requests","statusCode":429,"error":"Too Many Requests"}`),
+			status: http.StatusTooManyRequests,
+		},
+		{
+			name:   "nested",
+			body:   []byte(`{"error":{"message":"Invalid key","code":400,"details":{"key_name":"is required"}}}`),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "non-json",
+			body:   []byte(`<html><body>502 Bad Gateway</body></html>`),
+			status: http.StatusBadGateway,
+		},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				_ = apierr.Parse(tc.body, tc.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package apierr
+
+import "syscall"
+
+// transientErrnos are the socket errors worth retrying: the peer reset or
+// aborted the connection, refused it (nothing listening yet), or closed it
+// while we were writing (EPIPE).
+var transientErrnos = []syscall.Errno{
+	syscall.ECONNRESET,
+	syscall.ECONNABORTED,
+	syscall.ECONNREFUSED,
+	syscall.EPIPE,
+}
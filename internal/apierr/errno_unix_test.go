@@ -0,0 +1,81 @@
+//go:build unix
+
+package apierr_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func TestIsRetryable_RealConnRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	_, err = net.DialTimeout("tcp", addr, time.Second)
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Skipf("dial to a closed port did not fail with ECONNREFUSED: %v", err)
+	}
+	if !apierr.IsRetryable(err) {
+		t.Fatalf("IsRetryable(%v) = false, want true", err)
+	}
+}
+
+func TestIsRetryable_RealBrokenPipe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	peer, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = peer.Close()
+
+	// The first writes may still succeed; once the peer's RST arrives
+	// they fail with EPIPE or ECONNRESET depending on timing.
+	buf := make([]byte, 64<<10)
+	for range 100 {
+		if _, err = conn.Write(buf); err != nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err == nil {
+		t.Skip("writes to a closed peer kept succeeding")
+	}
+	if !errors.Is(err, syscall.EPIPE) && !errors.Is(err, syscall.ECONNRESET) {
+		t.Skipf("write failed with %v, not EPIPE or ECONNRESET", err)
+	}
+	if !apierr.IsRetryable(err) {
+		t.Fatalf("IsRetryable(%v) = false, want true", err)
+	}
+}
+
+func TestIsRetryable_WrappedErrnos(t *testing.T) {
+	for _, errno := range []syscall.Errno{syscall.ECONNREFUSED, syscall.EPIPE, syscall.ECONNRESET, syscall.ECONNABORTED} {
+		err := &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", errno)}
+		if !apierr.IsRetryable(err) {
+			t.Fatalf("IsRetryable(%v) = false, want true", err)
+		}
+	}
+	if apierr.IsRetryable(&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.EACCES)}) {
+		t.Fatal("IsRetryable(EACCES) = true, want false")
+	}
+}
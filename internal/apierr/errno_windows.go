@@ -0,0 +1,27 @@
+package apierr
+
+import "syscall"
+
+// Winsock reports socket failures with its own codes, which don't match
+// the POSIX-style syscall.ECONN* values.
+const (
+	wsaeconnaborted syscall.Errno = 10053
+	wsaeconnreset   syscall.Errno = 10054
+	wsaeconnrefused syscall.Errno = 10061
+)
+
+// transientErrnos are the socket errors worth retrying: the peer reset or
+// aborted the connection, or refused it (nothing listening yet). Writing
+// to a connection the peer closed shows up as a reset or abort here, or as
+// ERROR_BROKEN_PIPE / ERROR_NO_DATA on pipes.
+var transientErrnos = []syscall.Errno{
+	wsaeconnaborted,
+	wsaeconnreset,
+	wsaeconnrefused,
+	syscall.ERROR_BROKEN_PIPE,
+	syscall.Errno(232), // ERROR_NO_DATA: the pipe is being closed
+	syscall.ECONNRESET,
+	syscall.ECONNABORTED,
+	syscall.ECONNREFUSED,
+	syscall.EPIPE,
+}
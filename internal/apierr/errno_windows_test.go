@@ -0,0 +1,27 @@
+package apierr_test
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func TestIsRetryable_WinsockErrors(t *testing.T) {
+	for _, errno := range []syscall.Errno{
+		10053, // WSAECONNABORTED
+		10054, // WSAECONNRESET
+		10061, // WSAECONNREFUSED
+		syscall.ERROR_BROKEN_PIPE,
+	} {
+		err := &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("wsasend", errno)}
+		if !apierr.IsRetryable(err) {
+			t.Fatalf("IsRetryable(%v) = false, want true", err)
+		}
+	}
+	if apierr.IsRetryable(&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connectex", syscall.Errno(10013))}) {
+		t.Fatal("IsRetryable(WSAEACCES) = true, want false")
+	}
+}
@@ -6,12 +6,13 @@
 package apierr
 
 import (
+	"io"
 	"net/http"
 )
 
 const (
-	// defaultErrCap caps how many bytes we slurp from a non-2xx response when
-	// constructing an apierr.APIError.
+	// DefaultErrCap caps how many bytes we slurp from a non-2xx response when
+	// constructing an apierr.APIError, unless configured otherwise.
 	DefaultErrCap = 8192
 )
 
@@ -44,6 +45,15 @@ type APIError struct {
 	// or logging when decoding failed or fields were missing.
 	Raw string
 
+	// Truncated reports that the body was longer than the capture limit and
+	// Raw holds only its beginning (so JSON in it may be cut off and Details
+	// incomplete).
+	Truncated bool
+
+	// Meta holds the request ID and rate-limit headers of the response.
+	// Quote Meta.RequestID when reporting the failure to Lokalise support.
+	Meta ResponseMeta
+
 	// Resp is the original HTTP response for access to headers/status/etc.
 	// The body has already been fully read/consumed upstream; do not read it.
 	Resp *http.Response
@@ -58,3 +68,17 @@ func (e *APIError) Error() string {
 	}
 	return http.StatusText(e.Status)
 }
+
+// ReadBody reads up to limit bytes of an error response body and reports
+// whether more was available. A non-positive limit means DefaultErrCap.
+// The rest of r is left unread.
+func ReadBody(r io.Reader, limit int64) (slurp []byte, truncated bool) {
+	if limit <= 0 {
+		limit = DefaultErrCap
+	}
+	slurp, _ = io.ReadAll(io.LimitReader(r, limit+1))
+	if int64(len(slurp)) > limit {
+		return slurp[:limit], true
+	}
+	return slurp, false
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/bodrovis/lokex/v2/internal/apierr"
@@ -86,3 +87,31 @@ func TestAPIError_FieldsRoundtrip(t *testing.T) {
 		t.Fatalf("fields lost: %#v", got)
 	}
 }
+
+func TestReadBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		body          string
+		limit         int64
+		want          string
+		wantTruncated bool
+	}{
+		{"shorter than limit", "abc", 5, "abc", false},
+		{"exactly limit", "abcde", 5, "abcde", false},
+		{"longer than limit", "abcdef", 5, "abcde", true},
+		{"empty", "", 5, "", false},
+		{"non-positive limit uses default", strings.Repeat("x", apierr.DefaultErrCap+1), 0, strings.Repeat("x", apierr.DefaultErrCap), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, truncated := apierr.ReadBody(strings.NewReader(tt.body), tt.limit)
+			if string(got) != tt.want || truncated != tt.wantTruncated {
+				t.Fatalf("ReadBody() = %q, %v; want %q, %v", got, truncated, tt.want, tt.wantTruncated)
+			}
+		})
+	}
+}
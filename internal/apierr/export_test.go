@@ -1,6 +1,9 @@
 package apierr
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 func ExportCoalesce(ss ...string) string {
 	return coalesce(ss...)
@@ -21,3 +24,9 @@ func ExportGetNumberAsInt(m map[string]any, key string) (int, bool) {
 func ExportJSONNumber(s string) json.Number {
 	return json.Number(s)
 }
+
+func ExportSetNowForTest(fn func() time.Time) func() {
+	prev := nowFn
+	nowFn = fn
+	return func() { nowFn = prev }
+}
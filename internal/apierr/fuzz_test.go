@@ -0,0 +1,44 @@
+package apierr_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		``,
+		`gateway exploded`,
+		`<html>502</html>`,
+		`{`,
+		`[]`,
+		`[1,2,3]`,
+		`null`,
+		`{"message":"m","statusCode":429,"error":"Too Many Requests"}`,
+		`{"error":{"message":"m","code":"400","details":{"a":1}}}`,
+		`{"error":{"message":"m","code":1e400,"details":[1]}}`,
+		`{"message":"m","code":"x"}`,
+		`{"message":"m","errorCode":99999999999999999999}`,
+		`{"message":123,"error":{"code":{}}}`,
+	}
+	for _, s := range seeds {
+		f.Add([]byte(s), http.StatusBadRequest)
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte, status int) {
+		e := apierr.Parse(body, status)
+		if e == nil {
+			t.Fatal("Parse() returned nil")
+		}
+		if e.Status != status {
+			t.Fatalf("Status = %d, want %d", e.Status, status)
+		}
+		if e.Raw != strings.TrimSpace(string(body)) {
+			t.Fatalf("Raw = %q, want trimmed body", e.Raw)
+		}
+		_ = e.Error()
+	})
+}
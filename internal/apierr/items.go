@@ -0,0 +1,236 @@
+package apierr
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ItemIssue is one per-item failure reported in an error payload, such as a
+// key that failed validation in a bulk create or a file an upload rejected.
+type ItemIssue struct {
+	// Index is the item's position in the request (the "index" field, the
+	// array position, or the number in a path like "keys[3].key_name");
+	// -1 if the payload doesn't say.
+	Index int
+	// Key is the key name the issue refers to, if any.
+	Key string
+	// KeyID is the key ID the issue refers to, if any.
+	KeyID int64
+	// Filename is the file the issue refers to, if any.
+	Filename string
+	// Field is the offending parameter (e.g. "key_name"), if known.
+	Field string
+	// Message describes the problem; several messages are joined with "; ".
+	Message string
+	// Code is the item's error code, if any.
+	Code int
+	// Source is the details field the issue was found under, e.g. "errors"
+	// or "keys[3].key_name".
+	Source string
+}
+
+// Retryable reports whether the item failed for a transient reason (its
+// code is 408, 425, 429, 500, 502, 503 or 504), so resubmitting it as-is may
+// succeed.
+func (it ItemIssue) Retryable() bool { return retryableStatus(it.Code) }
+
+// Items extracts per-item failures from Details. It understands the common
+// shapes:
+//
+//   - arrays of objects with a message: {"errors":[{"message":"...","key":{"key_name":"a"}}]}
+//   - field messages: {"key_name":"is required"} or {"key_name":["is required"]}
+//   - indexed paths: {"keys[3].key_name":"is taken"} or {"keys.3.key_name":"is taken"}
+//
+// Issues are ordered by details field, then by position. When Details has
+// none, the body in Raw is searched too (arrays such as "errors" sit next to
+// "message" there and are not part of Details). Items returns nil when
+// nothing item-level is found; for a Truncated body it may be incomplete.
+func (e *APIError) Items() []ItemIssue {
+	if e == nil {
+		return nil
+	}
+	if issues := ParseItems(e.Details); issues != nil {
+		return issues
+	}
+	body, err := decodeErrorJSON(e.Raw)
+	obj, ok := body.(map[string]any)
+	if err != nil || !ok {
+		return nil
+	}
+	if issues := ParseItems(obj); issues != nil {
+		return issues
+	}
+	if errObj, ok := obj["error"].(map[string]any); ok {
+		return ParseItems(errObj)
+	}
+	return nil
+}
+
+// metaFields are payload fields that describe the error as a whole.
+var metaFields = []string{"message", "code", "statusCode", "errorCode", "error", "reason"}
+
+// ParseItems extracts per-item issues from a decoded error object (see
+// APIError.Items).
+func ParseItems(m map[string]any) []ItemIssue {
+	var issues []ItemIssue
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		if slices.Contains(metaFields, k) {
+			continue
+		}
+		issues = append(issues, itemsFromField(k, m[k])...)
+	}
+	return issues
+}
+
+func itemsFromField(name string, v any) []ItemIssue {
+	index, field := parsePath(name)
+	switch v := v.(type) {
+	case string:
+		return []ItemIssue{{Index: index, Field: field, Message: v, Source: name}}
+	case map[string]any:
+		if it, ok := itemFromObject(v, index, name); ok {
+			return []ItemIssue{it}
+		}
+		// {"keys":{"3":{...}}}: objects keyed by position.
+		var issues []ItemIssue
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			sub, ok := v[k].(map[string]any)
+			pos, err := strconv.Atoi(k)
+			if !ok || err != nil {
+				continue
+			}
+			if it, ok := itemFromObject(sub, pos, name); ok {
+				issues = append(issues, it)
+			}
+		}
+		return issues
+	case []any:
+		if msgs := stringsOf(v); msgs != nil {
+			return []ItemIssue{{Index: index, Field: field, Message: strings.Join(msgs, "; "), Source: name}}
+		}
+		var issues []ItemIssue
+		for i, el := range v {
+			if obj, ok := el.(map[string]any); ok {
+				if it, ok := itemFromObject(obj, i, name); ok {
+					issues = append(issues, it)
+				}
+			}
+		}
+		return issues
+	}
+	return nil
+}
+
+// itemFromObject reads one item; ok is false if it carries no message.
+func itemFromObject(m map[string]any, pos int, source string) (ItemIssue, bool) {
+	it := ItemIssue{Index: pos, Source: source}
+	if i, ok := getNumberAsInt(m, "index"); ok {
+		it.Index = i
+	}
+
+	it.Message = messageOf(m)
+	if it.Message == "" {
+		return ItemIssue{}, false
+	}
+	it.Code, _ = getNumberAsInt(m, "code")
+	it.Field = coalesce(getStringOr(m, "field", ""), getStringOr(m, "param", ""), getStringOr(m, "parameter", ""))
+	it.Filename = coalesce(getStringOr(m, "filename", ""), getStringOr(m, "file", ""))
+
+	it.Key, it.KeyID = keyOf(m)
+	if key, ok := m["key"].(map[string]any); ok {
+		name, id := keyOf(key)
+		it.Key = coalesce(it.Key, name)
+		if it.KeyID == 0 {
+			it.KeyID = id
+		}
+		if it.Filename == "" {
+			it.Filename = platformString(key["filenames"])
+		}
+	} else if s, ok := getString(m, "key"); ok {
+		it.Key = coalesce(it.Key, s)
+	}
+	return it, true
+}
+
+func messageOf(m map[string]any) string {
+	if s, ok := getString(m, "message"); ok && s != "" {
+		return s
+	}
+	if s, ok := getString(m, "error"); ok && s != "" {
+		return s
+	}
+	switch errs := m["errors"].(type) {
+	case string:
+		return errs
+	case []any:
+		if msgs := stringsOf(errs); msgs != nil {
+			return strings.Join(msgs, "; ")
+		}
+		var msgs []string
+		for _, el := range errs {
+			if obj, ok := el.(map[string]any); ok {
+				if s := messageOf(obj); s != "" {
+					msgs = append(msgs, s)
+				}
+			}
+		}
+		return strings.Join(msgs, "; ")
+	}
+	return ""
+}
+
+func keyOf(m map[string]any) (name string, id int64) {
+	name = platformString(m["key_name"])
+	if n, ok := getNumberAsInt(m, "key_id"); ok {
+		id = int64(n)
+	}
+	return name, id
+}
+
+// platformString returns a plain string, or the first non-empty value of a
+// per-platform object ({"ios":...,"android":...,"web":...,"other":...}).
+func platformString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		for _, p := range []string{"web", "other", "ios", "android"} {
+			if s, _ := getString(v, p); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// stringsOf returns the elements of a non-empty array of strings, or nil.
+func stringsOf(v []any) []string {
+	if len(v) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(v))
+	for _, el := range v {
+		s, ok := el.(string)
+		if !ok {
+			return nil
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+var pathRe = regexp.MustCompile(`^[^\[.]+(?:\[(\d+)\]|\.(\d+))(?:\.(.+))?$`)
+
+// parsePath splits "keys[3].key_name" or "keys.3.key_name" into 3 and
+// "key_name". Other names are a field with no index.
+func parsePath(name string) (index int, field string) {
+	m := pathRe.FindStringSubmatch(name)
+	if m == nil {
+		return -1, name
+	}
+	index, _ = strconv.Atoi(m[1] + m[2])
+	return index, m[3]
+}
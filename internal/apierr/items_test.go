@@ -0,0 +1,122 @@
+package apierr_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func TestAPIError_Items(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want []apierr.ItemIssue
+	}{
+		{
+			name: "errors array with nested key",
+			body: `{"message":"Some keys failed","code":400,"errors":[
+				{"message":"This key name is already taken","code":400,"key":{"key_name":{"ios":"","android":"","web":"welcome","other":""}}},
+				{"message":"Invalid platform","code":400,"key":{"key_name":"bye","key_id":42,"filenames":{"web":"en.json"}}}
+			]}`,
+			want: []apierr.ItemIssue{
+				{Index: 0, Key: "welcome", Message: "This key name is already taken", Code: 400, Source: "errors"},
+				{Index: 1, Key: "bye", KeyID: 42, Filename: "en.json", Message: "Invalid platform", Code: 400, Source: "errors"},
+			},
+		},
+		{
+			name: "nested error with field messages",
+			body: `{"error":{"message":"Validation failed","code":400,"details":{"key_name":"is required","platforms":["is empty","is invalid"]}}}`,
+			want: []apierr.ItemIssue{
+				{Index: -1, Field: "key_name", Message: "is required", Source: "key_name"},
+				{Index: -1, Field: "platforms", Message: "is empty; is invalid", Source: "platforms"},
+			},
+		},
+		{
+			name: "indexed paths",
+			body: `{"error":{"message":"Validation failed","code":400,"details":{"keys[3].key_name":"is taken","keys.10.platforms":["is empty"]}}}`,
+			want: []apierr.ItemIssue{
+				{Index: 10, Field: "platforms", Message: "is empty", Source: "keys.10.platforms"},
+				{Index: 3, Field: "key_name", Message: "is taken", Source: "keys[3].key_name"},
+			},
+		},
+		{
+			name: "objects keyed by position with explicit index and errors list",
+			body: `{"error":{"message":"Upload failed","code":400,"details":{
+				"files":{"2":{"filename":"de.json","errors":["unsupported format"]}},
+				"items":[{"index":7,"file":"fr.json","errors":[{"message":"too large"},{"message":"bad encoding"}]}]
+			}}}`,
+			want: []apierr.ItemIssue{
+				{Index: 2, Filename: "de.json", Message: "unsupported format", Source: "files"},
+				{Index: 7, Filename: "fr.json", Message: "too large; bad encoding", Source: "items"},
+			},
+		},
+		{
+			name: "items without messages are skipped",
+			body: `{"error":{"message":"Bad request","code":400,"details":{"keys":[{"key_name":"a"}]}}}`,
+			want: nil,
+		},
+		{
+			name: "no details",
+			body: `{"error":{"message":"Not found","code":404}}`,
+			want: nil,
+		},
+		{
+			name: "top-level rate limit shape has no items",
+			body: `{"message":"Too many requests","statusCode":429,"error":"Too Many Requests"}`,
+			want: nil,
+		},
+		{
+			name: "non-json body",
+			body: `<html>502</html>`,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := apierr.Parse([]byte(tt.body), http.StatusBadRequest).Items()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Items() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIError_Items_Nil(t *testing.T) {
+	t.Parallel()
+
+	var e *apierr.APIError
+	if got := e.Items(); got != nil {
+		t.Fatalf("nil Items() = %+v", got)
+	}
+}
+
+func TestParseItems_FieldTypes(t *testing.T) {
+	t.Parallel()
+
+	got := apierr.ParseItems(map[string]any{
+		"errors": []any{
+			map[string]any{"error": "duplicate", "key_id": apierr.ExportJSONNumber("9007199254740993"), "key": "home.title", "param": "key_name"},
+			"not an object",
+		},
+	})
+	want := []apierr.ItemIssue{{Index: 0, Key: "home.title", KeyID: 9007199254740993, Field: "key_name", Message: "duplicate", Source: "errors"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseItems() = %+v, want %+v", got, want)
+	}
+}
+
+func TestItemIssue_Retryable(t *testing.T) {
+	t.Parallel()
+
+	for code, want := range map[int]bool{0: false, 400: false, 404: false, 408: true, 429: true, 500: true, 503: true, 501: false} {
+		if got := (apierr.ItemIssue{Code: code}).Retryable(); got != want {
+			t.Errorf("ItemIssue{Code: %d}.Retryable() = %v, want %v", code, got, want)
+		}
+	}
+}
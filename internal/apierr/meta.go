@@ -0,0 +1,60 @@
+package apierr
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HeaderRequestID carries the ID Lokalise support asks for.
+const HeaderRequestID = "X-Request-Id"
+
+// ResponseMeta is what lokex keeps from the headers of an API response.
+type ResponseMeta struct {
+	Method string // request method
+	Path   string // request path, without the query string
+	Status int    // HTTP status code
+
+	// RequestID is the X-Request-Id the server assigned; quote it when
+	// contacting Lokalise support. Empty if the response had none.
+	RequestID string
+
+	// RateLimit holds every X-Rate-Limit-* (or X-RateLimit-*) header of
+	// the response, as sent; nil if there were none.
+	RateLimit http.Header
+}
+
+// NewResponseMeta extracts the ResponseMeta of a response to req.
+func NewResponseMeta(req *http.Request, status int, header http.Header) ResponseMeta {
+	m := ResponseMeta{
+		Status:    status,
+		RequestID: strings.TrimSpace(header.Get(HeaderRequestID)),
+	}
+	if req != nil {
+		m.Method = req.Method
+		if req.URL != nil {
+			m.Path = req.URL.Path
+		}
+	}
+	for k, v := range header {
+		if isRateLimitHeader(k) {
+			if m.RateLimit == nil {
+				m.RateLimit = make(http.Header)
+			}
+			m.RateLimit[k] = append([]string(nil), v...)
+		}
+	}
+	return m
+}
+
+// MetaFromResponse is NewResponseMeta for resp; the zero value if resp is
+// nil.
+func MetaFromResponse(resp *http.Response) ResponseMeta {
+	if resp == nil {
+		return ResponseMeta{}
+	}
+	return NewResponseMeta(resp.Request, resp.StatusCode, resp.Header)
+}
+
+func isRateLimitHeader(canonical string) bool {
+	return strings.HasPrefix(canonical, "X-Rate-Limit-") || strings.HasPrefix(canonical, "X-Ratelimit-")
+}
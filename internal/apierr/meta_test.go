@@ -0,0 +1,48 @@
+package apierr_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func TestNewResponseMeta(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "https://api.lokalise.com/api2/projects/p/files/upload?x=1", nil)
+	h := http.Header{}
+	h.Set("X-Request-Id", " req-123 ")
+	h.Set("X-Rate-Limit-Remaining", "5")
+	h.Set("X-RateLimit-Reset", "1700000000")
+	h.Set("Content-Type", "application/json")
+
+	m := apierr.NewResponseMeta(req, http.StatusAccepted, h)
+	if m.Method != http.MethodPost || m.Path != "/api2/projects/p/files/upload" || m.Status != http.StatusAccepted {
+		t.Fatalf("meta = %+v", m)
+	}
+	if m.RequestID != "req-123" {
+		t.Fatalf("RequestID = %q, want req-123", m.RequestID)
+	}
+	if len(m.RateLimit) != 2 || m.RateLimit.Get("X-Rate-Limit-Remaining") != "5" || m.RateLimit.Get("X-RateLimit-Reset") != "1700000000" {
+		t.Fatalf("RateLimit = %v, want both rate-limit headers only", m.RateLimit)
+	}
+
+	h.Set("X-Rate-Limit-Remaining", "4")
+	if m.RateLimit.Get("X-Rate-Limit-Remaining") != "5" {
+		t.Fatal("RateLimit shares storage with the response header")
+	}
+}
+
+func TestNewResponseMeta_Empty(t *testing.T) {
+	t.Parallel()
+
+	m := apierr.NewResponseMeta(nil, http.StatusOK, http.Header{})
+	if m.RequestID != "" || m.RateLimit != nil || m.Method != "" {
+		t.Fatalf("meta = %+v, want only Status", m)
+	}
+	if got := apierr.MetaFromResponse(nil); got.Status != 0 {
+		t.Fatalf("MetaFromResponse(nil) = %+v, want zero", got)
+	}
+}
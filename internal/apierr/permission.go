@@ -0,0 +1,114 @@
+package apierr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrPermissionDenied is matched (via errors.Is) by a *PermissionError.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// PermissionError is a 403 from the API annotated with what the call most
+// likely needs: the token's access level and the project permission (the
+// contributor right set in Lokalise project settings).
+type PermissionError struct {
+	Method     string
+	Path       string // endpoint path without query, e.g. "projects/123/files/upload"
+	Access     string // "read" or "read/write"
+	Permission string // project permission, e.g. "upload"; empty if unknown
+	Err        *APIError
+}
+
+func (e *PermissionError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Err.Error())
+	fmt.Fprintf(&b, " (%s %s: the API token needs %s access", e.Method, e.Path, e.Access)
+	if e.Permission != "" {
+		fmt.Fprintf(&b, " and the %q project permission", e.Permission)
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func (e *PermissionError) Is(target error) bool { return target == ErrPermissionDenied }
+
+func (e *PermissionError) Unwrap() error { return e.Err }
+
+// resourcePermissions maps the first path segment under projects/{id} to the
+// project permission managing it. Endpoints outside a project, or not
+// listed, get no permission hint.
+var resourcePermissions = map[string]string{
+	"keys":                        "keys",
+	"translations":                "keys",
+	"comments":                    "keys",
+	"screenshots":                 "screenshots",
+	"languages":                   "languages",
+	"contributors":                "contributors",
+	"tasks":                       "tasks",
+	"branches":                    "branches",
+	"glossary-terms":              "glossary",
+	"custom_translation_statuses": "custom_status_modify",
+	"webhooks":                    "settings",
+	"snapshots":                   "settings",
+	"":                            "settings", // the project itself
+}
+
+// readOnlyPosts are POST endpoints that only need read access.
+var readOnlyPosts = map[string]bool{
+	"files/download":       true,
+	"files/async-download": true,
+}
+
+// PermissionHint returns the token access and project permission the
+// endpoint most likely requires. path is relative to the API base URL and
+// may include a query string.
+func PermissionHint(method, path string) (access, permission string) {
+	path, _, _ = strings.Cut(path, "?")
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+
+	var sub string
+	if segs[0] == "projects" && len(segs) >= 2 {
+		sub = strings.Join(segs[2:], "/")
+	}
+
+	access = "read/write"
+	if method == http.MethodGet || method == http.MethodHead || (method == http.MethodPost && readOnlyPosts[sub]) {
+		access = "read"
+	}
+	if segs[0] != "projects" || len(segs) < 2 {
+		return access, ""
+	}
+
+	resource, _, _ := strings.Cut(sub, "/")
+	switch {
+	case sub == "files/upload":
+		permission = "upload"
+	case resource == "files":
+		permission = "download"
+	case sub == "" && method == http.MethodGet:
+		permission = "" // any contributor can read the project
+	default:
+		permission = resourcePermissions[resource]
+	}
+	return access, permission
+}
+
+// WithPermissionHint wraps a 403 *APIError in a *PermissionError for the
+// given call. Other errors are returned unchanged.
+func WithPermissionHint(err error, method, path string) error {
+	var ae *APIError
+	if !errors.As(err, &ae) || ae.Status != http.StatusForbidden {
+		return err
+	}
+	access, perm := PermissionHint(method, path)
+	path, _, _ = strings.Cut(path, "?")
+	return &PermissionError{
+		Method:     method,
+		Path:       strings.Trim(path, "/"),
+		Access:     access,
+		Permission: perm,
+		Err:        ae,
+	}
+}
@@ -0,0 +1,91 @@
+package apierr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func TestPermissionHint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method, path string
+		access, perm string
+	}{
+		{http.MethodPost, "projects/123.abc/files/upload", "read/write", "upload"},
+		{http.MethodPost, "projects/123.abc/files/download", "read", "download"},
+		{http.MethodPost, "projects/123.abc/files/async-download", "read", "download"},
+		{http.MethodGet, "projects/123.abc/files?limit=10", "read", "download"},
+		{http.MethodGet, "projects/123.abc/keys?page=2", "read", "keys"},
+		{http.MethodPut, "projects/123.abc/keys/42", "read/write", "keys"},
+		{http.MethodDelete, "/projects/123.abc/keys/", "read/write", "keys"},
+		{http.MethodPut, "projects/123.abc/translations/9", "read/write", "keys"},
+		{http.MethodPost, "projects/123.abc/screenshots", "read/write", "screenshots"},
+		{http.MethodPost, "projects/123.abc/languages", "read/write", "languages"},
+		{http.MethodPost, "projects/123.abc/branches/1/merge", "read/write", "branches"},
+		{http.MethodPost, "projects/123.abc/webhooks", "read/write", "settings"},
+		{http.MethodPut, "projects/123.abc", "read/write", "settings"},
+		{http.MethodGet, "projects/123.abc", "read", ""},
+		{http.MethodGet, "projects/123.abc/processes/p1", "read", ""},
+		{http.MethodGet, "teams", "read", ""},
+		{http.MethodPost, "projects", "read/write", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			access, perm := apierr.PermissionHint(tt.method, tt.path)
+			if access != tt.access || perm != tt.perm {
+				t.Fatalf("PermissionHint() = %q, %q; want %q, %q", access, perm, tt.access, tt.perm)
+			}
+		})
+	}
+}
+
+func TestWithPermissionHint(t *testing.T) {
+	t.Parallel()
+
+	ae := &apierr.APIError{Status: http.StatusForbidden, Message: "Forbidden"}
+	err := apierr.WithPermissionHint(fmt.Errorf("wrapped: %w", ae), http.MethodPost, "projects/p/files/upload?x=1")
+
+	var pe *apierr.PermissionError
+	if !errors.As(err, &pe) || !errors.Is(err, apierr.ErrPermissionDenied) {
+		t.Fatalf("err = %v, want *PermissionError", err)
+	}
+	if pe.Err != ae || pe.Path != "projects/p/files/upload" || pe.Access != "read/write" || pe.Permission != "upload" {
+		t.Fatalf("PermissionError = %+v", pe)
+	}
+	var got *apierr.APIError
+	if !errors.As(err, &got) || got != ae {
+		t.Fatal("APIError not reachable via errors.As")
+	}
+	want := `Forbidden (POST projects/p/files/upload: the API token needs read/write access and the "upload" project permission)`
+	if err.Error() != want {
+		t.Fatalf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	noPerm := apierr.WithPermissionHint(ae, http.MethodGet, "teams")
+	if msg := noPerm.Error(); !strings.HasSuffix(msg, "needs read access)") {
+		t.Fatalf("Error() = %q", msg)
+	}
+}
+
+func TestWithPermissionHint_OtherErrorsUnchanged(t *testing.T) {
+	t.Parallel()
+
+	for _, err := range []error{
+		nil,
+		errors.New("boom"),
+		&apierr.APIError{Status: http.StatusUnauthorized},
+		&apierr.APIError{Status: http.StatusNotFound},
+	} {
+		if got := apierr.WithPermissionHint(err, http.MethodGet, "projects/p/keys"); got != err {
+			t.Fatalf("WithPermissionHint(%v) = %v, want unchanged", err, got)
+		}
+	}
+}
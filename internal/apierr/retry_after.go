@@ -0,0 +1,55 @@
+package apierr
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryAfter caps server-provided delays so a bogus header can't park a
+// caller for hours.
+const maxRetryAfter = 5 * time.Minute
+
+var nowFn = time.Now
+
+// RetryAfter extracts the delay requested by a 429/503 response's Retry-After
+// header (delta-seconds or HTTP-date) from err. It reports false when err is
+// not such an *APIError or the header is missing or malformed. The delay is
+// capped at 5 minutes; dates in the past yield 0.
+func RetryAfter(err error) (time.Duration, bool) {
+	var ae *APIError
+	if !errors.As(err, &ae) || ae.Resp == nil {
+		return 0, false
+	}
+	if ae.Status != http.StatusTooManyRequests && ae.Status != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	return ParseRetryAfter(ae.Resp.Header.Get("Retry-After"))
+}
+
+// ParseRetryAfter parses a Retry-After header value.
+func ParseRetryAfter(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+
+	var d time.Duration
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		if secs > int64(maxRetryAfter/time.Second) {
+			return maxRetryAfter, true
+		}
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = max(t.Sub(nowFn()), 0)
+	} else {
+		return 0, false
+	}
+
+	return min(d, maxRetryAfter), true
+}
@@ -0,0 +1,78 @@
+package apierr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/apierr"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	restore := apierr.ExportSetNowForTest(func() time.Time { return now })
+	defer restore()
+
+	tests := []struct {
+		in     string
+		want   time.Duration
+		wantOK bool
+	}{
+		{in: "", wantOK: false},
+		{in: "  3 ", want: 3 * time.Second, wantOK: true},
+		{in: "0", want: 0, wantOK: true},
+		{in: "-1", wantOK: false},
+		{in: "soon", wantOK: false},
+		{in: "99999999999", want: 5 * time.Minute, wantOK: true},
+		{in: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, wantOK: true},
+		{in: now.Add(-time.Hour).Format(http.TimeFormat), want: 0, wantOK: true},
+		{in: now.Add(time.Hour).Format(http.TimeFormat), want: 5 * time.Minute, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		got, ok := apierr.ParseRetryAfter(tt.in)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	withHeader := func(status int, v string) error {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if v != "" {
+			resp.Header.Set("Retry-After", v)
+		}
+		return fmt.Errorf("wrapped: %w", &apierr.APIError{Status: status, Resp: resp})
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "429 with header", err: withHeader(http.StatusTooManyRequests, "2"), want: 2 * time.Second, wantOK: true},
+		{name: "503 with header", err: withHeader(http.StatusServiceUnavailable, "1"), want: time.Second, wantOK: true},
+		{name: "429 without header", err: withHeader(http.StatusTooManyRequests, "")},
+		{name: "500 ignores header", err: withHeader(http.StatusInternalServerError, "2")},
+		{name: "no response", err: &apierr.APIError{Status: http.StatusTooManyRequests}},
+		{name: "other error", err: errors.New("boom")},
+		{name: "nil", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := apierr.RetryAfter(tt.err)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("RetryAfter() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"net"
 	"net/http"
+	"slices"
 	"sync"
 	"syscall"
 	"time"
@@ -31,6 +32,9 @@ func IsRetryable(err error) bool {
 	if isContextError(err) {
 		return false
 	}
+	if retryable, ok := classifyDNS(err); ok {
+		return retryable
+	}
 	if hasTimeout(err) {
 		return true
 	}
@@ -53,27 +57,48 @@ func isContextError(err error) bool {
 	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
 }
 
+// classifyDNS reports whether a failed lookup is worth retrying: resolver
+// timeouts and temporary failures (SERVFAIL, unreachable resolver) are,
+// while a name that doesn't exist (NXDOMAIN) fails fast. ok is false if err
+// is not a DNS error.
+func classifyDNS(err error) (retryable, ok bool) {
+	var de *net.DNSError
+	if !errors.As(err, &de) {
+		return false, false
+	}
+	if de.IsNotFound {
+		return false, true
+	}
+	return de.IsTimeout || de.IsTemporary, true
+}
+
 func hasTimeout(err error) bool {
 	var te interface{ Timeout() bool }
 	return errors.As(err, &te) && te.Timeout()
 }
 
+// isTransientIO reports connections that died or were refused, typically
+// while a load balancer or proxy restarts. The socket errors differ by
+// platform; see transientErrnos.
 func isTransientIO(err error) bool {
-	return errors.Is(err, io.ErrUnexpectedEOF) ||
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
 		errors.Is(err, io.EOF) ||
-		errors.Is(err, io.ErrClosedPipe) ||
-		errors.Is(err, syscall.ECONNRESET) ||
-		errors.Is(err, syscall.EPIPE) ||
-		errors.Is(err, syscall.ECONNABORTED)
+		errors.Is(err, io.ErrClosedPipe) {
+		return true
+	}
+	var errno syscall.Errno
+	return errors.As(err, &errno) && slices.Contains(transientErrnos, errno)
 }
 
 func isRetryableAPIError(err error) bool {
 	var ae *APIError
-	if !errors.As(err, &ae) {
-		return false
-	}
+	return errors.As(err, &ae) && retryableStatus(ae.Status)
+}
 
-	switch ae.Status {
+// retryableStatus reports whether an HTTP status (or a per-item error code
+// using HTTP semantics) signals a transient failure.
+func retryableStatus(code int) bool {
+	switch code {
 	case http.StatusRequestTimeout,
 		http.StatusTooEarly,
 		http.StatusTooManyRequests,
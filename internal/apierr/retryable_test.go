@@ -94,6 +94,8 @@ func TestIsRetryable_FlakyIO(t *testing.T) {
 		io.ErrClosedPipe,
 		syscall.ECONNRESET,
 		syscall.ECONNABORTED,
+		syscall.ECONNREFUSED,
+		syscall.EPIPE,
 	}
 	for _, e := range errs {
 		if !apierr.IsRetryable(e) {
@@ -102,6 +104,70 @@ func TestIsRetryable_FlakyIO(t *testing.T) {
 	}
 }
 
+func TestIsRetryable_DNSErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  *net.DNSError
+		want bool
+	}{
+		{"temporary", &net.DNSError{Err: "server misbehaving", Name: "api.lokalise.com", IsTemporary: true}, true},
+		{"timeout", &net.DNSError{Err: "i/o timeout", Name: "api.lokalise.com", IsTimeout: true}, true},
+		{"not found", &net.DNSError{Err: "no such host", Name: "api.lokalise.cmo", IsNotFound: true}, false},
+		{"not found but temporary", &net.DNSError{Err: "no such host", Name: "api.lokalise.cmo", IsNotFound: true, IsTemporary: true}, false},
+		{"permanent", &net.DNSError{Err: "invalid name", Name: "-"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Wrapped like a failed dial: *net.OpError{*net.DNSError}.
+			err := fmt.Errorf("Get: %w", &net.OpError{Op: "dial", Net: "tcp", Err: tc.err})
+			if got := apierr.IsRetryable(err); got != tc.want {
+				t.Fatalf("IsRetryable(%v) = %v, want %v", err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryable_RealNXDOMAINFailsFast(t *testing.T) {
+	// .invalid never resolves (RFC 2606); a sandbox without DNS may report
+	// another error, so only check lookups that ended in NXDOMAIN.
+	_, err := net.DefaultResolver.LookupHost(context.Background(), "lokex.invalid")
+	var de *net.DNSError
+	if !errors.As(err, &de) || !de.IsNotFound {
+		t.Skipf("lookup did not end in NXDOMAIN: %v", err)
+	}
+	if apierr.IsRetryable(err) {
+		t.Fatal("IsRetryable(NXDOMAIN) = true, want false")
+	}
+}
+
+func TestIsRetryable_TLSHandshakeTimeout(t *testing.T) {
+	// A server that accepts connections but never answers the handshake.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer func() { _ = c.Close() }()
+		}
+	}()
+
+	hc := &http.Client{Transport: &http.Transport{TLSHandshakeTimeout: 50 * time.Millisecond}}
+	resp, err := hc.Get("https://" + ln.Addr().String() + "/")
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("GET succeeded, want a TLS handshake timeout")
+	}
+	if !apierr.IsRetryable(err) {
+		t.Fatalf("IsRetryable(%v) = false, want true", err)
+	}
+}
+
 func TestIsRetryable_ContextErrorsAreNotRetryable(t *testing.T) {
 	if apierr.IsRetryable(context.Canceled) {
 		t.Fatalf("context.Canceled should not be retryable")
@@ -0,0 +1,118 @@
+// Package eol rewrites the line endings of text streams, so files uploaded
+// from or extracted on Windows don't show up as changed on every line.
+// Content that looks binary is passed through untouched.
+package eol
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"golang.org/x/text/transform"
+)
+
+// Style is a line ending style.
+type Style int
+
+const (
+	// Keep leaves line endings as they are.
+	Keep Style = iota
+	// LF ends lines with "\n"; "\r\n" becomes "\n".
+	LF
+	// CRLF ends lines with "\r\n"; a "\n" not preceded by "\r" becomes
+	// "\r\n".
+	CRLF
+)
+
+func (s Style) String() string {
+	switch s {
+	case Keep:
+		return "keep"
+	case LF:
+		return "lf"
+	case CRLF:
+		return "crlf"
+	}
+	return fmt.Sprintf("Style(%d)", int(s))
+}
+
+// Valid reports whether s is one of the defined styles.
+func (s Style) Valid() bool { return s >= Keep && s <= CRLF }
+
+// sniffLen is how much of a stream is checked for NUL bytes, like git does
+// to tell binary files from text.
+const sniffLen = 8000
+
+// NewReader returns r with line endings rewritten to s. If the first
+// sniffLen bytes contain a NUL, as in binary files or UTF-16 text, r is
+// returned as is.
+func NewReader(r io.Reader, s Style) io.Reader {
+	var t transform.Transformer
+	switch s {
+	case LF:
+		t = toLF{}
+	case CRLF:
+		t = new(toCRLF)
+	default:
+		return r
+	}
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, _ := br.Peek(sniffLen) // a read error resurfaces on Read
+	if bytes.IndexByte(head, 0) >= 0 {
+		return br
+	}
+	return transform.NewReader(br, t)
+}
+
+// toLF drops the "\r" of every "\r\n".
+type toLF struct{ transform.NopResetter }
+
+func (toLF) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		c := src[nSrc]
+		if c == '\r' {
+			if nSrc+1 == len(src) && !atEOF {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			if nSrc+1 < len(src) && src[nSrc+1] == '\n' {
+				nSrc++
+				continue
+			}
+		}
+		if nDst == len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		dst[nDst] = c
+		nDst++
+		nSrc++
+	}
+	return nDst, nSrc, nil
+}
+
+// toCRLF inserts "\r" before every "\n" that lacks one.
+type toCRLF struct {
+	prevCR bool
+}
+
+func (t *toCRLF) Reset() { t.prevCR = false }
+
+func (t *toCRLF) Transform(dst, src []byte, _ bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		c := src[nSrc]
+		if c == '\n' && !t.prevCR {
+			if nDst+2 > len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			dst[nDst] = '\r'
+			nDst++
+		} else if nDst == len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		dst[nDst] = c
+		nDst++
+		nSrc++
+		t.prevCR = c == '\r'
+	}
+	return nDst, nSrc, nil
+}
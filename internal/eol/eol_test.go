@@ -0,0 +1,101 @@
+package eol_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/bodrovis/lokex/v2/internal/eol"
+)
+
+func TestNewReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in       string
+		lf, crlf string
+	}{
+		{"a\r\nb\nc", "a\nb\nc", "a\r\nb\r\nc"},
+		{"a\r\n", "a\n", "a\r\n"},
+		{"\n\n", "\n\n", "\r\n\r\n"},
+		{"\r\n\r\n", "\n\n", "\r\n\r\n"},
+		{"lone\rcr\r", "lone\rcr\r", "lone\rcr\r"},
+		{"\r\r\n", "\r\n", "\r\r\n"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		for _, c := range []struct {
+			style eol.Style
+			want  string
+		}{{eol.LF, tt.lf}, {eol.CRLF, tt.crlf}, {eol.Keep, tt.in}} {
+			// One byte at a time exercises the chunk boundaries.
+			got, err := io.ReadAll(eol.NewReader(iotest.OneByteReader(strings.NewReader(tt.in)), c.style))
+			if err != nil {
+				t.Fatalf("%q/%s: %v", tt.in, c.style, err)
+			}
+			if string(got) != c.want {
+				t.Errorf("%q/%s = %q, want %q", tt.in, c.style, got, c.want)
+			}
+		}
+	}
+}
+
+func TestNewReader_LargeInput(t *testing.T) {
+	t.Parallel()
+
+	in := strings.Repeat("key = value\r\n", 50_000)
+	got, err := io.ReadAll(eol.NewReader(strings.NewReader(in), eol.LF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.ReplaceAll(in, "\r\n", "\n"); string(got) != want {
+		t.Fatalf("LF output differs: %d bytes, want %d", len(got), len(want))
+	}
+
+	back, err := io.ReadAll(eol.NewReader(bytes.NewReader(got), eol.CRLF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(back) != in {
+		t.Fatalf("CRLF round trip differs: %d bytes, want %d", len(back), len(in))
+	}
+}
+
+func TestNewReader_SkipsBinary(t *testing.T) {
+	t.Parallel()
+
+	in := "\xFF\xFEa\x00\n\x00" // UTF-16LE "a\n"
+	for _, s := range []eol.Style{eol.LF, eol.CRLF} {
+		got, err := io.ReadAll(eol.NewReader(strings.NewReader(in), s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != in {
+			t.Errorf("%s: binary content changed to %q", s, got)
+		}
+	}
+}
+
+func TestNewReader_ReadError(t *testing.T) {
+	t.Parallel()
+
+	r := eol.NewReader(iotest.ErrReader(io.ErrClosedPipe), eol.LF)
+	if _, err := io.ReadAll(r); err != io.ErrClosedPipe {
+		t.Fatalf("ReadAll() error = %v, want ErrClosedPipe", err)
+	}
+}
+
+func TestStyle(t *testing.T) {
+	t.Parallel()
+
+	for s, want := range map[eol.Style]string{eol.Keep: "keep", eol.LF: "lf", eol.CRLF: "crlf", eol.Style(9): "Style(9)"} {
+		if got := s.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+	if eol.Style(3).Valid() || eol.Style(-1).Valid() || !eol.CRLF.Valid() {
+		t.Fatal("Valid() is wrong")
+	}
+}
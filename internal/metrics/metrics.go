@@ -0,0 +1,55 @@
+// Package metrics defines the hook lokex reports measurements to. It has no
+// dependency on a metrics library: callers adapt Recorder to Prometheus,
+// OpenTelemetry metrics, expvar and so on.
+package metrics
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Recorder receives measurements. Methods are called synchronously on the
+// goroutine doing the work, possibly from several goroutines at once, so
+// they must be safe for concurrent use and must not block for long.
+type Recorder interface {
+	// Request is called after every API request, each retry attempt
+	// included. route is the URL path with IDs replaced by "{id}" (see
+	// Route), so it is safe as a metric label. status is 0 when no response
+	// arrived. d excludes time spent waiting for the rate limiter.
+	Request(method, route string, status int, d time.Duration)
+	// Retry is called before an operation is retried after delay. label
+	// names the operation ("request", "download", ...); attempt counts
+	// retries from 1.
+	Retry(label string, attempt int, delay time.Duration)
+	// Poll is called when polling async processes returns; rounds is how
+	// many times the pending processes were fetched.
+	Poll(processes, rounds int, d time.Duration)
+	// DownloadedBytes is called with the size of every bundle downloaded.
+	DownloadedBytes(n int64)
+}
+
+// Nop discards every measurement.
+type Nop struct{}
+
+func (Nop) Request(string, string, int, time.Duration) {}
+func (Nop) Retry(string, int, time.Duration)           {}
+func (Nop) Poll(int, int, time.Duration)               {}
+func (Nop) DownloadedBytes(int64)                      {}
+
+var versionRe = regexp.MustCompile(`^(api|v)\d+$`)
+
+// Route replaces the IDs in an API path (segments containing a digit, such
+// as project, key and process IDs, but not versions like "api2") with "{id}"
+// and drops the query, e.g. "projects/123.abc/keys/42?page=2" becomes
+// "projects/{id}/keys/{id}".
+func Route(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segs := strings.Split(path, "/")
+	for i, s := range segs {
+		if strings.ContainsAny(s, "0123456789") && !versionRe.MatchString(s) {
+			segs[i] = "{id}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
@@ -0,0 +1,35 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/metrics"
+)
+
+func TestRoute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct{ in, want string }{
+		{"projects/123.abc/keys/42?page=2", "projects/{id}/keys/{id}"},
+		{"/api2/projects/123.abc/processes/7f3e9c2a", "/api2/projects/{id}/processes/{id}"},
+		{"projects/123.abc/files/async-download", "projects/{id}/files/async-download"},
+		{"projects", "projects"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := metrics.Route(tt.in); got != tt.want {
+			t.Errorf("Route(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNop(t *testing.T) {
+	t.Parallel()
+
+	var r metrics.Recorder = metrics.Nop{}
+	r.Request("GET", "projects", 200, time.Second)
+	r.Retry("request", 1, time.Second)
+	r.Poll(1, 1, time.Second)
+	r.DownloadedBytes(1)
+}
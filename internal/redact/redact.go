@@ -0,0 +1,143 @@
+// Package redact removes credentials and bulky payloads from anything lokex
+// records or prints: request/response headers, JSON bodies and error
+// strings. Every logging and diagnostics path goes through a Redactor, so
+// there is one place that decides what must never leave the process.
+package redact
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Mask replaces redacted values.
+const Mask = "[REDACTED]"
+
+// Headers are always replaced with Mask.
+var Headers = []string{
+	"X-Api-Token",
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// Fields are JSON object fields whose string values are replaced with Mask:
+// "data" carries base64 file and screenshot contents, the others carry
+// credentials.
+var Fields = []string{"data", "secret", "token", "password", "api_token"}
+
+// fieldRe finds the start of a string value of one of Fields. It matches on
+// truncated JSON too, which is what diagnostics keep.
+var fieldRe = regexp.MustCompile(`"(` + strings.Join(Fields, "|") + `)"\s*:\s*"`)
+
+// Redactor masks Headers, Fields and a set of literal secrets (such as the
+// client's API token). The zero value and nil mask Headers and Fields only.
+// It is safe for concurrent use.
+type Redactor struct {
+	secrets []string
+}
+
+// New returns a Redactor that also masks every occurrence of secrets.
+// Empty secrets are ignored.
+func New(secrets ...string) *Redactor {
+	r := &Redactor{}
+	for _, s := range secrets {
+		if s = strings.TrimSpace(s); s != "" && !slices.Contains(r.secrets, s) {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	return r
+}
+
+// String masks the secrets in s.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	return s
+}
+
+// Body masks the string values of Fields in a (possibly truncated) JSON
+// body, then the secrets.
+func (r *Redactor) Body(s string) string {
+	if s == "" {
+		return s
+	}
+	var b strings.Builder
+	for {
+		loc := fieldRe.FindStringIndex(s)
+		if loc == nil {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:loc[1]])
+		b.WriteString(Mask)
+		s = s[loc[1]+stringEnd(s[loc[1]:]):]
+	}
+	return r.String(b.String())
+}
+
+// stringEnd returns the offset of the closing quote of the JSON string
+// whose contents start s, or len(s) if it is cut off.
+func stringEnd(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(s)
+}
+
+// Map masks, in place, the string values of Fields and the secrets in every
+// other string of a decoded JSON object, recursing into nested objects and
+// arrays.
+func (r *Redactor) Map(m map[string]any) {
+	for k, v := range m {
+		if _, ok := v.(string); ok && slices.Contains(Fields, k) {
+			m[k] = Mask
+			continue
+		}
+		m[k] = r.value(v)
+	}
+}
+
+func (r *Redactor) value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case map[string]any:
+		r.Map(v)
+	case []any:
+		for i := range v {
+			v[i] = r.value(v[i])
+		}
+	}
+	return v
+}
+
+// Header returns a copy of h with Headers masked and secrets removed from
+// the other values. It returns nil for an empty header.
+func (r *Redactor) Header(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for k, vv := range out {
+		if slices.Contains(Headers, http.CanonicalHeaderKey(k)) {
+			out[k] = []string{Mask}
+			continue
+		}
+		for i, v := range vv {
+			vv[i] = r.String(v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package redact_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/redact"
+)
+
+func TestRedactor_Body(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New("tok-123")
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			name: "data field",
+			in:   `{"filename":"en.json","data":"eyJhIjoiYiJ9","lang_iso":"en"}`,
+			want: `{"filename":"en.json","data":"[REDACTED]","lang_iso":"en"}`,
+		},
+		{
+			name: "nested, spaced and escaped",
+			in:   `{"screenshots":[{"data" : "data:image/png;base64,AA\"BB","title":"t"}],"webhook":{"secret":"s3"}}`,
+			want: `{"screenshots":[{"data" : "[REDACTED]","title":"t"}],"webhook":{"secret":"[REDACTED]"}}`,
+		},
+		{
+			name: "truncated mid-value",
+			in:   `{"data":"QUJDREVGR0hJSktMTU5PUFFSU1RVVldYWVo`,
+			want: `{"data":"[REDACTED]`,
+		},
+		{
+			name: "similar names and non-string values untouched",
+			in:   `{"metadata":"keep","data":null,"token_count":3}`,
+			want: `{"metadata":"keep","data":null,"token_count":3}`,
+		},
+		{
+			name: "literal secret anywhere",
+			in:   `token was tok-123, again tok-123`,
+			want: `token was [REDACTED], again [REDACTED]`,
+		},
+		{
+			name: "empty",
+			in:   ``,
+			want: ``,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := r.Body(tt.in); got != tt.want {
+				t.Fatalf("Body() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor_Header(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New("tok-123", "", " ")
+	h := http.Header{
+		"X-Api-Token":  {"tok-123"},
+		"Cookie":       {"a=b"},
+		"X-Debug":      {"sent tok-123"},
+		"Content-Type": {"application/json"},
+	}
+	got := r.Header(h)
+	if got.Get("X-Api-Token") != redact.Mask || got.Get("Cookie") != redact.Mask {
+		t.Fatalf("credentials not masked: %v", got)
+	}
+	if got.Get("X-Debug") != "sent "+redact.Mask || got.Get("Content-Type") != "application/json" {
+		t.Fatalf("other headers = %v", got)
+	}
+	if h.Get("X-Api-Token") != "tok-123" {
+		t.Fatal("Header() modified its input")
+	}
+	if r.Header(nil) != nil {
+		t.Fatal("Header(nil) != nil")
+	}
+}
+
+func TestRedactor_Nil(t *testing.T) {
+	t.Parallel()
+
+	var r *redact.Redactor
+	if got := r.String("tok"); got != "tok" {
+		t.Fatalf("nil String() = %q", got)
+	}
+	if got := r.Body(`{"data":"x"}`); got != `{"data":"[REDACTED]"}` {
+		t.Fatalf("nil Body() = %q", got)
+	}
+	if got := r.Header(http.Header{"Authorization": {"Bearer x"}}); got.Get("Authorization") != redact.Mask {
+		t.Fatalf("nil Header() = %v", got)
+	}
+}
+
+func FuzzRedactor_Body(f *testing.F) {
+	for _, s := range []string{`{"data":"abc"}`, `{"data":"a\`, `"data":"`, `{"secret":"x\"y"}`} {
+		f.Add(s)
+	}
+	r := redact.New("SECRET-TOKEN")
+	f.Fuzz(func(t *testing.T, s string) {
+		out := r.Body(s + "SECRET-TOKEN")
+		if strings.Contains(out, "SECRET-TOKEN") {
+			t.Fatalf("token leaked: %q", out)
+		}
+	})
+}
+
+func TestRedactor_Map(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New("tok-123")
+	m := map[string]any{
+		"data":    "QUJD",
+		"message": "bad tok-123",
+		"items":   []any{map[string]any{"secret": "s", "n": 1.0}, "tok-123"},
+		"token":   nil,
+	}
+	r.Map(m)
+	items := m["items"].([]any)
+	if m["data"] != redact.Mask || m["message"] != "bad "+redact.Mask || m["token"] != nil {
+		t.Fatalf("Map() = %v", m)
+	}
+	if inner := items[0].(map[string]any); inner["secret"] != redact.Mask || inner["n"] != 1.0 || items[1] != redact.Mask {
+		t.Fatalf("nested = %v", items)
+	}
+	r.Map(nil) // must not panic
+}
@@ -0,0 +1,151 @@
+// Package schema checks API responses against the fields lokex relies on.
+//
+// Typed response structs only declare a subset of what Lokalise returns, so
+// unknown fields are expected and ignored. What must not go unnoticed is a
+// field lokex needs disappearing or being renamed: encoding/json would leave
+// it zero without complaint. Struct fields tagged `lokex:"required"` must be
+// present and non-null in the JSON; Check walks nested structs, slices and
+// maps and reports the first violation.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Tag is the struct tag key; its value "required" marks a required field.
+const Tag = "lokex"
+
+// ErrMismatch is matched (via errors.Is) by an *Error.
+var ErrMismatch = errors.New("response does not match the expected schema")
+
+// Error describes where a response differs from the expected schema.
+type Error struct {
+	Path   string // JSON path of the offending value, e.g. "keys[2].key_id"
+	Reason string // e.g. "missing required field"
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+func (e *Error) Is(target error) bool { return target == ErrMismatch }
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// Check verifies that data, the JSON already decoded into v, contains every
+// required field of v's type. Non-struct targets always pass.
+func Check(data []byte, v any) error {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("schema: %w", err)
+	}
+	return walk(t, doc, "")
+}
+
+func walk(t reflect.Type, val any, path string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if val == nil {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if reflect.PointerTo(t).Implements(unmarshalerType) {
+			return nil // custom decoding; only its presence is checked
+		}
+		obj, ok := val.(map[string]any)
+		if !ok {
+			return &Error{Path: pathOr(path), Reason: "expected an object"}
+		}
+		return walkStruct(t, obj, path)
+	case reflect.Slice, reflect.Array:
+		arr, ok := val.([]any)
+		if !ok {
+			return nil // e.g. []byte as base64
+		}
+		for i, el := range arr {
+			if err := walk(t.Elem(), el, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		obj, ok := val.(map[string]any)
+		if !ok {
+			return nil
+		}
+		for _, k := range slices.Sorted(maps.Keys(obj)) {
+			if err := walk(t.Elem(), obj[k], join(path, k)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func walkStruct(t reflect.Type, obj map[string]any, path string) error {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := walkStruct(ft, obj, path); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fv, present := obj[name]
+		if f.Tag.Get(Tag) == "required" && fv == nil {
+			reason := "missing required field"
+			if present {
+				reason = "required field is null"
+			}
+			return &Error{Path: join(path, name), Reason: reason}
+		}
+		if present {
+			if err := walk(f.Type, fv, join(path, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func pathOr(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
@@ -0,0 +1,116 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/schema"
+)
+
+type names struct{ Web string }
+
+func (n *names) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		n.Web = s
+		return nil
+	}
+	var obj struct {
+		Web string `json:"web"`
+	}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	n.Web = obj.Web
+	return nil
+}
+
+type translation struct {
+	LanguageISO string `json:"language_iso" lokex:"required"`
+	Text        string `json:"translation"`
+}
+
+type key struct {
+	KeyID        int64          `json:"key_id" lokex:"required"`
+	Name         names          `json:"key_name" lokex:"required"`
+	Description  string         `json:"description"`
+	Translations []translation  `json:"translations"`
+	Custom       map[string]key `json:"custom,omitempty"`
+}
+
+type meta struct {
+	ProjectID string `json:"project_id" lokex:"required"`
+}
+
+type listResponse struct {
+	meta
+	Keys []key `json:"keys" lokex:"required"`
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantPath string
+	}{
+		{"valid with unknown fields", `{"project_id":"p","keys":[{"key_id":1,"key_name":{"web":"a"},"extra":true,"translations":[{"language_iso":"en"}]}],"total":1}`, ""},
+		{"custom unmarshaler accepts any shape", `{"project_id":"p","keys":[{"key_id":1,"key_name":"a"}]}`, ""},
+		{"missing top-level field", `{"project_id":"p"}`, "keys"},
+		{"missing embedded field", `{"keys":[]}`, "project_id"},
+		{"missing nested field", `{"project_id":"p","keys":[{"key_id":1,"key_name":"a"},{"key_name":"b"}]}`, "keys[1].key_id"},
+		{"null required field", `{"project_id":"p","keys":[{"key_id":null,"key_name":"a"}]}`, "keys[0].key_id"},
+		{"deeply nested", `{"project_id":"p","keys":[{"key_id":1,"key_name":"a","translations":[{"translation":"x"}]}]}`, "keys[0].translations[0].language_iso"},
+		{"map values", `{"project_id":"p","keys":[{"key_id":1,"key_name":"a","custom":{"x":{"key_name":"y"}}}]}`, "keys[0].custom.x.key_id"},
+		{"object expected", `{"project_id":"p","keys":["a"]}`, "keys[0]"},
+		{"optional slice null", `{"project_id":"p","keys":[{"key_id":1,"key_name":"a","translations":null}]}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var v listResponse
+			err := schema.Check([]byte(tt.body), &v)
+			if tt.wantPath == "" {
+				if err != nil {
+					t.Fatalf("Check() error = %v", err)
+				}
+				return
+			}
+			var se *schema.Error
+			if !errors.As(err, &se) || se.Path != tt.wantPath {
+				t.Fatalf("Check() error = %v, want path %q", err, tt.wantPath)
+			}
+			if !errors.Is(err, schema.ErrMismatch) {
+				t.Fatalf("errors.Is(%v, ErrMismatch) = false", err)
+			}
+		})
+	}
+}
+
+func TestCheck_NonStructTargets(t *testing.T) {
+	t.Parallel()
+
+	var m map[string]any
+	if err := schema.Check([]byte(`{"a":1}`), &m); err != nil {
+		t.Fatalf("map target: %v", err)
+	}
+	if err := schema.Check([]byte(`{"a":1}`), nil); err != nil {
+		t.Fatalf("nil target: %v", err)
+	}
+	var v listResponse
+	if err := schema.Check([]byte(`{`), &v); err == nil || errors.Is(err, schema.ErrMismatch) {
+		t.Fatalf("invalid JSON: error = %v, want a decode error", err)
+	}
+}
+
+func TestError_Message(t *testing.T) {
+	t.Parallel()
+
+	e := &schema.Error{Path: "keys[0].key_id", Reason: "missing required field"}
+	if got, want := e.Error(), "keys[0].key_id: missing required field"; got != want {
+		t.Fatalf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+// Package telemetry wraps the OpenTelemetry tracing calls lokex makes, so
+// instrumented code reads the same whether tracing is on or off: a nil
+// tracer yields no-op spans.
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// ScopeName is the instrumentation scope of lokex's tracer.
+const ScopeName = "github.com/bodrovis/lokex/v2"
+
+// Span names.
+const (
+	SpanRequest  = "lokex.request"
+	SpanAttempt  = "lokex.attempt"
+	SpanPoll     = "lokex.poll"
+	SpanDownload = "lokex.download"
+	SpanUpload   = "lokex.upload"
+)
+
+// Attribute keys. HTTP ones follow the OpenTelemetry semantic conventions.
+const (
+	AttrHTTPMethod     = attribute.Key("http.request.method")
+	AttrHTTPStatusCode = attribute.Key("http.response.status_code")
+	AttrURLPath        = attribute.Key("url.path")
+	AttrServerAddress  = attribute.Key("server.address")
+	AttrRetryLabel     = attribute.Key("lokex.retry.label")
+	AttrRetryAttempt   = attribute.Key("lokex.retry.attempt") // 0 for the first try
+	AttrRetryDelay     = attribute.Key("lokex.retry.delay_ms")
+	AttrProcessCount   = attribute.Key("lokex.process.count")
+	AttrProcessID      = attribute.Key("lokex.process.id")
+	AttrPollRounds     = attribute.Key("lokex.poll.rounds")
+	AttrPollPending    = attribute.Key("lokex.poll.pending") // unresolved when polling stopped
+	AttrBundleSize     = attribute.Key("lokex.bundle.size")  // bytes
+	AttrFilename       = attribute.Key("lokex.upload.filename")
+	AttrUploadPoll     = attribute.Key("lokex.upload.poll")
+)
+
+var noopSpan = noop.Span{}
+
+// Tracer returns tp's lokex tracer, or nil for a nil tp.
+func Tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		return nil
+	}
+	return tp.Tracer(ScopeName)
+}
+
+// Start starts a span with tr, or returns ctx and a no-op span if tr is nil.
+func Start(ctx context.Context, tr trace.Tracer, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if tr == nil {
+		return ctx, noopSpan
+	}
+	return tr.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err (if any) on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,62 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/telemetry"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStart_NilTracer(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	got, span := telemetry.Start(ctx, nil, "x")
+	if got != ctx {
+		t.Fatal("Start with nil tracer changed the context")
+	}
+	if span.IsRecording() {
+		t.Fatal("Start with nil tracer returned a recording span")
+	}
+	telemetry.End(span, errors.New("boom")) // must not panic
+}
+
+func TestTracer_NilProvider(t *testing.T) {
+	t.Parallel()
+
+	if telemetry.Tracer(nil) != nil {
+		t.Fatal("Tracer(nil) != nil")
+	}
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	t.Parallel()
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	tr := telemetry.Tracer(tp)
+
+	_, ok := telemetry.Start(context.Background(), tr, "ok", telemetry.AttrProcessID.String("p1"))
+	telemetry.End(ok, nil)
+	_, bad := telemetry.Start(context.Background(), tr, "bad")
+	telemetry.End(bad, errors.New("boom"))
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %d, want 2", len(spans))
+	}
+	if spans[0].Status().Code == codes.Error || len(spans[0].Attributes()) != 1 {
+		t.Fatalf("ok span: status %v, attrs %v", spans[0].Status(), spans[0].Attributes())
+	}
+	if spans[1].Status().Code != codes.Error || spans[1].Status().Description != "boom" || len(spans[1].Events()) != 1 {
+		t.Fatalf("bad span: status %v, events %v", spans[1].Status(), spans[1].Events())
+	}
+	if got := spans[0].InstrumentationScope().Name; got != telemetry.ScopeName {
+		t.Fatalf("scope = %q, want %q", got, telemetry.ScopeName)
+	}
+}
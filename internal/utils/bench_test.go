@@ -0,0 +1,38 @@
+package utils_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+func BenchmarkEncodeJSONBody(b *testing.B) {
+	small := map[string]any{"format": "json", "original_filenames": true}
+
+	large := make(map[string]any, 500)
+	for i := range 500 {
+		large[fmt.Sprintf("key_%03d", i)] = map[string]any{
+			"translation": "Some reasonably sized translation value",
+			"tags":        []string{"a", "b"},
+		}
+	}
+
+	b.Run("small", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := utils.EncodeJSONBody(small); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("large", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := utils.EncodeJSONBody(large); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferCap keeps one huge payload from pinning memory in the pool.
+const maxPooledBufferCap = 1 << 20 // 1 MiB
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// GetBuffer returns an empty buffer from the shared pool.
+// Return it with PutBuffer once its contents are no longer referenced.
+func GetBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// PutBuffer resets b and returns it to the pool. Buffers that grew beyond
+// maxPooledBufferCap are dropped so the pool stays small. Nil is ignored.
+func PutBuffer(b *bytes.Buffer) {
+	if b == nil || b.Cap() > maxPooledBufferCap {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
@@ -0,0 +1,55 @@
+package utils_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/utils"
+)
+
+func TestBufferPool_GetReturnsEmptyBuffer(t *testing.T) {
+	b := utils.GetBuffer()
+	b.WriteString("dirty")
+	utils.PutBuffer(b)
+
+	for range 8 {
+		got := utils.GetBuffer()
+		if got.Len() != 0 {
+			t.Fatalf("GetBuffer() returned buffer with %d bytes, want empty", got.Len())
+		}
+		utils.PutBuffer(got)
+	}
+}
+
+func TestBufferPool_PutIgnoresNilAndHuge(t *testing.T) {
+	utils.PutBuffer(nil)
+
+	huge := bytes.NewBuffer(make([]byte, 0, 2<<20))
+	huge.WriteString("x")
+	utils.PutBuffer(huge)
+
+	// Dropped buffers are left untouched (not reset).
+	if huge.Len() != 1 {
+		t.Fatalf("huge buffer len = %d, want 1 (not recycled)", huge.Len())
+	}
+}
+
+func TestEncodeJSONBody_ResultDoesNotAliasPool(t *testing.T) {
+	first, err := utils.EncodeJSONBody(map[string]string{"a": "first"})
+	if err != nil {
+		t.Fatalf("EncodeJSONBody error: %v", err)
+	}
+
+	// Encoding again reuses pooled buffers; the first reader must be unaffected.
+	for range 4 {
+		if _, err := utils.EncodeJSONBody(map[string]string{"b": "second-longer-value"}); err != nil {
+			t.Fatalf("EncodeJSONBody error: %v", err)
+		}
+	}
+
+	out, _ := io.ReadAll(first)
+	if string(out) != "{\"a\":\"first\"}\n" {
+		t.Fatalf("first payload = %q, want untouched", out)
+	}
+}
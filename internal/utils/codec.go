@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONEncoder is the subset of *json.Encoder lokex relies on.
+type JSONEncoder interface {
+	Encode(v any) error
+	SetEscapeHTML(on bool)
+}
+
+// JSONDecoder is the subset of *json.Decoder lokex relies on.
+type JSONDecoder interface {
+	Decode(v any) error
+	UseNumber()
+	DisallowUnknownFields()
+}
+
+// Codec creates streaming JSON encoders/decoders. Alternative implementations
+// (go-json, sonic, ...) usually satisfy it with a thin wrapper because their
+// APIs mirror encoding/json.
+type Codec interface {
+	NewEncoder(w io.Writer) JSONEncoder
+	NewDecoder(r io.Reader) JSONDecoder
+}
+
+// StdCodec is the encoding/json implementation of Codec.
+type StdCodec struct{}
+
+// NewEncoder returns a *json.Encoder writing to w.
+func (StdCodec) NewEncoder(w io.Writer) JSONEncoder {
+	return json.NewEncoder(w)
+}
+
+// NewDecoder returns a *json.Decoder reading from r.
+func (StdCodec) NewDecoder(r io.Reader) JSONDecoder {
+	return json.NewDecoder(r)
+}
+
+// CodecOrDefault returns c, or StdCodec when c is nil.
+func CodecOrDefault(c Codec) Codec {
+	if c == nil {
+		return StdCodec{}
+	}
+	return c
+}
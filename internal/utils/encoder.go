@@ -3,7 +3,6 @@ package utils
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 )
 
@@ -14,13 +13,23 @@ import (
 //   - json.Encoder.Encode appends a trailing newline; that's fine for HTTP bodies.
 //   - On encode errors (e.g., unsupported values), returns a wrapped error.
 func EncodeJSONBody(body any) (*bytes.Reader, error) {
-	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
+	return EncodeJSONBodyWith(StdCodec{}, body)
+}
+
+// EncodeJSONBodyWith is EncodeJSONBody using the given codec.
+// A nil codec falls back to StdCodec.
+func EncodeJSONBodyWith(codec Codec, body any) (*bytes.Reader, error) {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+
+	enc := CodecOrDefault(codec).NewEncoder(buf)
 	enc.SetEscapeHTML(false)
 	if err := enc.Encode(body); err != nil {
 		return nil, fmt.Errorf("encode body: %w", err)
 	}
 
-	// bytes.Reader умеет Seek, и он читает из слайса без доп. копии
-	return bytes.NewReader(buf.Bytes()), nil
+	// The pooled buffer is reused right after return, so hand out an exact-size
+	// copy: net/http may still read the body after Do returns, so it must not
+	// alias pooled memory. This still saves the repeated growth allocations.
+	return bytes.NewReader(bytes.Clone(buf.Bytes())), nil
 }
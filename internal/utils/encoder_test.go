@@ -70,3 +70,41 @@ func TestEncodeJSONBody_ErrorOnUnsupportedType(t *testing.T) {
 		t.Fatalf("error should be wrapped with context, got: %v", err)
 	}
 }
+
+type recordingCodec struct {
+	utils.StdCodec
+	used bool
+}
+
+func (c *recordingCodec) NewEncoder(w io.Writer) utils.JSONEncoder {
+	c.used = true
+	return c.StdCodec.NewEncoder(w)
+}
+
+func TestEncodeJSONBodyWith_UsesCodec(t *testing.T) {
+	codec := &recordingCodec{}
+
+	buf, err := utils.EncodeJSONBodyWith(codec, map[string]any{"a": "<b>"})
+	if err != nil {
+		t.Fatalf("EncodeJSONBodyWith error: %v", err)
+	}
+	if !codec.used {
+		t.Fatal("custom codec was not used")
+	}
+
+	out, _ := io.ReadAll(buf)
+	if string(out) != "{\"a\":\"<b>\"}\n" {
+		t.Fatalf("output = %q", out)
+	}
+}
+
+func TestEncodeJSONBodyWith_NilCodecFallsBackToStd(t *testing.T) {
+	buf, err := utils.EncodeJSONBodyWith(nil, []int{1, 2})
+	if err != nil {
+		t.Fatalf("EncodeJSONBodyWith error: %v", err)
+	}
+	out, _ := io.ReadAll(buf)
+	if string(out) != "[1,2]\n" {
+		t.Fatalf("output = %q", out)
+	}
+}
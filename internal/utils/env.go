@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"os"
+	"strings"
+)
+
+// GetEnv returns the environment variable value if set and not blank, or
+// the default. The value is trimmed.
+func GetEnv(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
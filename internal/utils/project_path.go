@@ -9,3 +9,11 @@ import (
 func ProjectPath(projectID, suffix string) string {
 	return fmt.Sprintf("projects/%s/%s", url.PathEscape(projectID), suffix)
 }
+
+// WithQuery appends encoded query values to path. Empty values leave path as-is.
+func WithQuery(path string, q url.Values) string {
+	if len(q) == 0 {
+		return path
+	}
+	return path + "?" + q.Encode()
+}
@@ -0,0 +1,194 @@
+// Package xlsx writes single-sheet Office Open XML workbooks holding plain
+// text. It covers what lokex reports need (a header row plus string cells)
+// without pulling in a spreadsheet library.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const workbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// styles defines two cell formats: 0 is the default, 1 is bold (header row).
+const styles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>
+</styleSheet>`
+
+// maxSheetName is Excel's limit on sheet name length.
+const maxSheetName = 31
+
+// Write writes a workbook with one sheet named sheet. The first row is
+// rendered bold and frozen when header is true. Every cell is a string,
+// escaped with EscapeFormula.
+func Write(w io.Writer, sheet string, rows [][]string, header bool) error {
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name string
+		body func(io.Writer) error
+	}{
+		{"[Content_Types].xml", constPart(contentTypes)},
+		{"_rels/.rels", constPart(rootRels)},
+		{"xl/workbook.xml", func(w io.Writer) error { return writeWorkbook(w, sheet) }},
+		{"xl/_rels/workbook.xml.rels", constPart(workbookRels)},
+		{"xl/styles.xml", constPart(styles)},
+		{"xl/worksheets/sheet1.xml", func(w io.Writer) error { return writeSheet(w, rows, header) }},
+	}
+	for _, p := range parts {
+		fw, err := zw.Create(p.name)
+		if err != nil {
+			return fmt.Errorf("xlsx: %s: %w", p.name, err)
+		}
+		if err := p.body(fw); err != nil {
+			return fmt.Errorf("xlsx: %s: %w", p.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("xlsx: %w", err)
+	}
+	return nil
+}
+
+func constPart(s string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+func writeWorkbook(w io.Writer, sheet string) error {
+	sheet = sanitizeSheetName(sheet)
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	escape(&b, sheet)
+	b.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeSheet(w io.Writer, rows [][]string, header bool) error {
+	bw := bufio.NewWriter(w)
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if header && len(rows) > 0 {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	b.WriteString(`<sheetData>`)
+	for i, row := range rows {
+		r := strconv.Itoa(i + 1)
+		b.WriteString(`<row r="` + r + `">`)
+		for j, cell := range row {
+			if cell == "" {
+				continue
+			}
+			b.WriteString(`<c r="` + ColumnName(j) + r + `" t="inlineStr"`)
+			if header && i == 0 {
+				b.WriteString(` s="1"`)
+			}
+			b.WriteString(`><is><t xml:space="preserve">`)
+			escape(&b, EscapeFormula(cell))
+			b.WriteString(`</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+		if _, err := bw.WriteString(b.String()); err != nil {
+			return err
+		}
+		b.Reset()
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	if _, err := bw.WriteString(b.String()); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ColumnName returns the letters of the zero-based column index: 0 is A,
+// 25 is Z, 26 is AA.
+func ColumnName(i int) string {
+	var out []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		out = append([]byte{byte('A' + (i-1)%26)}, out...)
+	}
+	return string(out)
+}
+
+// formulaTriggers are the leading characters that make spreadsheet apps
+// treat a typed or imported cell as a formula.
+const formulaTriggers = "=+-@\t\r"
+
+// EscapeFormula prefixes s with a single quote if it would start a formula
+// (=, +, -, @, tab or CR), so that a translation such as "=HYPERLINK(...)"
+// stays text when a reviewer opens or re-saves the file. Values that already
+// start with quotes before such a character get one more, so
+// UnescapeFormula restores every value exactly.
+func EscapeFormula(s string) string {
+	if needsFormulaEscape(s) {
+		return "'" + s
+	}
+	return s
+}
+
+// UnescapeFormula reverses EscapeFormula.
+func UnescapeFormula(s string) string {
+	if strings.HasPrefix(s, "'") && needsFormulaEscape(s) {
+		return s[1:]
+	}
+	return s
+}
+
+func needsFormulaEscape(s string) bool {
+	t := strings.TrimLeft(s, "'")
+	return t != "" && strings.ContainsRune(formulaTriggers, rune(t[0]))
+}
+
+// escape writes s as XML text. Characters XML cannot carry (most control
+// characters) become U+FFFD.
+func escape(b *strings.Builder, s string) {
+	_ = xml.EscapeText(b, []byte(s))
+}
+
+func sanitizeSheetName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	if s == "" {
+		return "Sheet1"
+	}
+	if r := []rune(s); len(r) > maxSheetName {
+		s = string(r[:maxSheetName])
+	}
+	return s
+}
@@ -0,0 +1,178 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/xlsx"
+)
+
+type sheetXML struct {
+	Views []struct {
+		Pane struct {
+			State string `xml:"state,attr"`
+		} `xml:"sheetView>pane"`
+	} `xml:"sheetViews"`
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			Ref   string `xml:"r,attr"`
+			Type  string `xml:"t,attr"`
+			Style string `xml:"s,attr"`
+			Text  string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readPart(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer func() { _ = f.Close() }()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	rows := [][]string{
+		{"key", "en", "fr"},
+		{"greeting", "Hello <b>&</b>", ""},
+		{"multi", "line 1\nline 2", "  spaced  "},
+	}
+	var buf bytes.Buffer
+	if err := xlsx.Write(&buf, "Translations", rows, true); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if !xmlWellFormed(readPart(t, zr, part)) {
+			t.Fatalf("%s is not well-formed", part)
+		}
+	}
+	if wb := string(readPart(t, zr, "xl/workbook.xml")); !strings.Contains(wb, `name="Translations"`) {
+		t.Fatalf("workbook.xml = %s", wb)
+	}
+
+	var sheet sheetXML
+	if err := xml.Unmarshal(readPart(t, zr, "xl/worksheets/sheet1.xml"), &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Views) != 1 || sheet.Views[0].Pane.State != "frozen" {
+		t.Fatalf("header row not frozen: %+v", sheet.Views)
+	}
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("rows = %d, want 3", len(sheet.Rows))
+	}
+
+	got := map[string]string{}
+	for _, r := range sheet.Rows {
+		for _, c := range r.Cells {
+			if c.Type != "inlineStr" {
+				t.Fatalf("cell %s type = %q", c.Ref, c.Type)
+			}
+			if (r.R == "1") != (c.Style == "1") {
+				t.Fatalf("cell %s style = %q", c.Ref, c.Style)
+			}
+			got[c.Ref] = c.Text
+		}
+	}
+	want := map[string]string{
+		"A1": "key", "B1": "en", "C1": "fr",
+		"A2": "greeting", "B2": "Hello <b>&</b>",
+		"A3": "multi", "B3": "line 1\nline 2", "C3": "  spaced  ",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("cells = %v, want %v", got, want)
+	}
+	for ref, v := range want {
+		if got[ref] != v {
+			t.Fatalf("%s = %q, want %q", ref, got[ref], v)
+		}
+	}
+}
+
+func TestWrite_NoHeaderAndSheetName(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := xlsx.Write(&buf, " a/b:c[d]? that is far too long for excel ", [][]string{{"x\x01y"}}, false); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wb := string(readPart(t, zr, "xl/workbook.xml")); !strings.Contains(wb, `name="a_b_c_d__ that is far too long "`) {
+		t.Fatalf("workbook.xml = %s", wb)
+	}
+	sheet := readPart(t, zr, "xl/worksheets/sheet1.xml")
+	if !xmlWellFormed(sheet) || bytes.Contains(sheet, []byte("sheetViews")) || bytes.Contains(sheet, []byte(`s="1"`)) {
+		t.Fatalf("sheet1.xml = %s", sheet)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	t.Parallel()
+
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsx.ColumnName(i); got != want {
+			t.Fatalf("ColumnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"=1+1":  "'=1+1",
+		"+1":    "'+1",
+		"-1":    "'-1",
+		"@A1":   "'@A1",
+		"\tx":   "'\tx",
+		"\rx":   "'\rx",
+		"'=x":   "''=x",
+		"'tis":  "'tis",
+		"Hello": "Hello",
+		"a=b":   "a=b",
+		"":      "",
+		"'":     "'",
+	} {
+		got := xlsx.EscapeFormula(in)
+		if got != want {
+			t.Errorf("EscapeFormula(%q) = %q, want %q", in, got, want)
+		}
+		if back := xlsx.UnescapeFormula(got); back != in {
+			t.Errorf("UnescapeFormula(%q) = %q, want %q", got, back, in)
+		}
+	}
+}
+
+func xmlWellFormed(b []byte) bool {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
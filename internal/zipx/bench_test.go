@@ -0,0 +1,48 @@
+package zipx_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/zipx"
+)
+
+// BenchmarkUnzip_LargeBundle extracts a synthetic bundle shaped like a real
+// export: many locale files of moderate size spread over a few directories.
+func BenchmarkUnzip_LargeBundle(b *testing.B) {
+	const (
+		files    = 400
+		fileSize = 32 << 10
+	)
+
+	payload := bytes.Repeat([]byte(`{"key":"value"},`), fileSize/16)
+	entries := make([]zentry, 0, files)
+	for i := range files {
+		entries = append(entries, zentry{
+			name: fmt.Sprintf("locale/%02d/file_%04d.json", i%20, i),
+			data: payload,
+		})
+	}
+	zp := makeZip(b, entries)
+
+	b.SetBytes(int64(files * len(payload)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if err := zipx.Unzip(zp, b.TempDir(), zipx.DefaultPolicy()); err != nil {
+			b.Fatalf("Unzip() error: %v", err)
+		}
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	zp := makeZip(b, []zentry{{name: "a.json", data: []byte(`{}`)}})
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := zipx.Validate(zp); err != nil {
+			b.Fatalf("Validate() error: %v", err)
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"archive/zip"
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/bodrovis/lokex/v2/internal/eol"
 	"github.com/bodrovis/lokex/v2/internal/zipx"
 )
 
@@ -123,3 +125,33 @@ func TestUnzip_OpenZipReaderError_Default(t *testing.T) {
 		t.Fatal("Unzip() error = nil, want non-nil")
 	}
 }
+
+func TestUnzip_LineEnding(t *testing.T) {
+	zipPath := makeZip(t, []zentry{
+		{name: "en.json", data: []byte("{\r\n  \"a\": \"b\"\r\n}\r\n")},
+		{name: "fr.mo", data: []byte("\x00\x01\r\n\x02")},
+	})
+	dest := t.TempDir()
+
+	p := zipx.DefaultPolicy()
+	p.LineEnding = eol.LF
+	if err := zipx.Unzip(zipPath, dest, p); err != nil {
+		t.Fatalf("Unzip() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dest, "en.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "{\n  \"a\": \"b\"\n}\n" {
+		t.Fatalf("en.json = %q, want LF line endings", got)
+	}
+	// Binary entries are left alone.
+	got, err = os.ReadFile(filepath.Join(dest, "fr.mo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "\x00\x01\r\n\x02" {
+		t.Fatalf("fr.mo = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,100 @@
+package zipx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/zipx"
+)
+
+var entryNameSeeds = []string{
+	"a.txt",
+	"dir/a.txt",
+	"./a.txt",
+	"/abs/a.txt",
+	"../evil.txt",
+	"dir/../../evil.txt",
+	`dir\..\..\evil.txt`,
+	`C:\windows\evil.txt`,
+	"C:/evil.txt",
+	"//server/share/x",
+	"a/./b/../c",
+	"a\x00b",
+	"",
+	".",
+	"..",
+	"....//....//x",
+}
+
+func FuzzResolveTargetPath_StaysInsideDest(f *testing.F) {
+	for _, s := range entryNameSeeds {
+		f.Add(s)
+	}
+
+	dest := f.TempDir()
+	destReal, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		f.Fatalf("eval dest: %v", err)
+	}
+
+	f.Fuzz(func(t *testing.T, name string) {
+		rel, err := zipx.ExportNormalizeZipEntryPath(name)
+		if err != nil || rel == "" {
+			return
+		}
+
+		target, err := zipx.ExportResolveTargetPath(destReal, destReal, rel, name)
+		if err != nil {
+			return
+		}
+
+		back, err := filepath.Rel(destReal, target)
+		if err != nil || back == ".." || strings.HasPrefix(back, ".."+string(filepath.Separator)) {
+			t.Fatalf("entry %q resolved outside dest: %q", name, target)
+		}
+	})
+}
+
+// FuzzUnzip_EntryName extracts a one-entry archive with a hostile name and
+// checks nothing lands outside the destination.
+func FuzzUnzip_EntryName(f *testing.F) {
+	for _, s := range entryNameSeeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, name string) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return
+		}
+		_, _ = w.Write([]byte("x"))
+		if err := zw.Close(); err != nil {
+			return
+		}
+
+		root := t.TempDir()
+		dest := filepath.Join(root, "dest")
+		zp := filepath.Join(root, "in.zip")
+		if err := os.WriteFile(zp, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("write zip: %v", err)
+		}
+
+		_ = zipx.Unzip(zp, dest, zipx.DefaultPolicy())
+
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			t.Fatalf("read root: %v", err)
+		}
+		for _, e := range entries {
+			if e.Name() != "dest" && e.Name() != "in.zip" {
+				t.Fatalf("entry %q escaped destination: created %q", name, e.Name())
+			}
+		}
+	})
+}
@@ -1,11 +1,12 @@
 package zipx_test
 
 import (
-	"archive/zip"
 	"os"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/bodrovis/lokex/v2/testutils"
 )
 
 func contains(s, sub string) bool { return strings.Contains(s, sub) }
@@ -18,44 +19,18 @@ type zentry struct {
 	isDir    bool
 }
 
-func makeZip(t *testing.T, entries []zentry) string {
+func makeZip(t testing.TB, entries []zentry) string {
 	t.Helper()
-	f, err := os.CreateTemp(t.TempDir(), "zipx-*.zip")
-	if err != nil {
-		t.Fatalf("create temp zip: %v", err)
-	}
-	defer func() { _ = f.Close() }()
-
-	zw := zip.NewWriter(f)
-	for _, e := range entries {
-		name := e.name
-		if e.isDir && name[len(name)-1] != '/' {
-			name += "/"
-		}
-		h := &zip.FileHeader{
-			Name:     name,
+	zes := make([]testutils.ZipEntry, len(entries))
+	for i, e := range entries {
+		zes[i] = testutils.ZipEntry{
+			Name:     e.name,
+			Data:     e.data,
+			Mode:     e.mode,
 			Modified: e.modified,
-			Method:   zip.Store, // keep simple, small fixtures
+			Dir:      e.isDir,
+			Symlink:  e.mode&os.ModeSymlink != 0,
 		}
-		if e.isDir {
-			h.SetMode(os.ModeDir | 0o755)
-		} else if e.mode != 0 {
-			h.SetMode(e.mode)
-		} else {
-			h.SetMode(0o644)
-		}
-		w, err := zw.CreateHeader(h)
-		if err != nil {
-			t.Fatalf("create header: %v", err)
-		}
-		if !e.isDir && len(e.data) > 0 {
-			if _, err := w.Write(e.data); err != nil {
-				t.Fatalf("write entry: %v", err)
-			}
-		}
-	}
-	if err := zw.Close(); err != nil {
-		t.Fatalf("close zip: %v", err)
 	}
-	return f.Name()
+	return testutils.WriteZip(t, zes...)
 }
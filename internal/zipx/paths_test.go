@@ -441,6 +441,9 @@ func FuzzNormalizeZipEntryPath(f *testing.F) {
 			if got == "." {
 				t.Fatalf("got %q, want normalized empty instead of dot", got)
 			}
+			if strings.HasPrefix(got, "/") {
+				t.Fatalf("got %q is absolute", got)
+			}
 			for seg := range strings.SplitSeq(got, "/") {
 				if seg == ".." {
 					t.Fatalf("got %q contains parent traversal segment", got)
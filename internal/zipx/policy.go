@@ -1,5 +1,7 @@
 package zipx
 
+import "github.com/bodrovis/lokex/v2/internal/eol"
+
 // Policy defines extraction limits and behavior.
 type Policy struct {
 	MaxFiles      int   // maximum number of files allowed
@@ -7,6 +9,8 @@ type Policy struct {
 	MaxFileBytes  int64 // maximum size per file
 	AllowSymlinks bool  // whether symlinks are allowed
 	PreserveTimes bool  // whether to preserve file mtimes
+
+	LineEnding eol.Style // rewrite line endings of text files; eol.Keep leaves them
 }
 
 // DefaultPolicy returns conservative defaults: 20k files,
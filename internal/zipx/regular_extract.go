@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/bodrovis/lokex/v2/internal/eol"
 )
 
 var (
@@ -29,7 +31,7 @@ func extractRegularFileEntry(f *zip.File, targetAbs string, p Policy) (int64, er
 		return 0, err
 	}
 
-	n, werr := copyCapped(tmpf, rc, p.MaxFileBytes)
+	n, werr := copyCapped(tmpf, eol.NewReader(rc, p.LineEnding), p.MaxFileBytes)
 	werr = closeWithPrecedence(werr, tmpf, rc)
 	if werr != nil {
 		_ = removeFile(tmp)
@@ -0,0 +1,231 @@
+package testutils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Chaos configures the failures a ChaosTransport injects. Rates are
+// probabilities in [0, 1], checked independently for every request in the
+// order of the fields; the first that hits decides what happens to it.
+type Chaos struct {
+	// Latency delays every request by Latency plus up to LatencyJitter.
+	Latency       time.Duration
+	LatencyJitter time.Duration
+
+	// DropRate fails requests with a connection reset, without a response.
+	DropRate float64
+	// RateLimitRate answers with 429 and a Retry-After of RetryAfter
+	// (default 1s) instead of sending the request.
+	RateLimitRate float64
+	RetryAfter    time.Duration
+	// ServerErrorRate answers with a random 500, 502, 503 or 504 instead of
+	// sending the request.
+	ServerErrorRate float64
+	// TruncateRate sends the request but cuts the response body in half; the
+	// reader then fails with io.ErrUnexpectedEOF.
+	TruncateRate float64
+
+	// Seed makes the injected failures reproducible; 0 picks a random seed.
+	Seed uint64
+	// Match, if set, limits chaos to the requests it returns true for, e.g.
+	// only polls. Other requests pass through untouched.
+	Match func(*http.Request) bool
+}
+
+// ChaosStats counts what a ChaosTransport did.
+type ChaosStats struct {
+	Requests     int // requests seen, matched or not
+	Dropped      int
+	RateLimited  int
+	ServerErrors int
+	Truncated    int
+}
+
+// ChaosTransport is an http.RoundTripper that injects latency, dropped
+// connections, truncated bodies and 5xx/429 responses in front of another
+// RoundTripper, to check retry and poll settings against realistic
+// failures:
+//
+//	chaos := testutils.NewChaosTransport(nil, testutils.Chaos{ServerErrorRate: 0.3, Seed: 1})
+//	cli, err := client.NewClient(token, projectID,
+//		client.WithHTTPClient(&http.Client{Transport: chaos}))
+//
+// It is safe for concurrent use.
+type ChaosTransport struct {
+	next  http.RoundTripper
+	chaos Chaos
+
+	mu    sync.Mutex
+	rng   *rand.Rand
+	stats ChaosStats
+}
+
+// NewChaosTransport wraps next (http.DefaultTransport if nil).
+func NewChaosTransport(next http.RoundTripper, c Chaos) *ChaosTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	seed := c.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	if c.RetryAfter <= 0 {
+		c.RetryAfter = time.Second
+	}
+	return &ChaosTransport{
+		next:  next,
+		chaos: c,
+		rng:   rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+// Stats returns the counts so far.
+func (t *ChaosTransport) Stats() ChaosStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+type chaosFault int
+
+const (
+	faultNone chaosFault = iota
+	faultDrop
+	faultRateLimit
+	faultServerError
+	faultTruncate
+)
+
+var chaosServerErrors = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ChaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	matched := t.chaos.Match == nil || t.chaos.Match(req)
+	delay, fault, status := t.decide(matched)
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			closeBody(req)
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	switch fault {
+	case faultDrop:
+		closeBody(req)
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	case faultRateLimit:
+		closeBody(req)
+		resp := chaosResponse(req, http.StatusTooManyRequests)
+		resp.Header.Set("Retry-After", strconv.Itoa(int(math.Ceil(t.chaos.RetryAfter.Seconds()))))
+		return resp, nil
+	case faultServerError:
+		closeBody(req)
+		return chaosResponse(req, status), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || fault != faultTruncate {
+		return resp, err
+	}
+	return truncate(resp)
+}
+
+// decide rolls the dice for one request.
+func (t *ChaosTransport) decide(matched bool) (time.Duration, chaosFault, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Requests++
+	if !matched {
+		return 0, faultNone, 0
+	}
+
+	c := t.chaos
+	delay := c.Latency
+	if c.LatencyJitter > 0 {
+		delay += time.Duration(t.rng.Int64N(int64(c.LatencyJitter)))
+	}
+	switch {
+	case t.hit(c.DropRate):
+		t.stats.Dropped++
+		return delay, faultDrop, 0
+	case t.hit(c.RateLimitRate):
+		t.stats.RateLimited++
+		return delay, faultRateLimit, http.StatusTooManyRequests
+	case t.hit(c.ServerErrorRate):
+		t.stats.ServerErrors++
+		return delay, faultServerError, chaosServerErrors[t.rng.IntN(len(chaosServerErrors))]
+	case t.hit(c.TruncateRate):
+		t.stats.Truncated++
+		return delay, faultTruncate, 0
+	}
+	return delay, faultNone, 0
+}
+
+func (t *ChaosTransport) hit(rate float64) bool {
+	return rate > 0 && t.rng.Float64() < rate
+}
+
+func chaosResponse(req *http.Request, status int) *http.Response {
+	body := fmt.Sprintf(`{"error":{"code":%d,"message":"chaos: injected %s"}}`, status, http.StatusText(status))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader([]byte(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// truncate replaces resp.Body with its first half, failing like a
+// connection that died mid-body. ContentLength keeps the full size.
+func truncate(resp *http.Response) (*http.Response, error) {
+	data, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if resp.ContentLength < 0 {
+		resp.ContentLength = int64(len(data))
+	}
+	resp.Body = io.NopCloser(io.MultiReader(
+		bytes.NewReader(data[:len(data)/2]),
+		errReader{io.ErrUnexpectedEOF},
+	))
+	return resp, nil
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
+
+var _ http.RoundTripper = (*ChaosTransport)(nil)
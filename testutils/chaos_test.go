@@ -0,0 +1,175 @@
+package testutils_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/testutils"
+)
+
+func chaosServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"project_id":"p","name":"chaos"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestChaosTransport_Faults(t *testing.T) {
+	t.Parallel()
+
+	srv, hits := chaosServer(t)
+	get := func(tr *testutils.ChaosTransport) (*http.Response, error) {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		return tr.RoundTrip(req)
+	}
+
+	t.Run("drop", func(t *testing.T) {
+		tr := testutils.NewChaosTransport(nil, testutils.Chaos{DropRate: 1})
+		if _, err := get(tr); !errors.Is(err, syscall.ECONNRESET) {
+			t.Fatalf("error = %v, want ECONNRESET", err)
+		}
+		if s := tr.Stats(); s.Requests != 1 || s.Dropped != 1 {
+			t.Fatalf("stats = %+v", s)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		tr := testutils.NewChaosTransport(nil, testutils.Chaos{RateLimitRate: 1, RetryAfter: 1500 * time.Millisecond})
+		resp, err := get(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+			t.Fatalf("response = %d, Retry-After %q; want 429, 2", resp.StatusCode, resp.Header.Get("Retry-After"))
+		}
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		tr := testutils.NewChaosTransport(nil, testutils.Chaos{ServerErrorRate: 1, Seed: 7})
+		resp, err := get(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 500 || resp.StatusCode > 504 {
+			t.Fatalf("status = %d, want 5xx", resp.StatusCode)
+		}
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		before := hits.Load()
+		tr := testutils.NewChaosTransport(nil, testutils.Chaos{TruncateRate: 1})
+		resp, err := get(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if !errors.Is(err, io.ErrUnexpectedEOF) || int64(len(body)) >= resp.ContentLength {
+			t.Fatalf("read %d of %d bytes, err %v; want a short read with ErrUnexpectedEOF", len(body), resp.ContentLength, err)
+		}
+		if hits.Load() != before+1 {
+			t.Fatal("truncated request did not reach the server")
+		}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		tr := testutils.NewChaosTransport(nil, testutils.Chaos{
+			DropRate: 1,
+			Match:    func(r *http.Request) bool { return r.Method == http.MethodPost },
+		})
+		resp, err := get(tr)
+		if err != nil {
+			t.Fatalf("unmatched request failed: %v", err)
+		}
+		resp.Body.Close()
+		if s := tr.Stats(); s.Requests != 1 || s.Dropped != 0 {
+			t.Fatalf("stats = %+v", s)
+		}
+	})
+}
+
+func TestChaosTransport_Latency(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := chaosServer(t)
+	tr := testutils.NewChaosTransport(nil, testutils.Chaos{Latency: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+
+	start := time.Now()
+	if _, err := tr.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want DeadlineExceeded", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("latency ignored the request context")
+	}
+}
+
+func TestChaosTransport_Seed(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := chaosServer(t)
+	run := func() testutils.ChaosStats {
+		tr := testutils.NewChaosTransport(nil, testutils.Chaos{DropRate: 0.3, ServerErrorRate: 0.3, Seed: 42})
+		for range 50 {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			if resp, err := tr.RoundTrip(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+		return tr.Stats()
+	}
+	if a, b := run(), run(); a != b {
+		t.Fatalf("same seed, different faults: %+v vs %+v", a, b)
+	}
+}
+
+// A client with retries rides out a flaky API.
+func TestChaosTransport_ClientRetries(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := chaosServer(t)
+	tr := testutils.NewChaosTransport(nil, testutils.Chaos{
+		DropRate:        0.2,
+		ServerErrorRate: 0.2,
+		TruncateRate:    0.2,
+		Seed:            3,
+	})
+	cli, err := client.NewClient("token", "p",
+		client.WithBaseURL(srv.URL),
+		client.WithHTTPClient(&http.Client{Transport: tr}),
+		client.WithMaxRetries(20),
+		client.WithBackoff(time.Millisecond, 2*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 10 {
+		var out struct {
+			Name string `json:"name"`
+		}
+		if err := cli.DoJSONWithRetry(context.Background(), http.MethodGet, "projects/p", nil, &out); err != nil || out.Name != "chaos" {
+			t.Fatalf("DoJSONWithRetry() = %+v, %v", out, err)
+		}
+	}
+	if s := tr.Stats(); s.Dropped+s.ServerErrors+s.Truncated == 0 {
+		t.Fatalf("no faults injected: %+v", s)
+	}
+}
@@ -0,0 +1,5 @@
+// Package testutils holds helpers for testing code built on lokex: canned
+// process lifecycles served through httpmock, zip fixture builders,
+// golden-file comparison, and environment-gated helpers for integration
+// tests. lokex uses it for its own tests too.
+package testutils
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/bodrovis/lokex/v2/internal/utils"
 	"github.com/joho/godotenv"
 )
 
@@ -31,8 +32,5 @@ func LoadDotEnv(paths ...string) error {
 
 // GetEnv returns the environment variable value if set, or the default.
 func GetEnv(key, def string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return def
+	return utils.GetEnv(key, def)
 }
@@ -0,0 +1,78 @@
+package testutils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that makes Golden rewrite golden
+// files when set to a true value (see strconv.ParseBool):
+//
+//	LOKEX_UPDATE_GOLDEN=1 go test ./... -run TestMyFormat
+//
+// An environment variable rather than a flag, so importing testutils never
+// adds flags to the test binary.
+const UpdateEnv = "LOKEX_UPDATE_GOLDEN"
+
+// GoldenPath returns the file Golden compares name against:
+// testdata/<name>.golden in the package under test.
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", filepath.FromSlash(name)+".golden")
+}
+
+// Golden fails t unless got matches the golden file for name byte-for-byte
+// (see GoldenPath). Run the tests with UpdateEnv set to write got to the file
+// instead, creating it and its directories if needed, and review the diff
+// before committing it. Golden files are compared as-is, so keep them out of
+// line-ending conversion (e.g. "*.golden -text" in .gitattributes).
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := GoldenPath(name)
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("testutils: golden %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("testutils: golden %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("testutils: golden %s: %v (run with %s=1 to create it)", path, err, UpdateEnv)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("testutils: output differs from %s (run with %s=1 to accept it)\n%s", path, UpdateEnv, firstDiff(want, got))
+	}
+}
+
+func updating() bool {
+	on, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	return on
+}
+
+// firstDiff describes the first line where want and got differ.
+func firstDiff(want, got []byte) string {
+	wl := bytes.SplitAfter(want, []byte("\n"))
+	gl := bytes.SplitAfter(got, []byte("\n"))
+	for i := range max(len(wl), len(gl)) {
+		var w, g []byte
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if !bytes.Equal(w, g) {
+			return fmt.Sprintf("line %d:\n  want %q\n  got  %q\n(want %d bytes, got %d)", i+1, w, g, len(want), len(got))
+		}
+	}
+	return ""
+}
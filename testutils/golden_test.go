@@ -0,0 +1,73 @@
+package testutils_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/testutils"
+)
+
+func setUpdate(t *testing.T, on bool) {
+	t.Helper()
+	val := ""
+	if on {
+		val = "1"
+	}
+	t.Setenv(testutils.UpdateEnv, val)
+}
+
+func TestGoldenPath(t *testing.T) {
+	t.Parallel()
+
+	if got, want := testutils.GoldenPath("csv/basic"), filepath.Join("testdata", "csv", "basic.golden"); got != want {
+		t.Fatalf("GoldenPath = %q, want %q", got, want)
+	}
+}
+
+func TestGolden_UpdateThenCompare(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	setUpdate(t, true)
+	testutils.Golden(t, "out/table", []byte("a,b\n1,2\n"))
+	data, err := os.ReadFile(filepath.Join("testdata", "out", "table.golden"))
+	if err != nil || string(data) != "a,b\n1,2\n" {
+		t.Fatalf("golden file = %q, %v", data, err)
+	}
+
+	setUpdate(t, false)
+	testutils.Golden(t, "out/table", []byte("a,b\n1,2\n"))
+
+	rec := &recordingTB{TB: t}
+	testutils.Golden(rec, "out/table", []byte("a,b\n1,3\n"))
+	for _, want := range []string{"table.golden", "LOKEX_UPDATE_GOLDEN=1", "line 2", `want "1,2\n"`, `got  "1,3\n"`} {
+		if !strings.Contains(rec.failed, want) {
+			t.Errorf("failure %q lacks %q", rec.failed, want)
+		}
+	}
+}
+
+func TestGolden_MissingFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	setUpdate(t, false)
+
+	rec := &recordingTB{TB: t}
+	testutils.Golden(rec, "nope", []byte("x"))
+	if !strings.Contains(rec.failed, "run with LOKEX_UPDATE_GOLDEN=1 to create it") {
+		t.Fatalf("failure = %q", rec.failed)
+	}
+}
+
+func TestGolden_TrailingDifference(t *testing.T) {
+	t.Chdir(t.TempDir())
+	setUpdate(t, true)
+	testutils.Golden(t, "short", []byte("one\n"))
+	setUpdate(t, false)
+
+	rec := &recordingTB{TB: t}
+	testutils.Golden(rec, "short", []byte("one\ntwo"))
+	if !strings.Contains(rec.failed, `line 2:`) || !strings.Contains(rec.failed, `got  "two"`) {
+		t.Fatalf("failure = %q", rec.failed)
+	}
+}
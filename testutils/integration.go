@@ -0,0 +1,129 @@
+package testutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+)
+
+// Environment variables read by the integration helpers.
+const (
+	EnvToken     = client.EnvAPIToken
+	EnvProjectID = client.EnvProjectID
+	// EnvTeamToken is a token allowed to create and delete projects.
+	// TempProject runs only when it is set, so a regular project token can
+	// never cause projects to be created.
+	EnvTeamToken = "LOKALISE_TEAM_TOKEN"
+	// EnvTeamID optionally selects the team TempProject creates projects in
+	// (default: the token's own team).
+	EnvTeamID = "LOKALISE_TEAM_ID"
+)
+
+// tempProjectTimeout bounds each of TempProject's create and delete calls.
+const tempProjectTimeout = 30 * time.Second
+
+// Credentials are the Lokalise settings an integration test runs against.
+type Credentials struct {
+	Token     string
+	ProjectID string
+}
+
+// RequireCredentials returns the token and project ID from the environment
+// (after LoadDotEnv), or skips t in -short mode or when either is unset.
+func RequireCredentials(t testing.TB) Credentials {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping integration test in -short mode")
+		return Credentials{}
+	}
+	_ = LoadDotEnv()
+	c := Credentials{Token: GetEnv(EnvToken, ""), ProjectID: GetEnv(EnvProjectID, "")}
+	if c.Token == "" || c.ProjectID == "" {
+		t.Skipf("%s and %s not set; skipping integration test", EnvToken, EnvProjectID)
+		return Credentials{}
+	}
+	return c
+}
+
+// TempProject creates a scratch project with the token in
+// LOKALISE_TEAM_TOKEN and returns a client bound to it; the project is
+// deleted when t finishes. t is skipped in -short mode or when the team token
+// is unset. opts apply to the returned client and to the create and delete
+// calls, which are never retried so a flaky create can't leave duplicates.
+func TempProject(t testing.TB, opts ...client.Option) *client.Client {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping integration test in -short mode")
+		return nil
+	}
+	_ = LoadDotEnv()
+	token := GetEnv(EnvTeamToken, "")
+	if token == "" {
+		t.Skipf("%s not set; skipping test that needs a scratch project", EnvTeamToken)
+		return nil
+	}
+
+	// Project creation isn't project-scoped; the ID only satisfies NewClient.
+	admin, err := client.NewClient(token, "-", append(slices.Clone(opts), client.WithMaxRetries(0))...)
+	if err != nil {
+		t.Fatalf("testutils: team client: %v", err)
+		return nil
+	}
+
+	body := map[string]any{
+		"name":        fmt.Sprintf("lokex-test %s %d", t.Name(), time.Now().UnixNano()),
+		"description": "Scratch project created by lokex testutils.TempProject; safe to delete.",
+	}
+	if team := GetEnv(EnvTeamID, ""); team != "" {
+		id, err := strconv.ParseInt(team, 10, 64)
+		if err != nil {
+			t.Fatalf("testutils: %s: %v", EnvTeamID, err)
+			return nil
+		}
+		body["team_id"] = id
+	}
+	buf, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("testutils: encode project: %v", err)
+		return nil
+	}
+
+	var created struct {
+		ProjectID string `json:"project_id"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tempProjectTimeout)
+	defer cancel()
+	if err := admin.DoJSONWithRetry(ctx, http.MethodPost, "projects", bytes.NewReader(buf), &created); err != nil {
+		t.Fatalf("testutils: create scratch project: %v", err)
+		return nil
+	}
+	if created.ProjectID == "" {
+		t.Fatalf("testutils: create scratch project: response has no project_id")
+		return nil
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tempProjectTimeout)
+		defer cancel()
+		path := "projects/" + url.PathEscape(created.ProjectID)
+		if err := admin.DoJSONWithRetry(ctx, http.MethodDelete, path, nil, nil); err != nil {
+			t.Errorf("testutils: delete scratch project %s (remove it by hand): %v", created.ProjectID, err)
+		}
+	})
+
+	cli, err := client.NewClient(token, created.ProjectID, opts...)
+	if err != nil {
+		t.Fatalf("testutils: client for scratch project: %v", err)
+		return nil
+	}
+	return cli
+}
@@ -0,0 +1,147 @@
+package testutils_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/testutils"
+	"github.com/jarcoal/httpmock"
+)
+
+// recordingTB captures skips and failures instead of stopping the test.
+type recordingTB struct {
+	testing.TB
+	skipped string
+	failed  string
+}
+
+func (r *recordingTB) Skip(args ...any)                 { r.skipped = fmt.Sprint(args...) }
+func (r *recordingTB) Skipf(format string, args ...any) { r.skipped = fmt.Sprintf(format, args...) }
+func (r *recordingTB) Fatalf(format string, args ...any) {
+	r.failed = fmt.Sprintf(format, args...)
+}
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.failed = fmt.Sprintf(format, args...)
+}
+
+func skipInShort(t *testing.T) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("checks behavior outside -short mode")
+	}
+}
+
+func TestRequireCredentials(t *testing.T) {
+	skipInShort(t)
+
+	t.Run("set", func(t *testing.T) {
+		t.Setenv(testutils.EnvToken, "tok")
+		t.Setenv(testutils.EnvProjectID, "123.abc")
+
+		rec := &recordingTB{TB: t}
+		got := testutils.RequireCredentials(rec)
+		if rec.skipped != "" {
+			t.Fatalf("skipped: %s", rec.skipped)
+		}
+		if got != (testutils.Credentials{Token: "tok", ProjectID: "123.abc"}) {
+			t.Fatalf("credentials = %+v", got)
+		}
+	})
+
+	t.Run("missing project", func(t *testing.T) {
+		t.Setenv(testutils.EnvToken, "tok")
+		t.Setenv(testutils.EnvProjectID, "")
+
+		rec := &recordingTB{TB: t}
+		got := testutils.RequireCredentials(rec)
+		if !strings.Contains(rec.skipped, testutils.EnvProjectID) {
+			t.Fatalf("skipped = %q, want mention of %s", rec.skipped, testutils.EnvProjectID)
+		}
+		if got != (testutils.Credentials{}) {
+			t.Fatalf("credentials = %+v, want zero", got)
+		}
+	})
+}
+
+func TestTempProject_SkipsWithoutTeamToken(t *testing.T) {
+	skipInShort(t)
+	t.Setenv(testutils.EnvTeamToken, "")
+
+	rec := &recordingTB{TB: t}
+	if cli := testutils.TempProject(rec); cli != nil {
+		t.Fatalf("client = %v, want nil", cli)
+	}
+	if !strings.Contains(rec.skipped, testutils.EnvTeamToken) {
+		t.Fatalf("skipped = %q, want mention of %s", rec.skipped, testutils.EnvTeamToken)
+	}
+}
+
+func TestTempProject_CreatesAndDeletes(t *testing.T) {
+	skipInShort(t)
+	t.Setenv(testutils.EnvTeamToken, "team-token")
+	t.Setenv(testutils.EnvTeamID, "42")
+
+	mt := httpmock.NewMockTransport()
+	var created map[string]any
+	mt.RegisterResponder(http.MethodPost, "https://api.test/api2/projects",
+		func(req *http.Request) (*http.Response, error) {
+			if got := req.Header.Get("X-Api-Token"); got != "team-token" {
+				t.Errorf("token = %q, want team-token", got)
+			}
+			if err := json.NewDecoder(req.Body).Decode(&created); err != nil {
+				t.Errorf("decode body: %v", err)
+			}
+			return httpmock.NewStringResponse(http.StatusOK, `{"project_id":"999.xyz","name":"x"}`), nil
+		})
+	mt.RegisterResponder(http.MethodDelete, "https://api.test/api2/projects/999.xyz",
+		httpmock.NewStringResponder(http.StatusOK, `{"project_id":"999.xyz","project_deleted":true}`))
+
+	t.Run("scratch", func(t *testing.T) {
+		cli := testutils.TempProject(t,
+			client.WithHTTPClient(&http.Client{Transport: mt}),
+			client.WithBaseURL("https://api.test/api2/"),
+		)
+		if cli == nil || cli.ProjectID != "999.xyz" {
+			t.Fatalf("client = %+v, want project 999.xyz", cli)
+		}
+		if mt.GetCallCountInfo()["DELETE https://api.test/api2/projects/999.xyz"] != 0 {
+			t.Fatal("project deleted before the test finished")
+		}
+	})
+
+	if n := mt.GetCallCountInfo()["DELETE https://api.test/api2/projects/999.xyz"]; n != 1 {
+		t.Fatalf("delete calls = %d, want 1", n)
+	}
+	if name, _ := created["name"].(string); !strings.Contains(name, "TestTempProject_CreatesAndDeletes/scratch") {
+		t.Fatalf("project name = %q, want the test name in it", name)
+	}
+	if created["team_id"] != float64(42) {
+		t.Fatalf("team_id = %v, want 42", created["team_id"])
+	}
+}
+
+func TestTempProject_CreateIsNotRetried(t *testing.T) {
+	skipInShort(t)
+	t.Setenv(testutils.EnvTeamToken, "team-token")
+	t.Setenv(testutils.EnvTeamID, "")
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodPost, "https://api.test/api2/projects",
+		httpmock.NewStringResponder(http.StatusServiceUnavailable, `{"error":{"message":"down","code":503}}`))
+
+	rec := &recordingTB{TB: t}
+	cli := testutils.TempProject(rec,
+		client.WithHTTPClient(&http.Client{Transport: mt}),
+		client.WithBaseURL("https://api.test/api2/"),
+	)
+	if cli != nil || !strings.Contains(rec.failed, "create scratch project") {
+		t.Fatalf("client = %v, failed = %q", cli, rec.failed)
+	}
+	if n := mt.GetTotalCallCount(); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+}
@@ -0,0 +1,130 @@
+package testutils
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/jarcoal/httpmock"
+)
+
+// ProcessScript is a canned Lokalise process whose status advances by one
+// step each time it is polled (GET .../processes/{ID}). Once the last status
+// is reached it is returned for every further poll.
+type ProcessScript struct {
+	ID          string
+	Type        string   // e.g. "file-import", "async-export"; optional
+	Statuses    []string // one per poll, e.g. Lifecycle(3, "finished")
+	Message     string   // sent with every status, e.g. the failure reason
+	DownloadURL string   // details.download_url once the last status is reached
+}
+
+// Lifecycle returns polls statuses going queued → running → final: the first
+// poll sees "queued", the last sees final, and the ones in between see
+// "running". polls < 1 is treated as 1 (final right away).
+func Lifecycle(polls int, final string) []string {
+	polls = max(polls, 1)
+	out := make([]string, polls)
+	for i := range out {
+		switch {
+		case i == polls-1:
+			out[i] = final
+		case i == 0:
+			out[i] = "queued"
+		default:
+			out[i] = "running"
+		}
+	}
+	return out
+}
+
+// ProcessMock serves ProcessScripts through httpmock. Create it with
+// MockProcesses.
+type ProcessMock struct {
+	mu    sync.Mutex
+	procs map[string]*ProcessScript
+	order []string
+	polls map[string]int
+}
+
+var (
+	processRe     = regexp.MustCompile(`/processes/([^/?]+)$`)
+	processListRe = regexp.MustCompile(`/processes/?$`)
+)
+
+// MockProcesses registers responders on mt for
+//
+//	GET .../processes/{id}  the script's next status (404 for unknown IDs)
+//	GET .../processes       every script at its current status, without advancing
+//
+// for any host and project. Pass nil to use httpmock's global transport
+// (httpmock.Activate); a dedicated httpmock.NewMockTransport wired in with
+// client.WithHTTPClient keeps parallel tests apart.
+func MockProcesses(mt *httpmock.MockTransport, procs ...ProcessScript) *ProcessMock {
+	m := &ProcessMock{procs: map[string]*ProcessScript{}, polls: map[string]int{}}
+	for _, p := range procs {
+		m.order = append(m.order, p.ID)
+		m.procs[p.ID] = &p
+	}
+
+	if mt == nil {
+		mt = httpmock.DefaultTransport
+	}
+	mt.RegisterRegexpResponder(http.MethodGet, processRe, m.serveOne)
+	mt.RegisterRegexpResponder(http.MethodGet, processListRe, m.serveList)
+	return m
+}
+
+// Polls returns how many times the process has been polled.
+func (m *ProcessMock) Polls(id string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.polls[id]
+}
+
+func (m *ProcessMock) serveOne(req *http.Request) (*http.Response, error) {
+	id := processRe.FindStringSubmatch(req.URL.Path)[1]
+
+	m.mu.Lock()
+	p, ok := m.procs[id]
+	if !ok {
+		m.mu.Unlock()
+		return httpmock.NewStringResponse(http.StatusNotFound,
+			`{"error":{"message":"Process not found","code":404}}`), nil
+	}
+	obj := m.processObject(p, m.polls[id])
+	m.polls[id]++
+	m.mu.Unlock()
+
+	return httpmock.NewJsonResponse(http.StatusOK, map[string]any{"process": obj})
+}
+
+func (m *ProcessMock) serveList(*http.Request) (*http.Response, error) {
+	m.mu.Lock()
+	list := make([]map[string]any, 0, len(m.order))
+	for _, id := range m.order {
+		list = append(list, m.processObject(m.procs[id], max(m.polls[id]-1, 0)))
+	}
+	m.mu.Unlock()
+
+	return httpmock.NewJsonResponse(http.StatusOK, map[string]any{"processes": list})
+}
+
+// processObject renders p as of its poll'th poll. Callers hold m.mu.
+func (m *ProcessMock) processObject(p *ProcessScript, poll int) map[string]any {
+	status := "finished"
+	last := len(p.Statuses) - 1
+	if last >= 0 {
+		status = p.Statuses[min(poll, last)]
+	}
+	obj := map[string]any{
+		"process_id": p.ID,
+		"type":       p.Type,
+		"status":     status,
+		"message":    p.Message,
+	}
+	if p.DownloadURL != "" && poll >= last {
+		obj["details"] = map[string]any{"download_url": p.DownloadURL}
+	}
+	return obj
+}
@@ -0,0 +1,168 @@
+package testutils_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bodrovis/lokex/v2/client"
+	"github.com/bodrovis/lokex/v2/testutils"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestLifecycle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		polls int
+		final string
+		want  []string
+	}{
+		{0, "finished", []string{"finished"}},
+		{1, "failed", []string{"failed"}},
+		{2, "finished", []string{"queued", "finished"}},
+		{4, "finished", []string{"queued", "running", "running", "finished"}},
+	}
+	for _, tt := range tests {
+		if got := testutils.Lifecycle(tt.polls, tt.final); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lifecycle(%d, %q) = %v, want %v", tt.polls, tt.final, got, tt.want)
+		}
+	}
+}
+
+func newMockedClient(t *testing.T, mt *httpmock.MockTransport) *client.Client {
+	t.Helper()
+
+	c, err := client.NewClient("token", "proj",
+		client.WithHTTPClient(&http.Client{Transport: mt}),
+		client.WithPollWait(time.Millisecond, 5*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestMockProcesses_WaitAll(t *testing.T) {
+	t.Parallel()
+
+	mt := httpmock.NewMockTransport()
+	pm := testutils.MockProcesses(mt,
+		testutils.ProcessScript{ID: "imp", Type: "file-import", Statuses: testutils.Lifecycle(3, "finished")},
+		testutils.ProcessScript{ID: "exp", Statuses: testutils.Lifecycle(2, "finished"), DownloadURL: "https://cdn.example/bundle.zip"},
+		testutils.ProcessScript{ID: "bad", Statuses: testutils.Lifecycle(2, "failed"), Message: "bad file"},
+	)
+
+	procs, err := newMockedClient(t, mt).WaitAll(context.Background(), []string{"imp", "exp", "bad"})
+	if err != nil {
+		t.Fatalf("WaitAll() error = %v", err)
+	}
+
+	want := map[string]client.QueuedProcess{
+		"imp": {ProcessID: "imp", Type: "file-import", Status: client.ProcessFinished},
+		"exp": {ProcessID: "exp", Status: client.ProcessFinished, DownloadURL: "https://cdn.example/bundle.zip"},
+		"bad": {ProcessID: "bad", Status: client.ProcessFailed, Message: "bad file"},
+	}
+	for _, p := range procs {
+		if p.Details.DownloadURL != p.DownloadURL {
+			t.Errorf("process %s: Details.DownloadURL = %q, want %q", p.ProcessID, p.Details.DownloadURL, p.DownloadURL)
+		}
+		p.Details = client.ProcessDetails{}
+		if !reflect.DeepEqual(p, want[p.ProcessID]) {
+			t.Errorf("process %s = %+v, want %+v", p.ProcessID, p, want[p.ProcessID])
+		}
+	}
+	if got := pm.Polls("imp"); got != 3 {
+		t.Errorf("Polls(imp) = %d, want 3", got)
+	}
+	if got := pm.Polls("exp"); got != 2 {
+		t.Errorf("Polls(exp) = %d, want 2", got)
+	}
+}
+
+func TestMockProcesses_ProcessHandle(t *testing.T) {
+	t.Parallel()
+
+	mt := httpmock.NewMockTransport()
+	testutils.MockProcesses(mt, testutils.ProcessScript{ID: "p1", Statuses: []string{"queued", "running", "finished"}})
+	c := newMockedClient(t, mt)
+
+	var seen []string
+	for range 4 {
+		p, err := c.Process("p1").Status(context.Background())
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		seen = append(seen, p.Status)
+	}
+	if want := []string{"queued", "running", "finished", "finished"}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("statuses = %v, want %v", seen, want)
+	}
+}
+
+func TestMockProcesses_UnknownAndList(t *testing.T) {
+	t.Parallel()
+
+	mt := httpmock.NewMockTransport()
+	testutils.MockProcesses(mt,
+		testutils.ProcessScript{ID: "a", Type: "file-import", Statuses: testutils.Lifecycle(3, "finished")},
+		testutils.ProcessScript{ID: "b", Type: "file-import"},
+	)
+	hc := &http.Client{Transport: mt}
+
+	resp, err := hc.Get("https://api.lokalise.com/api2/projects/proj/processes/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown process status = %d, want 404", resp.StatusCode)
+	}
+
+	for range 2 {
+		resp, err := hc.Get("https://api.lokalise.com/api2/projects/proj/processes/a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	resp, err = hc.Get("https://api.lokalise.com/api2/projects/proj/processes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var list struct {
+		Processes []struct {
+			ID     string `json:"process_id"`
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"processes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Processes) != 2 || list.Processes[0].Status != "running" || list.Processes[1].Status != "finished" || list.Processes[0].Type != "file-import" {
+		t.Fatalf("list = %+v, want a running and b finished (no statuses)", list.Processes)
+	}
+}
+
+func TestMockProcesses_GlobalTransport(t *testing.T) {
+	httpmock.Activate(t)
+
+	pm := testutils.MockProcesses(nil, testutils.ProcessScript{ID: "g", Statuses: testutils.Lifecycle(2, "finished")})
+	c, err := client.NewClient("token", "proj", client.WithPollWait(time.Millisecond, 5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := c.Process("g").Wait(context.Background())
+	if err != nil || p.Status != client.ProcessFinished {
+		t.Fatalf("Wait() = %+v, %v", p, err)
+	}
+	if pm.Polls("g") != 2 {
+		t.Fatalf("Polls(g) = %d, want 2", pm.Polls("g"))
+	}
+}
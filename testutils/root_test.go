@@ -6,7 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
-	"github.com/bodrovis/lokex/v2/internal/testutils"
+	"github.com/bodrovis/lokex/v2/testutils"
 )
 
 func resetProjectRootCache(t *testing.T) {
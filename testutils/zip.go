@@ -0,0 +1,136 @@
+package testutils
+
+import (
+	"archive/zip"
+	"bytes"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ZipEntry is one entry of a fixture archive built with BuildZip or
+// WriteZip. The Zip* constructors cover the usual cases; set fields directly
+// for anything else.
+type ZipEntry struct {
+	Name string
+	Data []byte
+	// Mode's type bits (e.g. os.ModeNamedPipe) are kept; without permission
+	// bits it gets 0o644, or 0o755 for directories and 0o777 for symlinks.
+	Mode     os.FileMode
+	Modified time.Time
+	Dir      bool   // a directory entry; "/" is appended to Name if missing
+	Symlink  bool   // a symlink; Data is the link target
+	Method   uint16 // compression method: zip.Store (the zero value) or zip.Deflate
+	// DeclaredSize, when non-zero, is written to the header as the
+	// uncompressed size instead of len(Data). Data is stored as-is, so
+	// reading the entry fails; use it to test size limits checked against
+	// headers (zip bombs) without building a huge archive.
+	DeclaredSize uint64
+}
+
+// ZipFile is a regular file entry.
+func ZipFile(name, data string) ZipEntry {
+	return ZipEntry{Name: name, Data: []byte(data)}
+}
+
+// ZipDir is a directory entry.
+func ZipDir(name string) ZipEntry {
+	return ZipEntry{Name: name, Dir: true}
+}
+
+// ZipSymlink is a symlink entry pointing at target.
+func ZipSymlink(name, target string) ZipEntry {
+	return ZipEntry{Name: name, Data: []byte(target), Symlink: true}
+}
+
+// ZipHugeFile is a small file entry whose header claims size uncompressed
+// bytes.
+func ZipHugeFile(name string, size uint64) ZipEntry {
+	return ZipEntry{Name: name, Data: []byte("x"), DeclaredSize: size}
+}
+
+// ZipSlip is a file entry whose name escapes the extraction root: "../" is
+// prepended to name unless it is absolute or already starts with "../".
+func ZipSlip(name, data string) ZipEntry {
+	if !strings.HasPrefix(name, "../") && !strings.HasPrefix(name, "/") {
+		name = "../" + name
+	}
+	return ZipFile(name, data)
+}
+
+// BuildZip returns an archive holding entries, in order. It fails t on any
+// error.
+func BuildZip(t testing.TB, entries ...ZipEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		if err := writeZipEntry(zw, e); err != nil {
+			t.Fatalf("testutils: zip entry %q: %v", e.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("testutils: close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// WriteZip writes BuildZip(t, entries...) to a file in t.TempDir and
+// returns its path.
+func WriteZip(t testing.TB, entries ...ZipEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.zip")
+	if err := os.WriteFile(path, BuildZip(t, entries...), 0o644); err != nil {
+		t.Fatalf("testutils: write zip: %v", err)
+	}
+	return path
+}
+
+func writeZipEntry(zw *zip.Writer, e ZipEntry) error {
+	fh := &zip.FileHeader{Name: e.Name, Modified: e.Modified, Method: e.Method}
+	switch {
+	case e.Dir:
+		if !strings.HasSuffix(fh.Name, "/") {
+			fh.Name += "/"
+		}
+		fh.Method = zip.Store
+		fh.SetMode(os.ModeDir | permOr(e.Mode, 0o755))
+	case e.Symlink:
+		fh.SetMode(os.ModeSymlink | permOr(e.Mode, 0o777))
+	default:
+		fh.SetMode(permOr(e.Mode, 0o644))
+	}
+
+	if e.DeclaredSize > 0 {
+		fh.Method = zip.Store
+		fh.CRC32 = crc32.ChecksumIEEE(e.Data)
+		fh.CompressedSize64 = uint64(len(e.Data))
+		fh.UncompressedSize64 = e.DeclaredSize
+		w, err := zw.CreateRaw(fh)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(e.Data)
+		return err
+	}
+
+	w, err := zw.CreateHeader(fh)
+	if err != nil {
+		return err
+	}
+	if !e.Dir {
+		_, err = w.Write(e.Data)
+	}
+	return err
+}
+
+// permOr returns mode with def as its permission bits if it has none.
+func permOr(mode, def os.FileMode) os.FileMode {
+	if mode.Perm() == 0 {
+		return mode | def
+	}
+	return mode
+}
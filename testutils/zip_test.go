@@ -0,0 +1,122 @@
+package testutils_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bodrovis/lokex/v2/internal/zipx"
+	"github.com/bodrovis/lokex/v2/testutils"
+)
+
+func openZip(t *testing.T, data []byte) *zip.Reader {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	return zr
+}
+
+func TestBuildZip_Entries(t *testing.T) {
+	t.Parallel()
+
+	zr := openZip(t, testutils.BuildZip(t,
+		testutils.ZipFile("en/app.json", `{"a":"b"}`),
+		testutils.ZipDir("fr"),
+		testutils.ZipSymlink("link", "en/app.json"),
+		testutils.ZipEntry{Name: "big.txt", Data: []byte(strings.Repeat("a", 100)), Method: zip.Deflate, Mode: 0o600},
+	))
+	if len(zr.File) != 4 {
+		t.Fatalf("entries = %d, want 4", len(zr.File))
+	}
+
+	read := func(f *zip.File) string {
+		t.Helper()
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		defer func() { _ = rc.Close() }()
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		return string(b)
+	}
+
+	file, dir, link, big := zr.File[0], zr.File[1], zr.File[2], zr.File[3]
+	if file.Mode() != 0o644 || read(file) != `{"a":"b"}` {
+		t.Fatalf("file: mode %v, data %q", file.Mode(), read(file))
+	}
+	if dir.Name != "fr/" || !dir.Mode().IsDir() {
+		t.Fatalf("dir: name %q, mode %v", dir.Name, dir.Mode())
+	}
+	if link.Mode()&os.ModeSymlink == 0 || read(link) != "en/app.json" {
+		t.Fatalf("symlink: mode %v, target %q", link.Mode(), read(link))
+	}
+	if big.Method != zip.Deflate || big.Mode() != 0o600 || read(big) != strings.Repeat("a", 100) {
+		t.Fatalf("deflated: method %d, mode %v", big.Method, big.Mode())
+	}
+}
+
+func TestBuildZip_KeepsTypeBits(t *testing.T) {
+	t.Parallel()
+
+	zr := openZip(t, testutils.BuildZip(t, testutils.ZipEntry{Name: "fifo", Mode: os.ModeNamedPipe}))
+	if got := zr.File[0].Mode(); got != os.ModeNamedPipe|0o644 {
+		t.Fatalf("mode = %v, want %v", got, os.ModeNamedPipe|0o644)
+	}
+}
+
+func TestZipHugeFile_DeclaresSize(t *testing.T) {
+	t.Parallel()
+
+	const size = 10 << 30 // beyond 4 GiB, so zip64 headers are needed
+	zr := openZip(t, testutils.BuildZip(t, testutils.ZipHugeFile("bomb.bin", size)))
+	if got := zr.File[0].UncompressedSize64; got != size {
+		t.Fatalf("declared size = %d, want %d", got, uint64(size))
+	}
+
+	err := zipx.Unzip(testutils.WriteZip(t, testutils.ZipHugeFile("bomb.bin", size)), t.TempDir(), zipx.DefaultPolicy())
+	if err == nil || !strings.Contains(err.Error(), "too big by header") {
+		t.Fatalf("Unzip err = %v, want header size error", err)
+	}
+}
+
+func TestZipSlip(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct{ in, want string }{
+		{"evil.txt", "../evil.txt"},
+		{"../evil.txt", "../evil.txt"},
+		{"/etc/evil", "/etc/evil"},
+	} {
+		if got := testutils.ZipSlip(tc.in, "x").Name; got != tc.want {
+			t.Errorf("ZipSlip(%q).Name = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	dst := t.TempDir()
+	err := zipx.Unzip(testutils.WriteZip(t, testutils.ZipSlip("evil.txt", "nope")), dst, zipx.DefaultPolicy())
+	if err == nil || !strings.Contains(err.Error(), "unsafe path") {
+		t.Fatalf("Unzip err = %v, want unsafe path error", err)
+	}
+}
+
+func TestWriteZip_File(t *testing.T) {
+	t.Parallel()
+
+	path := testutils.WriteZip(t, testutils.ZipFile("a.txt", "hello"))
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer func() { _ = zr.Close() }()
+	if len(zr.File) != 1 || zr.File[0].Name != "a.txt" {
+		t.Fatalf("unexpected entries: %+v", zr.File)
+	}
+}
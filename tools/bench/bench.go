@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// result holds averaged metrics for one benchmark.
+type result struct {
+	nsPerOp     float64
+	allocsPerOp float64
+	runs        int
+}
+
+func repoRoot() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	// Same layout as tools/lint: we run from tools/ or tools/bench/.
+	d := wd
+	for range [4]struct{}{} {
+		if filepath.Base(d) == "tools" {
+			return filepath.Dir(d), nil
+		}
+		d = filepath.Dir(d)
+	}
+
+	return "", fmt.Errorf("cannot locate repo root: expected to be run under tools/")
+}
+
+// parse reads `go test -bench` output and averages repeated runs (-count).
+func parse(r io.Reader) map[string]result {
+	out := map[string]result{}
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		name := fields[0]
+		// Drop the -GOMAXPROCS suffix so results compare across machines.
+		if i := strings.LastIndexByte(name, '-'); i > 0 {
+			if _, err := strconv.Atoi(name[i+1:]); err == nil {
+				name = name[:i]
+			}
+		}
+
+		var ns, allocs float64
+		for i := 1; i+1 < len(fields); i++ {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				ns = v
+			case "allocs/op":
+				allocs = v
+			}
+		}
+		if ns == 0 {
+			continue
+		}
+
+		cur := out[name]
+		cur.nsPerOp = (cur.nsPerOp*float64(cur.runs) + ns) / float64(cur.runs+1)
+		cur.allocsPerOp = (cur.allocsPerOp*float64(cur.runs) + allocs) / float64(cur.runs+1)
+		cur.runs++
+		out[name] = cur
+	}
+
+	return out
+}
+
+func pctChange(old, cur float64) float64 {
+	if old == 0 {
+		return 0
+	}
+	return (cur - old) / old * 100
+}
+
+// compare prints a table and returns the names that regressed beyond threshold (%).
+func compare(w io.Writer, base, cur map[string]result, threshold float64) []string {
+	names := make([]string, 0, len(cur))
+	for name := range cur {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var regressed []string
+	for _, name := range names {
+		c := cur[name]
+		b, ok := base[name]
+		if !ok {
+			_, _ = fmt.Fprintf(w, "%-50s %14.0f ns/op %10.0f allocs/op   (new)\n", name, c.nsPerOp, c.allocsPerOp)
+			continue
+		}
+
+		dNs := pctChange(b.nsPerOp, c.nsPerOp)
+		dAllocs := pctChange(b.allocsPerOp, c.allocsPerOp)
+
+		mark := ""
+		if dNs > threshold || dAllocs > threshold {
+			mark = "  REGRESSION"
+			regressed = append(regressed, name)
+		}
+
+		_, _ = fmt.Fprintf(w, "%-50s %14.0f ns/op (%+6.1f%%) %10.0f allocs/op (%+6.1f%%)%s\n",
+			name, c.nsPerOp, dNs, c.allocsPerOp, dAllocs, mark)
+	}
+
+	return regressed
+}
+
+func main() {
+	var (
+		baselinePath = flag.String("baseline", "bench_baseline.txt", "baseline file, relative to repo root")
+		update       = flag.Bool("update", false, "write the current run as the new baseline")
+		threshold    = flag.Float64("threshold", 15, "allowed regression in percent (ns/op or allocs/op)")
+		count        = flag.Int("count", 5, "benchmark repetitions (go test -count)")
+		pattern      = flag.String("bench", ".", "benchmark pattern (go test -bench)")
+	)
+	flag.Parse()
+
+	root, err := repoRoot()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("===> go test -bench (root)")
+	var buf bytes.Buffer
+	cmd := exec.Command("go", "test", "-run=^$", "-bench="+*pattern, "-benchmem",
+		"-count="+strconv.Itoa(*count), "./...")
+	cmd.Dir = root
+	cmd.Stdout = io.MultiWriter(os.Stdout, &buf)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "bench_output.txt"), buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	base := filepath.Join(root, *baselinePath)
+	if *update {
+		if err := os.WriteFile(base, buf.Bytes(), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Baseline updated:", base)
+		return
+	}
+
+	raw, err := os.ReadFile(base)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: read baseline (run with -update first):", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("===> compare against %s (threshold %.1f%%)\n", *baselinePath, *threshold)
+	regressed := compare(os.Stdout, parse(bytes.NewReader(raw)), parse(&buf), *threshold)
+	if len(regressed) > 0 {
+		fmt.Fprintf(os.Stderr, "%d benchmark(s) regressed: %s\n", len(regressed), strings.Join(regressed, ", "))
+		os.Exit(1)
+	}
+
+	fmt.Println("Done ✔")
+}